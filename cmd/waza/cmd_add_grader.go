@@ -0,0 +1,379 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+
+	"github.com/microsoft/waza/internal/graders"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/microsoft/waza/internal/validation"
+)
+
+// addGraderKinds are the grader types add-grader can scaffold, in the order
+// the picker lists them. "regex" is a text grader with regex_match.
+var addGraderKinds = []struct {
+	name, description string
+}{
+	{"regex", "output matches a regular expression"},
+	{"text", "output contains substrings"},
+	{"prompt", "an LLM judge grades the output against instructions"},
+	{"code", "a Python assertion over the output"},
+	{"file", "files exist in the workspace"},
+}
+
+// addGraderOptions holds the grader being added, from flags or the form.
+type addGraderOptions struct {
+	kind       string
+	name       string
+	weight     float64
+	patterns   []string
+	contains   []string
+	prompt     string
+	assertions []string
+	mustExist  []string
+}
+
+// addGraderIsInteractive reports whether in is a terminal the form can
+// prompt on. Tests replace it to drive the form with scripted input.
+var addGraderIsInteractive = func(in io.Reader) bool {
+	f, ok := in.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+func newAddGraderCommand() *cobra.Command {
+	var opts addGraderOptions
+
+	cmd := &cobra.Command{
+		Use:   "add-grader [task.yaml]",
+		Short: "Append a grader to a task file",
+		Long: `Append a grader to a task file's graders list.
+
+In a terminal, a form asks for the grader type, its name and the fields that
+type requires. Without a terminal (CI, pipes), pass --type, --name and the
+type's fields as flags instead:
+
+  regex   --pattern (repeatable)
+  text    --contains (repeatable)
+  prompt  --prompt
+  code    --assertion (repeatable)
+  file    --must-exist (repeatable)
+
+The new grader is validated against the task schema before the file is
+written. Existing graders are never changed, and a name already used by
+another grader in the file is rejected.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var taskPath string
+			if len(args) > 0 {
+				taskPath = args[0]
+			}
+			return addGraderCommandE(cmd, taskPath, &opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.kind, "type", "", "Grader type: regex, text, prompt, code or file")
+	cmd.Flags().StringVar(&opts.name, "name", "", "Grader name, unique within the task")
+	cmd.Flags().Float64Var(&opts.weight, "weight", 0, "Grader weight in the weighted score (default 1.0)")
+	cmd.Flags().StringArrayVar(&opts.patterns, "pattern", nil, "Regex the output must match (regex graders, repeatable)")
+	cmd.Flags().StringArrayVar(&opts.contains, "contains", nil, "Substring the output must contain (text graders, repeatable)")
+	cmd.Flags().StringVar(&opts.prompt, "prompt", "", "Instructions for the judge (prompt graders)")
+	cmd.Flags().StringArrayVar(&opts.assertions, "assertion", nil, "Python assertion over the output (code graders, repeatable)")
+	cmd.Flags().StringArrayVar(&opts.mustExist, "must-exist", nil, "Workspace path that must exist (file graders, repeatable)")
+
+	return cmd
+}
+
+func addGraderCommandE(cmd *cobra.Command, taskPath string, opts *addGraderOptions) error {
+	if opts.kind == "" {
+		if !addGraderIsInteractive(cmd.InOrStdin()) {
+			return errors.New("add-grader needs a terminal to prompt for the grader; pass the task file, --type, --name and the type's fields as flags instead (see --help)")
+		}
+		if err := runAddGraderForm(cmd.InOrStdin(), cmd.OutOrStdout(), &taskPath, opts); err != nil {
+			return err
+		}
+	}
+	if taskPath == "" {
+		return errors.New("task file is required")
+	}
+
+	data, err := os.ReadFile(taskPath)
+	if err != nil {
+		return fmt.Errorf("reading task file: %w", err)
+	}
+	grader, err := opts.validator()
+	if err != nil {
+		return err
+	}
+	updated, err := appendGrader(data, grader)
+	if err != nil {
+		return fmt.Errorf("%s: %w", taskPath, err)
+	}
+
+	info, err := os.Stat(taskPath)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(taskPath, updated, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("writing task file: %w", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Added %s grader %q to %s\n", opts.kind, grader.Identifier, taskPath) //nolint:errcheck
+	return nil
+}
+
+// runAddGraderForm asks for the grader type and name, then for the fields
+// that type requires.
+func runAddGraderForm(in io.Reader, out io.Writer, taskPath *string, opts *addGraderOptions) error {
+	var fields []huh.Field
+	if *taskPath == "" {
+		fields = append(fields, huh.NewInput().
+			Title("Task file").
+			Placeholder("evals/my-skill/tasks/basic.yaml").
+			Value(taskPath).
+			Validate(requiredField("task file")))
+	}
+	kindOptions := make([]huh.Option[string], len(addGraderKinds))
+	for i, k := range addGraderKinds {
+		kindOptions[i] = huh.NewOption(k.name+" — "+k.description, k.name)
+	}
+	fields = append(fields,
+		huh.NewSelect[string]().
+			Title("Grader type").
+			Options(kindOptions...).
+			Value(&opts.kind),
+		huh.NewInput().
+			Title("Grader name").
+			Description("Unique within the task; used as the result key").
+			Value(&opts.name).
+			Validate(requiredField("grader name")),
+	)
+	if err := runAddGraderStep(in, out, huh.NewGroup(fields...)); err != nil {
+		return err
+	}
+
+	var value string
+	var field *huh.Input
+	switch opts.kind {
+	case "regex":
+		field = huh.NewInput().
+			Title("Pattern").
+			Description("Regular expression the output must match").
+			Validate(func(s string) error {
+				if strings.TrimSpace(s) == "" {
+					return errors.New("pattern is required")
+				}
+				_, err := regexp.Compile(s)
+				return err
+			})
+	case "text":
+		field = huh.NewInput().
+			Title("Contains").
+			Description("Comma-separated substrings the output must contain").
+			Validate(requiredField("at least one substring"))
+	case "prompt":
+		field = huh.NewInput().
+			Title("Judge instructions").
+			Description("What the judge should check in the output").
+			Validate(requiredField("instructions"))
+	case "code":
+		field = huh.NewInput().
+			Title("Assertion").
+			Description("Python expression over output, e.g. len(output) > 0").
+			Validate(requiredField("assertion"))
+	case "file":
+		field = huh.NewInput().
+			Title("Must exist").
+			Description("Comma-separated workspace paths that must exist").
+			Validate(requiredField("at least one path"))
+	}
+	if err := runAddGraderStep(in, out, huh.NewGroup(field.Value(&value))); err != nil {
+		return err
+	}
+
+	switch opts.kind {
+	case "regex":
+		opts.patterns = []string{value}
+	case "text":
+		opts.contains = splitCSV(value)
+	case "prompt":
+		opts.prompt = value
+	case "code":
+		opts.assertions = []string{value}
+	case "file":
+		opts.mustExist = splitCSV(value)
+	}
+	return nil
+}
+
+func runAddGraderStep(in io.Reader, out io.Writer, group *huh.Group) error {
+	form := huh.NewForm(group).WithInput(in).WithOutput(out)
+	if f, ok := in.(*os.File); !ok || !term.IsTerminal(int(f.Fd())) {
+		form = form.WithAccessible(true)
+	}
+	if err := form.Run(); err != nil {
+		return fmt.Errorf("add-grader form: %w", err)
+	}
+	return nil
+}
+
+func requiredField(what string) func(string) error {
+	return func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return fmt.Errorf("%s is required", what)
+		}
+		return nil
+	}
+}
+
+func splitCSV(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// validator builds the grader block from opts, checking that the type's
+// required fields are set and the grader can be constructed.
+func (o *addGraderOptions) validator() (models.ValidatorInline, error) {
+	v := models.ValidatorInline{Identifier: strings.TrimSpace(o.name), Weight: o.weight}
+	if v.Identifier == "" {
+		return v, errors.New("--name is required")
+	}
+	if o.weight < 0 {
+		return v, fmt.Errorf("--weight must not be negative, got %g", o.weight)
+	}
+
+	switch o.kind {
+	case "regex":
+		if len(o.patterns) == 0 {
+			return v, errors.New("regex graders need at least one --pattern")
+		}
+		for _, p := range o.patterns {
+			if _, err := regexp.Compile(p); err != nil {
+				return v, fmt.Errorf("--pattern %q: %w", p, err)
+			}
+		}
+		v.Kind = models.GraderKindText
+		v.Parameters = models.TextGraderParameters{RegexMatch: o.patterns}
+	case "text":
+		if len(o.contains) == 0 {
+			return v, errors.New("text graders need at least one --contains")
+		}
+		v.Kind = models.GraderKindText
+		v.Parameters = models.TextGraderParameters{Contains: o.contains}
+	case "prompt":
+		if strings.TrimSpace(o.prompt) == "" {
+			return v, errors.New("prompt graders need --prompt")
+		}
+		v.Kind = models.GraderKindPrompt
+		v.Parameters = models.PromptGraderParameters{Prompt: o.prompt}
+	case "code":
+		if len(o.assertions) == 0 {
+			return v, errors.New("code graders need at least one --assertion")
+		}
+		v.Kind = models.GraderKindInlineScript
+		v.Parameters = models.InlineScriptGraderParameters{Assertions: o.assertions}
+	case "file":
+		if len(o.mustExist) == 0 {
+			return v, errors.New("file graders need at least one --must-exist")
+		}
+		v.Kind = models.GraderKindFile
+		v.Parameters = models.FileGraderParameters{MustExist: o.mustExist}
+	default:
+		names := make([]string, len(addGraderKinds))
+		for i, k := range addGraderKinds {
+			names[i] = k.name
+		}
+		return v, fmt.Errorf("--type %q is not one of %s", o.kind, strings.Join(names, ", "))
+	}
+
+	if _, err := graders.Create(v.Identifier, v.Parameters); err != nil {
+		return v, err
+	}
+	return v, nil
+}
+
+// appendGrader adds v to the end of the task's graders list (creating the
+// list if needed) and returns the re-encoded file. Existing graders, other
+// fields and comments are kept. The result must not add schema errors the
+// file didn't already have.
+func appendGrader(data []byte, v models.ValidatorInline) ([]byte, error) {
+	var tc models.TestCase
+	if err := yaml.Unmarshal(data, &tc); err != nil {
+		return nil, fmt.Errorf("parsing task file: %w", err)
+	}
+	for _, existing := range tc.Validators {
+		if existing.Identifier == v.Identifier {
+			return nil, fmt.Errorf("a grader named %q already exists", v.Identifier)
+		}
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing task file: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("task file is not a YAML mapping")
+	}
+	root := doc.Content[0]
+
+	var list *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "graders" {
+			list = root.Content[i+1]
+			break
+		}
+	}
+	if list == nil {
+		list = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "graders"}, list)
+	}
+	if list.Kind != yaml.SequenceNode {
+		// An empty "graders:" decodes as null
+		if list.Tag != "!!null" {
+			return nil, errors.New("graders is not a list")
+		}
+		*list = yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	}
+
+	var item yaml.Node
+	if err := item.Encode(v); err != nil {
+		return nil, fmt.Errorf("encoding grader: %w", err)
+	}
+	list.Content = append(list.Content, &item)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("encoding task file: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encoding task file: %w", err)
+	}
+
+	before := validation.ValidateTaskBytes(data)
+	var introduced []string
+	for _, e := range validation.ValidateTaskBytes(buf.Bytes()) {
+		if !slices.Contains(before, e) {
+			introduced = append(introduced, e)
+		}
+	}
+	if len(introduced) > 0 {
+		return nil, fmt.Errorf("the new grader fails schema validation: %s", strings.Join(introduced, "; "))
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/microsoft/waza/internal/models"
+	"github.com/microsoft/waza/internal/validation"
+)
+
+const addGraderTask = `# Checks the basic greeting
+id: greet-001
+name: Greeting
+inputs:
+  prompt: "Say hello"
+graders:
+  - name: says-hello
+    type: text
+    config:
+      contains: ["hello"]
+`
+
+func writeAddGraderTask(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "task.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+// scriptedInput feeds one line at a time so each accessible-mode prompt
+// reads only its own answer.
+func scriptedInput(lines ...string) io.Reader {
+	r, w := io.Pipe()
+	go func() {
+		defer w.Close() //nolint:errcheck
+		for _, line := range lines {
+			w.Write([]byte(line + "\n")) //nolint:errcheck
+			time.Sleep(50 * time.Millisecond)
+		}
+	}()
+	return r
+}
+
+func runAddGrader(t *testing.T, in io.Reader, args ...string) (string, error) {
+	t.Helper()
+	cmd := newAddGraderCommand()
+	cmd.SetArgs(args)
+	cmd.SetIn(in)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func loadAddGraderTask(t *testing.T, path string) *models.TestCase {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Empty(t, validation.ValidateTaskBytes(data))
+	tc, err := models.LoadTestCase(path)
+	require.NoError(t, err)
+	return tc
+}
+
+func TestAddGrader_InteractiveRegex(t *testing.T) {
+	orig := addGraderIsInteractive
+	addGraderIsInteractive = func(io.Reader) bool { return true }
+	t.Cleanup(func() { addGraderIsInteractive = orig })
+
+	path := writeAddGraderTask(t, addGraderTask)
+	// Type 1 is regex
+	out, err := runAddGrader(t, scriptedInput("1", "mentions-world", `(?i)hello,? world`), path)
+	require.NoError(t, err)
+	assert.Contains(t, out, `Added regex grader "mentions-world"`)
+
+	tc := loadAddGraderTask(t, path)
+	require.Len(t, tc.Validators, 2)
+	assert.Equal(t, "says-hello", tc.Validators[0].Identifier, "existing graders are kept first")
+	added := tc.Validators[1]
+	assert.Equal(t, "mentions-world", added.Identifier)
+	assert.Equal(t, models.GraderKindText, added.Kind)
+	assert.Equal(t, models.TextGraderParameters{RegexMatch: []string{`(?i)hello,? world`}}, added.Parameters)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "# Checks the basic greeting\n"), "comments are kept:\n%s", data)
+}
+
+func TestAddGrader_InteractivePromptsForTaskFile(t *testing.T) {
+	orig := addGraderIsInteractive
+	addGraderIsInteractive = func(io.Reader) bool { return true }
+	t.Cleanup(func() { addGraderIsInteractive = orig })
+
+	path := writeAddGraderTask(t, "id: t\nname: T\ninputs:\n  prompt: hi\n")
+	// Type 2 is text
+	_, err := runAddGrader(t, scriptedInput(path, "2", "keywords", "alpha, beta"))
+	require.NoError(t, err)
+
+	tc := loadAddGraderTask(t, path)
+	require.Len(t, tc.Validators, 1)
+	assert.Equal(t, models.TextGraderParameters{Contains: []string{"alpha", "beta"}}, tc.Validators[0].Parameters)
+}
+
+func TestAddGrader_Flags(t *testing.T) {
+	path := writeAddGraderTask(t, addGraderTask)
+	_, err := runAddGrader(t, strings.NewReader(""), path,
+		"--type", "code", "--name", "non-empty", "--assertion", "len(output) > 0", "--weight", "2")
+	require.NoError(t, err)
+
+	tc := loadAddGraderTask(t, path)
+	require.Len(t, tc.Validators, 2)
+	added := tc.Validators[1]
+	assert.Equal(t, models.GraderKindInlineScript, added.Kind)
+	assert.Equal(t, 2.0, added.Weight)
+	assert.Equal(t, []string{"len(output) > 0"}, added.Parameters.(models.InlineScriptGraderParameters).Assertions)
+}
+
+func TestAddGrader_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"non-TTY without flags", nil, "needs a terminal"},
+		{"duplicate name", []string{"--type", "text", "--name", "says-hello", "--contains", "x"}, `"says-hello" already exists`},
+		{"missing fields", []string{"--type", "regex", "--name", "r"}, "at least one --pattern"},
+		{"bad regex", []string{"--type", "regex", "--name", "r", "--pattern", "("}, `--pattern "("`},
+		{"unknown type", []string{"--type", "nope", "--name", "r"}, `--type "nope" is not one of`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeAddGraderTask(t, addGraderTask)
+			_, err := runAddGrader(t, strings.NewReader(""), append([]string{path}, tt.args...)...)
+			require.ErrorContains(t, err, tt.want)
+
+			data, readErr := os.ReadFile(path)
+			require.NoError(t, readErr)
+			assert.Equal(t, addGraderTask, string(data), "the task file is untouched on error")
+		})
+	}
+}
@@ -8,10 +8,18 @@ import (
 	"strings"
 
 	"github.com/microsoft/waza/internal/models"
+	"github.com/microsoft/waza/internal/statistics"
 	"github.com/spf13/cobra"
 )
 
-var compareOutputFormat string
+var (
+	compareOutputFormat string
+	compareConfidence   float64
+)
+
+// compareBootstrapSeed keeps significance flags stable across repeated
+// comparisons of the same files.
+const compareBootstrapSeed = 42
 
 func newCompareCommand() *cobra.Command {
 	cmd := &cobra.Command{
@@ -20,25 +28,32 @@ func newCompareCommand() *cobra.Command {
 		Long: `Compare results from multiple evaluation runs side by side.
 
 Loads two or more result JSON files and generates a comparison report showing
-per-task score deltas, pass rate differences, and aggregate statistics.`,
+per-task score deltas, pass rate differences, and aggregate statistics.
+
+A task's score change between the first and last file is flagged as
+significant when a bootstrap confidence interval over the difference of
+per-run score means excludes zero. Tasks need at least 2 runs in both files.`,
 		Args: cobra.MinimumNArgs(2),
 		RunE: compareCommandE,
 	}
 
 	cmd.Flags().StringVarP(&compareOutputFormat, "format", "f", "table", "Output format: table or json")
+	cmd.Flags().Float64Var(&compareConfidence, "confidence", 0.95, "Confidence level for flagging significant score changes, in (0, 1)")
 
 	return cmd
 }
 
 // taskComparison holds per-task delta information across result files.
 type taskComparison struct {
-	TaskID      string          `json:"task_id"`
-	DisplayName string          `json:"display_name"`
-	Scores      []float64       `json:"scores"`
-	PassRates   []float64       `json:"pass_rates"`
-	Statuses    []models.Status `json:"statuses"`
-	ScoreDelta  float64         `json:"score_delta"`
-	PassDelta   float64         `json:"pass_rate_delta"`
+	TaskID      string                         `json:"task_id"`
+	DisplayName string                         `json:"display_name"`
+	Scores      []float64                      `json:"scores"`
+	PassRates   []float64                      `json:"pass_rates"`
+	Statuses    []models.Status                `json:"statuses"`
+	ScoreDelta  float64                        `json:"score_delta"`
+	PassDelta   float64                        `json:"pass_rate_delta"`
+	DeltaCI     *statistics.ConfidenceInterval `json:"delta_ci,omitempty"`
+	Significant bool                           `json:"significant"`
 }
 
 // comparisonReport is the full comparison output.
@@ -53,12 +68,17 @@ type comparisonReport struct {
 	TotalTests     []int            `json:"total_tests"`
 	DurationsMs    []int64          `json:"durations_ms"`
 	DurationDeltaM int64            `json:"duration_delta_ms"`
+	Confidence     float64          `json:"confidence,omitempty"`
+	SkillCommits   []string         `json:"skill_commits,omitempty"`
 }
 
 func compareCommandE(_ *cobra.Command, args []string) error {
 	if compareOutputFormat != "table" && compareOutputFormat != "json" {
 		return fmt.Errorf("unsupported format %q: must be table or json", compareOutputFormat)
 	}
+	if compareConfidence <= 0 || compareConfidence >= 1 {
+		return fmt.Errorf("--confidence must be between 0 and 1 (exclusive), got %v", compareConfidence)
+	}
 
 	outcomes := make([]*models.EvaluationOutcome, 0, len(args))
 	for _, path := range args {
@@ -70,6 +90,7 @@ func compareCommandE(_ *cobra.Command, args []string) error {
 	}
 
 	report := buildComparisonReport(args, outcomes)
+	flagSignificantDeltas(report, outcomes, compareConfidence)
 
 	if compareOutputFormat == "json" {
 		return printComparisonJSON(report)
@@ -83,6 +104,11 @@ func loadOutcomeFile(path string) (*models.EvaluationOutcome, error) {
 	if err != nil {
 		return nil, err
 	}
+	if isYAMLPath(path) {
+		if data, err = yamlToJSON(data); err != nil {
+			return nil, err
+		}
+	}
 	var outcome models.EvaluationOutcome
 	if err := json.Unmarshal(data, &outcome); err != nil {
 		return nil, err
@@ -95,7 +121,11 @@ func buildComparisonReport(files []string, outcomes []*models.EvaluationOutcome)
 		Files: files,
 	}
 
+	hasCommit := false
 	for _, o := range outcomes {
+		commit := skillCommit(o)
+		hasCommit = hasCommit || commit != ""
+		report.SkillCommits = append(report.SkillCommits, commit)
 		report.Models = append(report.Models, o.Setup.ModelID)
 		report.AggScores = append(report.AggScores, o.Digest.AggregateScore)
 		report.SuccessRates = append(report.SuccessRates, o.Digest.SuccessRate)
@@ -103,6 +133,10 @@ func buildComparisonReport(files []string, outcomes []*models.EvaluationOutcome)
 		report.DurationsMs = append(report.DurationsMs, o.Digest.DurationMs)
 	}
 
+	if !hasCommit {
+		report.SkillCommits = nil
+	}
+
 	n := len(outcomes)
 	report.AggScoreDelta = report.AggScores[n-1] - report.AggScores[0]
 	report.SuccessRDelta = report.SuccessRates[n-1] - report.SuccessRates[0]
@@ -160,6 +194,64 @@ func buildComparisonReport(files []string, outcomes []*models.EvaluationOutcome)
 	return report
 }
 
+// skillCommit returns the skill commit recorded in the outcome's skill_git
+// metadata, or "".
+func skillCommit(o *models.EvaluationOutcome) string {
+	switch info := o.Metadata["skill_git"].(type) {
+	case map[string]any:
+		commit, _ := info["commit"].(string)
+		return commit
+	case *skillGitInfo:
+		return info.Commit
+	}
+	return ""
+}
+
+// flagSignificantDeltas sets DeltaCI and Significant on each task present in
+// both the first and last outcome with at least min_runs_for_ci scored runs on
+// each side, as the outcome that ran them was configured.
+func flagSignificantDeltas(r *comparisonReport, outcomes []*models.EvaluationOutcome, confidence float64) {
+	r.Confidence = confidence
+	first, last := outcomes[0], outcomes[len(outcomes)-1]
+	for i := range r.TaskDeltas {
+		tc := &r.TaskDeltas[i]
+		before := taskRunScores(first, tc.TaskID)
+		after := taskRunScores(last, tc.TaskID)
+		if len(before) < minRunsForCI(first) || len(after) < minRunsForCI(last) {
+			continue
+		}
+		ci := statistics.BootstrapDiffCIWithSeed(before, after, confidence, compareBootstrapSeed)
+		tc.DeltaCI = &ci
+		tc.Significant = statistics.IsSignificant(ci)
+	}
+}
+
+// minRunsForCI returns the fewest runs o's spec gives a confidence interval.
+func minRunsForCI(o *models.EvaluationOutcome) int {
+	if o.Setup.MinRunsForCI > 0 {
+		return o.Setup.MinRunsForCI
+	}
+	return models.DefaultMinRunsForCI
+}
+
+// taskRunScores returns the per-run scores of a task, leaving out skipped runs.
+func taskRunScores(o *models.EvaluationOutcome, taskID string) []float64 {
+	for _, t := range o.TestOutcomes {
+		if t.TestID != taskID {
+			continue
+		}
+		var scores []float64
+		for _, run := range t.Runs {
+			if run.Status == models.StatusSkipped {
+				continue
+			}
+			scores = append(scores, run.ComputeRunScore())
+		}
+		return scores
+	}
+	return nil
+}
+
 func printComparisonTable(r *comparisonReport) {
 	n := len(r.Files)
 
@@ -171,6 +263,10 @@ func printComparisonTable(r *comparisonReport) {
 
 	// File listing
 	for i, f := range r.Files {
+		if r.SkillCommits != nil && r.SkillCommits[i] != "" {
+			fmt.Printf("  [%d] %s  (model: %s, skill commit: %.12s)\n", i+1, f, r.Models[i], r.SkillCommits[i])
+			continue
+		}
 		fmt.Printf("  [%d] %s  (model: %s)\n", i+1, f, r.Models[i])
 	}
 	fmt.Println()
@@ -217,6 +313,7 @@ func printComparisonTable(r *comparisonReport) {
 	}
 	fmt.Printf("  Delta\n")
 
+	significant := 0
 	for _, tc := range r.TaskDeltas {
 		name := tc.DisplayName
 		if len(name) > 25 {
@@ -236,9 +333,17 @@ func printComparisonTable(r *comparisonReport) {
 		} else if tc.ScoreDelta < 0 {
 			deltaIcon = "↓"
 		}
-		fmt.Printf("  %s%+.4f\n", deltaIcon, tc.ScoreDelta)
+		marker := ""
+		if tc.Significant {
+			marker = " *"
+			significant++
+		}
+		fmt.Printf("  %s%+.4f%s\n", deltaIcon, tc.ScoreDelta, marker)
 	}
 	fmt.Println()
+	if significant > 0 {
+		fmt.Printf("  * significant at %.0f%% confidence (bootstrap over per-run scores)\n\n", r.Confidence*100)
+	}
 }
 
 func printComparisonJSON(r *comparisonReport) error {
@@ -14,6 +14,21 @@ import (
 
 func resetCompareGlobals() {
 	compareOutputFormat = "table"
+	compareConfidence = 0.95
+}
+
+// withRunScores replaces task-001's runs with one run per score.
+func withRunScores(o *models.EvaluationOutcome, scores ...float64) *models.EvaluationOutcome {
+	runs := make([]models.RunResult, len(scores))
+	for i, s := range scores {
+		runs[i] = models.RunResult{
+			RunNumber:   i + 1,
+			Status:      models.StatusPassed,
+			Validations: map[string]models.GraderResults{"check": {Score: s, Passed: s >= 0.5}},
+		}
+	}
+	o.TestOutcomes[0].Runs = runs
+	return o
 }
 
 // createResultFile writes an EvaluationOutcome to a temp JSON file.
@@ -240,6 +255,99 @@ func TestBuildComparisonReport_MissingTask(t *testing.T) {
 	assert.Equal(t, models.StatusNA, report.TaskDeltas[1].Statuses[0])
 }
 
+func TestCompareCommand_ShowsSkillCommits(t *testing.T) {
+	resetCompareGlobals()
+	dir := t.TempDir()
+	o1 := sampleOutcome("gpt-4", 0.8, 1.0, 0.8)
+	o1.Metadata = map[string]any{"skill_git": map[string]any{"commit": "0123456789abcdef0123"}}
+	o2 := sampleOutcome("gpt-4", 0.9, 1.0, 0.9)
+	f1 := createResultFile(t, dir, "r1.json", o1)
+	f2 := createResultFile(t, dir, "r2.json", o2)
+
+	cmd := newCompareCommand()
+	cmd.SetArgs([]string{f1, f2})
+	out := captureStdout(t, func() {
+		require.NoError(t, cmd.Execute())
+	})
+	assert.Contains(t, out, "(model: gpt-4, skill commit: 0123456789ab)")
+	assert.Contains(t, out, "r2.json  (model: gpt-4)\n")
+}
+
+func TestFlagSignificantDeltas(t *testing.T) {
+	tests := []struct {
+		name        string
+		before      []float64
+		after       []float64
+		wantCI      bool
+		significant bool
+	}{
+		{"clear improvement", []float64{0.1, 0.2, 0.1, 0.15, 0.2}, []float64{0.9, 0.95, 0.85, 0.9, 1.0}, true, true},
+		{"clear regression", []float64{0.9, 0.95, 0.85, 0.9, 1.0}, []float64{0.1, 0.2, 0.1, 0.15, 0.2}, true, true},
+		{"noise", []float64{0.2, 0.9, 0.4, 0.7, 0.5}, []float64{0.3, 0.8, 0.6, 0.9, 0.4}, true, false},
+		{"single run", []float64{0.1}, []float64{0.9, 0.95}, false, false},
+		{"below min_runs_for_ci", []float64{0.1, 0.2}, []float64{0.9, 0.95}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcomes := []*models.EvaluationOutcome{
+				withRunScores(sampleOutcome("gpt-4", 0.5, 1.0, 0.5), tt.before...),
+				withRunScores(sampleOutcome("gpt-4o", 0.5, 1.0, 0.5), tt.after...),
+			}
+			report := buildComparisonReport([]string{"r1.json", "r2.json"}, outcomes)
+			flagSignificantDeltas(report, outcomes, 0.95)
+
+			tc := report.TaskDeltas[0]
+			assert.Equal(t, tt.wantCI, tc.DeltaCI != nil)
+			assert.Equal(t, tt.significant, tc.Significant)
+		})
+	}
+}
+
+func TestFlagSignificantDeltas_MinRunsForCI(t *testing.T) {
+	outcomes := []*models.EvaluationOutcome{
+		withRunScores(sampleOutcome("gpt-4", 0.5, 1.0, 0.5), 0.1, 0.2, 0.1),
+		withRunScores(sampleOutcome("gpt-4o", 0.5, 1.0, 0.5), 0.9, 0.95, 0.9),
+	}
+	report := buildComparisonReport([]string{"r1.json", "r2.json"}, outcomes)
+	flagSignificantDeltas(report, outcomes, 0.95)
+	assert.Nil(t, report.TaskDeltas[0].DeltaCI, "3 runs is under the default minimum")
+
+	for _, o := range outcomes {
+		o.Setup.MinRunsForCI = 3
+	}
+	report = buildComparisonReport([]string{"r1.json", "r2.json"}, outcomes)
+	flagSignificantDeltas(report, outcomes, 0.95)
+	assert.NotNil(t, report.TaskDeltas[0].DeltaCI, "the outcomes' min_runs_for_ci lowers it")
+}
+
+func TestCompareCommand_MarksSignificantDeltas(t *testing.T) {
+	resetCompareGlobals()
+	dir := t.TempDir()
+	f1 := createResultFile(t, dir, "r1.json", withRunScores(sampleOutcome("gpt-4", 0.14, 0.0, 0.14), 0.1, 0.2, 0.1, 0.15, 0.2))
+	f2 := createResultFile(t, dir, "r2.json", withRunScores(sampleOutcome("gpt-4o", 0.92, 1.0, 0.92), 0.9, 0.95, 0.85, 0.9, 1.0))
+
+	cmd := newCompareCommand()
+	cmd.SetArgs([]string{f1, f2, "--confidence", "0.99"})
+	out := captureStdout(t, func() {
+		require.NoError(t, cmd.Execute())
+	})
+	assert.Contains(t, out, "+0.7800 *")
+	assert.Contains(t, out, "significant at 99% confidence")
+}
+
+func TestCompareCommand_InvalidConfidence(t *testing.T) {
+	resetCompareGlobals()
+	dir := t.TempDir()
+	f1 := createResultFile(t, dir, "r1.json", sampleOutcome("gpt-4", 0.8, 1.0, 0.8))
+	f2 := createResultFile(t, dir, "r2.json", sampleOutcome("gpt-4o", 0.9, 1.0, 0.9))
+
+	cmd := newCompareCommand()
+	cmd.SetArgs([]string{f1, f2, "--confidence", "1"})
+	cmd.SilenceUsage = true
+	err := cmd.Execute()
+	require.ErrorContains(t, err, "--confidence must be between 0 and 1")
+}
+
 // ---------------------------------------------------------------------------
 // Root command wiring
 // ---------------------------------------------------------------------------
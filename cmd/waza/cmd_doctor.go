@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/projectconfig"
+	"github.com/microsoft/waza/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// doctorStatus is the result of a single doctor check.
+type doctorStatus string
+
+const (
+	doctorPass doctorStatus = "pass"
+	doctorWarn doctorStatus = "warn"
+	doctorFail doctorStatus = "fail"
+)
+
+// doctorCheck is one line of the doctor report. Fix is a remediation hint,
+// shown for warnings and failures.
+type doctorCheck struct {
+	Name   string
+	Status doctorStatus
+	Detail string
+	Fix    string
+}
+
+func newDoctorCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose common environment and setup problems",
+		Long: `Check the local environment for problems that commonly make waza runs
+fail:
+
+  version    the waza build and Go runtime
+  config     whether a .waza.yaml is found
+  workspace  whether skills and eval.yaml files can be detected
+  engine     whether the default engine is known and its credentials work
+  model      whether the engine accepts the default model
+
+Each check reports pass, warn or fail, with a hint for fixing anything that
+isn't passing. The command exits with an error only when a check fails.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			wd, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("getting working directory: %w", err)
+			}
+			checks := runDoctorChecks(wd)
+			printDoctorChecks(cmd.OutOrStdout(), checks)
+
+			failed := 0
+			for _, c := range checks {
+				if c.Status == doctorFail {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d doctor check(s) failed", failed)
+			}
+			return nil
+		},
+	}
+}
+
+// runDoctorChecks runs every check against the workspace at dir.
+func runDoctorChecks(dir string) []doctorCheck {
+	checks := []doctorCheck{{
+		Name:   "version",
+		Status: doctorPass,
+		Detail: fmt.Sprintf("waza %s (%s, %s/%s)", version, runtime.Version(), runtime.GOOS, runtime.GOARCH),
+	}}
+
+	cfg, err := projectconfig.Load(dir)
+	switch {
+	case err != nil:
+		checks = append(checks, doctorCheck{Name: "config", Status: doctorFail, Detail: err.Error(), Fix: "fix or remove the .waza.yaml file"})
+		cfg = projectconfig.New()
+	case cfg.Dir == "":
+		checks = append(checks, doctorCheck{Name: "config", Status: doctorWarn, Detail: "no .waza.yaml found; using built-in defaults", Fix: "run `waza init` to create a project config"})
+	default:
+		checks = append(checks, doctorCheck{Name: "config", Status: doctorPass, Detail: fmt.Sprintf("using %s/.waza.yaml", cfg.Dir)})
+	}
+
+	checks = append(checks, checkDoctorWorkspace(dir))
+	return append(checks, checkDoctorEngine(cfg.Defaults.Engine, cfg.Defaults.Model)...)
+}
+
+func checkDoctorWorkspace(dir string) doctorCheck {
+	wsCtx, err := workspace.DetectContext(dir, configDetectOptions()...)
+	if err != nil {
+		return doctorCheck{Name: "workspace", Status: doctorFail, Detail: err.Error()}
+	}
+	if wsCtx.Type == workspace.ContextNone || len(wsCtx.Skills) == 0 {
+		return doctorCheck{Name: "workspace", Status: doctorWarn, Detail: "no skills detected", Fix: "run from a skill or workspace directory, or create a skill with `waza new`"}
+	}
+
+	withEval := 0
+	for _, si := range wsCtx.Skills {
+		if _, err := workspace.FindEval(wsCtx, si.Name); err == nil {
+			withEval++
+		}
+	}
+	detail := fmt.Sprintf("%d skill(s), %d with an eval.yaml", len(wsCtx.Skills), withEval)
+	if withEval == 0 {
+		return doctorCheck{Name: "workspace", Status: doctorWarn, Detail: detail, Fix: "add an eval suite with `waza new`"}
+	}
+	return doctorCheck{Name: "workspace", Status: doctorPass, Detail: detail}
+}
+
+// checkDoctorEngine starts the engine to verify its credentials, then asks it
+// whether modelID is available.
+func checkDoctorEngine(engineType, modelID string) []doctorCheck {
+	if !slices.Contains(knownEngines, engineType) {
+		return []doctorCheck{
+			{Name: "engine", Status: doctorFail, Detail: fmt.Sprintf("unknown engine %q", engineType), Fix: fmt.Sprintf("set defaults.engine in .waza.yaml to one of: %s", strings.Join(knownEngines, ", "))},
+			{Name: "model", Status: doctorWarn, Detail: fmt.Sprintf("%q not checked: no usable engine", modelID)},
+		}
+	}
+
+	engine, err := newEngine(engineType, modelID)
+	if err != nil {
+		return []doctorCheck{{Name: "engine", Status: doctorFail, Detail: err.Error()}}
+	}
+	ctx := context.Background()
+	if err := engine.Initialize(ctx); err != nil {
+		return []doctorCheck{
+			{Name: "engine", Status: doctorFail, Detail: fmt.Sprintf("%s: %v", engineType, err), Fix: "install the copilot CLI and run `copilot login`"},
+			{Name: "model", Status: doctorWarn, Detail: fmt.Sprintf("%q not checked: engine failed to start", modelID)},
+		}
+	}
+	defer func() {
+		if err := engine.Shutdown(ctx); err != nil {
+			slog.Warn("engine shutdown failed", "error", err)
+		}
+	}()
+	checks := []doctorCheck{{Name: "engine", Status: doctorPass, Detail: fmt.Sprintf("%s started", engineType)}}
+
+	lister, ok := engine.(execution.ModelLister)
+	if !ok {
+		return append(checks, doctorCheck{Name: "model", Status: doctorPass, Detail: fmt.Sprintf("%q (%s accepts any model)", modelID, engineType)})
+	}
+	available, err := lister.ListModels(ctx)
+	switch {
+	case err != nil:
+		return append(checks, doctorCheck{Name: "model", Status: doctorWarn, Detail: fmt.Sprintf("%q not checked: %v", modelID, err)})
+	case !slices.Contains(available, modelID):
+		return append(checks, doctorCheck{Name: "model", Status: doctorFail, Detail: fmt.Sprintf("%q is not available from %s", modelID, engineType), Fix: "set defaults.model in .waza.yaml; `waza run --list-models` shows the available models"})
+	default:
+		return append(checks, doctorCheck{Name: "model", Status: doctorPass, Detail: fmt.Sprintf("%q is available", modelID)})
+	}
+}
+
+func printDoctorChecks(w io.Writer, checks []doctorCheck) {
+	icons := map[doctorStatus]string{doctorPass: "✅", doctorWarn: "⚠️ ", doctorFail: "❌"}
+	for _, c := range checks {
+		fmt.Fprintf(w, "%s %-10s %s\n", icons[c.Status], c.Name, c.Detail) //nolint:errcheck
+		if c.Fix != "" && c.Status != doctorPass {
+			fmt.Fprintf(w, "   fix: %s\n", c.Fix) //nolint:errcheck
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	copilot "github.com/github/copilot-sdk/go"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/projectconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func executeDoctor(t *testing.T) (string, error) {
+	t.Helper()
+	cmd := newRootCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"doctor"})
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestDoctorCommand_MissingConfigIsWarning(t *testing.T) {
+	resetRunGlobals()
+	t.Chdir(t.TempDir())
+	ctrl := gomock.NewController(t)
+	newCopilotClientFn = func(clientOptions *copilot.ClientOptions) execution.CopilotClient {
+		client := newClientMock(ctrl)
+		client.EXPECT().ListModels(gomock.Any()).Return([]copilot.ModelInfo{{ID: projectconfig.DefaultModel}}, nil)
+		return client
+	}
+
+	out, err := executeDoctor(t)
+	require.NoError(t, err, "warnings don't fail the command")
+	assert.Contains(t, out, "✅ version")
+	assert.Contains(t, out, "⚠️  config     no .waza.yaml found")
+	assert.Contains(t, out, "fix: run `waza init`")
+	assert.Contains(t, out, "⚠️  workspace  no skills detected")
+	assert.Contains(t, out, "✅ engine     copilot-sdk started")
+	assert.Contains(t, out, `✅ model      "`+projectconfig.DefaultModel+`" is available`)
+}
+
+func TestDoctorCommand_AllPassing(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".waza.yaml"), []byte("defaults:\n  engine: mock\n  model: test-model\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\nname: doc-skill\ndescription: \"test\"\n---\n# Body\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "eval.yaml"), []byte("name: doc-eval\nskill: doc-skill\ntasks: []\n"), 0o644))
+	t.Chdir(dir)
+
+	out, err := executeDoctor(t)
+	require.NoError(t, err)
+	assert.NotContains(t, out, "⚠️")
+	assert.Contains(t, out, "✅ config")
+	assert.Contains(t, out, "✅ workspace  1 skill(s), 1 with an eval.yaml")
+	assert.Contains(t, out, `✅ model      "test-model" (mock accepts any model)`)
+}
+
+func TestDoctorCommand_UnknownEngineFails(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".waza.yaml"), []byte("defaults:\n  engine: bogus\n"), 0o644))
+	t.Chdir(dir)
+
+	out, err := executeDoctor(t)
+	require.ErrorContains(t, err, "1 doctor check(s) failed")
+	assert.Contains(t, out, `❌ engine     unknown engine "bogus"`)
+	assert.Contains(t, out, "fix: set defaults.engine in .waza.yaml to one of: mock, copilot-sdk")
+}
+
+func TestDoctorCommand_UnavailableModelFails(t *testing.T) {
+	resetRunGlobals()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".waza.yaml"), []byte("defaults:\n  model: no-such-model\n"), 0o644))
+	t.Chdir(dir)
+	ctrl := gomock.NewController(t)
+	newCopilotClientFn = func(clientOptions *copilot.ClientOptions) execution.CopilotClient {
+		client := newClientMock(ctrl)
+		client.EXPECT().ListModels(gomock.Any()).Return([]copilot.ModelInfo{{ID: "gpt-4o"}}, nil)
+		return client
+	}
+
+	out, err := executeDoctor(t)
+	require.ErrorContains(t, err, "1 doctor check(s) failed")
+	assert.Contains(t, out, `❌ model      "no-such-model" is not available from copilot-sdk`)
+	assert.Contains(t, out, "--list-models")
+}
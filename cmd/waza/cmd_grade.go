@@ -82,6 +82,11 @@ func runGrade(ctx context.Context, w, errW io.Writer, specPath, taskID, resultsF
 	if readErr != nil {
 		return fmt.Errorf("failed to read results file: %w", readErr)
 	}
+	if isYAMLPath(resultsFile) {
+		if data, readErr = yamlToJSON(data); readErr != nil {
+			return fmt.Errorf("failed to parse results YAML: %w", readErr)
+		}
+	}
 	var outcome models.EvaluationOutcome
 	if jsonErr := json.Unmarshal(data, &outcome); jsonErr != nil {
 		return fmt.Errorf("failed to parse results JSON: %w", jsonErr)
@@ -92,10 +97,11 @@ func runGrade(ctx context.Context, w, errW io.Writer, specPath, taskID, resultsF
 		runsByTask[to.TestID] = append([]models.RunResult(nil), to.Runs...)
 	}
 
-	effectiveJudgeModel := judgeModel
-	if effectiveJudgeModel == "" {
-		effectiveJudgeModel = spec.Config.JudgeModel
+	// As in waza run, a judge_map entry for the graded model wins over --judge-model
+	if judgeModel != "" {
+		spec.Config.JudgeModel = judgeModel
 	}
+	effectiveJudgeModel := spec.Config.JudgeModelFor(outcome.Setup.ModelID)
 
 	taskResults := make(map[string]models.GradeOutcome)
 	gradedOutcomes := make([]models.TestOutcome, 0, len(allTasks))
@@ -135,8 +141,11 @@ func runGrade(ctx context.Context, w, errW io.Writer, specPath, taskID, resultsF
 		gradedOutcomes = append(gradedOutcomes, models.TestOutcome{
 			TestID:      tc.TestID,
 			DisplayName: tc.DisplayName,
+			Tags:        tc.Tags,
 			Status:      status,
 			Runs:        gradedRuns,
+			XFail:       tc.XFail,
+			XFailReason: tc.XFailReason,
 		})
 	}
 
@@ -181,6 +190,12 @@ func runGrade(ctx context.Context, w, errW io.Writer, specPath, taskID, resultsF
 			}
 		}
 
+		// Recompute stats with the weight mode and trimming the runs were just graded with
+		outcome.Setup.WeightMode = spec.Config.WeightMode
+		outcome.Setup.TrimOutliers = spec.Config.TrimOutliers
+		outcome.Setup.MinRunsForCI = spec.Config.MinRunsForCI
+		outcome.Setup.WeightByTrials = spec.Config.WeightByTrials
+		outcome.Setup.AggregateMethod = spec.Config.EffectiveAggregateMethod()
 		graded := orchestration.RegradeOutcome(&outcome, finalOutcomes, effectiveJudgeModel)
 		if err := saveOutcome(graded, outputFile); err != nil {
 			return fmt.Errorf("failed to save graded outcome: %w", err)
@@ -221,7 +236,7 @@ func gradeTaskRuns(ctx context.Context, spec *models.BenchmarkSpec, tc *models.T
 			return models.GradeOutcome{}, nil, err
 		}
 
-		totalScore += gradedRun.ComputeWeightedRunScore()
+		totalScore += gradedRun.ComputeWeightedRunScore(spec.Config.WeightMode)
 		if gradedRun.Status != models.StatusPassed {
 			allPassed = false
 		}
@@ -242,8 +257,13 @@ func gradeTaskRuns(ctx context.Context, spec *models.BenchmarkSpec, tc *models.T
 		graderAverages[name] = total / graderWeights[name]
 	}
 
+	overallScore := totalScore / float64(len(runs))
+	if threshold := tc.EffectivePassThreshold(spec.Config); threshold > 0 {
+		allPassed = overallScore >= threshold
+	}
+
 	return models.GradeOutcome{
-		OverallScore:   totalScore / float64(len(runs)),
+		OverallScore:   overallScore,
 		Passed:         allPassed,
 		GraderAverages: graderAverages,
 	}, gradedRuns, nil
@@ -286,6 +306,7 @@ func gradeRun(ctx context.Context, spec *models.BenchmarkSpec, tc *models.TestCa
 	if err != nil {
 		return nil, err
 	}
+	spec.ApplyGraderThresholds(graderResults)
 
 	if verbose {
 		for name, result := range graderResults {
@@ -296,12 +317,15 @@ func gradeRun(ctx context.Context, spec *models.BenchmarkSpec, tc *models.TestCa
 	}
 
 	gradedRun.Validations = graderResults
-	gradedRun.Status = models.StatusPassed
-	for _, result := range graderResults {
-		if !result.Passed {
-			gradedRun.Status = models.StatusFailed
-			break
-		}
+	passed, err := tc.RunPassed(graderResults, run.SessionDigest, run.DurationMs)
+	switch {
+	case err != nil:
+		gradedRun.Status = models.StatusError
+		gradedRun.ErrorMsg = err.Error()
+	case passed:
+		gradedRun.Status = models.StatusPassed
+	default:
+		gradedRun.Status = models.StatusFailed
 	}
 
 	return &gradedRun, nil
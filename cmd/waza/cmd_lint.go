@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/microsoft/waza/internal/lint"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/microsoft/waza/internal/projectconfig"
+	"github.com/spf13/cobra"
+)
+
+func newLintCommand() *cobra.Command {
+	var strict bool
+
+	cmd := &cobra.Command{
+		Use:   "lint [eval.yaml | skill-name]",
+		Short: "Warn about common eval.yaml anti-patterns",
+		Long: `Lint an eval spec for patterns that pass schema validation but are
+probably mistakes:
+
+  no-graders                  a task has no graders, so it always passes
+  single-trial-flaky-graders  trials_per_task is 1 with prompt, rubric, behavior or efficiency graders
+  unreachable-tag             a task tag can't be selected with --tags
+  judge-model-unset           prompt or rubric graders have no judge model
+
+Each warning includes a suggested fix. Warnings don't fail the command
+unless --strict is set. With no argument, every eval.yaml in the workspace
+is linted.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLint(cmd.OutOrStdout(), args, strict)
+		},
+	}
+
+	cmd.Flags().BoolVar(&strict, "strict", false, "Exit with an error when any warning is reported")
+
+	return cmd
+}
+
+func runLint(w io.Writer, args []string, strict bool) error {
+	specPaths, err := resolveSpecPaths(args)
+	if err != nil {
+		return err
+	}
+
+	// Judge model precedence matches waza run: WAZA_JUDGE_MODEL > .waza.yaml > spec.
+	defaultJudge := strings.TrimSpace(os.Getenv(envJudgeModelVar))
+	if defaultJudge == "" {
+		if cfg, err := projectconfig.Load("."); err == nil && cfg != nil {
+			defaultJudge = cfg.Defaults.JudgeModel
+		}
+	}
+
+	total := 0
+	for _, sp := range specPaths {
+		spec, err := models.LoadBenchmarkSpec(sp.evalSpecPath)
+		if err != nil {
+			return fmt.Errorf("failed to load spec %s: %w", sp.evalSpecPath, err)
+		}
+		tasks, err := loadTestCases(spec, sp.evalSpecPath)
+		if err != nil {
+			return fmt.Errorf("failed to load tasks for %s: %w", sp.evalSpecPath, err)
+		}
+		if defaultJudge != "" {
+			spec.Config.JudgeModel = defaultJudge
+		}
+
+		warnings := lint.Spec(spec, tasks)
+		total += len(warnings)
+		printLintWarnings(w, sp.evalSpecPath, warnings)
+	}
+
+	if total > 0 && strict {
+		return fmt.Errorf("%d lint warning(s)", total)
+	}
+	return nil
+}
+
+func printLintWarnings(w io.Writer, specPath string, warnings []lint.Warning) {
+	if len(warnings) == 0 {
+		fmt.Fprintf(w, "✅ %s: no lint warnings\n", specPath) //nolint:errcheck
+		return
+	}
+
+	fmt.Fprintf(w, "%s: %d warning(s)\n", specPath, len(warnings)) //nolint:errcheck
+	for _, warn := range warnings {
+		where := ""
+		if warn.Task != "" {
+			where = fmt.Sprintf(" task %s:", warn.Task)
+		}
+		fmt.Fprintf(w, "  ⚠️  [%s]%s %s\n", warn.Rule, where, warn.Message) //nolint:errcheck
+		fmt.Fprintf(w, "      fix: %s\n", warn.Fix)                         //nolint:errcheck
+	}
+}
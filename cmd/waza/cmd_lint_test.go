@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeLintSpec(t *testing.T, spec, task string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "tasks"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tasks", "task.yaml"), []byte(task), 0o644))
+	specPath := filepath.Join(dir, "eval.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(spec), 0o644))
+	t.Chdir(dir)
+	return specPath
+}
+
+func executeLint(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newRootCommand()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(append([]string{"lint"}, args...))
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestLintCommand(t *testing.T) {
+	t.Setenv(envJudgeModelVar, "")
+	specPath := writeLintSpec(t, `name: lint-eval
+skill: test-skill
+config:
+  trials_per_task: 1
+  timeout_seconds: 30
+  executor: mock
+  model: test-model
+graders:
+  - name: judge
+    type: prompt
+    config:
+      prompt: "Is the answer correct?"
+tasks:
+  - "tasks/*.yaml"
+`, `id: task-001
+name: Task
+tags: ["[wip]"]
+inputs:
+  prompt: "hello"
+`)
+
+	out, err := executeLint(t, specPath)
+	require.NoError(t, err, "warnings alone don't fail without --strict")
+	assert.Contains(t, out, "3 warning(s)")
+	assert.Contains(t, out, "[single-trial-flaky-graders]")
+	assert.Contains(t, out, "[unreachable-tag] task task-001:")
+	assert.Contains(t, out, "[judge-model-unset]")
+	assert.Contains(t, out, "fix: set config.trials_per_task")
+
+	_, err = executeLint(t, specPath, "--strict")
+	require.ErrorContains(t, err, "3 lint warning(s)")
+
+	t.Setenv(envJudgeModelVar, "gpt-4o")
+	out, err = executeLint(t, specPath)
+	require.NoError(t, err)
+	assert.NotContains(t, out, "judge-model-unset", "WAZA_JUDGE_MODEL counts as a judge model")
+}
+
+func TestLintCommand_NoGradersAndClean(t *testing.T) {
+	specPath := writeLintSpec(t, `name: lint-eval
+skill: test-skill
+config:
+  trials_per_task: 1
+  timeout_seconds: 30
+  executor: mock
+  model: test-model
+tasks:
+  - "tasks/*.yaml"
+`, `id: task-001
+name: Task
+inputs:
+  prompt: "hello"
+`)
+
+	_, err := executeLint(t, specPath, "--strict")
+	require.ErrorContains(t, err, "1 lint warning(s)")
+
+	out, err := executeLint(t, specPath)
+	require.NoError(t, err)
+	assert.Contains(t, out, "[no-graders] task task-001: task has no graders")
+
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(specPath), "tasks", "task.yaml"), []byte(`id: task-001
+name: Task
+inputs:
+  prompt: "hello"
+graders:
+  - name: has-output
+    type: text
+    config:
+      contains: ["hello"]
+`), 0o644))
+	out, err = executeLint(t, specPath, "--strict")
+	require.NoError(t, err)
+	assert.Contains(t, out, "no lint warnings")
+}
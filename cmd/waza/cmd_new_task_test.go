@@ -305,6 +305,7 @@ func TestNewTaskFromPromptCommand_EndToEndCreatesTaskFile(t *testing.T) {
 				},
 			},
 		},
+		SourcePath: outputPath,
 	}
 
 	require.Equal(t, expected, actual)
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/microsoft/waza/internal/session"
+	"github.com/spf13/cobra"
+)
+
+func newReplaySessionCommand() *cobra.Command {
+	var (
+		outputFile string
+		verboseOut bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "replay-session <session-file> [eval.yaml]",
+		Short: "Re-grade a recorded session log without running the engine",
+		Long: `Re-grade the tasks recorded in a session log with the graders currently
+configured in the eval spec, without calling the engine.
+
+Runs with --session-log record each task's output and transcript. This command
+rebuilds them from the NDJSON log and grades them like 'waza run --replay', so
+grader changes can be checked against fixed agent output. The eval spec defaults
+to the one the session ran; pass it explicitly if it has moved.
+
+When the spec sets config.redact, the log holds the redacted output, so graders
+see the masked text and can score differently from the original run.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionPath := args[0]
+			events, err := session.ReadEvents(sessionPath)
+			if err != nil {
+				return fmt.Errorf("reading session: %w", err)
+			}
+
+			specPath := session.SpecPath(events)
+			if len(args) == 2 {
+				specPath = args[1]
+			}
+			if specPath == "" {
+				return fmt.Errorf("%s doesn't record an eval spec; pass it as the second argument", sessionPath)
+			}
+
+			runArgs := []string{specPath, "--replay", sessionPath}
+			if outputFile != "" {
+				runArgs = append(runArgs, "--output", outputFile)
+			}
+			if verboseOut {
+				runArgs = append(runArgs, "--verbose")
+			}
+			run := newRunCommand()
+			run.SetArgs(runArgs)
+			run.SetOut(cmd.OutOrStdout())
+			run.SetErr(cmd.ErrOrStderr())
+			return run.Execute()
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "Save the re-graded results to JSON, or YAML when the file ends in .yaml or .yml")
+	cmd.Flags().BoolVarP(&verboseOut, "verbose", "v", false, "Show detailed progress")
+
+	return cmd
+}
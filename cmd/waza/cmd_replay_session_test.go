@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/microsoft/waza/internal/models"
+	"github.com/microsoft/waza/internal/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaySessionCommand(t *testing.T) {
+	specPath := createTestSpec(t, "mock")
+	sessionDir := t.TempDir()
+
+	// Record a session of the original run
+	resetRunGlobals()
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--session-log", "--session-dir", sessionDir})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	captureStdout(t, func() { require.NoError(t, cmd.Execute()) })
+
+	sessions, err := session.ListSessions(sessionDir)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	events, err := session.ReadEvents(sessions[0].Path)
+	require.NoError(t, err)
+	transcripts, err := session.Transcripts(events)
+	require.NoError(t, err)
+	require.Contains(t, transcripts, "test-task-001")
+	assert.Equal(t, "Mock response for: Explain this code", transcripts["test-task-001"].FinalOutput)
+
+	// Change the graders, then re-grade the recorded output
+	spec, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+	graders := `graders:
+  - name: mentions-code
+    type: text
+    config:
+      contains: ["Explain this code"]
+  - name: mentions-tests
+    type: text
+    config:
+      contains: ["unit tests"]
+`
+	require.NoError(t, os.WriteFile(specPath, []byte(strings.Replace(string(spec), "tasks:\n", graders+"tasks:\n", 1)), 0o644))
+
+	outPath := filepath.Join(t.TempDir(), "regraded.json")
+	replay := newReplaySessionCommand()
+	replay.SetArgs([]string{sessions[0].Path, "-o", outPath})
+	replay.SetOut(io.Discard)
+	replay.SetErr(io.Discard)
+	captureStdout(t, func() {
+		err = replay.Execute()
+	})
+	_, isTestFailure := errors.AsType[*TestFailureError](err)
+	require.True(t, isTestFailure, "the new failing grader should fail the replayed task: %v", err)
+
+	outcome, err := loadOutcomeFile(outPath)
+	require.NoError(t, err)
+	assert.Equal(t, "replay", outcome.Setup.EngineType)
+	require.Len(t, outcome.TestOutcomes, 1)
+	run := outcome.TestOutcomes[0].Runs[0]
+	assert.Equal(t, "Mock response for: Explain this code", run.FinalOutput)
+	require.Contains(t, run.Validations, "mentions-code")
+	require.Contains(t, run.Validations, "mentions-tests")
+	assert.True(t, run.Validations["mentions-code"].Passed)
+	assert.False(t, run.Validations["mentions-tests"].Passed)
+	assert.Equal(t, models.StatusFailed, outcome.TestOutcomes[0].Status)
+}
+
+func TestReplaySessionCommand_Errors(t *testing.T) {
+	dir := t.TempDir()
+	noSpec := filepath.Join(dir, "no-spec-session.jsonl")
+	logger, err := session.NewJSONLogger(noSpec)
+	require.NoError(t, err)
+	require.NoError(t, logger.Log(session.NewEvent(session.EventTaskStart, session.TaskStartData("t", 1, 1))))
+	require.NoError(t, logger.Close())
+
+	replay := newReplaySessionCommand()
+	replay.SetArgs([]string{noSpec})
+	replay.SetOut(io.Discard)
+	replay.SetErr(io.Discard)
+	require.ErrorContains(t, replay.Execute(), "doesn't record an eval spec")
+
+	// A session without transcripts can't be replayed
+	resetRunGlobals()
+	replay = newReplaySessionCommand()
+	replay.SetArgs([]string{noSpec, createTestSpec(t, "mock")})
+	replay.SetOut(io.Discard)
+	replay.SetErr(io.Discard)
+	captureStdout(t, func() {
+		err = replay.Execute()
+	})
+	require.ErrorContains(t, err, "no task_transcript events")
+}
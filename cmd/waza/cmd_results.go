@@ -40,6 +40,7 @@ func newResultsListCommand() *cobra.Command {
 		sinceStr    string
 		limit       int
 		format      string
+		runTags     []string
 	)
 
 	cmd := &cobra.Command{
@@ -51,6 +52,7 @@ Examples:
   waza results list
   waza results list --skill my-skill --model gpt-4o
   waza results list --since 2026-01-01 --limit 10
+  waza results list --run-tag nightly
   waza results list --format json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if format != "table" && format != "json" {
@@ -71,9 +73,10 @@ Examples:
 			}
 
 			opts := storage.ListOptions{
-				Skill: skillFilter,
-				Model: modelFilter,
-				Limit: limit,
+				Skill:   skillFilter,
+				Model:   modelFilter,
+				Limit:   limit,
+				RunTags: runTags,
 			}
 
 			if sinceStr != "" {
@@ -109,10 +112,10 @@ Examples:
 			}
 
 			// Print table header
-			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%-36s  %-20s  %-24s  %9s  %s\n",
-				"Run ID", "Skill", "Model", "Pass Rate", "Timestamp")
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%-36s  %-20s  %-24s  %9s  %-19s  %s\n",
+				"Run ID", "Skill", "Model", "Pass Rate", "Timestamp", "Run Tags")
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\n",
-				strings.Repeat("-", 36)+"  "+strings.Repeat("-", 20)+"  "+strings.Repeat("-", 24)+"  "+strings.Repeat("-", 9)+"  "+strings.Repeat("-", 19))
+				strings.Repeat("-", 36)+"  "+strings.Repeat("-", 20)+"  "+strings.Repeat("-", 24)+"  "+strings.Repeat("-", 9)+"  "+strings.Repeat("-", 19)+"  "+strings.Repeat("-", 8))
 
 			for _, r := range results {
 				runID := r.RunID
@@ -127,8 +130,8 @@ Examples:
 				if len(model) > 24 {
 					model = model[:21] + "..."
 				}
-				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%-36s  %-20s  %-24s  %8.1f%%  %s\n",
-					runID, skill, model, r.PassRate, r.Timestamp.Format("2006-01-02 15:04:05"))
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%-36s  %-20s  %-24s  %8.1f%%  %-19s  %s\n",
+					runID, skill, model, r.PassRate, r.Timestamp.Format("2006-01-02 15:04:05"), strings.Join(r.RunTags, ","))
 			}
 
 			return nil
@@ -140,6 +143,7 @@ Examples:
 	cmd.Flags().StringVar(&sinceStr, "since", "", "Filter by date (YYYY-MM-DD)")
 	cmd.Flags().IntVar(&limit, "limit", 20, "Maximum number of results to show")
 	cmd.Flags().StringVar(&format, "format", "table", "Output format: table | json")
+	cmd.Flags().StringArrayVar(&runTags, "run-tag", nil, "Only show runs tagged with this run tag (can be repeated; runs must have all of them)")
 
 	return cmd
 }
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/microsoft/waza/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTag_RecordedAndFilterable(t *testing.T) {
+	specPath := createTestSpec(t, "mock")
+	dir := filepath.Dir(specPath)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".waza.yaml"),
+		[]byte("paths:\n  results: results\nstorage:\n  provider: local\n  enabled: true\n"), 0o644))
+	t.Chdir(dir)
+
+	run := func(args ...string) {
+		t.Helper()
+		resetRunGlobals()
+		cmd := newRunCommand()
+		cmd.SetArgs(append([]string{specPath}, args...))
+		var err error
+		captureStdout(t, func() { err = cmd.Execute() })
+		require.NoError(t, err)
+	}
+	run("--run-tag", "nightly", "--run-tag", "pr-1234")
+	run()
+
+	list := func(args ...string) []storage.ResultSummary {
+		t.Helper()
+		cmd := newResultsListCommand()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs(append([]string{"--format", "json"}, args...))
+		require.NoError(t, cmd.Execute())
+		if out.String() == "No results found.\n" {
+			return nil
+		}
+		var results []storage.ResultSummary
+		require.NoError(t, json.Unmarshal(out.Bytes(), &results))
+		return results
+	}
+
+	assert.Len(t, list(), 2)
+	tagged := list("--run-tag", "nightly")
+	require.Len(t, tagged, 1)
+	assert.Equal(t, []string{"nightly", "pr-1234"}, tagged[0].RunTags)
+	assert.Len(t, list("--run-tag", "nightly", "--run-tag", "pr-1234"), 1)
+	assert.Empty(t, list("--run-tag", "weekly"))
+
+	outcome, err := loadOutcomeFile(tagged[0].BlobPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nightly", "pr-1234"}, outcome.RunTags)
+}
+
+func TestRunTag_RejectsInvalid(t *testing.T) {
+	resetRunGlobals()
+	specPath := createTestSpec(t, "mock")
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--run-tag", "a,b"})
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --run-tag")
+}
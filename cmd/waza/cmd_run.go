@@ -1,49 +1,70 @@
 package main
 
 import (
+	"bytes"
 	"cmp"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"log/slog"
 	"maps"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 
 	copilot "github.com/github/copilot-sdk/go"
+	"github.com/joho/godotenv"
 	"github.com/microsoft/waza/internal/cache"
 	"github.com/microsoft/waza/internal/config"
 	"github.com/microsoft/waza/internal/discovery"
 	"github.com/microsoft/waza/internal/execution"
 	"github.com/microsoft/waza/internal/graders"
 	"github.com/microsoft/waza/internal/models"
+	"github.com/microsoft/waza/internal/notify"
 	"github.com/microsoft/waza/internal/orchestration"
 	"github.com/microsoft/waza/internal/projectconfig"
 	"github.com/microsoft/waza/internal/recommend"
 	"github.com/microsoft/waza/internal/reporting"
 	"github.com/microsoft/waza/internal/session"
+	"github.com/microsoft/waza/internal/skillsource"
 	"github.com/microsoft/waza/internal/storage"
+	"github.com/microsoft/waza/internal/transcript"
 	"github.com/microsoft/waza/internal/trigger"
-	"github.com/microsoft/waza/internal/utils"
+	"github.com/microsoft/waza/internal/validation"
+	"github.com/microsoft/waza/internal/webserver"
 	"github.com/microsoft/waza/internal/workspace"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 var (
 	contextDir      string
 	outputPath      string
 	outputDir       string
+	reportDir       string
+	openReport      bool
+	comparisonCSV   string
 	verbose         bool
 	transcriptDir   string
+	transcriptName  string
+	resultsStream   string
 	taskFilters     []string
 	tagFilters      []string
+	graderTags      []string
+	runTags         []string
+	inputFlags      []string
 	parallel        bool
 	workers         int
 	trials          int
@@ -52,33 +73,136 @@ var (
 	enableCache     bool
 	disableCache    bool
 	runCacheDir     string
+	verifyCache     bool
+	verifySample    float64
+	runCacheConfig  projectconfig.CacheConfig
 	modelOverrides  []string
+	engineOverrides []string
 	recommendFlag   bool
 	baselineFlag    bool
+	baselineFile    string
 	suggestFlag     bool
 	sessionLog      bool
 	sessionDir      string
 	noSummary       bool
 	judgeModel      string
 	reporters       []string
+	badgePath       string
+	badgeThreshold  float64
+	metricsPath     string
+	maxGraders      int
+	graderTaskLimit int
+	rampUp          time.Duration
 	discoverFlag    bool
 	strictFlag      bool
 	updateSnapshots bool
 	skipGradersFlag bool
+	skipInvalid     bool
+	baseRef         string
+	compactSummary  bool
+	tuiProgress     bool
+	fixturesLock    string
+	commentTemplate string
+	jsonStdout      bool
+	replayDir       string
+	maxTaskDuration time.Duration
+	printPrompt     bool
+	printConfig     string
+	artifactsDir    string
+	artifactGlobs   []string
+	artifactMaxSize int64
+	listModels      bool
+	strictSchema    bool
+	difficultyPath  string
+	reliabilityPath string
+	skillSource     string
+	fetchedSkill    *skillsource.Skill
+	noTrigger       bool
+	envFile         string
+	onlyTrigger     bool
+	shuffleTasks    bool
+	failOnWarning   bool
+	normalizeOutput bool
+	tasksFrom       string
+	taskRange       []int
+	firstNTasks     int
+	shuffleSeed     uint64
+
+	// badgeThresholdSet records an explicit --badge-threshold, which wins over
+	// the spec's pass_rate_threshold.
+	badgeThresholdSet bool
+	// inputOverrides is --input parsed into key/value pairs.
+	inputOverrides map[string]string
+	// commentTmpl is the parsed --comment-template, loaded once per invocation.
+	commentTmpl *template.Template
 
 	// newCopilotClientFn allows you to override the client used by the copilot engine, for this command.
 	newCopilotClientFn func(clientOptions *copilot.ClientOptions) execution.CopilotClient
 )
 
+// Environment variables consulted when the corresponding flags aren't set, so CI
+// workflows can pick models without templating flags.
+const (
+	envModelsVar     = "WAZA_MODELS"      // comma-separated model IDs, like repeated --model
+	envJudgeModelVar = "WAZA_JUDGE_MODEL" // like --judge-model
+)
+
+// knownEngines lists the engine names accepted by --engine.
+var knownEngines = []string{"mock", "copilot-sdk"}
+
+// engineLabels names each --engine entry for output files and comparisons.
+// Repeated engines get a numeric suffix ("mock", "mock-2") so their results stay apart.
+func engineLabels(engines []string) []string {
+	labels := make([]string, len(engines))
+	seen := make(map[string]int, len(engines))
+	for i, e := range engines {
+		seen[e]++
+		labels[i] = e
+		if seen[e] > 1 {
+			labels[i] = fmt.Sprintf("%s-%d", e, seen[e])
+		}
+	}
+	return labels
+}
+
+// parseModelList splits a comma-separated list of model IDs, dropping blanks.
+func parseModelList(s string) []string {
+	var out []string
+	for m := range strings.SplitSeq(s, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
 // modelResult pairs a model identifier with its evaluation outcome.
 type modelResult struct {
 	modelID string
+	// engine is set only for multi-engine runs (repeated --engine); see label.
+	engine string
+	// env is the config.env_matrix environment, if the spec has one; see label.
+	env     string
 	outcome *models.EvaluationOutcome
 }
 
+// label identifies the result in output file names and comparison tables: the
+// model ID, prefixed with the engine when several engines were evaluated and
+// with the environment for env_matrix runs.
+func (mr modelResult) label() string {
+	label := mr.modelID
+	if mr.engine != "" {
+		label = mr.engine + "_" + label
+	}
+	if mr.env != "" {
+		label = mr.env + "_" + label
+	}
+	return label
+}
+
 func newRunCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "run [eval.yaml | skill-name]",
+		Use:   "run [eval.yaml... | skill-name]",
 		Short: "Run an evaluation benchmark",
 		Long: `Run an evaluation benchmark from a spec file.
 
@@ -90,40 +214,91 @@ With no arguments, uses workspace detection to find eval.yaml automatically:
   - Multi-skill workspace → runs ALL evals sequentially with summary
 
 You can also specify a skill name to run its eval:
-  waza run code-explainer`,
-		Args:          cobra.MaximumNArgs(1),
+  waza run code-explainer
+
+Multiple spec paths are run sequentially as a multi-skill batch:
+  waza run skills/a/eval.yaml skills/b/eval.yaml`,
+		Args:          cobra.ArbitraryArgs,
 		RunE:          runCommandE,
 		SilenceErrors: true,
 	}
 
 	cmd.Flags().StringVar(&contextDir, "context-dir", "", "Context directory for fixtures (default: ./fixtures relative to spec)")
 	cmd.Flags().StringVarP(&outputPath, "output", "o", "", "Output JSON file for results")
+	cmd.Flags().BoolVar(&normalizeOutput, "normalize-output", false, "Zero run IDs, timestamps and durations in saved results so repeated runs diff cleanly")
 	cmd.Flags().StringVar(&outputDir, "output-dir", "", "Directory for structured output (mutually exclusive with --output)")
+	cmd.Flags().StringVar(&reportDir, "report-dir", "", "Directory for a shareable report: results JSON, reporter outputs, transcripts and a static index.html (mutually exclusive with --output and --output-dir)")
+	cmd.Flags().BoolVar(&openReport, "open", false, "Open the --report-dir index.html in the default browser when the run finishes (skipped when stdout isn't a terminal)")
+	cmd.Flags().StringVar(&comparisonCSV, "comparison-csv", "", "Write one CSV row per evaluated model (score, pass rate, duration, tokens, premium requests) to this path")
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output with detailed progress")
 	cmd.Flags().StringVar(&transcriptDir, "transcript-dir", "", "Directory to save per-task transcript JSON files")
+	cmd.Flags().StringVar(&resultsStream, "results-stream", "", "Append each task's outcome to this JSONL file as soon as it completes, so an interrupted run keeps its results")
+	cmd.Flags().StringVar(&transcriptName, "transcript-name-template", "", "Go template for transcript filenames without extension; fields: .TaskName, .TaskID, .Status, .Timestamp (default \""+transcript.DefaultNameTemplate+"\")")
+	cmd.Flags().StringVar(&artifactsDir, "capture-artifacts", "", "Copy each run's workspace into <dir>/<task>/run-<N>/ after grading")
+	cmd.Flags().StringArrayVar(&artifactGlobs, "artifact-glob", nil, "Only capture workspace files matching this glob (can be repeated; requires --capture-artifacts)")
+	cmd.Flags().Int64Var(&artifactMaxSize, "artifact-max-bytes", orchestration.DefaultArtifactMaxBytes, "Maximum bytes captured per run; larger files are skipped")
+	cmd.Flags().StringVar(&printConfig, "print-config", "", "Print the effective configuration (spec, .waza.yaml, environment and flags merged) as yaml or json, then exit without running")
+	cmd.Flags().Lookup("print-config").NoOptDefVal = "yaml"
+	cmd.Flags().BoolVar(&printPrompt, "print-prompt", false, "Print each task's rendered prompt (after template resolution and resource loading) and exit without running the engine")
+	cmd.Flags().StringVar(&difficultyPath, "difficulty-weights", "", "History JSON of per-task failure rates; weights each task by 1 + failure_rate in the weighted score")
+	cmd.Flags().StringVar(&reliabilityPath, "grader-reliability", "", "History JSON of per-grader reliability factors; multiplies each grader's weight by its factor")
+	cmd.Flags().StringVar(&skillSource, "skill-source", "", "Evaluate a packaged skill instead of the spec's skill_directories: skill://name@version (resolved against $"+skillsource.RegistryEnvVar+"), a tarball URL or a local .tar.gz")
+	cmd.Flags().StringVar(&baseRef, "base-ref", "", "Record the skill files changed since this git ref (branch, tag or SHA) in the outcome metadata, next to the skill's current commit")
+	cmd.Flags().StringVar(&envFile, "env-file", "", "Load environment variables from this file instead of the spec directory's .env; variables already set are kept")
+	cmd.Flags().BoolVar(&noTrigger, "no-trigger", false, "Skip the trigger tests discovered next to the eval (trigger_tests.yaml)")
+	cmd.Flags().BoolVar(&onlyTrigger, "only-trigger", false, "Run only the trigger tests in trigger_tests.yaml, skipping the eval tasks")
+	cmd.Flags().BoolVar(&strictSchema, "strict-schema", false, "Validate the eval and task files against the schema before running and abort on any error")
+	cmd.Flags().BoolVar(&listModels, "list-models", false, "List the configured models and the models the engine accepts, then exit")
+	cmd.Flags().StringVar(&replayDir, "replay", "", "Grade transcripts saved by --transcript-dir, or recorded in a --session-log file, instead of executing tasks (no engine calls)")
 	cmd.Flags().StringArrayVar(&taskFilters, "task", nil, "Filter tasks by name/ID glob pattern (can be repeated).")
-	cmd.Flags().StringArrayVar(&tagFilters, "tags", nil, "Filter tasks by tags, using glob patterns (can be repeated)")
+	cmd.Flags().StringVar(&tasksFrom, "tasks-from", "", "CSV dataset to generate tasks from, overriding the spec's tasks and tasks_from (resolved relative to the spec directory)")
+	cmd.Flags().StringArrayVar(&inputFlags, "input", nil, "Set a spec input as key=value, overriding the spec's inputs for template rendering (can be repeated)")
+	cmd.Flags().IntSliceVar(&taskRange, "range", nil, "Only use CSV rows start,end (1-based, inclusive) from the tasks_from dataset, overriding the spec's range")
+	cmd.Flags().IntVar(&firstNTasks, "first-n", 0, "Run only the first N tasks, after --task/--tags filters and --shuffle, for a quick sanity check (0 = all)")
+	cmd.Flags().StringArrayVar(&tagFilters, "tags", nil, "Filter tasks by tags, using glob patterns; key:value tags also match on key (area) or key:value globs (area:*) (can be repeated)")
+	cmd.Flags().StringArrayVar(&runTags, "run-tag", nil, "Tag this run (e.g. nightly, pr-1234) so stored results can be filtered with 'waza results list --run-tag' (can be repeated)")
+	cmd.Flags().StringArrayVar(&graderTags, "grader-tags", nil, "Run only graders whose tags match these glob patterns (e.g. fast); other graders are skipped and don't count toward scores (can be repeated)")
 	cmd.Flags().BoolVar(&parallel, "parallel", false, "Run tasks concurrently")
 	cmd.Flags().IntVar(&workers, "workers", 0, "Number of concurrent workers (default: 4, requires --parallel)")
+	cmd.Flags().DurationVar(&rampUp, "ramp-up", 0, "Stagger parallel worker starts evenly over this window (e.g. 30s) to avoid a burst of requests at the start of the run (default: no ramp)")
+	cmd.Flags().IntVar(&maxGraders, "max-concurrent-graders", 0, "Maximum graders running at once across all tasks, to stay under judge model rate limits (default: unlimited)")
+	cmd.Flags().IntVar(&graderTaskLimit, "grader-concurrency-per-task", 0, "Maximum graders a single task runs at once (default: one at a time)")
 	cmd.Flags().IntVar(&trials, "trials", 0, "Number of trials per task (overrides config.trials_per_task only when explicitly provided)")
 	cmd.Flags().BoolVar(&interpret, "interpret", false, "Print a plain-language interpretation of the results")
-	cmd.Flags().StringVar(&format, "format", "default", "Output format: default, github-comment")
+	cmd.Flags().StringVar(&format, "format", "default", "Output format: default, github-comment, github-actions (workflow annotations for failed tasks)")
+	cmd.Flags().BoolVar(&jsonStdout, "json-stdout", false, "Write only the outcome JSON to stdout, suppressing progress and the summary (not compatible with --format or --verbose)")
+	cmd.Flags().StringVar(&commentTemplate, "comment-template", "", "Go text/template file for the github-comment format, rendered with the evaluation outcome")
 	cmd.Flags().BoolVar(&enableCache, "cache", false, "Enable result caching (default: false)")
 	cmd.Flags().BoolVar(&disableCache, "no-cache", false, "Disable result caching (default)")
 	cmd.Flags().StringVar(&runCacheDir, "cache-dir", ".waza-cache", "Cache directory for storing results")
+	cmd.Flags().BoolVar(&verifyCache, "verify-cache", false, "Debug cache keys: re-execute a random sample of cache hits and fail if their results differ from the cache (requires caching)")
+	cmd.Flags().Float64Var(&verifySample, "verify-cache-sample", 0.1, "Fraction of cache hits (0-1) that --verify-cache re-executes")
 	cmd.Flags().StringArrayVar(&modelOverrides, "model", nil, "Model to use (overrides spec config, can be repeated for comparison)")
+	cmd.Flags().StringArrayVar(&engineOverrides, "engine", nil, "Engine to use (overrides config.executor, can be repeated to compare engines; runs every engine × model pair)")
 	cmd.Flags().BoolVar(&recommendFlag, "recommend", false, "Generate heuristic recommendation after multi-model run")
 	cmd.Flags().BoolVar(&baselineFlag, "baseline", false, "Run A/B comparison: with skills vs without skills")
+	cmd.Flags().StringVar(&baselineFile, "baseline-file", "", "Compare against a saved skills-disabled results JSON instead of rerunning the baseline pass")
 	cmd.Flags().BoolVar(&suggestFlag, "suggest", false, "Generate a Copilot report suggesting skill improvements based on test outcomes")
 	cmd.Flags().BoolVar(&sessionLog, "session-log", false, "Enable session event logging (NDJSON)")
 	cmd.Flags().StringVar(&sessionDir, "session-dir", "", "Directory for session log files (default: current directory)")
-	cmd.Flags().BoolVar(&noSummary, "no-summary", false, "Skip writing combined summary.json for multi-skill runs")
+	cmd.Flags().BoolVar(&noSummary, "no-summary", false, "Skip writing summary.json (multi-skill --output runs and all --output-dir runs)")
 	cmd.Flags().StringVar(&judgeModel, "judge-model", "", "Model for prompt graders (overrides execution model for LLM-as-judge)")
 	cmd.Flags().StringArrayVar(&reporters, "reporter", nil, "Output reporters: json (default), junit:path.xml (can be repeated)")
+	cmd.Flags().StringVar(&badgePath, "badge", "", "Write a shields.io-style SVG badge with the run's pass rate (e.g. \"eval: 92% passing\") to this path")
+	cmd.Flags().Float64Var(&badgeThreshold, "badge-threshold", 0.8, "Pass rate (0-1) at or above which the --badge is green instead of red (default: config.pass_rate_threshold if set, else 0.8)")
+	cmd.Flags().StringVar(&metricsPath, "metrics", "", "Write the run's pass rate, scores, duration and task counts in Prometheus text format (for node_exporter's textfile collector) to this path")
 	cmd.Flags().BoolVar(&discoverFlag, "discover", false, "Walk directory tree to discover and run all skill evals")
-	cmd.Flags().BoolVar(&strictFlag, "strict", false, "With --discover, fail if any SKILL.md lacks an eval.yaml")
+	cmd.Flags().BoolVar(&strictFlag, "strict", false, "With --discover, fail if any SKILL.md lacks an eval.yaml; with --fixtures-lock, fail on fixture drift")
+	cmd.Flags().StringVar(&fixturesLock, "fixtures-lock", "", "Record fixture content hashes in the outcome and compare them with this lock file (created on first use; a prior results JSON also works)")
+	cmd.Flags().BoolVar(&failOnWarning, "fail-on-warning", false, "Fail the run when any warning is reported (e.g. resource load, cache write or hook failures)")
+	cmd.Flags().BoolVar(&shuffleTasks, "shuffle", false, "Run tasks in a random order to expose order dependence (sequential runs only); the seed is printed and saved in the outcome metadata")
+	cmd.Flags().Uint64Var(&shuffleSeed, "seed", 0, "Seed for --shuffle, to reproduce a previous order (default: random)")
 	cmd.Flags().BoolVar(&updateSnapshots, "update-snapshots", false, "Update or create diff grader snapshot files to match current workspace output")
 	cmd.Flags().BoolVar(&skipGradersFlag, "skip-graders", false, "Skip grading (execution only); use with waza grade to grade later")
+	cmd.Flags().BoolVar(&skipInvalid, "skip-invalid-tasks", false, "Warn about and skip task files that fail to parse or validate instead of aborting the run")
+	cmd.Flags().DurationVar(&maxTaskDuration, "max-duration-per-task", 0, "List tasks whose average run duration exceeds this (e.g. 30s) under Slow Tasks in the summary; overrides config.slow_task_ms, never fails the run")
+	cmd.Flags().BoolVar(&compactSummary, "compact", false, "Print one line per task in the results summary")
+	cmd.Flags().BoolVar(&tuiProgress, "tui", false, "Show a live-updating progress table (falls back to simple output when not a terminal; ignored with --verbose)")
 
 	return cmd
 }
@@ -146,8 +321,19 @@ func runCommandE(cmd *cobra.Command, args []string) error {
 	if !cmd.Flags().Changed("cache-dir") && cfg.Cache.Dir != "" {
 		runCacheDir = cfg.Cache.Dir
 	}
-	if !cmd.Flags().Changed("judge-model") && cfg.Defaults.JudgeModel != "" {
-		judgeModel = cfg.Defaults.JudgeModel
+	runCacheConfig = cfg.Cache
+	// Models: --model > WAZA_MODELS > spec. Judge: --judge-model > WAZA_JUDGE_MODEL > .waza.yaml > spec.
+	if !cmd.Flags().Changed("model") {
+		if envModels := parseModelList(os.Getenv(envModelsVar)); len(envModels) > 0 {
+			modelOverrides = envModels
+		}
+	}
+	if !cmd.Flags().Changed("judge-model") {
+		if env := strings.TrimSpace(os.Getenv(envJudgeModelVar)); env != "" {
+			judgeModel = env
+		} else if cfg.Defaults.JudgeModel != "" {
+			judgeModel = cfg.Defaults.JudgeModel
+		}
 	}
 	if !cmd.Flags().Changed("verbose") && cfg.Defaults.Verbose != nil {
 		verbose = *cfg.Defaults.Verbose
@@ -160,12 +346,126 @@ func runCommandE(cmd *cobra.Command, args []string) error {
 	if outputPath != "" && outputDir != "" {
 		return fmt.Errorf("--output and --output-dir are mutually exclusive")
 	}
+	if reportDir != "" && (outputPath != "" || outputDir != "") {
+		return fmt.Errorf("--report-dir is mutually exclusive with --output and --output-dir")
+	}
+	if openReport && reportDir == "" {
+		return fmt.Errorf("--open requires --report-dir")
+	}
+	if transcriptName != "" {
+		if _, err := transcript.ParseNameTemplate(transcriptName); err != nil {
+			return fmt.Errorf("invalid --transcript-name-template: %w", err)
+		}
+	}
 	if cmd.Flags().Changed("trials") && trials < 1 {
 		return fmt.Errorf("--trials must be at least 1")
 	}
+	if replayDir != "" && baselineFlag {
+		return fmt.Errorf("--replay and --baseline are mutually exclusive")
+	}
+	for _, e := range engineOverrides {
+		if !slices.Contains(knownEngines, e) {
+			return fmt.Errorf("unknown --engine %q (supported: %s)", e, strings.Join(knownEngines, ", "))
+		}
+	}
+	if noTrigger && onlyTrigger {
+		return fmt.Errorf("--no-trigger and --only-trigger are mutually exclusive")
+	}
+	if onlyTrigger && replayDir != "" {
+		return fmt.Errorf("--only-trigger and --replay are mutually exclusive")
+	}
+	if onlyTrigger && baselineFlag {
+		return fmt.Errorf("--only-trigger and --baseline are mutually exclusive")
+	}
+	if baselineFile != "" && baselineFlag {
+		return fmt.Errorf("--baseline-file and --baseline are mutually exclusive")
+	}
+	if baselineFile != "" && onlyTrigger {
+		return fmt.Errorf("--only-trigger and --baseline-file are mutually exclusive")
+	}
+	if rampUp < 0 {
+		return fmt.Errorf("--ramp-up must not be negative, got %s", rampUp)
+	}
+	if maxGraders < 0 {
+		return fmt.Errorf("--max-concurrent-graders must not be negative, got %d", maxGraders)
+	}
+	if graderTaskLimit < 0 {
+		return fmt.Errorf("--grader-concurrency-per-task must not be negative, got %d", graderTaskLimit)
+	}
+	if badgeThreshold < 0 || badgeThreshold > 1 {
+		return fmt.Errorf("--badge-threshold must be between 0 and 1, got %g", badgeThreshold)
+	}
+	if cmd.Flags().Changed("badge-threshold") && badgePath == "" {
+		return fmt.Errorf("--badge-threshold requires --badge")
+	}
+	badgeThresholdSet = cmd.Flags().Changed("badge-threshold")
+	if cmd.Flags().Changed("range") && len(taskRange) != 2 {
+		return fmt.Errorf("--range must be two values: start,end")
+	}
+	if firstNTasks < 0 {
+		return fmt.Errorf("--first-n must be non-negative, got %d", firstNTasks)
+	}
+	if cmd.Flags().Changed("verify-cache-sample") && !verifyCache {
+		return fmt.Errorf("--verify-cache-sample requires --verify-cache")
+	}
+	if verifyCache {
+		if !enableCache || disableCache {
+			return fmt.Errorf("--verify-cache requires caching; pass --cache or enable it in .waza.yaml")
+		}
+		if verifySample <= 0 || verifySample > 1 {
+			return fmt.Errorf("--verify-cache-sample must be greater than 0 and at most 1, got %g", verifySample)
+		}
+	}
+	for _, tag := range runTags {
+		if strings.TrimSpace(tag) == "" || strings.Contains(tag, ",") {
+			return fmt.Errorf("invalid --run-tag %q: must be non-empty and can't contain commas", tag)
+		}
+	}
+	overrides, err := parseInputFlags(inputFlags)
+	if err != nil {
+		return err
+	}
+	inputOverrides = overrides
+	if cmd.Flags().Changed("seed") && !shuffleTasks {
+		return fmt.Errorf("--seed requires --shuffle")
+	}
+	if shuffleTasks && !cmd.Flags().Changed("seed") {
+		shuffleSeed = rand.Uint64N(1 << 32)
+	}
+	if len(artifactGlobs) > 0 && artifactsDir == "" {
+		return fmt.Errorf("--artifact-glob requires --capture-artifacts")
+	}
+	if replayDir != "" && len(engineOverrides) > 0 {
+		return fmt.Errorf("--replay and --engine are mutually exclusive")
+	}
+	if printConfig != "" && printConfig != "yaml" && printConfig != "json" {
+		return fmt.Errorf("--print-config must be yaml or json, got %q", printConfig)
+	}
+	if jsonStdout && cmd.Flags().Changed("format") && format != "default" {
+		return fmt.Errorf("--json-stdout and --format %s are mutually exclusive", format)
+	}
+	if jsonStdout && verbose {
+		return fmt.Errorf("--json-stdout and --verbose are mutually exclusive")
+	}
+	if jsonStdout && (len(modelOverrides) > 1 || len(engineOverrides) > 1) {
+		return fmt.Errorf("--json-stdout writes a single outcome and can't be combined with several --model or --engine values")
+	}
+	if jsonStdout && discoverFlag {
+		return fmt.Errorf("--json-stdout writes a single outcome and can't be combined with --discover")
+	}
+	commentTmpl = nil
+	if commentTemplate != "" {
+		if format != "github-comment" {
+			return fmt.Errorf("--comment-template requires --format github-comment")
+		}
+		commentTmpl, err = LoadCommentTemplate(commentTemplate)
+		if err != nil {
+			return err
+		}
+	}
 
 	// Apply config defaults for output-dir when not explicitly set
-	if outputDir == "" && !cmd.Flags().Changed("output-dir") && outputPath == "" {
+	if outputDir == "" && !cmd.Flags().Changed("output-dir") && outputPath == "" && reportDir == "" {
 		wd, _ := os.Getwd() //nolint:errcheck
 		if cfg, err := projectconfig.Load(wd); err == nil && cfg != nil && cfg.Paths.Results != projectconfig.DefaultResultsDir {
 			resultsPath := cfg.Paths.Results
@@ -176,7 +476,15 @@ func runCommandE(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// A report bundles the transcripts unless they're sent elsewhere
+	if reportDir != "" && transcriptDir == "" {
+		transcriptDir = filepath.Join(reportDir, reportTranscriptsDir)
+	}
+
 	// Handle --discover mode
+	if discoverFlag && skillSource != "" {
+		return fmt.Errorf("--skill-source evaluates one skill and can't be combined with --discover")
+	}
 	if discoverFlag {
 		return runDiscoverMode(cmd, args)
 	}
@@ -186,6 +494,9 @@ func runCommandE(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if jsonStdout && len(specPaths) > 1 {
+		return fmt.Errorf("--json-stdout writes a single outcome and can't be used to run %d skills at once", len(specPaths))
+	}
 
 	var skillFolders []string
 
@@ -224,6 +535,52 @@ func runCommandE(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if strictSchema {
+		if err := validateSpecSchemas(specPaths); err != nil {
+			return err
+		}
+	}
+
+	if printPrompt {
+		for _, sp := range specPaths {
+			if err := printSpecPrompts(sp.evalSpecPath, skillFolders); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if printConfig != "" {
+		for _, sp := range specPaths {
+			if err := printEffectiveConfig(cmd, os.Stdout, sp.evalSpecPath); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if listModels {
+		for _, sp := range specPaths {
+			if err := listSpecModels(os.Stdout, sp.evalSpecPath); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if skillSource != "" {
+		fetched, err := skillsource.Fetch(cmd.Context(), skillSource, skillsource.DefaultLimits)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			fetched.Close() //nolint:errcheck
+			fetchedSkill = nil
+		}()
+		fetchedSkill = fetched
+		statusf("Using skill %s from %s\n", fetched.Name, skillSource)
+	}
+
 	if len(specPaths) == 1 {
 		results, err := runCommandForSpec(cmd, specPaths[0], skillFolders)
 
@@ -236,14 +593,33 @@ func runCommandE(cmd *cobra.Command, args []string) error {
 			}
 		}
 
+		skillResults := []skillRunResult{{skillName: specPaths[0].skillName, outcomes: results}}
+
+		// A multi-model run of one skill gets the same combined summary as a multi-skill run
+		if outputPath != "" && !noSummary && len(results) > 1 {
+			if wErr := writeCombinedSummary(skillResults); wErr != nil {
+				return wErr
+			}
+		}
+
 		// Write structured directory output when --output-dir is specified
 		if outputDir != "" {
-			if wErr := writeOutputDir(outputDir, []skillRunResult{
-				{skillName: specPaths[0].skillName, outcomes: results},
-			}); wErr != nil {
+			if wErr := writeOutputDir(outputDir, skillResults); wErr != nil {
 				return fmt.Errorf("failed to write output directory: %w", wErr)
 			}
 		}
+		if reportDir != "" {
+			if wErr := writeReportDir(reportDir, skillResults); wErr != nil {
+				return fmt.Errorf("failed to write report directory: %w", wErr)
+			}
+			openReportIndex(reportDir)
+		}
+
+		if comparisonCSV != "" {
+			if wErr := writeComparisonCSV(comparisonCSV, skillResults); wErr != nil {
+				return fmt.Errorf("failed to write comparison CSV: %w", wErr)
+			}
+		}
 
 		// Auto-upload after all local writes succeed
 		autoUploadOutcomes(cmd, cfg, results)
@@ -254,11 +630,13 @@ func runCommandE(cmd *cobra.Command, args []string) error {
 	// Suppress per-skill output during the loop — we'll write it after
 	savedOutputPath := outputPath
 	outputPath = ""
+	savedMetricsPath := metricsPath
+	metricsPath = ""
 
 	var allSkillResults []skillRunResult
 	var lastErr error
 	for _, sp := range specPaths {
-		fmt.Printf("\n=== %s ===\n\n", sp.skillName)
+		statusf("\n=== %s ===\n\n", sp.skillName)
 		result := skillRunResult{skillName: sp.skillName}
 		outcomes, err := runCommandForSpec(cmd, sp, skillFolders)
 		result.outcomes = outcomes
@@ -276,21 +654,22 @@ func runCommandE(cmd *cobra.Command, args []string) error {
 
 	// Restore outputPath for per-skill output writing
 	outputPath = savedOutputPath
+	metricsPath = savedMetricsPath
+	if err := writeMetrics(flattenSkillResults(allSkillResults)); err != nil {
+		return err
+	}
 
 	if len(allSkillResults) > 1 {
 		printSkillRunSummary(allSkillResults)
+		if isCrossProductRun(allSkillResults) {
+			printCrossProductMatrix(allSkillResults)
+		}
 
 		// Write combined summary.json if --output is specified and --no-summary is not set
 		if outputPath != "" && !noSummary {
-			summary := buildMultiSkillSummary(allSkillResults)
-			ext := filepath.Ext(outputPath)
-			base := strings.TrimSuffix(outputPath, ext)
-			summaryPath := fmt.Sprintf("%s_summary%s", base, ext)
-
-			if err := saveSummary(summary, summaryPath); err != nil {
-				return fmt.Errorf("failed to save summary: %w", err)
+			if err := writeCombinedSummary(allSkillResults); err != nil {
+				return err
 			}
-			fmt.Printf("Combined summary saved to: %s\n", summaryPath)
 		}
 	}
 
@@ -307,11 +686,11 @@ func runCommandE(cmd *cobra.Command, args []string) error {
 				if mr.outcome == nil {
 					continue
 				}
-				perSkillPath := buildOutputPath(base, ext, skillResult.skillName, mr.modelID, true, multiModel)
+				perSkillPath := buildOutputPath(base, ext, skillResult.skillName, mr.label(), true, multiModel)
 				if err := saveOutcome(mr.outcome, perSkillPath); err != nil {
-					return fmt.Errorf("failed to save output for skill %s, model %s: %w", skillResult.skillName, mr.modelID, err)
+					return fmt.Errorf("failed to save output for skill %s, model %s: %w", skillResult.skillName, mr.label(), err)
 				}
-				fmt.Printf("Results saved to: %s\n", perSkillPath)
+				statusf("Results saved to: %s\n", perSkillPath)
 			}
 		}
 	}
@@ -322,6 +701,18 @@ func runCommandE(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("failed to write output directory: %w", err)
 		}
 	}
+	if reportDir != "" {
+		if err := writeReportDir(reportDir, allSkillResults); err != nil {
+			return fmt.Errorf("failed to write report directory: %w", err)
+		}
+		openReportIndex(reportDir)
+	}
+
+	if comparisonCSV != "" {
+		if err := writeComparisonCSV(comparisonCSV, allSkillResults); err != nil {
+			return fmt.Errorf("failed to write comparison CSV: %w", err)
+		}
+	}
 
 	// Auto-upload to configured storage
 	for _, sr := range allSkillResults {
@@ -343,7 +734,19 @@ type skillRunResult struct {
 }
 
 // resolveSpecPaths resolves eval.yaml paths from args or workspace detection.
+// When more than one arg is given, each must be a path to a spec file.
 func resolveSpecPaths(args []string) ([]skillSpecPath, error) {
+	if len(args) > 1 {
+		paths := make([]skillSpecPath, 0, len(args))
+		for _, arg := range args {
+			if !workspace.LooksLikePath(arg) {
+				return nil, fmt.Errorf("%q is not a spec path; multiple arguments must all be eval.yaml paths", arg)
+			}
+			paths = append(paths, skillSpecPath{evalSpecPath: arg, skillName: skillNameForSpecPath(arg)})
+		}
+		return paths, nil
+	}
+
 	if len(args) > 0 {
 		arg := args[0]
 		// If it looks like a path, use directly
@@ -378,7 +781,7 @@ func resolveSpecPaths(args []string) ([]skillSpecPath, error) {
 			if len(wsCtx.Skills) == 1 {
 				return nil, err
 			}
-			fmt.Printf("⚠️  Skipping %s: %v\n", si.Name, err)
+			statusf("⚠️  Skipping %s: %v\n", si.Name, err)
 			continue
 		}
 		paths = append(paths, skillSpecPath{evalSpecPath: evalPath, skillName: si.Name})
@@ -391,6 +794,19 @@ func resolveSpecPaths(args []string) ([]skillSpecPath, error) {
 	return paths, nil
 }
 
+// skillNameForSpecPath returns a display name for an explicitly provided spec path.
+// It prefers the spec's skill field, falling back to the spec's directory name.
+func skillNameForSpecPath(specPath string) string {
+	if spec, err := models.LoadBenchmarkSpec(specPath); err == nil && spec.SkillName != "" {
+		return spec.SkillName
+	}
+	abs, err := filepath.Abs(specPath)
+	if err != nil {
+		abs = specPath
+	}
+	return filepath.Base(filepath.Dir(abs))
+}
+
 func printSkillRunSummary(results []skillRunResult) {
 	fmt.Println()
 	fmt.Println("═══════════════════════════════════════════════")
@@ -437,6 +853,74 @@ func printSkillRunSummary(results []skillRunResult) {
 	fmt.Println()
 }
 
+// isCrossProductRun reports whether any skill in a multi-skill run was evaluated
+// against more than one model.
+func isCrossProductRun(results []skillRunResult) bool {
+	for _, r := range results {
+		if len(r.outcomes) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// matrixCell renders a pass rate with a symbol: ✓ all passed, ~ at least half, ✗ below half.
+func matrixCell(outcome *models.EvaluationOutcome) string {
+	if outcome == nil {
+		return "—"
+	}
+	rate := outcome.Digest.SuccessRate
+	symbol := "✗"
+	switch {
+	case rate >= 1:
+		symbol = "✓"
+	case rate >= 0.5:
+		symbol = "~"
+	}
+	return fmt.Sprintf("%s %.0f%%", symbol, rate*100)
+}
+
+// printCrossProductMatrix renders a skills (rows) × models (columns) matrix of
+// pass rates for multi-skill, multi-model runs. Missing cells render as "—".
+func printCrossProductMatrix(results []skillRunResult) {
+	modelSet := make(map[string]bool)
+	for _, r := range results {
+		for _, mr := range r.outcomes {
+			modelSet[mr.label()] = true
+		}
+	}
+	modelIDs := slices.Sorted(maps.Keys(modelSet))
+
+	const skillWidth, cellWidth = 25, 16
+
+	fmt.Println("═══════════════════════════════════════════════")
+	fmt.Println(" SKILL × MODEL PASS RATES")
+	fmt.Println("═══════════════════════════════════════════════")
+	fmt.Println()
+
+	fmt.Print(padRight("Skill", skillWidth))
+	for _, m := range modelIDs {
+		fmt.Print(padRight(truncateName(m, cellWidth-1), cellWidth))
+	}
+	fmt.Println()
+	fmt.Println(strings.Repeat("─", skillWidth+cellWidth*len(modelIDs)))
+
+	for _, r := range results {
+		byModel := make(map[string]*models.EvaluationOutcome, len(r.outcomes))
+		for _, mr := range r.outcomes {
+			byModel[mr.label()] = mr.outcome
+		}
+		fmt.Print(padRight(truncateName(r.skillName, skillWidth-1), skillWidth))
+		for _, m := range modelIDs {
+			fmt.Print(padRight(matrixCell(byModel[m]), cellWidth))
+		}
+		fmt.Println()
+	}
+	fmt.Println()
+	fmt.Println("Legend: ✓ all passed  ~ ≥50% passed  ✗ <50% passed  — not run")
+	fmt.Println()
+}
+
 // runCommandForSpec runs the evaluation for a single spec path.
 // defaultSkills - skills found under the workspace folder, specified by .waza.yaml
 func runCommandForSpec(cmd *cobra.Command, sp skillSpecPath, defaultSkills []string) ([]modelResult, error) {
@@ -448,28 +932,7 @@ func runCommandForSpec(cmd *cobra.Command, sp skillSpecPath, defaultSkills []str
 		return nil, fmt.Errorf("failed to load spec: %w", err)
 	}
 
-	// CLI flags override spec config
-	if parallel {
-		spec.Config.Concurrent = true
-	}
-	if workers > 0 {
-		spec.Config.Workers = workers
-	}
-	// Dual-path: when invoked via CLI, use Changed() so default 0 doesn't
-	// override spec; when cmd is nil (tests), fall back to trials > 0.
-	shouldOverrideTrials := trials > 0
-	if cmd != nil {
-		shouldOverrideTrials = cmd.Flags().Changed("trials")
-	}
-	if shouldOverrideTrials {
-		spec.Config.TrialsPerTask = trials
-	}
-	if baselineFlag {
-		spec.Baseline = true
-	}
-	if judgeModel != "" {
-		spec.Config.JudgeModel = judgeModel
-	}
+	applyRunFlags(cmd, spec)
 
 	// Determine the list of models to evaluate
 	modelsToRun := []string{spec.Config.ModelID}
@@ -488,28 +951,67 @@ func runCommandForSpec(cmd *cobra.Command, sp skillSpecPath, defaultSkills []str
 		}
 	}
 
-	multiModel := len(modelsToRun) > 1
+	// Determine the engines to evaluate; labels are only needed to tell several apart
+	enginesToRun := []string{spec.Config.EngineType}
+	var labels []string
+	if len(engineOverrides) > 0 {
+		enginesToRun = engineOverrides
+		if len(engineOverrides) > 1 {
+			labels = engineLabels(engineOverrides)
+		}
+	}
+
+	// Each env_matrix environment repeats the whole engine × model set
+	var envsToRun []*models.MatrixEnvironment
+	for i := range spec.Config.EnvMatrix {
+		envsToRun = append(envsToRun, &spec.Config.EnvMatrix[i])
+	}
+	if len(envsToRun) == 0 {
+		envsToRun = []*models.MatrixEnvironment{nil}
+	}
+
+	multiModel := len(envsToRun)*len(modelsToRun)*len(enginesToRun) > 1
+	if jsonStdout && multiModel {
+		return nil, fmt.Errorf("--json-stdout writes a single outcome and can't be combined with env_matrix")
+	}
 
-	// Run evaluation for each model, collecting results
+	// Run evaluation for each environment × engine × model, collecting results
 	var allResults []modelResult
 	var lastErr error
 
-	for _, modelID := range modelsToRun {
-		// Override spec model for this iteration
-		spec.Config.ModelID = modelID
+	for _, env := range envsToRun {
+		envName := ""
+		if env != nil {
+			envName = env.Name
+			statusf("Environment: %s\n", envName)
+		}
+		for i, engineType := range enginesToRun {
+			engineLabel := ""
+			if labels != nil {
+				engineLabel = labels[i]
+			}
+			for _, modelID := range modelsToRun {
+				// Override spec engine and model for this iteration
+				spec.Config.EngineType = engineType
+				spec.Config.ModelID = modelID
 
-		outcome, err := runSingleModel(cmd, spec, specPath, defaultSkills)
-		if err != nil {
-			var testErr *TestFailureError
-			if errors.As(err, &testErr) {
-				// Test failures are recorded but don't stop a multi-model run
-				allResults = append(allResults, modelResult{modelID: modelID, outcome: outcome})
-				lastErr = err
-				continue
+				result := modelResult{modelID: modelID, engine: engineLabel, env: envName}
+				outcome, err := runSingleModel(cmd, spec, specPath, defaultSkills, env)
+				if err != nil {
+					var testErr *TestFailureError
+					if errors.As(err, &testErr) {
+						// Test failures are recorded but don't stop a multi-model run
+						result.outcome = outcome
+						allResults = append(allResults, result)
+						lastErr = err
+						continue
+					}
+					return nil, err
+				}
+				result.outcome = outcome
+				allResults = append(allResults, result)
 			}
-			return nil, err
 		}
-		allResults = append(allResults, modelResult{modelID: modelID, outcome: outcome})
 	}
 
 	// Print comparison table when multiple models were evaluated
@@ -540,19 +1042,16 @@ func runCommandForSpec(cmd *cobra.Command, sp skillSpecPath, defaultSkills []str
 		base := strings.TrimSuffix(outputPath, ext)
 		for _, mr := range allResults {
 			// Use buildOutputPath for consistency (multiSkill=false for single-skill context)
-			perModelPath := buildOutputPath(base, ext, "", mr.modelID, false, true)
+			perModelPath := buildOutputPath(base, ext, "", mr.label(), false, true)
 			if err := saveOutcome(mr.outcome, perModelPath); err != nil {
-				return nil, fmt.Errorf("failed to save output for model %s: %w", mr.modelID, err)
+				return nil, fmt.Errorf("failed to save output for model %s: %w", mr.label(), err)
 			}
-			fmt.Printf("Results saved to: %s\n", perModelPath)
+			statusf("Results saved to: %s\n", perModelPath)
 		}
 	}
 
-	if lastErr != nil {
-		return allResults, lastErr
-	}
-
-	// Write reporter outputs for the last model result
+	// Reports, badges and metrics matter most for failing runs, so they're
+	// written before a test failure is returned
 	if len(allResults) > 0 {
 		last := allResults[len(allResults)-1]
 		if last.outcome != nil {
@@ -561,13 +1060,16 @@ func runCommandForSpec(cmd *cobra.Command, sp skillSpecPath, defaultSkills []str
 			}
 		}
 	}
+	if err := writeMetrics(allResults); err != nil {
+		return allResults, err
+	}
 
-	return allResults, nil
+	return allResults, lastErr
 }
 
-// runSingleModel executes a benchmark for one model and returns the outcome.
-// It prints the per-model summary and saves output for single-model runs.
-func runSingleModel(cmd *cobra.Command, spec *models.BenchmarkSpec, specPath string, defaultSkills []string) (*models.EvaluationOutcome, error) {
+// newRunConfig builds the benchmark config for spec, resolving the spec and
+// fixture directories and falling back to the workspace skill directories.
+func newRunConfig(spec *models.BenchmarkSpec, specPath string, defaultSkills []string) *config.BenchmarkConfig {
 	// Get spec directory for resolving relative paths
 	specDir := filepath.Dir(specPath)
 	if !filepath.IsAbs(specDir) {
@@ -593,22 +1095,392 @@ func runSingleModel(cmd *cobra.Command, spec *models.BenchmarkSpec, specPath str
 		spec.Config.SkillPaths = append(spec.Config.SkillPaths, defaultSkills...)
 	}
 
-	// Create config with both directories
-	cfg := config.NewBenchmarkConfig(spec,
+	return config.NewBenchmarkConfig(spec,
 		config.WithSpecDir(specDir),
 		config.WithFixtureDir(fixtureDir),
 		config.WithVerbose(verbose),
 		config.WithOutputPath(outputPath),
 		config.WithTranscriptDir(transcriptDir),
+		config.WithTranscriptNameTemplate(transcriptName),
+	)
+}
+
+// printSpecPrompts prints the rendered prompt for every selected task in the
+// spec at specPath without starting an engine.
+func printSpecPrompts(specPath string, defaultSkills []string) error {
+	spec, err := models.LoadBenchmarkSpec(specPath)
+	if err != nil {
+		return fmt.Errorf("failed to load spec: %w", err)
+	}
+	applyInputOverrides(spec)
+
+	runner := orchestration.NewTestRunner(newRunConfig(spec, specPath, defaultSkills), nil,
+		orchestration.WithTaskFilters(taskFilters...),
+		orchestration.WithTagFilters(tagFilters...),
+		orchestration.WithFirstN(firstNTasks),
 	)
+	return runner.PrintPrompts(os.Stdout)
+}
+
+// validateSpecSchemas checks each eval file and its tasks against the JSON
+// schema, returning every error found across all specs.
+func validateSpecSchemas(specPaths []skillSpecPath) error {
+	var lines []string
+	for _, sp := range specPaths {
+		evalErrs, taskErrs, err := validation.ValidateEvalFile(sp.evalSpecPath)
+		if err != nil {
+			return err
+		}
+		for _, e := range evalErrs {
+			lines = append(lines, fmt.Sprintf("  %s: %s", sp.evalSpecPath, e))
+		}
+		taskFiles := slices.Sorted(maps.Keys(taskErrs))
+		for _, taskFile := range taskFiles {
+			for _, e := range taskErrs[taskFile] {
+				lines = append(lines, fmt.Sprintf("  %s: %s", filepath.Join(filepath.Dir(sp.evalSpecPath), taskFile), e))
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return fmt.Errorf("schema validation failed (--strict-schema):\n%s", strings.Join(lines, "\n"))
+}
+
+// newEngine creates an uninitialized engine of the given type.
+func newEngine(engineType, modelID string) (execution.AgentEngine, error) {
+	switch engineType {
+	case "mock", "replay":
+		return execution.NewMockEngine(modelID), nil
+	case "copilot-sdk":
+		return execution.NewCopilotEngineBuilder(modelID, &execution.CopilotEngineBuilderOptions{
+			NewCopilotClient: newCopilotClientFn, // if nil, uses the real function, otherwise overridable for tests.
+		}).Build(), nil
+	default:
+		return nil, fmt.Errorf("unknown engine type: %s", engineType)
+	}
+}
+
+// listSpecModels prints the models configured for a spec (eval.yaml, WAZA_MODELS
+// or --model) and, for engines that can enumerate them, the models each engine
+// accepts. Engines that can't list models are reported without failing.
+func listSpecModels(w io.Writer, specPath string) error {
+	spec, err := models.LoadBenchmarkSpec(specPath)
+	if err != nil {
+		return fmt.Errorf("failed to load spec: %w", err)
+	}
+
+	configured := []string{spec.Config.ModelID}
+	if len(modelOverrides) > 0 {
+		configured = modelOverrides
+	}
+	fmt.Fprintf(w, "Configured models (%s):\n", spec.Name)
+	for _, m := range configured {
+		if m == "" {
+			m = "(engine default)"
+		}
+		fmt.Fprintf(w, "  %s\n", m)
+	}
+
+	enginesToList := []string{spec.Config.EngineType}
+	if len(engineOverrides) > 0 {
+		enginesToList = engineOverrides
+	}
+
+	seen := make(map[string]bool, len(enginesToList))
+	for _, engineType := range enginesToList {
+		if seen[engineType] {
+			continue
+		}
+		seen[engineType] = true
+
+		available, err := listEngineModels(engineType, spec.Config.ModelID)
+		if err != nil {
+			fmt.Fprintf(w, "Available models (%s): unavailable: %v\n", engineType, err)
+			continue
+		}
+		if available == nil {
+			fmt.Fprintf(w, "Available models (%s): engine does not enumerate models; use the configured models above\n", engineType)
+			continue
+		}
+		fmt.Fprintf(w, "Available models (%s):\n", engineType)
+		for _, m := range available {
+			fmt.Fprintf(w, "  %s\n", m)
+		}
+	}
+	return nil
+}
+
+// applyRunFlags overrides spec config with the run command's flags.
+func applyRunFlags(cmd *cobra.Command, spec *models.BenchmarkSpec) {
+	if parallel {
+		spec.Config.Concurrent = true
+	}
+	if workers > 0 {
+		spec.Config.Workers = workers
+	}
+	// Dual-path: when invoked via CLI, use Changed() so default 0 doesn't
+	// override spec; when cmd is nil (tests), fall back to trials > 0.
+	shouldOverrideTrials := trials > 0
+	if cmd != nil {
+		shouldOverrideTrials = cmd.Flags().Changed("trials")
+	}
+	if shouldOverrideTrials {
+		spec.Config.TrialsPerTask = trials
+	}
+	if baselineFlag {
+		spec.Baseline = true
+	}
+	if fetchedSkill != nil {
+		spec.Config.SkillPaths = []string{fetchedSkill.Dir}
+	}
+	if judgeModel != "" {
+		spec.Config.JudgeModel = judgeModel
+	}
+	if tasksFrom != "" {
+		spec.TasksFrom = tasksFrom
+		spec.Range = [2]int{}
+	}
+	if len(taskRange) == 2 {
+		spec.Range = [2]int{taskRange[0], taskRange[1]}
+	}
+	if maxTaskDuration > 0 {
+		spec.Config.SlowTaskMs = maxTaskDuration.Milliseconds()
+	}
+	applyInputOverrides(spec)
+}
+
+// parseInputFlags parses --input key=value flags; a repeated key keeps the
+// last value.
+func parseInputFlags(flags []string) (map[string]string, error) {
+	if len(flags) == 0 {
+		return nil, nil
+	}
+	inputs := make(map[string]string, len(flags))
+	for _, f := range flags {
+		key, value, ok := strings.Cut(f, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("--input %q must be key=value", f)
+		}
+		inputs[key] = value
+	}
+	return inputs, nil
+}
+
+// applyInputOverrides merges --input over the spec's inputs, so CLI values
+// win for template rendering of both file and CSV tasks.
+func applyInputOverrides(spec *models.BenchmarkSpec) {
+	if len(inputOverrides) == 0 {
+		return
+	}
+	if spec.Inputs == nil {
+		spec.Inputs = make(map[string]string, len(inputOverrides))
+	}
+	maps.Copy(spec.Inputs, inputOverrides)
+}
+
+// effectiveConfig is the configuration a run would use, as printed by
+// --print-config.
+type effectiveConfig struct {
+	Spec          string            `yaml:"spec" json:"spec"`
+	Models        []string          `yaml:"models" json:"models"`
+	Engines       []string          `yaml:"engines" json:"engines"`
+	JudgeModel    string            `yaml:"judge_model,omitempty" json:"judge_model,omitempty"`
+	JudgeMap      map[string]string `yaml:"judge_map,omitempty" json:"judge_map,omitempty"`
+	Parallel      bool              `yaml:"parallel" json:"parallel"`
+	Workers       int               `yaml:"workers,omitempty" json:"workers,omitempty"`
+	TrialsPerTask int               `yaml:"trials_per_task" json:"trials_per_task"`
+	TimeoutSec    int               `yaml:"timeout_seconds" json:"timeout_seconds"`
+	Cache         struct {
+		Enabled bool   `yaml:"enabled" json:"enabled"`
+		Dir     string `yaml:"dir" json:"dir"`
+	} `yaml:"cache" json:"cache"`
+	Filters struct {
+		Tasks       []string `yaml:"tasks,omitempty" json:"tasks,omitempty"`
+		Tags        []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+		GraderTags  []string `yaml:"grader_tags,omitempty" json:"grader_tags,omitempty"`
+		FirstN      int      `yaml:"first_n,omitempty" json:"first_n,omitempty"`
+		TasksFrom   string   `yaml:"tasks_from,omitempty" json:"tasks_from,omitempty"`
+		Range       []int    `yaml:"range,omitempty" json:"range,omitempty"`
+		NoTrigger   bool     `yaml:"no_trigger,omitempty" json:"no_trigger,omitempty"`
+		OnlyTrigger bool     `yaml:"only_trigger,omitempty" json:"only_trigger,omitempty"`
+	} `yaml:"filters" json:"filters"`
+}
+
+// printEffectiveConfig writes the configuration a run of specPath would use,
+// in the --print-config format.
+func printEffectiveConfig(cmd *cobra.Command, w io.Writer, specPath string) error {
+	spec, err := models.LoadBenchmarkSpec(specPath)
+	if err != nil {
+		return fmt.Errorf("failed to load spec: %w", err)
+	}
+	applyRunFlags(cmd, spec)
+
+	ec := effectiveConfig{
+		Spec:          spec.Name,
+		Models:        []string{spec.Config.ModelID},
+		Engines:       []string{spec.Config.EngineType},
+		JudgeModel:    spec.Config.JudgeModel,
+		JudgeMap:      spec.Config.JudgeMap,
+		Parallel:      spec.Config.Concurrent,
+		TrialsPerTask: spec.Config.TrialsPerTask,
+		TimeoutSec:    spec.Config.TimeoutSec,
+	}
+	if len(modelOverrides) > 0 {
+		ec.Models = modelOverrides
+	}
+	if len(engineOverrides) > 0 {
+		ec.Engines = engineOverrides
+	}
+	if ec.Parallel {
+		ec.Workers = spec.Config.Workers
+		if ec.Workers <= 0 {
+			ec.Workers = projectconfig.DefaultWorkers
+		}
+	}
+	ec.Cache.Enabled = enableCache && !disableCache && replayDir == "" && !cache.HasNonDeterministicGraders(spec)
+	ec.Cache.Dir = runCacheDir
+	ec.Filters.Tasks = taskFilters
+	ec.Filters.Tags = tagFilters
+	ec.Filters.GraderTags = graderTags
+	ec.Filters.FirstN = firstNTasks
+	ec.Filters.TasksFrom = spec.TasksFrom
+	if spec.Range != [2]int{} {
+		ec.Filters.Range = spec.Range[:]
+	}
+	ec.Filters.NoTrigger = noTrigger
+	ec.Filters.OnlyTrigger = onlyTrigger
+
+	if printConfig == "json" {
+		data, err := json.MarshalIndent(ec, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal config: %w", err)
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+	data, err := yaml.Marshal(ec)
+	if err != nil {
+		return fmt.Errorf("marshal config: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// listEngineModels asks an engine for its models. It returns nil, nil when the
+// engine can't enumerate models.
+func listEngineModels(engineType, modelID string) ([]string, error) {
+	engine, err := newEngine(engineType, modelID)
+	if err != nil {
+		return nil, err
+	}
+	lister, ok := engine.(execution.ModelLister)
+	if !ok {
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	if err := engine.Initialize(ctx); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := engine.Shutdown(ctx); err != nil {
+			slog.Warn("engine shutdown failed", "error", err)
+		}
+	}()
+
+	ids, err := lister.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+	return ids, nil
+}
+
+// printRunBanner prints the benchmark header shown before tasks start.
+func printRunBanner(spec *models.BenchmarkSpec, specDir string) {
+	fmt.Printf("Running benchmark: %s\n", spec.Name)
+	fmt.Printf("Skill: %s\n", spec.SkillName)
+	fmt.Printf("Engine: %s\n", spec.Config.EngineType)
+	fmt.Printf("Model: %s\n", spec.Config.ModelID)
+	if judge := spec.Config.JudgeModelFor(spec.Config.ModelID); judge != "" {
+		fmt.Printf("Judge Model: %s\n", judge)
+	}
+	if spec.Config.Concurrent {
+		w := spec.Config.Workers
+		if w <= 0 {
+			w = 4
+		}
+		fmt.Printf("Parallel: %d workers\n", w)
+	}
+
+	if verbose && (len(spec.Config.SkillPaths) > 0 || spec.Config.SkillRegistry != "") {
+		fmt.Printf("Skill Directories:\n")
+		resolvedPaths := spec.ResolveSkillPaths(specDir)
+		for _, path := range resolvedPaths {
+			fmt.Printf("  - %s\n", path)
+		}
+	}
+
+	fmt.Println()
+}
+
+// warnOutcome reports a non-fatal problem found after the runner finished the
+// way the runner's own warnings are: on stderr and in outcome.Warnings, so
+// --fail-on-warning sees it.
+func warnOutcome(outcome *models.EvaluationOutcome, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	fmt.Fprintf(os.Stderr, "[WARN] %s\n", msg)
+	outcome.Warnings = append(outcome.Warnings, msg)
+}
+
+// outcomeWarnings returns the warnings recorded in outcome and, for baseline
+// comparisons, in its skills-disabled pass.
+func outcomeWarnings(outcome *models.EvaluationOutcome) []string {
+	warnings := outcome.Warnings
+	if outcome.BaselineOutcome != nil {
+		warnings = append(slices.Clone(warnings), outcome.BaselineOutcome.Warnings...)
+	}
+	return warnings
+}
+
+// taskSourceFiles maps each task ID in spec to the file it was defined in, for
+// annotating failures. Tasks that can't be loaded are left out.
+func taskSourceFiles(spec *models.BenchmarkSpec, specPath string) map[string]string {
+	testCases, err := loadTestCases(spec, specPath)
+	if err != nil {
+		return nil
+	}
+	files := make(map[string]string, len(testCases))
+	for _, tc := range testCases {
+		if tc.SourcePath != "" {
+			files[tc.TestID] = tc.SourcePath
+		}
+	}
+	return files
+}
+
+// runSingleModel evaluates spec with its configured engine and model. env,
+// when non-nil, is the env_matrix environment to export to hooks.
+func runSingleModel(cmd *cobra.Command, spec *models.BenchmarkSpec, specPath string, defaultSkills []string, env *models.MatrixEnvironment) (*models.EvaluationOutcome, error) {
+	cfg := newRunConfig(spec, specPath, defaultSkills)
+	specDir := cfg.SpecDir()
 
 	// Setup cache if enabled
 	var resultCache *cache.Cache
 	useCaching := enableCache && !disableCache
 
+	// Replayed runs depend on the transcripts, which aren't part of the cache key
+	if replayDir != "" {
+		useCaching = false
+	}
+
 	if useCaching && cache.HasNonDeterministicGraders(spec) {
 		if verbose {
-			fmt.Println("Note: Caching disabled due to non-deterministic graders (behavior, prompt)")
+			fmt.Println("Note: Caching disabled due to non-deterministic graders (behavior, prompt, rubric)")
 		}
 		useCaching = false
 	}
@@ -618,24 +1490,38 @@ func runSingleModel(cmd *cobra.Command, spec *models.BenchmarkSpec, specPath str
 		if err != nil {
 			return nil, fmt.Errorf("resolving cache directory: %w", err)
 		}
-		resultCache = cache.New(absCacheDir)
+		resultCache, err = cache.Open(&runCacheConfig, absCacheDir)
+		if err != nil {
+			return nil, fmt.Errorf("opening cache: %w", err)
+		}
 		if verbose {
-			fmt.Printf("Cache enabled: %s\n", absCacheDir)
+			location := absCacheDir
+			if runCacheConfig.Backend == cache.BackendHTTP {
+				location = runCacheConfig.URL
+			}
+			fmt.Printf("Cache enabled: %s\n", location)
 		}
 	}
 
-	// Create engine based on spec
-	var engine execution.AgentEngine
+	// In replay mode responses come from captured transcripts, so no real engine is started
+	var replayTranscripts map[string]*models.TaskTranscript
+	if replayDir != "" {
+		var err error
+		replayTranscripts, err = loadReplayTranscripts(replayDir)
+		if err != nil {
+			return nil, fmt.Errorf("loading replay transcripts: %w", err)
+		}
+		spec.Config.EngineType = "replay"
+	}
 
-	switch spec.Config.EngineType {
-	case "mock":
-		engine = execution.NewMockEngine(spec.Config.ModelID)
-	case "copilot-sdk":
-		engine = execution.NewCopilotEngineBuilder(spec.Config.ModelID, &execution.CopilotEngineBuilderOptions{
-			NewCopilotClient: newCopilotClientFn, // if nil, uses the real function, otherwise overridable for tests.
-		}).Build()
-	default:
-		return nil, fmt.Errorf("unknown engine type: %s", spec.Config.EngineType)
+	if err := loadEnvFile(specDir); err != nil {
+		return nil, err
+	}
+
+	// Create engine based on spec
+	engine, err := newEngine(spec.Config.EngineType, spec.Config.ModelID)
+	if err != nil {
+		return nil, err
 	}
 	if err := engine.Initialize(context.Background()); err != nil {
 		return nil, fmt.Errorf("failed to initialize agent: %w", err)
@@ -646,20 +1532,97 @@ func runSingleModel(cmd *cobra.Command, spec *models.BenchmarkSpec, specPath str
 		}
 	}()
 
+	// Trigger tests live in trigger_tests.yaml next to the eval spec
+	var triggerSpec *trigger.TestSpec
+	if !noTrigger {
+		if triggerSpec, err = trigger.Discover(specDir); err != nil {
+			return nil, fmt.Errorf("loading trigger tests: %w", err)
+		}
+	}
+	if onlyTrigger && triggerSpec == nil {
+		return nil, fmt.Errorf("--only-trigger: no trigger_tests.yaml found in %s", specDir)
+	}
+
 	// Create runner with optional task filters and cache
 	runnerOpts := []orchestration.RunnerOption{
 		orchestration.WithTaskFilters(taskFilters...),
 		orchestration.WithTagFilters(tagFilters...),
+		orchestration.WithGraderTags(graderTags...),
+		orchestration.WithToolVersion(version),
+	}
+	if jsonStdout {
+		runnerOpts = append(runnerOpts, orchestration.WithOutput(io.Discard))
 	}
 	if resultCache != nil {
 		runnerOpts = append(runnerOpts, orchestration.WithCache(resultCache))
+		if verifyCache {
+			runnerOpts = append(runnerOpts, orchestration.WithVerifyCache(verifySample))
+		}
+	} else if verifyCache && verbose {
+		fmt.Println("Note: --verify-cache has nothing to check because caching is off for this run")
 	}
 	if updateSnapshots {
 		runnerOpts = append(runnerOpts, orchestration.WithUpdateSnapshots(true))
 	}
+	if difficultyPath != "" {
+		weights, err := orchestration.LoadDifficultyWeights(difficultyPath)
+		if err != nil {
+			return nil, err
+		}
+		runnerOpts = append(runnerOpts, orchestration.WithDifficultyWeights(weights))
+	}
+	if reliabilityPath != "" {
+		factors, err := orchestration.LoadGraderReliability(reliabilityPath)
+		if err != nil {
+			return nil, err
+		}
+		runnerOpts = append(runnerOpts, orchestration.WithGraderReliability(factors))
+	}
+	if artifactsDir != "" {
+		runnerOpts = append(runnerOpts, orchestration.WithArtifactCapture(orchestration.ArtifactCapture{
+			Dir:      artifactsDir,
+			Patterns: artifactGlobs,
+			MaxBytes: artifactMaxSize,
+		}))
+	}
 	if skipGradersFlag {
 		runnerOpts = append(runnerOpts, orchestration.WithSkipGraders())
 	}
+	if skipInvalid {
+		runnerOpts = append(runnerOpts, orchestration.WithSkipInvalidTasks())
+	}
+	if rampUp > 0 {
+		runnerOpts = append(runnerOpts, orchestration.WithRampUp(rampUp))
+	}
+	if maxGraders > 0 {
+		runnerOpts = append(runnerOpts, orchestration.WithMaxConcurrentGraders(maxGraders))
+	}
+	if graderTaskLimit > 0 {
+		runnerOpts = append(runnerOpts, orchestration.WithGraderConcurrencyPerTask(graderTaskLimit))
+	}
+	if fixturesLock != "" {
+		runnerOpts = append(runnerOpts, orchestration.WithFixtureManifest())
+	}
+	if replayTranscripts != nil {
+		runnerOpts = append(runnerOpts, orchestration.WithReplay(replayTranscripts))
+	}
+	if shuffleTasks {
+		statusf("Shuffling tasks with seed %d (reproduce with --shuffle --seed %d)\n", shuffleSeed, shuffleSeed)
+		runnerOpts = append(runnerOpts, orchestration.WithShuffle(shuffleSeed))
+	}
+	if firstNTasks > 0 {
+		runnerOpts = append(runnerOpts, orchestration.WithFirstN(firstNTasks))
+	}
+	if baselineFile != "" {
+		baseline, err := loadOutcomeFile(baselineFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading --baseline-file: %w", err)
+		}
+		runnerOpts = append(runnerOpts, orchestration.WithBaselineOutcome(baseline))
+	}
+	if env != nil {
+		runnerOpts = append(runnerOpts, orchestration.WithEnvironment(*env))
+	}
 	runner := orchestration.NewTestRunner(cfg, engine, runnerOpts...)
 
 	// Setup session logger if enabled
@@ -681,6 +1644,19 @@ func runSingleModel(cmd *cobra.Command, spec *models.BenchmarkSpec, specPath str
 		}
 	}
 
+	if resultsStream != "" {
+		stream, err := orchestration.NewResultsStream(resultsStream)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err := stream.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "[WARN] Results stream %s: %v\n", resultsStream, err)
+			}
+		}()
+		runner.OnProgress(stream.Listener())
+	}
+
 	// Wire session logger as a progress listener
 	runner.OnProgress(func(event orchestration.ProgressEvent) {
 		var ev session.Event
@@ -704,51 +1680,62 @@ func runSingleModel(cmd *cobra.Command, spec *models.BenchmarkSpec, specPath str
 			feedback, _ := event.Details["feedback"].(string)      //nolint:errcheck
 			ev = session.NewEvent(session.EventGraderResult,
 				session.GraderResultData(grader, graderType, passed, score, feedback))
+		case orchestration.EventTestCached:
+			if sessionLog {
+				logSessionTranscript(sessLogger, event)
+			}
+			return
 		default:
 			return
 		}
 		sessLogger.Log(ev) //nolint:errcheck
+		if sessionLog && event.EventType == orchestration.EventTestComplete {
+			logSessionTranscript(sessLogger, event)
+		}
 	})
 
 	// Add progress listener
-	if verbose {
+	stopProgress := func() {}
+	switch {
+	case jsonStdout:
+		// stdout carries only the outcome JSON
+	case verbose:
 		runner.OnProgress(verboseProgressListener)
-	} else {
+	case tuiProgress:
+		var listener orchestration.ProgressListener
+		listener, stopProgress = newTUIProgressListener(os.Stdout, stdoutIsTerminal())
+		runner.OnProgress(listener)
+	default:
 		runner.OnProgress(simpleProgressListener)
 	}
 
 	// Run benchmark
 	ctx := context.Background()
 
-	fmt.Printf("Running benchmark: %s\n", spec.Name)
-	fmt.Printf("Skill: %s\n", spec.SkillName)
-	fmt.Printf("Engine: %s\n", spec.Config.EngineType)
-	fmt.Printf("Model: %s\n", spec.Config.ModelID)
-	if spec.Config.JudgeModel != "" {
-		fmt.Printf("Judge Model: %s\n", spec.Config.JudgeModel)
-	}
-	if spec.Config.Concurrent {
-		w := spec.Config.Workers
-		if w <= 0 {
-			w = 4
-		}
-		fmt.Printf("Parallel: %d workers\n", w)
+	if !jsonStdout {
+		printRunBanner(spec, specDir)
 	}
 
-	if verbose && len(spec.Config.SkillPaths) > 0 {
-		fmt.Printf("Skill Directories:\n")
-		resolvedPaths := utils.ResolvePaths(spec.Config.SkillPaths, specDir)
-		for _, path := range resolvedPaths {
-			fmt.Printf("  - %s\n", path)
-		}
+	var outcome *models.EvaluationOutcome
+	if onlyTrigger {
+		outcome = triggerOnlyOutcome(spec)
+	} else {
+		outcome, err = runner.RunBenchmark(ctx)
 	}
-
-	fmt.Println()
-
-	outcome, err := runner.RunBenchmark(ctx)
+	stopProgress()
 	if err != nil {
 		return nil, fmt.Errorf("benchmark failed: %w", err)
 	}
+	outcome.RunTags = runTags
+	if fetchedSkill != nil {
+		if outcome.Metadata == nil {
+			outcome.Metadata = make(map[string]any)
+		}
+		outcome.Metadata["skill_source"] = map[string]any{"ref": fetchedSkill.Ref, "name": fetchedSkill.Name}
+	}
+	if fetchedSkill == nil {
+		recordSkillGitInfo(outcome, specDir)
+	}
 
 	// Log task completion and session summary from outcome data
 	if sessionLog {
@@ -758,12 +1745,16 @@ func runSingleModel(cmd *cobra.Command, spec *models.BenchmarkSpec, specPath str
 		sessLogger.Log(ev) //nolint:errcheck
 	}
 
+	if fixturesLock != "" && !onlyTrigger {
+		if err := checkFixtureLock(fixturesLock, outcome.FixtureManifest, strictFlag); err != nil {
+			return outcome, err
+		}
+	}
+
 	var triggerResults []models.TriggerResult
 
-	// Discover and run trigger tests if present alongside the eval spec
-	if triggerSpec, err := trigger.Discover(specDir); err != nil {
-		return outcome, fmt.Errorf("loading trigger tests: %w", err)
-	} else if triggerSpec != nil {
+	// Run trigger tests if present alongside the eval spec
+	if triggerSpec != nil && replayDir == "" {
 		var tm *models.TriggerMetrics
 		if spec.Config.EngineType == "mock" {
 			// return perfect results
@@ -787,7 +1778,11 @@ func runSingleModel(cmd *cobra.Command, spec *models.BenchmarkSpec, specPath str
 			triggerResults = results
 			tm = models.ComputeTriggerMetrics(results)
 		} else {
-			tr := trigger.NewRunner(triggerSpec, engine, cfg, os.Stdout)
+			var triggerOut io.Writer = os.Stdout
+			if jsonStdout {
+				triggerOut = io.Discard
+			}
+			tr := trigger.NewRunner(triggerSpec, engine, cfg, triggerOut)
 			if verbose {
 				fmt.Println("Running trigger tests...")
 			}
@@ -798,6 +1793,7 @@ func runSingleModel(cmd *cobra.Command, spec *models.BenchmarkSpec, specPath str
 		if tm != nil {
 			outcome.TriggerMetrics = tm
 			outcome.TriggerResults = triggerResults
+			orchestration.ApplyTriggerWeight(outcome, spec.Config.TriggerWeight)
 			for _, m := range spec.Metrics {
 				if m.Identifier == "trigger_accuracy" {
 					outcome.Measures[m.Identifier] = models.MeasureResult{
@@ -832,10 +1828,21 @@ func runSingleModel(cmd *cobra.Command, spec *models.BenchmarkSpec, specPath str
 	execution.UpdateOutcomeUsage(outcome, engine)
 
 	// Print results based on format
-	switch format {
-	case "github-comment":
-		fmt.Print(FormatGitHubComment(outcome))
-	case "default":
+	switch {
+	case jsonStdout:
+		if err := writeOutcomeJSON(cmd.OutOrStdout(), outcome); err != nil {
+			return nil, fmt.Errorf("writing outcome to stdout: %w", err)
+		}
+	case format == "github-comment":
+		comment, err := RenderGitHubComment(outcome, commentTmpl)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Print(comment)
+	case format == "github-actions":
+		printSummary(outcome)
+		fmt.Print(FormatGitHubActions(outcome, taskSourceFiles(spec, specPath)))
+	case format == "default":
 		printSummary(outcome)
 		printSnapshotUpdateSummary(outcome)
 		if interpret {
@@ -847,18 +1854,33 @@ func runSingleModel(cmd *cobra.Command, spec *models.BenchmarkSpec, specPath str
 			displaySuggestionReport(cmd.OutOrStdout(), spec.Config.ModelID, report)
 		}
 	default:
-		return nil, fmt.Errorf("unknown output format: %s (supported: default, github-comment)", format)
+		return nil, fmt.Errorf("unknown output format: %s (supported: default, github-comment, github-actions)", format)
 	}
 
-	// Save output for single-model runs (multi-model saves are handled by the caller)
-	if outputPath != "" && len(modelOverrides) <= 1 {
+	// Save output for single-model runs (multi-model, multi-engine and env_matrix saves are handled by the caller)
+	if outputPath != "" && len(modelOverrides) <= 1 && len(engineOverrides) <= 1 && len(spec.Config.EnvMatrix) <= 1 {
 		if err := saveOutcome(outcome, outputPath); err != nil {
 			return nil, fmt.Errorf("failed to save output: %w", err)
 		}
-		fmt.Printf("\nResults saved to: %s\n", outputPath)
+		statusf("\nResults saved to: %s\n", outputPath)
+	}
+
+	// Notification failures never fail the run
+	if spec.Notify != nil {
+		if err := notify.Send(context.Background(), *spec.Notify, outcome); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
 	}
 
 	// Return test failure as error so caller can decide how to handle it
+	if failOnWarning {
+		if warnings := outcomeWarnings(outcome); len(warnings) > 0 {
+			return outcome, &TestFailureError{
+				Message: fmt.Sprintf("run reported %d warning(s) with --fail-on-warning:\n  %s", len(warnings), strings.Join(warnings, "\n  ")),
+			}
+		}
+	}
+
 	// In baseline mode, exit code is based on skill impact (0=improvement, 1=regression/neutral)
 	if outcome.IsBaseline {
 		withPassRate := outcome.Digest.SuccessRate
@@ -875,9 +1897,15 @@ func runSingleModel(cmd *cobra.Command, spec *models.BenchmarkSpec, specPath str
 		return outcome, nil
 	}
 
-	// Normal mode: fail if tests failed or errors occurred
+	// Normal mode: fail if tests failed or errors occurred, or with
+	// gate_pass_rate, only if the pass rate is below the threshold
 	var failures []string
-	if outcome.Digest.Failed > 0 || outcome.Digest.Errors > 0 {
+	if spec.Config.GatePassRate {
+		if !outcome.PassRateGreen() {
+			failures = append(failures, fmt.Sprintf("pass rate %.1f%% below pass_rate_threshold %.1f%%",
+				outcome.Digest.SuccessRate*100, outcome.Setup.PassRateTarget()*100))
+		}
+	} else if outcome.Digest.Failed > 0 || outcome.Digest.Errors > 0 {
 		failures = append(failures, fmt.Sprintf("%d failed and %d error(s)", outcome.Digest.Failed, outcome.Digest.Errors))
 	}
 	if m, ok := outcome.Measures["trigger_accuracy"]; ok && !m.Passed {
@@ -889,21 +1917,92 @@ func runSingleModel(cmd *cobra.Command, spec *models.BenchmarkSpec, specPath str
 		}
 	}
 
-	return outcome, nil
+	return outcome, nil
+}
+
+// loadEnvFile sets variables from --env-file, or from a .env file in specDir
+// when there is one, so skills, hooks, and graders can read API keys without
+// manual exports. Variables already in the environment are never overridden.
+func loadEnvFile(specDir string) error {
+	path := envFile
+	if path == "" {
+		path = filepath.Join(specDir, ".env")
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+	}
+
+	vars, err := godotenv.Read(path)
+	if err != nil {
+		return fmt.Errorf("loading env file %s: %w", path, err)
+	}
+
+	loaded := 0
+	for _, k := range slices.Sorted(maps.Keys(vars)) {
+		if _, ok := os.LookupEnv(k); ok {
+			continue
+		}
+		if err := os.Setenv(k, vars[k]); err != nil {
+			return fmt.Errorf("setting %s from %s: %w", k, path, err)
+		}
+		loaded++
+	}
+	if verbose {
+		fmt.Printf("Loaded %d variable(s) from %s (%d already set)\n", loaded, path, len(vars)-loaded)
+	}
+	return nil
+}
+
+// triggerOnlyOutcome is the outcome for an --only-trigger run: no eval tasks
+// ran, so it has an empty digest for the trigger results to be attached to.
+func triggerOnlyOutcome(spec *models.BenchmarkSpec) *models.EvaluationOutcome {
+	now := time.Now()
+	return &models.EvaluationOutcome{
+		RunID:       orchestration.NewRunID(),
+		SkillTested: spec.SkillName,
+		BenchName:   spec.Name,
+		Timestamp:   now,
+		Setup: models.OutcomeSetup{
+			RunsPerTest:       spec.Config.TrialsPerTask,
+			ModelID:           spec.Config.ModelID,
+			EngineType:        spec.Config.EngineType,
+			TimeoutSec:        spec.Config.TimeoutSec,
+			JudgeModel:        spec.Config.JudgeModelFor(spec.Config.ModelID),
+			PassRateThreshold: spec.Config.PassRateThreshold,
+		},
+		Measures:   make(map[string]models.MeasureResult),
+		Metadata:   map[string]any{"trigger_only": true},
+		Provenance: orchestration.NewProvenance(version, spec.Config.EngineType, "", spec.Config.ModelID, now),
+	}
 }
 
 // printModelComparison renders a comparison table for multi-model runs.
 func printModelComparison(results []modelResult) {
 	slices.SortFunc(results, func(a, b modelResult) int {
-		return cmp.Compare(a.modelID, b.modelID)
+		return cmp.Compare(a.label(), b.label())
 	})
 
+	// Multi-engine and env_matrix runs label rows "env_engine_model", which
+	// needs a wider first column
+	title, header, width := "MODEL COMPARISON", "Model", 20
+	if len(results) > 0 && (results[0].engine != "" || results[0].env != "") {
+		if results[0].engine != "" {
+			title, header = "ENGINE × MODEL COMPARISON", "Engine_Model"
+		}
+		if results[0].env != "" {
+			title, header = "ENVIRONMENT × "+title, "Env_"+header
+		}
+		for _, mr := range results {
+			width = max(width, len(mr.label())+1)
+		}
+	}
+
 	fmt.Println()
 	fmt.Println("═" + strings.Repeat("═", 95))
-	fmt.Println(" MODEL COMPARISON")
+	fmt.Println(" " + title)
 	fmt.Println("═" + strings.Repeat("═", 95))
 	fmt.Println()
-	fmt.Printf("%-20s %-8s %-10s %-12s %-8s %-14s %s\n", "Model", "Score", "Pass Rate", "Duration", "Turns", "Total Tokens", "Premium Reqs")
+	fmt.Printf("%-*s %-8s %-10s %-12s %-8s %-14s %s\n", width, header, "Score", "Pass Rate", "Duration", "Turns", "Total Tokens", "Premium Reqs")
 	fmt.Println("─" + strings.Repeat("─", 95))
 
 	for _, mr := range results {
@@ -930,11 +2029,52 @@ func printModelComparison(results []modelResult) {
 		}
 		duration := time.Duration(durationMs) * time.Millisecond
 		passStr := fmt.Sprintf("%.1f%%", passRate)
-		fmt.Printf("%-20s %-8.2f %-10s %-12v %-8s %-14s %s\n", mr.modelID, score, passStr, duration, turns, totalTokens, premReqs)
+		fmt.Printf("%-*s %-8.2f %-10s %-12v %-8s %-14s %s\n", width, mr.label(), score, passStr, duration, turns, totalTokens, premReqs)
 	}
 	fmt.Println()
 }
 
+// writeComparisonCSV writes one row per evaluated model, with the columns of
+// the model comparison table. Usage cells are empty when the engine reported
+// no usage. Single-model runs still get their one row.
+func writeComparisonCSV(path string, results []skillRunResult) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck
+
+	w := csv.NewWriter(f)
+	rows := [][]string{{"skill", "model", "aggregate_score", "pass_rate", "duration_ms", "input_tokens", "output_tokens", "premium_requests"}}
+	for _, sr := range results {
+		for _, mr := range sr.outcomes {
+			if mr.outcome == nil {
+				continue
+			}
+			d := mr.outcome.Digest
+			row := []string{
+				sr.skillName,
+				mr.label(),
+				strconv.FormatFloat(d.AggregateScore, 'f', 4, 64),
+				strconv.FormatFloat(d.SuccessRate, 'f', 4, 64),
+				strconv.FormatInt(d.DurationMs, 10),
+				"", "", "",
+			}
+			if d.Usage != nil && !d.Usage.IsZero() {
+				row[5] = strconv.Itoa(d.Usage.InputTokens)
+				row[6] = strconv.Itoa(d.Usage.OutputTokens)
+				row[7] = strconv.FormatFloat(d.Usage.PremiumRequests, 'f', -1, 64)
+			}
+			rows = append(rows, row)
+		}
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	statusf("Comparison CSV saved to: %s\n", path)
+	return f.Close()
+}
+
 // sanitizePathSegment replaces characters that are invalid in filenames.
 func sanitizePathSegment(name string) string {
 	r := strings.NewReplacer("/", "-", "\\", "-", ":", "-", " ", "-")
@@ -1110,6 +2250,75 @@ func parseSnapshotUpdates(raw any) ([]graders.SnapshotUpdate, error) {
 	}
 }
 
+// findSlowTasks returns the tasks whose average run duration exceeds the
+// outcome's slow-task budget, slowest first.
+func findSlowTasks(outcome *models.EvaluationOutcome) []models.TestOutcome {
+	budget := outcome.Setup.SlowTaskMs
+	if budget <= 0 {
+		return nil
+	}
+	var slow []models.TestOutcome
+	for _, to := range outcome.TestOutcomes {
+		if to.Stats != nil && to.Stats.AvgDurationMs > budget {
+			slow = append(slow, to)
+		}
+	}
+	slices.SortStableFunc(slow, func(a, b models.TestOutcome) int {
+		return cmp.Compare(b.Stats.AvgDurationMs, a.Stats.AvgDurationMs)
+	})
+	return slow
+}
+
+// sortedByTestID returns a copy of outcomes ordered by task ID, so listings
+// don't depend on load or completion order.
+func sortedByTestID(outcomes []models.TestOutcome) []models.TestOutcome {
+	sorted := slices.Clone(outcomes)
+	slices.SortStableFunc(sorted, func(a, b models.TestOutcome) int {
+		return cmp.Compare(a.TestID, b.TestID)
+	})
+	return sorted
+}
+
+// formatRunTiming renders a run's timing breakdown with graders in execution order,
+// e.g. "total=1200ms engine=400ms grading=790ms [rubric=780ms, regex=0ms]".
+func formatRunTiming(t *models.RunTiming) string {
+	s := fmt.Sprintf("total=%dms engine=%dms grading=%dms", t.TotalMs, t.EngineMs, t.GradingMs)
+	if len(t.Graders) == 0 {
+		return s
+	}
+	parts := make([]string, len(t.Graders))
+	for i, g := range t.Graders {
+		parts[i] = fmt.Sprintf("%s=%dms", g.Name, g.DurationMs)
+	}
+	return s + " [" + strings.Join(parts, ", ") + "]"
+}
+
+// xfailReasonSuffix formats a task's xfail reason for the summary, or returns
+// "" if it has none.
+func xfailReasonSuffix(to models.TestOutcome) string {
+	if to.XFailReason == "" {
+		return ""
+	}
+	return ": " + to.XFailReason
+}
+
+// successRateLine formats the summary's success rate, noting the
+// pass_rate_threshold when one is set. With color it is green when the run
+// meets the threshold (100% by default) and red otherwise.
+func successRateLine(outcome *models.EvaluationOutcome, color bool) string {
+	line := fmt.Sprintf("Success Rate:   %.1f%%", outcome.Digest.SuccessRate*100)
+	if threshold := outcome.Setup.PassRateThreshold; threshold > 0 {
+		line += fmt.Sprintf(" (threshold %.1f%%)", threshold*100)
+	}
+	if !color {
+		return line
+	}
+	if outcome.PassRateGreen() {
+		return "\033[32m" + line + "\033[0m"
+	}
+	return "\033[31m" + line + "\033[0m"
+}
+
 func printSummary(outcome *models.EvaluationOutcome) {
 	fmt.Println("=" + strings.Repeat("=", 50))
 	fmt.Println(" BENCHMARK RESULTS")
@@ -1118,18 +2327,41 @@ func printSummary(outcome *models.EvaluationOutcome) {
 
 	digest := outcome.Digest
 
+	if outcome.RunID != "" {
+		fmt.Printf("Run ID:         %s\n", outcome.RunID)
+	}
 	fmt.Printf("Total Tests:    %d\n", digest.TotalTests)
 	fmt.Printf("Succeeded:      %d\n", digest.Succeeded)
 	fmt.Printf("Failed:         %d\n", digest.Failed)
 	fmt.Printf("Errors:         %d\n", digest.Errors)
-	fmt.Printf("Success Rate:   %.1f%%\n", digest.SuccessRate*100)
-	fmt.Printf("Aggregate Score: %.2f\n", digest.AggregateScore)
+	if digest.XFailed > 0 {
+		fmt.Printf("XFailed:        %d\n", digest.XFailed)
+	}
+	if digest.XPassed > 0 {
+		fmt.Printf("XPassed:        %d\n", digest.XPassed)
+	}
+	fmt.Println(successRateLine(outcome, stdoutIsTerminal()))
+	switch m := outcome.Setup.AggregateMethod; {
+	case outcome.Setup.TriggerWeight > 0 && outcome.TriggerMetrics != nil:
+		fmt.Printf("Aggregate Score: %.2f (includes trigger F1 %.2f at weight %g)\n",
+			digest.AggregateScore, outcome.TriggerMetrics.F1, outcome.Setup.TriggerWeight)
+	case m != "" && m != models.AggregateMethodMean:
+		fmt.Printf("Aggregate Score: %.2f (%s)\n", digest.AggregateScore, m)
+	default:
+		fmt.Printf("Aggregate Score: %.2f\n", digest.AggregateScore)
+	}
 	fmt.Printf("Min Score:      %.2f\n", digest.MinScore)
 	fmt.Printf("Max Score:      %.2f\n", digest.MaxScore)
 	fmt.Printf("Std Dev:        %.4f\n", digest.StdDev)
+	if digest.Stability != nil {
+		fmt.Printf("Stability:      %.2f\n", *digest.Stability)
+	}
 
 	duration := time.Duration(digest.DurationMs) * time.Millisecond
 	fmt.Printf("Duration:       %v\n", duration)
+	if names, ok := outcome.Metadata[orchestration.MetadataNonDeterministicGraders].([]string); ok && len(names) > 0 {
+		fmt.Printf("Note:           results may vary between runs (non-deterministic graders: %s)\n", strings.Join(names, ", "))
+	}
 	fmt.Println()
 
 	// Grouped results summary
@@ -1148,15 +2380,42 @@ func printSummary(outcome *models.EvaluationOutcome) {
 		fmt.Println()
 	}
 
+	// Tag results summary
+	if len(digest.Tags) > 0 {
+		fmt.Println("-" + strings.Repeat("-", 50))
+		fmt.Println(" RESULTS BY TAG")
+		fmt.Println("-" + strings.Repeat("-", 50))
+		for _, g := range digest.Tags {
+			pct := 0.0
+			if g.Total > 0 {
+				pct = float64(g.Passed) / float64(g.Total) * 100
+			}
+			fmt.Printf("  %-20s %d/%d passed (%.0f%%)  avg: %.2f\n",
+				g.Name+":", g.Passed, g.Total, pct, g.AvgScore)
+		}
+		fmt.Println()
+	}
+
 	// Per-task breakdown
 	fmt.Println("-" + strings.Repeat("-", 50))
 	fmt.Println(" PER-TASK BREAKDOWN")
 	fmt.Println("-" + strings.Repeat("-", 50))
 	for _, to := range outcome.TestOutcomes {
 		icon := "✓"
-		if to.Status != models.StatusPassed {
+		switch {
+		case to.XFailed():
+			icon = "~"
+		case to.Status != models.StatusPassed:
 			icon = "✗"
 		}
+		if compactSummary {
+			score := 0.0
+			if to.Stats != nil {
+				score = to.Stats.AvgScore
+			}
+			fmt.Printf("  %s %s %.2f\n", icon, to.DisplayName, score)
+			continue
+		}
 		fmt.Printf("  %s %s [%s]\n", icon, to.DisplayName, to.Status)
 		if to.Stats != nil {
 			fmt.Printf("      pass_rate=%.1f%%  avg=%.2f  min=%.2f  max=%.2f  stddev=%.4f  avg_dur=%dms\n",
@@ -1164,14 +2423,21 @@ func printSummary(outcome *models.EvaluationOutcome) {
 				to.Stats.MinScore, to.Stats.MaxScore,
 				to.Stats.StdDevScore, to.Stats.AvgDurationMs)
 		}
+		if verbose {
+			for _, run := range to.Runs {
+				if run.Timing != nil {
+					fmt.Printf("      run %d: %s\n", run.RunNumber, formatRunTiming(run.Timing))
+				}
+			}
+		}
 	}
 	fmt.Println()
 
 	// Show failed tests
 	if digest.Failed > 0 || digest.Errors > 0 {
 		fmt.Println("Failed Tests:")
-		for _, to := range outcome.TestOutcomes {
-			if to.Status != models.StatusPassed {
+		for _, to := range sortedByTestID(outcome.TestOutcomes) {
+			if to.Status != models.StatusPassed && !to.XFailed() {
 				fmt.Printf("  - %s (%s)\n", to.DisplayName, to.Status)
 
 				// Show validation failures
@@ -1189,9 +2455,29 @@ func printSummary(outcome *models.EvaluationOutcome) {
 		fmt.Println()
 	}
 
+	// Show xfail tasks: expected failures, then unexpected passes
+	if digest.XFailed > 0 {
+		fmt.Println("Expected Failures (xfail):")
+		for _, to := range sortedByTestID(outcome.TestOutcomes) {
+			if to.XFailed() {
+				fmt.Printf("  - %s (%s)%s\n", to.DisplayName, to.Status, xfailReasonSuffix(to))
+			}
+		}
+		fmt.Println()
+	}
+	if digest.XPassed > 0 {
+		fmt.Println("\u26a0 Unexpected Passes (xpass; consider removing xfail):")
+		for _, to := range sortedByTestID(outcome.TestOutcomes) {
+			if to.XPassed() {
+				fmt.Printf("  - %s%s\n", to.DisplayName, xfailReasonSuffix(to))
+			}
+		}
+		fmt.Println()
+	}
+
 	// Show flaky tasks
 	var flakyTasks []models.TestOutcome
-	for _, to := range outcome.TestOutcomes {
+	for _, to := range sortedByTestID(outcome.TestOutcomes) {
 		if to.Stats != nil && to.Stats.Flaky {
 			flakyTasks = append(flakyTasks, to)
 		}
@@ -1199,10 +2485,11 @@ func printSummary(outcome *models.EvaluationOutcome) {
 	if len(flakyTasks) > 0 {
 		fmt.Println("\u26a0 Flaky Tasks (inconsistent pass/fail across trials):")
 		for _, to := range flakyTasks {
-			fmt.Printf("  - %s  pass_rate=%.0f%%  flakiness=%.1f%%  score=%.2f\u00b1%.2f  CI95=[%.2f, %.2f]\n",
+			fmt.Printf("  - %s  pass_rate=%.0f%%  flakiness=%.1f%%  stability=%.2f  score=%.2f\u00b1%.2f  CI95=[%.2f, %.2f]\n",
 				to.DisplayName,
 				to.Stats.PassRate*100,
 				to.Stats.FlakinessPercent,
+				to.Stats.StabilityScore,
 				to.Stats.AvgScore,
 				to.Stats.StdDevScore,
 				to.Stats.CI95Lo,
@@ -1212,6 +2499,38 @@ func printSummary(outcome *models.EvaluationOutcome) {
 		fmt.Println()
 	}
 
+	// Show tasks over the slow-task budget (informational only)
+	if slowTasks := findSlowTasks(outcome); len(slowTasks) > 0 {
+		fmt.Printf("\u23f1 Slow Tasks (avg duration over %dms):\n", outcome.Setup.SlowTaskMs)
+		for _, to := range slowTasks {
+			fmt.Printf("  - %s  avg_dur=%dms\n", to.DisplayName, to.Stats.AvgDurationMs)
+		}
+		fmt.Println()
+	}
+
+	// Show tasks whose agent called tools outside allowed_tools/denied_tools
+	var violationLines []string
+	for _, to := range sortedByTestID(outcome.TestOutcomes) {
+		var tools []string
+		for _, run := range to.Runs {
+			for _, tool := range run.ToolViolations {
+				if !slices.Contains(tools, tool) {
+					tools = append(tools, tool)
+				}
+			}
+		}
+		if len(tools) > 0 {
+			violationLines = append(violationLines, fmt.Sprintf("  - %s  tools=%s", to.DisplayName, strings.Join(tools, ", ")))
+		}
+	}
+	if len(violationLines) > 0 {
+		fmt.Println("\U0001f6ab Tool Violations (calls outside allowed_tools/denied_tools):")
+		for _, line := range violationLines {
+			fmt.Println(line)
+		}
+		fmt.Println()
+	}
+
 	// Show trigger accuracy if trigger tests were run
 	if outcome.TriggerMetrics != nil {
 		m := outcome.TriggerMetrics
@@ -1275,15 +2594,123 @@ func printUsageSummary(usage *models.UsageStats) {
 	fmt.Println()
 }
 
+// loadReplayTranscripts loads --replay transcripts from a --transcript-dir
+// directory or from the task_transcript events of a --session-log file.
+func loadReplayTranscripts(path string) (map[string]*models.TaskTranscript, error) {
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		events, err := session.ReadEvents(path)
+		if err != nil {
+			return nil, err
+		}
+		return session.Transcripts(events)
+	}
+	return transcript.LoadDir(path)
+}
+
+// logSessionTranscript records the transcript of the task a test-complete or
+// test-cached event reports, so `waza replay-session` can re-grade it.
+func logSessionTranscript(logger session.Logger, event orchestration.ProgressEvent) {
+	outcome, ok := event.Details["outcome"].(models.TestOutcome)
+	if !ok {
+		return
+	}
+	tc := &models.TestCase{TestID: outcome.TestID, DisplayName: outcome.DisplayName}
+	t := transcript.BuildTaskTranscript(tc, outcome, outcome.StartedAt)
+	logger.Log(session.NewEvent(session.EventTaskTranscript, session.TaskTranscriptData(t))) //nolint:errcheck
+}
+
+// statusf prints a human-readable status line to stdout, unless --json-stdout
+// reserves stdout for the outcome JSON.
+func statusf(format string, args ...any) {
+	if jsonStdout {
+		return
+	}
+	fmt.Printf(format, args...)
+}
+
+// writeOutcomeJSON writes outcome to w as indented JSON followed by a newline.
+func writeOutcomeJSON(w io.Writer, outcome *models.EvaluationOutcome) error {
+	if normalizeOutput {
+		outcome = outcome.Normalized()
+	}
+	data, err := json.MarshalIndent(outcome, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// saveOutcome writes outcome to path as YAML if path ends in .yaml or .yml,
+// and as JSON otherwise. With --normalize-output the volatile fields are
+// zeroed first.
 func saveOutcome(outcome *models.EvaluationOutcome, path string) error {
+	if normalizeOutput {
+		outcome = outcome.Normalized()
+	}
 	data, err := json.MarshalIndent(outcome, "", "  ")
 	if err != nil {
 		return err
 	}
+	if isYAMLPath(path) {
+		if data, err = jsonToYAML(data); err != nil {
+			return err
+		}
+	}
 
 	return os.WriteFile(path, data, 0644)
 }
 
+// isYAMLPath reports whether path has a .yaml or .yml extension.
+func isYAMLPath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// jsonToYAML re-encodes a JSON document as block-style YAML with the same
+// keys in the same order, so YAML results match the JSON ones field for field.
+func jsonToYAML(data []byte) ([]byte, error) {
+	// JSON is valid YAML, so parsing it keeps the key order; clearing the
+	// node styles turns JSON's flow collections and quoted strings into
+	// plain block YAML, still quoting strings that would otherwise change type.
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("converting results to YAML: %w", err)
+	}
+	clearYAMLStyle(&doc)
+
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("converting results to YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("converting results to YAML: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+func clearYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearYAMLStyle(child)
+	}
+}
+
+// yamlToJSON converts a YAML results file back to JSON, for decoding into the
+// JSON-tagged result types.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var v any
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
 // autoUploadOutcomes uploads outcomes to configured remote storage.
 // Errors are reported as warnings — local results are always preserved.
 func autoUploadOutcomes(cmd *cobra.Command, cfg *projectconfig.ProjectConfig, results []modelResult) {
@@ -1316,13 +2743,46 @@ func autoUploadOutcomes(cmd *cobra.Command, cfg *projectconfig.ProjectConfig, re
 		}
 		if err := store.Upload(ctx, mr.outcome); err != nil {
 			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "⚠️  Failed to upload results to %s: %v. Results saved locally.\n", provider, err)
-		} else {
+		} else if !jsonStdout {
 			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "☁️  Results uploaded to %s\n", provider)
 		}
 	}
 }
 
-// writeReporters processes --reporter flags and writes the requested outputs.
+// checkFixtureLock compares the run's fixture hashes against the lock at path.
+// The lock is created from manifest when it doesn't exist yet. Drift is reported
+// as a warning, or returned as an error when strict is set.
+func checkFixtureLock(path string, manifest models.FixtureManifest, strict bool) error {
+	locked, err := orchestration.LoadFixtureLock(path)
+	if err != nil {
+		return err
+	}
+	if locked == nil {
+		if err := orchestration.WriteFixtureLock(path, manifest); err != nil {
+			return fmt.Errorf("writing fixture lock: %w", err)
+		}
+		statusf("Fixture lock written to: %s (%d file(s))\n", path, len(manifest))
+		return nil
+	}
+
+	drift := orchestration.DiffFixtureManifests(locked, manifest)
+	for _, f := range drift.Unlocked {
+		statusf("[WARN] fixture %s is not in %s\n", f, path)
+	}
+	if !drift.HasDrift() {
+		return nil
+	}
+	for _, f := range drift.Changed {
+		statusf("[WARN] fixture drift: %s changed since %s was recorded\n", f, path)
+	}
+	if strict {
+		return fmt.Errorf("fixture drift detected in %d file(s): %s", len(drift.Changed), strings.Join(drift.Changed, ", "))
+	}
+	return nil
+}
+
+// writeReporters processes the --reporter and --badge flags and writes the
+// requested outputs.
 func writeReporters(outcome *models.EvaluationOutcome) error {
 	for _, r := range reporters {
 		switch {
@@ -1337,11 +2797,57 @@ func writeReporters(outcome *models.EvaluationOutcome) error {
 			if err := reporting.WriteJUnitXML(outcome, path); err != nil {
 				return fmt.Errorf("failed to write JUnit XML: %w", err)
 			}
-			fmt.Printf("JUnit XML saved to: %s\n", path)
+			statusf("JUnit XML saved to: %s\n", path)
 		default:
 			return fmt.Errorf("unknown reporter: %s (supported: json, junit:<path>)", r)
 		}
 	}
+	if badgePath != "" {
+		if err := reporting.WriteBadge(outcome, badgePath, badgeThresholdFor(outcome)); err != nil {
+			return fmt.Errorf("failed to write badge: %w", err)
+		}
+		statusf("Badge saved to: %s\n", badgePath)
+	}
+	return nil
+}
+
+// flattenSkillResults returns the model results of every skill, in order.
+func flattenSkillResults(skills []skillRunResult) []modelResult {
+	var results []modelResult
+	for _, s := range skills {
+		results = append(results, s.outcomes...)
+	}
+	return results
+}
+
+// badgeThresholdFor is the --badge-threshold, or the outcome's
+// pass_rate_threshold when the flag wasn't given.
+func badgeThresholdFor(outcome *models.EvaluationOutcome) float64 {
+	if !badgeThresholdSet && outcome.Setup.PassRateThreshold > 0 {
+		return outcome.Setup.PassRateThreshold
+	}
+	return badgeThreshold
+}
+
+// writeMetrics writes the --metrics file, with one set of samples per skill,
+// model, engine and environment.
+func writeMetrics(results []modelResult) error {
+	if metricsPath == "" {
+		return nil
+	}
+	var runs []reporting.MetricsRun
+	for _, mr := range results {
+		if mr.outcome != nil {
+			runs = append(runs, reporting.MetricsRun{Outcome: mr.outcome, Engine: mr.engine})
+		}
+	}
+	if len(runs) == 0 {
+		return nil
+	}
+	if err := reporting.WritePrometheus(metricsPath, runs...); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	statusf("Metrics saved to: %s\n", metricsPath)
 	return nil
 }
 
@@ -1350,7 +2856,7 @@ func computeAndPrintRecommendation(results []modelResult) *models.Recommendation
 	inputs := make([]recommend.ModelInput, len(results))
 	for i, mr := range results {
 		inputs[i] = recommend.ModelInput{
-			ModelID: mr.modelID,
+			ModelID: mr.label(),
 			Outcome: mr.outcome,
 		}
 	}
@@ -1417,8 +2923,18 @@ func buildMultiSkillSummary(results []skillRunResult) *models.MultiSkillSummary
 	modelsMap := make(map[string]bool)
 
 	for _, r := range results {
+		skillName := r.skillName
+		if skillName == "" {
+			// Single spec-path runs don't carry a skill name; fall back to the spec's
+			for _, mr := range r.outcomes {
+				if mr.outcome != nil && mr.outcome.SkillTested != "" {
+					skillName = mr.outcome.SkillTested
+					break
+				}
+			}
+		}
 		skill := models.SkillSummary{
-			SkillName:   r.skillName,
+			SkillName:   skillName,
 			Models:      make([]string, 0, len(r.outcomes)),
 			OutputFiles: make([]string, 0, len(r.outcomes)),
 		}
@@ -1429,23 +2945,32 @@ func buildMultiSkillSummary(results []skillRunResult) *models.MultiSkillSummary
 
 		// Aggregate across all models for this skill
 		for _, mr := range r.outcomes {
-			skill.Models = append(skill.Models, mr.modelID)
-			modelsMap[mr.modelID] = true
+			skill.Models = append(skill.Models, mr.label())
+			modelsMap[mr.label()] = true
+
+			modelSummary := models.ModelSummary{Model: mr.label()}
 
 			if mr.outcome != nil {
 				totalPassed += mr.outcome.Digest.Succeeded
 				totalTests += mr.outcome.Digest.TotalTests
 				sumScore += mr.outcome.Digest.AggregateScore
 				validOutcomes++
+
+				modelSummary.RunID = mr.outcome.RunID
+				modelSummary.PassRate = mr.outcome.Digest.SuccessRate
+				modelSummary.AggregateScore = mr.outcome.Digest.AggregateScore
 			}
 
 			// Build output file path (matches multi-model output naming)
 			if outputPath != "" {
 				ext := filepath.Ext(outputPath)
 				base := strings.TrimSuffix(outputPath, ext)
-				perModelPath := fmt.Sprintf("%s_%s%s", base, sanitizePathSegment(mr.modelID), ext)
+				perModelPath := fmt.Sprintf("%s_%s%s", base, sanitizePathSegment(mr.label()), ext)
 				skill.OutputFiles = append(skill.OutputFiles, perModelPath)
+				modelSummary.OutputFile = perModelPath
 			}
+
+			skill.ModelResults = append(skill.ModelResults, modelSummary)
 		}
 
 		// Calculate skill-level metrics
@@ -1477,57 +3002,216 @@ func buildMultiSkillSummary(results []skillRunResult) *models.MultiSkillSummary
 	return summary
 }
 
-// saveSummary writes a MultiSkillSummary to a JSON file.
+// writeCombinedSummary saves the summary for results next to --output, as
+// <output>_summary.<ext>.
+func writeCombinedSummary(results []skillRunResult) error {
+	summary := buildMultiSkillSummary(results)
+	ext := filepath.Ext(outputPath)
+	base := strings.TrimSuffix(outputPath, ext)
+	summaryPath := fmt.Sprintf("%s_summary%s", base, ext)
+
+	if err := saveSummary(summary, summaryPath); err != nil {
+		return fmt.Errorf("failed to save summary: %w", err)
+	}
+	statusf("Combined summary saved to: %s\n", summaryPath)
+	return nil
+}
+
+// saveSummary writes a MultiSkillSummary to path, as YAML or JSON like saveOutcome.
 func saveSummary(summary *models.MultiSkillSummary, path string) error {
 	data, err := json.MarshalIndent(summary, "", "  ")
 	if err != nil {
 		return err
 	}
+	if isYAMLPath(path) {
+		if data, err = jsonToYAML(data); err != nil {
+			return err
+		}
+	}
 
 	return os.WriteFile(path, data, 0644)
 }
 
-// writeOutputDir writes results to a structured directory hierarchy.
-// For multi-skill runs: {outputDir}/{skillName}/{modelName}.json
-// For single-skill runs: {outputDir}/{modelName}.json
+// writeOutputDir writes a run's results to a structured bundle directory:
+//
+//	multi-skill:  {outputDir}/{skillName}/{modelName}.json
+//	single-skill: {outputDir}/{modelName}.json
+//	always:       {outputDir}/summary.json (unless --no-summary)
+//
+// When a junit reporter is requested, {modelName}.junit.xml is written next to
+// each model's JSON as well.
 func writeOutputDir(dir string, results []skillRunResult) error {
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("create output directory: %w", err)
 	}
 
-	multiSkill := len(results) > 1
+	writeJUnit := slices.ContainsFunc(reporters, func(r string) bool { return strings.HasPrefix(r, "junit:") })
+
+	// Files written per skill, relative to dir, for the summary
+	outputFiles := make([][]string, len(results))
+
+	for i, skillResult := range results {
+		outDir := bundleSkillDir(dir, results, i)
+		if outDir != dir {
+			if err := os.MkdirAll(outDir, 0755); err != nil {
+				return fmt.Errorf("create skill directory %s: %w", outDir, err)
+			}
+		}
 
-	for _, skillResult := range results {
 		for _, mr := range skillResult.outcomes {
 			if mr.outcome == nil {
 				continue
 			}
 
-			var outPath string
-			if multiSkill {
-				// Multi-skill: create skill subdirectory
-				skillDir := filepath.Join(dir, sanitizePathSegment(skillResult.skillName))
-				if err := os.MkdirAll(skillDir, 0755); err != nil {
-					return fmt.Errorf("create skill directory %s: %w", skillDir, err)
+			modelName := sanitizePathSegment(mr.label())
+			outPath := filepath.Join(outDir, modelName+".json")
+			if err := saveOutcome(mr.outcome, outPath); err != nil {
+				return fmt.Errorf("save outcome to %s: %w", outPath, err)
+			}
+			statusf("Results saved to: %s\n", outPath)
+			outputFiles[i] = append(outputFiles[i], bundleRelPath(dir, outPath))
+
+			if writeJUnit {
+				junitPath := filepath.Join(outDir, modelName+".junit.xml")
+				if err := reporting.WriteJUnitXML(mr.outcome, junitPath); err != nil {
+					return fmt.Errorf("write JUnit XML to %s: %w", junitPath, err)
 				}
-				modelFile := sanitizePathSegment(mr.modelID) + ".json"
-				outPath = filepath.Join(skillDir, modelFile)
-			} else {
-				// Single-skill: write directly to output dir
-				modelFile := sanitizePathSegment(mr.modelID) + ".json"
-				outPath = filepath.Join(dir, modelFile)
+				statusf("JUnit XML saved to: %s\n", junitPath)
 			}
+		}
+	}
 
-			if err := saveOutcome(mr.outcome, outPath); err != nil {
-				return fmt.Errorf("save outcome to %s: %w", outPath, err)
+	if noSummary {
+		return nil
+	}
+
+	summary := buildMultiSkillSummary(results)
+	for i := range summary.Skills {
+		summary.Skills[i].OutputFiles = outputFiles[i]
+	}
+	summaryPath := filepath.Join(dir, outputDirSummaryFile)
+	if err := saveSummary(summary, summaryPath); err != nil {
+		return fmt.Errorf("save summary to %s: %w", summaryPath, err)
+	}
+	statusf("Summary saved to: %s\n", summaryPath)
+
+	return nil
+}
+
+// outputDirSummaryFile is the name of the run summary inside an --output-dir bundle.
+const outputDirSummaryFile = "summary.json"
+
+// bundleSkillDir returns the directory inside a bundle that holds results[i]'s
+// files: dir itself, or a per-skill subdirectory for multi-skill runs.
+func bundleSkillDir(dir string, results []skillRunResult, i int) string {
+	if len(results) > 1 {
+		return filepath.Join(dir, sanitizePathSegment(results[i].skillName))
+	}
+	return dir
+}
+
+// Files inside a --report-dir bundle, alongside the --output-dir layout.
+const (
+	reportIndexFile      = "index.html"
+	reportTranscriptsDir = "transcripts"
+)
+
+// writeReportDir writes the --output-dir bundle to dir, adds a badge per model
+// when --badge is set, and links everything, including any transcripts under
+// dir, from a static index.html.
+func writeReportDir(dir string, results []skillRunResult) error {
+	if err := writeOutputDir(dir, results); err != nil {
+		return err
+	}
+
+	index := reporting.ReportIndex{Generated: time.Now()}
+	for i, skillResult := range results {
+		outDir := bundleSkillDir(dir, results, i)
+		skill := ""
+		if len(results) > 1 {
+			skill = skillResult.skillName
+		}
+		for _, mr := range skillResult.outcomes {
+			if mr.outcome == nil {
+				continue
+			}
+			base := filepath.Join(outDir, sanitizePathSegment(mr.label()))
+			entry := reporting.ReportEntry{Skill: skill, Label: mr.label(), Outcome: mr.outcome}
+			entry.Files = append(entry.Files, reportLink(dir, base+".json"))
+			if _, err := os.Stat(base + ".junit.xml"); err == nil {
+				entry.Files = append(entry.Files, reportLink(dir, base+".junit.xml"))
+			}
+			if badgePath != "" {
+				if err := reporting.WriteBadge(mr.outcome, base+".badge.svg", badgeThresholdFor(mr.outcome)); err != nil {
+					return fmt.Errorf("write badge: %w", err)
+				}
+				entry.Files = append(entry.Files, reportLink(dir, base+".badge.svg"))
 			}
-			fmt.Printf("Results saved to: %s\n", outPath)
+			index.Entries = append(index.Entries, entry)
 		}
 	}
 
+	if !noSummary {
+		link := reportLink(dir, filepath.Join(dir, outputDirSummaryFile))
+		index.Summary = &link
+	}
+
+	// Transcripts sent outside the bundle with --transcript-dir aren't linked
+	transcripts, err := filepath.Glob(filepath.Join(dir, reportTranscriptsDir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("list transcripts: %w", err)
+	}
+	for _, path := range transcripts {
+		index.Transcripts = append(index.Transcripts, reportLink(dir, path))
+	}
+
+	indexPath := filepath.Join(dir, reportIndexFile)
+	if err := reporting.WriteHTMLIndex(indexPath, index); err != nil {
+		return err
+	}
+	statusf("Report saved to: %s\n", indexPath)
 	return nil
 }
 
+// openReportOpener opens a report in the default browser, and
+// openReportIsTerminal reports whether there's a user to look at it. Tests
+// replace both.
+var (
+	openReportOpener     = webserver.OpenBrowser
+	openReportIsTerminal = stdoutIsTerminal
+)
+
+// openReportIndex opens the --report-dir index.html when --open is set. It's a
+// no-op when stdout isn't a terminal, as in CI, and a failure to open only
+// prints a warning since the report is already written.
+func openReportIndex(dir string) {
+	if !openReport || !openReportIsTerminal() {
+		return
+	}
+	indexPath, err := filepath.Abs(filepath.Join(dir, reportIndexFile))
+	if err == nil {
+		err = openReportOpener(indexPath)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: couldn't open report: %v\n", err)
+	}
+}
+
+// reportLink links path from the bundle's index.html.
+func reportLink(dir, path string) reporting.ReportLink {
+	rel := bundleRelPath(dir, path)
+	return reporting.ReportLink{Name: rel, Href: rel}
+}
+
+// bundleRelPath returns path relative to the bundle dir, using forward slashes.
+func bundleRelPath(dir, path string) string {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
 // runDiscoverMode walks a directory tree, discovers skills with eval configs, and runs them.
 func runDiscoverMode(cmd *cobra.Command, args []string) error {
 	root := "."
@@ -1583,6 +3267,11 @@ func runDiscoverMode(cmd *cobra.Command, args []string) error {
 	var allSkillResults []skillRunResult
 	var lastErr error
 
+	// One metrics file covers every skill, written once they've all run
+	savedMetricsPath := metricsPath
+	metricsPath = ""
+	defer func() { metricsPath = savedMetricsPath }()
+
 	for i, s := range withEval {
 		fmt.Printf("\n[%d/%d] %s\n", i+1, len(withEval), s.Name)
 
@@ -1617,8 +3306,15 @@ func runDiscoverMode(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 	if len(allSkillResults) > 1 {
 		printSkillRunSummary(allSkillResults)
+		if isCrossProductRun(allSkillResults) {
+			printCrossProductMatrix(allSkillResults)
+		}
 	}
 	fmt.Printf("Results: %d skills evaluated, %d passed, %d failed\n", len(allSkillResults), passed, failed)
 
+	metricsPath = savedMetricsPath
+	if err := writeMetrics(flattenSkillResults(allSkillResults)); err != nil {
+		return err
+	}
 	return lastErr
 }
@@ -20,7 +20,6 @@ import (
 	"github.com/microsoft/waza/internal/models"
 	"github.com/microsoft/waza/internal/template"
 	"github.com/microsoft/waza/internal/transcript"
-	"github.com/microsoft/waza/internal/utils"
 	"gopkg.in/yaml.v3"
 )
 
@@ -140,7 +139,7 @@ func generateFakeSuggestionReport(spec *models.BenchmarkSpec, failedTests, faile
 
 func resolveSuggestionSkillPaths(spec *models.BenchmarkSpec, specPath string) []string {
 	specDir := filepath.Dir(specPath)
-	paths := utils.ResolvePaths(spec.Config.SkillPaths, specDir)
+	paths := spec.ResolveSkillPaths(specDir)
 	paths = append(paths, specDir)
 	paths = append(paths, resolveEvaluatedSkillDirs(spec, specDir, paths)...)
 	sort.Strings(paths)
@@ -612,6 +611,7 @@ func loadTestCasesFromCSV(spec *models.BenchmarkSpec, specPath string) ([]*model
 			Stimulus: models.TestStimulus{
 				Message: prompt,
 			},
+			SourcePath: csvPath,
 		})
 	}
 	return testCases, nil
@@ -782,7 +782,7 @@ func writeSuggestionTranscript(prompt string, res *execution.ExecutionResponse)
 		ErrorMsg:    res.ErrorMsg,
 	}
 
-	if _, err := transcript.Write(transcriptDir, t); err != nil {
+	if _, err := transcript.WriteNamed(transcriptDir, t, transcriptName); err != nil {
 		fmt.Fprintf(os.Stderr, "[WARN] Failed to write suggestion transcript: %v\n", err)
 	}
 }
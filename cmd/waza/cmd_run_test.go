@@ -1,16 +1,23 @@
 package main
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/csv"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"maps"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"runtime"
 	"slices"
 	"sort"
 	"strings"
@@ -19,12 +26,17 @@ import (
 	"time"
 
 	copilot "github.com/github/copilot-sdk/go"
+	"github.com/google/uuid"
 	"github.com/microsoft/waza/internal/execution"
 	"github.com/microsoft/waza/internal/graders"
 	"github.com/microsoft/waza/internal/models"
+	"github.com/microsoft/waza/internal/orchestration"
+	"github.com/microsoft/waza/internal/projectconfig"
+	"github.com/microsoft/waza/internal/reporting"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"gopkg.in/yaml.v3"
 )
 
 // resetRunGlobals zeroes the package-level flag vars so prior tests don't leak.
@@ -32,10 +44,17 @@ func resetRunGlobals() {
 	contextDir = ""
 	outputPath = ""
 	outputDir = ""
+	normalizeOutput = false
 	verbose = false
 	transcriptDir = ""
+	transcriptName = ""
+	resultsStream = ""
 	taskFilters = nil
 	tagFilters = nil
+	graderTags = nil
+	runTags = nil
+	inputFlags = nil
+	inputOverrides = nil
 	parallel = false
 	workers = 0
 	trials = 0
@@ -44,15 +63,60 @@ func resetRunGlobals() {
 	enableCache = false
 	disableCache = false
 	runCacheDir = ".waza-cache"
+	verifyCache = false
+	verifySample = 0.1
+	runCacheConfig = projectconfig.CacheConfig{}
 	modelOverrides = nil
+	engineOverrides = nil
 	recommendFlag = false
 	baselineFlag = false
+	baselineFile = ""
 	sessionLog = false
 	sessionDir = ""
 	noSummary = false
 	reporters = nil
+	badgePath = ""
+	badgeThreshold = 0.8
+	badgeThresholdSet = false
+	metricsPath = ""
+	maxGraders = 0
+	graderTaskLimit = 0
+	reportDir = ""
+	openReport = false
 	suggestFlag = false
 	updateSnapshots = false
+	compactSummary = false
+	tuiProgress = false
+	fixturesLock = ""
+	commentTemplate = ""
+	commentTmpl = nil
+	jsonStdout = false
+	replayDir = ""
+	maxTaskDuration = 0
+	printPrompt = false
+	printConfig = ""
+	listModels = false
+	strictSchema = false
+	difficultyPath = ""
+	reliabilityPath = ""
+	skillSource = ""
+	fetchedSkill = nil
+	noTrigger = false
+	onlyTrigger = false
+	shuffleTasks = false
+	failOnWarning = false
+	skipInvalid = false
+	baseRef = ""
+	tasksFrom = ""
+	taskRange = nil
+	firstNTasks = 0
+	rampUp = 0
+	shuffleSeed = 0
+	envFile = ""
+	comparisonCSV = ""
+	artifactsDir = ""
+	artifactGlobs = nil
+	artifactMaxSize = orchestration.DefaultArtifactMaxBytes
 	newCopilotClientFn = nil
 }
 
@@ -551,6 +615,30 @@ func TestRunCommand_TrialsOverridesSpec(t *testing.T) {
 	assert.Equal(t, 3, result.TestOutcomes[0].Stats.TotalRuns)
 }
 
+func TestRunCommand_TrialsOmittedKeepsSpecValue(t *testing.T) {
+	resetRunGlobals()
+
+	specPath := createTestSpec(t, "mock")
+	data, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(specPath, []byte(strings.Replace(string(data), "trials_per_task: 1", "trials_per_task: 2", 1)), 0o644))
+
+	outFile := filepath.Join(t.TempDir(), "results.json")
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--output", outFile})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	require.NoError(t, cmd.Execute())
+
+	data, err = os.ReadFile(outFile)
+	require.NoError(t, err)
+	var result models.EvaluationOutcome
+	require.NoError(t, json.Unmarshal(data, &result))
+	require.NotEmpty(t, result.TestOutcomes)
+	assert.Equal(t, 2, result.Setup.RunsPerTest)
+	assert.Len(t, result.TestOutcomes[0].Runs, 2)
+}
+
 func TestRunCommand_ParallelRunsMock(t *testing.T) {
 	resetRunGlobals()
 
@@ -565,6 +653,43 @@ func TestRunCommand_ParallelRunsMock(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestRunCommand_ParallelFailedTestsOrderIsStable(t *testing.T) {
+	specPath := createFailingTestSpec(t, "mock")
+	taskDir := filepath.Join(filepath.Dir(specPath), "tasks")
+	require.NoError(t, os.Remove(filepath.Join(taskDir, "task.yaml")))
+	// File names sort opposite to task IDs, so load order differs from ID order
+	for file, id := range map[string]string{"a.yaml": "task-zeta", "b.yaml": "task-mid", "c.yaml": "task-alpha"} {
+		task := "id: " + id + "\nname: " + id + "\ninputs:\n  prompt: \"Explain this code\"\n"
+		require.NoError(t, os.WriteFile(filepath.Join(taskDir, file), []byte(task), 0o644))
+	}
+
+	failedSection := func() string {
+		resetRunGlobals()
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--parallel", "--workers", "4"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		out := captureStdout(t, func() {
+			require.Error(t, cmd.Execute())
+		})
+		start := strings.Index(out, "Failed Tests:")
+		require.GreaterOrEqual(t, start, 0, out)
+		section, _, _ := strings.Cut(out[start:], "\n\n")
+		return section
+	}
+
+	first := failedSection()
+	assert.Equal(t, first, failedSection())
+
+	var names []string
+	for line := range strings.Lines(first) {
+		if name, ok := strings.CutPrefix(line, "  - "); ok {
+			names = append(names, strings.Fields(name)[0])
+		}
+	}
+	assert.Equal(t, []string{"task-alpha", "task-mid", "task-zeta"}, names)
+}
+
 func TestRunCommand_ParallelOverridesSpec(t *testing.T) {
 	resetRunGlobals()
 
@@ -746,242 +871,2224 @@ func TestRunCommand_FormatGitHubComment(t *testing.T) {
 	assert.NoError(t, err, "github-comment format should execute successfully")
 }
 
-func TestRunCommand_FormatInvalid(t *testing.T) {
+func TestRunCommand_CommentTemplate(t *testing.T) {
 	resetRunGlobals()
 
 	specPath := createTestSpec(t, "mock")
+	tmplPath := filepath.Join(t.TempDir(), "comment.tmpl")
+	require.NoError(t, os.WriteFile(tmplPath, []byte("<!-- waza-marker -->\n{{ .BenchName }}: {{ .Digest.TotalTests }} task(s)\n"), 0o644))
 
 	cmd := newRunCommand()
-	cmd.SetArgs([]string{specPath, "--format", "invalid-format"})
+	cmd.SetArgs([]string{specPath, "--format", "github-comment", "--comment-template", tmplPath})
 	cmd.SetOut(io.Discard)
 	cmd.SetErr(io.Discard)
 
-	err := cmd.Execute()
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "unknown output format")
-	assert.Contains(t, err.Error(), "invalid-format")
+	out := captureStdout(t, func() {
+		require.NoError(t, cmd.Execute())
+	})
+	assert.Contains(t, out, "<!-- waza-marker -->\ntest-eval: 1 task(s)")
+	assert.NotContains(t, out, "Waza Eval Results")
 }
 
-// ---------------------------------------------------------------------------
-// --model flag: multi-model support (#39)
-// ---------------------------------------------------------------------------
+func TestRunCommand_CommentTemplateInvalid(t *testing.T) {
+	specPath := createTestSpec(t, "mock")
+	tmplPath := filepath.Join(t.TempDir(), "comment.tmpl")
+	require.NoError(t, os.WriteFile(tmplPath, []byte("{{ .BenchName "), 0o644))
 
-func TestRunCommand_ModelFlagParsed(t *testing.T) {
-	cmd := newRunCommand()
-	require.NoError(t, cmd.ParseFlags([]string{"--model", "gpt-4o-mini"}))
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"does not compile", []string{"--format", "github-comment", "--comment-template", tmplPath}, "parsing comment template"},
+		{"missing file", []string{"--format", "github-comment", "--comment-template", tmplPath + ".missing"}, "reading comment template"},
+		{"wrong format", []string{"--comment-template", tmplPath}, "--comment-template requires --format github-comment"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetRunGlobals()
 
-	vals, err := cmd.Flags().GetStringArray("model")
-	require.NoError(t, err)
-	assert.Equal(t, []string{"gpt-4o-mini"}, vals)
+			cmd := newRunCommand()
+			cmd.SetArgs(append([]string{specPath}, tt.args...))
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
 }
 
-func TestRunCommand_ModelFlagMultiple(t *testing.T) {
+func TestRunCommand_JSONStdout(t *testing.T) {
+	resetRunGlobals()
+
+	specPath := createTestSpec(t, "mock")
+
+	var out bytes.Buffer
 	cmd := newRunCommand()
-	require.NoError(t, cmd.ParseFlags([]string{
-		"--model", "gpt-4o",
-		"--model", "claude-sonnet",
-	}))
+	cmd.SetArgs([]string{specPath, "--json-stdout", "--task", "test-task-*"})
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
 
-	vals, err := cmd.Flags().GetStringArray("model")
-	require.NoError(t, err)
-	assert.Equal(t, []string{"gpt-4o", "claude-sonnet"}, vals)
+	// The banner, filter matches, progress and summary are suppressed rather than redirected
+	human := captureStdout(t, func() {
+		require.NoError(t, cmd.Execute())
+	})
+	assert.Empty(t, human)
+
+	var outcome models.EvaluationOutcome
+	require.NoError(t, json.Unmarshal(out.Bytes(), &outcome), "stdout should be only the outcome JSON: %q", out.String())
+	assert.Equal(t, "test-eval", outcome.BenchName)
+	assert.Equal(t, 1, outcome.Digest.TotalTests)
 }
 
-func TestRunCommand_ModelFlagEdgeCases(t *testing.T) {
+func TestRunCommand_JSONStdoutWriters(t *testing.T) {
+	// Every writer that confirms what it wrote must stay quiet under --json-stdout
 	tests := []struct {
-		name     string
-		flags    []string
-		expected []string
+		name string
+		args func(dir string) []string
+		// The mock engine scores the same with and without skills, which fails a baseline run
+		wantErr bool
 	}{
-		{
-			name:     "single model",
-			flags:    []string{"--model", "gpt-4o"},
-			expected: []string{"gpt-4o"},
-		},
-		{
-			name:     "three models for comparison",
-			flags:    []string{"--model", "gpt-4o", "--model", "claude-sonnet", "--model", "gpt-4o-mini"},
-			expected: []string{"gpt-4o", "claude-sonnet", "gpt-4o-mini"},
-		},
-		{
-			name:     "model with version suffix",
-			flags:    []string{"--model", "gpt-4o-2024-08-06"},
-			expected: []string{"gpt-4o-2024-08-06"},
-		},
+		{"baseline", func(string) []string { return []string{"--baseline"} }, true},
+		{"output-dir", func(dir string) []string { return []string{"--output-dir", filepath.Join(dir, "out")} }, false},
+		{"report-dir", func(dir string) []string { return []string{"--report-dir", filepath.Join(dir, "report")} }, false},
+		{"reporters, badge and metrics", func(dir string) []string {
+			return []string{"--reporter", "junit:" + filepath.Join(dir, "junit.xml"), "--badge", filepath.Join(dir, "badge.svg"), "--metrics", filepath.Join(dir, "waza.prom")}
+		}, false},
+		{"comparison-csv", func(dir string) []string { return []string{"--comparison-csv", filepath.Join(dir, "comparison.csv")} }, false},
+		{"shuffle", func(string) []string { return []string{"--shuffle"} }, false},
+		{"fixtures-lock", func(dir string) []string { return []string{"--fixtures-lock", filepath.Join(dir, "fixtures.lock")} }, false},
+		{"cache", func(dir string) []string { return []string{"--cache", "--cache-dir", filepath.Join(dir, "cache")} }, false},
+		{"session-log", func(dir string) []string { return []string{"--session-log", "--session-dir", dir} }, false},
+		{"transcript-dir", func(dir string) []string { return []string{"--transcript-dir", filepath.Join(dir, "transcripts")} }, false},
+		{"tui and compact", func(string) []string { return []string{"--tui", "--compact"} }, false},
+		{"first-n", func(string) []string { return []string{"--first-n", "1"} }, false},
+		{"results-stream", func(dir string) []string { return []string{"--results-stream", filepath.Join(dir, "results.jsonl")} }, false},
+		{"capture-artifacts", func(dir string) []string { return []string{"--capture-artifacts", filepath.Join(dir, "artifacts")} }, false},
 	}
-
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			resetRunGlobals()
+			specPath := createTestSpec(t, "mock")
+
+			// No SetOut, so the outcome and any stray notice share the real stdout
 			cmd := newRunCommand()
-			require.NoError(t, cmd.ParseFlags(tt.flags))
+			cmd.SetArgs(append([]string{specPath, "--json-stdout"}, tt.args(t.TempDir())...))
+			cmd.SetErr(io.Discard)
+			out := captureStdout(t, func() {
+				err := cmd.Execute()
+				if tt.wantErr {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+			})
 
-			vals, err := cmd.Flags().GetStringArray("model")
-			require.NoError(t, err)
-			assert.Equal(t, tt.expected, vals)
+			var outcome models.EvaluationOutcome
+			require.NoError(t, json.Unmarshal([]byte(out), &outcome), "stdout should be only the outcome JSON: %q", out)
 		})
 	}
 }
 
-func TestParseSnapshotUpdates_DirectTypedSlice(t *testing.T) {
-	input := []graders.SnapshotUpdate{
-		{Path: "a.txt", Snapshot: "expected/a.txt", Status: "updated", LinesChanged: 2},
-	}
+func TestRunCommand_JSONStdoutWithVerbose(t *testing.T) {
+	resetRunGlobals()
 
-	updates, err := parseSnapshotUpdates(input)
-	require.NoError(t, err)
-	require.Len(t, updates, 1)
-	assert.Equal(t, "updated", updates[0].Status)
-}
+	specPath := createTestSpec(t, "mock")
 
-func TestParseSnapshotUpdates_JSONCompatibleShape(t *testing.T) {
-	input := []map[string]any{
-		{
-			"path":          "b.txt",
-			"snapshot":      "expected/b.txt",
-			"status":        "created",
-			"lines_changed": 1,
-		},
-	}
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--json-stdout", "--verbose"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
 
-	updates, err := parseSnapshotUpdates(input)
-	require.NoError(t, err)
-	require.Len(t, updates, 1)
-	assert.Equal(t, "created", updates[0].Status)
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--json-stdout and --verbose are mutually exclusive")
 }
 
-func TestRunCommand_ModelOverridesSpec(t *testing.T) {
+func TestRunCommand_JSONStdoutMultiOutcome(t *testing.T) {
 	resetRunGlobals()
 
-	// Spec declares model: claude-sonnet; CLI overrides with gpt-4o-mini.
-	dir := t.TempDir()
-	taskDir := filepath.Join(dir, "tasks")
-	require.NoError(t, os.MkdirAll(taskDir, 0o755))
-	require.NoError(t, os.WriteFile(
-		filepath.Join(taskDir, "t.yaml"),
-		[]byte("id: t1\nname: t\ninputs:\n  prompt: hi\n"),
-		0o644,
-	))
-
-	spec := `name: test-override
-skill: test-skill
-version: "1.0"
-config:
-  trials_per_task: 1
-  timeout_seconds: 30
-  executor: mock
-  model: claude-sonnet
-tasks:
-  - "tasks/*.yaml"
-`
-	specPath := filepath.Join(dir, "eval.yaml")
-	require.NoError(t, os.WriteFile(specPath, []byte(spec), 0o644))
-	outFile := filepath.Join(dir, "results.json")
+	specPath := createTestSpec(t, "mock")
 
 	cmd := newRunCommand()
-	cmd.SetArgs([]string{specPath, "--model", "gpt-4o-mini", "--output", outFile})
+	cmd.SetArgs([]string{specPath, "--json-stdout", "--model", "a", "--model", "b"})
 	cmd.SetOut(io.Discard)
 	cmd.SetErr(io.Discard)
 
 	err := cmd.Execute()
-	require.NoError(t, err)
-
-	data, err := os.ReadFile(outFile)
-	require.NoError(t, err)
-
-	var result map[string]any
-	require.NoError(t, json.Unmarshal(data, &result))
-	cfg, ok := result["config"].(map[string]any)
-	require.True(t, ok, "expected config key in output JSON")
-	assert.Equal(t, "gpt-4o-mini", cfg["model_id"],
-		"--model flag should override spec config model")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--json-stdout writes a single outcome")
 }
 
-func TestRunCommand_MultiModelExecution(t *testing.T) {
+func TestRunCommand_JSONStdoutWithFormat(t *testing.T) {
 	resetRunGlobals()
 
 	specPath := createTestSpec(t, "mock")
-	outDir := t.TempDir()
-	outFile := filepath.Join(outDir, "results.json")
 
 	cmd := newRunCommand()
-	cmd.SetArgs([]string{
-		specPath,
-		"--model", "gpt-4o",
-		"--model", "claude-sonnet",
-		"--output", outFile,
-	})
+	cmd.SetArgs([]string{specPath, "--json-stdout", "--format", "github-comment"})
 	cmd.SetOut(io.Discard)
 	cmd.SetErr(io.Discard)
 
 	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--json-stdout and --format github-comment are mutually exclusive")
+
+	// --format default is the same as not passing it
+	resetRunGlobals()
+	var out bytes.Buffer
+	cmd = newRunCommand()
+	cmd.SetArgs([]string{specPath, "--json-stdout", "--format", "default"})
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+	require.NoError(t, cmd.Execute())
+	assert.True(t, json.Valid(out.Bytes()))
+}
+
+func TestRunCommand_Replay(t *testing.T) {
+	specPath := createTestSpec(t, "mock")
+	transcripts := filepath.Join(t.TempDir(), "transcripts")
+
+	resetRunGlobals()
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--transcript-dir", transcripts})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	require.NoError(t, cmd.Execute())
+
+	resetRunGlobals()
+	outPath := filepath.Join(t.TempDir(), "replayed.json")
+	cmd = newRunCommand()
+	cmd.SetArgs([]string{specPath, "--replay", transcripts, "-o", outPath})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	require.NoError(t, cmd.Execute())
+
+	data, err := os.ReadFile(outPath)
 	require.NoError(t, err)
+	var outcome models.EvaluationOutcome
+	require.NoError(t, json.Unmarshal(data, &outcome))
+	assert.Equal(t, "replay", outcome.Setup.EngineType)
+	require.Len(t, outcome.TestOutcomes, 1)
+	assert.Equal(t, models.StatusPassed, outcome.TestOutcomes[0].Status)
+	assert.Contains(t, outcome.TestOutcomes[0].Runs[0].FinalOutput, "Mock response")
+}
 
-	// Multi-model saves per-model files: results_<model>.json
-	for _, model := range []string{"gpt-4o", "claude-sonnet"} {
-		perModelPath := filepath.Join(outDir, fmt.Sprintf("results_%s.json", model))
-		data, err := os.ReadFile(perModelPath)
-		require.NoError(t, err, "expected per-model output for %s", model)
-		assert.Greater(t, len(data), 0)
+func TestRunCommand_ReplayErrors(t *testing.T) {
+	specPath := createTestSpec(t, "mock")
 
-		var result map[string]any
-		require.NoError(t, json.Unmarshal(data, &result))
-		cfg, ok := result["config"].(map[string]any)
-		require.True(t, ok)
-		assert.Equal(t, model, cfg["model_id"],
-			"per-model output should reflect the model that was evaluated")
-	}
+	resetRunGlobals()
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--replay", t.TempDir()})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "loading replay transcripts: no transcripts found")
+
+	resetRunGlobals()
+	cmd = newRunCommand()
+	cmd.SetArgs([]string{specPath, "--replay", t.TempDir(), "--baseline"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	err = cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--replay and --baseline are mutually exclusive")
 }
 
-func TestRunCommand_NoModelFlagPreservesYAML(t *testing.T) {
+func TestRunCommand_PrintPrompt(t *testing.T) {
 	resetRunGlobals()
 
-	specPath := createTestSpec(t, "mock") // spec has model: test-model
-	outFile := filepath.Join(t.TempDir(), "results.json")
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data.csv"),
+		[]byte("id,name,lang,prompt\nt1,go-task,Go,Explain {{.Vars.lang}} for {{.Vars.team}}\n"), 0o644))
+	specPath := filepath.Join(dir, "eval.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(`name: prompt-eval
+skill: test-skill
+config:
+  trials_per_task: 1
+  timeout_seconds: 30
+  executor: copilot-sdk
+  model: test-model
+inputs:
+  team: platform
+tasks_from: data.csv
+`), 0o644))
 
 	cmd := newRunCommand()
-	cmd.SetArgs([]string{specPath, "--output", outFile})
+	cmd.SetArgs([]string{specPath, "--print-prompt"})
 	cmd.SetOut(io.Discard)
 	cmd.SetErr(io.Discard)
+	out := captureStdout(t, func() {
+		require.NoError(t, cmd.Execute())
+	})
 
-	err := cmd.Execute()
+	assert.Contains(t, out, "=== go-task (t1) ===\nExplain Go for platform\n")
+	// The engine is never started
+	assert.NotContains(t, out, "Running benchmark")
+}
+
+func TestRunCommand_InputOverride(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data.csv"),
+		[]byte("id,name,prompt\nt1,csv-task,Explain {{.Vars.lang}} for {{.Vars.team}}\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "tasks"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tasks", "file-task.yaml"),
+		[]byte("id: f1\nname: file-task\nsystem_prompt: \"You review {{.Vars.lang}} for {{.Vars.team}}\"\ninputs:\n  prompt: \"Review this\"\n"), 0o644))
+	writeSpec := func(tasks string) string {
+		path := filepath.Join(dir, tasks+".yaml")
+		spec := "name: input-eval\nskill: test-skill\nconfig:\n  trials_per_task: 1\n  timeout_seconds: 30\n  executor: mock\n  model: test-model\ninputs:\n  team: platform\n  lang: Go\n"
+		if tasks == "csv" {
+			spec += "tasks_from: data.csv\n"
+		} else {
+			spec += "tasks:\n  - \"tasks/*.yaml\"\n"
+		}
+		require.NoError(t, os.WriteFile(path, []byte(spec), 0o644))
+		return path
+	}
+	printPrompts := func(specPath string, args ...string) (string, error) {
+		resetRunGlobals()
+		cmd := newRunCommand()
+		cmd.SetArgs(append([]string{specPath, "--print-prompt"}, args...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		var err error
+		out := captureStdout(t, func() {
+			err = cmd.Execute()
+		})
+		return out, err
+	}
+
+	out, err := printPrompts(writeSpec("csv"), "--input", "team=infra", "--input", "region=eu")
 	require.NoError(t, err)
+	assert.Contains(t, out, "Explain Go for infra\n", "--input wins over the spec's inputs")
 
-	data, err := os.ReadFile(outFile)
+	out, err = printPrompts(writeSpec("files"), "--input", "lang=Rust")
+	require.NoError(t, err)
+	assert.Contains(t, out, "[system] You review Rust for platform\n")
+
+	out, err = printPrompts(writeSpec("files"))
+	require.NoError(t, err)
+	assert.Contains(t, out, "[system] You review Go for platform\n", "overrides don't leak between runs")
+
+	_, err = printPrompts(writeSpec("csv"), "--input", "team")
+	require.ErrorContains(t, err, `--input "team" must be key=value`)
+	_, err = printPrompts(writeSpec("csv"), "--input", "=infra")
+	require.ErrorContains(t, err, "must be key=value")
+}
+
+func TestParseInputFlags(t *testing.T) {
+	inputs, err := parseInputFlags([]string{"a=1", "b=x=y", "c=", "a=2"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "2", "b": "x=y", "c": ""}, inputs)
+
+	inputs, err = parseInputFlags(nil)
+	require.NoError(t, err)
+	assert.Nil(t, inputs)
+}
+
+func TestRunCommand_CaptureArtifacts(t *testing.T) {
+	resetRunGlobals()
+
+	specPath := createTestSpec(t, "mock")
+	artifacts := t.TempDir()
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--capture-artifacts", artifacts})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	captureStdout(t, func() {
+		require.NoError(t, cmd.Execute())
+	})
+	// The mock engine's workspace is empty for this task, so nothing is captured
+	assert.NoDirExists(t, filepath.Join(artifacts, "test-task-001"))
+
+	resetRunGlobals()
+	cmd = newRunCommand()
+	cmd.SetArgs([]string{specPath, "--artifact-glob", "*.go"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	err := cmd.Execute()
+	require.ErrorContains(t, err, "--artifact-glob requires --capture-artifacts")
+}
+
+func TestRunCommand_StrictSchema(t *testing.T) {
+	// createTestSpec omits metrics, which the eval schema requires
+	newValidSpec := func(t *testing.T) string {
+		specPath := createTestSpec(t, "mock")
+		f, err := os.OpenFile(specPath, os.O_APPEND|os.O_WRONLY, 0o644)
+		require.NoError(t, err)
+		_, err = f.WriteString("metrics:\n  - name: accuracy\n    weight: 1.0\n    threshold: 0.8\n")
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		return specPath
+	}
+	newSpecWithBadTask := func(t *testing.T) string {
+		specPath := newValidSpec(t)
+		// timeout_seconds must be >= 1; the loader accepts it but the schema doesn't
+		bad := `id: bad-task
+name: Bad Task
+timeout_seconds: 0
+inputs:
+  prompt: "Explain this code"
+`
+		require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(specPath), "tasks", "bad.yaml"), []byte(bad), 0o644))
+		return specPath
+	}
+
+	t.Run("aborts with schema errors", func(t *testing.T) {
+		resetRunGlobals()
+
+		specPath := newSpecWithBadTask(t)
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--strict-schema"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		var err error
+		out := captureStdout(t, func() {
+			err = cmd.Execute()
+		})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "schema validation failed")
+		assert.Contains(t, err.Error(), filepath.Join("tasks", "bad.yaml"))
+		assert.Contains(t, err.Error(), "timeout_seconds")
+		assert.NotContains(t, err.Error(), "eval.yaml:")
+		assert.NotContains(t, out, "Running benchmark")
+	})
+
+	t.Run("runs without strict mode", func(t *testing.T) {
+		resetRunGlobals()
+
+		specPath := newSpecWithBadTask(t)
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		captureStdout(t, func() {
+			require.NoError(t, cmd.Execute())
+		})
+	})
+
+	t.Run("valid spec passes strict mode", func(t *testing.T) {
+		resetRunGlobals()
+
+		specPath := newValidSpec(t)
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--strict-schema"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		captureStdout(t, func() {
+			require.NoError(t, cmd.Execute())
+		})
+	})
+}
+
+func TestRunCommand_DifficultyWeights(t *testing.T) {
+	t.Run("weights the weighted score", func(t *testing.T) {
+		resetRunGlobals()
+
+		specPath := createTestSpec(t, "mock")
+		historyPath := filepath.Join(t.TempDir(), "history.json")
+		require.NoError(t, os.WriteFile(historyPath, []byte(`{"tasks": {"test-task-001": {"failure_rate": 0.5}}}`), 0o644))
+		outputPath := filepath.Join(t.TempDir(), "results.json")
+
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--difficulty-weights", historyPath, "--output", outputPath})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		captureStdout(t, func() {
+			require.NoError(t, cmd.Execute())
+		})
+
+		data, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		var outcome models.EvaluationOutcome
+		require.NoError(t, json.Unmarshal(data, &outcome))
+		assert.Equal(t, true, outcome.Metadata["difficulty_weighted"])
+	})
+
+	t.Run("bad history file fails", func(t *testing.T) {
+		resetRunGlobals()
+
+		specPath := createTestSpec(t, "mock")
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--difficulty-weights", filepath.Join(t.TempDir(), "missing.json")})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		var err error
+		captureStdout(t, func() {
+			err = cmd.Execute()
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "reading difficulty history")
+	})
+}
+
+func TestRunCommand_GraderReliability(t *testing.T) {
+	t.Run("scales grader weights", func(t *testing.T) {
+		resetRunGlobals()
+
+		specPath := createTestSpec(t, "mock")
+		historyPath := filepath.Join(t.TempDir(), "history.json")
+		require.NoError(t, os.WriteFile(historyPath, []byte(`{"graders": {"judge": {"reliability": 0.5}}}`), 0o644))
+		outputPath := filepath.Join(t.TempDir(), "results.json")
+
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--grader-reliability", historyPath, "--output", outputPath})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		captureStdout(t, func() {
+			require.NoError(t, cmd.Execute())
+		})
+
+		outcome, err := loadOutcomeFile(outputPath)
+		require.NoError(t, err)
+		assert.Equal(t, true, outcome.Metadata["grader_reliability_weighted"])
+	})
+
+	t.Run("bad history file fails", func(t *testing.T) {
+		resetRunGlobals()
+
+		specPath := createTestSpec(t, "mock")
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--grader-reliability", filepath.Join(t.TempDir(), "missing.json")})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		var err error
+		captureStdout(t, func() {
+			err = cmd.Execute()
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "reading grader reliability history")
+	})
+}
+
+func TestRunCommand_SkillSource(t *testing.T) {
+	// A gzipped tarball wrapping the skill in a versioned directory, as release
+	// artifacts usually are
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	skillMD := "---\nname: packaged-skill\ndescription: Released build\n---\n\n# Packaged\n"
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "packaged-skill-1.0.0/SKILL.md", Mode: 0o644, Size: int64(len(skillMD))}))
+	_, err := tw.Write([]byte(skillMD))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	archive := filepath.Join(t.TempDir(), "packaged-skill-1.0.0.tgz")
+	require.NoError(t, os.WriteFile(archive, buf.Bytes(), 0o644))
+
+	newSpec := func(t *testing.T) string {
+		specPath := createTestSpec(t, "mock")
+		data, err := os.ReadFile(specPath)
+		require.NoError(t, err)
+		// required_skills only passes if the fetched SKILL.md is in the run's skill directories
+		spec := strings.Replace(string(data), "config:\n", "config:\n  skill_directories: [./not-checked-out]\n  required_skills: [packaged-skill]\n", 1)
+		require.NoError(t, os.WriteFile(specPath, []byte(spec), 0o644))
+		return specPath
+	}
+
+	t.Run("evaluates the extracted skill", func(t *testing.T) {
+		resetRunGlobals()
+
+		outputPath := filepath.Join(t.TempDir(), "results.json")
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{newSpec(t), "--skill-source", archive, "--output", outputPath, "-v"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		out := captureStdout(t, func() {
+			require.NoError(t, cmd.Execute())
+		})
+
+		assert.Contains(t, out, "Using skill packaged-skill from "+archive)
+		assert.Contains(t, out, "Required skills validation passed")
+		assert.Regexp(t, `Skill Directories:\n  - .*waza-skill-[^/\\]+[/\\]packaged-skill-1\.0\.0\n`, out)
+
+		outcome, err := loadOutcomeFile(outputPath)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"ref": archive, "name": "packaged-skill"}, outcome.Metadata["skill_source"])
+		assert.Nil(t, fetchedSkill, "the extracted skill is released after the run")
+	})
+
+	t.Run("without it the required skill is missing", func(t *testing.T) {
+		resetRunGlobals()
+
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{newSpec(t)})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		var err error
+		captureStdout(t, func() {
+			err = cmd.Execute()
+		})
+		require.ErrorContains(t, err, "packaged-skill")
+	})
+
+	t.Run("unsafe archive fails", func(t *testing.T) {
+		resetRunGlobals()
+
+		var bad bytes.Buffer
+		tw := tar.NewWriter(&bad)
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "../escape", Mode: 0o644, Size: 1}))
+		_, err := tw.Write([]byte("x"))
+		require.NoError(t, err)
+		require.NoError(t, tw.Close())
+		badArchive := filepath.Join(t.TempDir(), "bad.tar")
+		require.NoError(t, os.WriteFile(badArchive, bad.Bytes(), 0o644))
+
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{newSpec(t), "--skill-source", badArchive})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		captureStdout(t, func() {
+			err = cmd.Execute()
+		})
+		require.ErrorContains(t, err, "escapes the extraction directory")
+	})
+}
+
+func TestRunCommand_TriggerSelection(t *testing.T) {
+	newSpecWithTriggers := func(t *testing.T) string {
+		specPath := createTestSpec(t, "mock")
+		triggers := `skill: test-skill
+should_trigger_prompts:
+  - prompt: "Explain this code to me"
+    confidence: high
+should_not_trigger_prompts:
+  - prompt: "What's the weather today?"
+    confidence: high
+`
+		require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(specPath), "trigger_tests.yaml"), []byte(triggers), 0o644))
+		return specPath
+	}
+	run := func(t *testing.T, args ...string) (*models.EvaluationOutcome, error) {
+		outputPath := filepath.Join(t.TempDir(), "results.json")
+		cmd := newRunCommand()
+		cmd.SetArgs(append(args, "--output", outputPath))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		var err error
+		captureStdout(t, func() {
+			err = cmd.Execute()
+		})
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(outputPath)
+		require.NoError(t, err)
+		var outcome models.EvaluationOutcome
+		require.NoError(t, json.Unmarshal(data, &outcome))
+		return &outcome, nil
+	}
+
+	t.Run("runs both by default", func(t *testing.T) {
+		resetRunGlobals()
+
+		outcome, err := run(t, newSpecWithTriggers(t))
+		require.NoError(t, err)
+		assert.Len(t, outcome.TestOutcomes, 1)
+		assert.Len(t, outcome.TriggerResults, 2)
+	})
+
+	t.Run("no-trigger skips trigger tests", func(t *testing.T) {
+		resetRunGlobals()
+
+		outcome, err := run(t, newSpecWithTriggers(t), "--no-trigger")
+		require.NoError(t, err)
+		assert.Len(t, outcome.TestOutcomes, 1)
+		assert.Empty(t, outcome.TriggerResults)
+		assert.Nil(t, outcome.TriggerMetrics)
+	})
+
+	t.Run("no-trigger ignores an invalid trigger file", func(t *testing.T) {
+		resetRunGlobals()
+
+		specPath := createTestSpec(t, "mock")
+		require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(specPath), "trigger_tests.yaml"), []byte("not: [valid"), 0o644))
+		_, err := run(t, specPath, "--no-trigger")
+		require.NoError(t, err)
+	})
+
+	t.Run("only-trigger skips eval tasks", func(t *testing.T) {
+		resetRunGlobals()
+
+		outcome, err := run(t, newSpecWithTriggers(t), "--only-trigger")
+		require.NoError(t, err)
+		assert.Empty(t, outcome.TestOutcomes)
+		assert.Zero(t, outcome.Digest.TotalTests)
+		assert.Len(t, outcome.TriggerResults, 2)
+		require.NotNil(t, outcome.TriggerMetrics)
+		assert.Equal(t, 1.0, outcome.TriggerMetrics.Accuracy)
+	})
+
+	t.Run("only-trigger ignores failing eval tasks", func(t *testing.T) {
+		resetRunGlobals()
+
+		specPath := createFailingTestSpec(t, "mock")
+		require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(specPath), "trigger_tests.yaml"),
+			[]byte("skill: test-skill\nshould_trigger_prompts:\n  - prompt: \"Explain this code\"\n"), 0o644))
+
+		_, err := run(t, specPath)
+		require.Error(t, err)
+		_, err = run(t, specPath, "--only-trigger")
+		require.NoError(t, err)
+	})
+
+	t.Run("only-trigger without trigger tests fails", func(t *testing.T) {
+		resetRunGlobals()
+
+		_, err := run(t, createTestSpec(t, "mock"), "--only-trigger")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no trigger_tests.yaml")
+	})
+
+	t.Run("flags are mutually exclusive", func(t *testing.T) {
+		resetRunGlobals()
+
+		_, err := run(t, newSpecWithTriggers(t), "--no-trigger", "--only-trigger")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "mutually exclusive")
+	})
+}
+
+func TestRunCommand_Notify(t *testing.T) {
+	newSpecWithNotify := func(t *testing.T, webhookURL string) string {
+		specPath := createTestSpec(t, "mock")
+		f, err := os.OpenFile(specPath, os.O_APPEND|os.O_WRONLY, 0o644)
+		require.NoError(t, err)
+		_, err = f.WriteString("notify:\n  webhook_url: " + webhookURL + "\n")
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		return specPath
+	}
+	run := func(t *testing.T, specPath string) error {
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		var err error
+		captureStdout(t, func() {
+			err = cmd.Execute()
+		})
+		return err
+	}
+
+	t.Run("posts summary", func(t *testing.T) {
+		resetRunGlobals()
+
+		var texts []string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var payload map[string]string
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			texts = append(texts, payload["text"])
+		}))
+		defer srv.Close()
+
+		require.NoError(t, run(t, newSpecWithNotify(t, srv.URL)))
+		require.Len(t, texts, 1)
+		assert.Contains(t, texts[0], "test-skill")
+		assert.Contains(t, texts[0], "1/1 passed (100.0%)")
+	})
+
+	t.Run("webhook errors are not fatal", func(t *testing.T) {
+		resetRunGlobals()
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		require.NoError(t, run(t, newSpecWithNotify(t, srv.URL)))
+	})
+}
+
+func TestRunCommand_EnvFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("program grader uses sh")
+	}
+
+	// The grader only passes when it sees the loaded value and the original one
+	newSpec := func(t *testing.T) string {
+		specPath := createTestSpec(t, "mock")
+		f, err := os.OpenFile(specPath, os.O_APPEND|os.O_WRONLY, 0o644)
+		require.NoError(t, err)
+		_, err = f.WriteString(`graders:
+  - type: program
+    name: env-check
+    config:
+      command: sh
+      args: ["-c", "test \"$WAZA_TEST_DOTENV\" = from-file && test \"$WAZA_TEST_EXISTING\" = original"]
+`)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		return specPath
+	}
+	const dotenv = "WAZA_TEST_DOTENV=from-file\nWAZA_TEST_EXISTING=clobbered\n"
+	run := func(t *testing.T, args ...string) error {
+		// Restore the environment the run modifies
+		t.Setenv("WAZA_TEST_DOTENV", "")
+		require.NoError(t, os.Unsetenv("WAZA_TEST_DOTENV"))
+		t.Setenv("WAZA_TEST_EXISTING", "original")
+
+		cmd := newRunCommand()
+		cmd.SetArgs(args)
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		var err error
+		captureStdout(t, func() {
+			err = cmd.Execute()
+		})
+		return err
+	}
+
+	t.Run("loads .env from the spec directory", func(t *testing.T) {
+		resetRunGlobals()
+
+		specPath := newSpec(t)
+		require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(specPath), ".env"), []byte(dotenv), 0o600))
+
+		require.NoError(t, run(t, specPath))
+		assert.Equal(t, "from-file", os.Getenv("WAZA_TEST_DOTENV"))
+		assert.Equal(t, "original", os.Getenv("WAZA_TEST_EXISTING"))
+	})
+
+	t.Run("--env-file overrides the default location", func(t *testing.T) {
+		resetRunGlobals()
+
+		envPath := filepath.Join(t.TempDir(), "ci.env")
+		require.NoError(t, os.WriteFile(envPath, []byte(dotenv), 0o600))
+
+		require.NoError(t, run(t, newSpec(t), "--env-file", envPath))
+		assert.Equal(t, "from-file", os.Getenv("WAZA_TEST_DOTENV"))
+	})
+
+	t.Run("without an env file the grader can't see the variable", func(t *testing.T) {
+		resetRunGlobals()
+
+		require.Error(t, run(t, newSpec(t)))
+	})
+
+	t.Run("missing --env-file fails", func(t *testing.T) {
+		resetRunGlobals()
+
+		err := run(t, newSpec(t), "--env-file", filepath.Join(t.TempDir(), "missing.env"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "loading env file")
+	})
+}
+
+func TestRunCommand_ListModels(t *testing.T) {
+	t.Run("mock prints configured models", func(t *testing.T) {
+		resetRunGlobals()
+
+		specPath := createTestSpec(t, "mock")
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--list-models"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		out := captureStdout(t, func() {
+			require.NoError(t, cmd.Execute())
+		})
+
+		assert.Contains(t, out, "Configured models (test-eval):\n  test-model\n")
+		assert.Contains(t, out, "Available models (mock): engine does not enumerate models")
+		assert.NotContains(t, out, "Running benchmark")
+	})
+
+	t.Run("--model overrides configured list", func(t *testing.T) {
+		resetRunGlobals()
+
+		specPath := createTestSpec(t, "mock")
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--list-models", "--model", "m1", "--model", "m2"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		out := captureStdout(t, func() {
+			require.NoError(t, cmd.Execute())
+		})
+
+		assert.Contains(t, out, "Configured models (test-eval):\n  m1\n  m2\n")
+	})
+
+	t.Run("copilot-sdk queries the engine", func(t *testing.T) {
+		resetRunGlobals()
+		ctrl := gomock.NewController(t)
+		newCopilotClientFn = func(clientOptions *copilot.ClientOptions) execution.CopilotClient {
+			client := newClientMock(ctrl)
+			client.EXPECT().ListModels(gomock.Any()).Return([]copilot.ModelInfo{{ID: "gpt-4o"}, {ID: "claude-sonnet-4.5"}}, nil)
+			return client
+		}
+
+		specPath := createTestSpec(t, "copilot-sdk")
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--list-models"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		out := captureStdout(t, func() {
+			require.NoError(t, cmd.Execute())
+		})
+
+		assert.Contains(t, out, "Available models (copilot-sdk):\n  gpt-4o\n  claude-sonnet-4.5\n")
+	})
+
+	t.Run("copilot-sdk listing failure degrades", func(t *testing.T) {
+		resetRunGlobals()
+		ctrl := gomock.NewController(t)
+		newCopilotClientFn = func(clientOptions *copilot.ClientOptions) execution.CopilotClient {
+			client := newClientMock(ctrl)
+			client.EXPECT().ListModels(gomock.Any()).Return(nil, errors.New("not supported"))
+			return client
+		}
+
+		specPath := createTestSpec(t, "copilot-sdk")
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--list-models"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		out := captureStdout(t, func() {
+			require.NoError(t, cmd.Execute())
+		})
+
+		assert.Contains(t, out, "Configured models (test-eval):\n  test-model\n")
+		assert.Contains(t, out, "Available models (copilot-sdk): unavailable: failed to list copilot models: not supported")
+	})
+}
+
+func TestRunCommand_MultiEngine(t *testing.T) {
+	resetRunGlobals()
+
+	specPath := createTestSpec(t, "mock")
+	outDir := t.TempDir()
+	outPath := filepath.Join(outDir, "results.json")
+
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--engine", "mock", "--engine", "mock", "--model", "m1", "-o", outPath})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	out := captureStdout(t, func() {
+		require.NoError(t, cmd.Execute())
+	})
+
+	for _, label := range []string{"mock_m1", "mock-2_m1"} {
+		data, err := os.ReadFile(filepath.Join(outDir, "results_"+label+".json"))
+		require.NoError(t, err, "missing per-engine output for %s", label)
+		var outcome models.EvaluationOutcome
+		require.NoError(t, json.Unmarshal(data, &outcome))
+		assert.Equal(t, "mock", outcome.Setup.EngineType)
+		assert.Equal(t, "m1", outcome.Setup.ModelID)
+	}
+	_, err := os.Stat(outPath)
+	assert.True(t, os.IsNotExist(err), "combined --output path should not be written for multi-engine runs")
+
+	assert.Contains(t, out, "ENGINE × MODEL COMPARISON")
+	assert.Contains(t, out, "Engine_Model")
+	assert.Contains(t, out, "mock_m1 ")
+	assert.Contains(t, out, "mock-2_m1 ")
+}
+
+func TestRunCommand_EnvMatrix(t *testing.T) {
+	resetRunGlobals()
+
+	specPath := createTestSpec(t, "mock")
+	dir := filepath.Dir(specPath)
+	hookLog := filepath.Join(dir, "hooks.log")
+	hookScript := filepath.Join(dir, "hook.sh")
+	require.NoError(t, os.WriteFile(hookScript, []byte(`echo "$WAZA_ENV FEATURE_X=$FEATURE_X" >> "$WAZA_SPEC_DIR/hooks.log"`+"\n"), 0o644))
+	spec, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+	spec = bytes.Replace(spec, []byte("  model: test-model\n"), []byte(`  model: test-model
+  env_matrix:
+    - name: flags-on
+      env:
+        FEATURE_X: "1"
+    - name: flags-off
+      env:
+        FEATURE_X: "0"
+hooks:
+  before_run:
+    - command: sh `+hookScript+`
+`), 1)
+	require.NoError(t, os.WriteFile(specPath, spec, 0o644))
+
+	outDir := t.TempDir()
+	outPath := filepath.Join(outDir, "results.json")
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "-o", outPath})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	out := captureStdout(t, func() {
+		require.NoError(t, cmd.Execute())
+	})
+
+	for _, env := range []string{"flags-on", "flags-off"} {
+		data, err := os.ReadFile(filepath.Join(outDir, "results_"+env+"_test-model.json"))
+		require.NoError(t, err, "missing per-environment output for %s", env)
+		var outcome models.EvaluationOutcome
+		require.NoError(t, json.Unmarshal(data, &outcome))
+		assert.Equal(t, env, outcome.Setup.Environment)
+	}
+	_, err = os.Stat(outPath)
+	assert.True(t, os.IsNotExist(err), "combined --output path should not be written for env_matrix runs")
+
+	log, err := os.ReadFile(hookLog)
+	require.NoError(t, err)
+	assert.Equal(t, "flags-on FEATURE_X=1\nflags-off FEATURE_X=0\n", string(log))
+
+	assert.Contains(t, out, "ENVIRONMENT × MODEL COMPARISON")
+	assert.Contains(t, out, "Env_Model")
+	assert.Contains(t, out, "flags-on_test-model ")
+	assert.Contains(t, out, "flags-off_test-model ")
+}
+
+func TestRunCommand_ComparisonCSV(t *testing.T) {
+	readCSV := func(t *testing.T, path string) [][]string {
+		t.Helper()
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close() //nolint:errcheck
+		rows, err := csv.NewReader(f).ReadAll()
+		require.NoError(t, err)
+		return rows
+	}
+	header := []string{"skill", "model", "aggregate_score", "pass_rate", "duration_ms", "input_tokens", "output_tokens", "premium_requests"}
+
+	t.Run("one row per model", func(t *testing.T) {
+		resetRunGlobals()
+		specPath := createTestSpec(t, "mock")
+		csvPath := filepath.Join(t.TempDir(), "comparison.csv")
+
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--model", "m1", "--model", "m2", "--comparison-csv", csvPath})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		captureStdout(t, func() {
+			require.NoError(t, cmd.Execute())
+		})
+
+		rows := readCSV(t, csvPath)
+		require.Len(t, rows, 3)
+		assert.Equal(t, header, rows[0])
+		var labels []string
+		for _, row := range rows[1:] {
+			labels = append(labels, row[1])
+			assert.Equal(t, "1.0000", row[3], "pass rate")
+		}
+		assert.ElementsMatch(t, []string{"m1", "m2"}, labels)
+	})
+
+	t.Run("single model", func(t *testing.T) {
+		resetRunGlobals()
+		specPath := createTestSpec(t, "mock")
+		csvPath := filepath.Join(t.TempDir(), "comparison.csv")
+
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--comparison-csv", csvPath})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		captureStdout(t, func() {
+			require.NoError(t, cmd.Execute())
+		})
+
+		rows := readCSV(t, csvPath)
+		require.Len(t, rows, 2)
+		assert.Equal(t, header, rows[0])
+		assert.Equal(t, "test-model", rows[1][1])
+		// The mock engine reports no usage
+		assert.Equal(t, []string{"", "", ""}, rows[1][5:])
+	})
+
+	t.Run("json stdout", func(t *testing.T) {
+		resetRunGlobals()
+		specPath := createTestSpec(t, "mock")
+		csvPath := filepath.Join(t.TempDir(), "comparison.csv")
+
+		// No SetOut, so the outcome and any stray notice share the real stdout
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--json-stdout", "--comparison-csv", csvPath})
+		cmd.SetErr(io.Discard)
+		out := captureStdout(t, func() {
+			require.NoError(t, cmd.Execute())
+		})
+
+		var outcome models.EvaluationOutcome
+		require.NoError(t, json.Unmarshal([]byte(out), &outcome), "stdout should be only the outcome JSON: %q", out)
+		assert.Len(t, readCSV(t, csvPath), 2)
+	})
+}
+
+func TestRunCommand_BaselineFile(t *testing.T) {
+	writeBaseline := func(t *testing.T, testID string) string {
+		t.Helper()
+		data, err := json.Marshal(&models.EvaluationOutcome{
+			TestOutcomes: []models.TestOutcome{{
+				TestID: testID,
+				Status: models.StatusFailed,
+				Runs:   []models.RunResult{{RunNumber: 1, Status: models.StatusFailed}},
+			}},
+		})
+		require.NoError(t, err)
+		path := filepath.Join(t.TempDir(), "baseline.json")
+		require.NoError(t, os.WriteFile(path, data, 0o644))
+		return path
+	}
+
+	t.Run("merges live run with loaded baseline", func(t *testing.T) {
+		resetRunGlobals()
+		specPath := createTestSpec(t, "mock")
+		outPath := filepath.Join(t.TempDir(), "out.json")
+
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--baseline-file", writeBaseline(t, "test-task-001"), "-o", outPath})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		captureStdout(t, func() {
+			require.NoError(t, cmd.Execute(), "skills improved on the baseline")
+		})
+
+		data, err := os.ReadFile(outPath)
+		require.NoError(t, err)
+		var outcome models.EvaluationOutcome
+		require.NoError(t, json.Unmarshal(data, &outcome))
+		assert.True(t, outcome.IsBaseline)
+		require.NotNil(t, outcome.BaselineOutcome)
+		require.Len(t, outcome.TestOutcomes, 1)
+		require.NotNil(t, outcome.TestOutcomes[0].SkillImpact)
+		assert.Equal(t, 1.0, outcome.TestOutcomes[0].SkillImpact.Delta)
+	})
+
+	t.Run("task mismatch", func(t *testing.T) {
+		resetRunGlobals()
+		specPath := createTestSpec(t, "mock")
+
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--baseline-file", writeBaseline(t, "other-task")})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		var err error
+		captureStdout(t, func() {
+			err = cmd.Execute()
+		})
+		require.ErrorContains(t, err, `baseline mismatch: task "test-task-001"`)
+	})
+
+	t.Run("exclusive with --baseline", func(t *testing.T) {
+		resetRunGlobals()
+		specPath := createTestSpec(t, "mock")
+
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--baseline", "--baseline-file", "baseline.json"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		require.ErrorContains(t, cmd.Execute(), "--baseline-file and --baseline are mutually exclusive")
+	})
+}
+
+func TestRunCommand_Shuffle(t *testing.T) {
+	t.Run("records the seed", func(t *testing.T) {
+		resetRunGlobals()
+		specPath := createTestSpec(t, "mock")
+		outPath := filepath.Join(t.TempDir(), "out.json")
+
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--shuffle", "--seed", "1234", "-o", outPath})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		out := captureStdout(t, func() {
+			require.NoError(t, cmd.Execute())
+		})
+		assert.Contains(t, out, "Shuffling tasks with seed 1234")
+
+		data, err := os.ReadFile(outPath)
+		require.NoError(t, err)
+		var outcome models.EvaluationOutcome
+		require.NoError(t, json.Unmarshal(data, &outcome))
+		assert.Equal(t, float64(1234), outcome.Metadata[orchestration.MetadataShuffleSeed])
+	})
+
+	t.Run("--seed requires --shuffle", func(t *testing.T) {
+		resetRunGlobals()
+		specPath := createTestSpec(t, "mock")
+
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--seed", "1234"})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		require.ErrorContains(t, cmd.Execute(), "--seed requires --shuffle")
+	})
+}
+
+func TestRunCommand_FirstN(t *testing.T) {
+	specPath := createTestSpec(t, "mock")
+	taskDir := filepath.Join(filepath.Dir(specPath), "tasks")
+	for _, id := range []string{"test-task-002", "test-task-003"} {
+		task := "id: " + id + "\nname: " + id + "\ninputs:\n  prompt: \"Explain this code\"\n"
+		require.NoError(t, os.WriteFile(filepath.Join(taskDir, id+".yaml"), []byte(task), 0o644))
+	}
+
+	run := func(args ...string) (string, *models.EvaluationOutcome) {
+		resetRunGlobals()
+		outPath := filepath.Join(t.TempDir(), "out.json")
+		cmd := newRunCommand()
+		cmd.SetArgs(append([]string{specPath, "-o", outPath}, args...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		out := captureStdout(t, func() {
+			require.NoError(t, cmd.Execute())
+		})
+		data, err := os.ReadFile(outPath)
+		require.NoError(t, err)
+		var outcome models.EvaluationOutcome
+		require.NoError(t, json.Unmarshal(data, &outcome))
+		return out, &outcome
+	}
+
+	out, outcome := run("--first-n", "2")
+	assert.Contains(t, out, "Running the first 2 of 3 tasks")
+	assert.Len(t, outcome.TestOutcomes, 2)
+
+	out, outcome = run("--first-n", "10")
+	assert.NotContains(t, out, "Running the first")
+	assert.Len(t, outcome.TestOutcomes, 3)
+
+	resetRunGlobals()
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--first-n", "-1"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	require.ErrorContains(t, cmd.Execute(), "--first-n must be non-negative")
+}
+
+func TestRunCommand_XFail(t *testing.T) {
+	run := func(t *testing.T, task string) (string, *models.EvaluationOutcome, error) {
+		specPath := createTestSpec(t, "mock")
+		require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(specPath), "tasks", "task.yaml"), []byte(task), 0o644))
+
+		resetRunGlobals()
+		outPath := filepath.Join(t.TempDir(), "out.json")
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "-o", outPath})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		var err error
+		out := captureStdout(t, func() {
+			err = cmd.Execute()
+		})
+		data, readErr := os.ReadFile(outPath)
+		require.NoError(t, readErr)
+		var outcome models.EvaluationOutcome
+		require.NoError(t, json.Unmarshal(data, &outcome))
+		return out, &outcome, err
+	}
+
+	t.Run("failing xfail task is not fatal", func(t *testing.T) {
+		out, outcome, err := run(t, `id: test-task-001
+name: Test Task
+xfail: true
+xfail_reason: "tracked in #123"
+inputs:
+  prompt: "Explain this code"
+graders:
+  - name: impossible_check
+    type: code
+    config:
+      assertions:
+        - "False"
+`)
+		require.NoError(t, err)
+		assert.Equal(t, 1, outcome.Digest.XFailed)
+		assert.Equal(t, 0, outcome.Digest.Failed)
+		assert.Equal(t, models.StatusFailed, outcome.TestOutcomes[0].Status)
+		assert.Contains(t, out, "XFailed:        1")
+		assert.Contains(t, out, "Expected Failures (xfail):")
+		assert.Contains(t, out, "Test Task (failed): tracked in #123")
+		assert.NotContains(t, out, "Failed Tests:")
+	})
+
+	t.Run("passing xfail task is reported as xpass", func(t *testing.T) {
+		out, outcome, err := run(t, `id: test-task-001
+name: Test Task
+xfail: true
+inputs:
+  prompt: "Explain this code"
+`)
+		require.NoError(t, err)
+		assert.Equal(t, 1, outcome.Digest.XPassed)
+		assert.Equal(t, 1, outcome.Digest.Succeeded)
+		assert.True(t, outcome.TestOutcomes[0].XPassed())
+		assert.Contains(t, out, "XPassed:        1")
+		assert.Contains(t, out, "Unexpected Passes (xpass; consider removing xfail):")
+	})
+}
+
+func TestRunCommand_FailOnWarning(t *testing.T) {
+	specPath := createTestSpec(t, "mock")
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(specPath), "tasks", "task.yaml"), []byte(`id: test-task-001
+name: Test Task
+inputs:
+  prompt: "Explain this code"
+  files:
+    - path: missing.py
+`), 0o644))
+
+	run := func(args ...string) (*models.EvaluationOutcome, error) {
+		resetRunGlobals()
+		outPath := filepath.Join(t.TempDir(), "out.json")
+		cmd := newRunCommand()
+		cmd.SetArgs(append([]string{specPath, "-o", outPath}, args...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		var err error
+		captureStdout(t, func() {
+			err = cmd.Execute()
+		})
+		data, readErr := os.ReadFile(outPath)
+		require.NoError(t, readErr)
+		var outcome models.EvaluationOutcome
+		require.NoError(t, json.Unmarshal(data, &outcome))
+		return &outcome, err
+	}
+
+	outcome, err := run()
+	require.NoError(t, err, "warnings are non-fatal by default")
+	require.Len(t, outcome.Warnings, 1)
+	assert.Contains(t, outcome.Warnings[0], "failed to load resource file")
+
+	_, err = run("--fail-on-warning")
+	var testFailureErr *TestFailureError
+	require.ErrorAs(t, err, &testFailureErr)
+	assert.Contains(t, err.Error(), "run reported 1 warning(s) with --fail-on-warning")
+	assert.Contains(t, err.Error(), "missing.py")
+}
+
+func TestRunCommand_TasksFrom(t *testing.T) {
+	specPath := createTestSpec(t, "mock")
+	dir := filepath.Dir(specPath)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data.csv"),
+		[]byte("id,name,prompt\nr1,row-1,one\nr2,row-2,two\nr3,row-3,three\nr4,row-4,four\n"), 0o644))
+
+	run := func(t *testing.T, args ...string) ([]string, error) {
+		t.Helper()
+		resetRunGlobals()
+		outPath := filepath.Join(t.TempDir(), "out.json")
+		cmd := newRunCommand()
+		cmd.SetArgs(append([]string{specPath, "-o", outPath}, args...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		var err error
+		captureStdout(t, func() {
+			err = cmd.Execute()
+		})
+		if err != nil {
+			return nil, err
+		}
+		data, err := os.ReadFile(outPath)
+		require.NoError(t, err)
+		var outcome models.EvaluationOutcome
+		require.NoError(t, json.Unmarshal(data, &outcome))
+		var ids []string
+		for _, to := range outcome.TestOutcomes {
+			ids = append(ids, to.TestID)
+		}
+		return ids, nil
+	}
+
+	t.Run("dataset overrides spec tasks", func(t *testing.T) {
+		ids, err := run(t, "--tasks-from", "data.csv")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"r1", "r2", "r3", "r4"}, ids)
+	})
+
+	t.Run("range filters rows", func(t *testing.T) {
+		ids, err := run(t, "--tasks-from", "data.csv", "--range", "2,3")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"r2", "r3"}, ids)
+	})
+
+	t.Run("range needs two values", func(t *testing.T) {
+		_, err := run(t, "--tasks-from", "data.csv", "--range", "2")
+		require.ErrorContains(t, err, "--range must be two values: start,end")
+	})
+
+	t.Run("path must stay in the spec directory", func(t *testing.T) {
+		_, err := run(t, "--tasks-from", "../data.csv")
+		require.ErrorContains(t, err, `tasks_from path "../data.csv" escapes spec directory`)
+	})
+}
+
+func TestRunCommand_Badge(t *testing.T) {
+	specPath := createTestSpec(t, "mock")
+
+	run := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+		resetRunGlobals()
+		badge := filepath.Join(t.TempDir(), "badge.svg")
+		cmd := newRunCommand()
+		cmd.SetArgs(append([]string{specPath, "--badge", badge}, args...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		var err error
+		captureStdout(t, func() {
+			err = cmd.Execute()
+		})
+		if err != nil {
+			return "", err
+		}
+		data, err := os.ReadFile(badge)
+		require.NoError(t, err)
+		return string(data), nil
+	}
+
+	t.Run("writes the pass rate", func(t *testing.T) {
+		svg, err := run(t)
+		require.NoError(t, err)
+		assert.Contains(t, svg, "100% passing")
+		assert.Contains(t, svg, reporting.BadgeColorPassing)
+	})
+
+	t.Run("threshold out of range", func(t *testing.T) {
+		_, err := run(t, "--badge-threshold", "1.5")
+		require.ErrorContains(t, err, "--badge-threshold must be between 0 and 1")
+	})
+}
+
+func TestRunCommand_ReportDir(t *testing.T) {
+	resetRunGlobals()
+	specPath := createTestSpec(t, "mock")
+	dir := filepath.Join(t.TempDir(), "report")
+
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--report-dir", dir, "--reporter", "junit:" + filepath.Join(t.TempDir(), "results.xml"), "--badge", filepath.Join(t.TempDir(), "badge.svg")})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	captureStdout(t, func() {
+		require.NoError(t, cmd.Execute())
+	})
+
+	for _, name := range []string{"index.html", "test-model.json", "test-model.junit.xml", "test-model.badge.svg", "summary.json"} {
+		assert.FileExists(t, filepath.Join(dir, name))
+	}
+	transcripts, err := filepath.Glob(filepath.Join(dir, "transcripts", "*.json"))
+	require.NoError(t, err)
+	require.Len(t, transcripts, 1)
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	require.NoError(t, err)
+	page := string(index)
+	assert.Contains(t, page, `<a href="test-model.json">`)
+	assert.Contains(t, page, `<a href="test-model.junit.xml">`)
+	assert.Contains(t, page, `<a href="transcripts/`+filepath.Base(transcripts[0])+`">`)
+	assert.Contains(t, page, "100.0% passing")
+}
+
+func TestRunCommand_ReportDirExclusive(t *testing.T) {
+	resetRunGlobals()
+	specPath := createTestSpec(t, "mock")
+
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--report-dir", t.TempDir(), "--output-dir", t.TempDir()})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	err := cmd.Execute()
+	require.ErrorContains(t, err, "--report-dir is mutually exclusive with --output and --output-dir")
+}
+
+func TestRunCommand_OpenReport(t *testing.T) {
+	specPath := createTestSpec(t, "mock")
+
+	var opened []string
+	var openErr error
+	isTerminal := true
+	origOpener, origIsTerminal := openReportOpener, openReportIsTerminal
+	t.Cleanup(func() { openReportOpener, openReportIsTerminal = origOpener, origIsTerminal })
+	openReportOpener = func(path string) error {
+		opened = append(opened, path)
+		return openErr
+	}
+	openReportIsTerminal = func() bool { return isTerminal }
+
+	run := func(args ...string) error {
+		resetRunGlobals()
+		cmd := newRunCommand()
+		cmd.SetArgs(append([]string{specPath}, args...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		var err error
+		captureStdout(t, func() {
+			err = cmd.Execute()
+		})
+		return err
+	}
+
+	dir := filepath.Join(t.TempDir(), "report")
+	require.NoError(t, run("--report-dir", dir, "--open"))
+	abs, err := filepath.Abs(filepath.Join(dir, "index.html"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{abs}, opened)
+
+	// Without --open, or without a terminal as in CI, nothing is opened.
+	opened = nil
+	require.NoError(t, run("--report-dir", dir))
+	isTerminal = false
+	require.NoError(t, run("--report-dir", dir, "--open"))
+	assert.Empty(t, opened)
+
+	// A missing opener doesn't fail the run.
+	isTerminal = true
+	openErr = errors.New("xdg-open not found")
+	require.NoError(t, run("--report-dir", dir, "--open"))
+	assert.Len(t, opened, 1)
+
+	require.ErrorContains(t, run("--open"), "--open requires --report-dir")
+}
+
+func TestRunCommand_TranscriptNameTemplate(t *testing.T) {
+	specPath := createTestSpec(t, "mock")
+	run := func(args ...string) error {
+		resetRunGlobals()
+		cmd := newRunCommand()
+		cmd.SetArgs(append([]string{specPath}, args...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		var err error
+		captureStdout(t, func() {
+			err = cmd.Execute()
+		})
+		return err
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, run("--transcript-dir", dir, "--transcript-name-template", "{{.TaskID}}"))
+	_, err := os.Stat(filepath.Join(dir, "test-task-001.json"))
+	require.NoError(t, err)
+
+	require.ErrorContains(t, run("--transcript-dir", dir, "--transcript-name-template", "{{.Nope}}"), "invalid --transcript-name-template")
+}
+
+func TestRunCommand_ResultsStream(t *testing.T) {
+	resetRunGlobals()
+	specPath := createTestSpec(t, "mock")
+	taskDir := filepath.Join(filepath.Dir(specPath), "tasks")
+	require.NoError(t, os.WriteFile(filepath.Join(taskDir, "task2.yaml"), []byte(`id: test-task-002
+name: Second Task
+inputs:
+  prompt: "Explain this too"
+`), 0o644))
+
+	streamPath := filepath.Join(t.TempDir(), "results.jsonl")
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--parallel", "--results-stream", streamPath})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	captureStdout(t, func() {
+		require.NoError(t, cmd.Execute())
+	})
+
+	data, err := os.ReadFile(streamPath)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	var ids []string
+	for _, line := range lines {
+		var outcome models.TestOutcome
+		require.NoError(t, json.Unmarshal([]byte(line), &outcome))
+		ids = append(ids, outcome.TestID)
+	}
+	assert.ElementsMatch(t, []string{"test-task-001", "test-task-002"}, ids)
+}
+
+func TestRunCommand_RedactsSavedResults(t *testing.T) {
+	resetRunGlobals()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "tasks"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tasks", "task.yaml"), []byte(`id: pii-task
+name: PII Task
+inputs:
+  prompt: "Email alice@example.com about token=sk-live-1234567890"
+graders:
+  - type: text
+    name: saw-email
+    config:
+      regex_match: ["alice@example\\.com"]
+`), 0o644))
+	specPath := filepath.Join(dir, "eval.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(`name: redact-eval
+skill: test-skill
+config:
+  trials_per_task: 1
+  timeout_seconds: 30
+  executor: mock
+  model: test-model
+  redact: [pii, "sk-live-[0-9]+"]
+tasks:
+  - "tasks/*.yaml"
+`), 0o644))
+
+	outPath := filepath.Join(t.TempDir(), "results.json")
+	transcripts := t.TempDir()
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "-o", outPath, "--transcript-dir", transcripts})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	captureStdout(t, func() {
+		require.NoError(t, cmd.Execute())
+	})
+
+	// The grader matched the original output...
+	outcome, err := loadOutcomeFile(outPath)
+	require.NoError(t, err)
+	require.Len(t, outcome.TestOutcomes, 1)
+	assert.Equal(t, models.StatusPassed, outcome.TestOutcomes[0].Status)
+	assert.True(t, outcome.TestOutcomes[0].Runs[0].Validations["saw-email"].Passed)
+
+	// ...but nothing written to disk contains it
+	data, err := os.ReadFile(outPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "alice@example.com")
+	assert.NotContains(t, string(data), "sk-live-1234567890")
+	assert.Contains(t, outcome.TestOutcomes[0].Runs[0].FinalOutput, "[REDACTED]")
+
+	entries, err := os.ReadDir(transcripts)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	data, err = os.ReadFile(filepath.Join(transcripts, entries[0].Name()))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "alice@example.com")
+	assert.NotContains(t, string(data), "sk-live-1234567890")
+	assert.Contains(t, string(data), "[REDACTED]")
+}
+
+func TestRunCommand_GitHubActionsFormat(t *testing.T) {
+	resetRunGlobals()
+	specPath := createFailingTestSpec(t, "mock")
+
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--format", "github-actions"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	out := captureStdout(t, func() {
+		require.Error(t, cmd.Execute())
+	})
+
+	assert.Equal(t, 1, strings.Count(out, "::error "), out)
+	assert.Contains(t, out, "::error file="+filepath.ToSlash(filepath.Join(filepath.Dir(specPath), "tasks", "task.yaml"))+",title=waza%3A Test Task::")
+	assert.Contains(t, out, "Task Test Task failed (score 0.00, pass rate 0%25)")
+}
+
+func TestRunCommand_UnknownEngine(t *testing.T) {
+	resetRunGlobals()
+
+	specPath := createTestSpec(t, "mock")
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--engine", "mock", "--engine", "bogus"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown --engine "bogus" (supported: mock, copilot-sdk)`)
+}
+
+func TestEngineLabels(t *testing.T) {
+	assert.Equal(t, []string{"mock", "copilot-sdk", "mock-2", "mock-3"},
+		engineLabels([]string{"mock", "copilot-sdk", "mock", "mock"}))
+}
+
+func TestRunCommand_ModelsFromEnv(t *testing.T) {
+	specPath := createTestSpec(t, "mock")
+
+	runWithEnv := func(t *testing.T, args ...string) *models.EvaluationOutcome {
+		t.Helper()
+		resetRunGlobals()
+		outPath := filepath.Join(t.TempDir(), "out.json")
+		cmd := newRunCommand()
+		cmd.SetArgs(append([]string{specPath, "-o", outPath}, args...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		require.NoError(t, cmd.Execute())
+
+		data, err := os.ReadFile(outPath)
+		require.NoError(t, err)
+		var outcome models.EvaluationOutcome
+		require.NoError(t, json.Unmarshal(data, &outcome))
+		return &outcome
+	}
+
+	t.Run("env overrides spec", func(t *testing.T) {
+		t.Setenv("WAZA_MODELS", "env-model")
+		t.Setenv("WAZA_JUDGE_MODEL", "env-judge")
+
+		outcome := runWithEnv(t)
+		assert.Equal(t, "env-model", outcome.Setup.ModelID)
+		assert.Equal(t, "env-judge", outcome.Setup.JudgeModel)
+	})
+
+	t.Run("flags override env", func(t *testing.T) {
+		t.Setenv("WAZA_MODELS", "env-model")
+		t.Setenv("WAZA_JUDGE_MODEL", "env-judge")
+
+		outcome := runWithEnv(t, "--model", "flag-model", "--judge-model", "flag-judge")
+		assert.Equal(t, "flag-model", outcome.Setup.ModelID)
+		assert.Equal(t, "flag-judge", outcome.Setup.JudgeModel)
+	})
+
+	t.Run("unset env falls back to spec", func(t *testing.T) {
+		t.Setenv("WAZA_MODELS", "")
+		t.Setenv("WAZA_JUDGE_MODEL", "")
+
+		outcome := runWithEnv(t)
+		assert.Equal(t, "test-model", outcome.Setup.ModelID)
+		assert.Empty(t, outcome.Setup.JudgeModel)
+	})
+
+	t.Run("comma-separated list runs each model", func(t *testing.T) {
+		t.Setenv("WAZA_MODELS", " model-a, ,model-b ")
+		resetRunGlobals()
+
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		out := captureStdout(t, func() {
+			require.NoError(t, cmd.Execute())
+		})
+		assert.Equal(t, []string{"model-a", "model-b"}, modelOverrides)
+		assert.Contains(t, out, "Model: model-a")
+		assert.Contains(t, out, "Model: model-b")
+	})
+}
+
+func TestRunCommand_FormatInvalid(t *testing.T) {
+	resetRunGlobals()
+
+	specPath := createTestSpec(t, "mock")
+
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--format", "invalid-format"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown output format")
+	assert.Contains(t, err.Error(), "invalid-format")
+}
+
+// ---------------------------------------------------------------------------
+// --compact summary
+// ---------------------------------------------------------------------------
+
+func TestPrintSummary_Compact(t *testing.T) {
+	resetRunGlobals()
+	compactSummary = true
+	t.Cleanup(resetRunGlobals)
+
+	outcome := &models.EvaluationOutcome{
+		Digest: models.OutcomeDigest{TotalTests: 3, Succeeded: 2, Failed: 1},
+		TestOutcomes: []models.TestOutcome{
+			{DisplayName: "task-a", Status: models.StatusPassed, Stats: &models.TestStats{AvgScore: 0.87, PassRate: 1}},
+			{DisplayName: "task-b", Status: models.StatusPassed, Stats: &models.TestStats{AvgScore: 1, PassRate: 1}},
+			{DisplayName: "task-c", Status: models.StatusFailed, Stats: &models.TestStats{AvgScore: 0.5, PassRate: 0.5, Flaky: true}},
+		},
+	}
+
+	out := captureStdout(t, func() { printSummary(outcome) })
+
+	_, breakdown, found := strings.Cut(out, "PER-TASK BREAKDOWN")
+	require.True(t, found)
+	var taskLines []string
+	for line := range strings.Lines(breakdown) {
+		if strings.HasPrefix(line, "  ") {
+			taskLines = append(taskLines, strings.TrimSpace(line))
+		}
+		if strings.TrimSpace(line) == "" && len(taskLines) > 0 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"✓ task-a 0.87", "✓ task-b 1.00", "✗ task-c 0.50"}, taskLines)
+	assert.NotContains(t, out, "pass_rate=100.0%", "compact mode should suppress per-task stats")
+
+	// failed and flaky sections are still rendered
+	assert.Contains(t, out, "Failed Tests:")
+	assert.Contains(t, out, "Flaky Tasks")
+}
+
+// ---------------------------------------------------------------------------
+// slow tasks
+// ---------------------------------------------------------------------------
+
+func TestPrintSummary_SlowTasks(t *testing.T) {
+	resetRunGlobals()
+
+	outcome := &models.EvaluationOutcome{
+		Setup:  models.OutcomeSetup{SlowTaskMs: 1000},
+		Digest: models.OutcomeDigest{TotalTests: 3, Succeeded: 3},
+		TestOutcomes: []models.TestOutcome{
+			{DisplayName: "quick", Status: models.StatusPassed, Stats: &models.TestStats{PassRate: 1, AvgDurationMs: 200}},
+			{DisplayName: "slow", Status: models.StatusPassed, Stats: &models.TestStats{PassRate: 1, AvgDurationMs: 1500}},
+			{DisplayName: "slowest", Status: models.StatusPassed, Stats: &models.TestStats{PassRate: 1, AvgDurationMs: 4200}},
+		},
+	}
+
+	out := captureStdout(t, func() { printSummary(outcome) })
+
+	_, section, found := strings.Cut(out, "Slow Tasks (avg duration over 1000ms):\n")
+	require.True(t, found, out)
+	assert.True(t, strings.HasPrefix(section, "  - slowest  avg_dur=4200ms\n  - slow  avg_dur=1500ms\n\n"), section)
+	assert.NotContains(t, section, "quick")
+
+	// no budget, no section
+	outcome.Setup.SlowTaskMs = 0
+	out = captureStdout(t, func() { printSummary(outcome) })
+	assert.NotContains(t, out, "Slow Tasks")
+}
+
+func TestPrintSummary_NonDeterministicNote(t *testing.T) {
+	resetRunGlobals()
+
+	outcome := &models.EvaluationOutcome{
+		Digest:   models.OutcomeDigest{TotalTests: 1, Succeeded: 1},
+		Metadata: map[string]any{},
+	}
+
+	out := captureStdout(t, func() { printSummary(outcome) })
+	assert.NotContains(t, out, "may vary")
+
+	outcome.Metadata[orchestration.MetadataNonDeterministic] = true
+	outcome.Metadata[orchestration.MetadataNonDeterministicGraders] = []string{"judge", "limits"}
+	out = captureStdout(t, func() { printSummary(outcome) })
+	assert.Contains(t, out, "results may vary between runs (non-deterministic graders: judge, limits)")
+}
+
+func TestPrintSummary_VerboseRunTiming(t *testing.T) {
+	resetRunGlobals()
+
+	outcome := &models.EvaluationOutcome{
+		Digest: models.OutcomeDigest{TotalTests: 1, Succeeded: 1},
+		TestOutcomes: []models.TestOutcome{{
+			DisplayName: "task",
+			Status:      models.StatusPassed,
+			Runs: []models.RunResult{{
+				RunNumber: 1,
+				Timing: &models.RunTiming{
+					TotalMs:   1200,
+					EngineMs:  400,
+					GradingMs: 790,
+					Graders: []models.GraderTiming{
+						{Name: "rubric", Type: models.GraderKindPrompt, DurationMs: 780},
+						{Name: "regex", Type: models.GraderKindText, DurationMs: 10},
+					},
+				},
+			}},
+		}},
+	}
+
+	out := captureStdout(t, func() { printSummary(outcome) })
+	assert.NotContains(t, out, "engine=")
+
+	verbose = true
+	out = captureStdout(t, func() { printSummary(outcome) })
+	assert.Contains(t, out, "      run 1: total=1200ms engine=400ms grading=790ms [rubric=780ms, regex=10ms]\n")
+}
+
+func TestRunCommand_MaxDurationPerTaskOverridesConfig(t *testing.T) {
+	resetRunGlobals()
+
+	specPath := createTestSpec(t, "mock")
+	outPath := filepath.Join(t.TempDir(), "out.json")
+
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--max-duration-per-task", "2s", "-o", outPath})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	require.NoError(t, cmd.Execute(), "slow tasks never affect the exit code")
+
+	data, err := os.ReadFile(outPath)
+	require.NoError(t, err)
+	var outcome models.EvaluationOutcome
+	require.NoError(t, json.Unmarshal(data, &outcome))
+	assert.Equal(t, int64(2000), outcome.Setup.SlowTaskMs)
+}
+
+// ---------------------------------------------------------------------------
+// --model flag: multi-model support (#39)
+// ---------------------------------------------------------------------------
+
+func TestRunCommand_ModelFlagParsed(t *testing.T) {
+	cmd := newRunCommand()
+	require.NoError(t, cmd.ParseFlags([]string{"--model", "gpt-4o-mini"}))
+
+	vals, err := cmd.Flags().GetStringArray("model")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpt-4o-mini"}, vals)
+}
+
+func TestRunCommand_ModelFlagMultiple(t *testing.T) {
+	cmd := newRunCommand()
+	require.NoError(t, cmd.ParseFlags([]string{
+		"--model", "gpt-4o",
+		"--model", "claude-sonnet",
+	}))
+
+	vals, err := cmd.Flags().GetStringArray("model")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpt-4o", "claude-sonnet"}, vals)
+}
+
+func TestRunCommand_ModelFlagEdgeCases(t *testing.T) {
+	tests := []struct {
+		name     string
+		flags    []string
+		expected []string
+	}{
+		{
+			name:     "single model",
+			flags:    []string{"--model", "gpt-4o"},
+			expected: []string{"gpt-4o"},
+		},
+		{
+			name:     "three models for comparison",
+			flags:    []string{"--model", "gpt-4o", "--model", "claude-sonnet", "--model", "gpt-4o-mini"},
+			expected: []string{"gpt-4o", "claude-sonnet", "gpt-4o-mini"},
+		},
+		{
+			name:     "model with version suffix",
+			flags:    []string{"--model", "gpt-4o-2024-08-06"},
+			expected: []string{"gpt-4o-2024-08-06"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newRunCommand()
+			require.NoError(t, cmd.ParseFlags(tt.flags))
+
+			vals, err := cmd.Flags().GetStringArray("model")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, vals)
+		})
+	}
+}
+
+func TestParseSnapshotUpdates_DirectTypedSlice(t *testing.T) {
+	input := []graders.SnapshotUpdate{
+		{Path: "a.txt", Snapshot: "expected/a.txt", Status: "updated", LinesChanged: 2},
+	}
+
+	updates, err := parseSnapshotUpdates(input)
+	require.NoError(t, err)
+	require.Len(t, updates, 1)
+	assert.Equal(t, "updated", updates[0].Status)
+}
+
+func TestParseSnapshotUpdates_JSONCompatibleShape(t *testing.T) {
+	input := []map[string]any{
+		{
+			"path":          "b.txt",
+			"snapshot":      "expected/b.txt",
+			"status":        "created",
+			"lines_changed": 1,
+		},
+	}
+
+	updates, err := parseSnapshotUpdates(input)
+	require.NoError(t, err)
+	require.Len(t, updates, 1)
+	assert.Equal(t, "created", updates[0].Status)
+}
+
+func TestRunCommand_ModelOverridesSpec(t *testing.T) {
+	resetRunGlobals()
+
+	// Spec declares model: claude-sonnet; CLI overrides with gpt-4o-mini.
+	dir := t.TempDir()
+	taskDir := filepath.Join(dir, "tasks")
+	require.NoError(t, os.MkdirAll(taskDir, 0o755))
+	require.NoError(t, os.WriteFile(
+		filepath.Join(taskDir, "t.yaml"),
+		[]byte("id: t1\nname: t\ninputs:\n  prompt: hi\n"),
+		0o644,
+	))
+
+	spec := `name: test-override
+skill: test-skill
+version: "1.0"
+config:
+  trials_per_task: 1
+  timeout_seconds: 30
+  executor: mock
+  model: claude-sonnet
+tasks:
+  - "tasks/*.yaml"
+`
+	specPath := filepath.Join(dir, "eval.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(spec), 0o644))
+	outFile := filepath.Join(dir, "results.json")
+
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--model", "gpt-4o-mini", "--output", outFile})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+
+	var result map[string]any
+	require.NoError(t, json.Unmarshal(data, &result))
+	cfg, ok := result["config"].(map[string]any)
+	require.True(t, ok, "expected config key in output JSON")
+	assert.Equal(t, "gpt-4o-mini", cfg["model_id"],
+		"--model flag should override spec config model")
+}
+
+func TestRunCommand_MultiModelExecution(t *testing.T) {
+	resetRunGlobals()
+
+	specPath := createTestSpec(t, "mock")
+	outDir := t.TempDir()
+	outFile := filepath.Join(outDir, "results.json")
+
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{
+		specPath,
+		"--model", "gpt-4o",
+		"--model", "claude-sonnet",
+		"--output", outFile,
+	})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	// Multi-model saves per-model files: results_<model>.json
+	for _, model := range []string{"gpt-4o", "claude-sonnet"} {
+		perModelPath := filepath.Join(outDir, fmt.Sprintf("results_%s.json", model))
+		data, err := os.ReadFile(perModelPath)
+		require.NoError(t, err, "expected per-model output for %s", model)
+		assert.Greater(t, len(data), 0)
+
+		var result map[string]any
+		require.NoError(t, json.Unmarshal(data, &result))
+		cfg, ok := result["config"].(map[string]any)
+		require.True(t, ok)
+		assert.Equal(t, model, cfg["model_id"],
+			"per-model output should reflect the model that was evaluated")
+	}
+}
+
+func TestRunCommand_JudgeMap(t *testing.T) {
+	resetRunGlobals()
+
+	specPath := createTestSpec(t, "mock")
+	data, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+	spec := strings.Replace(string(data), "  model: test-model\n", `  model: test-model
+  judge_map:
+    gpt-4o: claude-judge
+    claude-sonnet: gpt-judge
+`, 1)
+	require.NoError(t, os.WriteFile(specPath, []byte(spec), 0o644))
+	outDir := t.TempDir()
+	outFile := filepath.Join(outDir, "results.json")
+
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{
+		specPath,
+		"--model", "gpt-4o",
+		"--model", "claude-sonnet",
+		"--model", "other",
+		"--judge-model", "fallback-judge",
+		"--output", outFile,
+	})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	require.NoError(t, cmd.Execute())
+
+	for model, judge := range map[string]string{
+		"gpt-4o":        "claude-judge",
+		"claude-sonnet": "gpt-judge",
+		"other":         "fallback-judge",
+	} {
+		data, err := os.ReadFile(filepath.Join(outDir, fmt.Sprintf("results_%s.json", model)))
+		require.NoError(t, err)
+		var outcome models.EvaluationOutcome
+		require.NoError(t, json.Unmarshal(data, &outcome))
+		assert.Equal(t, judge, outcome.Setup.JudgeModel, model)
+	}
+}
+
+func TestRunCommand_NoModelFlagPreservesYAML(t *testing.T) {
+	resetRunGlobals()
+
+	specPath := createTestSpec(t, "mock") // spec has model: test-model
+	outFile := filepath.Join(t.TempDir(), "results.json")
+
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--output", outFile})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+
+	var result map[string]any
+	require.NoError(t, json.Unmarshal(data, &result))
+	cfg, ok := result["config"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "test-model", cfg["model_id"],
+		"without --model flag, spec config model should be preserved")
+}
+
+func TestRunCommand_ModelNameInOutputJSON(t *testing.T) {
+	resetRunGlobals()
+
+	specPath := createTestSpec(t, "mock")
+	outFile := filepath.Join(t.TempDir(), "results.json")
+
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--model", "gpt-4o", "--output", outFile})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+
+	var result map[string]any
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	// Model name must appear in config section of output
+	cfg, ok := result["config"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "gpt-4o", cfg["model_id"],
+		"model name should appear in results JSON config")
+}
+
+func TestRunCommand_RunIDInReports(t *testing.T) {
+	specPath := createTestSpec(t, "mock")
+
+	run := func() (string, *models.EvaluationOutcome, *reporting.JUnitTestSuites) {
+		resetRunGlobals()
+		dir := t.TempDir()
+		outFile := filepath.Join(dir, "results.json")
+		junitFile := filepath.Join(dir, "results.xml")
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specPath, "--output", outFile, "--reporter", "junit:" + junitFile})
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		out := captureStdout(t, func() {
+			require.NoError(t, cmd.Execute())
+		})
+
+		outcome, err := loadOutcomeFile(outFile)
+		require.NoError(t, err)
+		data, err := os.ReadFile(junitFile)
+		require.NoError(t, err)
+		var suites reporting.JUnitTestSuites
+		require.NoError(t, xml.Unmarshal(data, &suites))
+		return out, outcome, &suites
+	}
+
+	out, outcome, suites := run()
+	_, err := uuid.Parse(outcome.RunID)
+	require.NoError(t, err, "run ID should be a UUID, got %q", outcome.RunID)
+
+	var junitRunID string
+	for _, p := range suites.TestSuites[0].Properties {
+		if p.Name == "run_id" {
+			junitRunID = p.Value
+		}
+	}
+	assert.Equal(t, outcome.RunID, junitRunID, "JUnit properties should carry the outcome's run ID")
+	assert.Contains(t, out, "Run ID:         "+outcome.RunID)
+
+	// Runs started in the same second no longer share an ID.
+	_, second, _ := run()
+	assert.NotEqual(t, outcome.RunID, second.RunID)
+}
+
+func TestRunCommand_YAMLOutput(t *testing.T) {
+	resetRunGlobals()
+
+	specPath := createTestSpec(t, "mock")
+	outFile := filepath.Join(t.TempDir(), "results.yaml")
+
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--output", outFile})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	require.NoError(t, cmd.Execute())
+
+	data, err := os.ReadFile(outFile)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "eval_id: "), "expected block YAML, got:\n%s", data)
+	assert.Contains(t, string(data), "\nconfig:\n  runs_per_test: 1\n")
+
+	outcome, err := loadOutcomeFile(outFile)
+	require.NoError(t, err)
+	assert.Equal(t, "test-eval", outcome.BenchName)
+	assert.Equal(t, "test-model", outcome.Setup.ModelID)
+	require.Len(t, outcome.TestOutcomes, 1)
+	assert.Equal(t, models.StatusPassed, outcome.TestOutcomes[0].Status)
+
+	// The YAML holds the same document as the JSON output would.
+	jsonPath := filepath.Join(t.TempDir(), "results")
+	require.NoError(t, saveOutcome(outcome, jsonPath))
+	jsonData, err := os.ReadFile(jsonPath)
 	require.NoError(t, err)
-
-	var result map[string]any
-	require.NoError(t, json.Unmarshal(data, &result))
-	cfg, ok := result["config"].(map[string]any)
-	require.True(t, ok)
-	assert.Equal(t, "test-model", cfg["model_id"],
-		"without --model flag, spec config model should be preserved")
+	var fromJSON, fromYAML any
+	require.NoError(t, json.Unmarshal(jsonData, &fromJSON), "extensionless output should be JSON")
+	converted, err := yamlToJSON(data)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(converted, &fromYAML))
+	assert.Equal(t, fromJSON, fromYAML)
 }
 
-func TestRunCommand_ModelNameInOutputJSON(t *testing.T) {
+func TestRunCommand_YAMLOutputMultiModel(t *testing.T) {
 	resetRunGlobals()
 
 	specPath := createTestSpec(t, "mock")
-	outFile := filepath.Join(t.TempDir(), "results.json")
+	outDir := t.TempDir()
 
 	cmd := newRunCommand()
-	cmd.SetArgs([]string{specPath, "--model", "gpt-4o", "--output", outFile})
+	cmd.SetArgs([]string{specPath, "--model", "a", "--model", "b", "--output", filepath.Join(outDir, "results.yml")})
 	cmd.SetOut(io.Discard)
 	cmd.SetErr(io.Discard)
+	captureStdout(t, func() {
+		require.NoError(t, cmd.Execute())
+	})
 
-	err := cmd.Execute()
+	for _, model := range []string{"a", "b"} {
+		outcome, err := loadOutcomeFile(filepath.Join(outDir, "results_"+model+".yml"))
+		require.NoError(t, err)
+		assert.Equal(t, model, outcome.Setup.ModelID)
+	}
+	data, err := os.ReadFile(filepath.Join(outDir, "results_summary.yml"))
 	require.NoError(t, err)
-
-	data, err := os.ReadFile(outFile)
+	assert.False(t, json.Valid(data), "summary should be YAML, not JSON")
+	summaryJSON, err := yamlToJSON(data)
 	require.NoError(t, err)
+	var summary models.MultiSkillSummary
+	require.NoError(t, json.Unmarshal(summaryJSON, &summary))
+	assert.NotEmpty(t, summary.Skills)
+}
 
-	var result map[string]any
-	require.NoError(t, json.Unmarshal(data, &result))
-
-	// Model name must appear in config section of output
-	cfg, ok := result["config"].(map[string]any)
-	require.True(t, ok)
-	assert.Equal(t, "gpt-4o", cfg["model_id"],
-		"model name should appear in results JSON config")
+func TestJSONToYAML_QuotesAmbiguousStrings(t *testing.T) {
+	out, err := jsonToYAML([]byte(`{"name":"true","id":"0123","n":1.5,"empty":[],"none":null,"nested":{"k":"v: x"}}`))
+	require.NoError(t, err)
+	back, err := yamlToJSON(out)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"true","id":"0123","n":1.5,"empty":[],"none":null,"nested":{"k":"v: x"}}`, string(back), string(out))
 }
 
 func TestRunCommand_SingleModelMatchingSpecIsNoop(t *testing.T) {
@@ -1511,6 +3618,81 @@ tasks:
 	require.NoError(t, err, "single-skill run should save to exact output path")
 }
 
+func TestRunCommand_MultipleSpecPaths(t *testing.T) {
+	resetRunGlobals()
+
+	specA := createTestSpec(t, "mock")
+	specB := createTestSpec(t, "mock")
+	outFile := filepath.Join(t.TempDir(), "results.json")
+
+	var execErr error
+	output := captureStdout(t, func() {
+		cmd := newRunCommand()
+		cmd.SetArgs([]string{specA, specB, "--output", outFile})
+		cmd.SetErr(io.Discard)
+		execErr = cmd.Execute()
+	})
+	require.NoError(t, execErr)
+
+	assert.Contains(t, output, "MULTI-SKILL RUN SUMMARY")
+	assert.Equal(t, 2, strings.Count(output, "=== test-skill ==="), "each spec should run as its own skill")
+
+	_, err := os.Stat(filepath.Join(filepath.Dir(outFile), "results_summary.json"))
+	require.NoError(t, err, "combined summary should be written for multiple spec paths")
+}
+
+func TestRunCommand_SingleSkillMultiModelSummary(t *testing.T) {
+	for _, skip := range []bool{false, true} {
+		t.Run(fmt.Sprintf("no-summary=%v", skip), func(t *testing.T) {
+			resetRunGlobals()
+
+			specPath := createTestSpec(t, "mock")
+			outFile := filepath.Join(t.TempDir(), "results.json")
+			args := []string{specPath, "--model", "m1", "--model", "m2", "--output", outFile}
+			if skip {
+				args = append(args, "--no-summary")
+			}
+
+			cmd := newRunCommand()
+			cmd.SetArgs(args)
+			cmd.SetErr(io.Discard)
+			captureStdout(t, func() {
+				require.NoError(t, cmd.Execute())
+			})
+
+			summaryPath := filepath.Join(filepath.Dir(outFile), "results_summary.json")
+			data, err := os.ReadFile(summaryPath)
+			if skip {
+				require.ErrorIs(t, err, os.ErrNotExist)
+				return
+			}
+			require.NoError(t, err)
+
+			var summary models.MultiSkillSummary
+			require.NoError(t, json.Unmarshal(data, &summary))
+			require.Len(t, summary.Skills, 1)
+			skill := summary.Skills[0]
+			assert.Equal(t, "test-skill", skill.SkillName)
+			assert.Equal(t, []string{"m1", "m2"}, skill.Models)
+			assert.Equal(t, 2, summary.Overall.TotalModels)
+
+			require.Len(t, skill.ModelResults, 2)
+			for i, model := range []string{"m1", "m2"} {
+				mr := skill.ModelResults[i]
+				assert.Equal(t, model, mr.Model)
+				assert.Equal(t, 1.0, mr.PassRate)
+				assert.Equal(t, filepath.Join(filepath.Dir(outFile), "results_"+model+".json"), mr.OutputFile)
+			}
+		})
+	}
+}
+
+func TestResolveSpecPaths_MultipleArgsRequirePaths(t *testing.T) {
+	_, err := resolveSpecPaths([]string{"a/eval.yaml", "code-explainer"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "code-explainer")
+}
+
 func TestRunCommand_MultiSkillOutputDoesNotOverwrite(t *testing.T) {
 	// This test verifies the core issue from #271:
 	// When multiple skills run, each needs its own output file
@@ -1879,6 +4061,57 @@ func TestRunCommand_CrossProduct_PartialFailure(t *testing.T) {
 	assert.Equal(t, "results_skill-b_model-2.json", path4)
 }
 
+func TestPrintCrossProductMatrix(t *testing.T) {
+	outcome := func(rate float64) *models.EvaluationOutcome {
+		return &models.EvaluationOutcome{Digest: models.OutcomeDigest{SuccessRate: rate}}
+	}
+	results := []skillRunResult{
+		{skillName: "code-explainer", outcomes: []modelResult{
+			{modelID: "gpt-4o", outcome: outcome(1)},
+			{modelID: "claude-sonnet", outcome: outcome(0.5)},
+		}},
+		{skillName: "sql-generator", outcomes: []modelResult{
+			{modelID: "gpt-4o", outcome: outcome(0.25)},
+			{modelID: "claude-sonnet", outcome: nil},
+		}},
+		{skillName: "summarizer", outcomes: []modelResult{
+			{modelID: "gpt-4o", outcome: outcome(1)},
+		}},
+	}
+
+	require.True(t, isCrossProductRun(results))
+	out := captureStdout(t, func() { printCrossProductMatrix(results) })
+
+	rows := map[string][]string{}
+	var header []string
+	for line := range strings.Lines(out) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "Skill":
+			header = fields[1:]
+		case "code-explainer", "sql-generator", "summarizer":
+			rows[fields[0]] = fields[1:]
+		}
+	}
+
+	// columns are sorted model IDs
+	assert.Equal(t, []string{"claude-sonnet", "gpt-4o"}, header)
+	assert.Equal(t, []string{"~", "50%", "✓", "100%"}, rows["code-explainer"])
+	assert.Equal(t, []string{"—", "✗", "25%"}, rows["sql-generator"], "nil outcome renders as —")
+	assert.Equal(t, []string{"—", "✓", "100%"}, rows["summarizer"], "missing model renders as —")
+}
+
+func TestIsCrossProductRun_SingleModelPerSkill(t *testing.T) {
+	results := []skillRunResult{
+		{skillName: "a", outcomes: []modelResult{{modelID: "m"}}},
+		{skillName: "b", outcomes: []modelResult{{modelID: "m"}}},
+	}
+	assert.False(t, isCrossProductRun(results))
+}
+
 // ---------------------------------------------------------------------------
 // Edge case: special characters in names
 // ---------------------------------------------------------------------------
@@ -1936,7 +4169,7 @@ func TestRunCommand_OutputDirSingleSkill(t *testing.T) {
 	// Find and validate the JSON result file
 	var found bool
 	for _, e := range entries {
-		if filepath.Ext(e.Name()) == ".json" {
+		if filepath.Ext(e.Name()) == ".json" && e.Name() != outputDirSummaryFile {
 			data, err := os.ReadFile(filepath.Join(outDir, e.Name()))
 			require.NoError(t, err)
 			var outcome models.EvaluationOutcome
@@ -1946,6 +4179,49 @@ func TestRunCommand_OutputDirSingleSkill(t *testing.T) {
 		}
 	}
 	assert.True(t, found, "expected at least one .json result in output dir")
+
+	// The summary lands in the bundle too
+	data, err := os.ReadFile(filepath.Join(outDir, outputDirSummaryFile))
+	require.NoError(t, err)
+	var summary models.MultiSkillSummary
+	require.NoError(t, json.Unmarshal(data, &summary))
+	require.Len(t, summary.Skills, 1)
+	assert.Equal(t, "test-skill", summary.Skills[0].SkillName)
+	assert.Equal(t, []string{"test-model.json"}, summary.Skills[0].OutputFiles)
+}
+
+func TestRunCommand_OutputDirIncludesReporterOutputs(t *testing.T) {
+	resetRunGlobals()
+	defer resetRunGlobals()
+
+	specPath := createTestSpec(t, "mock")
+	tmp := t.TempDir()
+	outDir := filepath.Join(tmp, "results")
+
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--output-dir", outDir, "--reporter", "junit:" + filepath.Join(tmp, "junit.xml")})
+	require.NoError(t, cmd.Execute())
+
+	assert.FileExists(t, filepath.Join(outDir, "test-model.json"))
+	assert.FileExists(t, filepath.Join(outDir, "test-model.junit.xml"))
+	assert.FileExists(t, filepath.Join(outDir, outputDirSummaryFile))
+	// the explicitly requested reporter path is still honored
+	assert.FileExists(t, filepath.Join(tmp, "junit.xml"))
+}
+
+func TestWriteOutputDir_NoSummary(t *testing.T) {
+	resetRunGlobals()
+	defer resetRunGlobals()
+	noSummary = true
+
+	dir := t.TempDir()
+	err := writeOutputDir(dir, []skillRunResult{
+		{skillName: "s", outcomes: []modelResult{{modelID: "m", outcome: &models.EvaluationOutcome{}}}},
+	})
+	require.NoError(t, err)
+
+	assert.FileExists(t, filepath.Join(dir, "m.json"))
+	assert.NoFileExists(t, filepath.Join(dir, outputDirSummaryFile))
 }
 
 func TestWriteOutputDir_SingleSkill(t *testing.T) {
@@ -2030,13 +4306,22 @@ func TestWriteOutputDir_MultiSkill(t *testing.T) {
 	assert.DirExists(t, explainerDir)
 	assert.DirExists(t, reviewerDir)
 
+	// Summary lists each skill's files relative to the bundle root
+	data, err := os.ReadFile(filepath.Join(dir, outputDirSummaryFile))
+	require.NoError(t, err)
+	var summary models.MultiSkillSummary
+	require.NoError(t, json.Unmarshal(data, &summary))
+	require.Len(t, summary.Skills, 2)
+	assert.Equal(t, []string{"code-explainer/gpt-4o.json", "code-explainer/claude-sonnet.json"}, summary.Skills[0].OutputFiles)
+	assert.Equal(t, []string{"code-reviewer/gpt-4o.json"}, summary.Skills[1].OutputFiles)
+
 	// Verify files
 	assert.FileExists(t, filepath.Join(explainerDir, "gpt-4o.json"))
 	assert.FileExists(t, filepath.Join(explainerDir, "claude-sonnet.json"))
 	assert.FileExists(t, filepath.Join(reviewerDir, "gpt-4o.json"))
 
 	// Verify content
-	data, err := os.ReadFile(filepath.Join(explainerDir, "gpt-4o.json"))
+	data, err = os.ReadFile(filepath.Join(explainerDir, "gpt-4o.json"))
 	require.NoError(t, err)
 	var outcome models.EvaluationOutcome
 	require.NoError(t, json.Unmarshal(data, &outcome))
@@ -2090,6 +4375,58 @@ func TestWriteOutputDir_SanitizesPaths(t *testing.T) {
 // .waza.yaml config defaults
 // ---------------------------------------------------------------------------
 
+func TestRunCommand_PrintConfig(t *testing.T) {
+	specPath := createTestSpec(t, "mock")
+	dir := filepath.Dir(specPath)
+	wazaYAML := `defaults:
+  parallel: true
+  workers: 12
+  judgeModel: "gpt-4o-judge"
+cache:
+  enabled: true
+  dir: ".my-cache"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".waza.yaml"), []byte(wazaYAML), 0o644))
+	t.Chdir(dir)
+	t.Setenv(envModelsVar, "")
+	t.Setenv(envJudgeModelVar, "")
+
+	run := func(args ...string) string {
+		resetRunGlobals()
+		cmd := newRunCommand()
+		cmd.SetArgs(append([]string{specPath}, args...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		return captureStdout(t, func() {
+			require.NoError(t, cmd.Execute())
+		})
+	}
+
+	var got effectiveConfig
+	require.NoError(t, yaml.Unmarshal([]byte(run("--print-config", "--workers", "3", "--model", "cli-model", "--tags", "smoke")), &got))
+	assert.Equal(t, "test-eval", got.Spec)
+	assert.Equal(t, []string{"cli-model"}, got.Models)
+	assert.Equal(t, []string{"mock"}, got.Engines)
+	assert.True(t, got.Parallel)
+	assert.Equal(t, 3, got.Workers, "--workers should beat the .waza.yaml default")
+	assert.Equal(t, "gpt-4o-judge", got.JudgeModel)
+	assert.True(t, got.Cache.Enabled)
+	assert.Equal(t, ".my-cache", got.Cache.Dir)
+	assert.Equal(t, []string{"smoke"}, got.Filters.Tags)
+
+	got = effectiveConfig{}
+	require.NoError(t, json.Unmarshal([]byte(run("--print-config=json")), &got))
+	assert.Equal(t, 12, got.Workers)
+	assert.Equal(t, []string{"test-model"}, got.Models)
+
+	resetRunGlobals()
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--print-config=toml"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	require.ErrorContains(t, cmd.Execute(), "--print-config must be yaml or json")
+}
+
 func TestRunCommand_WazaYamlAppliesDefaults(t *testing.T) {
 	resetRunGlobals()
 
@@ -2406,7 +4743,7 @@ func testWazaRun(t *testing.T, cwd string, args []string) (evalNames []string, s
 	err = filepath.WalkDir(outputFolder, func(path string, d fs.DirEntry, err error) error {
 		require.NoError(t, err)
 
-		if filepath.Ext(d.Name()) != ".json" {
+		if filepath.Ext(d.Name()) != ".json" || d.Name() == outputDirSummaryFile {
 			return nil
 		}
 
@@ -2429,3 +4766,362 @@ func testWazaRun(t *testing.T, cwd string, args []string) (evalNames []string, s
 
 	return slices.Sorted(maps.Keys(evalNamesMap)), slices.Compact(skillsLoaded)
 }
+
+func TestCheckFixtureLock_CreatesThenDetectsDrift(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), "fixtures.lock.json")
+
+	out := captureStdout(t, func() {
+		require.NoError(t, checkFixtureLock(lockPath, models.FixtureManifest{"app.py": "v1"}, true))
+	})
+	assert.Contains(t, out, "Fixture lock written to")
+	assert.FileExists(t, lockPath)
+
+	// matching hashes pass even in strict mode
+	require.NoError(t, checkFixtureLock(lockPath, models.FixtureManifest{"app.py": "v1"}, true))
+
+	// drift warns without --strict...
+	out = captureStdout(t, func() {
+		require.NoError(t, checkFixtureLock(lockPath, models.FixtureManifest{"app.py": "v2"}, false))
+	})
+	assert.Contains(t, out, "[WARN] fixture drift: app.py changed")
+
+	// ...and fails with it
+	err := checkFixtureLock(lockPath, models.FixtureManifest{"app.py": "v2"}, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "fixture drift detected in 1 file(s): app.py")
+}
+
+func TestRunCommand_SkipInvalidTasks(t *testing.T) {
+	resetRunGlobals()
+
+	specPath := createTestSpec(t, "mock")
+	broken := filepath.Join(filepath.Dir(specPath), "tasks", "broken.yaml")
+	require.NoError(t, os.WriteFile(broken, []byte("id: broken\nname: [unterminated\n"), 0o644))
+
+	run := func(args ...string) error {
+		cmd := newRunCommand()
+		cmd.SetArgs(append([]string{specPath}, args...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		var err error
+		captureStdout(t, func() { err = cmd.Execute() })
+		return err
+	}
+
+	require.ErrorContains(t, run(), "broken.yaml", "a broken task file aborts the run by default")
+
+	resetRunGlobals()
+	outputPath := filepath.Join(t.TempDir(), "results.json")
+	require.NoError(t, run("--skip-invalid-tasks", "--output", outputPath))
+
+	outcome, err := loadOutcomeFile(outputPath)
+	require.NoError(t, err)
+	require.Len(t, outcome.TestOutcomes, 1)
+	assert.Equal(t, "test-task-001", outcome.TestOutcomes[0].TestID)
+	require.Len(t, outcome.Warnings, 1)
+	assert.Contains(t, outcome.Warnings[0], "broken.yaml")
+}
+
+func TestRunCommand_RampUp(t *testing.T) {
+	specPath := createTestSpec(t, "mock")
+
+	resetRunGlobals()
+	outPath := filepath.Join(t.TempDir(), "out.json")
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "-o", outPath, "--parallel", "--ramp-up", "50ms"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	captureStdout(t, func() {
+		require.NoError(t, cmd.Execute())
+	})
+	assert.Equal(t, 50*time.Millisecond, rampUp)
+
+	resetRunGlobals()
+	cmd = newRunCommand()
+	cmd.SetArgs([]string{specPath, "--ramp-up", "-1s"})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	require.ErrorContains(t, cmd.Execute(), "--ramp-up must not be negative")
+}
+
+func TestRunCommand_Metrics(t *testing.T) {
+	specPath := createTestSpec(t, "mock")
+	metrics := filepath.Join(t.TempDir(), "waza.prom")
+
+	resetRunGlobals()
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--metrics", metrics})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	out := captureStdout(t, func() {
+		require.NoError(t, cmd.Execute())
+	})
+	assert.Contains(t, out, "Metrics saved to: "+metrics)
+
+	data, err := os.ReadFile(metrics)
+	require.NoError(t, err)
+	text := string(data)
+	assert.Contains(t, text, "# TYPE waza_pass_rate gauge\n")
+	assert.Contains(t, text, `waza_pass_rate{skill="test-skill",eval="test-eval",model="test-model",engine="mock",environment=""} 1`+"\n")
+	assert.Contains(t, text, `status="passed"} 1`+"\n")
+}
+
+func TestRunCommand_MetricsOnFailure(t *testing.T) {
+	specPath := createFailingTestSpec(t, "mock")
+	metrics := filepath.Join(t.TempDir(), "waza.prom")
+
+	resetRunGlobals()
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--metrics", metrics})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	captureStdout(t, func() {
+		_, isTestFailure := errors.AsType[*TestFailureError](cmd.Execute())
+		require.True(t, isTestFailure)
+	})
+
+	data, err := os.ReadFile(metrics)
+	require.NoError(t, err, "a failing run still writes metrics")
+	text := string(data)
+	assert.Contains(t, text, `waza_pass_rate{skill="test-skill",eval="test-eval",model="test-model",engine="mock",environment=""} 0`+"\n")
+	assert.Contains(t, text, `status="failed"} 1`+"\n")
+	assert.Contains(t, text, `status="passed"} 0`+"\n")
+}
+
+func TestRunCommand_BadgeOnFailure(t *testing.T) {
+	specPath := createFailingTestSpec(t, "mock")
+	badge := filepath.Join(t.TempDir(), "badge.svg")
+
+	resetRunGlobals()
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--badge", badge})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	out := captureStdout(t, func() {
+		_, isTestFailure := errors.AsType[*TestFailureError](cmd.Execute())
+		require.True(t, isTestFailure)
+	})
+	assert.Contains(t, out, "Badge saved to: "+badge)
+
+	data, err := os.ReadFile(badge)
+	require.NoError(t, err, "a failing run still writes its badge")
+	assert.Contains(t, string(data), "0% passing")
+	assert.Contains(t, string(data), "#e05d44", "the badge is red")
+}
+
+func TestRunCommand_PassRateThreshold(t *testing.T) {
+	// writeSpec creates a spec whose tasks pass unless their prompt says FAIL.
+	writeSpec := func(t *testing.T, passing, failing int, gate bool) string {
+		dir := t.TempDir()
+		taskDir := filepath.Join(dir, "tasks")
+		require.NoError(t, os.MkdirAll(taskDir, 0o755))
+		for i := range passing + failing {
+			prompt := "PASS"
+			if i >= passing {
+				prompt = "FAIL"
+			}
+			task := fmt.Sprintf("id: task-%02d\nname: task-%02d\ninputs:\n  prompt: %q\n", i, i, prompt)
+			require.NoError(t, os.WriteFile(filepath.Join(taskDir, fmt.Sprintf("task-%02d.yaml", i)), []byte(task), 0o644))
+		}
+		spec := fmt.Sprintf(`name: rate-eval
+skill: test-skill
+config:
+  trials_per_task: 1
+  timeout_seconds: 30
+  executor: mock
+  model: test-model
+  pass_rate_threshold: 0.9
+  gate_pass_rate: %t
+graders:
+  - type: text
+    name: passes
+    config:
+      contains: ["PASS"]
+tasks:
+  - "tasks/*.yaml"
+`, gate)
+		specPath := filepath.Join(dir, "eval.yaml")
+		require.NoError(t, os.WriteFile(specPath, []byte(spec), 0o644))
+		return specPath
+	}
+	run := func(t *testing.T, specPath string, args ...string) (string, *models.EvaluationOutcome, error) {
+		resetRunGlobals()
+		outPath := filepath.Join(t.TempDir(), "out.json")
+		cmd := newRunCommand()
+		cmd.SetArgs(append([]string{specPath, "-o", outPath}, args...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		var err error
+		out := captureStdout(t, func() {
+			err = cmd.Execute()
+		})
+		outcome, loadErr := loadOutcomeFile(outPath)
+		require.NoError(t, loadErr)
+		return out, outcome, err
+	}
+
+	t.Run("95% is green under a 90% threshold", func(t *testing.T) {
+		out, outcome, err := run(t, writeSpec(t, 19, 1, true))
+		require.NoError(t, err, "the gate replaces per-task failures")
+		assert.Contains(t, out, "Success Rate:   95.0% (threshold 90.0%)\n")
+		assert.True(t, outcome.PassRateGreen())
+		assert.Equal(t, 0.9, outcome.Setup.PassRateThreshold)
+		assert.Contains(t, successRateLine(outcome, true), "\033[32m")
+	})
+
+	t.Run("80% fails the gate", func(t *testing.T) {
+		_, outcome, err := run(t, writeSpec(t, 4, 1, true))
+		testErr, ok := errors.AsType[*TestFailureError](err)
+		require.True(t, ok, "want a test failure, got %v", err)
+		assert.Contains(t, testErr.Message, "pass rate 80.0% below pass_rate_threshold 90.0%")
+		assert.Contains(t, successRateLine(outcome, true), "\033[31m")
+	})
+
+	t.Run("without the gate task failures still fail the run", func(t *testing.T) {
+		_, _, err := run(t, writeSpec(t, 19, 1, false))
+		require.ErrorContains(t, err, "1 failed and 0 error(s)")
+	})
+
+	t.Run("badge defaults to the threshold", func(t *testing.T) {
+		resetRunGlobals()
+		outcome := &models.EvaluationOutcome{Setup: models.OutcomeSetup{PassRateThreshold: 0.9}}
+		assert.Equal(t, 0.9, badgeThresholdFor(outcome))
+		assert.Equal(t, 0.8, badgeThresholdFor(&models.EvaluationOutcome{}), "the flag default without a threshold")
+
+		badgeThreshold, badgeThresholdSet = 0.7, true
+		assert.Equal(t, 0.7, badgeThresholdFor(outcome), "--badge-threshold wins")
+	})
+}
+
+func TestRunCommand_NormalizeOutput(t *testing.T) {
+	specPath := createTestSpec(t, "mock")
+
+	run := func(t *testing.T, name string, extra ...string) []byte {
+		t.Helper()
+		resetRunGlobals()
+		outPath := filepath.Join(t.TempDir(), name)
+		cmd := newRunCommand()
+		cmd.SetArgs(append([]string{specPath, "-o", outPath}, extra...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		captureStdout(t, func() { require.NoError(t, cmd.Execute()) })
+		data, err := os.ReadFile(outPath)
+		require.NoError(t, err)
+		return data
+	}
+
+	first := run(t, "first.json", "--normalize-output")
+	second := run(t, "second.json", "--normalize-output")
+	require.Equal(t, string(first), string(second), "normalized results should be byte-identical across runs")
+
+	var outcome models.EvaluationOutcome
+	require.NoError(t, json.Unmarshal(first, &outcome))
+	assert.Empty(t, outcome.RunID)
+	assert.True(t, outcome.Timestamp.IsZero())
+	assert.NotContains(t, string(first), `"started_at"`)
+	require.Len(t, outcome.TestOutcomes, 1)
+	assert.Equal(t, models.StatusPassed, outcome.TestOutcomes[0].Status)
+
+	t.Run("without the flag runs differ", func(t *testing.T) {
+		a := run(t, "a.json")
+		b := run(t, "b.json")
+		assert.NotEqual(t, string(a), string(b))
+	})
+}
+
+func TestRunCommand_GraderTags(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "tasks"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tasks", "task.yaml"),
+		[]byte("id: t1\nname: tagged\ninputs:\n  prompt: \"hello\"\n"), 0o644))
+	specPath := filepath.Join(dir, "eval.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(`name: grader-tags
+skill: test-skill
+config:
+  trials_per_task: 1
+  timeout_seconds: 30
+  executor: mock
+  model: test-model
+graders:
+  - name: quick-check
+    type: text
+    tags: [fast]
+    config:
+      contains: ["Mock response"]
+  - name: deep-check
+    type: text
+    tags: [thorough]
+    config:
+      contains: ["not in the output"]
+tasks:
+  - "tasks/*.yaml"
+`), 0o644))
+
+	resetRunGlobals()
+	outPath := filepath.Join(t.TempDir(), "out.json")
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--grader-tags", "fast", "-o", outPath})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	captureStdout(t, func() { require.NoError(t, cmd.Execute()) })
+
+	outcome, err := loadOutcomeFile(outPath)
+	require.NoError(t, err)
+	require.Len(t, outcome.TestOutcomes, 1)
+	validations := outcome.TestOutcomes[0].Runs[0].Validations
+	assert.Contains(t, validations, "quick-check")
+	assert.NotContains(t, validations, "deep-check")
+	assert.Equal(t, models.StatusPassed, outcome.TestOutcomes[0].Status)
+
+	// Without the filter the thorough grader runs and fails the task
+	resetRunGlobals()
+	cmd = newRunCommand()
+	cmd.SetArgs([]string{specPath})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	captureStdout(t, func() {
+		_, isTestFailure := errors.AsType[*TestFailureError](cmd.Execute())
+		assert.True(t, isTestFailure)
+	})
+}
+
+func TestRunCommand_VerifyCache(t *testing.T) {
+	specPath := createTestSpec(t, "mock")
+	cacheDir := t.TempDir()
+	run := func(args ...string) error {
+		resetRunGlobals()
+		cmd := newRunCommand()
+		cmd.SetArgs(append([]string{specPath, "--cache", "--cache-dir", cacheDir}, args...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		var err error
+		captureStdout(t, func() { err = cmd.Execute() })
+		return err
+	}
+
+	require.NoError(t, run())
+	require.NoError(t, run("--verify-cache", "--verify-cache-sample", "1"), "an up-to-date cache verifies cleanly")
+}
+
+func TestRunCommand_VerifyCacheFlagValidation(t *testing.T) {
+	specPath := createTestSpec(t, "mock")
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--verify-cache"}, "--verify-cache requires caching"},
+		{[]string{"--verify-cache", "--cache", "--no-cache"}, "--verify-cache requires caching"},
+		{[]string{"--cache", "--verify-cache-sample", "0.5"}, "--verify-cache-sample requires --verify-cache"},
+		{[]string{"--cache", "--verify-cache", "--verify-cache-sample", "0"}, "--verify-cache-sample must be greater than 0"},
+		{[]string{"--cache", "--verify-cache", "--verify-cache-sample", "1.5"}, "--verify-cache-sample must be greater than 0"},
+	} {
+		resetRunGlobals()
+		cmd := newRunCommand()
+		cmd.SetArgs(append([]string{specPath}, tc.args...))
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		err := cmd.Execute()
+		require.Error(t, err, tc.args)
+		assert.Contains(t, err.Error(), tc.want, tc.args)
+	}
+}
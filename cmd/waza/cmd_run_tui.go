@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/microsoft/waza/internal/orchestration"
+	"golang.org/x/term"
+)
+
+var tuiSpinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// tuiRecentCompleted caps how many finished tasks stay visible in the live table.
+const tuiRecentCompleted = 8
+
+type tuiTask struct {
+	num      int
+	name     string
+	status   models.Status // empty while running
+	cached   bool
+	started  time.Time
+	duration time.Duration
+}
+
+// tuiProgressModel is the state behind the --tui progress view. It is driven
+// by orchestration progress events and renders either a live table of running
+// and recently completed tasks, or a final summary once the benchmark is done.
+type tuiProgressModel struct {
+	total     int
+	tasks     []*tuiTask
+	byNum     map[int]*tuiTask
+	frame     int
+	done      bool
+	startedAt time.Time
+	elapsed   time.Duration
+	now       func() time.Time
+}
+
+type tuiEventMsg orchestration.ProgressEvent
+type tuiTickMsg struct{}
+
+func newTUIProgressModel() *tuiProgressModel {
+	return &tuiProgressModel{byNum: map[int]*tuiTask{}, now: time.Now}
+}
+
+func (m *tuiProgressModel) task(event orchestration.ProgressEvent) *tuiTask {
+	t, ok := m.byNum[event.TestNum]
+	if !ok {
+		t = &tuiTask{num: event.TestNum, name: event.TestName, started: m.now()}
+		m.byNum[event.TestNum] = t
+		m.tasks = append(m.tasks, t)
+	}
+	return t
+}
+
+// apply folds a progress event into the model.
+func (m *tuiProgressModel) apply(event orchestration.ProgressEvent) {
+	if event.TotalTests > 0 {
+		m.total = event.TotalTests
+	}
+
+	switch event.EventType {
+	case orchestration.EventBenchmarkStart:
+		m.startedAt = m.now()
+	case orchestration.EventTestStart:
+		m.task(event)
+	case orchestration.EventTestCached:
+		t := m.task(event)
+		t.status = event.Status
+		t.cached = true
+	case orchestration.EventTestComplete:
+		t := m.task(event)
+		t.status = event.Status
+		t.duration = m.now().Sub(t.started)
+		if ms, ok := event.Details["duration_ms"].(int64); ok && ms > 0 {
+			t.duration = time.Duration(ms) * time.Millisecond
+		}
+	case orchestration.EventBenchmarkComplete:
+		m.done = true
+		m.elapsed = time.Duration(event.DurationMs) * time.Millisecond
+		if m.elapsed == 0 && !m.startedAt.IsZero() {
+			m.elapsed = m.now().Sub(m.startedAt)
+		}
+	}
+}
+
+func (m *tuiProgressModel) Init() tea.Cmd {
+	return tuiTick()
+}
+
+func tuiTick() tea.Cmd {
+	return tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg { return tuiTickMsg{} })
+}
+
+func (m *tuiProgressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tuiEventMsg:
+		m.apply(orchestration.ProgressEvent(msg))
+		if m.done {
+			return m, tea.Quit
+		}
+	case tuiTickMsg:
+		m.frame++
+		return m, tuiTick()
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+func (m *tuiProgressModel) counts() (passed, failed, running int) {
+	for _, t := range m.tasks {
+		switch t.status {
+		case "":
+			running++
+		case models.StatusPassed:
+			passed++
+		default:
+			failed++
+		}
+	}
+	return passed, failed, running
+}
+
+func (m *tuiProgressModel) View() string {
+	if m.done {
+		return m.finalView()
+	}
+
+	passed, failed, running := m.counts()
+	var b strings.Builder
+	fmt.Fprintf(&b, "Tasks: %d/%d done  ✓ %d  ✗ %d  ⟳ %d running\n\n",
+		passed+failed, m.total, passed, failed, running)
+
+	var completed []*tuiTask
+	for _, t := range m.tasks {
+		if t.status == "" {
+			spin := tuiSpinnerFrames[m.frame%len(tuiSpinnerFrames)]
+			fmt.Fprintf(&b, "  %s [%d/%d] %s  %s\n", spin, t.num, m.total, t.name,
+				m.now().Sub(t.started).Truncate(time.Second))
+			continue
+		}
+		completed = append(completed, t)
+	}
+
+	if len(completed) > tuiRecentCompleted {
+		completed = completed[len(completed)-tuiRecentCompleted:]
+	}
+	for _, t := range completed {
+		fmt.Fprintf(&b, "  %s\n", m.taskLine(t))
+	}
+	return b.String()
+}
+
+func (m *tuiProgressModel) taskLine(t *tuiTask) string {
+	icon := "✓"
+	if t.status != models.StatusPassed {
+		icon = "✗"
+	}
+	suffix := t.duration.Round(time.Millisecond).String()
+	if t.cached {
+		suffix = "cached"
+	}
+	return fmt.Sprintf("%s [%d/%d] %s  %s", icon, t.num, m.total, t.name, suffix)
+}
+
+// finalView collapses the live table into a one-line tally plus any failures.
+func (m *tuiProgressModel) finalView() string {
+	passed, failed, _ := m.counts()
+	var b strings.Builder
+	fmt.Fprintf(&b, "Completed %d/%d task(s) in %v: ✓ %d passed  ✗ %d failed\n",
+		passed+failed, m.total, m.elapsed.Round(time.Millisecond), passed, failed)
+	for _, t := range m.tasks {
+		if t.status != "" && t.status != models.StatusPassed {
+			fmt.Fprintf(&b, "  %s\n", m.taskLine(t))
+		}
+	}
+	return b.String()
+}
+
+// newTUIProgressListener returns a progress listener for --tui and a stop func
+// that must be called once the benchmark returns. On a terminal it drives a
+// live-updating bubbletea view written to w; otherwise it falls back to the
+// simple listener and writes the final summary frame to w on completion.
+func newTUIProgressListener(w io.Writer, isTTY bool) (orchestration.ProgressListener, func()) {
+	model := newTUIProgressModel()
+
+	if !isTTY {
+		var mu sync.Mutex
+		return func(event orchestration.ProgressEvent) {
+			simpleProgressListener(event)
+
+			mu.Lock()
+			defer mu.Unlock()
+			model.apply(event)
+			if event.EventType == orchestration.EventBenchmarkComplete {
+				fmt.Fprint(w, model.View()) //nolint:errcheck
+			}
+		}, func() {}
+	}
+
+	program := tea.NewProgram(model, tea.WithOutput(w), tea.WithInput(nil))
+	exited := make(chan struct{})
+	var started atomic.Bool
+
+	listener := func(event orchestration.ProgressEvent) {
+		// Start lazily so the run header printed before the benchmark isn't overdrawn
+		if event.EventType == orchestration.EventBenchmarkStart && started.CompareAndSwap(false, true) {
+			go func() {
+				defer close(exited)
+				if _, err := program.Run(); err != nil {
+					fmt.Fprintf(os.Stderr, "warning: tui progress view stopped: %v\n", err)
+				}
+			}()
+		}
+		if started.Load() {
+			program.Send(tuiEventMsg(event))
+		}
+	}
+
+	stop := func() {
+		if !started.Load() {
+			return
+		}
+		program.Quit()
+		<-exited
+	}
+
+	return listener, stop
+}
+
+// stdoutIsTerminal reports whether stdout is attached to a terminal.
+func stdoutIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/microsoft/waza/internal/models"
+	"github.com/microsoft/waza/internal/orchestration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func syntheticParallelEvents() []orchestration.ProgressEvent {
+	return []orchestration.ProgressEvent{
+		{EventType: orchestration.EventBenchmarkStart, TotalTests: 3},
+		{EventType: orchestration.EventTestStart, TestName: "alpha", TestNum: 1, TotalTests: 3},
+		{EventType: orchestration.EventTestStart, TestName: "beta", TestNum: 2, TotalTests: 3},
+		{EventType: orchestration.EventTestCached, TestName: "gamma", TestNum: 3, TotalTests: 3, Status: models.StatusPassed},
+		{EventType: orchestration.EventTestComplete, TestName: "beta", TestNum: 2, TotalTests: 3, Status: models.StatusFailed,
+			Details: map[string]any{"duration_ms": int64(1500)}},
+		{EventType: orchestration.EventTestComplete, TestName: "alpha", TestNum: 1, TotalTests: 3, Status: models.StatusPassed,
+			Details: map[string]any{"duration_ms": int64(250)}},
+		{EventType: orchestration.EventBenchmarkComplete, DurationMs: 2000},
+	}
+}
+
+func TestTUIProgressListener_NonTTYFinalFrame(t *testing.T) {
+	var frame bytes.Buffer
+
+	stdout := captureStdout(t, func() {
+		listener, stop := newTUIProgressListener(&frame, false)
+		require.NotPanics(t, func() {
+			for _, e := range syntheticParallelEvents() {
+				listener(e)
+			}
+			stop()
+		})
+	})
+
+	// Falls back to the simple listener for per-task lines
+	assert.Contains(t, stdout, "✗ [2/3] beta")
+	assert.Contains(t, stdout, "✓ [1/3] alpha")
+	assert.Contains(t, stdout, "✓ [3/3] gamma [cached]")
+
+	// And renders the collapsed final frame
+	assert.Contains(t, frame.String(), "Completed 3/3 task(s) in 2s: ✓ 2 passed  ✗ 1 failed")
+	assert.Contains(t, frame.String(), "✗ [2/3] beta  1.5s")
+	assert.NotContains(t, frame.String(), "alpha")
+}
+
+func TestTUIProgressModel_LiveView(t *testing.T) {
+	m := newTUIProgressModel()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return start }
+
+	events := syntheticParallelEvents()
+	for _, e := range events[:5] {
+		m.apply(e)
+	}
+	m.now = func() time.Time { return start.Add(3 * time.Second) }
+	m.frame = 2
+
+	view := m.View()
+	assert.Contains(t, view, "Tasks: 2/3 done  ✓ 1  ✗ 1  ⟳ 1 running")
+	assert.Contains(t, view, "⠹ [1/3] alpha  3s")
+	assert.Contains(t, view, "✗ [2/3] beta  1.5s")
+	assert.Contains(t, view, "✓ [3/3] gamma  cached")
+}
+
+func TestTUIProgressModel_QuitsOnBenchmarkComplete(t *testing.T) {
+	m := newTUIProgressModel()
+	for _, e := range syntheticParallelEvents()[:6] {
+		_, cmd := m.Update(tuiEventMsg(e))
+		assert.Nil(t, cmd)
+	}
+	_, cmd := m.Update(tuiEventMsg(syntheticParallelEvents()[6]))
+	require.NotNil(t, cmd, "benchmark completion should quit the program")
+	assert.True(t, m.done)
+}
@@ -2,8 +2,11 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/microsoft/waza/internal/models"
@@ -19,6 +22,41 @@ func formatDuration(d time.Duration) string {
 	return d.String()
 }
 
+// commentTemplateFuncs are available to --comment-template templates in addition
+// to the text/template builtins.
+var commentTemplateFuncs = template.FuncMap{
+	"builtin":  FormatGitHubComment,
+	"duration": func(ms int64) string { return formatDuration(time.Duration(ms) * time.Millisecond) },
+	"percent":  func(f float64) string { return fmt.Sprintf("%.1f%%", f*100) },
+}
+
+// LoadCommentTemplate parses a Go text/template file for the github-comment format.
+// The template is executed with the *models.EvaluationOutcome as its data.
+func LoadCommentTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading comment template: %w", err)
+	}
+	tmpl, err := template.New(path).Funcs(commentTemplateFuncs).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing comment template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderGitHubComment renders outcome with tmpl, or with the built-in layout
+// from FormatGitHubComment when tmpl is nil.
+func RenderGitHubComment(outcome *models.EvaluationOutcome, tmpl *template.Template) (string, error) {
+	if tmpl == nil {
+		return FormatGitHubComment(outcome), nil
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, outcome); err != nil {
+		return "", fmt.Errorf("rendering comment template: %w", err)
+	}
+	return b.String(), nil
+}
+
 // FormatGitHubComment formats an EvaluationOutcome as a markdown comment for GitHub PRs
 func FormatGitHubComment(outcome *models.EvaluationOutcome) string {
 	var b strings.Builder
@@ -41,6 +79,10 @@ func FormatGitHubComment(outcome *models.EvaluationOutcome) string {
 	// Summary stats
 	fmt.Fprintf(&b, "- **Tests:** %d total, %d passed, %d failed, %d errors\n",
 		digest.TotalTests, digest.Succeeded, digest.Failed, digest.Errors)
+	if digest.XFailed > 0 || digest.XPassed > 0 {
+		fmt.Fprintf(&b, "- **XFail:** %d failed as expected, %d passed unexpectedly\n",
+			digest.XFailed, digest.XPassed)
+	}
 	fmt.Fprintf(&b, "- **Success Rate:** %.1f%%\n", digest.SuccessRate*100)
 	fmt.Fprintf(&b, "- **Score Range:** %.2f - %.2f (σ=%.4f)\n\n",
 		digest.MinScore, digest.MaxScore, digest.StdDev)
@@ -52,7 +94,12 @@ func FormatGitHubComment(outcome *models.EvaluationOutcome) string {
 
 	for _, to := range outcome.TestOutcomes {
 		statusIcon := "✅"
-		if to.Status != models.StatusPassed {
+		switch {
+		case to.XFailed():
+			statusIcon = "➖ xfail"
+		case to.XPassed():
+			statusIcon = "⚠️ xpass"
+		case to.Status != models.StatusPassed:
 			statusIcon = "❌"
 		}
 
@@ -113,7 +160,7 @@ func FormatGitHubComment(outcome *models.EvaluationOutcome) string {
 	if digest.Failed > 0 || digest.Errors > 0 {
 		b.WriteString("### Failed Task Details\n\n")
 		for _, to := range outcome.TestOutcomes {
-			if to.Status != models.StatusPassed {
+			if to.Status != models.StatusPassed && !to.XFailed() {
 				fmt.Fprintf(&b, "#### %s\n\n", to.DisplayName)
 
 				// Show validation failures from runs
@@ -150,8 +197,121 @@ func FormatGitHubComment(outcome *models.EvaluationOutcome) string {
 
 	// Footer with metadata
 	b.WriteString("---\n\n")
-	fmt.Fprintf(&b, "**Benchmark:** %s | **Skill:** %s | **Model:** %s\n",
-		outcome.BenchName, outcome.SkillTested, outcome.Setup.ModelID)
+	fmt.Fprintf(&b, "**Benchmark:** %s | **Skill:** %s | **Model:** %s | **Run:** `%s`\n",
+		outcome.BenchName, outcome.SkillTested, outcome.Setup.ModelID, outcome.RunID)
 
 	return b.String()
 }
+
+// FormatGitHubActions formats an EvaluationOutcome as GitHub Actions workflow
+// commands: an ::error annotation per failed or errored task, listing its
+// failing graders, and a ::warning for each run warning, flaky task and
+// unexpectedly passing xfail task. Xfail tasks that fail get no annotation.
+// taskFiles maps task IDs to their source files; tasks found there are
+// annotated on that file.
+func FormatGitHubActions(outcome *models.EvaluationOutcome, taskFiles map[string]string) string {
+	var b strings.Builder
+
+	for _, to := range outcome.TestOutcomes {
+		if to.Status != models.StatusFailed && to.Status != models.StatusError || to.XFailed() {
+			continue
+		}
+		msg := fmt.Sprintf("Task %s %s", to.DisplayName, to.Status)
+		if to.Stats != nil {
+			msg += fmt.Sprintf(" (score %.2f, pass rate %.0f%%)", to.Stats.AvgScore, to.Stats.PassRate*100)
+		}
+		if graders := failedGraderSummaries(to.Runs); len(graders) > 0 {
+			msg += "\n" + strings.Join(graders, "\n")
+		}
+		writeWorkflowCommand(&b, "error", taskFiles[to.TestID], "waza: "+to.DisplayName, msg)
+	}
+
+	for _, to := range outcome.TestOutcomes {
+		if to.Stats != nil && to.Stats.Flaky {
+			writeWorkflowCommand(&b, "warning", taskFiles[to.TestID], "waza: flaky task",
+				fmt.Sprintf("Task %s is flaky: %.0f%% pass rate across %d runs", to.DisplayName, to.Stats.PassRate*100, to.Stats.TotalRuns))
+		}
+	}
+
+	for _, to := range outcome.TestOutcomes {
+		if to.XPassed() {
+			writeWorkflowCommand(&b, "warning", taskFiles[to.TestID], "waza: unexpected pass",
+				fmt.Sprintf("Task %s is marked xfail but passed; consider removing xfail", to.DisplayName))
+		}
+	}
+
+	for _, w := range outcome.Warnings {
+		writeWorkflowCommand(&b, "warning", "", "waza", w)
+	}
+
+	return b.String()
+}
+
+// failedGraderSummaries lists each grader that failed in any run, with the
+// feedback from its first failure, sorted by grader name.
+func failedGraderSummaries(runs []models.RunResult) []string {
+	feedback := make(map[string]string)
+	for _, run := range runs {
+		if run.ErrorMsg != "" {
+			if _, ok := feedback["error"]; !ok {
+				feedback["error"] = run.ErrorMsg
+			}
+		}
+		for name, val := range run.Validations {
+			if _, ok := feedback[name]; !ok && !val.Passed {
+				feedback[name] = val.Feedback
+			}
+		}
+	}
+
+	names := make([]string, 0, len(feedback))
+	for name := range feedback {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		line := "- " + name
+		if feedback[name] != "" {
+			line += ": " + feedback[name]
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// writeWorkflowCommand writes one ::level workflow command, escaping its
+// properties and message as the Actions runner expects. file is omitted when
+// empty and otherwise made relative to the working directory, which is the
+// repository root in a workflow.
+func writeWorkflowCommand(b *strings.Builder, level, file, title, msg string) {
+	props := []string{}
+	if file != "" {
+		if abs, err := filepath.Abs(file); err == nil {
+			if wd, err := os.Getwd(); err == nil {
+				if rel, err := filepath.Rel(wd, abs); err == nil && !strings.HasPrefix(rel, "..") {
+					file = rel
+				}
+			}
+		}
+		props = append(props, "file="+escapeWorkflowProperty(filepath.ToSlash(file)))
+	}
+	if title != "" {
+		props = append(props, "title="+escapeWorkflowProperty(title))
+	}
+
+	b.WriteString("::" + level)
+	if len(props) > 0 {
+		b.WriteString(" " + strings.Join(props, ","))
+	}
+	b.WriteString("::" + escapeWorkflowData(msg) + "\n")
+}
+
+var (
+	workflowDataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	workflowPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+func escapeWorkflowData(s string) string     { return workflowDataEscaper.Replace(s) }
+func escapeWorkflowProperty(s string) string { return workflowPropertyEscaper.Replace(s) }
@@ -2,10 +2,14 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/microsoft/waza/internal/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFormatGitHubComment_PassedEval(t *testing.T) {
@@ -492,3 +496,126 @@ func TestFormatGitHubComment_MultipleRunsInFailedTask(t *testing.T) {
 	assert.NotContains(t, result, "**Run 2/3** (passed)")
 	assert.NotContains(t, result, "Run 2 passed")
 }
+
+func TestRenderGitHubComment_DefaultTemplate(t *testing.T) {
+	outcome := &models.EvaluationOutcome{
+		Digest: models.OutcomeDigest{TotalTests: 1, Succeeded: 1, SuccessRate: 1.0},
+	}
+
+	result, err := RenderGitHubComment(outcome, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, FormatGitHubComment(outcome), result)
+	assert.Contains(t, result, "## 🧪 Waza Eval Results")
+}
+
+func TestRenderGitHubComment_CustomTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "comment.tmpl")
+	body := `<!-- custom-marker -->
+### {{ .SkillTested }}
+{{ percent .Digest.SuccessRate }} in {{ duration .Digest.DurationMs }}
+{{- range .TestOutcomes }}
+- {{ .DisplayName }}: {{ .Status }}
+{{- end }}
+---
+{{ builtin . }}`
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o644))
+
+	tmpl, err := LoadCommentTemplate(path)
+	require.NoError(t, err)
+
+	outcome := &models.EvaluationOutcome{
+		SkillTested: "code-explainer",
+		Digest:      models.OutcomeDigest{TotalTests: 2, Succeeded: 1, Failed: 1, SuccessRate: 0.5, DurationMs: 1500},
+		TestOutcomes: []models.TestOutcome{
+			{DisplayName: "explain-fn", Status: models.StatusPassed},
+			{DisplayName: "explain-class", Status: models.StatusFailed},
+		},
+	}
+
+	result, err := RenderGitHubComment(outcome, tmpl)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(result, "<!-- custom-marker -->\n### code-explainer\n50.0% in 1.5s\n"))
+	assert.Contains(t, result, "- explain-fn: passed\n- explain-class: failed\n")
+	assert.Contains(t, result, "## 🧪 Waza Eval Results", "builtin renders the default layout")
+}
+
+func TestLoadCommentTemplate_Errors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte("{{ if .BenchName }}unterminated"), 0o644))
+
+	_, err := LoadCommentTemplate(path)
+	assert.ErrorContains(t, err, "parsing comment template")
+
+	_, err = LoadCommentTemplate(filepath.Join(t.TempDir(), "missing.tmpl"))
+	assert.ErrorContains(t, err, "reading comment template")
+}
+
+func TestFormatGitHubActions_FailedTasks(t *testing.T) {
+	failedRun := func(grader, feedback string) []models.RunResult {
+		return []models.RunResult{{
+			RunNumber: 1,
+			Status:    models.StatusFailed,
+			Validations: map[string]models.GraderResults{
+				grader: {Name: grader, Passed: false, Feedback: feedback},
+				"ok":   {Name: "ok", Passed: true, Feedback: "fine"},
+			},
+		}}
+	}
+	outcome := &models.EvaluationOutcome{
+		TestOutcomes: []models.TestOutcome{
+			{TestID: "tc-001", DisplayName: "passing-task", Status: models.StatusPassed},
+			{TestID: "tc-002", DisplayName: "failing-task", Status: models.StatusFailed,
+				Stats: &models.TestStats{AvgScore: 0.1}, Runs: failedRun("code", "expected True")},
+			{TestID: "tc-003", DisplayName: "erroring-task", Status: models.StatusError,
+				Runs: []models.RunResult{{RunNumber: 1, Status: models.StatusError, ErrorMsg: "timed out"}}},
+		},
+	}
+	taskFiles := map[string]string{"tc-002": filepath.Join("evals", "tasks", "failing.yaml")}
+
+	result := FormatGitHubActions(outcome, taskFiles)
+
+	var errors []string
+	for line := range strings.Lines(result) {
+		if strings.HasPrefix(line, "::error") {
+			errors = append(errors, line)
+		}
+	}
+	require.Len(t, errors, 2, result)
+	assert.True(t, strings.HasPrefix(errors[0], "::error file=evals/tasks/failing.yaml,title=waza%3A failing-task::"), errors[0])
+	assert.Contains(t, errors[0], "Task failing-task failed (score 0.10, pass rate 0%25)%0A- code: expected True")
+	assert.NotContains(t, errors[0], "- ok")
+	assert.True(t, strings.HasPrefix(errors[1], "::error title=waza%3A erroring-task::"), errors[1])
+	assert.Contains(t, errors[1], "- error: timed out")
+	assert.NotContains(t, result, "passing-task")
+}
+
+func TestFormatGitHubActions_Warnings(t *testing.T) {
+	outcome := &models.EvaluationOutcome{
+		Warnings: []string{"hook failed: 100% broken\nsee logs"},
+		TestOutcomes: []models.TestOutcome{
+			{TestID: "tc-001", DisplayName: "flaky-task", Status: models.StatusPassed,
+				Stats: &models.TestStats{Flaky: true, PassRate: 0.5, TotalRuns: 4}},
+		},
+	}
+
+	result := FormatGitHubActions(outcome, nil)
+
+	assert.NotContains(t, result, "::error")
+	assert.Contains(t, result, "::warning title=waza%3A flaky task::Task flaky-task is flaky: 50%25 pass rate across 4 runs\n")
+	assert.Contains(t, result, "::warning title=waza::hook failed: 100%25 broken%0Asee logs\n")
+}
+
+func TestFormatGitHubActions_XFail(t *testing.T) {
+	outcome := &models.EvaluationOutcome{
+		TestOutcomes: []models.TestOutcome{
+			{TestID: "tc-001", DisplayName: "known-bad", Status: models.StatusFailed, XFail: true},
+			{TestID: "tc-002", DisplayName: "fixed-now", Status: models.StatusPassed, XFail: true},
+		},
+	}
+
+	result := FormatGitHubActions(outcome, map[string]string{"tc-002": "fixed.yaml"})
+
+	assert.NotContains(t, result, "::error")
+	assert.NotContains(t, result, "known-bad")
+	assert.Contains(t, result, "::warning file=fixed.yaml,title=waza%3A unexpected pass::Task fixed-now is marked xfail but passed; consider removing xfail\n")
+}
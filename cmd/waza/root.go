@@ -44,10 +44,14 @@ performance against predefined test cases.`,
 	cmd.AddCommand(newGradeCommand())
 	cmd.AddCommand(newMetadataCommand(cmd))
 	cmd.AddCommand(newCheckCommand())
+	cmd.AddCommand(newLintCommand())
+	cmd.AddCommand(newDoctorCommand())
 	cmd.AddCommand(newSuggestCommand())
 	cmd.AddCommand(newCacheCommand())
 	cmd.AddCommand(newNewCommand())
+	cmd.AddCommand(newAddGraderCommand())
 	cmd.AddCommand(newSessionCommand())
+	cmd.AddCommand(newReplaySessionCommand())
 	cmd.AddCommand(newServeCommand())
 	cmd.AddCommand(newResultsCommand())
 
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/microsoft/waza/internal/models"
+)
+
+// skillGitInfo describes the git state of the skill under evaluation, so a
+// score change can be traced back to the skill edits behind it.
+type skillGitInfo struct {
+	// Commit is the full SHA of HEAD in the repository holding the skill.
+	Commit string `json:"commit"`
+	// Dirty reports uncommitted changes (including untracked files) in the
+	// skill directories.
+	Dirty bool `json:"dirty,omitempty"`
+	// BaseRef and ChangedFiles are set with --base-ref: the skill files that
+	// differ between BaseRef and the working tree, relative to the repo root.
+	BaseRef      string   `json:"base_ref,omitempty"`
+	ChangedFiles []string `json:"changed_files,omitempty"`
+}
+
+// collectSkillGitInfo returns the git state of skillDir, or nil when it isn't
+// inside a git repository. When baseRef is set, the skill files changed since
+// baseRef are listed; a baseRef that doesn't resolve is an error, but the
+// commit is still returned.
+func collectSkillGitInfo(skillDir, baseRef string) (*skillGitInfo, error) {
+	repo := skillDir
+	commit, err := gitOutput(repo, "rev-parse", "HEAD")
+	if err != nil {
+		// Not a repository, or one without commits yet
+		return nil, nil
+	}
+	info := &skillGitInfo{Commit: commit}
+
+	pathspec := []string{"--", skillDir}
+	status, err := gitOutput(repo, append([]string{"status", "--porcelain"}, pathspec...)...)
+	if err != nil {
+		return info, err
+	}
+	info.Dirty = status != ""
+
+	if baseRef == "" {
+		return info, nil
+	}
+	if _, err := gitOutput(repo, "rev-parse", "--verify", "--quiet", baseRef+"^{commit}"); err != nil {
+		return info, fmt.Errorf("--base-ref %q is not a commit in %s", baseRef, repo)
+	}
+	info.BaseRef = baseRef
+
+	// Committed and uncommitted changes since baseRef, plus new untracked files
+	changed, err := gitOutput(repo, append([]string{"diff", "--name-only", baseRef}, pathspec...)...)
+	if err != nil {
+		return info, err
+	}
+	untracked, err := gitOutput(repo, append([]string{"ls-files", "--others", "--exclude-standard", "--full-name"}, pathspec...)...)
+	if err != nil {
+		return info, err
+	}
+	for _, f := range strings.Split(changed+"\n"+untracked, "\n") {
+		if f != "" {
+			info.ChangedFiles = append(info.ChangedFiles, f)
+		}
+	}
+	slices.Sort(info.ChangedFiles)
+	info.ChangedFiles = slices.Compact(info.ChangedFiles)
+	return info, nil
+}
+
+// recordSkillGitInfo stores the git state of the skill the spec in specDir
+// evaluates under the outcome's "skill_git" metadata. Outside git nothing is
+// recorded; a --base-ref that can't be honored is a warning.
+func recordSkillGitInfo(outcome *models.EvaluationOutcome, specDir string) {
+	skillDir := skillDirForSpec(specDir)
+	info, err := collectSkillGitInfo(skillDir, baseRef)
+	if err != nil {
+		warnOutcome(outcome, "skill git info: %v", err)
+	}
+	if info == nil {
+		if baseRef != "" && err == nil {
+			warnOutcome(outcome, "--base-ref %q ignored: %s is not in a git repository", baseRef, skillDir)
+		}
+		return
+	}
+	if outcome.Metadata == nil {
+		outcome.Metadata = make(map[string]any)
+	}
+	outcome.Metadata["skill_git"] = info
+	if info.BaseRef != "" {
+		statusf("Skill changes since %s: %d file(s)\n", info.BaseRef, len(info.ChangedFiles))
+	}
+}
+
+// skillDirForSpec returns the nearest directory at or above specDir holding a
+// SKILL.md, since evals usually live inside the skill they test. The search
+// stops at the repository root; without a SKILL.md it returns specDir.
+func skillDirForSpec(specDir string) string {
+	for dir := specDir; ; {
+		if _, err := os.Stat(filepath.Join(dir, "SKILL.md")); err == nil {
+			return dir
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return specDir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return specDir
+		}
+		dir = parent
+	}
+}
+
+// gitOutput runs git in dir and returns its trimmed stdout.
+func gitOutput(dir string, args ...string) (string, error) {
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
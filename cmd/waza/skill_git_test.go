@@ -0,0 +1,125 @@
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runGitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, "git %s: %s", strings.Join(args, " "), out)
+	return strings.TrimSpace(string(out))
+}
+
+// initSkillRepo creates a git repo with a committed skill under skills/demo
+// and tags that commit "base".
+func initSkillRepo(t *testing.T) (repo, skillDir string) {
+	t.Helper()
+	repo = t.TempDir()
+	runGitCmd(t, repo, "init", "-q")
+	runGitCmd(t, repo, "config", "user.email", "test@example.com")
+	runGitCmd(t, repo, "config", "user.name", "Test User")
+	skillDir = filepath.Join(repo, "skills", "demo")
+	require.NoError(t, os.MkdirAll(skillDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("---\nname: demo\n---\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "README.md"), []byte("readme"), 0o644))
+	runGitCmd(t, repo, "add", "-A")
+	runGitCmd(t, repo, "commit", "-qm", "initial")
+	runGitCmd(t, repo, "tag", "base")
+	return repo, skillDir
+}
+
+func TestCollectSkillGitInfo(t *testing.T) {
+	repo, skillDir := initSkillRepo(t)
+
+	info, err := collectSkillGitInfo(skillDir, "")
+	require.NoError(t, err)
+	require.NotNil(t, info)
+	assert.Equal(t, runGitCmd(t, repo, "rev-parse", "HEAD"), info.Commit)
+	assert.False(t, info.Dirty)
+	assert.Empty(t, info.BaseRef)
+	assert.Empty(t, info.ChangedFiles)
+
+	// A committed edit, an uncommitted edit, a new file, and a change outside the skill
+	require.NoError(t, os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte("---\nname: demo\n---\nv2\n"), 0o644))
+	runGitCmd(t, repo, "commit", "-qam", "edit skill")
+	require.NoError(t, os.MkdirAll(filepath.Join(skillDir, "references"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(skillDir, "references", "guide.md"), []byte("guide"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "README.md"), []byte("changed"), 0o644))
+
+	info, err = collectSkillGitInfo(skillDir, "base")
+	require.NoError(t, err)
+	assert.Equal(t, runGitCmd(t, repo, "rev-parse", "HEAD"), info.Commit)
+	assert.True(t, info.Dirty)
+	assert.Equal(t, "base", info.BaseRef)
+	assert.Equal(t, []string{"skills/demo/SKILL.md", "skills/demo/references/guide.md"}, info.ChangedFiles)
+}
+
+func TestCollectSkillGitInfo_OutsideGit(t *testing.T) {
+	info, err := collectSkillGitInfo(t.TempDir(), "main")
+	require.NoError(t, err)
+	assert.Nil(t, info)
+}
+
+func TestCollectSkillGitInfo_UnknownBaseRef(t *testing.T) {
+	_, skillDir := initSkillRepo(t)
+
+	info, err := collectSkillGitInfo(skillDir, "no-such-ref")
+	require.ErrorContains(t, err, `--base-ref "no-such-ref" is not a commit`)
+	require.NotNil(t, info, "the commit is still recorded")
+	assert.NotEmpty(t, info.Commit)
+	assert.Empty(t, info.ChangedFiles)
+}
+
+func TestSkillDirForSpec(t *testing.T) {
+	repo, skillDir := initSkillRepo(t)
+	evalDir := filepath.Join(skillDir, "evals")
+	require.NoError(t, os.MkdirAll(evalDir, 0o755))
+	assert.Equal(t, skillDir, skillDirForSpec(evalDir), "evals inside a skill map to the skill")
+
+	other := filepath.Join(repo, "evals", "demo")
+	require.NoError(t, os.MkdirAll(other, 0o755))
+	assert.Equal(t, other, skillDirForSpec(other), "no SKILL.md up to the repo root")
+}
+
+func TestRunCommand_RecordsSkillGitInfo(t *testing.T) {
+	resetRunGlobals()
+
+	specPath := createTestSpec(t, "mock")
+	specDir := filepath.Dir(specPath)
+	runGitCmd(t, specDir, "init", "-q")
+	runGitCmd(t, specDir, "config", "user.email", "test@example.com")
+	runGitCmd(t, specDir, "config", "user.name", "Test User")
+	runGitCmd(t, specDir, "add", "-A")
+	runGitCmd(t, specDir, "commit", "-qm", "initial")
+	require.NoError(t, os.WriteFile(filepath.Join(specDir, "SKILL.md"), []byte("---\nname: test-skill\n---\n"), 0o644))
+
+	outputPath := filepath.Join(t.TempDir(), "results.json")
+	cmd := newRunCommand()
+	cmd.SetArgs([]string{specPath, "--base-ref", "HEAD", "--output", outputPath})
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	out := captureStdout(t, func() {
+		require.NoError(t, cmd.Execute())
+	})
+	assert.Contains(t, out, "Skill changes since HEAD: 1 file(s)")
+
+	outcome, err := loadOutcomeFile(outputPath)
+	require.NoError(t, err)
+	gitInfo, ok := outcome.Metadata["skill_git"].(map[string]any)
+	require.True(t, ok, "skill_git metadata: %v", outcome.Metadata)
+	assert.Equal(t, runGitCmd(t, specDir, "rev-parse", "HEAD"), gitInfo["commit"])
+	assert.Equal(t, true, gitInfo["dirty"])
+	assert.Equal(t, "HEAD", gitInfo["base_ref"])
+	assert.Equal(t, []any{"SKILL.md"}, gitInfo["changed_files"])
+}
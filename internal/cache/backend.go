@@ -0,0 +1,177 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/microsoft/waza/internal/projectconfig"
+)
+
+// Cache backend names accepted in the cache.backend field of .waza.yaml.
+const (
+	BackendFile = "file"
+	BackendHTTP = "http"
+)
+
+// ErrMiss is returned by a Backend's Get when no entry exists for the key.
+var ErrMiss = errors.New("cache miss")
+
+// Backend stores cache entries as opaque bytes under string keys. Keys are
+// hex digests, optionally prefixed ("response-", "grader-"), so they are safe
+// to use as file names and URL path segments.
+type Backend interface {
+	// Get returns the entry for key, or ErrMiss if there is none.
+	Get(key string) ([]byte, error)
+	// Put stores data under key, replacing any existing entry.
+	Put(key string, data []byte) error
+}
+
+// FileBackend stores each entry as {key}.json in a local directory. It is the
+// default backend.
+type FileBackend struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileBackend creates a FileBackend rooted at dir. The directory is created
+// on the first Put.
+func NewFileBackend(dir string) *FileBackend {
+	return &FileBackend{dir: dir}
+}
+
+// Get reads the entry for key from disk.
+func (b *FileBackend) Get(key string) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	data, err := os.ReadFile(b.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrMiss
+	}
+	return data, err
+}
+
+// Put writes the entry for key to disk, creating the directory if needed.
+func (b *FileBackend) Put(key string, data []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := os.MkdirAll(b.dir, 0755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	if err := os.WriteFile(b.path(key), data, 0644); err != nil {
+		return fmt.Errorf("writing cache file: %w", err)
+	}
+	return nil
+}
+
+func (b *FileBackend) path(key string) string {
+	return filepath.Join(b.dir, key+".json")
+}
+
+// HTTPBackend stores entries in a remote key-value store over plain HTTP:
+// GET {url}/{key}.json reads an entry (404 is a miss) and PUT writes one.
+// This fits cache servers such as bazel-remote and nginx with WebDAV, and
+// object stores with an HTTP API that accepts bearer tokens, like Google
+// Cloud Storage's XML API. Stores that need request signing, like S3, can be
+// used through such a server.
+type HTTPBackend struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// httpBackendTimeout bounds each cache request so an unreachable server
+// slows a run down rather than hanging it.
+const httpBackendTimeout = 30 * time.Second
+
+// NewHTTPBackend creates an HTTPBackend for the store at baseURL. If token is
+// set it is sent as a bearer token with every request.
+func NewHTTPBackend(baseURL, token string) *HTTPBackend {
+	return &HTTPBackend{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: httpBackendTimeout},
+	}
+}
+
+// Get fetches the entry for key.
+func (b *HTTPBackend) Get(key string) ([]byte, error) {
+	resp, err := b.do(http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrMiss
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("reading cache entry: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Put uploads the entry for key.
+func (b *HTTPBackend) Put(key string, data []byte) error {
+	resp, err := b.do(http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("writing cache entry: %s", resp.Status)
+	}
+	return nil
+}
+
+func (b *HTTPBackend) do(method, key string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, b.baseURL+"/"+url.PathEscape(key)+".json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating cache request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if b.token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cache request: %w", err)
+	}
+	return resp, nil
+}
+
+// Open creates a Cache for the backend selected in cfg. The file backend,
+// used when cfg is nil or names no backend, stores entries in dir.
+func Open(cfg *projectconfig.CacheConfig, dir string) (*Cache, error) {
+	if cfg == nil {
+		return New(dir), nil
+	}
+	switch cfg.Backend {
+	case "", BackendFile:
+		return New(dir), nil
+	case BackendHTTP:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("cache backend %q requires cache.url", BackendHTTP)
+		}
+		var token string
+		if cfg.TokenEnv != "" {
+			token = os.Getenv(cfg.TokenEnv)
+		}
+		return NewWithBackend(NewHTTPBackend(cfg.URL, token)), nil
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q (supported: %s, %s)", cfg.Backend, BackendFile, BackendHTTP)
+	}
+}
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/microsoft/waza/internal/models"
+	"github.com/microsoft/waza/internal/projectconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// kvServer is an in-memory key-value store speaking the HTTPBackend protocol.
+type kvServer struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+	auth    []string
+}
+
+func newKVServer(t *testing.T) (*kvServer, *httptest.Server) {
+	kv := &kvServer{entries: map[string][]byte{}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		kv.mu.Lock()
+		defer kv.mu.Unlock()
+		kv.auth = append(kv.auth, r.Header.Get("Authorization"))
+		switch r.Method {
+		case http.MethodGet:
+			data, ok := kv.entries[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write(data)
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			kv.entries[r.URL.Path] = data
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return kv, srv
+}
+
+func TestHTTPBackend_GetPut(t *testing.T) {
+	kv, srv := newKVServer(t)
+	c := NewWithBackend(NewHTTPBackend(srv.URL+"/waza/", "secret"))
+
+	_, found := c.Get("abc")
+	assert.False(t, found, "empty store should miss")
+
+	outcome := &models.TestOutcome{TestID: "test-1", Status: models.StatusPassed}
+	require.NoError(t, c.Put("abc", outcome))
+	assert.Contains(t, kv.entries, "/waza/abc.json")
+
+	// A second cache on the same store, as on another machine, sees the entry.
+	other := NewWithBackend(NewHTTPBackend(srv.URL+"/waza", "secret"))
+	retrieved, found := other.Get("abc")
+	require.True(t, found)
+	assert.Equal(t, "test-1", retrieved.TestID)
+	assert.Equal(t, models.StatusPassed, retrieved.Status)
+
+	for _, auth := range kv.auth {
+		assert.Equal(t, "Bearer secret", auth)
+	}
+}
+
+func TestHTTPBackend_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	b := NewHTTPBackend(srv.URL, "")
+	_, err := b.Get("abc")
+	assert.ErrorContains(t, err, "500")
+	assert.NotErrorIs(t, err, ErrMiss)
+	assert.ErrorContains(t, b.Put("abc", []byte("{}")), "500")
+
+	// Server errors read as misses so the task runs instead.
+	c := NewWithBackend(b)
+	_, found := c.Get("abc")
+	assert.False(t, found)
+}
+
+func TestFileBackend_Miss(t *testing.T) {
+	b := NewFileBackend(filepath.Join(t.TempDir(), "cache"))
+	_, err := b.Get("abc")
+	assert.ErrorIs(t, err, ErrMiss)
+
+	require.NoError(t, b.Put("abc", []byte("{}")))
+	data, err := b.Get("abc")
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(data))
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := Open(nil, dir)
+	require.NoError(t, err)
+	require.NoError(t, c.Put("k", &models.TestOutcome{TestID: "t"}))
+	_, err = os.Stat(filepath.Join(dir, "k.json"))
+	assert.NoError(t, err, "default backend writes to dir")
+
+	_, srv := newKVServer(t)
+	t.Setenv("WAZA_TEST_CACHE_TOKEN", "tok")
+	c, err = Open(&projectconfig.CacheConfig{Backend: BackendHTTP, URL: srv.URL, TokenEnv: "WAZA_TEST_CACHE_TOKEN"}, dir)
+	require.NoError(t, err)
+	require.NoError(t, c.Put("remote", &models.TestOutcome{TestID: "t"}))
+	_, err = os.Stat(filepath.Join(dir, "remote.json"))
+	assert.True(t, os.IsNotExist(err), "http backend should not write locally")
+	assert.Equal(t, "tok", c.backend.(*HTTPBackend).token)
+
+	_, err = Open(&projectconfig.CacheConfig{Backend: BackendHTTP}, dir)
+	assert.ErrorContains(t, err, "requires cache.url")
+
+	_, err = Open(&projectconfig.CacheConfig{Backend: "s3"}, dir)
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), `unknown cache backend "s3"`), err.Error())
+}
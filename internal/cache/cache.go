@@ -6,23 +6,34 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
-	"sync"
+	"strings"
 
+	"github.com/microsoft/waza/internal/execution"
 	"github.com/microsoft/waza/internal/models"
 )
 
 // Cache provides caching for evaluation results
 type Cache struct {
-	dir string
-	mu  sync.Mutex
+	// dir is the local cache directory, empty for remote backends.
+	dir     string
+	backend Backend
 }
 
 // New creates a new cache instance with the specified directory
 func New(dir string) *Cache {
-	return &Cache{dir: dir}
+	if dir == "" {
+		return &Cache{}
+	}
+	return &Cache{dir: dir, backend: NewFileBackend(dir)}
+}
+
+// NewWithBackend creates a cache that stores entries in backend.
+func NewWithBackend(backend Backend) *Cache {
+	return &Cache{backend: backend}
 }
 
 // CacheKey generates a unique cache key for a test case run
@@ -33,58 +44,111 @@ func New(dir string) *Cache {
 // - fixture file hashes
 func CacheKey(spec *models.BenchmarkSpec, task *models.TestCase, fixtureDir string) (string, error) {
 	h := sha256.New()
+	if err := writeRunInputs(h, spec, task, fixtureDir, true); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ResponseKey generates the cache key for a single trial's engine response.
+// It covers the same inputs as CacheKey except the grader configuration, so
+// editing a grader doesn't force the agent to run again.
+func ResponseKey(spec *models.BenchmarkSpec, task *models.TestCase, fixtureDir string, trial int) (string, error) {
+	h := sha256.New()
+	if err := writeString(h, "response"); err != nil {
+		return "", err
+	}
+	if err := writeInt(h, trial); err != nil {
+		return "", err
+	}
+	if err := writeRunInputs(h, spec, task, fixtureDir, false); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
 
+// writeRunInputs hashes everything that determines a task run into h.
+// Grader configuration is included only when includeGraders is set.
+func writeRunInputs(h io.Writer, spec *models.BenchmarkSpec, task *models.TestCase, fixtureDir string, includeGraders bool) error {
 	// Include spec identity
 	if err := writeString(h, spec.Name); err != nil {
-		return "", err
+		return err
 	}
 	if err := writeString(h, spec.SkillName); err != nil {
-		return "", err
+		return err
 	}
 
 	// Include config (model, engine, timeout, runs)
 	if err := writeString(h, spec.Config.ModelID); err != nil {
-		return "", err
+		return err
 	}
 	if err := writeString(h, spec.Config.EngineType); err != nil {
-		return "", err
+		return err
 	}
 	if err := writeInt(h, spec.Config.TimeoutSec); err != nil {
-		return "", err
+		return err
 	}
 	if err := writeInt(h, spec.Config.TrialsPerTask); err != nil {
-		return "", err
+		return err
 	}
 	if err := writeInt(h, spec.Config.MaxAttempts); err != nil {
-		return "", err
+		return err
+	}
+	// Only hashed when set, so existing entries stay valid
+	if spec.Config.SystemPrompt != "" {
+		if err := writeString(h, "system_prompt:"+spec.Config.SystemPrompt); err != nil {
+			return err
+		}
 	}
 
 	// Include skill paths (critical for baseline A/B: with-skills vs without-skills
 	// must produce different cache keys)
 	skillsJSON, err := json.Marshal(spec.Config.SkillPaths)
 	if err != nil {
-		return "", fmt.Errorf("marshaling skill paths: %w", err)
+		return fmt.Errorf("marshaling skill paths: %w", err)
 	}
 	if _, err := h.Write(skillsJSON); err != nil {
-		return "", err
+		return err
+	}
+	// A registry also loads each required skill, so both pick the skills in play
+	if spec.Config.SkillRegistry != "" {
+		if err := writeString(h, "skill_registry:"+spec.Config.SkillRegistry+":"+strings.Join(spec.Config.RequiredSkills, ",")); err != nil {
+			return err
+		}
 	}
 
 	// Include graders configuration
-	gradersJSON, err := json.Marshal(spec.Graders)
-	if err != nil {
-		return "", fmt.Errorf("marshaling graders: %w", err)
-	}
-	if _, err := h.Write(gradersJSON); err != nil {
-		return "", err
+	if includeGraders {
+		gradersJSON, err := json.Marshal(spec.Graders)
+		if err != nil {
+			return fmt.Errorf("marshaling graders: %w", err)
+		}
+		if _, err := h.Write(gradersJSON); err != nil {
+			return err
+		}
+		for _, g := range spec.Graders {
+			if err := hashCorpus(h, g.Parameters, fixtureDir); err != nil {
+				return err
+			}
+		}
+		for _, v := range task.Validators {
+			if err := hashCorpus(h, v.Parameters, fixtureDir); err != nil {
+				return err
+			}
+		}
 	}
 
-	// Include task definition
-	taskJSON, err := json.Marshal(task)
+	// Include task definition (without its own graders unless requested)
+	taskDef := *task
+	if !includeGraders {
+		taskDef.Validators = nil
+	}
+	taskJSON, err := json.Marshal(taskDef)
 	if err != nil {
-		return "", fmt.Errorf("marshaling task: %w", err)
+		return fmt.Errorf("marshaling task: %w", err)
 	}
 	if _, err := h.Write(taskJSON); err != nil {
-		return "", err
+		return err
 	}
 
 	// Include fixture files from resources
@@ -95,73 +159,97 @@ func CacheKey(spec *models.BenchmarkSpec, task *models.TestCase, fixtureDir stri
 		}
 	}
 	if err := hashFixtures(h, fixtureDir, fixtures); err != nil {
-		return "", fmt.Errorf("hashing fixtures: %w", err)
+		return fmt.Errorf("hashing fixtures: %w", err)
 	}
 
-	return hex.EncodeToString(h.Sum(nil)), nil
+	return nil
 }
 
 // Get retrieves a cached test outcome if it exists
 func (c *Cache) Get(key string) (*models.TestOutcome, bool) {
-	if c.dir == "" {
+	var outcome models.TestOutcome
+	if !c.get(key, &outcome) {
 		return nil, false
 	}
+	return &outcome, true
+}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
+// Put stores a test outcome in the cache
+func (c *Cache) Put(key string, outcome *models.TestOutcome) error {
+	return c.put(key, outcome)
+}
 
-	path := c.cachePath(key)
-	data, err := os.ReadFile(path)
-	if err != nil {
-		// Cache miss
+// GetResponse retrieves a cached engine response stored under a ResponseKey.
+func (c *Cache) GetResponse(key string) (*execution.ExecutionResponse, bool) {
+	var resp execution.ExecutionResponse
+	if !c.get("response-"+key, &resp) {
 		return nil, false
 	}
+	return &resp, true
+}
 
-	var outcome models.TestOutcome
-	if err := json.Unmarshal(data, &outcome); err != nil {
-		// Invalid cache entry, treat as miss
+// PutResponse stores an engine response under a ResponseKey. The workspace
+// doesn't outlive the run, so WorkspaceDir isn't stored.
+func (c *Cache) PutResponse(key string, resp *execution.ExecutionResponse) error {
+	stored := *resp
+	stored.WorkspaceDir = ""
+	return c.put("response-"+key, &stored)
+}
+
+// GetGraderResult retrieves a cached grader result stored under a GraderKey.
+func (c *Cache) GetGraderResult(key string) (*models.GraderResults, bool) {
+	var result models.GraderResults
+	if !c.get("grader-"+key, &result) {
 		return nil, false
 	}
+	return &result, true
+}
 
-	return &outcome, true
+// PutGraderResult stores a grader result under a GraderKey.
+func (c *Cache) PutGraderResult(key string, result *models.GraderResults) error {
+	return c.put("grader-"+key, result)
 }
 
-// Put stores a test outcome in the cache
-func (c *Cache) Put(key string, outcome *models.TestOutcome) error {
-	if c.dir == "" {
-		return nil
+// get reads the JSON entry stored under key into v, reporting whether it was
+// found and valid. Entry keys are cache keys, with a "response-" or "grader-"
+// prefix for those kinds of entry, so all of them can share one flat store
+// (Clear refuses subdirectories).
+func (c *Cache) get(key string, v any) bool {
+	if c.backend == nil {
+		return false
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	// Ensure cache directory exists
-	if err := os.MkdirAll(c.dir, 0755); err != nil {
-		return fmt.Errorf("creating cache directory: %w", err)
+	data, err := c.backend.Get(key)
+	if err != nil {
+		// Cache miss, or an unreachable remote backend: run the task instead
+		return false
 	}
 
-	data, err := json.MarshalIndent(outcome, "", "  ")
-	if err != nil {
-		return fmt.Errorf("marshaling outcome: %w", err)
+	// Invalid cache entry, treat as miss
+	return json.Unmarshal(data, v) == nil
+}
+
+// put stores v under key as JSON.
+func (c *Cache) put(key string, v any) error {
+	if c.backend == nil {
+		return nil
 	}
 
-	path := c.cachePath(key)
-	if err := os.WriteFile(path, data, 0644); err != nil {
-		return fmt.Errorf("writing cache file: %w", err)
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling cache entry: %w", err)
 	}
 
-	return nil
+	return c.backend.Put(key, data)
 }
 
-// Clear removes all cached results
+// Clear removes all cached results from the local cache directory. Remote
+// backends are left untouched.
 func (c *Cache) Clear() error {
 	if c.dir == "" {
 		return nil
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	// Check if directory exists
 	if _, err := os.Stat(c.dir); os.IsNotExist(err) {
 		return nil
@@ -195,22 +283,105 @@ func (c *Cache) Clear() error {
 	return os.RemoveAll(c.dir)
 }
 
-// cachePath returns the file path for a cache key
-func (c *Cache) cachePath(key string) string {
-	return filepath.Join(c.dir, key+".json")
+// GraderKey generates the cache key for one grader's result on resp for task.
+// It covers the response content, the task's ID, prompt and metadata (graders
+// such as the trigger grader read them), and the grader's identifier, kind,
+// and parameters, so a result is reused only when the agent output, the task
+// and the grader are all unchanged.
+func GraderKey(resp *execution.ExecutionResponse, task *models.TestCase, identifier string, kind models.GraderKind, params models.GraderParameters) (string, error) {
+	h := sha256.New()
+	if err := writeString(h, "grader"); err != nil {
+		return "", err
+	}
+
+	stored := *resp
+	stored.WorkspaceDir = ""
+	stored.DurationMs = 0
+	respJSON, err := json.Marshal(stored)
+	if err != nil {
+		return "", fmt.Errorf("marshaling response: %w", err)
+	}
+	if _, err := h.Write(respJSON); err != nil {
+		return "", err
+	}
+
+	if err := writeString(h, "task:"+task.TestID); err != nil {
+		return "", err
+	}
+	if err := writeString(h, "prompt:"+task.Stimulus.Message); err != nil {
+		return "", err
+	}
+	if len(task.Metadata) > 0 {
+		metadataJSON, err := json.Marshal(task.Metadata)
+		if err != nil {
+			return "", fmt.Errorf("marshaling task metadata: %w", err)
+		}
+		if err := writeString(h, "metadata:"+string(metadataJSON)); err != nil {
+			return "", err
+		}
+	}
+
+	if err := writeString(h, identifier); err != nil {
+		return "", err
+	}
+	if err := writeString(h, string(kind)); err != nil {
+		return "", err
+	}
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("marshaling grader parameters: %w", err)
+	}
+	if _, err := h.Write(paramsJSON); err != nil {
+		return "", err
+	}
+	if err := hashCorpus(h, params, ""); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// NeedsWorkspace reports whether any grader for task inspects the agent's
+// workspace (file, diff, program, prompt, and rubric graders). Cached
+// responses carry no workspace, so these runs must execute the engine.
+func NeedsWorkspace(spec *models.BenchmarkSpec, task *models.TestCase) bool {
+	kinds := make([]models.GraderKind, 0, len(spec.Graders)+len(task.Validators))
+	for _, g := range spec.Graders {
+		kinds = append(kinds, g.Kind)
+	}
+	for _, v := range task.Validators {
+		kinds = append(kinds, v.Kind)
+	}
+	for _, k := range kinds {
+		switch k {
+		case models.GraderKindFile, models.GraderKindDiff, models.GraderKindProgram, models.GraderKindPrompt, models.GraderKindRubric:
+			return true
+		}
+	}
+	return false
 }
 
 // HasNonDeterministicGraders checks if any graders are non-deterministic
-// Non-deterministic graders include: behavior and prompt
+// Non-deterministic graders include: behavior, efficiency, prompt and rubric
 func HasNonDeterministicGraders(spec *models.BenchmarkSpec) bool {
 	for _, g := range spec.Graders {
-		if g.Kind == models.GraderKindBehavior || g.Kind == models.GraderKindPrompt {
+		if IsNonDeterministic(g.Kind) {
 			return true
 		}
 	}
 	return false
 }
 
+// IsNonDeterministic reports whether graders of kind can give different
+// results for the same agent output.
+func IsNonDeterministic(kind models.GraderKind) bool {
+	switch kind {
+	case models.GraderKindBehavior, models.GraderKindEfficiency, models.GraderKindPrompt, models.GraderKindRubric:
+		return true
+	}
+	return false
+}
+
 // Helper functions
 
 func writeString(w io.Writer, s string) error {
@@ -259,6 +430,51 @@ func hashFixtures(h io.Writer, fixtureDir string, fixtures []string) error {
 	return nil
 }
 
+// hashCorpus hashes the reference files of a corpus_similarity grader, so
+// editing the corpus invalidates results graded against it. Other parameters
+// are left alone. contextDir stands in for a context_dir the grader doesn't
+// set. Like loadCorpus it skips hidden files; a missing corpus is hashed by
+// its path, as a missing fixture is.
+func hashCorpus(h io.Writer, params models.GraderParameters, contextDir string) error {
+	p, ok := params.(models.CorpusSimilarityGraderParameters)
+	if !ok {
+		return nil
+	}
+	if p.ContextDir == "" {
+		p.ContextDir = contextDir
+	}
+	dir := p.CorpusPath()
+	if err := writeString(h, "corpus:"+dir); err != nil {
+		return err
+	}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if err := writeString(h, filepath.ToSlash(rel)); err != nil {
+			return err
+		}
+		return hashFile(h, path)
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("hashing corpus_dir %s: %w", dir, err)
+	}
+	return nil
+}
+
 func hashFile(h io.Writer, path string) error {
 	f, err := os.Open(path)
 	if err != nil {
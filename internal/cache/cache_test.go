@@ -7,6 +7,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/microsoft/waza/internal/execution"
 	"github.com/microsoft/waza/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -138,6 +139,28 @@ func TestCacheKey_DifferentSkillPathsChangesKey(t *testing.T) {
 	assert.NotEqual(t, key1, key2, "with-skills and without-skills runs must have different cache keys")
 }
 
+func TestCacheKey_SkillRegistryChangesKey(t *testing.T) {
+	spec := func(required ...string) *models.BenchmarkSpec {
+		return &models.BenchmarkSpec{
+			SpecIdentity: models.SpecIdentity{Name: "test"},
+			Config: models.Config{
+				ModelID:        "gpt-4",
+				EngineType:     "copilot-sdk",
+				TimeoutSec:     300,
+				SkillRegistry:  "registry",
+				RequiredSkills: required,
+			},
+		}
+	}
+	task := &models.TestCase{TestID: "test-1", Stimulus: models.TestStimulus{Message: "Test"}}
+
+	withSkill, err := CacheKey(spec("azure-deploy"), task, "")
+	require.NoError(t, err)
+	baseline, err := CacheKey(spec(), task, "")
+	require.NoError(t, err)
+	assert.NotEqual(t, withSkill, baseline, "registry skills load only for the with-skills run")
+}
+
 func TestCacheKey_DifferentFixturesChangesKey(t *testing.T) {
 	spec := &models.BenchmarkSpec{
 		SpecIdentity: models.SpecIdentity{Name: "test"},
@@ -302,6 +325,14 @@ func TestHasNonDeterministicGraders(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			name: "has rubric grader",
+			graders: []models.GraderConfig{
+				{Kind: models.GraderKindText},
+				{Kind: models.GraderKindRubric},
+			},
+			expected: true,
+		},
 		{
 			name: "has both non-deterministic graders",
 			graders: []models.GraderConfig{
@@ -671,3 +702,167 @@ func TestCache_ConcurrentOperations(t *testing.T) {
 		wg.Wait()
 	})
 }
+
+func TestResponseKey_IgnoresGraders(t *testing.T) {
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "test-spec"},
+		Config:       models.Config{ModelID: "gpt-4", EngineType: "mock"},
+		Graders:      []models.GraderConfig{{Kind: models.GraderKindText, Identifier: "g"}},
+	}
+	task := &models.TestCase{
+		TestID:     "test-1",
+		Stimulus:   models.TestStimulus{Message: "Do something"},
+		Validators: []models.ValidatorInline{{Identifier: "v", Kind: models.GraderKindText}},
+	}
+
+	key1, err := ResponseKey(spec, task, "", 1)
+	require.NoError(t, err)
+
+	// Grader edits, spec-level or task-level, keep the key
+	spec.Graders[0].Parameters = models.TextGraderParameters{Contains: []string{"x"}}
+	task.Validators[0].Parameters = models.TextGraderParameters{Contains: []string{"y"}}
+	key2, err := ResponseKey(spec, task, "", 1)
+	require.NoError(t, err)
+	assert.Equal(t, key1, key2)
+
+	// ...while the outcome key changes
+	outcomeKey, err := CacheKey(spec, task, "")
+	require.NoError(t, err)
+	assert.NotEqual(t, key1, outcomeKey)
+
+	// Each trial and each prompt gets its own response
+	key3, err := ResponseKey(spec, task, "", 2)
+	require.NoError(t, err)
+	assert.NotEqual(t, key1, key3)
+
+	task.Stimulus.Message = "Do something else"
+	key4, err := ResponseKey(spec, task, "", 1)
+	require.NoError(t, err)
+	assert.NotEqual(t, key1, key4)
+}
+
+func TestGraderKey(t *testing.T) {
+	resp := &execution.ExecutionResponse{FinalOutput: "hello", WorkspaceDir: "/tmp/ws-1", DurationMs: 10}
+	task := &models.TestCase{TestID: "t1", Stimulus: models.TestStimulus{Message: "say hello"}}
+	params := models.TextGraderParameters{Contains: []string{"hello"}}
+
+	key1, err := GraderKey(resp, task, "g", models.GraderKindText, params)
+	require.NoError(t, err)
+
+	// Workspace path and duration don't identify the response
+	key2, err := GraderKey(&execution.ExecutionResponse{FinalOutput: "hello", WorkspaceDir: "/tmp/ws-2", DurationMs: 99}, task, "g", models.GraderKindText, params)
+	require.NoError(t, err)
+	assert.Equal(t, key1, key2)
+
+	key3, err := GraderKey(resp, task, "g", models.GraderKindText, models.TextGraderParameters{Contains: []string{"bye"}})
+	require.NoError(t, err)
+	assert.NotEqual(t, key1, key3)
+
+	key4, err := GraderKey(&execution.ExecutionResponse{FinalOutput: "different"}, task, "g", models.GraderKindText, params)
+	require.NoError(t, err)
+	assert.NotEqual(t, key1, key4)
+
+	// Graders see the task metadata, so editing it must invalidate their results
+	withMetadata := func(m map[string]any) *models.TestCase {
+		tc := *task
+		tc.Metadata = m
+		return &tc
+	}
+	key5, err := GraderKey(resp, withMetadata(map[string]any{"expected_count": 3}), "g", models.GraderKindText, params)
+	require.NoError(t, err)
+	assert.NotEqual(t, key1, key5)
+	key6, err := GraderKey(resp, withMetadata(map[string]any{"expected_count": 4}), "g", models.GraderKindText, params)
+	require.NoError(t, err)
+	assert.NotEqual(t, key5, key6)
+	key7, err := GraderKey(resp, withMetadata(map[string]any{}), "g", models.GraderKindText, params)
+	require.NoError(t, err)
+	assert.Equal(t, key1, key7, "empty metadata keys like none")
+}
+
+func TestGraderKey_DiffersByTask(t *testing.T) {
+	// Two tasks that differ only in their prompt get the same response, but a
+	// grader like the trigger grader judges it against the prompt
+	resp := &execution.ExecutionResponse{FinalOutput: "ok"}
+	params := models.TextGraderParameters{Contains: []string{"ok"}}
+
+	keyA, err := GraderKey(resp, &models.TestCase{TestID: "t", Stimulus: models.TestStimulus{Message: "use the skill"}}, "g", models.GraderKindText, params)
+	require.NoError(t, err)
+	keyB, err := GraderKey(resp, &models.TestCase{TestID: "t", Stimulus: models.TestStimulus{Message: "don't use the skill"}}, "g", models.GraderKindText, params)
+	require.NoError(t, err)
+	assert.NotEqual(t, keyA, keyB)
+
+	keyC, err := GraderKey(resp, &models.TestCase{TestID: "other", Stimulus: models.TestStimulus{Message: "use the skill"}}, "g", models.GraderKindText, params)
+	require.NoError(t, err)
+	assert.NotEqual(t, keyA, keyC)
+}
+
+func TestGraderKey_CorpusContents(t *testing.T) {
+	resp := &execution.ExecutionResponse{FinalOutput: "hello"}
+	task := &models.TestCase{TestID: "t1"}
+	corpus := t.TempDir()
+	params := models.CorpusSimilarityGraderParameters{CorpusDir: corpus}
+	require.NoError(t, os.WriteFile(filepath.Join(corpus, "a.md"), []byte("first"), 0o644))
+
+	graderKey := func() string {
+		key, err := GraderKey(resp, task, "style", models.GraderKindCorpusSimilarity, params)
+		require.NoError(t, err)
+		return key
+	}
+	spec := &models.BenchmarkSpec{Graders: []models.GraderConfig{{Kind: models.GraderKindCorpusSimilarity, Identifier: "style", Parameters: params}}}
+	outcomeKey := func() string {
+		key, err := CacheKey(spec, task, "")
+		require.NoError(t, err)
+		return key
+	}
+
+	grader1, outcome1 := graderKey(), outcomeKey()
+	assert.Equal(t, grader1, graderKey())
+
+	// Editing the corpus must invalidate results graded against it
+	require.NoError(t, os.WriteFile(filepath.Join(corpus, "a.md"), []byte("second"), 0o644))
+	assert.NotEqual(t, grader1, graderKey())
+	assert.NotEqual(t, outcome1, outcomeKey())
+
+	grader2 := graderKey()
+	require.NoError(t, os.WriteFile(filepath.Join(corpus, "b.md"), []byte("added"), 0o644))
+	assert.NotEqual(t, grader2, graderKey())
+
+	// Hidden files aren't references
+	grader3 := graderKey()
+	require.NoError(t, os.WriteFile(filepath.Join(corpus, ".notes"), []byte("ignored"), 0o644))
+	assert.Equal(t, grader3, graderKey())
+}
+
+func TestNeedsWorkspace(t *testing.T) {
+	spec := &models.BenchmarkSpec{Graders: []models.GraderConfig{{Kind: models.GraderKindText}}}
+	assert.False(t, NeedsWorkspace(spec, &models.TestCase{}))
+
+	// The rubric judge runs in the workspace, which a cached response doesn't have
+	task := &models.TestCase{Validators: []models.ValidatorInline{{Kind: models.GraderKindRubric}}}
+	assert.True(t, NeedsWorkspace(spec, task))
+
+	spec.Graders = append(spec.Graders, models.GraderConfig{Kind: models.GraderKindFile})
+	assert.True(t, NeedsWorkspace(spec, &models.TestCase{}))
+}
+
+func TestCache_ResponseAndGraderEntries(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir)
+
+	_, found := c.GetResponse("k")
+	assert.False(t, found)
+
+	require.NoError(t, c.PutResponse("k", &execution.ExecutionResponse{FinalOutput: "out", WorkspaceDir: "/tmp/ws"}))
+	resp, found := c.GetResponse("k")
+	require.True(t, found)
+	assert.Equal(t, "out", resp.FinalOutput)
+	assert.Empty(t, resp.WorkspaceDir)
+
+	require.NoError(t, c.PutGraderResult("k", &models.GraderResults{Name: "g", Score: 0.5}))
+	result, found := c.GetGraderResult("k")
+	require.True(t, found)
+	assert.Equal(t, 0.5, result.Score)
+
+	// Entries are flat files, so Clear still accepts the directory
+	require.NoError(t, c.Clear())
+}
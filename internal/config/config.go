@@ -6,13 +6,14 @@ import (
 
 // BenchmarkConfig is the main configuration with functional options
 type BenchmarkConfig struct {
-	spec          *models.BenchmarkSpec
-	specDir       string // Directory containing the spec file (for resolving test patterns)
-	fixtureDir    string // Directory containing fixtures/context files
-	verbose       bool
-	outputPath    string
-	logPath       string
-	transcriptDir string // Directory for per-task transcript JSON files
+	spec           *models.BenchmarkSpec
+	specDir        string // Directory containing the spec file (for resolving test patterns)
+	fixtureDir     string // Directory containing fixtures/context files
+	verbose        bool
+	outputPath     string
+	logPath        string
+	transcriptDir  string // Directory for per-task transcript JSON files
+	transcriptName string // Template for transcript filenames (empty uses the default)
 }
 
 // Option is a functional option for BenchmarkConfig
@@ -79,12 +80,20 @@ func WithTranscriptDir(path string) Option {
 	}
 }
 
+// WithTranscriptNameTemplate sets the template used to name transcript files
+func WithTranscriptNameTemplate(tmpl string) Option {
+	return func(c *BenchmarkConfig) {
+		c.transcriptName = tmpl
+	}
+}
+
 // Getters
-func (c *BenchmarkConfig) Spec() *models.BenchmarkSpec { return c.spec }
-func (c *BenchmarkConfig) SpecDir() string             { return c.specDir }
-func (c *BenchmarkConfig) FixtureDir() string          { return c.fixtureDir }
-func (c *BenchmarkConfig) ContextRoot() string         { return c.fixtureDir } // Alias for compatibility
-func (c *BenchmarkConfig) Verbose() bool               { return c.verbose }
-func (c *BenchmarkConfig) OutputPath() string          { return c.outputPath }
-func (c *BenchmarkConfig) LogPath() string             { return c.logPath }
-func (c *BenchmarkConfig) TranscriptDir() string       { return c.transcriptDir }
+func (c *BenchmarkConfig) Spec() *models.BenchmarkSpec    { return c.spec }
+func (c *BenchmarkConfig) SpecDir() string                { return c.specDir }
+func (c *BenchmarkConfig) FixtureDir() string             { return c.fixtureDir }
+func (c *BenchmarkConfig) ContextRoot() string            { return c.fixtureDir } // Alias for compatibility
+func (c *BenchmarkConfig) Verbose() bool                  { return c.verbose }
+func (c *BenchmarkConfig) OutputPath() string             { return c.outputPath }
+func (c *BenchmarkConfig) LogPath() string                { return c.logPath }
+func (c *BenchmarkConfig) TranscriptDir() string          { return c.transcriptDir }
+func (c *BenchmarkConfig) TranscriptNameTemplate() string { return c.transcriptName }
@@ -2,7 +2,10 @@ package dataset
 
 import (
 	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"iter"
 	"os"
 )
 
@@ -12,37 +15,7 @@ type Row map[string]string
 // LoadCSV reads a CSV file and returns rows as maps of column to value.
 // The first row is treated as headers (column names).
 func LoadCSV(path string) ([]Row, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("csv: open %s: %w", path, err)
-	}
-	defer f.Close() //nolint:errcheck
-
-	reader := csv.NewReader(f)
-	records, err := reader.ReadAll()
-	if err != nil {
-		return nil, fmt.Errorf("csv: parse %s: %w", path, err)
-	}
-
-	if len(records) == 0 {
-		return nil, fmt.Errorf("csv: %s is empty (no header row)", path)
-	}
-
-	headers := records[0]
-	rows := make([]Row, 0, len(records)-1)
-
-	for i, record := range records[1:] {
-		if len(record) != len(headers) {
-			return nil, fmt.Errorf("csv: row %d has %d columns, expected %d", i+2, len(record), len(headers))
-		}
-		row := make(Row, len(headers))
-		for j, h := range headers {
-			row[h] = record[j]
-		}
-		rows = append(rows, row)
-	}
-
-	return rows, nil
+	return collect(StreamCSV(path))
 }
 
 // LoadCSVRange reads rows in the given range [start, end] (1-based, inclusive).
@@ -54,21 +27,74 @@ func LoadCSVRange(path string, start, end int) ([]Row, error) {
 	if end < start {
 		return nil, fmt.Errorf("csv: range end (%d) must be >= start (%d)", end, start)
 	}
+	return collect(StreamCSVRange(path, start, end))
+}
 
-	allRows, err := LoadCSV(path)
-	if err != nil {
-		return nil, err
-	}
+// StreamCSV returns an iterator over the data rows of a CSV file, reading one
+// record at a time so the whole file is never held in memory. The first row is
+// treated as headers. Iteration stops after the first error, which is yielded
+// with a nil Row.
+func StreamCSV(path string) iter.Seq2[Row, error] {
+	return StreamCSVRange(path, 1, 0)
+}
 
-	// Clamp end to available rows
-	if end > len(allRows) {
-		end = len(allRows)
-	}
+// StreamCSVRange is like [StreamCSV] but only yields rows in [start, end]
+// (1-based, inclusive), and stops reading the file once end is reached.
+// An end of 0 means no upper bound.
+func StreamCSVRange(path string, start, end int) iter.Seq2[Row, error] {
+	return func(yield func(Row, error) bool) {
+		f, err := os.Open(path)
+		if err != nil {
+			yield(nil, fmt.Errorf("csv: open %s: %w", path, err))
+			return
+		}
+		defer f.Close() //nolint:errcheck
+
+		reader := csv.NewReader(f)
+		headers, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			yield(nil, fmt.Errorf("csv: %s is empty (no header row)", path))
+			return
+		}
+		if err != nil {
+			yield(nil, fmt.Errorf("csv: parse %s: %w", path, err))
+			return
+		}
 
-	// If start is beyond available rows, return empty
-	if start > len(allRows) {
-		return []Row{}, nil
+		for rowNum := 1; end == 0 || rowNum <= end; rowNum++ {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(nil, fmt.Errorf("csv: parse %s: %w", path, err))
+				return
+			}
+			if rowNum < start {
+				continue
+			}
+			if len(record) != len(headers) {
+				yield(nil, fmt.Errorf("csv: row %d has %d columns, expected %d", rowNum+1, len(record), len(headers)))
+				return
+			}
+			row := make(Row, len(headers))
+			for j, h := range headers {
+				row[h] = record[j]
+			}
+			if !yield(row, nil) {
+				return
+			}
+		}
 	}
+}
 
-	return allRows[start-1 : end], nil
+func collect(seq iter.Seq2[Row, error]) ([]Row, error) {
+	rows := []Row{}
+	for row, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
 }
@@ -177,3 +177,64 @@ func TestLoadCSVRange_Values(t *testing.T) {
 	assert.Equal(t, "c", rows[1]["name"])
 	assert.Equal(t, "p3", rows[1]["prompt"])
 }
+
+func TestStreamCSV_MatchesLoadCSV(t *testing.T) {
+	dir := t.TempDir()
+	p := writeCSV(t, dir, "data.csv", "id,prompt\nA,one\nB,two\nC,three\nD,four\n")
+
+	want, err := LoadCSV(p)
+	require.NoError(t, err)
+
+	var got []Row
+	for row, err := range StreamCSV(p) {
+		require.NoError(t, err)
+		got = append(got, row)
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestStreamCSV_StopsEarly(t *testing.T) {
+	dir := t.TempDir()
+	// The malformed row after B is never read when iteration stops at B.
+	p := writeCSV(t, dir, "data.csv", "id,prompt\nA,one\nB,two\n\"unterminated\n")
+
+	var ids []string
+	for row, err := range StreamCSV(p) {
+		require.NoError(t, err)
+		ids = append(ids, row["id"])
+		if row["id"] == "B" {
+			break
+		}
+	}
+	assert.Equal(t, []string{"A", "B"}, ids)
+}
+
+func TestStreamCSVRange_StopsReadingAfterEnd(t *testing.T) {
+	dir := t.TempDir()
+	p := writeCSV(t, dir, "data.csv", "id,prompt\nA,one\nB,two\nC,three\n\"unterminated\n")
+
+	rows, err := collect(StreamCSVRange(p, 2, 3))
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "B", rows[0]["id"])
+	assert.Equal(t, "C", rows[1]["id"])
+}
+
+func TestStreamCSV_Error(t *testing.T) {
+	dir := t.TempDir()
+	p := writeCSV(t, dir, "data.csv", "id,prompt\nA,one\nB\n")
+
+	var rows int
+	var gotErr error
+	for row, err := range StreamCSV(p) {
+		if err != nil {
+			gotErr = err
+			assert.Nil(t, row)
+			continue
+		}
+		rows++
+	}
+	assert.Equal(t, 1, rows)
+	require.Error(t, gotErr)
+	assert.Contains(t, gotErr.Error(), "wrong number of fields")
+}
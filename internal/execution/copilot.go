@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"runtime/debug"
 	"strings"
 	"sync"
 	"time"
@@ -123,6 +124,44 @@ func (e *CopilotEngine) Initialize(ctx context.Context) error {
 	return nil
 }
 
+// copilotSDKModule is the module whose version EngineVersion reports.
+const copilotSDKModule = "github.com/github/copilot-sdk/go"
+
+// EngineVersion implements [VersionReporter], reporting the Copilot SDK
+// version waza was built with.
+func (e *CopilotEngine) EngineVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == copilotSDKModule {
+			if dep.Replace != nil {
+				dep = dep.Replace
+			}
+			return "copilot-sdk/go " + dep.Version
+		}
+	}
+	return ""
+}
+
+// ListModels implements [ModelLister].
+func (e *CopilotEngine) ListModels(ctx context.Context) ([]string, error) {
+	infos, err := e.client.ListModels(ctx)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list copilot models: %w", err)
+	}
+
+	ids := make([]string, 0, len(infos))
+
+	for _, info := range infos {
+		ids = append(ids, info.ID)
+	}
+
+	return ids, nil
+}
+
 // Execute runs a test with Copilot SDK
 func (e *CopilotEngine) Execute(ctx context.Context, req *ExecutionRequest) (*ExecutionResponse, error) {
 	if req == nil {
@@ -160,10 +199,17 @@ func (e *CopilotEngine) Execute(ctx context.Context, req *ExecutionRequest) (*Ex
 		permRequestCallback = req.PermissionHandler
 	}
 
+	// The system prompt is appended so the SDK's own instructions and guardrails stay in place
+	var systemMessage *copilot.SystemMessageConfig
+	if req.SystemPrompt != "" {
+		systemMessage = &copilot.SystemMessageConfig{Mode: "append", Content: req.SystemPrompt}
+	}
+
 	if req.SessionID == "" {
 		// Create session with updated API
 		session, err = e.client.CreateSession(ctx, &copilot.SessionConfig{
-			Model: modelID,
+			Model:         modelID,
+			SystemMessage: systemMessage,
 
 			OnPermissionRequest: permRequestCallback,
 
@@ -176,7 +222,8 @@ func (e *CopilotEngine) Execute(ctx context.Context, req *ExecutionRequest) (*Ex
 		}
 	} else {
 		session, err = e.client.ResumeSessionWithOptions(ctx, req.SessionID, &copilot.ResumeSessionConfig{
-			Model: modelID,
+			Model:         modelID,
+			SystemMessage: systemMessage,
 
 			OnPermissionRequest: permRequestCallback,
 
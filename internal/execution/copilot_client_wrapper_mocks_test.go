@@ -166,6 +166,21 @@ func (mr *MockCopilotClientMockRecorder) GetAuthStatus(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthStatus", reflect.TypeOf((*MockCopilotClient)(nil).GetAuthStatus), ctx)
 }
 
+// ListModels mocks base method.
+func (m *MockCopilotClient) ListModels(ctx context.Context) ([]copilot.ModelInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListModels", ctx)
+	ret0, _ := ret[0].([]copilot.ModelInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListModels indicates an expected call of ListModels.
+func (mr *MockCopilotClientMockRecorder) ListModels(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListModels", reflect.TypeOf((*MockCopilotClient)(nil).ListModels), ctx)
+}
+
 // ResumeSessionWithOptions mocks base method.
 func (m *MockCopilotClient) ResumeSessionWithOptions(ctx context.Context, sessionID string, config *copilot.ResumeSessionConfig) (CopilotSession, error) {
 	m.ctrl.T.Helper()
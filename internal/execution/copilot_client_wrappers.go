@@ -41,6 +41,9 @@ type CopilotClient interface {
 
 	// DeleteSession maps to [copilot.Client.DeleteSession]
 	DeleteSession(ctx context.Context, sessionID string) error
+
+	// ListModels maps to [copilot.Client.ListModels]
+	ListModels(ctx context.Context) ([]copilot.ModelInfo, error)
 }
 
 func newCopilotClient(clientOptions *copilot.ClientOptions) CopilotClient {
@@ -89,6 +92,10 @@ func (w *copilotClientWrapper) DeleteSession(ctx context.Context, sessionID stri
 	return w.inner.DeleteSession(ctx, sessionID)
 }
 
+func (w *copilotClientWrapper) ListModels(ctx context.Context) ([]copilot.ModelInfo, error) {
+	return w.inner.ListModels(ctx)
+}
+
 // copilotSessionWrapper is a light wrapper that forwards all calls to [copilot.Session]
 // and only has to exist because [copilot.Session.SessionID] is a field, so we can't represent
 // it in an interface...
@@ -154,3 +154,29 @@ func TestCopilotEngine_Shutdown_StopsClientAndCleansWorkspaces(t *testing.T) {
 	_, err = os.Stat(workspaceDir)
 	assert.True(t, os.IsNotExist(err))
 }
+
+func TestCopilotEngine_ListModels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	clientMock := NewMockCopilotClient(ctrl)
+
+	engine := NewCopilotEngineBuilder("test-model", &CopilotEngineBuilderOptions{
+		NewCopilotClient: func(clientOptions *copilot.ClientOptions) CopilotClient { return clientMock },
+	}).Build()
+
+	var _ ModelLister = engine
+
+	clientMock.EXPECT().ListModels(gomock.Any()).Return([]copilot.ModelInfo{{ID: "gpt-4o", Name: "GPT-4o"}, {ID: "claude-sonnet-4.5"}}, nil)
+	ids, err := engine.ListModels(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"gpt-4o", "claude-sonnet-4.5"}, ids)
+
+	clientMock.EXPECT().ListModels(gomock.Any()).Return(nil, errors.New("boom"))
+	_, err = engine.ListModels(context.Background())
+	require.ErrorContains(t, err, "boom")
+}
+
+func TestCopilotEngine_EngineVersion(t *testing.T) {
+	var e *CopilotEngine
+	require.Implements(t, (*VersionReporter)(nil), e)
+	assert.Regexp(t, `^copilot-sdk/go v\d+\.\d+\.\d+`, (&CopilotEngine{}).EngineVersion())
+}
@@ -340,6 +340,55 @@ func TestCopilotNotAuthenticated(t *testing.T) {
 	})
 }
 
+func TestCopilotSystemPrompt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	clientMock := newClientMock(ctrl)
+	sessionMock := NewMockCopilotSession(ctrl)
+
+	sourceDir := t.TempDir()
+
+	expectedConfig := sessionConfigMatcher{
+		t:         t,
+		sourceDir: sourceDir,
+		expected: copilot.SessionConfig{
+			OnPermissionRequest: allowAllTools,
+			Model:               "gpt-4o-mini",
+			SystemMessage:       &copilot.SystemMessageConfig{Mode: "append", Content: "Answer in French."},
+			SkillDirectories:    []string{sourceDir},
+		},
+	}
+
+	clientMock.EXPECT().CreateSession(gomock.Any(), expectedConfig).Return(sessionMock, nil)
+	sessionMock.EXPECT().Disconnect()
+	clientMock.EXPECT().DeleteSession(gomock.Any(), "session-1")
+
+	sessionMock.EXPECT().On(gomock.Any()).Times(3).Return(func() {})
+	sessionMock.EXPECT().SendAndWait(gomock.Any(), gomock.Any()).Return(&copilot.SessionEvent{}, nil)
+	sessionMock.EXPECT().SessionID().Return("session-1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	engine := NewCopilotEngineBuilder("gpt-4o-mini", &CopilotEngineBuilderOptions{
+		NewCopilotClient: func(clientOptions *copilot.ClientOptions) CopilotClient { return clientMock },
+	}).Build()
+
+	defer func() {
+		err := engine.Shutdown(context.Background())
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, engine.Initialize(ctx))
+
+	_, err := engine.Execute(ctx, &ExecutionRequest{
+		Message:      "hello?",
+		SystemPrompt: "Answer in French.",
+		Timeout:      time.Minute,
+		SourceDir:    sourceDir,
+	})
+	require.NoError(t, err)
+}
+
 type sessionConfigMatcher struct {
 	expected  any
 	sourceDir string
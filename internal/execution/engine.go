@@ -28,6 +28,20 @@ type AgentEngine interface {
 	SessionUsage(sessionID string) *models.UsageStats
 }
 
+// ModelLister is implemented by engines that can enumerate the models they accept.
+type ModelLister interface {
+	// ListModels returns the IDs of the models available to the engine. The
+	// engine must be initialized first.
+	ListModels(ctx context.Context) ([]string, error)
+}
+
+// VersionReporter is implemented by engines that can report the version of
+// the agent runtime they drive, for an outcome's provenance.
+type VersionReporter interface {
+	// EngineVersion returns the runtime version, or "" when it's unknown.
+	EngineVersion() string
+}
+
 // ExecutionRequest represents a test execution request
 type ExecutionRequest struct {
 	ModelID   string
@@ -43,6 +57,15 @@ type ExecutionRequest struct {
 
 	Timeout time.Duration
 
+	// SystemPrompt, when set, is appended to the engine's system prompt.
+	SystemPrompt string
+
+	// AllowedTools and DeniedTools are the tool policy for this request (glob
+	// patterns). The runner reports calls outside it after execution; engines
+	// don't block them, so the run shows what the agent actually tried.
+	AllowedTools []string
+	DeniedTools  []string
+
 	// PermissionHandler called when the copilot SDK wants to determine if a tool can be used.
 	// Default: allows all tools.
 	PermissionHandler copilot.PermissionHandlerFunc
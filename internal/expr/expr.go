@@ -0,0 +1,434 @@
+// Package expr evaluates small boolean expressions over a map of values, such
+// as a task's success_when condition over its grader results.
+//
+// The grammar is deliberately tiny and has no function calls or side effects:
+//
+//	expr       = or
+//	or         = and { "||" and }
+//	and        = not { "&&" not }
+//	not        = "!" not | comparison
+//	comparison = operand [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" ) operand ]
+//	operand    = number | string | "true" | "false" | path | "(" expr ")"
+//	path       = name { "." name }
+//
+// Names may contain letters, digits, "_" and "-" (grader names often use
+// dashes). Strings are single- or double-quoted. && and || short-circuit, so
+// a path that only appears in an unevaluated branch need not exist.
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expr is a parsed expression.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Parse parses src into an Expr.
+func Parse(src string) (*Expr, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, fmt.Errorf("expression %q: %w", src, err)
+	}
+	p := &parser{toks: toks}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("expression %q: %w", src, err)
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("expression %q: unexpected %s at offset %d", src, tok, tok.pos)
+	}
+	return &Expr{src: src, root: root}, nil
+}
+
+// String returns the source the expression was parsed from.
+func (e *Expr) String() string { return e.src }
+
+// EvalBool evaluates the expression against vars and requires a boolean
+// result. Nested maps in vars are reached with dotted paths, e.g. code.passed.
+// Numbers may be any Go integer or float type; they're compared as float64.
+func (e *Expr) EvalBool(vars map[string]any) (bool, error) {
+	v, err := e.root.eval(vars)
+	if err != nil {
+		return false, fmt.Errorf("expression %q: %w", e.src, err)
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression %q: result is %s, not a boolean", e.src, describe(v))
+	}
+	return b, nil
+}
+
+// tokens
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokNumber
+	tokString
+	tokName
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+func isNameStart(r rune) bool { return r == '_' || unicode.IsLetter(r) }
+func isNameChar(r rune) bool {
+	return r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func lex(src string) ([]token, error) {
+	var toks []token
+	rs := []rune(src)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			toks = append(toks, token{tokLParen, "(", i})
+			i++
+		case r == ')':
+			toks = append(toks, token{tokRParen, ")", i})
+			i++
+		case r == '"' || r == '\'':
+			start := i
+			i++
+			var sb strings.Builder
+			for i < len(rs) && rs[i] != r {
+				if rs[i] == '\\' && i+1 < len(rs) {
+					i++
+				}
+				sb.WriteRune(rs[i])
+				i++
+			}
+			if i >= len(rs) {
+				return nil, fmt.Errorf("unterminated string at offset %d", start)
+			}
+			i++
+			toks = append(toks, token{tokString, sb.String(), start})
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(rs) && (unicode.IsDigit(rs[i+1]) || rs[i+1] == '.')) || r == '.':
+			start := i
+			i++
+			for i < len(rs) && (unicode.IsDigit(rs[i]) || rs[i] == '.') {
+				i++
+			}
+			toks = append(toks, token{tokNumber, string(rs[start:i]), start})
+		case isNameStart(r):
+			start := i
+			for i < len(rs) && (isNameChar(rs[i]) || (rs[i] == '.' && i+1 < len(rs) && isNameStart(rs[i+1]))) {
+				i++
+			}
+			toks = append(toks, token{tokName, string(rs[start:i]), start})
+		default:
+			start := i
+			two := ""
+			if i+1 < len(rs) {
+				two = string(rs[i : i+2])
+			}
+			switch two {
+			case "&&", "||", "==", "!=", "<=", ">=":
+				toks = append(toks, token{tokOp, two, start})
+				i += 2
+				continue
+			}
+			switch r {
+			case '!', '<', '>':
+				toks = append(toks, token{tokOp, string(r), start})
+				i++
+			default:
+				return nil, fmt.Errorf("unexpected character %q at offset %d", r, start)
+			}
+		}
+	}
+	return append(toks, token{tokEOF, "", len(rs)}), nil
+}
+
+// parser
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) acceptOp(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.acceptOp("||"); !ok {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{op: "||", left: left, right: right}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.acceptOp("&&"); !ok {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{op: "&&", left: left, right: right}
+	}
+}
+
+func (p *parser) parseNot() (node, error) {
+	if _, ok := p.acceptOp("!"); ok {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.acceptOp("==", "!=", "<", "<=", ">", ">=")
+	if !ok {
+		return left, nil
+	}
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return compareNode{op: op, left: left, right: right}, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at offset %d", t.text, t.pos)
+		}
+		return literalNode{value: f}, nil
+	case tokString:
+		return literalNode{value: t.text}, nil
+	case tokName:
+		switch t.text {
+		case "true":
+			return literalNode{value: true}, nil
+		case "false":
+			return literalNode{value: false}, nil
+		}
+		return pathNode{path: strings.Split(t.text, ".")}, nil
+	case tokLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, fmt.Errorf("expected \")\" at offset %d, got %s", closing.pos, closing)
+		}
+		return inner, nil
+	default:
+		return nil, fmt.Errorf("expected a value at offset %d, got %s", t.pos, t)
+	}
+}
+
+// evaluation
+
+type node interface {
+	eval(vars map[string]any) (any, error)
+}
+
+type literalNode struct{ value any }
+
+func (n literalNode) eval(map[string]any) (any, error) { return n.value, nil }
+
+type pathNode struct{ path []string }
+
+func (n pathNode) eval(vars map[string]any) (any, error) {
+	var cur any = vars
+	for i, key := range n.path {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s is %s, not an object", strings.Join(n.path[:i], "."), describe(cur))
+		}
+		if cur, ok = m[key]; !ok {
+			return nil, fmt.Errorf("unknown name %q", strings.Join(n.path[:i+1], "."))
+		}
+	}
+	return normalize(cur), nil
+}
+
+type notNode struct{ operand node }
+
+func (n notNode) eval(vars map[string]any) (any, error) {
+	b, err := evalBool(n.operand, vars, "!")
+	if err != nil {
+		return nil, err
+	}
+	return !b, nil
+}
+
+type logicalNode struct {
+	op          string
+	left, right node
+}
+
+func (n logicalNode) eval(vars map[string]any) (any, error) {
+	l, err := evalBool(n.left, vars, n.op)
+	if err != nil {
+		return nil, err
+	}
+	if (n.op == "&&" && !l) || (n.op == "||" && l) {
+		return l, nil
+	}
+	return evalBool(n.right, vars, n.op)
+}
+
+type compareNode struct {
+	op          string
+	left, right node
+}
+
+func (n compareNode) eval(vars map[string]any) (any, error) {
+	l, err := n.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	r, err := n.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	switch lv := l.(type) {
+	case float64:
+		if rv, ok := r.(float64); ok {
+			return compareOrdered(n.op, lv, rv), nil
+		}
+	case string:
+		if rv, ok := r.(string); ok {
+			return compareOrdered(n.op, lv, rv), nil
+		}
+	case bool:
+		if rv, ok := r.(bool); ok && (n.op == "==" || n.op == "!=") {
+			return (lv == rv) == (n.op == "=="), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot compare %s %s %s", describe(l), n.op, describe(r))
+}
+
+func compareOrdered[T float64 | string](op string, l, r T) bool {
+	switch op {
+	case "==":
+		return l == r
+	case "!=":
+		return l != r
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	default:
+		return l >= r
+	}
+}
+
+func evalBool(n node, vars map[string]any, op string) (bool, error) {
+	v, err := n.eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s needs a boolean, got %s", op, describe(v))
+	}
+	return b, nil
+}
+
+// normalize converts Go numeric types to float64 so callers can pass ints.
+func normalize(v any) any {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int32:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float32:
+		return float64(n)
+	}
+	return v
+}
+
+func describe(v any) string {
+	switch v := v.(type) {
+	case bool:
+		return fmt.Sprintf("boolean %v", v)
+	case float64:
+		return fmt.Sprintf("number %v", v)
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case map[string]any:
+		return "an object"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
@@ -0,0 +1,99 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvalBool(t *testing.T) {
+	vars := map[string]any{
+		"code":        map[string]any{"passed": true, "score": 1.0},
+		"prompt":      map[string]any{"passed": false, "score": 0.85},
+		"check-regex": map[string]any{"passed": false, "score": 0.0},
+		"tool_calls":  7,
+		"label":       "smoke",
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"code.passed", true},
+		{"code.passed && prompt.score >= 0.8", true},
+		{"code.passed && prompt.passed", false},
+		{"prompt.passed || code.passed", true},
+		{"!prompt.passed", true},
+		{"!(code.passed && prompt.passed)", true},
+		{"check-regex.passed || tool_calls < 10", true},
+		{"tool_calls <= 5", false},
+		{"tool_calls == 7 && tool_calls != 8", true},
+		{"prompt.score > .9", false},
+		{"prompt.score > -1", true},
+		{"label == 'smoke'", true},
+		{`label != "smoke"`, false},
+		{"code.passed == true", true},
+		{"true && !false", true},
+		{"code.passed || prompt.passed && check-regex.passed", true}, // && binds tighter
+		{"(code.passed || prompt.passed) && check-regex.passed", false},
+		// Short-circuiting skips names that don't exist
+		{"code.passed || missing.passed", true},
+		{"prompt.passed && missing.passed", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Parse(tt.expr)
+			require.NoError(t, err)
+			got, err := e.EvalBool(vars)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"code.passed &&",
+		"(code.passed",
+		"code.passed)",
+		"code.passed & prompt.passed",
+		"label == 'smoke",
+		"score >= 1.2.3",
+		"a < b < c",
+		"len(x) > 1",
+	} {
+		t.Run(src, func(t *testing.T) {
+			_, err := Parse(src)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEvalBool_Errors(t *testing.T) {
+	vars := map[string]any{
+		"code":  map[string]any{"passed": true, "score": 0.5},
+		"label": "smoke",
+	}
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{"missing.passed", `unknown name "missing"`},
+		{"code.nope", `unknown name "code.nope"`},
+		{"label.passed", "label is string"},
+		{"code.score", "not a boolean"},
+		{"code.score && code.passed", "&& needs a boolean"},
+		{"label > 1", "cannot compare"},
+		{"code.passed < true", "cannot compare"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			e, err := Parse(tt.expr)
+			require.NoError(t, err)
+			_, err = e.EvalBool(vars)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package graders
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/microsoft/waza/internal/models"
+)
+
+const (
+	corpusMethodNgram  = "ngram"
+	corpusMethodCosine = "cosine"
+
+	corpusAggregateMax  = "max"
+	corpusAggregateMean = "mean"
+
+	defaultCorpusNgramSize = 3
+	defaultCorpusThreshold = 0.5
+)
+
+// corpusSimilarityGrader scores how close the output is to a corpus of approved
+// reference outputs. Both methods are lexical, so the same output and corpus
+// always get the same score.
+type corpusSimilarityGrader struct {
+	name      string
+	corpusDir string
+	method    string
+	n         int
+	aggregate string
+	threshold float64
+}
+
+// NewCorpusSimilarityGrader creates a [corpusSimilarityGrader] that compares the
+// output with every file in a reference corpus directory.
+func NewCorpusSimilarityGrader(name string, args models.CorpusSimilarityGraderParameters) (*corpusSimilarityGrader, error) {
+	if strings.TrimSpace(args.CorpusDir) == "" {
+		return nil, fmt.Errorf("corpus_similarity grader '%s' must have a 'corpus_dir'", name)
+	}
+
+	g := &corpusSimilarityGrader{
+		name:      name,
+		corpusDir: args.CorpusPath(),
+		method:    args.Method,
+		n:         args.N,
+		aggregate: args.Aggregate,
+		threshold: defaultCorpusThreshold,
+	}
+	if g.method == "" {
+		g.method = corpusMethodNgram
+	}
+	if g.method != corpusMethodNgram && g.method != corpusMethodCosine {
+		return nil, fmt.Errorf("corpus_similarity grader '%s': method must be %q or %q, got %q", name, corpusMethodNgram, corpusMethodCosine, g.method)
+	}
+	if g.n == 0 {
+		g.n = defaultCorpusNgramSize
+	}
+	if g.n < 1 {
+		return nil, fmt.Errorf("corpus_similarity grader '%s': n must be at least 1, got %d", name, g.n)
+	}
+	if g.aggregate == "" {
+		g.aggregate = corpusAggregateMax
+	}
+	if g.aggregate != corpusAggregateMax && g.aggregate != corpusAggregateMean {
+		return nil, fmt.Errorf("corpus_similarity grader '%s': aggregate must be %q or %q, got %q", name, corpusAggregateMax, corpusAggregateMean, g.aggregate)
+	}
+	if args.Threshold != nil {
+		if *args.Threshold < 0 || *args.Threshold > 1 {
+			return nil, fmt.Errorf("corpus_similarity grader '%s': threshold must be between 0.0 and 1.0, got %g", name, *args.Threshold)
+		}
+		g.threshold = *args.Threshold
+	}
+	return g, nil
+}
+
+func (g *corpusSimilarityGrader) Name() string            { return g.name }
+func (g *corpusSimilarityGrader) Kind() models.GraderKind { return models.GraderKindCorpusSimilarity }
+
+func (g *corpusSimilarityGrader) Grade(ctx context.Context, gradingContext *Context) (*models.GraderResults, error) {
+	return measureTime(func() (*models.GraderResults, error) {
+		refs, err := loadCorpus(g.corpusDir)
+		if err != nil {
+			return nil, fmt.Errorf("corpus_similarity grader '%s': %w", g.name, err)
+		}
+
+		output := corpusWords(gradingContext.Output)
+		similarities := make(map[string]float64, len(refs))
+		bestMatch := ""
+		best, total := -1.0, 0.0
+		// refs are sorted by path, so ties go to the first reference
+		for _, ref := range refs {
+			sim := g.similarity(output, corpusWords(ref.text))
+			similarities[ref.path] = sim
+			total += sim
+			if sim > best {
+				best, bestMatch = sim, ref.path
+			}
+		}
+
+		score := best
+		if g.aggregate == corpusAggregateMean {
+			score = total / float64(len(refs))
+		}
+		passed := score >= g.threshold
+
+		return &models.GraderResults{
+			Name:   g.name,
+			Type:   models.GraderKindCorpusSimilarity,
+			Score:  score,
+			Passed: passed,
+			Feedback: fmt.Sprintf("%s %s similarity %.2f against %d reference(s) (closest: %s, %.2f); threshold %.2f",
+				g.aggregate, g.method, score, len(refs), bestMatch, best, g.threshold),
+			Details: map[string]any{
+				"method":       g.method,
+				"aggregate":    g.aggregate,
+				"threshold":    g.threshold,
+				"best_match":   bestMatch,
+				"similarities": similarities,
+			},
+		}, nil
+	})
+}
+
+func (g *corpusSimilarityGrader) similarity(a, b []string) float64 {
+	if g.method == corpusMethodCosine {
+		return cosineSimilarity(a, b)
+	}
+	return ngramJaccard(a, b, g.n)
+}
+
+// corpusReference is one reference file, keyed by its path relative to the corpus directory.
+type corpusReference struct {
+	path string
+	text string
+}
+
+// loadCorpus reads every non-hidden regular file under dir, in path order.
+func loadCorpus(dir string) ([]corpusReference, error) {
+	var refs []corpusReference
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		refs = append(refs, corpusReference{path: filepath.ToSlash(rel), text: string(data)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading corpus_dir: %w", err)
+	}
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("corpus_dir %s has no reference files", dir)
+	}
+	return refs, nil
+}
+
+// corpusWords lowercases text and splits it into runs of letters and digits.
+// Unlike the trigger grader's tokenize it keeps short and stop words, which
+// carry much of a text's style.
+func corpusWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// ngramJaccard is the Jaccard index of the word n-gram sets of a and b. Texts
+// shorter than n words are compared as a single n-gram.
+func ngramJaccard(a, b []string, n int) float64 {
+	setA, setB := ngramSet(a, n), ngramSet(b, n)
+	if len(setA) == 0 || len(setB) == 0 {
+		return 0
+	}
+	shared := 0
+	for gram := range setA {
+		if setB[gram] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(setA)+len(setB)-shared)
+}
+
+func ngramSet(words []string, n int) map[string]bool {
+	set := make(map[string]bool)
+	if len(words) == 0 {
+		return set
+	}
+	n = min(n, len(words))
+	for i := 0; i+n <= len(words); i++ {
+		set[strings.Join(words[i:i+n], " ")] = true
+	}
+	return set
+}
+
+// cosineSimilarity compares the word frequency vectors of a and b.
+func cosineSimilarity(a, b []string) float64 {
+	freqA, freqB := wordCounts(a), wordCounts(b)
+	var dot, normA, normB float64
+	for w, ca := range freqA {
+		dot += ca * freqB[w]
+		normA += ca * ca
+	}
+	for _, cb := range freqB {
+		normB += cb * cb
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return min(1, dot/(math.Sqrt(normA)*math.Sqrt(normB)))
+}
+
+func wordCounts(words []string) map[string]float64 {
+	counts := make(map[string]float64, len(words))
+	for _, w := range words {
+		counts[w]++
+	}
+	return counts
+}
@@ -0,0 +1,172 @@
+package graders
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/microsoft/waza/internal/models"
+	"github.com/microsoft/waza/internal/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCorpus creates a corpus directory holding the given files.
+func writeCorpus(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+var styleCorpus = map[string]string{
+	"release-notes.md": "## Summary\n\nThis release adds retry support to the upload client and fixes a crash when the config file is empty.",
+	"incident.md":      "Root cause: the cache expired before the deploy finished. Mitigation: we pinned the cache version and reran the pipeline.",
+	"nested/howto.md":  "To rotate a key, open the portal, select the vault and choose Regenerate. Update any service that reads the old key.",
+	".hidden":          "This release adds retry support to the upload client and fixes a crash when the config file is empty.",
+}
+
+func TestCorpusSimilarityGrader_Basic(t *testing.T) {
+	g, err := NewCorpusSimilarityGrader("style", models.CorpusSimilarityGraderParameters{CorpusDir: "corpus"})
+	require.NoError(t, err)
+
+	require.Equal(t, models.GraderKindCorpusSimilarity, g.Kind())
+	require.Equal(t, "style", g.Name())
+	require.Equal(t, corpusMethodNgram, g.method)
+	require.Equal(t, defaultCorpusNgramSize, g.n)
+	require.Equal(t, corpusAggregateMax, g.aggregate)
+	require.Equal(t, defaultCorpusThreshold, g.threshold)
+}
+
+func TestCorpusSimilarityGrader_CloseMatchScoresHigh(t *testing.T) {
+	dir := writeCorpus(t, styleCorpus)
+	output := "## Summary\n\nThis release adds retry support to the upload client and fixes a crash when the config file is missing."
+
+	for _, method := range []string{corpusMethodNgram, corpusMethodCosine} {
+		t.Run(method, func(t *testing.T) {
+			g, err := NewCorpusSimilarityGrader("style", models.CorpusSimilarityGraderParameters{CorpusDir: dir, Method: method})
+			require.NoError(t, err)
+
+			results, err := g.Grade(context.Background(), &Context{Output: output})
+			require.NoError(t, err)
+			require.True(t, results.Passed, results.Feedback)
+			require.Greater(t, results.Score, 0.8)
+			require.Equal(t, "release-notes.md", results.Details["best_match"])
+
+			similarities := results.Details["similarities"].(map[string]float64)
+			require.Len(t, similarities, 3, "hidden files aren't references")
+			require.Less(t, similarities["incident.md"], 0.5)
+			require.Contains(t, similarities, "nested/howto.md")
+		})
+	}
+}
+
+func TestCorpusSimilarityGrader_ContextDir(t *testing.T) {
+	contextDir := writeCorpus(t, map[string]string{"refs/a.md": "approved reference text"})
+
+	g, err := NewCorpusSimilarityGrader("style", models.CorpusSimilarityGraderParameters{CorpusDir: "refs", ContextDir: contextDir})
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(contextDir, "refs"), g.corpusDir)
+
+	results, err := g.Grade(context.Background(), &Context{Output: "approved reference text"})
+	require.NoError(t, err)
+	require.True(t, results.Passed, results.Feedback)
+
+	abs := filepath.Join(contextDir, "refs")
+	g, err = NewCorpusSimilarityGrader("style", models.CorpusSimilarityGraderParameters{CorpusDir: abs, ContextDir: t.TempDir()})
+	require.NoError(t, err)
+	require.Equal(t, abs, g.corpusDir, "absolute paths ignore context_dir")
+}
+
+func TestCorpusSimilarityGrader_UnrelatedOutputFails(t *testing.T) {
+	dir := writeCorpus(t, styleCorpus)
+	g, err := NewCorpusSimilarityGrader("style", models.CorpusSimilarityGraderParameters{CorpusDir: dir})
+	require.NoError(t, err)
+
+	results, err := g.Grade(context.Background(), &Context{Output: "Bananas are an excellent source of potassium."})
+	require.NoError(t, err)
+	require.False(t, results.Passed)
+	require.Equal(t, 0.0, results.Score)
+}
+
+func TestCorpusSimilarityGrader_MeanAggregate(t *testing.T) {
+	dir := writeCorpus(t, map[string]string{
+		"a.txt": "alpha beta gamma delta",
+		"b.txt": "epsilon zeta eta theta",
+	})
+	g, err := NewCorpusSimilarityGrader("style", models.CorpusSimilarityGraderParameters{
+		CorpusDir: dir,
+		N:         1,
+		Aggregate: corpusAggregateMean,
+		Threshold: utils.Ptr(0.4),
+	})
+	require.NoError(t, err)
+
+	results, err := g.Grade(context.Background(), &Context{Output: "alpha beta gamma delta"})
+	require.NoError(t, err)
+	require.InDelta(t, 0.5, results.Score, 1e-9, "1.0 against a.txt and 0.0 against b.txt")
+	require.True(t, results.Passed)
+}
+
+func TestCorpusSimilarityGrader_Deterministic(t *testing.T) {
+	dir := writeCorpus(t, styleCorpus)
+	g, err := NewCorpusSimilarityGrader("style", models.CorpusSimilarityGraderParameters{CorpusDir: dir})
+	require.NoError(t, err)
+
+	output := "To rotate a key, open the vault and choose Regenerate."
+	first, err := g.Grade(context.Background(), &Context{Output: output})
+	require.NoError(t, err)
+	for range 5 {
+		again, err := g.Grade(context.Background(), &Context{Output: output})
+		require.NoError(t, err)
+		require.Equal(t, first.Score, again.Score)
+		require.Equal(t, first.Details["best_match"], again.Details["best_match"])
+	}
+}
+
+func TestNgramJaccard(t *testing.T) {
+	words := corpusWords
+	require.Equal(t, 1.0, ngramJaccard(words("a b c d"), words("A, b. c d!"), 2))
+	// bigrams {a b, b c, c d} vs {a b, b c, c e}: 2 shared of 4
+	require.Equal(t, 0.5, ngramJaccard(words("a b c d"), words("a b c e"), 2))
+	require.Equal(t, 1.0, ngramJaccard(words("short"), words("short"), 3), "texts shorter than n compare as one gram")
+	require.Equal(t, 0.0, ngramJaccard(nil, words("a b c"), 3))
+}
+
+func TestCorpusSimilarityGrader_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		params models.CorpusSimilarityGraderParameters
+		want   string
+	}{
+		{"missing corpus_dir", models.CorpusSimilarityGraderParameters{}, "must have a 'corpus_dir'"},
+		{"bad method", models.CorpusSimilarityGraderParameters{CorpusDir: "c", Method: "embedding"}, `method must be "ngram" or "cosine"`},
+		{"bad n", models.CorpusSimilarityGraderParameters{CorpusDir: "c", N: -1}, "n must be at least 1"},
+		{"bad aggregate", models.CorpusSimilarityGraderParameters{CorpusDir: "c", Aggregate: "median"}, `aggregate must be "max" or "mean"`},
+		{"bad threshold", models.CorpusSimilarityGraderParameters{CorpusDir: "c", Threshold: utils.Ptr(1.5)}, "threshold must be between"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCorpusSimilarityGrader("style", tt.params)
+			require.ErrorContains(t, err, tt.want)
+		})
+	}
+
+	t.Run("empty corpus", func(t *testing.T) {
+		g, err := NewCorpusSimilarityGrader("style", models.CorpusSimilarityGraderParameters{CorpusDir: t.TempDir()})
+		require.NoError(t, err)
+		_, err = g.Grade(context.Background(), &Context{Output: "x"})
+		require.ErrorContains(t, err, "has no reference files")
+	})
+
+	t.Run("missing corpus", func(t *testing.T) {
+		g, err := NewCorpusSimilarityGrader("style", models.CorpusSimilarityGraderParameters{CorpusDir: filepath.Join(t.TempDir(), "nope")})
+		require.NoError(t, err)
+		_, err = g.Grade(context.Background(), &Context{Output: "x"})
+		require.ErrorContains(t, err, "reading corpus_dir")
+	})
+}
@@ -18,6 +18,7 @@ if (data.debug) {
 const evalContext = {
   output: data.output,
   outcome: data.outcome,
+  metadata: data.metadata || {},
   transcript: data.transcript,
   tool_calls: data.tool_calls,
   errors: data.transcript.filter(
@@ -26,6 +27,7 @@ const evalContext = {
       (t.content && String(t.content).includes("error"))
   ),
   duration_ms: data.duration_ms || 0,
+  platform: process.platform,
 
   // Expose safe builtins
   Array,
@@ -50,10 +52,16 @@ const evalContext = {
 vm.createContext(evalContext);
 
 const assertions = data.assertions || [];
+const skipIf = data.skip_if || [];
 const results = [];
 
-for (const assertion of assertions) {
+for (const [i, assertion] of assertions.entries()) {
   try {
+    const condition = skipIf[i] || "";
+    if (condition && vm.runInContext(condition, evalContext, { timeout: 5000 })) {
+      results.push("skipped");
+      continue;
+    }
     const result = vm.runInContext(assertion, evalContext, { timeout: 5000 });
     results.push(!!result ? "" : "fail");
   } catch (err) {
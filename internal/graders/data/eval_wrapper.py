@@ -20,10 +20,12 @@ if data.get('debug'):
 eval_context = {
     "output": data['output'] or "",
     "outcome": data['outcome'],
+    "metadata": data.get('metadata') or {},
     "transcript": data['transcript'],
     "tool_calls": data['tool_calls'],
     "errors": [t for t in data['transcript'] if "error" in t.get("type") or "error" in str(t.get("content", ""))],
     "duration_ms": data['duration_ms'],
+    "platform": sys.platform,
     "len": len,
     "any": any,
     "all": all,
@@ -40,11 +42,17 @@ eval_context = {
 
 # anything but empty string means we failed.
 # 'fail' if it's just an assertion failure
+# 'skipped' if the assertion's skip_if condition was true
 # any other string is assumed to be an exception of some kind (ie, bad syntax, using a non-existent field).
 results: list[str] = []
+skip_if = data.get('skip_if') or []
 
-for assertion in data['assertions']:
+for i, assertion in enumerate(data['assertions']):
     try:
+        condition = skip_if[i] if i < len(skip_if) else ""
+        if condition and eval(condition, {"__builtins__": {}}, eval_context):
+            results.append("skipped")
+            continue
         result = eval(assertion, {"__builtins__": {}}, eval_context)
         results.append("" if not not result else "fail")
     except Exception as e:
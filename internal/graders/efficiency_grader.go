@@ -0,0 +1,96 @@
+package graders
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/microsoft/waza/internal/models"
+)
+
+// efficiencyGrader checks that the agent finished within a budget of tool
+// calls and conversation turns, to catch runs that reach the right answer the
+// long way round.
+type efficiencyGrader struct {
+	name         string
+	maxToolCalls int
+	maxTurns     int
+}
+
+// NewEfficiencyGrader creates an efficiencyGrader from decoded parameters.
+func NewEfficiencyGrader(name string, params models.EfficiencyGraderParameters) (*efficiencyGrader, error) {
+	if params.MaxToolCalls < 0 || params.MaxTurns < 0 {
+		return nil, fmt.Errorf("efficiency grader '%s': max_tool_calls and max_turns must be non-negative", name)
+	}
+	if params.MaxToolCalls == 0 && params.MaxTurns == 0 {
+		return nil, fmt.Errorf("efficiency grader '%s' must set max_tool_calls or max_turns", name)
+	}
+
+	return &efficiencyGrader{
+		name:         name,
+		maxToolCalls: params.MaxToolCalls,
+		maxTurns:     params.MaxTurns,
+	}, nil
+}
+
+func (eg *efficiencyGrader) Name() string            { return eg.name }
+func (eg *efficiencyGrader) Kind() models.GraderKind { return models.GraderKindEfficiency }
+
+func (eg *efficiencyGrader) Grade(ctx context.Context, gradingContext *Context) (*models.GraderResults, error) {
+	return measureTime(func() (*models.GraderResults, error) {
+		session := gradingContext.Session
+		if session == nil {
+			return &models.GraderResults{
+				Name:     eg.name,
+				Type:     models.GraderKindEfficiency,
+				Score:    0.0,
+				Passed:   false,
+				Feedback: "No session digest available for efficiency grading",
+			}, nil
+		}
+
+		details := map[string]any{
+			"max_tool_calls":  eg.maxToolCalls,
+			"max_turns":       eg.maxTurns,
+			"tool_call_count": session.ToolCallCount,
+		}
+
+		var checks int
+		var failures []string
+		if eg.maxToolCalls > 0 {
+			checks++
+			if over := session.ToolCallCount - eg.maxToolCalls; over > 0 {
+				failures = append(failures, fmt.Sprintf("Used %d tool calls, %d over the limit of %d", session.ToolCallCount, over, eg.maxToolCalls))
+			}
+		}
+		if eg.maxTurns > 0 {
+			checks++
+			// Turns come from the engine's usage data. Without it the run
+			// can't be shown to be within budget, so the check fails.
+			if session.Usage == nil {
+				failures = append(failures, "Turn count unavailable: the engine reported no usage data")
+			} else {
+				turns := session.Usage.Turns
+				details["turns"] = turns
+				if over := turns - eg.maxTurns; over > 0 {
+					failures = append(failures, fmt.Sprintf("Took %d turns, %d over the limit of %d", turns, over, eg.maxTurns))
+				}
+			}
+		}
+		details["failures"] = failures
+
+		feedback := "Within efficiency limits"
+		if len(failures) > 0 {
+			feedback = strings.Join(failures, "; ")
+		}
+
+		return &models.GraderResults{
+			Name:     eg.name,
+			Type:     models.GraderKindEfficiency,
+			Score:    float64(checks-len(failures)) / float64(checks),
+			Passed:   len(failures) == 0,
+			Feedback: feedback,
+			Details:  details,
+		}, nil
+	})
+}
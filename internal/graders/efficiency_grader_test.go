@@ -0,0 +1,67 @@
+package graders
+
+import (
+	"context"
+	"testing"
+
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEfficiencyGrader_Constructor(t *testing.T) {
+	_, err := NewEfficiencyGrader("test", models.EfficiencyGraderParameters{})
+	require.ErrorContains(t, err, "must set max_tool_calls or max_turns")
+
+	_, err = NewEfficiencyGrader("test", models.EfficiencyGraderParameters{MaxTurns: -1})
+	require.ErrorContains(t, err, "non-negative")
+
+	g, err := NewEfficiencyGrader("test", models.EfficiencyGraderParameters{MaxToolCalls: 5})
+	require.NoError(t, err)
+	require.Equal(t, models.GraderKindEfficiency, g.Kind())
+	require.Equal(t, "test", g.Name())
+}
+
+func TestEfficiencyGrader_Grade(t *testing.T) {
+	params := models.EfficiencyGraderParameters{MaxToolCalls: 5, MaxTurns: 3}
+	grade := func(t *testing.T, session *models.SessionDigest) *models.GraderResults {
+		g, err := NewEfficiencyGrader("efficient", params)
+		require.NoError(t, err)
+		results, err := g.Grade(context.Background(), &Context{Session: session})
+		require.NoError(t, err)
+		return results
+	}
+
+	t.Run("within bounds", func(t *testing.T) {
+		results := grade(t, &models.SessionDigest{ToolCallCount: 5, Usage: &models.UsageStats{Turns: 3}})
+		require.True(t, results.Passed)
+		require.Equal(t, 1.0, results.Score)
+		require.Equal(t, "Within efficiency limits", results.Feedback)
+	})
+
+	t.Run("over tool call limit", func(t *testing.T) {
+		results := grade(t, &models.SessionDigest{ToolCallCount: 8, Usage: &models.UsageStats{Turns: 2}})
+		require.False(t, results.Passed)
+		require.Equal(t, 0.5, results.Score)
+		require.Equal(t, "Used 8 tool calls, 3 over the limit of 5", results.Feedback)
+	})
+
+	t.Run("over both limits", func(t *testing.T) {
+		results := grade(t, &models.SessionDigest{ToolCallCount: 6, Usage: &models.UsageStats{Turns: 7}})
+		require.False(t, results.Passed)
+		require.Equal(t, 0.0, results.Score)
+		require.Equal(t, "Used 6 tool calls, 1 over the limit of 5; Took 7 turns, 4 over the limit of 3", results.Feedback)
+		require.Equal(t, 7, results.Details["turns"])
+	})
+
+	t.Run("missing usage fails turn check", func(t *testing.T) {
+		results := grade(t, &models.SessionDigest{ToolCallCount: 1})
+		require.False(t, results.Passed)
+		require.Contains(t, results.Feedback, "Turn count unavailable")
+	})
+
+	t.Run("missing session", func(t *testing.T) {
+		results := grade(t, nil)
+		require.False(t, results.Passed)
+		require.Equal(t, 0.0, results.Score)
+	})
+}
@@ -29,7 +29,9 @@ type Context struct {
 	Output     string
 	Outcome    map[string]any
 	DurationMS int64
-	Metadata   map[string]any
+	// Metadata holds the task's `metadata:` values, for graders that compare
+	// against known-good data.
+	Metadata map[string]any
 
 	// WorkspaceDir is the sandbox folder we used for this session - it should contain any edits
 	// or other changes we've made. This can be useful for things like the [FileGrader],
@@ -50,6 +52,18 @@ type Context struct {
 	// BaselineOutput is the agent output from the baseline (no-skill) run.
 	// Populated when running in baseline mode; used by pairwise prompt grading.
 	BaselineOutput string
+
+	// Limiter, when set, is shared with other tasks' grading to bound how many
+	// graders run at once.
+	Limiter *Limiter
+
+	// ContextDir is the task's context directory. A corpus_similarity grader
+	// without a context_dir of its own resolves a relative corpus_dir against it.
+	ContextDir string
+
+	// TaskLimiter, when set, lets RunAllCached run this task's graders
+	// concurrently, at most its limit at a time. Without it they run one by one.
+	TaskLimiter *Limiter
 }
 
 // Create creates a validator from the global registry
@@ -63,6 +77,8 @@ func Create(identifier string, params models.GraderParameters) (Grader, error) {
 		return NewFileGrader(identifier, p)
 	case models.BehaviorGraderParameters:
 		return NewBehaviorGrader(identifier, p)
+	case models.EfficiencyGraderParameters:
+		return NewEfficiencyGrader(identifier, p)
 	case models.ActionSequenceGraderParameters:
 		return NewActionSequenceGrader(identifier, p)
 	case models.SkillInvocationGraderParameters:
@@ -78,7 +94,16 @@ func Create(identifier string, params models.GraderParameters) (Grader, error) {
 	case models.ProgramGraderParameters:
 		return NewProgramGrader(identifier, p)
 	case models.TriggerHeuristicGraderParameters:
+		if p.Source == models.TriggerSourceInvocation {
+			return NewTriggerInvocationGrader(identifier, p)
+		}
 		return NewTriggerHeuristicGrader(identifier, p)
+	case models.NoSecretsGraderParameters:
+		return NewNoSecretsGrader(identifier, p)
+	case models.RubricGraderParameters:
+		return NewRubricGrader(identifier, p)
+	case models.CorpusSimilarityGraderParameters:
+		return NewCorpusSimilarityGrader(identifier, p)
 	default:
 		return nil, fmt.Errorf("grader with identifier %q is using an unsupported grader type. Valid grader types: %s", identifier, strings.Join(models.AllGraderKinds(), ", "))
 	}
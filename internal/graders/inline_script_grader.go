@@ -19,6 +19,14 @@ import (
 
 const allAssertionsPassedMsg = "All assertions passed"
 
+// skipIfPrefix starts an assertion that only runs when a condition is false:
+//
+//	skip_if(platform == "win32") "chmod" in output
+const skipIfPrefix = "skip_if("
+
+// skippedResult is what the wrapper scripts report for a skipped assertion.
+const skippedResult = "skipped"
+
 //go:embed data/eval_wrapper.py
 var evalWrapperPy string
 
@@ -30,6 +38,8 @@ var evalWrapperJS string
 type InlineScriptGrader struct {
 	name       string
 	assertions []string
+	// skipIf holds each assertion's skip_if condition, or "" when it has none.
+	skipIf []string
 
 	scriptExt      string
 	scriptBin      string
@@ -44,8 +54,16 @@ type InlineScriptResult struct {
 
 func NewInlineScriptGrader(name string, args models.InlineScriptGraderParameters) (*InlineScriptGrader, error) {
 	var g = &InlineScriptGrader{
-		name:       name,
-		assertions: args.Assertions,
+		name: name,
+	}
+
+	for _, a := range args.Assertions {
+		condition, assertion, err := parseSkipIf(a)
+		if err != nil {
+			return nil, err
+		}
+		g.assertions = append(g.assertions, assertion)
+		g.skipIf = append(g.skipIf, condition)
 	}
 
 	if args.Language == "" {
@@ -68,6 +86,52 @@ func NewInlineScriptGrader(name string, args models.InlineScriptGraderParameters
 	return g, nil
 }
 
+// parseSkipIf splits `skip_if(<condition>) <assertion>` into its condition and
+// assertion. Assertions without the prefix are returned with an empty condition.
+func parseSkipIf(assertion string) (condition, rest string, err error) {
+	trimmed := strings.TrimSpace(assertion)
+	if !strings.HasPrefix(trimmed, skipIfPrefix) {
+		return "", assertion, nil
+	}
+
+	// Find the closing paren, ignoring parens inside string literals
+	depth := 1
+	var quote rune
+	escaped := false
+	body := trimmed[len(skipIfPrefix):]
+	for i, c := range body {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if c == '\\' {
+				escaped = true
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			condition = strings.TrimSpace(body[:i])
+			rest = strings.TrimSpace(body[i+1:])
+			if condition == "" {
+				return "", "", fmt.Errorf("assertion %q: skip_if() needs a condition", assertion)
+			}
+			if rest == "" {
+				return "", "", fmt.Errorf("assertion %q: skip_if(...) must be followed by an assertion", assertion)
+			}
+			return condition, rest, nil
+		}
+	}
+	return "", "", fmt.Errorf("assertion %q: unterminated skip_if(", assertion)
+}
+
 func resolvePythonBin() string {
 	// Prefer python3, but verify it actually works — on Windows the
 	// Microsoft Store registers a python3.exe stub that just prints
@@ -98,19 +162,37 @@ func (isg *InlineScriptGrader) Grade(ctx context.Context, gradingContext *Contex
 			}, nil
 		}
 
-		failures, passed, err := isg.runScript(ctx, gradingContext)
+		failures, passed, skipped, err := isg.runScript(ctx, gradingContext)
 
 		if err != nil {
 			return nil, err
 		}
 
-		score := float64(passed) / float64(len(isg.assertions))
+		// Skipped assertions count toward neither side of the pass fraction
+		score := 1.0
+		if ran := len(isg.assertions) - len(skipped); ran > 0 {
+			score = float64(passed) / float64(ran)
+		}
 		allPassed := len(failures) == 0
 
 		feedback := allAssertionsPassedMsg
 		if !allPassed {
 			feedback = strings.Join(failures, "; ")
 		}
+		if len(skipped) > 0 {
+			feedback += fmt.Sprintf(" (%d skipped)", len(skipped))
+		}
+
+		// TODO: can we mapstructure.encode or something similar - this is a contract, like any other.
+		details := map[string]any{
+			"total_assertions":  len(isg.assertions),
+			"passed_assertions": passed,
+			"failures":          failures,
+		}
+		if len(skipped) > 0 {
+			details["skipped_assertions"] = len(skipped)
+			details["skipped"] = skipped
+		}
 
 		return &models.GraderResults{
 			Name:     isg.name,
@@ -118,28 +200,23 @@ func (isg *InlineScriptGrader) Grade(ctx context.Context, gradingContext *Contex
 			Score:    score,
 			Passed:   allPassed,
 			Feedback: feedback,
-			// TODO: can we mapstructure.encode or something similar - this is a contract, like any other.
-			Details: map[string]any{
-				"total_assertions":  len(isg.assertions),
-				"passed_assertions": passed,
-				"failures":          failures,
-			},
+			Details:  details,
 		}, nil
 	})
 }
 
-func (isg *InlineScriptGrader) runScript(ctx context.Context, gradingContext *Context) (failures []string, passed int, err error) {
-	stdinText, err := getStdinTextForScript(gradingContext, isg.assertions)
+func (isg *InlineScriptGrader) runScript(ctx context.Context, gradingContext *Context) (failures []string, passed int, skipped []string, err error) {
+	stdinText, err := getStdinTextForScript(gradingContext, isg.assertions, isg.skipIf)
 
 	if err != nil {
 		// let's not quit the entire thing, but we can mark this failure.
-		return nil, 0, fmt.Errorf("failed: script conversion failed for assertions: %w", err)
+		return nil, 0, nil, fmt.Errorf("failed: script conversion failed for assertions: %w", err)
 	}
 
 	tempScriptFile, err := os.CreateTemp("", "waza-inline-script-*."+isg.scriptExt)
 
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, nil, err
 	}
 
 	defer func() {
@@ -147,11 +224,11 @@ func (isg *InlineScriptGrader) runScript(ctx context.Context, gradingContext *Co
 	}()
 
 	if _, err := tempScriptFile.Write([]byte(isg.scriptContents)); err != nil {
-		return nil, 0, err
+		return nil, 0, nil, err
 	}
 
 	if err := tempScriptFile.Close(); err != nil {
-		return nil, 0, err
+		return nil, 0, nil, err
 	}
 
 	cmd := exec.CommandContext(ctx, isg.scriptBin, tempScriptFile.Name())
@@ -162,7 +239,7 @@ func (isg *InlineScriptGrader) runScript(ctx context.Context, gradingContext *Co
 	outputBytes, err := cmd.Output()
 
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to execute inline script for assertions (%s): %w", string(outputBytes), err)
+		return nil, 0, nil, fmt.Errorf("failed to execute inline script for assertions (%s): %w", string(outputBytes), err)
 	}
 
 	var snippetOutput *struct {
@@ -170,16 +247,19 @@ func (isg *InlineScriptGrader) runScript(ctx context.Context, gradingContext *Co
 	}
 
 	if err := json.Unmarshal(outputBytes, &snippetOutput); err != nil {
-		return nil, 0, fmt.Errorf("failed to deserialize output (%s) from assertions: %w", string(outputBytes), err)
+		return nil, 0, nil, fmt.Errorf("failed to deserialize output (%s) from assertions: %w", string(outputBytes), err)
 	}
 
 	// TODO: it might be nice to get more rich results here. Currently the script returns
 	// a Results slice with one entry per assertion, where:
 	//   ""     => assertion passed
 	//   "fail" => assertion failed with no additional message
+	//   "skipped" => the assertion's skip_if condition was true
 	//   other  => assertion failed and the value is an error message.
 	for i, errMsg := range snippetOutput.Results {
-		if errMsg == "fail" {
+		if errMsg == skippedResult {
+			skipped = append(skipped, isg.assertions[i])
+		} else if errMsg == "fail" {
 			failures = append(failures, fmt.Sprintf("Failed: %s", isg.assertions[i]))
 		} else if errMsg != "" {
 			failures = append(failures, fmt.Sprintf("Failed: %s: %s", isg.assertions[i], errMsg))
@@ -187,10 +267,10 @@ func (isg *InlineScriptGrader) runScript(ctx context.Context, gradingContext *Co
 			passed++
 		}
 	}
-	return failures, passed, nil
+	return failures, passed, skipped, nil
 }
 
-func getStdinTextForScript(gradingContext *Context, assertions []string) ([]byte, error) {
+func getStdinTextForScript(gradingContext *Context, assertions, skipIf []string) ([]byte, error) {
 	var sessionEvents []copilot.SessionEvent
 
 	for _, te := range gradingContext.Transcript {
@@ -203,6 +283,12 @@ func getStdinTextForScript(gradingContext *Context, assertions []string) ([]byte
 		outcome = map[string]any{}
 	}
 
+	metadata := gradingContext.Metadata
+
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+
 	transcriptEvents := gradingContext.Transcript
 
 	if transcriptEvents == nil {
@@ -218,20 +304,24 @@ func getStdinTextForScript(gradingContext *Context, assertions []string) ([]byte
 	scriptStdin := struct {
 		Output     string                   `json:"output"`
 		Outcome    map[string]any           `json:"outcome"`
+		Metadata   map[string]any           `json:"metadata"`
 		Transcript []models.TranscriptEvent `json:"transcript"`
 		ToolCalls  []models.ToolCall        `json:"tool_calls"`
 		DurationMS int64                    `json:"duration_ms"`
 		Assertions []string                 `json:"assertions"`
+		SkipIf     []string                 `json:"skip_if"`
 
 		// Debug causes the underlying scripts to print, to stderr, their stdin contents.
 		Debug bool `json:"debug"`
 	}{
 		Output:     gradingContext.Output,
 		Outcome:    outcome,
+		Metadata:   metadata,
 		Transcript: transcriptEvents,
 		ToolCalls:  toolCalls,
 		DurationMS: gradingContext.DurationMS,
 		Assertions: assertions,
+		SkipIf:     skipIf,
 		Debug:      slog.Default().Enabled(context.Background(), slog.LevelDebug),
 	}
 
@@ -234,6 +234,41 @@ func TestWithRealContextJavascript(t *testing.T) {
 	require.True(t, results.Passed)
 }
 
+func TestTaskMetadata(t *testing.T) {
+	gradingContext := &Context{
+		Output:   "found 3 files",
+		Metadata: map[string]any{"expected_count": 3, "label": "files"},
+	}
+
+	t.Run("python", func(t *testing.T) {
+		skipIfNoPython(t)
+
+		grader, err := NewInlineScriptGrader("test", models.InlineScriptGraderParameters{Language: models.LanguagePython, Assertions: []string{
+			"f\"found {metadata['expected_count']} {metadata['label']}\" == output",
+			"metadata.get('missing') is None",
+		}})
+		require.NoError(t, err)
+
+		results, err := grader.Grade(context.Background(), gradingContext)
+		require.NoError(t, err)
+		require.Equal(t, allAssertionsPassedMsg, results.Feedback)
+	})
+
+	t.Run("javascript", func(t *testing.T) {
+		skipIfNoJavascript(t)
+
+		grader, err := NewInlineScriptGrader("test", models.InlineScriptGraderParameters{Language: models.LanguageJavascript, Assertions: []string{
+			"output === `found ${metadata.expected_count} ${metadata.label}`",
+			"metadata.missing === undefined",
+		}})
+		require.NoError(t, err)
+
+		results, err := grader.Grade(context.Background(), gradingContext)
+		require.NoError(t, err)
+		require.Equal(t, allAssertionsPassedMsg, results.Feedback)
+	})
+}
+
 func TestWithError(t *testing.T) {
 	skipIfNoPython(t)
 
@@ -293,6 +328,96 @@ func TestWithSyntaxError(t *testing.T) {
 	})
 }
 
+func TestSkipIfAssertions(t *testing.T) {
+	langs := []struct {
+		lang       models.Language
+		skip       func(t *testing.T)
+		assertions []string
+		skipped    string
+	}{
+		{models.LanguagePython, skipIfNoPython, []string{
+			"'hello' in output",
+			"skip_if(output == 'hello world') 1 == 0",
+			"skip_if(platform == 'no-such-os') len(output) > 0",
+		}, "1 == 0"},
+		{models.LanguageJavascript, skipIfNoJavascript, []string{
+			"output.includes('hello')",
+			"skip_if(output === 'hello world') 1 === 0",
+			"skip_if(platform === 'no-such-os') output.length > 0",
+		}, "1 === 0"},
+	}
+
+	for _, tc := range langs {
+		t.Run(string(tc.lang), func(t *testing.T) {
+			tc.skip(t)
+
+			grader, err := NewInlineScriptGrader("test", models.InlineScriptGraderParameters{Language: tc.lang, Assertions: tc.assertions})
+			require.NoError(t, err)
+
+			results, err := grader.Grade(context.Background(), &Context{Output: "hello world"})
+			require.NoError(t, err)
+
+			// The failing assertion is skipped, so the other two decide the result
+			require.True(t, results.Passed)
+			require.Equal(t, 1.0, results.Score)
+			require.Equal(t, "All assertions passed (1 skipped)", results.Feedback)
+			require.Equal(t, 2, results.Details["passed_assertions"])
+			require.Equal(t, 1, results.Details["skipped_assertions"])
+			require.Equal(t, []string{tc.skipped}, results.Details["skipped"])
+		})
+	}
+
+	t.Run("skipped assertions don't count toward the score", func(t *testing.T) {
+		skipIfNoPython(t)
+
+		grader, err := NewInlineScriptGrader("test", models.InlineScriptGraderParameters{Language: models.LanguagePython, Assertions: []string{
+			"1 == 1",
+			"1 == 0",
+			"skip_if(True) 1 == 0",
+		}})
+		require.NoError(t, err)
+
+		results, err := grader.Grade(context.Background(), &Context{})
+		require.NoError(t, err)
+		require.False(t, results.Passed)
+		require.Equal(t, 0.5, results.Score)
+		require.Equal(t, "Failed: 1 == 0 (1 skipped)", results.Feedback)
+	})
+}
+
+func TestParseSkipIf(t *testing.T) {
+	tests := []struct {
+		in        string
+		condition string
+		rest      string
+		err       string
+	}{
+		{in: "1 == 1", rest: "1 == 1"},
+		{in: "skip_if(True) 1 == 1", condition: "True", rest: "1 == 1"},
+		{in: "skip_if(len(output) > 0)  'x' in output", condition: "len(output) > 0", rest: "'x' in output"},
+		{in: `skip_if(output == ")") output != ""`, condition: `output == ")"`, rest: `output != ""`},
+		{in: "skip_if(True)", err: "must be followed by an assertion"},
+		{in: "skip_if() 1 == 1", err: "needs a condition"},
+		{in: "skip_if(len(output) 1 == 1", err: "unterminated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			condition, rest, err := parseSkipIf(tt.in)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.condition, condition)
+			require.Equal(t, tt.rest, rest)
+		})
+	}
+
+	_, err := NewInlineScriptGrader("test", models.InlineScriptGraderParameters{Assertions: []string{"skip_if(True"}})
+	require.ErrorContains(t, err, "unterminated")
+}
+
 func TestUnsupportedLanguage(t *testing.T) {
 	_, err := NewInlineScriptGrader("test", models.InlineScriptGraderParameters{Language: models.Language("ruby"), Assertions: []string{"true"}})
 	require.Error(t, err)
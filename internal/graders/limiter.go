@@ -0,0 +1,39 @@
+package graders
+
+import "context"
+
+// Limiter caps how many graders run at once across every RunAllCached call
+// that shares it, so parallel tasks can't flood a judge model with requests.
+// A nil *Limiter doesn't limit anything.
+type Limiter struct {
+	slots chan struct{}
+}
+
+// NewLimiter returns a Limiter allowing n graders to run at once, or nil
+// (unlimited) when n <= 0.
+func NewLimiter(n int) *Limiter {
+	if n <= 0 {
+		return nil
+	}
+	return &Limiter{slots: make(chan struct{}, n)}
+}
+
+// acquire blocks until a grader slot is free or ctx is done.
+func (l *Limiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *Limiter) release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+}
@@ -0,0 +1,55 @@
+package graders
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_BoundsConcurrency(t *testing.T) {
+	l := NewLimiter(3)
+	var inFlight, peak atomic.Int32
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() {
+			require.NoError(t, l.acquire(context.Background()))
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			inFlight.Add(-1)
+			l.release()
+		})
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+}
+
+func TestLimiter_ContextCanceled(t *testing.T) {
+	l := NewLimiter(1)
+	require.NoError(t, l.acquire(context.Background()))
+	defer l.release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, l.acquire(ctx), context.Canceled)
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	l := NewLimiter(0)
+	assert.Nil(t, l)
+	// A nil limiter never blocks.
+	for range 10 {
+		require.NoError(t, l.acquire(context.Background()))
+	}
+	l.release()
+}
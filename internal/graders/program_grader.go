@@ -3,8 +3,13 @@ package graders
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,14 +21,21 @@ import (
 const defaultProgramTimeoutSeconds = 30
 
 // programGrader runs an external program/script to grade agent output.
-// The agent output is passed via stdin, and the workspace directory is
-// available as the WAZA_WORKSPACE_DIR environment variable.
+// The agent output is passed via stdin, the workspace directory is
+// available as the WAZA_WORKSPACE_DIR environment variable, and the task's
+// metadata as JSON in WAZA_TASK_METADATA.
 // Exit code 0 = pass (1.0), non-zero = fail (0.0).
+//
+// With an artifact configured, the program instead grades a file the agent
+// produced: its absolute path is appended to the arguments and exported as
+// WAZA_ARTIFACT_PATH, and a successful program may report its score on stdout
+// (see [parseArtifactScore]).
 type programGrader struct {
-	name    string
-	command string
-	args    []string
-	timeout time.Duration
+	name     string
+	command  string
+	args     []string
+	timeout  time.Duration
+	artifact string
 }
 
 // NewProgramGrader creates a [programGrader] that runs an external command to grade output.
@@ -31,6 +43,9 @@ func NewProgramGrader(name string, args models.ProgramGraderParameters) (*progra
 	if args.Command == "" {
 		return nil, fmt.Errorf("program grader '%s' must have a 'command'", name)
 	}
+	if args.Artifact != "" && !filepath.IsLocal(args.Artifact) {
+		return nil, fmt.Errorf("program grader '%s': artifact %q must be a relative path inside the workspace", name, args.Artifact)
+	}
 
 	timeout := args.Timeout
 	if timeout <= 0 {
@@ -44,10 +59,11 @@ func NewProgramGrader(name string, args models.ProgramGraderParameters) (*progra
 	}
 
 	return &programGrader{
-		name:    name,
-		command: args.Command,
-		args:    args.Args,
-		timeout: time.Duration(timeout) * time.Second,
+		name:     name,
+		command:  args.Command,
+		args:     args.Args,
+		timeout:  time.Duration(timeout) * time.Second,
+		artifact: args.Artifact,
 	}, nil
 }
 
@@ -59,19 +75,53 @@ func (pg *programGrader) Grade(ctx context.Context, gradingContext *Context) (*m
 		timeoutCtx, cancel := context.WithTimeout(ctx, pg.timeout)
 		defer cancel()
 
-		cmd := exec.CommandContext(timeoutCtx, pg.command, pg.args...)
+		args := pg.args
+		artifactPath := ""
+		if pg.artifact != "" {
+			artifactPath = filepath.Join(gradingContext.WorkspaceDir, pg.artifact)
+			if _, err := os.Stat(artifactPath); err != nil {
+				return &models.GraderResults{
+					Name:     pg.name,
+					Type:     models.GraderKindProgram,
+					Score:    0.0,
+					Passed:   false,
+					Feedback: fmt.Sprintf("Artifact %s not found in workspace", pg.artifact),
+					Details: map[string]any{
+						"command":       pg.command,
+						"artifact":      pg.artifact,
+						"workspace_dir": gradingContext.WorkspaceDir,
+					},
+				}, nil
+			}
+			args = append(slices.Clone(pg.args), artifactPath)
+		}
+
+		cmd := exec.CommandContext(timeoutCtx, pg.command, args...)
 
 		// Pass agent output via stdin
 		cmd.Stdin = strings.NewReader(gradingContext.Output)
 
-		// Set workspace dir as environment variable
-		cmd.Env = append(cmd.Environ(), fmt.Sprintf("WAZA_WORKSPACE_DIR=%s", gradingContext.WorkspaceDir))
+		// Set workspace dir and task metadata as environment variables
+		metadata := gradingContext.Metadata
+		if metadata == nil {
+			metadata = map[string]any{}
+		}
+		metadataJSON, err := json.Marshal(metadata)
+		if err != nil {
+			return nil, fmt.Errorf("program grader '%s': encoding task metadata: %w", pg.name, err)
+		}
+		cmd.Env = append(cmd.Environ(),
+			fmt.Sprintf("WAZA_WORKSPACE_DIR=%s", gradingContext.WorkspaceDir),
+			fmt.Sprintf("WAZA_TASK_METADATA=%s", metadataJSON))
+		if artifactPath != "" {
+			cmd.Env = append(cmd.Env, fmt.Sprintf("WAZA_ARTIFACT_PATH=%s", artifactPath))
+		}
 
 		var stdout, stderr bytes.Buffer
 		cmd.Stdout = &stdout
 		cmd.Stderr = &stderr
 
-		err := cmd.Run()
+		err = cmd.Run()
 
 		notes := strings.TrimSpace(stdout.String())
 		errOutput := strings.TrimSpace(stderr.String())
@@ -104,19 +154,48 @@ func (pg *programGrader) Grade(ctx context.Context, gradingContext *Context) (*m
 			feedback = notes
 		}
 
+		details := map[string]any{
+			"command":       pg.command,
+			"args":          pg.args,
+			"stdout":        notes,
+			"stderr":        errOutput,
+			"workspace_dir": gradingContext.WorkspaceDir,
+		}
+		score := 1.0
+		if pg.artifact != "" {
+			details["artifact"] = pg.artifact
+			if s, fb, ok := parseArtifactScore(notes); ok {
+				score = s
+				if fb != "" {
+					feedback = fb
+				}
+			}
+		}
+
 		return &models.GraderResults{
 			Name:     pg.name,
 			Type:     models.GraderKindProgram,
-			Score:    1.0,
+			Score:    score,
 			Passed:   true,
 			Feedback: feedback,
-			Details: map[string]any{
-				"command":       pg.command,
-				"args":          pg.args,
-				"stdout":        notes,
-				"stderr":        errOutput,
-				"workspace_dir": gradingContext.WorkspaceDir,
-			},
+			Details:  details,
 		}, nil
 	})
 }
+
+// parseArtifactScore reads an artifact grader's stdout: either a bare number
+// or a JSON object like {"score": 0.8, "feedback": "..."}. ok is false when
+// stdout holds neither, in which case the exit code alone decides the score.
+func parseArtifactScore(stdout string) (score float64, feedback string, ok bool) {
+	if v, err := strconv.ParseFloat(stdout, 64); err == nil {
+		return v, "", true
+	}
+	var report struct {
+		Score    *float64 `json:"score"`
+		Feedback string   `json:"feedback"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil || report.Score == nil {
+		return 0, "", false
+	}
+	return *report.Score, report.Feedback, true
+}
@@ -96,6 +96,24 @@ func TestProgramGrader_Grade(t *testing.T) {
 		require.Equal(t, "hello from stdin", results.Feedback)
 	})
 
+	t.Run("task metadata is available as JSON env var", func(t *testing.T) {
+		g, err := NewProgramGrader("test", models.ProgramGraderParameters{Command: "sh",
+			Args: []string{"-c", `printf %s "$WAZA_TASK_METADATA"`},
+		})
+		require.NoError(t, err)
+
+		results, err := g.Grade(context.Background(), &Context{
+			Metadata:     map[string]any{"expected_count": 3},
+			WorkspaceDir: t.TempDir(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, `{"expected_count":3}`, results.Feedback)
+
+		results, err = g.Grade(context.Background(), &Context{WorkspaceDir: t.TempDir()})
+		require.NoError(t, err)
+		require.Equal(t, `{}`, results.Feedback)
+	})
+
 	t.Run("workspace dir is available as env var", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
@@ -200,5 +218,77 @@ func TestProgramGrader_ViaCreate(t *testing.T) {
 	})
 }
 
+func TestProgramGrader_Artifact(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping program grader tests on Windows")
+	}
+
+	// A fake image grader: scores PNGs by their magic bytes.
+	script := filepath.Join(t.TempDir(), "grade-image.sh")
+	require.NoError(t, os.WriteFile(script, []byte(`#!/bin/sh
+[ "$1" = "$WAZA_ARTIFACT_PATH" ] || { echo "path mismatch" >&2; exit 2; }
+if [ "$(head -c 4 "$1" | tail -c 3)" = "PNG" ]; then
+  echo '{"score": 0.75, "feedback": "looks like a PNG"}'
+else
+  echo 0.1
+fi
+`), 0o755))
+
+	workspace := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(workspace, "out"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "out", "chart.png"), []byte("\x89PNG\r\n\x1a\nrest"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "out", "notes.txt"), []byte("plain text"), 0o644))
+
+	grade := func(t *testing.T, artifact string) *models.GraderResults {
+		t.Helper()
+		g, err := NewProgramGrader("image", models.ProgramGraderParameters{Command: script, Artifact: artifact})
+		require.NoError(t, err)
+		results, err := g.Grade(context.Background(), &Context{Output: "done", WorkspaceDir: workspace})
+		require.NoError(t, err)
+		return results
+	}
+
+	t.Run("JSON score and feedback", func(t *testing.T) {
+		results := grade(t, "out/chart.png")
+		require.True(t, results.Passed)
+		require.Equal(t, 0.75, results.Score)
+		require.Equal(t, "looks like a PNG", results.Feedback)
+		require.Equal(t, "out/chart.png", results.Details["artifact"])
+	})
+
+	t.Run("bare number score", func(t *testing.T) {
+		results := grade(t, "out/notes.txt")
+		require.Equal(t, 0.1, results.Score)
+	})
+
+	t.Run("missing artifact fails", func(t *testing.T) {
+		results := grade(t, "out/missing.png")
+		require.False(t, results.Passed)
+		require.Equal(t, 0.0, results.Score)
+		require.Contains(t, results.Feedback, "Artifact out/missing.png not found")
+	})
+
+	t.Run("artifact must stay in the workspace", func(t *testing.T) {
+		_, err := NewProgramGrader("image", models.ProgramGraderParameters{Command: script, Artifact: "../escape.png"})
+		require.ErrorContains(t, err, "must be a relative path inside the workspace")
+	})
+}
+
+func TestParseArtifactScore(t *testing.T) {
+	score, feedback, ok := parseArtifactScore(`{"score": 0.5, "feedback": "meh"}`)
+	require.True(t, ok)
+	require.Equal(t, 0.5, score)
+	require.Equal(t, "meh", feedback)
+
+	score, _, ok = parseArtifactScore("87")
+	require.True(t, ok)
+	require.Equal(t, 87.0, score)
+
+	_, _, ok = parseArtifactScore("all good")
+	require.False(t, ok)
+	_, _, ok = parseArtifactScore(`{"feedback": "no score"}`)
+	require.False(t, ok)
+}
+
 // Ensure programGrader satisfies the Grader interface at compile time.
 var _ Grader = (*programGrader)(nil)
@@ -0,0 +1,247 @@
+package graders
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	copilot "github.com/github/copilot-sdk/go"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/microsoft/waza/internal/utils"
+)
+
+const (
+	defaultRubricMaxScore  = 5
+	defaultRubricThreshold = 0.6
+)
+
+// judgeFunc sends prompt to the judge model and returns its reply.
+type judgeFunc func(ctx context.Context, gradingContext *Context, model, prompt string) (string, error)
+
+type rubricGrader struct {
+	name  string
+	args  models.RubricGraderParameters
+	judge judgeFunc
+}
+
+// NewRubricGrader creates a grader that asks an LLM judge to score the output
+// against each rubric criterion and grades on the weighted total.
+func NewRubricGrader(name string, args models.RubricGraderParameters) (*rubricGrader, error) {
+	if name == "" {
+		return nil, errors.New("missing name")
+	}
+
+	if len(args.Criteria) == 0 {
+		return nil, errors.New("required field 'criteria' is missing")
+	}
+
+	seen := map[string]bool{}
+	criteria := make([]models.RubricCriterion, len(args.Criteria))
+	for i, c := range args.Criteria {
+		if c.Name == "" {
+			return nil, fmt.Errorf("criteria[%d] is missing a name", i)
+		}
+		if seen[c.Name] {
+			return nil, fmt.Errorf("criterion %q is listed more than once", c.Name)
+		}
+		seen[c.Name] = true
+		if c.Weight < 0 {
+			return nil, fmt.Errorf("criterion %q has a negative weight", c.Name)
+		}
+		if c.Weight == 0 {
+			c.Weight = 1
+		}
+		if c.MaxScore < 0 {
+			return nil, fmt.Errorf("criterion %q has a negative max_score", c.Name)
+		}
+		if c.MaxScore == 0 {
+			c.MaxScore = defaultRubricMaxScore
+		}
+		criteria[i] = c
+	}
+	args.Criteria = criteria
+
+	if args.Threshold != nil && (*args.Threshold < 0 || *args.Threshold > 1) {
+		return nil, fmt.Errorf("threshold must be between 0.0 and 1.0, got %g", *args.Threshold)
+	}
+
+	return &rubricGrader{
+		name:  name,
+		args:  args,
+		judge: copilotJudge,
+	}, nil
+}
+
+// Kind implements [Grader].
+func (r *rubricGrader) Kind() models.GraderKind {
+	return models.GraderKindRubric
+}
+
+// Name implements [Grader].
+func (r *rubricGrader) Name() string {
+	return r.name
+}
+
+// rubricScore is one criterion's score as reported by the judge.
+type rubricScore struct {
+	Name      string  `json:"name"`
+	Score     float64 `json:"score"`
+	Reasoning string  `json:"reasoning,omitempty"`
+}
+
+// RubricCriterionResult is the per-criterion breakdown stored in the grader details.
+type RubricCriterionResult struct {
+	Score     float64 `json:"score"`
+	MaxScore  int     `json:"max_score"`
+	Weight    float64 `json:"weight"`
+	Reasoning string  `json:"reasoning,omitempty"`
+}
+
+// Grade implements [Grader].
+func (r *rubricGrader) Grade(ctx context.Context, gradingContext *Context) (*models.GraderResults, error) {
+	return measureTime(func() (*models.GraderResults, error) {
+		reply, err := r.judge(ctx, gradingContext, r.args.Model, r.buildPrompt(gradingContext.Output))
+		if err != nil {
+			return nil, fmt.Errorf("rubric judge failed: %w", err)
+		}
+
+		scores, err := parseRubricScores(reply)
+		if err != nil {
+			return nil, err
+		}
+
+		breakdown := make(map[string]RubricCriterionResult, len(r.args.Criteria))
+		var weighted, totalWeight float64
+		var parts []string
+		for _, c := range r.args.Criteria {
+			s, ok := scores[c.Name]
+			if !ok {
+				return nil, fmt.Errorf("rubric judge did not score criterion %q", c.Name)
+			}
+			if s.Score < 0 || s.Score > float64(c.MaxScore) {
+				return nil, fmt.Errorf("rubric judge scored criterion %q %g, outside 0-%d", c.Name, s.Score, c.MaxScore)
+			}
+			breakdown[c.Name] = RubricCriterionResult{
+				Score:     s.Score,
+				MaxScore:  c.MaxScore,
+				Weight:    c.Weight,
+				Reasoning: s.Reasoning,
+			}
+			weighted += c.Weight * s.Score / float64(c.MaxScore)
+			totalWeight += c.Weight
+			parts = append(parts, fmt.Sprintf("%s=%g/%d", c.Name, s.Score, c.MaxScore))
+		}
+
+		score := 0.0
+		if totalWeight > 0 {
+			score = weighted / totalWeight
+		}
+
+		threshold := defaultRubricThreshold
+		if r.args.Threshold != nil {
+			threshold = *r.args.Threshold
+		}
+
+		return &models.GraderResults{
+			Name:     r.name,
+			Type:     r.Kind(),
+			Score:    score,
+			Passed:   score >= threshold,
+			Feedback: fmt.Sprintf("rubric score %.2f (threshold %.2f): %s", score, threshold, strings.Join(parts, ", ")),
+			Details: map[string]any{
+				"criteria":  breakdown,
+				"threshold": threshold,
+			},
+		}, nil
+	})
+}
+
+func (r *rubricGrader) buildPrompt(output string) string {
+	var sb strings.Builder
+	sb.WriteString("You are a judge scoring an AI agent's output against a rubric.\n\n")
+	if r.args.Prompt != "" {
+		sb.WriteString("## Instructions\n")
+		sb.WriteString(r.args.Prompt)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("## Criteria\n")
+	for _, c := range r.args.Criteria {
+		fmt.Fprintf(&sb, "- %s (0-%d)", c.Name, c.MaxScore)
+		if c.Description != "" {
+			fmt.Fprintf(&sb, ": %s", c.Description)
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "\n## Output\n```\n%s\n```\n\n", output)
+	sb.WriteString("Score the output on every criterion. Reply with only a JSON object of the form:\n")
+	sb.WriteString(`{"scores": [{"name": "<criterion>", "score": <number>, "reasoning": "<one sentence>"}]}`)
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// parseRubricScores extracts the judge's JSON reply, ignoring any prose or
+// code fences around it.
+func parseRubricScores(reply string) (map[string]rubricScore, error) {
+	start := strings.Index(reply, "{")
+	end := strings.LastIndex(reply, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("rubric judge reply contains no JSON object: %q", reply)
+	}
+
+	var parsed struct {
+		Scores []rubricScore `json:"scores"`
+	}
+	if err := json.Unmarshal([]byte(reply[start:end+1]), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse rubric judge reply: %w", err)
+	}
+
+	scores := make(map[string]rubricScore, len(parsed.Scores))
+	for _, s := range parsed.Scores {
+		scores[s.Name] = s
+	}
+	return scores, nil
+}
+
+// copilotJudge sends prompt to a fresh Copilot session and returns the reply.
+func copilotJudge(ctx context.Context, gradingContext *Context, model, prompt string) (string, error) {
+	client := copilot.NewClient(&copilot.ClientOptions{
+		Cwd:             gradingContext.WorkspaceDir,
+		AutoStart:       utils.Ptr(true),
+		AutoRestart:     utils.Ptr(true),
+		UseLoggedInUser: utils.Ptr(true),
+		LogLevel:        "error",
+	})
+
+	defer func() {
+		if err := client.Stop(); err != nil {
+			slog.ErrorContext(ctx, "error stopping client for rubric grader")
+		}
+	}()
+
+	session, err := client.CreateSession(ctx, &copilot.SessionConfig{
+		OnPermissionRequest: copilot.PermissionHandler.ApproveAll,
+		Model:               model,
+		Streaming:           true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to start up copilot session for rubric grading: %w", err)
+	}
+
+	session.On(utils.SessionToSlog)
+
+	resp, err := session.SendAndWait(ctx, copilot.MessageOptions{
+		Prompt: prompt,
+		Mode:   "enqueue",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to send prompt: %w", err)
+	}
+
+	if resp == nil || resp.Data.Content == nil {
+		return "", errors.New("judge returned no response content")
+	}
+	return *resp.Data.Content, nil
+}
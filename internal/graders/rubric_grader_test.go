@@ -0,0 +1,102 @@
+package graders
+
+import (
+	"context"
+	"testing"
+
+	"github.com/microsoft/waza/internal/models"
+	"github.com/microsoft/waza/internal/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRubricGrader(t *testing.T, args models.RubricGraderParameters, reply string) (*rubricGrader, *string) {
+	t.Helper()
+	g, err := NewRubricGrader("rubric", args)
+	require.NoError(t, err)
+
+	var gotPrompt string
+	g.judge = func(_ context.Context, _ *Context, _ string, prompt string) (string, error) {
+		gotPrompt = prompt
+		return reply, nil
+	}
+	return g, &gotPrompt
+}
+
+var threeCriteria = []models.RubricCriterion{
+	{Name: "correctness", Description: "Is the answer right?", Weight: 2},
+	{Name: "clarity"},
+	{Name: "completeness"},
+}
+
+func TestRubricGrader_Basic(t *testing.T) {
+	g, err := NewRubricGrader("rubric", models.RubricGraderParameters{Criteria: threeCriteria})
+	require.NoError(t, err)
+
+	require.Equal(t, models.GraderKindRubric, g.Kind())
+	require.Equal(t, "rubric", g.Name())
+	require.Equal(t, 5, g.args.Criteria[1].MaxScore)
+	require.Equal(t, 1.0, g.args.Criteria[1].Weight)
+}
+
+func TestRubricGrader_InvalidParams(t *testing.T) {
+	_, err := NewRubricGrader("rubric", models.RubricGraderParameters{})
+	require.ErrorContains(t, err, "criteria")
+
+	_, err = NewRubricGrader("rubric", models.RubricGraderParameters{Criteria: []models.RubricCriterion{{Name: "a"}, {Name: "a"}}})
+	require.ErrorContains(t, err, "more than once")
+
+	_, err = NewRubricGrader("rubric", models.RubricGraderParameters{Criteria: threeCriteria, Threshold: utils.Ptr(1.5)})
+	require.ErrorContains(t, err, "threshold")
+}
+
+func TestRubricGrader_WeightedScore(t *testing.T) {
+	reply := "Here is my evaluation:\n```json\n" + `{"scores": [
+		{"name": "correctness", "score": 5, "reasoning": "right"},
+		{"name": "clarity", "score": 3, "reasoning": "a bit dense"},
+		{"name": "completeness", "score": 1, "reasoning": "missed edge cases"}
+	]}` + "\n```"
+	g, prompt := newTestRubricGrader(t, models.RubricGraderParameters{Criteria: threeCriteria, Prompt: "Judge the code review."}, reply)
+
+	results, err := g.Grade(context.Background(), &Context{Output: "the agent output"})
+	require.NoError(t, err)
+
+	// (2*5/5 + 1*3/5 + 1*1/5) / 4 = 0.7
+	require.InDelta(t, 0.7, results.Score, 1e-9)
+	require.True(t, results.Passed)
+	require.Equal(t, models.GraderKindRubric, results.Type)
+	require.Contains(t, results.Feedback, "correctness=5/5")
+
+	breakdown := results.Details["criteria"].(map[string]RubricCriterionResult)
+	require.Equal(t, RubricCriterionResult{Score: 3, MaxScore: 5, Weight: 1, Reasoning: "a bit dense"}, breakdown["clarity"])
+	require.Equal(t, 2.0, breakdown["correctness"].Weight)
+
+	require.Contains(t, *prompt, "Judge the code review.")
+	require.Contains(t, *prompt, "- correctness (0-5): Is the answer right?")
+	require.Contains(t, *prompt, "the agent output")
+}
+
+func TestRubricGrader_BelowThreshold(t *testing.T) {
+	reply := `{"scores": [{"name": "correctness", "score": 4}, {"name": "clarity", "score": 4}, {"name": "completeness", "score": 4}]}`
+	g, _ := newTestRubricGrader(t, models.RubricGraderParameters{Criteria: threeCriteria, Threshold: utils.Ptr(0.9)}, reply)
+
+	results, err := g.Grade(context.Background(), &Context{})
+	require.NoError(t, err)
+	require.InDelta(t, 0.8, results.Score, 1e-9)
+	require.False(t, results.Passed)
+	require.Equal(t, 0.9, results.Details["threshold"])
+}
+
+func TestRubricGrader_BadReplies(t *testing.T) {
+	cases := map[string]string{
+		"no JSON":           "I think it's pretty good.",
+		"missing criterion": `{"scores": [{"name": "correctness", "score": 4}, {"name": "clarity", "score": 4}]}`,
+		"out of range":      `{"scores": [{"name": "correctness", "score": 7}, {"name": "clarity", "score": 4}, {"name": "completeness", "score": 4}]}`,
+	}
+	for name, reply := range cases {
+		t.Run(name, func(t *testing.T) {
+			g, _ := newTestRubricGrader(t, models.RubricGraderParameters{Criteria: threeCriteria}, reply)
+			_, err := g.Grade(context.Background(), &Context{})
+			require.Error(t, err)
+		})
+	}
+}
@@ -2,68 +2,200 @@ package graders
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/microsoft/waza/internal/models"
 )
 
+// ResultCache lets RunAllCached reuse grader results. Implementations key
+// entries by the grader config passed in, plus whatever identifies the agent
+// response being graded.
+type ResultCache interface {
+	Get(identifier string, kind models.GraderKind, params models.GraderParameters) (*models.GraderResults, bool)
+	Put(identifier string, kind models.GraderKind, params models.GraderParameters, result *models.GraderResults)
+}
+
 // RunAll runs spec-level graders and task-level validators, returning the
 // combined results. judgeModel overrides the model for prompt graders.
 func RunAll(ctx context.Context, specGraders []models.GraderConfig, tc *models.TestCase, gCtx *Context, judgeModel string, updateSnapshots bool) (map[string]models.GraderResults, error) {
-	results := make(map[string]models.GraderResults)
+	return RunAllCached(ctx, specGraders, tc, gCtx, judgeModel, updateSnapshots, nil)
+}
 
+// RunAllCached is RunAll with an optional ResultCache: graders found in rc
+// aren't run again, and fresh results are stored in it. rc may be nil.
+// Graders run one at a time unless gCtx.TaskLimiter is set.
+func RunAllCached(ctx context.Context, specGraders []models.GraderConfig, tc *models.TestCase, gCtx *Context, judgeModel string, updateSnapshots bool, rc ResultCache) (map[string]models.GraderResults, error) {
+	if err := checkGraderNames(specGraders, tc); err != nil {
+		return nil, err
+	}
+
+	jobs := make([]graderJob, 0, len(specGraders)+len(tc.Validators))
 	for _, vCfg := range specGraders {
-		params := applyDefaults(vCfg.Parameters, judgeModel, updateSnapshots)
-		grader, err := Create(vCfg.Identifier, params)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create grader %s: %w", vCfg.Identifier, err)
+		jobs = append(jobs, graderJob{vCfg.Identifier, vCfg.Kind, vCfg.Parameters, vCfg.EffectiveWeight(), vCfg.ScoreRange})
+	}
+	for _, vCfg := range tc.Validators {
+		if vCfg.Kind == "" {
+			return nil, fmt.Errorf("no kind associated with grader %s", vCfg.Identifier)
 		}
+		jobs = append(jobs, graderJob{vCfg.Identifier, vCfg.Kind, vCfg.Parameters, vCfg.EffectiveWeight(), vCfg.ScoreRange})
+	}
 
-		result, err := grader.Grade(ctx, gCtx)
+	grade := func(j graderJob) (*models.GraderResults, error) {
+		params := applyDefaults(j.params, judgeModel, updateSnapshots, gCtx.ContextDir)
+		result, err := gradeOne(ctx, j.identifier, j.kind, params, gCtx, rc)
 		if err != nil {
-			return nil, fmt.Errorf("failed to run grader %s: %w", vCfg.Identifier, err)
+			return nil, err
+		}
+		result.Weight = j.weight
+		normalizeScore(result, j.scoreRange)
+		return result, nil
+	}
+
+	graded := make([]*models.GraderResults, len(jobs))
+	if gCtx.TaskLimiter == nil {
+		for i, j := range jobs {
+			result, err := grade(j)
+			if err != nil {
+				return nil, err
+			}
+			graded[i] = result
+		}
+	} else {
+		errs := make([]error, len(jobs))
+		var wg sync.WaitGroup
+		for i, j := range jobs {
+			wg.Go(func() {
+				// The task's slot is taken first so it doesn't hold a shared
+				// slot while waiting on its own limit
+				if err := gCtx.TaskLimiter.acquire(ctx); err != nil {
+					errs[i] = fmt.Errorf("waiting to run grader %s: %w", j.identifier, err)
+					return
+				}
+				defer gCtx.TaskLimiter.release()
+				graded[i], errs[i] = grade(j)
+			})
 		}
+		wg.Wait()
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+	}
 
-		result.Weight = vCfg.EffectiveWeight()
+	results := make(map[string]models.GraderResults, len(graded))
+	for _, result := range graded {
 		results[result.Name] = *result
 	}
+	return results, nil
+}
 
-	for _, vCfg := range tc.Validators {
-		if vCfg.Kind == "" {
-			return nil, fmt.Errorf("no kind associated with grader %s", vCfg.Identifier)
-		}
+// graderJob is one eval- or task-level grader for RunAllCached to run.
+type graderJob struct {
+	identifier string
+	kind       models.GraderKind
+	params     models.GraderParameters
+	weight     float64
+	scoreRange models.ScoreRange
+}
 
-		params := applyDefaults(vCfg.Parameters, judgeModel, updateSnapshots)
-		grader, err := Create(vCfg.Identifier, params)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create grader %s: %w", vCfg.Identifier, err)
+// checkGraderNames rejects a task whose eval and task graders share a name,
+// since results are keyed by name and one would silently overwrite the other.
+// The error names both graders' locations.
+func checkGraderNames(specGraders []models.GraderConfig, tc *models.TestCase) error {
+	taskLocation := "task " + tc.TestID
+	if tc.SourcePath != "" {
+		taskLocation += " (" + tc.SourcePath + ")"
+	}
+
+	seen := make(map[string]string, len(specGraders)+len(tc.Validators))
+	check := func(name, location string) error {
+		if name == "" {
+			return nil
+		}
+		if prev, ok := seen[name]; ok {
+			return fmt.Errorf("duplicate grader name %q: %s and %s", name, prev, location)
+		}
+		seen[name] = location
+		return nil
+	}
+	for i, g := range specGraders {
+		if err := check(g.Identifier, fmt.Sprintf("eval graders[%d]", i)); err != nil {
+			return err
 		}
+	}
+	for i, v := range tc.Validators {
+		if err := check(v.Identifier, fmt.Sprintf("%s graders[%d]", taskLocation, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		result, err := grader.Grade(ctx, gCtx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to run grader %s: %w", vCfg.Identifier, err)
+// gradeOne runs a single grader, consulting rc first when it's set.
+func gradeOne(ctx context.Context, identifier string, kind models.GraderKind, params models.GraderParameters, gCtx *Context, rc ResultCache) (*models.GraderResults, error) {
+	if rc != nil {
+		if cached, ok := rc.Get(identifier, kind, params); ok {
+			return cached, nil
 		}
+	}
 
-		result.Weight = vCfg.EffectiveWeight()
-		results[result.Name] = *result
+	grader, err := Create(identifier, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create grader %s: %w", identifier, err)
 	}
 
-	return results, nil
+	if err := gCtx.Limiter.acquire(ctx); err != nil {
+		return nil, fmt.Errorf("waiting to run grader %s: %w", identifier, err)
+	}
+	result, err := grader.Grade(ctx, gCtx)
+	gCtx.Limiter.release()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run grader %s: %w", identifier, err)
+	}
+
+	if rc != nil {
+		rc.Put(identifier, kind, params, result)
+	}
+	return result, nil
 }
 
-func applyDefaults(gp models.GraderParameters, judgeModel string, updateSnapshots bool) models.GraderParameters {
+func applyDefaults(gp models.GraderParameters, judgeModel string, updateSnapshots bool, contextDir string) models.GraderParameters {
 	switch p := gp.(type) {
 	case models.PromptGraderParameters:
 		if judgeModel != "" && p.Model == "" {
 			p.Model = judgeModel
 		}
 		return p
+	case models.RubricGraderParameters:
+		if judgeModel != "" && p.Model == "" {
+			p.Model = judgeModel
+		}
+		return p
 	case models.DiffGraderParameters:
 		if updateSnapshots {
 			p.UpdateSnapshots = true
 		}
 		return p
+	case models.CorpusSimilarityGraderParameters:
+		if p.ContextDir == "" {
+			p.ContextDir = contextDir
+		}
+		return p
 	default:
 		return p
 	}
 }
+
+// normalizeScore maps result.Score from the grader's score_range onto 0–1,
+// keeping the reported value in Details["raw_score"].
+func normalizeScore(result *models.GraderResults, r models.ScoreRange) {
+	if len(r) == 0 {
+		return
+	}
+	if result.Details == nil {
+		result.Details = make(map[string]any)
+	}
+	result.Details["raw_score"] = result.Score
+	result.Score = r.Normalize(result.Score)
+}
@@ -1,16 +1,21 @@
 package graders
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/microsoft/waza/internal/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestApplyDefaults_PromptGrader(t *testing.T) {
 	t.Run("sets judge model when empty", func(t *testing.T) {
 		p := models.PromptGraderParameters{Prompt: "check"}
-		result := applyDefaults(p, "gpt-4o", false)
+		result := applyDefaults(p, "gpt-4o", false, "")
 		pp, ok := result.(models.PromptGraderParameters)
 		assert.True(t, ok)
 		assert.Equal(t, "gpt-4o", pp.Model)
@@ -19,7 +24,7 @@ func TestApplyDefaults_PromptGrader(t *testing.T) {
 
 	t.Run("preserves existing model", func(t *testing.T) {
 		p := models.PromptGraderParameters{Model: "existing"}
-		result := applyDefaults(p, "gpt-4o", false)
+		result := applyDefaults(p, "gpt-4o", false, "")
 		pp, ok := result.(models.PromptGraderParameters)
 		assert.True(t, ok)
 		assert.Equal(t, "existing", pp.Model)
@@ -27,7 +32,7 @@ func TestApplyDefaults_PromptGrader(t *testing.T) {
 
 	t.Run("no judge model", func(t *testing.T) {
 		p := models.PromptGraderParameters{Prompt: "check"}
-		result := applyDefaults(p, "", false)
+		result := applyDefaults(p, "", false, "")
 		pp, ok := result.(models.PromptGraderParameters)
 		assert.True(t, ok)
 		assert.Equal(t, "", pp.Model)
@@ -37,7 +42,7 @@ func TestApplyDefaults_PromptGrader(t *testing.T) {
 func TestApplyDefaults_DiffGrader(t *testing.T) {
 	t.Run("sets update snapshots", func(t *testing.T) {
 		p := models.DiffGraderParameters{}
-		result := applyDefaults(p, "", true)
+		result := applyDefaults(p, "", true, "")
 		dp, ok := result.(models.DiffGraderParameters)
 		assert.True(t, ok)
 		assert.True(t, dp.UpdateSnapshots)
@@ -45,17 +50,114 @@ func TestApplyDefaults_DiffGrader(t *testing.T) {
 
 	t.Run("no update snapshots", func(t *testing.T) {
 		p := models.DiffGraderParameters{}
-		result := applyDefaults(p, "", false)
+		result := applyDefaults(p, "", false, "")
 		dp, ok := result.(models.DiffGraderParameters)
 		assert.True(t, ok)
 		assert.False(t, dp.UpdateSnapshots)
 	})
 }
 
+func TestApplyDefaults_CorpusSimilarityGrader(t *testing.T) {
+	result := applyDefaults(models.CorpusSimilarityGraderParameters{CorpusDir: "refs"}, "", false, "/evals/fixtures")
+	assert.Equal(t, "/evals/fixtures", result.(models.CorpusSimilarityGraderParameters).ContextDir)
+
+	result = applyDefaults(models.CorpusSimilarityGraderParameters{CorpusDir: "refs", ContextDir: "/own"}, "", false, "/evals/fixtures")
+	assert.Equal(t, "/own", result.(models.CorpusSimilarityGraderParameters).ContextDir, "the grader's own context_dir wins")
+}
+
 func TestApplyDefaults_OtherGrader(t *testing.T) {
 	p := models.TextGraderParameters{Contains: []string{"hello"}}
-	result := applyDefaults(p, "gpt-4o", true)
+	result := applyDefaults(p, "gpt-4o", true, "")
 	tp, ok := result.(models.TextGraderParameters)
 	assert.True(t, ok)
 	assert.Equal(t, []string{"hello"}, tp.Contains)
 }
+
+func TestRunAllCached_ScoreRange(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping program grader tests on Windows")
+	}
+
+	workspace := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "report.pdf"), []byte("%PDF"), 0o644))
+
+	// An external grader scoring 0–100
+	percent := func(name, score string) models.GraderConfig {
+		return models.GraderConfig{
+			Kind:       models.GraderKindProgram,
+			Identifier: name,
+			ScoreRange: models.ScoreRange{0, 100},
+			Parameters: models.ProgramGraderParameters{Command: "sh", Args: []string{"-c", "echo " + score}, Artifact: "report.pdf"},
+		}
+	}
+	tc := &models.TestCase{
+		TestID: "t",
+		Validators: []models.ValidatorInline{{
+			Identifier: "task-level",
+			Kind:       models.GraderKindProgram,
+			ScoreRange: models.ScoreRange{1, 5},
+			Parameters: models.ProgramGraderParameters{Command: "sh", Args: []string{"-c", "echo 4"}, Artifact: "report.pdf"},
+		}},
+	}
+
+	results, err := RunAllCached(context.Background(),
+		[]models.GraderConfig{percent("in-range", "85"), percent("too-high", "150"), percent("too-low", "-5")},
+		tc, &Context{WorkspaceDir: workspace}, "", false, nil)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 0.85, results["in-range"].Score, 1e-9)
+	assert.Equal(t, 85.0, results["in-range"].Details["raw_score"])
+	assert.Equal(t, 1.0, results["too-high"].Score)
+	assert.Equal(t, 0.0, results["too-low"].Score)
+	assert.InDelta(t, 0.75, results["task-level"].Score, 1e-9)
+}
+
+func TestRunAllCached_DuplicateGraderNames(t *testing.T) {
+	text := func(contains string) models.TextGraderParameters {
+		return models.TextGraderParameters{Contains: []string{contains}}
+	}
+
+	tests := []struct {
+		name    string
+		spec    []models.GraderConfig
+		task    []models.ValidatorInline
+		wantErr string
+	}{
+		{
+			name: "within task",
+			task: []models.ValidatorInline{
+				{Identifier: "check", Kind: models.GraderKindText, Parameters: text("a")},
+				{Identifier: "check", Kind: models.GraderKindText, Parameters: text("b")},
+			},
+			wantErr: `duplicate grader name "check": task t (tasks/t.yaml) graders[0] and task t (tasks/t.yaml) graders[1]`,
+		},
+		{
+			name: "eval and task",
+			spec: []models.GraderConfig{
+				{Identifier: "other", Kind: models.GraderKindText, Parameters: text("a")},
+				{Identifier: "check", Kind: models.GraderKindText, Parameters: text("a")},
+			},
+			task: []models.ValidatorInline{
+				{Identifier: "check", Kind: models.GraderKindText, Parameters: text("b")},
+			},
+			wantErr: `duplicate grader name "check": eval graders[1] and task t (tasks/t.yaml) graders[0]`,
+		},
+		{
+			name: "within eval",
+			spec: []models.GraderConfig{
+				{Identifier: "check", Kind: models.GraderKindText, Parameters: text("a")},
+				{Identifier: "check", Kind: models.GraderKindText, Parameters: text("b")},
+			},
+			wantErr: `duplicate grader name "check": eval graders[0] and eval graders[1]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc := &models.TestCase{TestID: "t", SourcePath: "tasks/t.yaml", Validators: tt.task}
+			results, err := RunAllCached(context.Background(), tt.spec, tc, &Context{Output: "a b"}, "", false, nil)
+			assert.EqualError(t, err, tt.wantErr)
+			assert.Nil(t, results)
+		})
+	}
+}
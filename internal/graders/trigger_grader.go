@@ -32,6 +32,11 @@ type triggerHeuristicGrader struct {
 }
 
 func NewTriggerHeuristicGrader(name string, params models.TriggerHeuristicGraderParameters) (*triggerHeuristicGrader, error) {
+	if params.Source != "" && params.Source != models.TriggerSourceHeuristic {
+		return nil, fmt.Errorf("trigger grader '%s' has invalid source %q (must be %s or %s)", name, params.Source,
+			models.TriggerSourceHeuristic, models.TriggerSourceInvocation)
+	}
+
 	if strings.TrimSpace(params.SkillPath) == "" {
 		return nil, fmt.Errorf("trigger grader '%s' requires skill_path", name)
 	}
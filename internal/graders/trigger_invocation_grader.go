@@ -0,0 +1,127 @@
+package graders
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/microsoft/waza/internal/models"
+	"github.com/microsoft/waza/internal/skill"
+)
+
+// triggerInvocationGrader is the `trigger` grader with `source: invocation`. Instead of
+// scoring the prompt heuristically, it checks whether the engine actually invoked the skill.
+type triggerInvocationGrader struct {
+	name  string
+	mode  triggerHeuristicMode
+	skill string
+}
+
+// NewTriggerInvocationGrader creates a trigger grader that passes based on the run's
+// skill invocations: in positive mode the skill must have been invoked, in negative
+// mode it must not have been.
+func NewTriggerInvocationGrader(name string, params models.TriggerHeuristicGraderParameters) (*triggerInvocationGrader, error) {
+	mode := triggerHeuristicMode(strings.ToLower(strings.TrimSpace(params.Mode)))
+	switch mode {
+	case triggerModePositive, triggerModeNegative:
+	default:
+		return nil, fmt.Errorf("trigger grader '%s' has invalid mode %q (must be positive or negative)", name, params.Mode)
+	}
+
+	skillName := strings.TrimSpace(params.Skill)
+	if skillName == "" {
+		if strings.TrimSpace(params.SkillPath) == "" {
+			return nil, fmt.Errorf("trigger grader '%s' with source invocation requires skill or skill_path", name)
+		}
+		var err error
+		skillName, err = loadSkillName(resolveSkillPath(params.SkillPath))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &triggerInvocationGrader{name: name, mode: mode, skill: skillName}, nil
+}
+
+func (g *triggerInvocationGrader) Name() string            { return g.name }
+func (g *triggerInvocationGrader) Kind() models.GraderKind { return models.GraderKindTrigger }
+
+func (g *triggerInvocationGrader) Grade(ctx context.Context, gradingContext *Context) (*models.GraderResults, error) {
+	return measureTime(func() (*models.GraderResults, error) {
+		invoked := []string{}
+		triggered := false
+		for _, si := range gradingContext.SkillInvocations {
+			invoked = append(invoked, si.Name)
+			if si.Name == g.skill {
+				triggered = true
+			}
+		}
+
+		passed := triggered
+		if g.mode == triggerModeNegative {
+			passed = !triggered
+		}
+
+		var feedback string
+		switch {
+		case triggered && passed:
+			feedback = fmt.Sprintf("Skill %q was invoked", g.skill)
+		case triggered:
+			feedback = fmt.Sprintf("Skill %q was invoked unexpectedly", g.skill)
+		case passed:
+			feedback = fmt.Sprintf("Skill %q was correctly not invoked", g.skill)
+		default:
+			feedback = fmt.Sprintf("Skill %q was not invoked (invoked: %s)", g.skill, formatInvokedSkills(invoked))
+		}
+
+		score := 0.0
+		if passed {
+			score = 1.0
+		}
+
+		return &models.GraderResults{
+			Name:     g.name,
+			Type:     models.GraderKindTrigger,
+			Score:    score,
+			Passed:   passed,
+			Feedback: feedback,
+			Details: map[string]any{
+				"source":         string(models.TriggerSourceInvocation),
+				"mode":           string(g.mode),
+				"skill":          g.skill,
+				"triggered":      triggered,
+				"invoked_skills": invoked,
+			},
+		}, nil
+	})
+}
+
+func formatInvokedSkills(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// loadSkillName reads the frontmatter name from a SKILL.md file or a directory containing one.
+func loadSkillName(skillPath string) (string, error) {
+	if info, err := os.Stat(skillPath); err == nil && info.IsDir() {
+		skillPath = filepath.Join(skillPath, "SKILL.md")
+	}
+
+	data, err := os.ReadFile(skillPath)
+	if err != nil {
+		return "", fmt.Errorf("reading SKILL.md %s: %w", skillPath, err)
+	}
+
+	var sk skill.Skill
+	if err := sk.UnmarshalText(data); err != nil {
+		return "", fmt.Errorf("parsing SKILL.md %s: %w", skillPath, err)
+	}
+	if sk.Frontmatter.Name == "" {
+		return "", fmt.Errorf("SKILL.md %s has no name in its frontmatter", skillPath)
+	}
+	return sk.Frontmatter.Name, nil
+}
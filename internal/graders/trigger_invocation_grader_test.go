@@ -0,0 +1,103 @@
+package graders
+
+import (
+	"context"
+	"testing"
+
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestTriggerInvocationGrader_ConstructorValidation(t *testing.T) {
+	_, err := NewTriggerInvocationGrader("trigger", models.TriggerHeuristicGraderParameters{Mode: "positive"})
+	require.ErrorContains(t, err, "requires skill or skill_path")
+
+	_, err = NewTriggerInvocationGrader("trigger", models.TriggerHeuristicGraderParameters{Skill: "azure-deploy", Mode: "maybe"})
+	require.ErrorContains(t, err, "invalid mode")
+
+	_, err = NewTriggerHeuristicGrader("trigger", models.TriggerHeuristicGraderParameters{
+		SkillPath: writeTestSkillFile(t), Mode: "positive", Source: "vibes",
+	})
+	require.ErrorContains(t, err, `invalid source "vibes"`)
+}
+
+func TestTriggerInvocationGrader_Positive(t *testing.T) {
+	g, err := NewTriggerInvocationGrader("trigger", models.TriggerHeuristicGraderParameters{
+		Source: models.TriggerSourceInvocation,
+		Skill:  "azure-deploy",
+		Mode:   "positive",
+	})
+	require.NoError(t, err)
+	require.Equal(t, models.GraderKindTrigger, g.Kind())
+
+	result, err := g.Grade(context.Background(), &Context{
+		SkillInvocations: []execution.SkillInvocation{{Name: "code-review"}, {Name: "azure-deploy"}},
+	})
+	require.NoError(t, err)
+	require.True(t, result.Passed)
+	require.Equal(t, 1.0, result.Score)
+	require.Equal(t, `Skill "azure-deploy" was invoked`, result.Feedback)
+
+	result, err = g.Grade(context.Background(), &Context{
+		SkillInvocations: []execution.SkillInvocation{{Name: "code-review"}},
+	})
+	require.NoError(t, err)
+	require.False(t, result.Passed)
+	require.Equal(t, 0.0, result.Score)
+	require.Equal(t, `Skill "azure-deploy" was not invoked (invoked: code-review)`, result.Feedback)
+}
+
+func TestTriggerInvocationGrader_Negative(t *testing.T) {
+	g, err := NewTriggerInvocationGrader("trigger", models.TriggerHeuristicGraderParameters{
+		Source: models.TriggerSourceInvocation,
+		Skill:  "azure-deploy",
+		Mode:   "negative",
+	})
+	require.NoError(t, err)
+
+	result, err := g.Grade(context.Background(), &Context{})
+	require.NoError(t, err)
+	require.True(t, result.Passed)
+	require.Equal(t, false, result.Details["triggered"])
+
+	result, err = g.Grade(context.Background(), &Context{
+		SkillInvocations: []execution.SkillInvocation{{Name: "azure-deploy"}},
+	})
+	require.NoError(t, err)
+	require.False(t, result.Passed)
+	require.Equal(t, `Skill "azure-deploy" was invoked unexpectedly`, result.Feedback)
+}
+
+func TestTriggerInvocationGrader_SkillNameFromSkillPath(t *testing.T) {
+	g, err := NewTriggerInvocationGrader("trigger", models.TriggerHeuristicGraderParameters{
+		Source:    models.TriggerSourceInvocation,
+		SkillPath: writeTestSkillFile(t),
+		Mode:      "positive",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "azure-deploy", g.skill)
+}
+
+func TestTriggerInvocationGrader_CreateFromYAML(t *testing.T) {
+	var cfg models.GraderConfig
+	require.NoError(t, yaml.Unmarshal([]byte(`
+type: trigger
+name: deploy-triggered
+config:
+  source: invocation
+  skill: azure-deploy
+  mode: positive
+`), &cfg))
+
+	g, err := Create(cfg.Identifier, cfg.Parameters)
+	require.NoError(t, err)
+	require.IsType(t, &triggerInvocationGrader{}, g)
+
+	result, err := g.Grade(context.Background(), &Context{
+		SkillInvocations: []execution.SkillInvocation{{Name: "azure-deploy"}},
+	})
+	require.NoError(t, err)
+	require.True(t, result.Passed)
+}
@@ -4,10 +4,23 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"os"
 	"os/exec"
+	"slices"
 	"strings"
 )
 
+// Environment variables exported to every hook process, so scripts can
+// parameterize themselves without templating the command line.
+const (
+	EnvSpecDir = "WAZA_SPEC_DIR" // directory containing the eval spec
+	EnvModel   = "WAZA_MODEL"    // model under evaluation
+	EnvSkill   = "WAZA_SKILL"    // skill under evaluation
+	EnvRunID   = "WAZA_RUN_ID"   // run identifier, matching the outcome's eval_id
+	EnvName    = "WAZA_ENV"      // env_matrix environment under evaluation, if any
+)
+
 // HookConfig defines a single hook command.
 type HookConfig struct {
 	Command          string `yaml:"command" json:"command"`
@@ -27,6 +40,9 @@ type HooksConfig struct {
 // Runner executes hook commands at lifecycle points.
 type Runner struct {
 	Verbose bool
+	// Env holds extra environment variables (e.g. [EnvModel]) added to the
+	// inherited environment of each hook process.
+	Env map[string]string
 }
 
 // Execute runs all hooks for a given lifecycle point.
@@ -56,6 +72,12 @@ func (r *Runner) runHook(ctx context.Context, name string, index int, h HookConf
 	if h.WorkingDirectory != "" {
 		cmd.Dir = h.WorkingDirectory
 	}
+	if len(r.Env) > 0 {
+		cmd.Env = os.Environ()
+		for _, k := range slices.Sorted(maps.Keys(r.Env)) {
+			cmd.Env = append(cmd.Env, k+"="+r.Env[k])
+		}
+	}
 
 	output, err := cmd.CombinedOutput()
 
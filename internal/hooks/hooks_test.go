@@ -2,6 +2,8 @@ package hooks
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 	"time"
@@ -111,6 +113,39 @@ func TestExecute_ContextTimeout(t *testing.T) {
 	}
 }
 
+func TestExecute_ExportsEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell script")
+	}
+
+	dir := t.TempDir()
+	script := `printf '%s|%s|%s|%s' "$WAZA_SPEC_DIR" "$WAZA_MODEL" "$WAZA_SKILL" "$WAZA_RUN_ID" > env.txt`
+	if err := os.WriteFile(filepath.Join(dir, "echo-env.sh"), []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &Runner{Env: map[string]string{
+		EnvSpecDir: "/evals/my-skill",
+		EnvModel:   "gpt-4o",
+		EnvSkill:   "my-skill",
+		EnvRunID:   "run-123",
+	}}
+	hooks := []HookConfig{
+		{Command: "sh echo-env.sh", WorkingDirectory: dir, ErrorOnFail: true},
+	}
+	if err := r.Execute(context.Background(), "before_run", hooks); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "env.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/evals/my-skill|gpt-4o|my-skill|run-123"; string(got) != want {
+		t.Errorf("hook saw env %q, want %q", got, want)
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && searchSubstring(s, substr)
 }
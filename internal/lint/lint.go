@@ -0,0 +1,156 @@
+// Package lint flags eval specs that are valid but probably not what the
+// author meant, such as tasks that can never fail.
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/microsoft/waza/internal/cache"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/microsoft/waza/internal/orchestration"
+)
+
+// Rule names reported in Warning.Rule.
+const (
+	RuleNoGraders       = "no-graders"
+	RuleSingleTrial     = "single-trial-flaky-graders"
+	RuleUnreachableTag  = "unreachable-tag"
+	RuleJudgeModelUnset = "judge-model-unset"
+)
+
+// Warning is a single lint finding.
+type Warning struct {
+	Rule    string
+	Task    string // empty for spec-level findings
+	Message string
+	Fix     string
+}
+
+// Spec lints spec and its loaded tasks. Findings are returned in rule order,
+// then task order.
+func Spec(spec *models.BenchmarkSpec, tasks []*models.TestCase) []Warning {
+	var warnings []Warning
+	warnings = append(warnings, noGraders(spec, tasks)...)
+	warnings = append(warnings, singleTrialFlaky(spec, tasks)...)
+	warnings = append(warnings, unreachableTags(tasks)...)
+	warnings = append(warnings, judgeModelUnset(spec, tasks)...)
+	return warnings
+}
+
+// noGraders flags tasks with no spec-level or task-level graders; they pass
+// on every run.
+func noGraders(spec *models.BenchmarkSpec, tasks []*models.TestCase) []Warning {
+	if len(spec.Graders) > 0 {
+		return nil
+	}
+	var warnings []Warning
+	for _, tc := range tasks {
+		if len(tc.Validators) > 0 {
+			continue
+		}
+		warnings = append(warnings, Warning{
+			Rule:    RuleNoGraders,
+			Task:    tc.TestID,
+			Message: "task has no graders, so every run passes",
+			Fix:     "add a graders: entry to the task, or a spec-level grader that applies to every task",
+		})
+	}
+	return warnings
+}
+
+// singleTrialFlaky flags judge-based graders in a spec that only runs each
+// task once, where one noisy judgment decides the result.
+func singleTrialFlaky(spec *models.BenchmarkSpec, tasks []*models.TestCase) []Warning {
+	if spec.Config.TrialsPerTask > 1 {
+		return nil
+	}
+
+	var names []string
+	for _, g := range spec.Graders {
+		if cache.IsNonDeterministic(g.Kind) {
+			names = append(names, g.Identifier)
+		}
+	}
+	for _, tc := range tasks {
+		for _, v := range tc.Validators {
+			if cache.IsNonDeterministic(v.Kind) {
+				names = append(names, tc.TestID+"/"+v.Identifier)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	return []Warning{{
+		Rule:    RuleSingleTrial,
+		Message: fmt.Sprintf("trials_per_task is 1 but non-deterministic graders are used (%s)", strings.Join(names, ", ")),
+		Fix:     "set config.trials_per_task to 3 or more so a single noisy judgment doesn't decide a task",
+	}}
+}
+
+// unreachableTags flags tags that --tags can't select by name, e.g. ones
+// containing glob syntax or surrounding whitespace.
+func unreachableTags(tasks []*models.TestCase) []Warning {
+	var warnings []Warning
+	for _, tc := range tasks {
+		for _, tag := range tc.Tags {
+			pattern := strings.TrimSpace(tag)
+			if pattern != "" {
+				matched, err := orchestration.FilterTestCases([]*models.TestCase{{Tags: []string{tag}}}, nil, []string{pattern})
+				if err == nil && len(matched) == 1 {
+					continue
+				}
+			}
+			warnings = append(warnings, Warning{
+				Rule:    RuleUnreachableTag,
+				Task:    tc.TestID,
+				Message: fmt.Sprintf("tag %q can't be selected with --tags %q", tag, pattern),
+				Fix:     `rename the tag without surrounding whitespace or glob characters ("*", "?", "[", "\")`,
+			})
+		}
+	}
+	return warnings
+}
+
+// judgeModelUnset flags prompt and rubric graders when neither the grader nor
+// config.judge_model (or a config.judge_map entry for the spec's model) names
+// a judge, so grading silently uses the session's default model.
+func judgeModelUnset(spec *models.BenchmarkSpec, tasks []*models.TestCase) []Warning {
+	if spec.Config.JudgeModelFor(spec.Config.ModelID) != "" {
+		return nil
+	}
+
+	var warnings []Warning
+	check := func(task, name string, kind models.GraderKind, params models.GraderParameters) {
+		if !needsJudgeModel(kind, params) {
+			return
+		}
+		warnings = append(warnings, Warning{
+			Rule:    RuleJudgeModelUnset,
+			Task:    task,
+			Message: fmt.Sprintf("%s grader %q has no judge model, so it grades with the session's default model", kind, name),
+			Fix:     "set config.judge_model in the spec, config.model on the grader, or defaults.judgeModel in .waza.yaml",
+		})
+	}
+	for _, g := range spec.Graders {
+		check("", g.Identifier, g.Kind, g.Parameters)
+	}
+	for _, tc := range tasks {
+		for _, v := range tc.Validators {
+			check(tc.TestID, v.Identifier, v.Kind, v.Parameters)
+		}
+	}
+	return warnings
+}
+
+func needsJudgeModel(kind models.GraderKind, params models.GraderParameters) bool {
+	switch p := params.(type) {
+	case models.PromptGraderParameters:
+		return p.Model == ""
+	case models.RubricGraderParameters:
+		return p.Model == ""
+	}
+	return params == nil && (kind == models.GraderKindPrompt || kind == models.GraderKindRubric)
+}
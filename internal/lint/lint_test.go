@@ -0,0 +1,106 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func textGrader(name string) models.GraderConfig {
+	return models.GraderConfig{
+		Kind:       models.GraderKindText,
+		Identifier: name,
+		Parameters: models.TextGraderParameters{Contains: []string{"ok"}},
+	}
+}
+
+func rules(warnings []Warning) []string {
+	var out []string
+	for _, w := range warnings {
+		out = append(out, w.Rule)
+	}
+	return out
+}
+
+func TestSpec_Clean(t *testing.T) {
+	spec := &models.BenchmarkSpec{
+		Config:  models.Config{TrialsPerTask: 3, JudgeModel: "gpt-4o"},
+		Graders: []models.GraderConfig{textGrader("ok"), {Kind: models.GraderKindPrompt, Identifier: "judge", Parameters: models.PromptGraderParameters{Prompt: "ok?"}}},
+	}
+	tasks := []*models.TestCase{{TestID: "t1", Tags: []string{"smoke", "area:sql"}}}
+
+	assert.Empty(t, Spec(spec, tasks))
+}
+
+func TestSpec_NoGraders(t *testing.T) {
+	spec := &models.BenchmarkSpec{Config: models.Config{TrialsPerTask: 1}}
+	tasks := []*models.TestCase{
+		{TestID: "bare"},
+		{TestID: "graded", Validators: []models.ValidatorInline{{Identifier: "v", Kind: models.GraderKindText, Parameters: models.TextGraderParameters{Contains: []string{"x"}}}}},
+	}
+
+	warnings := Spec(spec, tasks)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, RuleNoGraders, warnings[0].Rule)
+	assert.Equal(t, "bare", warnings[0].Task)
+	assert.NotEmpty(t, warnings[0].Fix)
+
+	spec.Graders = []models.GraderConfig{textGrader("global")}
+	assert.Empty(t, Spec(spec, tasks), "spec-level graders apply to every task")
+}
+
+func TestSpec_SingleTrialFlakyGraders(t *testing.T) {
+	spec := &models.BenchmarkSpec{
+		Config:  models.Config{TrialsPerTask: 1, JudgeModel: "gpt-4o"},
+		Graders: []models.GraderConfig{textGrader("ok"), {Kind: models.GraderKindBehavior, Identifier: "limits", Parameters: models.BehaviorGraderParameters{}}},
+	}
+	tasks := []*models.TestCase{{TestID: "t1", Validators: []models.ValidatorInline{{Identifier: "style", Kind: models.GraderKindPrompt, Parameters: models.PromptGraderParameters{Model: "gpt-4o"}}}}}
+
+	warnings := Spec(spec, tasks)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, RuleSingleTrial, warnings[0].Rule)
+	assert.Contains(t, warnings[0].Message, "limits, t1/style")
+	assert.Contains(t, warnings[0].Fix, "trials_per_task")
+
+	spec.Config.TrialsPerTask = 3
+	assert.Empty(t, Spec(spec, tasks))
+}
+
+func TestSpec_UnreachableTags(t *testing.T) {
+	spec := &models.BenchmarkSpec{Config: models.Config{TrialsPerTask: 1}, Graders: []models.GraderConfig{textGrader("ok")}}
+	tasks := []*models.TestCase{
+		{TestID: "t1", Tags: []string{"smoke", "[wip]", "area:*"}},
+		{TestID: "t2", Tags: []string{" slow ", ""}},
+	}
+
+	warnings := Spec(spec, tasks)
+	assert.Equal(t, []string{RuleUnreachableTag, RuleUnreachableTag, RuleUnreachableTag}, rules(warnings))
+	assert.Equal(t, "t1", warnings[0].Task)
+	assert.Contains(t, warnings[0].Message, `"[wip]"`)
+	assert.Equal(t, "t2", warnings[1].Task)
+	assert.Contains(t, warnings[1].Message, `" slow "`)
+	assert.Equal(t, "t2", warnings[2].Task)
+}
+
+func TestSpec_JudgeModelUnset(t *testing.T) {
+	spec := &models.BenchmarkSpec{
+		Config: models.Config{TrialsPerTask: 3},
+		Graders: []models.GraderConfig{
+			{Kind: models.GraderKindPrompt, Identifier: "judge", Parameters: models.PromptGraderParameters{Prompt: "ok?"}},
+			{Kind: models.GraderKindPrompt, Identifier: "pinned", Parameters: models.PromptGraderParameters{Prompt: "ok?", Model: "gpt-4o"}},
+		},
+	}
+	tasks := []*models.TestCase{{TestID: "t1", Validators: []models.ValidatorInline{{Identifier: "quality", Kind: models.GraderKindRubric, Parameters: models.RubricGraderParameters{}}}}}
+
+	warnings := Spec(spec, tasks)
+	assert.Equal(t, []string{RuleJudgeModelUnset, RuleJudgeModelUnset}, rules(warnings))
+	assert.Empty(t, warnings[0].Task)
+	assert.Contains(t, warnings[0].Message, `"judge"`)
+	assert.Equal(t, "t1", warnings[1].Task)
+	assert.Contains(t, warnings[1].Fix, "judge_model")
+
+	spec.Config.JudgeModel = "gpt-4o"
+	assert.Empty(t, Spec(spec, tasks))
+}
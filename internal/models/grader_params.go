@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"gopkg.in/yaml.v3"
 )
@@ -79,6 +80,15 @@ type BehaviorGraderParameters struct {
 
 func (BehaviorGraderParameters) isGraderParameters() {}
 
+// EfficiencyGraderParameters bounds how much work the agent may do. A zero
+// limit is not checked.
+type EfficiencyGraderParameters struct {
+	MaxToolCalls int `yaml:"max_tool_calls,omitempty" json:"max_tool_calls,omitempty"`
+	MaxTurns     int `yaml:"max_turns,omitempty" json:"max_turns,omitempty"`
+}
+
+func (EfficiencyGraderParameters) isGraderParameters() {}
+
 type ActionSequenceGraderParameters struct {
 	MatchingMode    ActionSequenceMatchingMode `yaml:"matching_mode,omitempty" json:"matching_mode,omitempty"`
 	ExpectedActions []string                   `yaml:"expected_actions,omitempty" json:"expected_actions,omitempty"`
@@ -180,19 +190,115 @@ type ProgramGraderParameters struct {
 	Command string   `yaml:"command,omitempty" json:"command,omitempty"`
 	Args    []string `yaml:"args,omitempty" json:"args,omitempty"`
 	Timeout int      `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	// Artifact is a workspace-relative file (e.g. an image or PDF the skill
+	// produced) to grade. Its absolute path is appended to Args and exported as
+	// WAZA_ARTIFACT_PATH, and the program reports a score on stdout.
+	Artifact string `yaml:"artifact,omitempty" json:"artifact,omitempty"`
 }
 
 func (ProgramGraderParameters) isGraderParameters() {}
 
-// TriggerHeuristicGraderParameters holds the arguments for creating a trigger heuristic grader.
+// TriggerHeuristicGraderParameters holds the arguments for creating a trigger grader.
 type TriggerHeuristicGraderParameters struct {
 	SkillPath string   `yaml:"skill_path" json:"skill_path"`
 	Mode      string   `yaml:"mode" json:"mode"`
 	Threshold *float64 `yaml:"threshold,omitempty" json:"threshold,omitempty"`
+	// Source picks the trigger signal: "heuristic" (default) scores the prompt against
+	// SKILL.md keywords; "invocation" checks whether the engine actually invoked the skill.
+	Source TriggerSource `yaml:"source,omitempty" json:"source,omitempty"`
+	// Skill is the skill name to look for with source: invocation. Defaults to the
+	// name in SkillPath's frontmatter.
+	Skill string `yaml:"skill,omitempty" json:"skill,omitempty"`
 }
 
+// TriggerSource selects the signal a trigger grader uses.
+type TriggerSource string
+
+const (
+	TriggerSourceHeuristic  TriggerSource = "heuristic"
+	TriggerSourceInvocation TriggerSource = "invocation"
+)
+
 func (TriggerHeuristicGraderParameters) isGraderParameters() {}
 
+// NoSecretsGraderParameters holds the arguments for creating a no_secrets grader.
+type NoSecretsGraderParameters struct {
+	// Patterns lists additional regexes to treat as secrets, on top of the built-in set
+	// (AWS keys, bearer tokens, private key headers, GitHub tokens).
+	Patterns []string `yaml:"patterns,omitempty" json:"patterns,omitempty"`
+
+	// ScanTranscript also scans transcript events (messages, tool arguments and results),
+	// not just the final output.
+	ScanTranscript bool `yaml:"scan_transcript,omitempty" json:"scan_transcript,omitempty"`
+}
+
+func (NoSecretsGraderParameters) isGraderParameters() {}
+
+// RubricGraderParameters holds the arguments for creating a rubric grader.
+type RubricGraderParameters struct {
+	// Criteria lists what the judge scores the output on, e.g. correctness, clarity
+	// and completeness.
+	Criteria []RubricCriterion `yaml:"criteria,omitempty" json:"criteria,omitempty"`
+
+	// Prompt is optional task-specific guidance included ahead of the criteria.
+	Prompt string `yaml:"prompt,omitempty" json:"prompt,omitempty"`
+
+	// Model is the judge model. Defaults to --judge-model, then the session default.
+	Model string `yaml:"model,omitempty" json:"model,omitempty"`
+
+	// Threshold is the minimum weighted score (0.0-1.0) needed to pass. Defaults to 0.6.
+	Threshold *float64 `yaml:"threshold,omitempty" json:"threshold,omitempty"`
+}
+
+// RubricCriterion is a single scored dimension of a rubric.
+type RubricCriterion struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+
+	// Weight is the criterion's share of the total score. Defaults to 1.
+	Weight float64 `yaml:"weight,omitempty" json:"weight,omitempty"`
+
+	// MaxScore is the top of the criterion's 0-N scale. Defaults to 5.
+	MaxScore int `yaml:"max_score,omitempty" json:"max_score,omitempty"`
+}
+
+func (RubricGraderParameters) isGraderParameters() {}
+
+// CorpusSimilarityGraderParameters holds the arguments for creating a corpus_similarity grader.
+type CorpusSimilarityGraderParameters struct {
+	// CorpusDir is the directory of approved reference outputs. Every regular file in
+	// it (recursively, skipping hidden files) is one reference.
+	CorpusDir string `yaml:"corpus_dir" json:"corpus_dir"`
+
+	// ContextDir, when set, is the directory a relative CorpusDir is resolved
+	// against, as the diff grader resolves its snapshots.
+	ContextDir string `yaml:"context_dir,omitempty" json:"context_dir,omitempty"`
+
+	// Method is how similarity is measured: "ngram" (default) for the Jaccard overlap of
+	// word n-grams, or "cosine" for the cosine similarity of word frequencies.
+	Method string `yaml:"method,omitempty" json:"method,omitempty"`
+
+	// N is the n-gram size for the ngram method. Defaults to 3.
+	N int `yaml:"n,omitempty" json:"n,omitempty"`
+
+	// Aggregate combines the per-reference similarities: "max" (default), the closest
+	// reference, or "mean" across the whole corpus.
+	Aggregate string `yaml:"aggregate,omitempty" json:"aggregate,omitempty"`
+
+	// Threshold is the minimum aggregate similarity (0.0-1.0) needed to pass. Defaults to 0.5.
+	Threshold *float64 `yaml:"threshold,omitempty" json:"threshold,omitempty"`
+}
+
+func (CorpusSimilarityGraderParameters) isGraderParameters() {}
+
+// CorpusPath returns CorpusDir resolved against ContextDir.
+func (p CorpusSimilarityGraderParameters) CorpusPath() string {
+	if p.ContextDir != "" && !filepath.IsAbs(p.CorpusDir) {
+		return filepath.Join(p.ContextDir, p.CorpusDir)
+	}
+	return p.CorpusDir
+}
+
 func decodeGraderParameters(kind GraderKind, configNode *yaml.Node) (GraderParameters, error) {
 	switch kind {
 	case GraderKindInlineScript:
@@ -203,6 +309,8 @@ func decodeGraderParameters(kind GraderKind, configNode *yaml.Node) (GraderParam
 		return decodeYAMLNode[FileGraderParameters](configNode)
 	case GraderKindBehavior:
 		return decodeYAMLNode[BehaviorGraderParameters](configNode)
+	case GraderKindEfficiency:
+		return decodeYAMLNode[EfficiencyGraderParameters](configNode)
 	case GraderKindActionSequence:
 		return decodeYAMLNode[ActionSequenceGraderParameters](configNode)
 	case GraderKindSkillInvocation:
@@ -219,6 +327,12 @@ func decodeGraderParameters(kind GraderKind, configNode *yaml.Node) (GraderParam
 		return decodeYAMLNode[ProgramGraderParameters](configNode)
 	case GraderKindTrigger:
 		return decodeYAMLNode[TriggerHeuristicGraderParameters](configNode)
+	case GraderKindNoSecrets:
+		return decodeYAMLNode[NoSecretsGraderParameters](configNode)
+	case GraderKindRubric:
+		return decodeYAMLNode[RubricGraderParameters](configNode)
+	case GraderKindCorpusSimilarity:
+		return decodeYAMLNode[CorpusSimilarityGraderParameters](configNode)
 	default:
 		return decodeYAMLNode[GenericGraderParameters](configNode)
 	}
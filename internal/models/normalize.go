@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// Normalized returns a copy of o without the fields that change from run to
+// run even when nothing meaningful did: the run ID, timestamps, durations,
+// timings and session IDs are zeroed, so results can be committed and diffed.
+// o is not modified.
+func (o *EvaluationOutcome) Normalized() *EvaluationOutcome {
+	if o == nil {
+		return nil
+	}
+	c := *o
+	c.RunID = ""
+	c.Timestamp = time.Time{}
+	c.Digest.DurationMs = 0
+	if o.Provenance != nil {
+		p := *o.Provenance
+		p.Timestamp = time.Time{}
+		c.Provenance = &p
+	}
+
+	c.TestOutcomes = normalizedTestOutcomes(o.TestOutcomes)
+	if o.TriggerResults != nil {
+		c.TriggerResults = make([]TriggerResult, len(o.TriggerResults))
+		for i, tr := range o.TriggerResults {
+			tr.SessionID = ""
+			c.TriggerResults[i] = tr
+		}
+	}
+	c.BaselineOutcome = o.BaselineOutcome.Normalized()
+	return &c
+}
+
+func normalizedTestOutcomes(outcomes []TestOutcome) []TestOutcome {
+	if outcomes == nil {
+		return nil
+	}
+	normalized := make([]TestOutcome, len(outcomes))
+	for i, to := range outcomes {
+		to.StartedAt, to.FinishedAt = time.Time{}, time.Time{}
+		if to.Stats != nil {
+			stats := *to.Stats
+			stats.AvgDurationMs = 0
+			to.Stats = &stats
+		}
+		var runs []RunResult
+		if to.Runs != nil {
+			runs = make([]RunResult, len(to.Runs))
+		}
+		for j, run := range to.Runs {
+			run.DurationMs = 0
+			run.Timing = nil
+			run.StartedAt, run.FinishedAt = time.Time{}, time.Time{}
+			run.SessionDigest.SessionID = ""
+			if run.Validations != nil {
+				validations := make(map[string]GraderResults, len(run.Validations))
+				for name, g := range run.Validations {
+					g.DurationMs = 0
+					validations[name] = g
+				}
+				run.Validations = validations
+			}
+			runs[j] = run
+		}
+		to.Runs = runs
+		normalized[i] = to
+	}
+	return normalized
+}
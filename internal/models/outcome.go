@@ -26,18 +26,22 @@ type GraderKind string
 const (
 	// NOTE: if you add more, make sure you add them to [AllGraderKinds], below.
 
-	GraderKindInlineScript    GraderKind = "code"
-	GraderKindPrompt          GraderKind = "prompt"
-	GraderKindText            GraderKind = "text"
-	GraderKindFile            GraderKind = "file"
-	GraderKindJSONSchema      GraderKind = "json_schema"
-	GraderKindProgram         GraderKind = "program"
-	GraderKindBehavior        GraderKind = "behavior"
-	GraderKindActionSequence  GraderKind = "action_sequence"
-	GraderKindSkillInvocation GraderKind = "skill_invocation"
-	GraderKindTrigger         GraderKind = "trigger"
-	GraderKindDiff            GraderKind = "diff"
-	GraderKindToolConstraint  GraderKind = "tool_constraint"
+	GraderKindInlineScript     GraderKind = "code"
+	GraderKindPrompt           GraderKind = "prompt"
+	GraderKindText             GraderKind = "text"
+	GraderKindFile             GraderKind = "file"
+	GraderKindJSONSchema       GraderKind = "json_schema"
+	GraderKindProgram          GraderKind = "program"
+	GraderKindBehavior         GraderKind = "behavior"
+	GraderKindActionSequence   GraderKind = "action_sequence"
+	GraderKindSkillInvocation  GraderKind = "skill_invocation"
+	GraderKindTrigger          GraderKind = "trigger"
+	GraderKindDiff             GraderKind = "diff"
+	GraderKindToolConstraint   GraderKind = "tool_constraint"
+	GraderKindNoSecrets        GraderKind = "no_secrets"
+	GraderKindRubric           GraderKind = "rubric"
+	GraderKindEfficiency       GraderKind = "efficiency"
+	GraderKindCorpusSimilarity GraderKind = "corpus_similarity"
 )
 
 func AllGraderKinds() []string {
@@ -54,6 +58,10 @@ func AllGraderKinds() []string {
 		string(GraderKindTrigger),
 		string(GraderKindDiff),
 		string(GraderKindToolConstraint),
+		string(GraderKindNoSecrets),
+		string(GraderKindRubric),
+		string(GraderKindEfficiency),
+		string(GraderKindCorpusSimilarity),
 	}
 
 	sort.Strings(names)
@@ -75,14 +83,68 @@ type EvaluationOutcome struct {
 	Metadata        map[string]any           `json:"metadata,omitempty"`
 	IsBaseline      bool                     `json:"is_baseline,omitempty"`
 	BaselineOutcome *EvaluationOutcome       `json:"baseline_outcome,omitempty"`
+	FixtureManifest FixtureManifest          `json:"fixture_manifest,omitempty"`
+	// Warnings lists non-fatal problems hit during the run, such as resource
+	// load, cache write or hook failures.
+	Warnings []string `json:"warnings,omitempty"`
+	// Provenance records the toolchain that produced the outcome.
+	Provenance *Provenance `json:"provenance,omitempty"`
+	// RunTags are the labels given with --run-tag (e.g. nightly, pr-1234),
+	// used to filter stored results.
+	RunTags []string `json:"run_tags,omitempty"`
 }
 
+// Provenance identifies the waza build, engine and platform behind an outcome
+// so a result can be traced back to the exact toolchain that produced it.
+type Provenance struct {
+	WazaVersion   string    `json:"waza_version"`
+	EngineType    string    `json:"engine_type"`
+	EngineVersion string    `json:"engine_version,omitempty"`
+	ModelID       string    `json:"model_id"`
+	OS            string    `json:"os"`
+	Arch          string    `json:"arch"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// FixtureManifest maps fixture files, by their path relative to the eval spec's
+// directory, to the hex-encoded SHA-256 of their contents.
+type FixtureManifest map[string]string
+
 type OutcomeSetup struct {
-	RunsPerTest int    `json:"runs_per_test"`
-	ModelID     string `json:"model_id"`
-	EngineType  string `json:"engine_type"`
-	TimeoutSec  int    `json:"timeout_sec"`
-	JudgeModel  string `json:"judge_model,omitempty"`
+	RunsPerTest  int        `json:"runs_per_test"`
+	ModelID      string     `json:"model_id"`
+	EngineType   string     `json:"engine_type"`
+	TimeoutSec   int        `json:"timeout_sec"`
+	JudgeModel   string     `json:"judge_model,omitempty"`
+	WeightMode   WeightMode `json:"weight_mode,omitempty"`
+	SlowTaskMs   int64      `json:"slow_task_ms,omitempty"`
+	TrimOutliers float64    `json:"trim_outliers,omitempty"`
+	MinRunsForCI int        `json:"min_runs_for_ci,omitempty"`
+	// WeightByTrials records that the aggregate score weights tasks by run count.
+	WeightByTrials bool `json:"weight_by_trials,omitempty"`
+	// AggregateMethod records how task scores were combined into the aggregate score.
+	AggregateMethod AggregateMethod `json:"aggregate_method,omitempty"`
+	// TriggerWeight records the weight the trigger F1 score carried in the
+	// aggregate score, when it was folded in.
+	TriggerWeight float64 `json:"trigger_weight,omitempty"`
+	// Environment names the env_matrix environment the run used, if any.
+	Environment string `json:"environment,omitempty"`
+	// PassRateThreshold is the config's pass_rate_threshold, if set.
+	PassRateThreshold float64 `json:"pass_rate_threshold,omitempty"`
+}
+
+// PassRateTarget is PassRateThreshold, or 1 (every task passes) when unset.
+func (s OutcomeSetup) PassRateTarget() float64 {
+	if s.PassRateThreshold == 0 {
+		return 1
+	}
+	return s.PassRateThreshold
+}
+
+// PassRateGreen reports whether the outcome's pass rate meets its
+// Setup.PassRateTarget.
+func (o *EvaluationOutcome) PassRateGreen() bool {
+	return o.Digest.SuccessRate >= o.Setup.PassRateTarget()
 }
 
 type OutcomeDigest struct {
@@ -91,6 +153,8 @@ type OutcomeDigest struct {
 	Failed         int          `json:"failed"`
 	Errors         int          `json:"errors"`
 	Skipped        int          `json:"skipped"`
+	XFailed        int          `json:"xfailed,omitempty"` // xfail tasks that failed as expected; not in Failed or Errors
+	XPassed        int          `json:"xpassed,omitempty"` // xfail tasks that passed unexpectedly; also in Succeeded
 	SuccessRate    float64      `json:"success_rate"`
 	AggregateScore float64      `json:"aggregate_score"`
 	WeightedScore  float64      `json:"weighted_score"`
@@ -99,10 +163,15 @@ type OutcomeDigest struct {
 	StdDev         float64      `json:"std_dev"`
 	DurationMs     int64        `json:"duration_ms"`
 	Groups         []GroupStats `json:"groups,omitempty"`
+	Tags           []GroupStats `json:"tags,omitempty"`
 	Usage          *UsageStats  `json:"usage,omitempty"`
 
 	// Statistical summary populated when trials_per_task > 1
 	Statistics *StatisticalSummary `json:"statistics,omitempty"`
+
+	// Stability averages StabilityScore over tasks with more than one trial.
+	// Nil when no task ran more than once.
+	Stability *float64 `json:"stability,omitempty"`
 }
 
 type MeasureResult struct {
@@ -119,10 +188,30 @@ type TestOutcome struct {
 	TestID      string             `json:"test_id"`
 	DisplayName string             `json:"display_name"`
 	Group       string             `json:"group,omitempty"`
+	Tags        []string           `json:"tags,omitempty"`
 	Status      Status             `json:"status"`
 	Runs        []RunResult        `json:"runs"`
 	Stats       *TestStats         `json:"stats,omitempty"`
 	SkillImpact *SkillImpactMetric `json:"skill_impact,omitempty"`
+	// XFail marks a task expected to fail (the task's xfail field), with its reason.
+	XFail       bool   `json:"xfail,omitempty"`
+	XFailReason string `json:"xfail_reason,omitempty"`
+	// StartedAt and FinishedAt span the task's runs, from the first run's
+	// start to the last run's finish. They're zero when no run executed.
+	StartedAt  time.Time `json:"started_at,omitzero"`
+	FinishedAt time.Time `json:"finished_at,omitzero"`
+}
+
+// XFailed reports whether an xfail task failed or errored as expected. Such
+// tasks count toward OutcomeDigest.XFailed instead of Failed or Errors.
+func (to *TestOutcome) XFailed() bool {
+	return to.XFail && (to.Status == StatusFailed || to.Status == StatusError)
+}
+
+// XPassed reports whether an xfail task passed unexpectedly, meaning its xfail
+// mark is probably stale.
+func (to *TestOutcome) XPassed() bool {
+	return to.XFail && to.Status == StatusPassed
 }
 
 // GroupStats holds aggregate statistics for a group of test outcomes.
@@ -154,6 +243,37 @@ type RunResult struct {
 	FinalOutput      string                   `json:"final_output"`
 	ErrorMsg         string                   `json:"error_msg,omitempty"`
 	SkillInvocations []SkillInvocation        `json:"skill_invocations,omitempty"`
+	// OutputTruncated is set when FinalOutput was cut to config.max_output_bytes;
+	// OriginalOutputBytes is the size before truncation.
+	OutputTruncated     bool `json:"output_truncated,omitempty"`
+	OriginalOutputBytes int  `json:"original_output_bytes,omitempty"`
+	// Timing breaks the run's wall-clock time into engine execution and grading.
+	Timing *RunTiming `json:"timing,omitempty"`
+	// ArtifactsDir is where the run's workspace was copied by --capture-artifacts.
+	ArtifactsDir string `json:"artifacts_dir,omitempty"`
+	// ToolViolations lists tools the agent called outside allowed_tools/denied_tools,
+	// in first-call order.
+	ToolViolations []string `json:"tool_violations,omitempty"`
+	// StartedAt and FinishedAt are the wall-clock bounds of the run's final
+	// attempt; FinishedAt - StartedAt matches Timing.TotalMs.
+	StartedAt  time.Time `json:"started_at,omitzero"`
+	FinishedAt time.Time `json:"finished_at,omitzero"`
+}
+
+// RunTiming records where a run spent its time. EngineMs + GradingMs is
+// approximately TotalMs; the remainder is runner bookkeeping.
+type RunTiming struct {
+	TotalMs   int64 `json:"total_ms"`
+	EngineMs  int64 `json:"engine_ms"`
+	GradingMs int64 `json:"grading_ms"`
+	// Graders lists each grader's duration in execution order.
+	Graders []GraderTiming `json:"graders,omitempty"`
+}
+
+type GraderTiming struct {
+	Name       string     `json:"name"`
+	Type       GraderKind `json:"type"`
+	DurationMs int64      `json:"duration_ms"`
 }
 
 type GraderResults struct {
@@ -243,6 +363,7 @@ type TestStats struct {
 	FailedRuns       int     `json:"failed_runs"`
 	ErrorRuns        int     `json:"error_runs"`
 	TotalRuns        int     `json:"total_runs"`
+	TrimmedRuns      int     `json:"trimmed_runs,omitempty"` // runs left out of the score stats by trim_outliers
 	AvgScore         float64 `json:"avg_score"`
 	AvgWeightedScore float64 `json:"avg_weighted_score"`
 	MinScore         float64 `json:"min_score"`
@@ -254,6 +375,10 @@ type TestStats struct {
 	Flaky            bool    `json:"flaky"`
 	AvgDurationMs    int64   `json:"avg_duration_ms"`
 
+	// StabilityScore is 0.0 to 1.0: how consistent pass/fail and scores were
+	// across trials. 1.0 means every trial agreed; single-trial tasks are 1.0.
+	StabilityScore float64 `json:"stability_score"`
+
 	// Bootstrap confidence interval over weighted scores (populated when trials > 1)
 	BootstrapCI   *statistics.ConfidenceInterval `json:"bootstrap_ci,omitempty"`
 	IsSignificant *bool                          `json:"is_significant,omitempty"`
@@ -295,9 +420,14 @@ func (r *RunResult) ComputeRunScore() float64 {
 	return total / float64(len(r.Validations))
 }
 
-// ComputeWeightedRunScore calculates the weighted composite score (0.0–1.0)
-// using each grader's Weight field. If all weights are zero, falls back to simple average.
-func (r *RunResult) ComputeWeightedRunScore() float64 {
+// ComputeWeightedRunScore calculates the weighted composite score using each
+// grader's Weight field (weights <= 0 count as 1.0):
+//
+//	normalized: Σ(scoreᵢ × weightᵢ) / Σ weightᵢ   (0.0–1.0)
+//	raw:        Σ(scoreᵢ × weightᵢ)
+//
+// An empty mode is treated as normalized.
+func (r *RunResult) ComputeWeightedRunScore(mode WeightMode) float64 {
 	if len(r.Validations) == 0 {
 		return 0.0
 	}
@@ -311,8 +441,8 @@ func (r *RunResult) ComputeWeightedRunScore() float64 {
 		weightedSum += v.Score * w
 		totalWeight += w
 	}
-	if totalWeight == 0 {
-		return 0.0
+	if mode == WeightModeRaw {
+		return weightedSum
 	}
 	return weightedSum / totalWeight
 }
@@ -3,6 +3,7 @@ package models
 import (
 	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -95,12 +96,25 @@ func TestComputeWeightedRunScore(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := tt.run.ComputeWeightedRunScore()
+			got := tt.run.ComputeWeightedRunScore(WeightModeNormalized)
 			require.InDelta(t, tt.want, got, 1e-9)
 		})
 	}
 }
 
+func TestComputeWeightedRunScore_WeightMode(t *testing.T) {
+	run := RunResult{Validations: map[string]GraderResults{
+		"light": {Score: 1.0, Weight: 1.0},
+		"heavy": {Score: 0.5, Weight: 3.0},
+	}}
+
+	// (1.0×1 + 0.5×3) / (1+3)
+	require.InDelta(t, 0.625, run.ComputeWeightedRunScore(WeightModeNormalized), 1e-9)
+	require.InDelta(t, 0.625, run.ComputeWeightedRunScore(""), 1e-9, "empty mode is normalized")
+	// 1.0×1 + 0.5×3
+	require.InDelta(t, 2.5, run.ComputeWeightedRunScore(WeightModeRaw), 1e-9)
+}
+
 func TestAllValidationsPassed(t *testing.T) {
 	tests := []struct {
 		name string
@@ -184,3 +198,78 @@ func TestAggregateUsageStats_AllNil(t *testing.T) {
 func TestAggregateUsageStats_Empty(t *testing.T) {
 	require.Nil(t, AggregateUsageStats(nil))
 }
+
+func TestEvaluationOutcome_PassRateGreen(t *testing.T) {
+	outcome := func(rate, threshold float64) *EvaluationOutcome {
+		return &EvaluationOutcome{
+			Setup:  OutcomeSetup{PassRateThreshold: threshold},
+			Digest: OutcomeDigest{SuccessRate: rate},
+		}
+	}
+	require.True(t, outcome(0.95, 0.9).PassRateGreen())
+	require.True(t, outcome(0.9, 0.9).PassRateGreen(), "meeting the threshold exactly is green")
+	require.False(t, outcome(0.8, 0.9).PassRateGreen())
+	require.False(t, outcome(0.95, 0).PassRateGreen(), "without a threshold every task must pass")
+	require.True(t, outcome(1, 0).PassRateGreen())
+	require.Equal(t, 1.0, OutcomeSetup{}.PassRateTarget())
+}
+
+func TestEvaluationOutcome_Normalized(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	orig := &EvaluationOutcome{
+		RunID:      "run-123",
+		Timestamp:  now,
+		Digest:     OutcomeDigest{TotalTests: 1, Succeeded: 1, SuccessRate: 1, DurationMs: 1500},
+		Provenance: &Provenance{Timestamp: now},
+		TestOutcomes: []TestOutcome{{
+			TestID:     "t1",
+			Status:     StatusPassed,
+			StartedAt:  now,
+			FinishedAt: now.Add(time.Second),
+			Stats:      &TestStats{PassRate: 1, AvgDurationMs: 900},
+			Runs: []RunResult{{
+				Status:        StatusPassed,
+				DurationMs:    900,
+				FinalOutput:   "done",
+				StartedAt:     now,
+				FinishedAt:    now.Add(time.Second),
+				Timing:        &RunTiming{TotalMs: 900},
+				SessionDigest: SessionDigest{SessionID: "sess-1", ToolCallCount: 2},
+				Validations: map[string]GraderResults{
+					"check": {Name: "check", Score: 1, Passed: true, DurationMs: 12},
+				},
+			}},
+		}},
+	}
+
+	n := orig.Normalized()
+	require.Empty(t, n.RunID)
+	require.True(t, n.Timestamp.IsZero())
+	require.Zero(t, n.Digest.DurationMs)
+	require.Equal(t, 1.0, n.Digest.SuccessRate)
+	require.True(t, n.Provenance.Timestamp.IsZero())
+
+	to := n.TestOutcomes[0]
+	require.True(t, to.StartedAt.IsZero())
+	require.True(t, to.FinishedAt.IsZero())
+	require.Zero(t, to.Stats.AvgDurationMs)
+	require.Equal(t, 1.0, to.Stats.PassRate)
+	run := to.Runs[0]
+	require.Zero(t, run.DurationMs)
+	require.Nil(t, run.Timing)
+	require.True(t, run.StartedAt.IsZero())
+	require.Empty(t, run.SessionDigest.SessionID)
+	require.Equal(t, 2, run.SessionDigest.ToolCallCount)
+	require.Equal(t, "done", run.FinalOutput)
+	require.Zero(t, run.Validations["check"].DurationMs)
+	require.True(t, run.Validations["check"].Passed)
+
+	// The original is untouched
+	require.Equal(t, "run-123", orig.RunID)
+	require.Equal(t, now, orig.Provenance.Timestamp)
+	require.Equal(t, int64(900), orig.TestOutcomes[0].Stats.AvgDurationMs)
+	require.Equal(t, "sess-1", orig.TestOutcomes[0].Runs[0].SessionDigest.SessionID)
+	require.Equal(t, int64(12), orig.TestOutcomes[0].Runs[0].Validations["check"].DurationMs)
+
+	require.Nil(t, (*EvaluationOutcome)(nil).Normalized())
+}
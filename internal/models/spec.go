@@ -2,10 +2,14 @@ package models
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/microsoft/waza/internal/hooks"
+	"github.com/microsoft/waza/internal/utils"
 	"gopkg.in/yaml.v3"
 )
 
@@ -23,6 +27,28 @@ type BenchmarkSpec struct {
 	Metrics      []MeasurementDef  `yaml:"metrics"`
 	Tasks        []string          `yaml:"tasks"`
 	Baseline     bool              `yaml:"baseline,omitempty" json:"baseline,omitempty"`
+
+	// GraderThresholds overrides pass/fail for graders, keyed by grader name or grader type.
+	// A grader passes when its score is >= the threshold. Name matches take precedence over type.
+	GraderThresholds map[string]float64 `yaml:"grader_thresholds,omitempty" json:"grader_thresholds,omitempty"`
+
+	// Notify posts a pass/fail summary to a chat webhook after the run. Nil disables it.
+	Notify *NotifyConfig `yaml:"notify,omitempty" json:"notify,omitempty"`
+}
+
+// NotifyConfig describes a Slack- or Teams-compatible incoming webhook that
+// receives a short summary message after each run.
+type NotifyConfig struct {
+	// WebhookURL receives the message. ${VAR} references are expanded from the
+	// environment, so the secret URL can stay out of the spec.
+	WebhookURL string `yaml:"webhook_url" json:"webhook_url"`
+
+	// Link is appended to the default message, e.g. a CI run or artifacts URL.
+	// ${VAR} references are expanded from the environment.
+	Link string `yaml:"link,omitempty" json:"link,omitempty"`
+
+	// Template overrides the message text using Go text/template syntax.
+	Template string `yaml:"template,omitempty" json:"template,omitempty"`
 }
 
 type SpecIdentity struct {
@@ -45,6 +71,124 @@ type Config struct {
 	MaxAttempts    int            `yaml:"max_attempts,omitempty" json:"max_attempts,omitempty"`
 	GroupBy        string         `yaml:"group_by,omitempty" json:"group_by,omitempty"`
 	JudgeModel     string         `yaml:"judge_model,omitempty" json:"judge_model,omitempty"`
+
+	// RetryBackoffMs is the base delay before a retry, doubled on each subsequent one.
+	RetryBackoffMs int         `yaml:"retry_backoff_ms,omitempty" json:"retry_backoff_ms,omitempty"`
+	RetryJitter    RetryJitter `yaml:"retry_jitter,omitempty" json:"retry_jitter,omitempty"`
+	// RetryMaxElapsedSec caps the cumulative backoff; retries stop once it would be exceeded.
+	RetryMaxElapsedSec int `yaml:"retry_max_elapsed_seconds,omitempty" json:"retry_max_elapsed_seconds,omitempty"`
+	// MaxOutputBytes truncates the agent's output before grading and storage (0 = unlimited).
+	MaxOutputBytes int `yaml:"max_output_bytes,omitempty" json:"max_output_bytes,omitempty"`
+	// MaxFeedbackBytes truncates grader feedback and details before storage (0 = unlimited).
+	MaxFeedbackBytes int `yaml:"max_feedback_bytes,omitempty" json:"max_feedback_bytes,omitempty"`
+	// SlowTaskMs flags slower tasks in the summary without failing the run (0 = disabled).
+	SlowTaskMs int64      `yaml:"slow_task_ms,omitempty" json:"slow_task_ms,omitempty"`
+	WeightMode WeightMode `yaml:"weight_mode,omitempty" json:"weight_mode,omitempty"`
+	// TrimOutliers is the percentage of runs dropped from each end of a task's scores.
+	TrimOutliers float64 `yaml:"trim_outliers,omitempty" json:"trim_outliers,omitempty"`
+	// MinRunsForCI is the fewest runs that get a confidence interval (0 = DefaultMinRunsForCI).
+	MinRunsForCI int `yaml:"min_runs_for_ci,omitempty" json:"min_runs_for_ci,omitempty"`
+	// WeightByTrials weights each task in the aggregate by its completed runs.
+	WeightByTrials  bool            `yaml:"weight_by_trials,omitempty" json:"weight_by_trials,omitempty"`
+	AggregateMethod AggregateMethod `yaml:"aggregate_method,omitempty" json:"aggregate_method,omitempty"`
+	// TriggerWeight (0-1) blends the trigger F1 score into the aggregate score.
+	TriggerWeight float64 `yaml:"trigger_weight,omitempty" json:"trigger_weight,omitempty"`
+	// PassThreshold passes a task on its average score rather than requiring every run to pass.
+	PassThreshold float64 `yaml:"pass_threshold,omitempty" json:"pass_threshold,omitempty"`
+	// PassRateThreshold is the pass rate (0-1) at which a run counts as green.
+	PassRateThreshold float64 `yaml:"pass_rate_threshold,omitempty" json:"pass_rate_threshold,omitempty"`
+	// GatePassRate bases the exit code on PassRateThreshold instead of task failures.
+	GatePassRate bool `yaml:"gate_pass_rate,omitempty" json:"gate_pass_rate,omitempty"`
+	// AllowedTools and DeniedTools take glob patterns; tasks can override either list.
+	AllowedTools        []string            `yaml:"allowed_tools,omitempty" json:"allowed_tools,omitempty"`
+	DeniedTools         []string            `yaml:"denied_tools,omitempty" json:"denied_tools,omitempty"`
+	FailOnToolViolation bool                `yaml:"fail_on_tool_violation,omitempty" json:"fail_on_tool_violation,omitempty"`
+	EnvMatrix           []MatrixEnvironment `yaml:"env_matrix,omitempty" json:"env_matrix,omitempty"`
+	// SystemPrompt is a template appended to the engine's system prompt; tasks can override it.
+	SystemPrompt string `yaml:"system_prompt,omitempty" json:"system_prompt,omitempty"`
+	// JudgeMap picks the judge model per executed model, falling back to JudgeModel.
+	JudgeMap map[string]string `yaml:"judge_map,omitempty" json:"judge_map,omitempty"`
+	// Redact lists regexes or named profiles ("secrets", "pii") masked before anything is written.
+	Redact []string `yaml:"redact,omitempty" json:"redact,omitempty"`
+	// SkillRegistry is a directory of shared skills that bare skill names resolve against.
+	SkillRegistry string `yaml:"skill_registry,omitempty" json:"skill_registry,omitempty"`
+}
+
+// JudgeModelFor returns the judge model for grading runs of model: its
+// judge_map entry, or JudgeModel if it has none.
+func (c *Config) JudgeModelFor(model string) string {
+	if judge := c.JudgeMap[model]; judge != "" {
+		return judge
+	}
+	return c.JudgeModel
+}
+
+// EffectiveAggregateMethod returns the method the aggregate score is computed
+// with: AggregateMethodTrialWeighted under weight_by_trials, else AggregateMethod.
+func (c *Config) EffectiveAggregateMethod() AggregateMethod {
+	if c.WeightByTrials {
+		return AggregateMethodTrialWeighted
+	}
+	return c.AggregateMethod.OrDefault()
+}
+
+// MatrixEnvironment is one named set of environment variables in config.env_matrix.
+type MatrixEnvironment struct {
+	Name string            `yaml:"name" json:"name"`
+	Env  map[string]string `yaml:"env,omitempty" json:"env,omitempty"`
+}
+
+// DefaultMinRunsForCI is the MinRunsForCI used when a spec doesn't set one.
+const DefaultMinRunsForCI = 5
+
+// RetryJitter controls how retry backoff delays are randomized.
+type RetryJitter string
+
+const (
+	// RetryJitterNone uses the exact exponential delay.
+	RetryJitterNone RetryJitter = "none"
+	// RetryJitterFull picks a delay uniformly in [0, delay].
+	RetryJitterFull RetryJitter = "full"
+	// RetryJitterEqual picks a delay uniformly in [delay/2, delay].
+	RetryJitterEqual RetryJitter = "equal"
+)
+
+// WeightMode controls whether weighted scores are divided by the total grader weight.
+type WeightMode string
+
+const (
+	// WeightModeNormalized divides the weighted sum by the total weight, keeping
+	// scores in [0, 1]. This is the default.
+	WeightModeNormalized WeightMode = "normalized"
+	// WeightModeRaw uses the weighted sum as-is, so weights act as absolute
+	// multipliers (e.g. weights that already sum to 1, or points-based scoring).
+	WeightModeRaw WeightMode = "raw"
+)
+
+// AggregateMethod controls how per-task scores combine into the aggregate score.
+type AggregateMethod string
+
+const (
+	// AggregateMethodMean averages the task scores. This is the default.
+	AggregateMethodMean AggregateMethod = "mean"
+	// AggregateMethodMedian takes the middle task score, so a few outliers
+	// don't move the headline.
+	AggregateMethodMedian AggregateMethod = "median"
+	// AggregateMethodMin takes the worst task score.
+	AggregateMethodMin AggregateMethod = "min"
+	// AggregateMethodP90 takes the 90th percentile task score (nearest rank).
+	AggregateMethodP90 AggregateMethod = "p90"
+	// AggregateMethodTrialWeighted averages task scores weighted by completed runs.
+	// It's recorded in results when weight_by_trials is set, not accepted in specs.
+	AggregateMethodTrialWeighted AggregateMethod = "trial_weighted"
+)
+
+// OrDefault returns m, or AggregateMethodMean when m is empty.
+func (m AggregateMethod) OrDefault() AggregateMethod {
+	if m == "" {
+		return AggregateMethodMean
+	}
+	return m
 }
 
 // GraderConfig defines a validator/grader
@@ -55,6 +199,8 @@ type GraderConfig struct {
 	Rubric     string           `yaml:"rubric,omitempty" json:"rubric,omitempty"`
 	ModelID    string           `yaml:"model,omitempty" json:"model_id,omitempty"`
 	Weight     float64          `yaml:"weight,omitempty" json:"weight,omitempty"`
+	ScoreRange ScoreRange       `yaml:"score_range,omitempty" json:"score_range,omitempty"`
+	Tags       []string         `yaml:"tags,omitempty" json:"tags,omitempty"`
 	Parameters GraderParameters `yaml:"config,omitempty" json:"parameters,omitempty"`
 }
 
@@ -66,6 +212,8 @@ func (g *GraderConfig) UnmarshalYAML(node *yaml.Node) error {
 		Rubric     string     `yaml:"rubric,omitempty"`
 		ModelID    string     `yaml:"model,omitempty"`
 		Weight     float64    `yaml:"weight,omitempty"`
+		ScoreRange ScoreRange `yaml:"score_range,omitempty"`
+		Tags       []string   `yaml:"tags,omitempty"`
 		Parameters yaml.Node  `yaml:"config,omitempty"`
 	}
 
@@ -73,6 +221,9 @@ func (g *GraderConfig) UnmarshalYAML(node *yaml.Node) error {
 	if err := node.Decode(&raw); err != nil {
 		return err
 	}
+	if err := raw.ScoreRange.Validate(); err != nil {
+		return fmt.Errorf("invalid score_range for grader %q: %w", raw.Identifier, err)
+	}
 
 	params, err := decodeGraderParameters(raw.Kind, &raw.Parameters)
 	if err != nil {
@@ -85,6 +236,8 @@ func (g *GraderConfig) UnmarshalYAML(node *yaml.Node) error {
 	g.Rubric = raw.Rubric
 	g.ModelID = raw.ModelID
 	g.Weight = raw.Weight
+	g.ScoreRange = raw.ScoreRange
+	g.Tags = raw.Tags
 	g.Parameters = params
 
 	return nil
@@ -98,6 +251,33 @@ func (g *GraderConfig) EffectiveWeight() float64 {
 	return g.Weight
 }
 
+// ScoreRange is the [min, max] range a grader reports its scores in, for
+// graders (e.g. external programs) that don't score on 0–1.
+type ScoreRange []float64
+
+// Validate checks that the range is empty or a [min, max] pair with max > min.
+func (r ScoreRange) Validate() error {
+	if len(r) == 0 {
+		return nil
+	}
+	if len(r) != 2 {
+		return fmt.Errorf("expected [min, max], got %d value(s)", len(r))
+	}
+	if r[1] <= r[0] {
+		return fmt.Errorf("max (%g) must be greater than min (%g)", r[1], r[0])
+	}
+	return nil
+}
+
+// Normalize maps score from the range onto 0–1, clamping scores outside the
+// range. An empty range returns score unchanged.
+func (r ScoreRange) Normalize(score float64) float64 {
+	if len(r) != 2 || r[1] <= r[0] {
+		return score
+	}
+	return math.Min(1, math.Max(0, (score-r[0])/(r[1]-r[0])))
+}
+
 // MeasurementDef defines a metric
 type MeasurementDef struct {
 	Identifier string  `yaml:"name" json:"identifier"`
@@ -107,6 +287,36 @@ type MeasurementDef struct {
 	Desc       string  `yaml:"description,omitempty" json:"desc,omitempty"`
 }
 
+// GraderThreshold returns the configured pass threshold for a grader, matching
+// by name first and then by type.
+func (s *BenchmarkSpec) GraderThreshold(name string, kind GraderKind) (float64, bool) {
+	if th, ok := s.GraderThresholds[name]; ok {
+		return th, true
+	}
+	th, ok := s.GraderThresholds[string(kind)]
+	return th, ok
+}
+
+// ApplyGraderThresholds recomputes Passed as score >= threshold for every
+// result that has a configured threshold.
+func (s *BenchmarkSpec) ApplyGraderThresholds(results map[string]GraderResults) {
+	if len(s.GraderThresholds) == 0 {
+		return
+	}
+	for name, res := range results {
+		th, ok := s.GraderThreshold(name, res.Type)
+		if !ok {
+			continue
+		}
+		res.Passed = res.Score >= th
+		if res.Details == nil {
+			res.Details = make(map[string]any)
+		}
+		res.Details["pass_threshold"] = th
+		results[name] = res
+	}
+}
+
 // LoadBenchmarkSpec loads a spec from a YAML file
 func LoadBenchmarkSpec(path string) (*BenchmarkSpec, error) {
 	data, err := os.ReadFile(path)
@@ -135,9 +345,101 @@ func (s *BenchmarkSpec) Validate() error {
 	if s.Config.TimeoutSec < 1 {
 		return fmt.Errorf("timeout_seconds must be at least 1, got %d", s.Config.TimeoutSec)
 	}
+	switch s.Config.RetryJitter {
+	case "", RetryJitterNone, RetryJitterFull, RetryJitterEqual:
+	default:
+		return fmt.Errorf("retry_jitter must be one of none, full, equal, got %q", s.Config.RetryJitter)
+	}
+	if s.Config.RetryBackoffMs < 0 {
+		return fmt.Errorf("retry_backoff_ms must not be negative, got %d", s.Config.RetryBackoffMs)
+	}
+	switch s.Config.WeightMode {
+	case "", WeightModeNormalized, WeightModeRaw:
+	default:
+		return fmt.Errorf("weight_mode must be one of normalized, raw, got %q", s.Config.WeightMode)
+	}
+	if s.Config.TrimOutliers < 0 || s.Config.TrimOutliers >= 50 {
+		return fmt.Errorf("trim_outliers must be at least 0 and less than 50, got %g", s.Config.TrimOutliers)
+	}
+	switch s.Config.AggregateMethod {
+	case "", AggregateMethodMean:
+	case AggregateMethodMedian, AggregateMethodMin, AggregateMethodP90:
+		if s.Config.WeightByTrials {
+			return fmt.Errorf("weight_by_trials only applies to aggregate_method mean, got %q", s.Config.AggregateMethod)
+		}
+	default:
+		return fmt.Errorf("aggregate_method must be one of mean, median, min, p90, got %q", s.Config.AggregateMethod)
+	}
+	if s.Config.TriggerWeight < 0 || s.Config.TriggerWeight > 1 {
+		return fmt.Errorf("trigger_weight must be between 0 and 1, got %g", s.Config.TriggerWeight)
+	}
+	for key, th := range s.GraderThresholds {
+		if th < 0 || th > 1 {
+			return fmt.Errorf("grader_thresholds[%s] must be between 0 and 1, got %g", key, th)
+		}
+	}
+	seenEnvs := make(map[string]bool, len(s.Config.EnvMatrix))
+	for i, env := range s.Config.EnvMatrix {
+		if env.Name == "" {
+			return fmt.Errorf("env_matrix[%d] must have a name", i)
+		}
+		if seenEnvs[env.Name] {
+			return fmt.Errorf("env_matrix has duplicate environment %q", env.Name)
+		}
+		seenEnvs[env.Name] = true
+	}
+	if s.Config.PassThreshold < 0 {
+		return fmt.Errorf("pass_threshold must not be negative, got %g", s.Config.PassThreshold)
+	}
+	if s.Config.PassRateThreshold < 0 || s.Config.PassRateThreshold > 1 {
+		return fmt.Errorf("pass_rate_threshold must be between 0 and 1, got %g", s.Config.PassRateThreshold)
+	}
+	if s.Config.RetryMaxElapsedSec < 0 {
+		return fmt.Errorf("retry_max_elapsed_seconds must not be negative, got %d", s.Config.RetryMaxElapsedSec)
+	}
 	return nil
 }
 
+// ResolveSkillPaths returns the directories to search for skills, resolved
+// against specDir. A skill_directories entry that is a bare name (no path
+// separator) resolves to that skill in skill_registry when the registry has
+// it, and relative to specDir otherwise. With a registry, the registry
+// directory of every required skill is searched too.
+func (s *BenchmarkSpec) ResolveSkillPaths(specDir string) []string {
+	if s.Config.SkillRegistry == "" {
+		return utils.ResolvePaths(s.Config.SkillPaths, specDir)
+	}
+	registry := utils.ResolvePaths([]string{s.Config.SkillRegistry}, specDir)[0]
+
+	var resolved []string
+	add := func(path string) {
+		if !slices.Contains(resolved, path) {
+			resolved = append(resolved, path)
+		}
+	}
+	for _, path := range s.Config.SkillPaths {
+		if isBareSkillName(path) {
+			if info, err := os.Stat(filepath.Join(registry, path)); err == nil && info.IsDir() {
+				add(filepath.Join(registry, path))
+				continue
+			}
+		}
+		add(utils.ResolvePaths([]string{path}, specDir)[0])
+	}
+	for _, required := range s.Config.RequiredSkills {
+		name, _, _ := strings.Cut(required, "@")
+		if name = strings.TrimSpace(name); isBareSkillName(name) {
+			add(filepath.Join(registry, name))
+		}
+	}
+	return resolved
+}
+
+// isBareSkillName reports whether path is a plain name rather than a path.
+func isBareSkillName(path string) bool {
+	return path != "" && path != "." && path != ".." && !strings.ContainsAny(path, `/\`)
+}
+
 // ResolveTestFiles expands glob patterns to actual test files
 func (s *BenchmarkSpec) ResolveTestFiles(basePath string) ([]string, error) {
 	var files []string
@@ -3,6 +3,7 @@ package models
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -301,3 +302,269 @@ config:
 		}
 	})
 }
+
+func TestBenchmarkSpec_ApplyGraderThresholds(t *testing.T) {
+	spec := &BenchmarkSpec{
+		GraderThresholds: map[string]float64{
+			"text":        0.8,
+			"lenient-one": 0.5,
+		},
+	}
+
+	results := map[string]GraderResults{
+		"strict-one":  {Name: "strict-one", Type: GraderKindText, Score: 0.6, Passed: true},
+		"lenient-one": {Name: "lenient-one", Type: GraderKindText, Score: 0.6, Passed: false},
+		"untouched":   {Name: "untouched", Type: GraderKindFile, Score: 0.1, Passed: true},
+	}
+	spec.ApplyGraderThresholds(results)
+
+	if results["strict-one"].Passed {
+		t.Errorf("expected strict-one to fail a 0.8 type threshold with score 0.6")
+	}
+	if !results["lenient-one"].Passed {
+		t.Errorf("expected lenient-one to pass its 0.5 name threshold (name overrides type)")
+	}
+	if !results["untouched"].Passed {
+		t.Errorf("expected graders without a threshold to keep their own verdict")
+	}
+	if got := results["strict-one"].Details["pass_threshold"]; got != 0.8 {
+		t.Errorf("expected pass_threshold detail 0.8, got %v", got)
+	}
+}
+
+func TestBenchmarkSpec_GraderThresholdsValidation(t *testing.T) {
+	spec := &BenchmarkSpec{
+		Config:           Config{TrialsPerTask: 1, TimeoutSec: 1},
+		GraderThresholds: map[string]float64{"text": 1.5},
+	}
+	if err := spec.Validate(); err == nil {
+		t.Fatal("expected error for threshold outside [0, 1]")
+	}
+}
+
+func TestBenchmarkSpec_PassThresholdValidation(t *testing.T) {
+	spec := &BenchmarkSpec{Config: Config{TrialsPerTask: 1, TimeoutSec: 1, PassThreshold: -0.1}}
+	if err := spec.Validate(); err == nil {
+		t.Fatal("expected error for negative pass_threshold")
+	}
+}
+
+func TestBenchmarkSpec_PassRateThresholdValidation(t *testing.T) {
+	for _, th := range []float64{-0.1, 1.5} {
+		spec := &BenchmarkSpec{Config: Config{TrialsPerTask: 1, TimeoutSec: 1, PassRateThreshold: th}}
+		if err := spec.Validate(); err == nil {
+			t.Fatalf("expected error for pass_rate_threshold %g", th)
+		}
+	}
+}
+
+func TestBenchmarkSpec_WeightModeValidation(t *testing.T) {
+	for _, m := range []WeightMode{"", WeightModeNormalized, WeightModeRaw} {
+		spec := &BenchmarkSpec{Config: Config{TrialsPerTask: 1, TimeoutSec: 1, WeightMode: m}}
+		if err := spec.Validate(); err != nil {
+			t.Errorf("weight_mode %q: unexpected error %v", m, err)
+		}
+	}
+
+	spec := &BenchmarkSpec{Config: Config{TrialsPerTask: 1, TimeoutSec: 1, WeightMode: "raww"}}
+	if err := spec.Validate(); err == nil {
+		t.Fatal("expected error for unknown weight_mode")
+	}
+}
+
+func TestBenchmarkSpec_TrimOutliersValidation(t *testing.T) {
+	for _, pct := range []float64{0, 10, 49.9} {
+		spec := &BenchmarkSpec{Config: Config{TrialsPerTask: 1, TimeoutSec: 1, TrimOutliers: pct}}
+		if err := spec.Validate(); err != nil {
+			t.Errorf("trim_outliers %g: unexpected error %v", pct, err)
+		}
+	}
+	for _, pct := range []float64{-5, 50, 60} {
+		spec := &BenchmarkSpec{Config: Config{TrialsPerTask: 1, TimeoutSec: 1, TrimOutliers: pct}}
+		if err := spec.Validate(); err == nil {
+			t.Fatalf("expected error for trim_outliers %g", pct)
+		}
+	}
+}
+
+func TestBenchmarkSpec_AggregateMethodValidation(t *testing.T) {
+	for _, m := range []AggregateMethod{"", AggregateMethodMean, AggregateMethodMedian, AggregateMethodMin, AggregateMethodP90} {
+		spec := &BenchmarkSpec{Config: Config{TrialsPerTask: 1, TimeoutSec: 1, AggregateMethod: m}}
+		if err := spec.Validate(); err != nil {
+			t.Errorf("aggregate_method %q: unexpected error %v", m, err)
+		}
+	}
+
+	spec := &BenchmarkSpec{Config: Config{TrialsPerTask: 1, TimeoutSec: 1, AggregateMethod: "mode"}}
+	if err := spec.Validate(); err == nil {
+		t.Fatal("expected error for unknown aggregate_method")
+	}
+
+	spec = &BenchmarkSpec{Config: Config{TrialsPerTask: 1, TimeoutSec: 1, AggregateMethod: AggregateMethodMedian, WeightByTrials: true}}
+	if err := spec.Validate(); err == nil {
+		t.Fatal("expected error for weight_by_trials with aggregate_method median")
+	}
+}
+
+func TestBenchmarkSpec_TriggerWeightValidation(t *testing.T) {
+	for _, w := range []float64{0, 0.25, 1} {
+		spec := &BenchmarkSpec{Config: Config{TrialsPerTask: 1, TimeoutSec: 1, TriggerWeight: w}}
+		if err := spec.Validate(); err != nil {
+			t.Errorf("trigger_weight %g: unexpected error %v", w, err)
+		}
+	}
+	for _, w := range []float64{-0.1, 1.5} {
+		spec := &BenchmarkSpec{Config: Config{TrialsPerTask: 1, TimeoutSec: 1, TriggerWeight: w}}
+		if err := spec.Validate(); err == nil {
+			t.Fatalf("expected error for trigger_weight %g", w)
+		}
+	}
+}
+
+func TestTestCase_EffectivePassThreshold(t *testing.T) {
+	cfg := Config{PassThreshold: 0.7}
+	tc := &TestCase{}
+	if got := tc.EffectivePassThreshold(cfg); got != 0.7 {
+		t.Errorf("expected spec threshold 0.7, got %g", got)
+	}
+
+	override := 0.9
+	tc.PassThreshold = &override
+	if got := tc.EffectivePassThreshold(cfg); got != 0.9 {
+		t.Errorf("expected task threshold 0.9, got %g", got)
+	}
+
+	// An explicit 0 turns the spec's threshold off for this task.
+	off := 0.0
+	tc.PassThreshold = &off
+	if got := tc.EffectivePassThreshold(cfg); got != 0 {
+		t.Errorf("expected 0, got %g", got)
+	}
+}
+
+func TestConfig_JudgeModelFor(t *testing.T) {
+	cfg := Config{
+		JudgeModel: "default-judge",
+		JudgeMap:   map[string]string{"gpt-5": "claude-judge", "claude": "gpt-judge"},
+	}
+	for model, want := range map[string]string{
+		"gpt-5":    "claude-judge",
+		"claude":   "gpt-judge",
+		"unmapped": "default-judge",
+	} {
+		if got := cfg.JudgeModelFor(model); got != want {
+			t.Errorf("JudgeModelFor(%q) = %q, want %q", model, got, want)
+		}
+	}
+
+	if got := (&Config{}).JudgeModelFor("gpt-5"); got != "" {
+		t.Errorf("expected no judge without judge_model or judge_map, got %q", got)
+	}
+}
+
+func TestBenchmarkSpec_EnvMatrixValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		envs    []MatrixEnvironment
+		wantErr bool
+	}{
+		{"valid", []MatrixEnvironment{{Name: "a"}, {Name: "b", Env: map[string]string{"X": "1"}}}, false},
+		{"missing name", []MatrixEnvironment{{Env: map[string]string{"X": "1"}}}, true},
+		{"duplicate name", []MatrixEnvironment{{Name: "a"}, {Name: "a"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &BenchmarkSpec{Config: Config{TrialsPerTask: 1, TimeoutSec: 1, EnvMatrix: tt.envs}}
+			if err := spec.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScoreRange_Normalize(t *testing.T) {
+	tests := []struct {
+		r     ScoreRange
+		score float64
+		want  float64
+	}{
+		{nil, 0.4, 0.4},
+		{ScoreRange{0, 100}, 85, 0.85},
+		{ScoreRange{0, 100}, 150, 1},
+		{ScoreRange{0, 100}, -5, 0},
+		{ScoreRange{1, 5}, 3, 0.5},
+	}
+	for _, tt := range tests {
+		if got := tt.r.Normalize(tt.score); got != tt.want {
+			t.Errorf("%v.Normalize(%g) = %g, want %g", tt.r, tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestGraderConfig_ScoreRangeYAML(t *testing.T) {
+	tempDir := t.TempDir()
+	load := func(scoreRange string) (*BenchmarkSpec, error) {
+		yamlContent := `name: ranged
+skill: test
+config:
+  trials_per_task: 1
+  timeout_seconds: 60
+  executor: mock
+graders:
+  - name: percent
+    type: program
+    score_range: ` + scoreRange + `
+    config:
+      command: echo
+`
+		specPath := filepath.Join(tempDir, "ranged.yaml")
+		if err := os.WriteFile(specPath, []byte(yamlContent), 0644); err != nil {
+			t.Fatalf("Failed to write spec file: %v", err)
+		}
+		return LoadBenchmarkSpec(specPath)
+	}
+
+	spec, err := load("[0, 100]")
+	if err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+	if got := spec.Graders[0].ScoreRange; len(got) != 2 || got[0] != 0 || got[1] != 100 {
+		t.Errorf("Expected score_range [0 100], got %v", got)
+	}
+
+	for _, bad := range []string{"[100]", "[10, 10]", "[5, 1]"} {
+		if _, err := load(bad); err == nil {
+			t.Errorf("Expected error for score_range %s", bad)
+		}
+	}
+}
+
+func TestBenchmarkSpec_ResolveSkillPaths(t *testing.T) {
+	specDir := t.TempDir()
+	registry := filepath.Join(specDir, "shared")
+	if err := os.MkdirAll(filepath.Join(registry, "deploy"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	abs := filepath.Join(t.TempDir(), "elsewhere")
+
+	// Without a registry, names are paths
+	spec := &BenchmarkSpec{Config: Config{SkillPaths: []string{"deploy", abs}}}
+	want := []string{filepath.Join(specDir, "deploy"), abs}
+	if got := spec.ResolveSkillPaths(specDir); !slices.Equal(got, want) {
+		t.Errorf("ResolveSkillPaths() = %v, want %v", got, want)
+	}
+
+	spec.Config.SkillRegistry = "shared"
+	spec.Config.SkillPaths = []string{"deploy", "./deploy", "local", abs}
+	spec.Config.RequiredSkills = []string{"deploy@>=1.0", "review"}
+	want = []string{
+		filepath.Join(registry, "deploy"),
+		filepath.Join(specDir, "deploy"),
+		filepath.Join(specDir, "local"),
+		abs,
+		filepath.Join(registry, "review"),
+	}
+	if got := spec.ResolveSkillPaths(specDir); !slices.Equal(got, want) {
+		t.Errorf("ResolveSkillPaths() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/microsoft/waza/internal/expr"
+)
+
+// SuccessVars returns the values a task's success_when expression can refer
+// to. Each grader appears under its name and, aggregated, under its type
+// (e.g. code.passed is true only if every code grader passed; code.score is
+// their mean). Names win over types. Run metrics are top-level: tool_calls,
+// duration_ms, and when usage was recorded, turns and tokens.
+func SuccessVars(results map[string]GraderResults, session SessionDigest, durationMs int64) map[string]any {
+	vars := map[string]any{
+		"tool_calls":  session.ToolCallCount,
+		"duration_ms": durationMs,
+	}
+	if session.Usage != nil {
+		vars["turns"] = session.Usage.Turns
+		vars["tokens"] = session.Usage.InputTokens + session.Usage.OutputTokens
+	}
+
+	type typeAgg struct {
+		passed bool
+		total  float64
+		n      int
+	}
+	byType := make(map[GraderKind]*typeAgg)
+	for _, r := range results {
+		agg, ok := byType[r.Type]
+		if !ok {
+			agg = &typeAgg{passed: true}
+			byType[r.Type] = agg
+		}
+		agg.passed = agg.passed && r.Passed
+		agg.total += r.Score
+		agg.n++
+	}
+	for kind, agg := range byType {
+		if kind == "" {
+			continue
+		}
+		vars[string(kind)] = map[string]any{
+			"passed": agg.passed,
+			"score":  agg.total / float64(agg.n),
+		}
+	}
+	for name, r := range results {
+		vars[name] = map[string]any{
+			"passed": r.Passed,
+			"score":  r.Score,
+			"weight": r.Weight,
+		}
+	}
+	return vars
+}
+
+// RunPassed reports whether a run with these grader results passes: every
+// grader passed, or, when the task sets success_when, the expression holds.
+func (tc *TestCase) RunPassed(results map[string]GraderResults, session SessionDigest, durationMs int64) (bool, error) {
+	if tc.SuccessWhen == "" {
+		for _, r := range results {
+			if !r.Passed {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	e, err := expr.Parse(tc.SuccessWhen)
+	if err != nil {
+		return false, fmt.Errorf("success_when: %w", err)
+	}
+	passed, err := e.EvalBool(SuccessVars(results, session, durationMs))
+	if err != nil {
+		return false, fmt.Errorf("success_when: %w", err)
+	}
+	return passed, nil
+}
@@ -16,6 +16,18 @@ type SkillSummary struct {
 	PassRate       float64  `json:"pass_rate"`
 	AggregateScore float64  `json:"aggregate_score"`
 	OutputFiles    []string `json:"output_files"`
+
+	// ModelResults breaks the skill's metrics down per model.
+	ModelResults []ModelSummary `json:"model_results,omitempty"`
+}
+
+// ModelSummary contains the metrics for one model's run of a skill.
+type ModelSummary struct {
+	Model          string  `json:"model"`
+	RunID          string  `json:"run_id,omitempty"`
+	PassRate       float64 `json:"pass_rate"`
+	AggregateScore float64 `json:"aggregate_score"`
+	OutputFile     string  `json:"output_file,omitempty"`
 }
 
 // OverallSummary contains cross-skill aggregated metrics.
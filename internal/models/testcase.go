@@ -4,21 +4,33 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/microsoft/waza/internal/expr"
 	"gopkg.in/yaml.v3"
 )
 
 // TestCase represents a single evaluation test
 type TestCase struct {
-	Active      *bool             `yaml:"enabled,omitempty" json:"active,omitempty"`
-	ContextRoot string            `yaml:"context_dir,omitempty" json:"context_root,omitempty"`
-	DisplayName string            `yaml:"name" json:"display_name"`
-	Expectation TestExpectation   `yaml:"expected,omitempty" json:"expectation,omitempty"`
-	Stimulus    TestStimulus      `yaml:"inputs" json:"stimulus"`
-	Summary     string            `yaml:"description,omitempty" json:"summary,omitempty"`
-	Tags        []string          `yaml:"tags,omitempty" json:"labels,omitempty"`
-	TestID      string            `yaml:"id" json:"test_id"`
-	TimeoutSec  *int              `yaml:"timeout_seconds,omitempty" json:"timeout_sec,omitempty"`
-	Validators  []ValidatorInline `yaml:"graders,omitempty" json:"validators,omitempty"`
+	Active        *bool             `yaml:"enabled,omitempty" json:"active,omitempty"`
+	AllowedTools  []string          `yaml:"allowed_tools,omitempty" json:"allowed_tools,omitempty"` // overrides config.allowed_tools
+	ContextRoot   string            `yaml:"context_dir,omitempty" json:"context_root,omitempty"`
+	DeniedTools   []string          `yaml:"denied_tools,omitempty" json:"denied_tools,omitempty"` // overrides config.denied_tools
+	DisplayName   string            `yaml:"name" json:"display_name"`
+	Expectation   TestExpectation   `yaml:"expected,omitempty" json:"expectation,omitempty"`
+	Metadata      map[string]any    `yaml:"metadata,omitempty" json:"metadata,omitempty"`             // passed to graders, not the agent
+	PassThreshold *float64          `yaml:"pass_threshold,omitempty" json:"pass_threshold,omitempty"` // overrides config.pass_threshold
+	Stimulus      TestStimulus      `yaml:"inputs" json:"stimulus"`
+	SuccessWhen   string            `yaml:"success_when,omitempty" json:"success_when,omitempty"` // boolean expression over grader results; replaces "all graders pass"
+	Summary       string            `yaml:"description,omitempty" json:"summary,omitempty"`
+	SystemPrompt  string            `yaml:"system_prompt,omitempty" json:"system_prompt,omitempty"` // overrides config.system_prompt
+	Tags          []string          `yaml:"tags,omitempty" json:"labels,omitempty"`
+	TestID        string            `yaml:"id" json:"test_id"`
+	TimeoutSec    *int              `yaml:"timeout_seconds,omitempty" json:"timeout_sec,omitempty"`
+	Validators    []ValidatorInline `yaml:"graders,omitempty" json:"validators,omitempty"`
+	XFail         bool              `yaml:"xfail,omitempty" json:"xfail,omitempty"`               // expected to fail; failing doesn't fail the run
+	XFailReason   string            `yaml:"xfail_reason,omitempty" json:"xfail_reason,omitempty"` // why, e.g. a tracking issue
+
+	// SourcePath is the file the task was loaded from, if any.
+	SourcePath string `yaml:"-" json:"-"`
 }
 
 // TestStimulus defines the input for a test
@@ -66,9 +78,21 @@ type ValidatorInline struct {
 	Checks     []string         `yaml:"assertions,omitempty" json:"checks,omitempty"`
 	Rubric     string           `yaml:"rubric,omitempty" json:"rubric,omitempty"`
 	Weight     float64          `yaml:"weight,omitempty" json:"weight,omitempty"`
+	ScoreRange ScoreRange       `yaml:"score_range,omitempty" json:"score_range,omitempty"`
+	Tags       []string         `yaml:"tags,omitempty" json:"tags,omitempty"`
 	Parameters GraderParameters `yaml:"config,omitempty" json:"parameters,omitempty"`
 }
 
+// EffectivePassThreshold returns the average weighted score the task must reach
+// to pass: its own pass_threshold if set, otherwise cfg's. Zero means the task
+// passes only when every run passed.
+func (tc *TestCase) EffectivePassThreshold(cfg Config) float64 {
+	if tc.PassThreshold != nil {
+		return *tc.PassThreshold
+	}
+	return cfg.PassThreshold
+}
+
 func (v *ValidatorInline) EffectiveWeight() float64 {
 	if v.Weight <= 0 {
 		return 1.0
@@ -83,6 +107,8 @@ func (v *ValidatorInline) UnmarshalYAML(node *yaml.Node) error {
 		Checks     []string   `yaml:"assertions,omitempty"`
 		Rubric     string     `yaml:"rubric,omitempty"`
 		Weight     float64    `yaml:"weight,omitempty"`
+		ScoreRange ScoreRange `yaml:"score_range,omitempty"`
+		Tags       []string   `yaml:"tags,omitempty"`
 		Parameters yaml.Node  `yaml:"config,omitempty"`
 	}
 
@@ -90,6 +116,9 @@ func (v *ValidatorInline) UnmarshalYAML(node *yaml.Node) error {
 	if err := node.Decode(&raw); err != nil {
 		return err
 	}
+	if err := raw.ScoreRange.Validate(); err != nil {
+		return fmt.Errorf("invalid score_range for grader %q: %w", raw.Identifier, err)
+	}
 
 	params, err := decodeGraderParameters(raw.Kind, &raw.Parameters)
 	if err != nil {
@@ -101,6 +130,8 @@ func (v *ValidatorInline) UnmarshalYAML(node *yaml.Node) error {
 	v.Checks = raw.Checks
 	v.Rubric = raw.Rubric
 	v.Weight = raw.Weight
+	v.ScoreRange = raw.ScoreRange
+	v.Tags = raw.Tags
 	v.Parameters = params
 
 	return nil
@@ -117,6 +148,16 @@ func LoadTestCase(path string) (*TestCase, error) {
 	if err := yaml.Unmarshal(data, &tc); err != nil {
 		return nil, err
 	}
+	tc.SourcePath = path
+
+	if err := tc.validateGraderNames(); err != nil {
+		return nil, err
+	}
+	if tc.SuccessWhen != "" {
+		if _, err := expr.Parse(tc.SuccessWhen); err != nil {
+			return nil, fmt.Errorf("invalid success_when: %w", err)
+		}
+	}
 
 	// Note: Active field defaults to nil when not specified in YAML.
 	// The runner treats nil as true (enabled by default).
@@ -124,3 +165,19 @@ func LoadTestCase(path string) (*TestCase, error) {
 
 	return &tc, nil
 }
+
+// validateGraderNames rejects two graders with the same name, since grader
+// results are keyed by name and one would silently overwrite the other.
+func (tc *TestCase) validateGraderNames() error {
+	seen := make(map[string]int, len(tc.Validators))
+	for i, v := range tc.Validators {
+		if v.Identifier == "" {
+			continue
+		}
+		if j, ok := seen[v.Identifier]; ok {
+			return fmt.Errorf("duplicate grader name %q: graders[%d] and graders[%d]", v.Identifier, j, i)
+		}
+		seen[v.Identifier] = i
+	}
+	return nil
+}
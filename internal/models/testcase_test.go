@@ -3,6 +3,7 @@ package models
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -77,3 +78,103 @@ inputs:
 		})
 	}
 }
+
+func TestLoadTestCase_DuplicateGraderNames(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "task.yaml")
+	yaml := `id: tc-dup
+name: Duplicate Graders
+inputs:
+  prompt: "test prompt"
+graders:
+  - type: text
+    name: check
+    config:
+      contains: ["a"]
+  - type: text
+    name: other
+    config:
+      contains: ["b"]
+  - type: text
+    name: check
+    config:
+      contains: ["c"]
+`
+	if err := os.WriteFile(p, []byte(yaml), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	_, err := LoadTestCase(p)
+	if err == nil {
+		t.Fatal("expected error for duplicate grader names")
+	}
+	want := `duplicate grader name "check": graders[0] and graders[2]`
+	if err.Error() != want {
+		t.Errorf("error = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestLoadTestCase_InvalidSuccessWhen(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "task.yaml")
+	yaml := `id: tc-expr
+name: Bad Expression
+inputs:
+  prompt: "test prompt"
+success_when: "code.passed &&"
+`
+	if err := os.WriteFile(p, []byte(yaml), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	if _, err := LoadTestCase(p); err == nil || !strings.Contains(err.Error(), "invalid success_when") {
+		t.Fatalf("LoadTestCase() error = %v, want invalid success_when", err)
+	}
+}
+
+func TestTestCase_RunPassed(t *testing.T) {
+	results := map[string]GraderResults{
+		"unit-tests": {Type: GraderKindInlineScript, Passed: true, Score: 1},
+		"lint":       {Type: GraderKindInlineScript, Passed: false, Score: 0.5},
+		"quality":    {Type: GraderKindPrompt, Passed: false, Score: 0.85},
+	}
+	session := SessionDigest{ToolCallCount: 4, Usage: &UsageStats{Turns: 3, InputTokens: 100, OutputTokens: 50}}
+
+	tests := []struct {
+		successWhen string
+		want        bool
+	}{
+		{"", false}, // default: every grader must pass
+		{"unit-tests.passed", true},
+		{"code.passed", false}, // a type passes only if all its graders did
+		{"code.score >= 0.75 && prompt.score >= 0.8", true},
+		{"unit-tests.passed && quality.score >= 0.9", false},
+		{"unit-tests.passed && (lint.passed || quality.passed)", false},
+		{"unit-tests.passed && tool_calls <= 5 && turns < 4 && tokens == 150", true},
+		{"unit-tests.passed && duration_ms < 1000", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.successWhen, func(t *testing.T) {
+			tc := &TestCase{SuccessWhen: tt.successWhen}
+			got, err := tc.RunPassed(results, session, 2000)
+			if err != nil {
+				t.Fatalf("RunPassed() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RunPassed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	allPassed := map[string]GraderResults{"unit-tests": {Type: GraderKindInlineScript, Passed: true, Score: 1}}
+	if got, err := (&TestCase{}).RunPassed(allPassed, session, 0); err != nil || !got {
+		t.Errorf("RunPassed() with every grader passing = %v, %v; want true", got, err)
+	}
+
+	// Names win over types, and unknown names are errors rather than false
+	shadow := map[string]GraderResults{"code": {Type: GraderKindText, Passed: true}, "x": {Type: GraderKindInlineScript, Passed: false}}
+	if got, err := (&TestCase{SuccessWhen: "code.passed"}).RunPassed(shadow, SessionDigest{}, 0); err != nil || !got {
+		t.Errorf("RunPassed() with shadowed type = %v, %v; want true", got, err)
+	}
+	if _, err := (&TestCase{SuccessWhen: "turns < 3"}).RunPassed(results, SessionDigest{}, 0); err == nil {
+		t.Error("RunPassed() without usage should fail on turns")
+	}
+}
@@ -0,0 +1,120 @@
+// Package notify posts a short pass/fail summary of a run to a Slack- or
+// Teams-compatible incoming webhook.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/microsoft/waza/internal/models"
+)
+
+// DefaultTemplate renders messages when NotifyConfig.Template is empty.
+const DefaultTemplate = `{{if .Passed}}✅{{else}}❌{{end}} waza {{.Eval}}: {{.Skill}} on {{.Model}}: {{.Succeeded}}/{{.Total}} passed ({{percent .PassRate}}){{if .Link}}
+{{.Link}}{{end}}`
+
+// Message is the data a notify template is rendered with.
+type Message struct {
+	Eval           string
+	Skill          string
+	Model          string
+	Passed         bool // no task failed or errored
+	Total          int
+	Succeeded      int
+	Failed         int
+	Errors         int
+	PassRate       float64 // 0.0 to 1.0
+	AggregateScore float64
+	Link           string
+}
+
+var templateFuncs = template.FuncMap{
+	"percent": func(f float64) string { return fmt.Sprintf("%.1f%%", f*100) },
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// NewMessage summarizes outcome for a notification.
+func NewMessage(outcome *models.EvaluationOutcome, link string) Message {
+	d := outcome.Digest
+	return Message{
+		Eval:           outcome.BenchName,
+		Skill:          outcome.SkillTested,
+		Model:          outcome.Setup.ModelID,
+		Passed:         d.Failed == 0 && d.Errors == 0,
+		Total:          d.TotalTests,
+		Succeeded:      d.Succeeded,
+		Failed:         d.Failed,
+		Errors:         d.Errors,
+		PassRate:       d.SuccessRate,
+		AggregateScore: d.AggregateScore,
+		Link:           link,
+	}
+}
+
+// Render formats msg with tmpl, or with DefaultTemplate when tmpl is empty.
+func Render(tmpl string, msg Message) (string, error) {
+	if tmpl == "" {
+		tmpl = DefaultTemplate
+	}
+	t, err := template.New("notify").Funcs(templateFuncs).Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("parsing notify template: %w", err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, msg); err != nil {
+		return "", fmt.Errorf("rendering notify template: %w", err)
+	}
+	return b.String(), nil
+}
+
+// Send posts a summary of outcome to cfg.WebhookURL as {"text": "..."}, the
+// payload both Slack and Teams incoming webhooks accept.
+func Send(ctx context.Context, cfg models.NotifyConfig, outcome *models.EvaluationOutcome) error {
+	webhookURL := os.ExpandEnv(cfg.WebhookURL)
+	if webhookURL == "" {
+		return fmt.Errorf("notify: webhook_url is empty")
+	}
+
+	text, err := Render(cfg.Template, NewMessage(outcome, os.ExpandEnv(cfg.Link)))
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("notify: invalid webhook_url")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		// Drop the URL from the error; webhook URLs embed their secret
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("notify: posting to webhook: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512)) //nolint:errcheck
+		return fmt.Errorf("notify: webhook returned %s: %s", resp.Status, strings.TrimSpace(string(snippet)))
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleOutcome() *models.EvaluationOutcome {
+	return &models.EvaluationOutcome{
+		BenchName:   "explainer-eval",
+		SkillTested: "code-explainer",
+		Setup:       models.OutcomeSetup{ModelID: "gpt-4o"},
+		Digest: models.OutcomeDigest{
+			TotalTests:     4,
+			Succeeded:      3,
+			Failed:         1,
+			SuccessRate:    0.75,
+			AggregateScore: 0.8,
+		},
+	}
+}
+
+// captureWebhook starts a server that records the text of each posted message.
+func captureWebhook(t *testing.T, status int) (*httptest.Server, *[]string) {
+	t.Helper()
+	var texts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var payload map[string]string
+		require.NoError(t, json.Unmarshal(body, &payload))
+		texts = append(texts, payload["text"])
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &texts
+}
+
+func TestSend_DefaultMessage(t *testing.T) {
+	srv, texts := captureWebhook(t, http.StatusOK)
+	t.Setenv("WAZA_TEST_RUN_URL", "https://ci.example.com/runs/42")
+
+	err := Send(context.Background(), models.NotifyConfig{
+		WebhookURL: srv.URL,
+		Link:       "${WAZA_TEST_RUN_URL}",
+	}, sampleOutcome())
+	require.NoError(t, err)
+
+	require.Len(t, *texts, 1)
+	assert.Equal(t, "❌ waza explainer-eval: code-explainer on gpt-4o: 3/4 passed (75.0%)\nhttps://ci.example.com/runs/42", (*texts)[0])
+}
+
+func TestSend_Template(t *testing.T) {
+	srv, texts := captureWebhook(t, http.StatusOK)
+	t.Setenv("WAZA_TEST_WEBHOOK", srv.URL)
+
+	outcome := sampleOutcome()
+	outcome.Digest.Failed = 0
+	err := Send(context.Background(), models.NotifyConfig{
+		WebhookURL: "${WAZA_TEST_WEBHOOK}",
+		Template:   `{{.Skill}} {{if .Passed}}ok{{end}} {{percent .PassRate}} score={{printf "%.2f" .AggregateScore}}`,
+	}, outcome)
+	require.NoError(t, err)
+
+	require.Len(t, *texts, 1)
+	assert.Equal(t, "code-explainer ok 75.0% score=0.80", (*texts)[0])
+}
+
+func TestSend_Errors(t *testing.T) {
+	t.Run("non-2xx status", func(t *testing.T) {
+		srv, _ := captureWebhook(t, http.StatusForbidden)
+		err := Send(context.Background(), models.NotifyConfig{WebhookURL: srv.URL}, sampleOutcome())
+		require.ErrorContains(t, err, "403")
+	})
+
+	t.Run("empty url", func(t *testing.T) {
+		err := Send(context.Background(), models.NotifyConfig{WebhookURL: "${WAZA_TEST_UNSET_WEBHOOK}"}, sampleOutcome())
+		require.ErrorContains(t, err, "webhook_url is empty")
+	})
+
+	t.Run("bad template", func(t *testing.T) {
+		err := Send(context.Background(), models.NotifyConfig{WebhookURL: "http://127.0.0.1:0", Template: "{{.Nope"}, sampleOutcome())
+		require.ErrorContains(t, err, "parsing notify template")
+	})
+
+	t.Run("unreachable webhook hides the url", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		url := srv.URL + "/services/SECRET"
+		srv.Close()
+
+		err := Send(context.Background(), models.NotifyConfig{WebhookURL: url}, sampleOutcome())
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "SECRET")
+	})
+}
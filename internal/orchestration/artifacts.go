@@ -0,0 +1,169 @@
+package orchestration
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/microsoft/waza/internal/models"
+)
+
+// DefaultArtifactMaxBytes caps how much of a run's workspace is captured when
+// ArtifactCapture.MaxBytes isn't set.
+const DefaultArtifactMaxBytes int64 = 50 * 1024 * 1024
+
+// ArtifactCapture configures copying each run's workspace for later inspection.
+type ArtifactCapture struct {
+	// Dir is the root directory; files for each run land in Dir/<task>/run-<N>/.
+	Dir string
+
+	// Patterns limits capture to workspace-relative paths matching any glob.
+	// Patterns without a "/" also match a file's base name at any depth.
+	// Empty captures the whole workspace.
+	Patterns []string
+
+	// MaxBytes caps the total size copied per run. Files that would exceed it
+	// are skipped. Defaults to DefaultArtifactMaxBytes.
+	MaxBytes int64
+}
+
+// WithArtifactCapture copies each run's workspace into capture.Dir after
+// grading. Runs without a workspace (replayed or cached responses) are skipped.
+func WithArtifactCapture(capture ArtifactCapture) RunnerOption {
+	return func(r *TestRunner) {
+		r.artifacts = &capture
+	}
+}
+
+var unsafeArtifactChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// artifactTaskDir names a task's artifact folder after its ID, falling back to its display name.
+func artifactTaskDir(tc *models.TestCase) string {
+	name := tc.TestID
+	if name == "" {
+		name = tc.DisplayName
+	}
+	name = unsafeArtifactChars.ReplaceAllString(strings.TrimSpace(name), "-")
+	if name == "" || name == "." || name == ".." {
+		name = "unnamed"
+	}
+	return name
+}
+
+// captureArtifacts copies workspaceDir into the run's artifact folder and
+// returns that folder. Symlinks and anything that would land outside the
+// folder are skipped.
+func (r *TestRunner) captureArtifacts(tc *models.TestCase, runNum int, workspaceDir string) (string, error) {
+	capture := r.artifacts
+	if capture == nil || capture.Dir == "" || workspaceDir == "" {
+		return "", nil
+	}
+	// Responses served from the cache point at workspaces that no longer exist
+	if _, err := os.Stat(workspaceDir); errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+
+	maxBytes := capture.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultArtifactMaxBytes
+	}
+
+	dest := filepath.Join(capture.Dir, artifactTaskDir(tc), fmt.Sprintf("run-%d", runNum))
+	// A retried run replaces the artifacts of the earlier attempt
+	if err := os.RemoveAll(dest); err != nil {
+		return "", fmt.Errorf("clearing artifact dir: %w", err)
+	}
+
+	var copied int64
+	var skipped []string
+
+	err := filepath.WalkDir(workspaceDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(workspaceDir, path)
+		if err != nil || !filepath.IsLocal(rel) {
+			return nil
+		}
+		if !matchesArtifactPatterns(capture.Patterns, rel) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if copied+info.Size() > maxBytes {
+			skipped = append(skipped, filepath.ToSlash(rel))
+			return nil
+		}
+
+		if err := copyArtifact(path, filepath.Join(dest, rel)); err != nil {
+			return err
+		}
+		copied += info.Size()
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("capturing artifacts: %w", err)
+	}
+
+	if len(skipped) > 0 {
+		r.warnf("artifact size limit (%d bytes) reached for %q run %d; skipped: %s",
+			maxBytes, tc.DisplayName, runNum, strings.Join(skipped, ", "))
+	}
+
+	if copied == 0 {
+		return "", nil
+	}
+	return dest, nil
+}
+
+func matchesArtifactPatterns(patterns []string, rel string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	rel = filepath.ToSlash(rel)
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(p, rel); ok {
+			return true
+		}
+		if !strings.Contains(p, "/") {
+			if ok, _ := filepath.Match(p, filepath.Base(rel)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func copyArtifact(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close() //nolint:errcheck
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,134 @@
+package orchestration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fileWritingEngine wraps the mock engine and writes files into each run's
+// workspace, standing in for an agent that edits code.
+type fileWritingEngine struct {
+	*execution.MockEngine
+	files map[string]string
+}
+
+func (e *fileWritingEngine) Execute(ctx context.Context, req *execution.ExecutionRequest) (*execution.ExecutionResponse, error) {
+	resp, err := e.MockEngine.Execute(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for name, content := range e.files {
+		path := filepath.Join(resp.WorkspaceDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+func runWithArtifactCapture(t *testing.T, files map[string]string, capture ArtifactCapture) *models.EvaluationOutcome {
+	t.Helper()
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "task.yaml"), `id: build-app
+name: Build App
+inputs:
+  prompt: "write the app"
+`)
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "artifacts"},
+		Config: models.Config{
+			TrialsPerTask: 2,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Tasks: []string{"task.yaml"},
+	}
+
+	engine := &fileWritingEngine{MockEngine: execution.NewMockEngine("mock-model"), files: files}
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	outcome, err := NewTestRunner(cfg, engine, WithArtifactCapture(capture)).RunBenchmark(context.Background())
+	require.NoError(t, err)
+	require.Len(t, outcome.TestOutcomes, 1)
+	return outcome
+}
+
+func TestRunBenchmark_CapturesWorkspaceArtifacts(t *testing.T) {
+	artifactsDir := t.TempDir()
+	outcome := runWithArtifactCapture(t, map[string]string{
+		"main.go":         "package main\n",
+		"build/out.log":   "ok\n",
+		"notes/readme.md": "# notes\n",
+	}, ArtifactCapture{Dir: artifactsDir})
+
+	runs := outcome.TestOutcomes[0].Runs
+	require.Len(t, runs, 2)
+	for i, run := range runs {
+		runDir := filepath.Join(artifactsDir, "build-app", []string{"run-1", "run-2"}[i])
+		assert.Equal(t, runDir, run.ArtifactsDir)
+
+		data, err := os.ReadFile(filepath.Join(runDir, "main.go"))
+		require.NoError(t, err)
+		assert.Equal(t, "package main\n", string(data))
+		assert.FileExists(t, filepath.Join(runDir, "build", "out.log"))
+		assert.FileExists(t, filepath.Join(runDir, "notes", "readme.md"))
+	}
+}
+
+func TestRunBenchmark_CaptureArtifactsGlob(t *testing.T) {
+	artifactsDir := t.TempDir()
+	runWithArtifactCapture(t, map[string]string{
+		"main.go":       "package main\n",
+		"pkg/util.go":   "package pkg\n",
+		"build/out.log": "ok\n",
+	}, ArtifactCapture{Dir: artifactsDir, Patterns: []string{"*.go"}})
+
+	runDir := filepath.Join(artifactsDir, "build-app", "run-1")
+	assert.FileExists(t, filepath.Join(runDir, "main.go"))
+	assert.FileExists(t, filepath.Join(runDir, "pkg", "util.go"))
+	assert.NoFileExists(t, filepath.Join(runDir, "build", "out.log"))
+}
+
+func TestRunBenchmark_CaptureArtifactsSizeLimit(t *testing.T) {
+	artifactsDir := t.TempDir()
+	outcome := runWithArtifactCapture(t, map[string]string{
+		"small.txt": "tiny",
+		"large.bin": string(make([]byte, 1024)),
+	}, ArtifactCapture{Dir: artifactsDir, MaxBytes: 100})
+
+	runDir := filepath.Join(artifactsDir, "build-app", "run-1")
+	assert.FileExists(t, filepath.Join(runDir, "small.txt"))
+	assert.NoFileExists(t, filepath.Join(runDir, "large.bin"))
+	require.Len(t, outcome.Warnings, 2, "one warning per run")
+	assert.Contains(t, outcome.Warnings[0], "skipped: large.bin")
+}
+
+func TestCaptureArtifacts_SkipsSymlinks(t *testing.T) {
+	workspace := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	require.NoError(t, os.WriteFile(outside, []byte("secret"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "kept.txt"), []byte("kept"), 0o644))
+	require.NoError(t, os.Symlink(outside, filepath.Join(workspace, "link.txt")))
+
+	artifactsDir := t.TempDir()
+	r := &TestRunner{artifacts: &ArtifactCapture{Dir: artifactsDir}}
+	dir, err := r.captureArtifacts(&models.TestCase{TestID: "../escape"}, 1, workspace)
+	require.NoError(t, err)
+
+	// The task ID can't walk the destination out of the artifacts dir
+	assert.Equal(t, filepath.Join(artifactsDir, "..-escape", "run-1"), dir)
+	assert.FileExists(t, filepath.Join(dir, "kept.txt"))
+	assert.NoFileExists(t, filepath.Join(dir, "link.txt"))
+}
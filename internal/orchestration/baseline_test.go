@@ -2,6 +2,7 @@ package orchestration
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 
 	"github.com/microsoft/waza/internal/config"
@@ -522,3 +523,81 @@ func TestMergeBaselineOutcomes_Success(t *testing.T) {
 	assert.InDelta(t, 0.333, task2.SkillImpact.PassRateBaseline, 0.001)
 	assert.InDelta(t, 0.667, task2.SkillImpact.Delta, 0.001)
 }
+
+func TestRunBenchmark_WithBaselineOutcome(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "task.yaml"), `id: task-001
+name: Task 1
+inputs:
+  prompt: "hello"
+`)
+
+	newRunner := func(baseline *models.EvaluationOutcome) *TestRunner {
+		spec := &models.BenchmarkSpec{
+			SpecIdentity: models.SpecIdentity{Name: "test-eval"},
+			Config: models.Config{
+				EngineType:    "mock",
+				ModelID:       "gpt-4",
+				TrialsPerTask: 1,
+				TimeoutSec:    60,
+			},
+			Tasks: []string{"task.yaml"},
+		}
+		cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+		return NewTestRunner(cfg, execution.NewMockEngine("gpt-4"), WithBaselineOutcome(baseline))
+	}
+	failedBaseline := func(testID string) *models.EvaluationOutcome {
+		return &models.EvaluationOutcome{
+			TestOutcomes: []models.TestOutcome{{
+				TestID: testID,
+				Status: models.StatusFailed,
+				Runs:   []models.RunResult{{RunNumber: 1, Status: models.StatusFailed}},
+			}},
+		}
+	}
+
+	t.Run("merges live run with saved baseline", func(t *testing.T) {
+		baseline := failedBaseline("task-001")
+		outcome, err := newRunner(baseline).RunBenchmark(context.Background())
+		require.NoError(t, err)
+
+		assert.True(t, outcome.IsBaseline)
+		assert.Same(t, baseline, outcome.BaselineOutcome)
+		require.Len(t, outcome.TestOutcomes, 1)
+		impact := outcome.TestOutcomes[0].SkillImpact
+		require.NotNil(t, impact)
+		assert.Equal(t, 1.0, impact.PassRateWithSkills)
+		assert.Equal(t, 0.0, impact.PassRateBaseline)
+	})
+
+	t.Run("uses the baseline half of a saved comparison", func(t *testing.T) {
+		baseline := failedBaseline("task-001")
+		saved := &models.EvaluationOutcome{IsBaseline: true, BaselineOutcome: baseline}
+		outcome, err := newRunner(saved).RunBenchmark(context.Background())
+		require.NoError(t, err)
+		assert.Same(t, baseline, outcome.BaselineOutcome)
+	})
+
+	t.Run("task IDs must align", func(t *testing.T) {
+		_, err := newRunner(failedBaseline("task-999")).RunBenchmark(context.Background())
+		require.ErrorContains(t, err, "baseline mismatch")
+	})
+
+	t.Run("ignores baseline tasks a filtered run didn't select", func(t *testing.T) {
+		baseline := failedBaseline("task-001")
+		baseline.TestOutcomes = append(baseline.TestOutcomes, models.TestOutcome{
+			TestID: "task-002",
+			Status: models.StatusPassed,
+			Runs:   []models.RunResult{{RunNumber: 1, Status: models.StatusPassed}},
+		})
+		baseline.Digest = BuildDigest(baseline.TestOutcomes, 0, 1)
+
+		outcome, err := newRunner(baseline).RunBenchmark(context.Background())
+		require.NoError(t, err)
+		require.Len(t, outcome.BaselineOutcome.TestOutcomes, 1)
+		assert.Equal(t, "task-001", outcome.BaselineOutcome.TestOutcomes[0].TestID)
+		assert.Equal(t, 1, outcome.BaselineOutcome.Digest.TotalTests)
+		assert.Equal(t, 0.0, outcome.BaselineOutcome.Digest.SuccessRate)
+		assert.Len(t, baseline.TestOutcomes, 2, "the loaded baseline is left intact")
+	})
+}
@@ -1,11 +1,14 @@
 package orchestration
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
 	"github.com/microsoft/waza/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -123,3 +126,102 @@ func TestLoadTestCasesFromCSV_PathTraversal(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "escapes spec directory")
 }
+
+func TestRunBenchmark_CSVStreamedWithFilters(t *testing.T) {
+	for _, concurrent := range []bool{false, true} {
+		t.Run(fmt.Sprintf("concurrent=%v", concurrent), func(t *testing.T) {
+			tmpDir := t.TempDir()
+			writeCSV(t, tmpDir, "data.csv", "id,prompt\nkeep-1,one\ndrop-2,two\nkeep-3,three\nkeep-4,four\n")
+
+			spec := &models.BenchmarkSpec{
+				SpecIdentity: models.SpecIdentity{Name: "csv-stream"},
+				TasksFrom:    "data.csv",
+				Config: models.Config{
+					TrialsPerTask: 1,
+					TimeoutSec:    30,
+					EngineType:    "mock",
+					ModelID:       "mock-model",
+					Concurrent:    concurrent,
+					Workers:       2,
+				},
+			}
+			cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+			runner := NewTestRunner(cfg, execution.NewMockEngine("mock-model"), WithTaskFilters("keep-*"))
+
+			var total int
+			runner.OnProgress(func(e ProgressEvent) {
+				if e.EventType == EventBenchmarkStart {
+					total = e.TotalTests
+				}
+			})
+
+			outcome, err := runner.RunBenchmark(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, 3, total)
+			require.Len(t, outcome.TestOutcomes, 3)
+			assert.Equal(t, "keep-1", outcome.TestOutcomes[0].TestID)
+			assert.Equal(t, "keep-3", outcome.TestOutcomes[1].TestID)
+			assert.Equal(t, "keep-4", outcome.TestOutcomes[2].TestID)
+		})
+	}
+}
+
+func TestRunBenchmark_CSVParseErrorBeforeRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeCSV(t, tmpDir, "data.csv", "id,prompt\nA,one\nB\n")
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "csv-bad"},
+		TasksFrom:    "data.csv",
+		Config:       models.Config{TrialsPerTask: 1, TimeoutSec: 30, EngineType: "mock", ModelID: "mock-model"},
+	}
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	runner := NewTestRunner(cfg, execution.NewMockEngine("mock-model"))
+
+	started := false
+	runner.OnProgress(func(e ProgressEvent) {
+		if e.EventType == EventTestStart {
+			started = true
+		}
+	})
+
+	_, err := runner.RunBenchmark(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load test cases")
+	assert.False(t, started, "no task should run when the dataset is malformed")
+}
+
+func TestRunBenchmark_CSVGrowsDuringRun(t *testing.T) {
+	for _, concurrent := range []bool{false, true} {
+		t.Run(fmt.Sprintf("concurrent=%v", concurrent), func(t *testing.T) {
+			tmpDir := t.TempDir()
+			writeCSV(t, tmpDir, "data.csv", "id,prompt\nA,one\nB,two\n")
+
+			spec := &models.BenchmarkSpec{
+				SpecIdentity: models.SpecIdentity{Name: "csv-grows"},
+				TasksFrom:    "data.csv",
+				Config: models.Config{
+					TrialsPerTask: 1,
+					TimeoutSec:    30,
+					EngineType:    "mock",
+					ModelID:       "mock-model",
+					Concurrent:    concurrent,
+					Workers:       2,
+				},
+			}
+			cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+			runner := NewTestRunner(cfg, execution.NewMockEngine("mock-model"))
+
+			// Rows appended after the counting pass must fail the run, not overrun the results
+			runner.OnProgress(func(e ProgressEvent) {
+				if e.EventType == EventBenchmarkStart {
+					writeCSV(t, tmpDir, "data.csv", "id,prompt\nA,one\nB,two\nC,three\n")
+				}
+			})
+
+			_, err := runner.RunBenchmark(context.Background())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "more than the 2 task(s) counted before the run")
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package orchestration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// DifficultyHistory records how often each task has failed in past runs.
+//
+//	{"tasks": {"fix-login": {"failure_rate": 0.8}}}
+type DifficultyHistory struct {
+	Tasks map[string]TaskHistory `json:"tasks"`
+}
+
+// TaskHistory is one task's entry in a DifficultyHistory.
+type TaskHistory struct {
+	// FailureRate is the fraction of past runs that failed, from 0.0 to 1.0.
+	FailureRate float64 `json:"failure_rate"`
+}
+
+// LoadDifficultyWeights reads a difficulty history file and returns a weight
+// per task ID of 1 + failure_rate, so tasks that usually fail count up to
+// twice as much in the weighted aggregate score.
+func LoadDifficultyWeights(path string) (map[string]float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading difficulty history: %w", err)
+	}
+
+	var history DifficultyHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("parsing difficulty history %s: %w", path, err)
+	}
+
+	weights := make(map[string]float64, len(history.Tasks))
+	for id, h := range history.Tasks {
+		if h.FailureRate < 0 || h.FailureRate > 1 {
+			return nil, fmt.Errorf("difficulty history %s: task %q failure_rate %g must be between 0.0 and 1.0", path, id, h.FailureRate)
+		}
+		weights[id] = 1 + h.FailureRate
+	}
+	return weights, nil
+}
+
+// WithDifficultyWeights weights each task's share of the digest's weighted
+// score by taskWeights (see [LoadDifficultyWeights]). Tasks without a weight
+// count as 1.0.
+func WithDifficultyWeights(taskWeights map[string]float64) RunnerOption {
+	return func(r *TestRunner) {
+		r.difficultyWeights = taskWeights
+	}
+}
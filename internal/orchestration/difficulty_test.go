@@ -0,0 +1,102 @@
+package orchestration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeHistory(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "history.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadDifficultyWeights(t *testing.T) {
+	weights, err := LoadDifficultyWeights(writeHistory(t, `{"tasks": {"hard": {"failure_rate": 0.8}, "easy": {"failure_rate": 0}}}`))
+	require.NoError(t, err)
+	assert.InDelta(t, 1.8, weights["hard"], 1e-9)
+	assert.InDelta(t, 1.0, weights["easy"], 1e-9)
+
+	_, err = LoadDifficultyWeights(writeHistory(t, `{"tasks": {"hard": {"failure_rate": 1.5}}}`))
+	require.ErrorContains(t, err, "between 0.0 and 1.0")
+
+	_, err = LoadDifficultyWeights(writeHistory(t, `not json`))
+	require.ErrorContains(t, err, "parsing difficulty history")
+
+	_, err = LoadDifficultyWeights(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorContains(t, err, "reading difficulty history")
+}
+
+func TestComputeWeightedAggregateScore_TaskWeights(t *testing.T) {
+	outcomes := []models.TestOutcome{
+		{TestID: "easy", Stats: &models.TestStats{AvgWeightedScore: 1.0}},
+		{TestID: "hard", Stats: &models.TestStats{AvgWeightedScore: 0.0}},
+		{TestID: "new", Stats: &models.TestStats{AvgWeightedScore: 0.5}},
+	}
+
+	assert.InDelta(t, 0.5, computeWeightedAggregateScore(outcomes, nil), 1e-9)
+	// "new" isn't in the history, so it keeps weight 1.0: (1 + 0*3 + 0.5) / 5
+	assert.InDelta(t, 0.3, computeWeightedAggregateScore(outcomes, map[string]float64{"hard": 3}), 1e-9)
+}
+
+func TestRunBenchmark_DifficultyWeightsShiftWeightedScore(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "easy.yaml"), `id: easy
+name: Easy
+inputs:
+  prompt: "hello"
+`)
+	writeTaskFile(t, filepath.Join(tmpDir, "hard.yaml"), `id: hard
+name: Hard
+inputs:
+  prompt: "hello"
+graders:
+  - name: never
+    type: text
+    config:
+      contains: ["not in the output"]
+`)
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "difficulty"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Graders: []models.GraderConfig{{
+			Kind:       models.GraderKindText,
+			Identifier: "mock",
+			Parameters: models.TextGraderParameters{Contains: []string{"Mock response"}},
+		}},
+		Tasks: []string{"easy.yaml", "hard.yaml"},
+	}
+	run := func(opts ...RunnerOption) *models.EvaluationOutcome {
+		cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+		outcome, err := NewTestRunner(cfg, execution.NewMockEngine("mock-model"), opts...).RunBenchmark(context.Background())
+		require.NoError(t, err)
+		return outcome
+	}
+
+	baseline := run()
+	weights, err := LoadDifficultyWeights(writeHistory(t, `{"tasks": {"hard": {"failure_rate": 1.0}}}`))
+	require.NoError(t, err)
+	weighted := run(WithDifficultyWeights(weights))
+
+	// easy scores 1.0 and hard 0.5 (one of its two graders passes)
+	assert.InDelta(t, 0.75, baseline.Digest.WeightedScore, 1e-9)
+	assert.InDelta(t, (1.0+2*0.5)/3, weighted.Digest.WeightedScore, 1e-9)
+	assert.Equal(t, baseline.Digest.AggregateScore, weighted.Digest.AggregateScore, "only the weighted score uses difficulty weights")
+	assert.Equal(t, true, weighted.Metadata["difficulty_weighted"])
+	assert.NotContains(t, baseline.Metadata, "difficulty_weighted")
+}
@@ -0,0 +1,36 @@
+package orchestration
+
+import (
+	"maps"
+
+	"github.com/microsoft/waza/internal/hooks"
+	"github.com/microsoft/waza/internal/models"
+)
+
+// WithEnvironment runs the benchmark under one config.env_matrix environment:
+// its variables, plus its name as WAZA_ENV, are exported to every hook, and
+// the name is recorded in the outcome's setup. The built-in WAZA_* hook
+// variables take precedence over the environment's own.
+func WithEnvironment(env models.MatrixEnvironment) RunnerOption {
+	return func(r *TestRunner) {
+		r.environment = &env
+	}
+}
+
+// hookEnv returns a fresh map of the environment's hook variables, empty when
+// no environment is set.
+func (r *TestRunner) hookEnv() map[string]string {
+	env := make(map[string]string)
+	if r.environment != nil {
+		maps.Copy(env, r.environment.Env)
+		env[hooks.EnvName] = r.environment.Name
+	}
+	return env
+}
+
+func (r *TestRunner) environmentName() string {
+	if r.environment == nil {
+		return ""
+	}
+	return r.environment.Name
+}
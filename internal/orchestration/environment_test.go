@@ -0,0 +1,61 @@
+package orchestration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/hooks"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBenchmark_WithEnvironment(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "task.yaml"), "id: t1\nname: T1\ninputs:\n  prompt: \"hi\"\n")
+	script := filepath.Join(tmpDir, "hook.sh")
+	require.NoError(t, os.WriteFile(script, []byte(`echo "$WAZA_ENV $FLAG $WAZA_MODEL" > "$WAZA_SPEC_DIR/env.txt"`+"\n"), 0o644))
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "env"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Hooks: hooks.HooksConfig{BeforeRun: []hooks.HookConfig{{Command: "sh " + script}}},
+		Tasks: []string{"task.yaml"},
+	}
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	// The built-in WAZA_MODEL wins over the environment's own value.
+	env := models.MatrixEnvironment{Name: "beta", Env: map[string]string{"FLAG": "on", hooks.EnvModel: "ignored"}}
+	outcome, err := NewTestRunner(cfg, execution.NewMockEngine("mock-model"), WithEnvironment(env)).RunBenchmark(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "beta", outcome.Setup.Environment)
+	got, err := os.ReadFile(filepath.Join(tmpDir, "env.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "beta on mock-model\n", string(got))
+}
+
+func TestRunBenchmark_NoEnvironment(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "task.yaml"), "id: t1\nname: T1\ninputs:\n  prompt: \"hi\"\n")
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "env"},
+		Config:       models.Config{TrialsPerTask: 1, TimeoutSec: 30, EngineType: "mock", ModelID: "mock-model"},
+		Tasks:        []string{"task.yaml"},
+	}
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	runner := NewTestRunner(cfg, execution.NewMockEngine("mock-model"))
+	outcome, err := runner.RunBenchmark(context.Background())
+	require.NoError(t, err)
+
+	assert.Empty(t, outcome.Setup.Environment)
+	assert.NotContains(t, runner.hookRunner.Env, hooks.EnvName)
+}
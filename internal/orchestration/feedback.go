@@ -0,0 +1,48 @@
+package orchestration
+
+import "github.com/microsoft/waza/internal/models"
+
+// truncateGraderFeedback caps each result's Feedback, and every string nested
+// in its Details, at maxBytes (see truncateText). maxBytes <= 0 means no limit.
+// Details maps are copied rather than modified, since they may be shared with
+// the grader result cache.
+func truncateGraderFeedback(results map[string]models.GraderResults, maxBytes int) {
+	if maxBytes <= 0 {
+		return
+	}
+	for name, res := range results {
+		res.Feedback, _ = truncateText(res.Feedback, maxBytes, "feedback")
+		if res.Details != nil {
+			res.Details = truncateDetailValue(res.Details, maxBytes).(map[string]any)
+		}
+		results[name] = res
+	}
+}
+
+func truncateDetailValue(v any, maxBytes int) any {
+	switch v := v.(type) {
+	case string:
+		s, _ := truncateText(v, maxBytes, "feedback")
+		return s
+	case []string:
+		out := make([]string, len(v))
+		for i, s := range v {
+			out[i], _ = truncateText(s, maxBytes, "feedback")
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = truncateDetailValue(e, maxBytes)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = truncateDetailValue(e, maxBytes)
+		}
+		return out
+	default:
+		return v
+	}
+}
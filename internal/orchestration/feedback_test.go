@@ -0,0 +1,85 @@
+package orchestration
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateGraderFeedback(t *testing.T) {
+	details := map[string]any{
+		"diff":     strings.Repeat("d", 20),
+		"failures": []string{"short", strings.Repeat("f", 20)},
+		"nested":   map[string]any{"lines": []any{strings.Repeat("n", 20), 3}},
+		"count":    42,
+	}
+	results := map[string]models.GraderResults{
+		"long":  {Name: "long", Feedback: strings.Repeat("x", 20), Details: details},
+		"short": {Name: "short", Feedback: "ok"},
+	}
+
+	truncateGraderFeedback(results, 10)
+
+	assert.Equal(t, "xxxxxxxxxx\n\n[... feedback truncated: 10 of 20 bytes omitted ...]", results["long"].Feedback)
+	assert.Equal(t, "ok", results["short"].Feedback)
+	got := results["long"].Details
+	assert.True(t, strings.HasPrefix(got["diff"].(string), "dddddddddd\n\n[... feedback truncated"))
+	assert.Equal(t, "short", got["failures"].([]string)[0])
+	assert.Contains(t, got["failures"].([]string)[1], "feedback truncated")
+	lines := got["nested"].(map[string]any)["lines"].([]any)
+	assert.Contains(t, lines[0], "feedback truncated")
+	assert.Equal(t, 3, lines[1])
+	assert.Equal(t, 42, got["count"])
+	assert.Len(t, details["diff"], 20, "the original details map is left untouched")
+
+	results = map[string]models.GraderResults{"long": {Feedback: strings.Repeat("x", 20)}}
+	truncateGraderFeedback(results, 0)
+	assert.Len(t, results["long"].Feedback, 20, "0 means unlimited")
+}
+
+func TestRunBenchmark_MaxFeedbackBytes(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "task.yaml"), `id: long-feedback
+name: Long Feedback
+inputs:
+  prompt: "hello"
+`)
+
+	missing := strings.Repeat("m", 500)
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "max-feedback"},
+		Config: models.Config{
+			TrialsPerTask:    1,
+			TimeoutSec:       30,
+			EngineType:       "mock",
+			ModelID:          "mock-model",
+			MaxFeedbackBytes: 64,
+		},
+		Graders: []models.GraderConfig{{
+			Kind:       models.GraderKindText,
+			Identifier: "wants-missing-text",
+			Parameters: models.TextGraderParameters{Contains: []string{missing}},
+		}},
+		Tasks: []string{"task.yaml"},
+	}
+
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	outcome, err := NewTestRunner(cfg, execution.NewMockEngine("mock-model")).RunBenchmark(context.Background())
+	require.NoError(t, err)
+
+	res := outcome.TestOutcomes[0].Runs[0].Validations["wants-missing-text"]
+	assert.False(t, res.Passed)
+	assert.True(t, strings.HasPrefix(res.Feedback, "Missing expected substring: mmm"), res.Feedback)
+	assert.Contains(t, res.Feedback, "[... feedback truncated: ")
+	assert.NotContains(t, res.Feedback, missing)
+	for _, f := range res.Details["failures"].([]string) {
+		assert.Contains(t, f, "[... feedback truncated: ")
+	}
+}
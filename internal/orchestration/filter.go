@@ -3,13 +3,14 @@ package orchestration
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/microsoft/waza/internal/models"
 )
 
 // FilterTestCases returns the subset of testCases based on whether it matches tags or task display name, or task id glob patterns.
 // - taskPatterns - matches either the task display name or the task ID.
-// - tagPatterns - matches tags.
+// - tagPatterns - matches tags. key:value tags also match on their key or value (see [matchTag]).
 //
 // If taskPatterns and tagPatterns are specified the result is the intersection of the matches between them.
 // If both taskPatterns and tagPatterns are empty, all test cases are returned.
@@ -21,19 +22,13 @@ func FilterTestCases(testCases []*models.TestCase, taskPatterns []string, tagPat
 	var matched []*models.TestCase
 
 	for _, tc := range testCases {
-		taskNameMatch, err := matchesTaskOrDisplayName(tc, taskPatterns)
+		ok, err := matchesFilters(tc, taskPatterns, tagPatterns)
 
 		if err != nil {
 			return nil, err
 		}
 
-		tagNameMatch, err := matchesTags(tc, tagPatterns)
-
-		if err != nil {
-			return nil, err
-		}
-
-		if taskNameMatch && tagNameMatch {
+		if ok {
 			matched = append(matched, tc)
 		}
 	}
@@ -41,6 +36,23 @@ func FilterTestCases(testCases []*models.TestCase, taskPatterns []string, tagPat
 	return matched, nil
 }
 
+// matchesFilters reports whether a single test case passes both the task and tag filters.
+func matchesFilters(tc *models.TestCase, taskPatterns []string, tagPatterns []string) (bool, error) {
+	taskNameMatch, err := matchesTaskOrDisplayName(tc, taskPatterns)
+
+	if err != nil {
+		return false, err
+	}
+
+	tagNameMatch, err := matchesTags(tc, tagPatterns)
+
+	if err != nil {
+		return false, err
+	}
+
+	return taskNameMatch && tagNameMatch, nil
+}
+
 // matchesTaskOrDisplayName reports whether a test case's DisplayName or TestID matches any pattern.
 func matchesTaskOrDisplayName(tc *models.TestCase, patterns []string) (bool, error) {
 	if len(patterns) == 0 {
@@ -71,14 +83,20 @@ func matchesTaskOrDisplayName(tc *models.TestCase, patterns []string) (bool, err
 	return false, nil
 }
 
+// matchesTags reports whether any of a test case's tags matches any pattern.
 func matchesTags(tc *models.TestCase, patterns []string) (bool, error) {
 	if len(patterns) == 0 {
 		return true, nil
 	}
 
-	for _, tag := range tc.Tags {
+	return matchesAnyTag(tc.Tags, patterns)
+}
+
+// matchesAnyTag reports whether any of tags matches any pattern.
+func matchesAnyTag(tags []string, patterns []string) (bool, error) {
+	for _, tag := range tags {
 		for _, p := range patterns {
-			tagMatched, err := filepath.Match(p, tag)
+			tagMatched, err := matchTag(p, tag)
 
 			if err != nil {
 				return false, fmt.Errorf("invalid tag filter pattern %q: %w", p, err)
@@ -92,3 +110,43 @@ func matchesTags(tc *models.TestCase, patterns []string) (bool, error) {
 
 	return false, nil
 }
+
+// matchTag matches a single tag against a glob pattern. Tags of the form
+// key:value are also matched by parts:
+//   - "area" matches "area:sql" (the key alone selects every value)
+//   - "area:*" and "*:p1" glob the key and value separately, so "*" matches
+//     values containing "/" too
+//
+// Plain tags are matched against the whole pattern.
+func matchTag(pattern, tag string) (bool, error) {
+	matched, err := filepath.Match(pattern, tag)
+
+	if err != nil || matched {
+		return matched, err
+	}
+
+	tagKey, tagValue, isKeyValue := strings.Cut(tag, ":")
+
+	if !isKeyValue {
+		return false, nil
+	}
+
+	patternKey, patternValue, hasValue := strings.Cut(pattern, ":")
+
+	if !hasValue {
+		return filepath.Match(pattern, tagKey)
+	}
+
+	if keyMatched, err := matchTagPart(patternKey, tagKey); err != nil || !keyMatched {
+		return false, err
+	}
+
+	return matchTagPart(patternValue, tagValue)
+}
+
+func matchTagPart(pattern, part string) (bool, error) {
+	if pattern == "*" {
+		return true, nil
+	}
+	return filepath.Match(pattern, part)
+}
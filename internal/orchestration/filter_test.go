@@ -118,6 +118,73 @@ func TestFilterTestCases_Tags(t *testing.T) {
 	}
 }
 
+func keyValueTagCases() []*models.TestCase {
+	return []*models.TestCase{
+		{TestID: "tc-001", DisplayName: "Tune SQL index", Tags: []string{"priority:p1", "area:sql"}},
+		{TestID: "tc-002", DisplayName: "Write SQL migration", Tags: []string{"priority:p2", "area:sql/migrations"}},
+		{TestID: "tc-003", DisplayName: "Fix flaky test", Tags: []string{"priority:p1", "fast"}},
+		{TestID: "tc-004", DisplayName: "Document the area", Tags: []string{"area", "docs"}},
+	}
+}
+
+func TestFilterTestCases_KeyValueTags(t *testing.T) {
+	tt := []struct {
+		Name       string
+		Patterns   []string
+		MatchedIDs []string
+	}{
+		{
+			Name:       "exact_key_value",
+			Patterns:   []string{"priority:p1"},
+			MatchedIDs: []string{"tc-001", "tc-003"},
+		},
+		{
+			Name:       "value_wildcard",
+			Patterns:   []string{"area:*"},
+			MatchedIDs: []string{"tc-001", "tc-002"},
+		},
+		{
+			Name:       "key_wildcard",
+			Patterns:   []string{"*:p2"},
+			MatchedIDs: []string{"tc-002"},
+		},
+		{
+			Name:       "value_glob",
+			Patterns:   []string{"priority:p[12]"},
+			MatchedIDs: []string{"tc-001", "tc-002", "tc-003"},
+		},
+		{
+			Name:       "key_only_matches_plain_and_key_value",
+			Patterns:   []string{"area"},
+			MatchedIDs: []string{"tc-001", "tc-002", "tc-004"},
+		},
+		{
+			Name:       "plain_tag",
+			Patterns:   []string{"fast"},
+			MatchedIDs: []string{"tc-003"},
+		},
+		{
+			Name:       "no_match",
+			Patterns:   []string{"priority:p3"},
+			MatchedIDs: nil,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			result, err := FilterTestCases(keyValueTagCases(), nil, tc.Patterns)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.MatchedIDs, testCaseIDs(result))
+		})
+	}
+}
+
+func TestFilterTestCases_KeyValueTags_InvalidPattern(t *testing.T) {
+	_, err := FilterTestCases(keyValueTagCases(), nil, []string{"area:[sql"})
+	require.ErrorContains(t, err, "invalid tag filter pattern")
+}
+
 func TestFilterTestCases_TagsAndTasks_Intersection(t *testing.T) {
 	tt := []struct {
 		Name         string
@@ -0,0 +1,46 @@
+package orchestration
+
+import (
+	"fmt"
+	"iter"
+
+	"github.com/microsoft/waza/internal/models"
+)
+
+// WithFirstN runs only the first n tasks, for a quick sanity check. It's
+// applied after task and tag filters and after shuffling, so with a shuffle
+// it runs a random sample of n tasks. n <= 0 runs every task.
+func WithFirstN(n int) RunnerOption {
+	return func(r *TestRunner) {
+		r.firstN = n
+	}
+}
+
+// limitTestCases applies WithFirstN to the selected test cases and the total
+// reported to progress listeners, printing how many of the total will run.
+func (r *TestRunner) limitTestCases(testCases iter.Seq2[*models.TestCase, error], total int) (iter.Seq2[*models.TestCase, error], int) {
+	if r.firstN <= 0 || total <= r.firstN {
+		return testCases, total
+	}
+	fmt.Fprintf(r.out, "Running the first %d of %d tasks\n", r.firstN, total)
+	return firstN(testCases, r.firstN), r.firstN
+}
+
+// firstN yields at most n test cases from testCases. A read error is passed
+// through without counting toward n.
+func firstN(testCases iter.Seq2[*models.TestCase, error], n int) iter.Seq2[*models.TestCase, error] {
+	return func(yield func(*models.TestCase, error) bool) {
+		yielded := 0
+		for tc, err := range testCases {
+			if yielded >= n {
+				return
+			}
+			if !yield(tc, err) {
+				return
+			}
+			if err == nil {
+				yielded++
+			}
+		}
+	}
+}
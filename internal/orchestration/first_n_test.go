@@ -0,0 +1,87 @@
+package orchestration
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBenchmark_FirstN(t *testing.T) {
+	tmpDir := t.TempDir()
+	for i := range 6 {
+		id := fmt.Sprintf("task-%d", i)
+		tags := "[odd]"
+		if i%2 == 0 {
+			tags = "[even]"
+		}
+		writeTaskFile(t, filepath.Join(tmpDir, id+".yaml"), fmt.Sprintf("id: %s\nname: %s\ntags: %s\ninputs:\n  prompt: \"hi\"\n", id, id, tags))
+	}
+
+	run := func(opts ...RunnerOption) []string {
+		spec := &models.BenchmarkSpec{
+			SpecIdentity: models.SpecIdentity{Name: "first-n"},
+			Config: models.Config{
+				TrialsPerTask: 1,
+				TimeoutSec:    30,
+				EngineType:    "mock",
+				ModelID:       "mock-model",
+			},
+			Tasks: []string{"task-*.yaml"},
+		}
+		cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+		outcome, err := NewTestRunner(cfg, execution.NewMockEngine("mock-model"), opts...).RunBenchmark(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, len(outcome.TestOutcomes), outcome.Digest.TotalTests)
+		var ids []string
+		for _, to := range outcome.TestOutcomes {
+			ids = append(ids, to.TestID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{"task-0", "task-1", "task-2"}, run(WithFirstN(3)))
+	assert.Len(t, run(WithFirstN(100)), 6, "N larger than the suite runs every task")
+	assert.Len(t, run(WithFirstN(0)), 6)
+
+	// Applied after filters
+	assert.Equal(t, []string{"task-1", "task-3"}, run(WithTagFilters("odd"), WithFirstN(2)))
+
+	// Applied after shuffling, so it takes a sample of the shuffled order
+	shuffled := run(WithShuffle(42))
+	assert.Equal(t, shuffled[:2], run(WithShuffle(42), WithFirstN(2)))
+
+	// The notice goes to the runner's output, which --json-stdout discards
+	var out bytes.Buffer
+	run(WithFirstN(2), WithOutput(&out))
+	assert.Contains(t, out.String(), "Running the first 2 of 6 tasks")
+}
+
+func TestFirstN_PassesErrorsThrough(t *testing.T) {
+	readErr := fmt.Errorf("bad row")
+	src := func(yield func(*models.TestCase, error) bool) {
+		_ = yield(&models.TestCase{TestID: "a"}, nil) &&
+			yield(nil, readErr) &&
+			yield(&models.TestCase{TestID: "b"}, nil) &&
+			yield(&models.TestCase{TestID: "c"}, nil)
+	}
+
+	var ids []string
+	var errs []error
+	for tc, err := range firstN(src, 2) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		ids = append(ids, tc.TestID)
+	}
+	assert.Equal(t, []string{"a", "b"}, ids)
+	assert.Equal(t, []error{readErr}, errs)
+}
@@ -0,0 +1,154 @@
+package orchestration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/microsoft/waza/internal/models"
+)
+
+// recordFixtureHashes hashes every file-backed resource tc references and adds
+// it to the runner's manifest. Inline resources have no fixture file and are skipped.
+func (r *TestRunner) recordFixtureHashes(tc *models.TestCase) {
+	fixtureDir, err := r.fixtureDirFor(tc)
+	if err != nil || fixtureDir == "" {
+		return
+	}
+
+	for _, ref := range tc.Stimulus.Resources {
+		if ref.Body != "" || ref.Location == "" {
+			continue
+		}
+		fullPath, err := resolveFixturePath(fixtureDir, ref.Location)
+		if err != nil {
+			continue
+		}
+		sum, err := hashFixtureFile(fullPath)
+		if err != nil {
+			continue
+		}
+
+		r.fixtureMu.Lock()
+		if r.fixtureManifest == nil {
+			r.fixtureManifest = models.FixtureManifest{}
+		}
+		r.fixtureManifest[r.fixtureManifestKey(fullPath)] = sum
+		r.fixtureMu.Unlock()
+	}
+}
+
+// fixtureManifestKey keys a fixture by its path relative to the spec
+// directory, so tasks with different context_dirs don't share an entry for
+// the same Location.
+func (r *TestRunner) fixtureManifestKey(path string) string {
+	specDir, err := filepath.Abs(r.cfg.SpecDir())
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(specDir, abs)
+	if err != nil {
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(rel)
+}
+
+func (r *TestRunner) fixtureManifestSnapshot() models.FixtureManifest {
+	r.fixtureMu.Lock()
+	defer r.fixtureMu.Unlock()
+	return maps.Clone(r.fixtureManifest)
+}
+
+func hashFixtureFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// FixtureDrift describes how a run's fixtures differ from a locked manifest.
+type FixtureDrift struct {
+	// Changed lists fixtures whose content hash differs from the lock.
+	Changed []string
+	// Unlocked lists fixtures used by the run that the lock doesn't know about.
+	Unlocked []string
+}
+
+// HasDrift reports whether any locked fixture changed content.
+func (d FixtureDrift) HasDrift() bool {
+	return len(d.Changed) > 0
+}
+
+// DiffFixtureManifests compares the current manifest against a locked one.
+// Fixtures that are in the lock but weren't used by this run (e.g. because of
+// task filters) are not reported.
+func DiffFixtureManifests(locked, current models.FixtureManifest) FixtureDrift {
+	var drift FixtureDrift
+	for _, path := range slices.Sorted(maps.Keys(current)) {
+		want, ok := locked[path]
+		switch {
+		case !ok:
+			drift.Unlocked = append(drift.Unlocked, path)
+		case want != current[path]:
+			drift.Changed = append(drift.Changed, path)
+		}
+	}
+	return drift
+}
+
+// fixtureLockFile is the on-disk format written by --fixtures-lock.
+type fixtureLockFile struct {
+	Fixtures models.FixtureManifest `json:"fixtures"`
+}
+
+// LoadFixtureLock reads a fixture manifest from path. It accepts either a lock
+// file written by [WriteFixtureLock] or a saved EvaluationOutcome that carries
+// a fixture_manifest, so a prior baseline's results can serve as the lock.
+// Returns (nil, nil) when path doesn't exist.
+func LoadFixtureLock(path string) (models.FixtureManifest, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading fixture lock: %w", err)
+	}
+
+	var doc struct {
+		fixtureLockFile
+		FixtureManifest models.FixtureManifest `json:"fixture_manifest"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing fixture lock %s: %w", path, err)
+	}
+	if doc.Fixtures != nil {
+		return doc.Fixtures, nil
+	}
+	if doc.FixtureManifest != nil {
+		return doc.FixtureManifest, nil
+	}
+	return models.FixtureManifest{}, nil
+}
+
+// WriteFixtureLock writes manifest to path as a fixture lock file.
+func WriteFixtureLock(path string, manifest models.FixtureManifest) error {
+	if manifest == nil {
+		manifest = models.FixtureManifest{}
+	}
+	data, err := json.MarshalIndent(fixtureLockFile{Fixtures: manifest}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,158 @@
+package orchestration
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runWithFixtureManifest(t *testing.T, specDir, fixtureDir string) *models.EvaluationOutcome {
+	t.Helper()
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "fixtures"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Tasks: []string{"task.yaml"},
+	}
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(specDir), config.WithFixtureDir(fixtureDir))
+	runner := NewTestRunner(cfg, execution.NewMockEngine("mock-model"), WithFixtureManifest())
+
+	outcome, err := runner.RunBenchmark(context.Background())
+	require.NoError(t, err)
+	return outcome
+}
+
+func TestFixtureManifest_DetectsChangedFixture(t *testing.T) {
+	specDir := t.TempDir()
+	fixtureDir := filepath.Join(specDir, "fixtures")
+	require.NoError(t, os.MkdirAll(fixtureDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(fixtureDir, "app.py"), []byte("print('v1')\n"), 0o644))
+
+	writeTaskFile(t, filepath.Join(specDir, "task.yaml"), `id: t1
+name: Task 1
+inputs:
+  prompt: "review app.py"
+  files:
+    - path: app.py
+    - path: inline.txt
+      content: "inline resources are not fixtures"
+`)
+
+	before := runWithFixtureManifest(t, specDir, fixtureDir)
+	sum := sha256.Sum256([]byte("print('v1')\n"))
+	require.Equal(t, models.FixtureManifest{"fixtures/app.py": hex.EncodeToString(sum[:])}, before.FixtureManifest)
+
+	// unchanged fixtures → no drift
+	again := runWithFixtureManifest(t, specDir, fixtureDir)
+	assert.False(t, DiffFixtureManifests(before.FixtureManifest, again.FixtureManifest).HasDrift())
+
+	require.NoError(t, os.WriteFile(filepath.Join(fixtureDir, "app.py"), []byte("print('v2')\n"), 0o644))
+	after := runWithFixtureManifest(t, specDir, fixtureDir)
+
+	drift := DiffFixtureManifests(before.FixtureManifest, after.FixtureManifest)
+	assert.True(t, drift.HasDrift())
+	assert.Equal(t, []string{"fixtures/app.py"}, drift.Changed)
+}
+
+func TestFixtureManifest_KeysByContextDir(t *testing.T) {
+	specDir := t.TempDir()
+	for _, dir := range []string{"a", "b"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(specDir, dir), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(specDir, dir, "app.py"), []byte("print('"+dir+"')\n"), 0o644))
+	}
+	// Both tasks reference app.py, each from its own context_dir
+	writeTaskFile(t, filepath.Join(specDir, "task.yaml"), `id: t1
+name: Task 1
+context_dir: `+filepath.Join(specDir, "a")+`
+inputs:
+  prompt: "review app.py"
+  files:
+    - path: app.py
+`)
+	writeTaskFile(t, filepath.Join(specDir, "task2.yaml"), `id: t2
+name: Task 2
+context_dir: `+filepath.Join(specDir, "b")+`
+inputs:
+  prompt: "review app.py"
+  files:
+    - path: app.py
+`)
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "fixtures"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Tasks: []string{"task.yaml", "task2.yaml"},
+	}
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(specDir), config.WithFixtureDir(specDir))
+	outcome, err := NewTestRunner(cfg, execution.NewMockEngine("mock-model"), WithFixtureManifest()).RunBenchmark(context.Background())
+	require.NoError(t, err)
+
+	sumA := sha256.Sum256([]byte("print('a')\n"))
+	sumB := sha256.Sum256([]byte("print('b')\n"))
+	assert.Equal(t, models.FixtureManifest{
+		"a/app.py": hex.EncodeToString(sumA[:]),
+		"b/app.py": hex.EncodeToString(sumB[:]),
+	}, outcome.FixtureManifest)
+}
+
+func TestDiffFixtureManifests(t *testing.T) {
+	locked := models.FixtureManifest{"a.txt": "1", "b.txt": "2", "filtered-out.txt": "3"}
+	current := models.FixtureManifest{"a.txt": "1", "b.txt": "changed", "new.txt": "4"}
+
+	drift := DiffFixtureManifests(locked, current)
+	assert.Equal(t, []string{"b.txt"}, drift.Changed)
+	assert.Equal(t, []string{"new.txt"}, drift.Unlocked)
+	assert.True(t, drift.HasDrift())
+
+	assert.False(t, DiffFixtureManifests(locked, models.FixtureManifest{"a.txt": "1"}).HasDrift())
+}
+
+func TestFixtureLock_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "fixtures.lock.json")
+
+	missing, err := LoadFixtureLock(path)
+	require.NoError(t, err)
+	assert.Nil(t, missing, "a missing lock reads as nil so callers can create it")
+
+	manifest := models.FixtureManifest{"app.py": "abc"}
+	require.NoError(t, WriteFixtureLock(path, manifest))
+
+	loaded, err := LoadFixtureLock(path)
+	require.NoError(t, err)
+	assert.Equal(t, manifest, loaded)
+}
+
+func TestFixtureLock_FromPriorOutcome(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	data, err := json.Marshal(&models.EvaluationOutcome{
+		BenchName:       "prior",
+		FixtureManifest: models.FixtureManifest{"app.py": "abc"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	loaded, err := LoadFixtureLock(path)
+	require.NoError(t, err)
+	assert.Equal(t, models.FixtureManifest{"app.py": "abc"}, loaded)
+}
@@ -0,0 +1,98 @@
+package orchestration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/microsoft/waza/internal/models"
+)
+
+// GraderReliabilityHistory records how trustworthy each grader has been in
+// past runs, e.g. how often a judge agreed with itself on re-grading.
+//
+//	{"graders": {"tone-judge": {"reliability": 0.6}}}
+type GraderReliabilityHistory struct {
+	Graders map[string]GraderHistory `json:"graders"`
+}
+
+// GraderHistory is one grader's entry in a GraderReliabilityHistory.
+type GraderHistory struct {
+	// Reliability scales the grader's weight, from just above 0.0 to 1.0.
+	Reliability float64 `json:"reliability"`
+}
+
+// LoadGraderReliability reads a grader reliability history file and returns
+// a weight factor per grader name.
+func LoadGraderReliability(path string) (map[string]float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading grader reliability history: %w", err)
+	}
+
+	var history GraderReliabilityHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("parsing grader reliability history %s: %w", path, err)
+	}
+
+	factors := make(map[string]float64, len(history.Graders))
+	for name, h := range history.Graders {
+		// A zero weight would be read as "unset" and count as 1.0, so it's rejected
+		if h.Reliability <= 0 || h.Reliability > 1 {
+			return nil, fmt.Errorf("grader reliability history %s: grader %q reliability %g must be greater than 0.0 and at most 1.0", path, name, h.Reliability)
+		}
+		factors[name] = h.Reliability
+	}
+	return factors, nil
+}
+
+// WithGraderReliability multiplies each grader's weight by its reliability
+// factor (see [LoadGraderReliability]), so historically noisy graders count
+// less in weighted run scores. Graders without a factor keep their weight.
+func WithGraderReliability(factors map[string]float64) RunnerOption {
+	return func(r *TestRunner) {
+		r.graderReliability = factors
+	}
+}
+
+// reliabilityFactorsFor returns the factors that apply to the graders of spec
+// and tc, keyed by grader name, or nil when none do.
+func reliabilityFactorsFor(spec *models.BenchmarkSpec, tc *models.TestCase, factors map[string]float64) map[string]float64 {
+	var applied map[string]float64
+	add := func(name string) {
+		if factor, ok := factors[name]; ok {
+			if applied == nil {
+				applied = make(map[string]float64)
+			}
+			applied[name] = factor
+		}
+	}
+	for _, g := range spec.Graders {
+		add(g.Identifier)
+	}
+	for _, v := range tc.Validators {
+		add(v.Identifier)
+	}
+	return applied
+}
+
+// applyGraderReliability scales the weight of each result that has a
+// reliability factor and records the factor in its details.
+func applyGraderReliability(results map[string]models.GraderResults, factors map[string]float64) {
+	for name, res := range results {
+		factor, ok := factors[name]
+		if !ok {
+			continue
+		}
+		weight := res.Weight
+		if weight <= 0 {
+			weight = 1.0
+		}
+		res.Weight = weight * factor
+		if res.Details == nil {
+			res.Details = make(map[string]any)
+		}
+		res.Details["reliability"] = factor
+		results[name] = res
+	}
+}
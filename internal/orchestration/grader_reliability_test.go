@@ -0,0 +1,142 @@
+package orchestration
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/microsoft/waza/internal/cache"
+	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadGraderReliability(t *testing.T) {
+	factors, err := LoadGraderReliability(writeHistory(t, `{"graders": {"judge": {"reliability": 0.6}, "regex": {"reliability": 1}}}`))
+	require.NoError(t, err)
+	assert.InDelta(t, 0.6, factors["judge"], 1e-9)
+	assert.InDelta(t, 1.0, factors["regex"], 1e-9)
+
+	_, err = LoadGraderReliability(writeHistory(t, `{"graders": {"judge": {"reliability": 0}}}`))
+	require.ErrorContains(t, err, "greater than 0.0 and at most 1.0")
+
+	_, err = LoadGraderReliability(writeHistory(t, `{"graders": {"judge": {"reliability": 1.2}}}`))
+	require.ErrorContains(t, err, "greater than 0.0 and at most 1.0")
+
+	_, err = LoadGraderReliability(writeHistory(t, `not json`))
+	require.ErrorContains(t, err, "parsing grader reliability history")
+
+	_, err = LoadGraderReliability(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorContains(t, err, "reading grader reliability history")
+}
+
+func TestApplyGraderReliability(t *testing.T) {
+	results := map[string]models.GraderResults{
+		"weighted":   {Name: "weighted", Weight: 2},
+		"unweighted": {Name: "unweighted"},
+		"absent":     {Name: "absent", Weight: 3},
+	}
+	applyGraderReliability(results, map[string]float64{"weighted": 0.5, "unweighted": 0.25})
+
+	assert.InDelta(t, 1.0, results["weighted"].Weight, 1e-9)
+	assert.InDelta(t, 0.25, results["unweighted"].Weight, 1e-9, "an unset weight counts as 1.0 before scaling")
+	assert.InDelta(t, 3.0, results["absent"].Weight, 1e-9, "graders without a factor keep their weight")
+	assert.Equal(t, 0.5, results["weighted"].Details["reliability"])
+	assert.Nil(t, results["absent"].Details)
+}
+
+func TestRunBenchmark_GraderReliabilityScalesWeights(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "task.yaml"), `id: task
+name: Task
+inputs:
+  prompt: "hello"
+graders:
+  - name: noisy
+    type: text
+    config:
+      contains: ["not in the output"]
+`)
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "reliability"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Graders: []models.GraderConfig{{
+			Kind:       models.GraderKindText,
+			Identifier: "steady",
+			Parameters: models.TextGraderParameters{Contains: []string{"Mock response"}},
+		}},
+		Tasks: []string{"task.yaml"},
+	}
+	run := func(opts ...RunnerOption) *models.EvaluationOutcome {
+		cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+		outcome, err := NewTestRunner(cfg, execution.NewMockEngine("mock-model"), opts...).RunBenchmark(context.Background())
+		require.NoError(t, err)
+		return outcome
+	}
+
+	baseline := run()
+	factors, err := LoadGraderReliability(writeHistory(t, `{"graders": {"noisy": {"reliability": 0.25}}}`))
+	require.NoError(t, err)
+	weighted := run(WithGraderReliability(factors))
+
+	validations := weighted.TestOutcomes[0].Runs[0].Validations
+	assert.InDelta(t, 0.25, validations["noisy"].Weight, 1e-9)
+	assert.InDelta(t, 1.0, validations["steady"].Weight, 1e-9)
+
+	// steady scores 1.0 and noisy 0.0: (1 + 0) / 2 unweighted, (1 + 0*0.25) / 1.25 weighted
+	assert.InDelta(t, 0.5, baseline.TestOutcomes[0].Stats.AvgWeightedScore, 1e-9)
+	assert.InDelta(t, 0.8, weighted.TestOutcomes[0].Stats.AvgWeightedScore, 1e-9)
+	assert.Equal(t, true, weighted.Metadata["grader_reliability_weighted"])
+	assert.NotContains(t, baseline.Metadata, "grader_reliability_weighted")
+}
+
+func TestRunBenchmark_GraderReliabilityCacheKey(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "task.yaml"), `id: task
+name: Task
+inputs:
+  prompt: "hello"
+`)
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "reliability-cache"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Graders: []models.GraderConfig{{
+			Kind:       models.GraderKindText,
+			Identifier: "judge",
+			Parameters: models.TextGraderParameters{Contains: []string{"Mock response"}},
+		}},
+		Tasks: []string{"task.yaml"},
+	}
+	cacheDir := t.TempDir()
+	run := func(opts ...RunnerOption) *models.EvaluationOutcome {
+		cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+		opts = append(opts, WithCache(cache.New(cacheDir)))
+		outcome, err := NewTestRunner(cfg, execution.NewMockEngine("mock-model"), opts...).RunBenchmark(context.Background())
+		require.NoError(t, err)
+		return outcome
+	}
+	weight := func(o *models.EvaluationOutcome) float64 {
+		return o.TestOutcomes[0].Runs[0].Validations["judge"].Weight
+	}
+
+	// Changing the reliability history must not serve outcomes weighted by the old one
+	assert.InDelta(t, 1.0, weight(run()), 1e-9)
+	assert.InDelta(t, 0.5, weight(run(WithGraderReliability(map[string]float64{"judge": 0.5}))), 1e-9)
+	assert.InDelta(t, 0.25, weight(run(WithGraderReliability(map[string]float64{"judge": 0.25}))), 1e-9)
+
+	// Factors for graders the task doesn't run leave the key alone
+	assert.InDelta(t, 1.0, weight(run(WithGraderReliability(map[string]float64{"other": 0.5}))), 1e-9)
+}
@@ -0,0 +1,84 @@
+package orchestration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/microsoft/waza/internal/cache"
+	"github.com/microsoft/waza/internal/models"
+)
+
+// WithGraderTags runs only the graders (eval-level and task-level) whose tags
+// match one of the glob patterns, using the same matching as task tag filters
+// (see [matchTag]). Other graders, including untagged ones, are skipped and
+// don't count toward scores. No patterns runs every grader.
+func WithGraderTags(patterns ...string) RunnerOption {
+	return func(r *TestRunner) {
+		r.graderTags = patterns
+	}
+}
+
+// selectGraders returns spec and tc with their graders narrowed to those
+// selected by the runner's grader tags. spec and tc are returned unchanged
+// without grader tags; otherwise they are shallow copies.
+func (r *TestRunner) selectGraders(spec *models.BenchmarkSpec, tc *models.TestCase) (*models.BenchmarkSpec, *models.TestCase, error) {
+	if len(r.graderTags) == 0 {
+		return spec, tc, nil
+	}
+
+	specGraders, err := filterByGraderTags(spec.Graders, func(g models.GraderConfig) []string { return g.Tags }, r.graderTags)
+	if err != nil {
+		return nil, nil, err
+	}
+	taskGraders, err := filterByGraderTags(tc.Validators, func(v models.ValidatorInline) []string { return v.Tags }, r.graderTags)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	specCopy, tcCopy := *spec, *tc
+	specCopy.Graders = specGraders
+	tcCopy.Validators = taskGraders
+	return &specCopy, &tcCopy, nil
+}
+
+// filterByGraderTags keeps the graders with a tag matching any pattern.
+func filterByGraderTags[T any](graders []T, tagsOf func(T) []string, patterns []string) ([]T, error) {
+	var selected []T
+	for _, g := range graders {
+		ok, err := matchesAnyTag(tagsOf(g), patterns)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			selected = append(selected, g)
+		}
+	}
+	return selected, nil
+}
+
+// outcomeCacheKey is the whole-task cache key for tc, covering only the
+// graders that will run and the reliability factors that scale their weights.
+func (r *TestRunner) outcomeCacheKey(spec *models.BenchmarkSpec, tc *models.TestCase) (string, error) {
+	spec, tc, err := r.selectGraders(spec, tc)
+	if err != nil {
+		return "", err
+	}
+	key, err := cache.CacheKey(spec, tc, r.cfg.FixtureDir())
+	if err != nil {
+		return "", err
+	}
+
+	// Only hashed when factors apply, so existing entries stay valid
+	factors := reliabilityFactorsFor(spec, tc, r.graderReliability)
+	if len(factors) == 0 {
+		return key, nil
+	}
+	factorsJSON, err := json.Marshal(factors)
+	if err != nil {
+		return "", fmt.Errorf("marshaling grader reliability: %w", err)
+	}
+	sum := sha256.Sum256([]byte(key + "grader_reliability:" + string(factorsJSON)))
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,141 @@
+package orchestration
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/microsoft/waza/internal/cache"
+	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBenchmark_GraderTags(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "task.yaml"), `id: task
+name: Task
+inputs:
+  prompt: "hello"
+graders:
+  - name: task-fast
+    type: text
+    tags: [fast]
+    config:
+      contains: ["Mock response"]
+  - name: task-thorough
+    type: text
+    tags: [thorough]
+    config:
+      contains: ["not in the output"]
+`)
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "grader-tags"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Graders: []models.GraderConfig{
+			{
+				Kind:       models.GraderKindText,
+				Identifier: "eval-fast",
+				Tags:       []string{"fast", "speed:low"},
+				Parameters: models.TextGraderParameters{Contains: []string{"Mock response"}},
+			},
+			{
+				Kind:       models.GraderKindText,
+				Identifier: "eval-thorough",
+				Tags:       []string{"thorough"},
+				Parameters: models.TextGraderParameters{Contains: []string{"not in the output"}},
+			},
+			{
+				Kind:       models.GraderKindText,
+				Identifier: "untagged",
+				Parameters: models.TextGraderParameters{Contains: []string{"not in the output"}},
+			},
+		},
+		Tasks: []string{"task.yaml"},
+	}
+	run := func(opts ...RunnerOption) *models.EvaluationOutcome {
+		cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+		outcome, err := NewTestRunner(cfg, execution.NewMockEngine("mock-model"), opts...).RunBenchmark(context.Background())
+		require.NoError(t, err)
+		return outcome
+	}
+	graderNames := func(o *models.EvaluationOutcome) []string {
+		var names []string
+		for name := range o.TestOutcomes[0].Runs[0].Validations {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	all := run()
+	assert.Equal(t, []string{"eval-fast", "eval-thorough", "task-fast", "task-thorough", "untagged"}, graderNames(all))
+	assert.Equal(t, models.StatusFailed, all.TestOutcomes[0].Status)
+
+	fast := run(WithGraderTags("fast"))
+	assert.Equal(t, []string{"eval-fast", "task-fast"}, graderNames(fast))
+	assert.Equal(t, models.StatusPassed, fast.TestOutcomes[0].Status, "skipped graders don't count toward the result")
+	assert.InDelta(t, 1.0, fast.TestOutcomes[0].Stats.AvgScore, 1e-9)
+
+	thorough := run(WithGraderTags("thor*"))
+	assert.Equal(t, []string{"eval-thorough", "task-thorough"}, graderNames(thorough))
+
+	keyed := run(WithGraderTags("speed"))
+	assert.Equal(t, []string{"eval-fast"}, graderNames(keyed), "key:value tags match on their key")
+}
+
+func TestRunBenchmark_GraderTagsCacheKey(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "task.yaml"), `id: task
+name: Task
+inputs:
+  prompt: "hello"
+`)
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "grader-tags-cache"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Graders: []models.GraderConfig{
+			{
+				Kind:       models.GraderKindText,
+				Identifier: "fast",
+				Tags:       []string{"fast"},
+				Parameters: models.TextGraderParameters{Contains: []string{"Mock response"}},
+			},
+			{
+				Kind:       models.GraderKindText,
+				Identifier: "thorough",
+				Tags:       []string{"thorough"},
+				Parameters: models.TextGraderParameters{Contains: []string{"Mock response"}},
+			},
+		},
+		Tasks: []string{"task.yaml"},
+	}
+	cacheDir := t.TempDir()
+	run := func(opts ...RunnerOption) *models.EvaluationOutcome {
+		cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+		opts = append(opts, WithCache(cache.New(cacheDir)))
+		outcome, err := NewTestRunner(cfg, execution.NewMockEngine("mock-model"), opts...).RunBenchmark(context.Background())
+		require.NoError(t, err)
+		return outcome
+	}
+
+	// A full run must not serve a later --grader-tags run from the outcome cache
+	require.Len(t, run().TestOutcomes[0].Runs[0].Validations, 2)
+	fast := run(WithGraderTags("fast"))
+	require.Len(t, fast.TestOutcomes[0].Runs[0].Validations, 1)
+	assert.Contains(t, fast.TestOutcomes[0].Runs[0].Validations, "fast")
+}
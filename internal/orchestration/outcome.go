@@ -1,14 +1,24 @@
 package orchestration
 
 import (
+	"cmp"
 	"math"
+	"slices"
+	"sort"
 
+	"github.com/microsoft/waza/internal/cache"
 	"github.com/microsoft/waza/internal/models"
 	"github.com/microsoft/waza/internal/statistics"
 )
 
 // ComputeTestStats computes aggregate statistics for a set of run results.
-func ComputeTestStats(runs []models.RunResult) *models.TestStats {
+// Weighted scores are computed with mode so they match the run-level scores.
+// When trimPercent is above zero, that percentage of runs is dropped from each
+// end of the score range before the mean, standard deviation, and confidence
+// interval are computed; pass counts and min/max still cover every run.
+// The confidence interval and significance are left nil unless at least
+// minRunsForCI runs remain (models.DefaultMinRunsForCI when zero).
+func ComputeTestStats(runs []models.RunResult, mode models.WeightMode, trimPercent float64, minRunsForCI int) *models.TestStats {
 	if len(runs) == 0 {
 		return nil
 	}
@@ -16,19 +26,12 @@ func ComputeTestStats(runs []models.RunResult) *models.TestStats {
 	passed := 0
 	failed := 0
 	errored := 0
-	totalScore := 0.0
-	totalWeightedScore := 0.0
 	minScore := math.Inf(1)
 	maxScore := math.Inf(-1)
 	totalDuration := int64(0)
-	scores := make([]float64, 0, len(runs))
 
 	for _, run := range runs {
 		score := run.ComputeRunScore()
-		weightedScore := run.ComputeWeightedRunScore()
-		totalScore += score
-		totalWeightedScore += weightedScore
-		scores = append(scores, score)
 
 		if score < minScore {
 			minScore = score
@@ -57,6 +60,17 @@ func ComputeTestStats(runs []models.RunResult) *models.TestStats {
 		totalDuration += run.DurationMs
 	}
 
+	scored := trimOutlierRuns(runs, trimPercent)
+	totalScore := 0.0
+	totalWeightedScore := 0.0
+	scores := make([]float64, 0, len(scored))
+	for _, run := range scored {
+		score := run.ComputeRunScore()
+		totalScore += score
+		totalWeightedScore += run.ComputeWeightedRunScore(mode)
+		scores = append(scores, score)
+	}
+
 	stdDev := models.ComputeStdDev(scores)
 
 	stats := &models.TestStats{
@@ -65,8 +79,9 @@ func ComputeTestStats(runs []models.RunResult) *models.TestStats {
 		FailedRuns:       failed,
 		ErrorRuns:        errored,
 		TotalRuns:        len(runs),
-		AvgScore:         totalScore / float64(len(runs)),
-		AvgWeightedScore: totalWeightedScore / float64(len(runs)),
+		TrimmedRuns:      len(runs) - len(scored),
+		AvgScore:         totalScore / float64(len(scored)),
+		AvgWeightedScore: totalWeightedScore / float64(len(scored)),
 		MinScore:         minScore,
 		MaxScore:         maxScore,
 		StdDevScore:      stdDev,
@@ -79,11 +94,15 @@ func ComputeTestStats(runs []models.RunResult) *models.TestStats {
 		minorityOutcomes := min(passed, len(runs)-passed)
 		stats.FlakinessPercent = (float64(minorityOutcomes) / float64(len(runs))) * 100
 	}
+	stats.StabilityScore = stabilityScore(stats.FlakinessPercent, stdDev)
 
-	if len(runs) >= 2 {
-		weightedScores := make([]float64, 0, len(runs))
-		for _, run := range runs {
-			weightedScores = append(weightedScores, run.ComputeWeightedRunScore())
+	if minRunsForCI <= 0 {
+		minRunsForCI = models.DefaultMinRunsForCI
+	}
+	if len(scored) >= max(minRunsForCI, 2) {
+		weightedScores := make([]float64, 0, len(scored))
+		for _, run := range scored {
+			weightedScores = append(weightedScores, run.ComputeWeightedRunScore(mode))
 		}
 
 		ci := statistics.BootstrapCI(weightedScores, 0.95)
@@ -98,6 +117,37 @@ func ComputeTestStats(runs []models.RunResult) *models.TestStats {
 	return stats
 }
 
+// stabilityScore combines pass/fail agreement and score spread into a single
+// 0.0-1.0 value. Agreement is 1.0 when every run had the same outcome and 0.0
+// at an even split; consistency is 1.0 for identical scores and 0.0 at the
+// largest possible spread of [0, 1] scores (a population std dev of 0.5).
+// The result is their mean.
+func stabilityScore(flakinessPercent, stdDev float64) float64 {
+	agreement := 1 - flakinessPercent/50
+	consistency := max(0, 1-2*stdDev)
+	return (agreement + consistency) / 2
+}
+
+// trimOutlierRuns drops trimPercent of runs from both the lowest and highest
+// scores, rounding down and always keeping at least one run. It returns runs
+// unchanged when trimPercent isn't positive or there is nothing to drop.
+func trimOutlierRuns(runs []models.RunResult, trimPercent float64) []models.RunResult {
+	if trimPercent <= 0 {
+		return runs
+	}
+	k := int(float64(len(runs)) * trimPercent / 100)
+	k = min(k, (len(runs)-1)/2)
+	if k == 0 {
+		return runs
+	}
+
+	sorted := slices.Clone(runs)
+	slices.SortStableFunc(sorted, func(a, b models.RunResult) int {
+		return cmp.Compare(a.ComputeRunScore(), b.ComputeRunScore())
+	})
+	return sorted[k : len(sorted)-k]
+}
+
 // BuildDigest computes an OutcomeDigest from test outcomes. durationMs is
 // the total wall-clock duration to store in the digest. runsPerTest controls
 // whether digest-level bootstrap CI is computed (requires > 1).
@@ -106,8 +156,17 @@ func BuildDigest(testOutcomes []models.TestOutcome, durationMs int64, runsPerTes
 	failed := 0
 	errors := 0
 	skipped := 0
+	xfailed := 0
+	xpassed := 0
 
 	for _, to := range testOutcomes {
+		if to.XFailed() {
+			xfailed++
+			continue
+		}
+		if to.XPassed() {
+			xpassed++
+		}
 		switch to.Status {
 		case models.StatusPassed:
 			succeeded++
@@ -127,7 +186,7 @@ func BuildDigest(testOutcomes []models.TestOutcome, durationMs int64, runsPerTes
 	}
 
 	aggregateScore := computeAggregateScore(testOutcomes)
-	weightedScore := computeWeightedAggregateScore(testOutcomes)
+	weightedScore := computeWeightedAggregateScore(testOutcomes, nil)
 	digestMin, digestMax, digestStdDev := computeDigestScoreStats(testOutcomes)
 	groupStats := computeGroupStats(testOutcomes)
 
@@ -137,6 +196,8 @@ func BuildDigest(testOutcomes []models.TestOutcome, durationMs int64, runsPerTes
 		Failed:         failed,
 		Errors:         errors,
 		Skipped:        skipped,
+		XFailed:        xfailed,
+		XPassed:        xpassed,
 		SuccessRate:    successRate,
 		AggregateScore: aggregateScore,
 		WeightedScore:  weightedScore,
@@ -145,9 +206,12 @@ func BuildDigest(testOutcomes []models.TestOutcome, durationMs int64, runsPerTes
 		StdDev:         digestStdDev,
 		DurationMs:     durationMs,
 		Groups:         groupStats,
+		Tags:           computeTagStats(testOutcomes),
 		Usage:          aggregateUsageFromOutcomes(testOutcomes),
 	}
 
+	digest.Stability = computeSuiteStability(testOutcomes)
+
 	if runsPerTest > 1 && len(testOutcomes) > 0 {
 		perTestScores := make([]float64, 0, len(testOutcomes))
 		for _, to := range testOutcomes {
@@ -168,6 +232,24 @@ func BuildDigest(testOutcomes []models.TestOutcome, durationMs int64, runsPerTes
 	return digest
 }
 
+// computeSuiteStability averages StabilityScore over tasks that ran more than
+// once. It returns nil when there are none.
+func computeSuiteStability(testOutcomes []models.TestOutcome) *float64 {
+	total := 0.0
+	n := 0
+	for _, to := range testOutcomes {
+		if to.Stats != nil && to.Stats.TotalRuns > 1 {
+			total += to.Stats.StabilityScore
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	stability := total / float64(n)
+	return &stability
+}
+
 func computeAggregateScore(testOutcomes []models.TestOutcome) float64 {
 	if len(testOutcomes) == 0 {
 		return 0.0
@@ -181,17 +263,88 @@ func computeAggregateScore(testOutcomes []models.TestOutcome) float64 {
 	return totalScore / float64(len(testOutcomes))
 }
 
-func computeWeightedAggregateScore(testOutcomes []models.TestOutcome) float64 {
+// computeTrialWeightedAggregateScore averages each task's score weighted by the
+// number of runs it completed, so a task run five times counts five times as
+// much as one run once. Tasks without stats contribute nothing.
+func computeTrialWeightedAggregateScore(testOutcomes []models.TestOutcome) float64 {
+	totalScore := 0.0
+	totalRuns := 0
+	for _, to := range testOutcomes {
+		if to.Stats == nil {
+			continue
+		}
+		totalScore += to.Stats.AvgScore * float64(to.Stats.TotalRuns)
+		totalRuns += to.Stats.TotalRuns
+	}
+	if totalRuns == 0 {
+		return 0.0
+	}
+	return totalScore / float64(totalRuns)
+}
+
+// computeAggregateScoreBy combines each task's average score with method.
+// Like [computeAggregateScore], tasks without stats count as 0.
+func computeAggregateScoreBy(testOutcomes []models.TestOutcome, method models.AggregateMethod) float64 {
+	if method.OrDefault() == models.AggregateMethodMean || len(testOutcomes) == 0 {
+		return computeAggregateScore(testOutcomes)
+	}
+	scores := make([]float64, len(testOutcomes))
+	for i, to := range testOutcomes {
+		if to.Stats != nil {
+			scores[i] = to.Stats.AvgScore
+		}
+	}
+	slices.Sort(scores)
+	n := len(scores)
+	switch method {
+	case models.AggregateMethodMin:
+		return scores[0]
+	case models.AggregateMethodP90:
+		// Nearest rank: the lowest score at or above 90% of the tasks
+		return scores[int(math.Ceil(0.9*float64(n)))-1]
+	default:
+		if n%2 == 1 {
+			return scores[n/2]
+		}
+		return (scores[n/2-1] + scores[n/2]) / 2
+	}
+}
+
+// ApplyTriggerWeight folds the outcome's trigger F1 score into its aggregate
+// score as (1-weight)*aggregate + weight*F1 and records the weight in the
+// setup. Outcomes without trigger metrics or tasks, or a weight of 0, are left
+// unchanged.
+func ApplyTriggerWeight(outcome *models.EvaluationOutcome, weight float64) {
+	if weight <= 0 || outcome.TriggerMetrics == nil || outcome.Digest.TotalTests == 0 {
+		return
+	}
+	outcome.Digest.AggregateScore = (1-weight)*outcome.Digest.AggregateScore + weight*outcome.TriggerMetrics.F1
+	outcome.Setup.TriggerWeight = weight
+}
+
+// computeWeightedAggregateScore averages each task's weighted score. taskWeights,
+// keyed by task ID, scales each task's share of the average; tasks without an
+// entry (or all tasks, when nil) count with weight 1.0.
+func computeWeightedAggregateScore(testOutcomes []models.TestOutcome, taskWeights map[string]float64) float64 {
 	if len(testOutcomes) == 0 {
 		return 0.0
 	}
 	totalScore := 0.0
+	totalWeight := 0.0
 	for _, to := range testOutcomes {
+		weight := 1.0
+		if w, ok := taskWeights[to.TestID]; ok {
+			weight = w
+		}
+		totalWeight += weight
 		if to.Stats != nil {
-			totalScore += to.Stats.AvgWeightedScore
+			totalScore += weight * to.Stats.AvgWeightedScore
 		}
 	}
-	return totalScore / float64(len(testOutcomes))
+	if totalWeight == 0 {
+		return 0.0
+	}
+	return totalScore / totalWeight
 }
 
 func computeDigestScoreStats(testOutcomes []models.TestOutcome) (float64, float64, float64) {
@@ -218,6 +371,25 @@ func computeDigestScoreStats(testOutcomes []models.TestOutcome) (float64, float6
 }
 
 func computeGroupStats(outcomes []models.TestOutcome) []models.GroupStats {
+	return aggregateStatsBy(outcomes, func(to models.TestOutcome) []string {
+		if to.Group == "" {
+			return nil
+		}
+		return []string{to.Group}
+	})
+}
+
+// computeTagStats aggregates outcomes per tag. A task with several tags counts
+// towards each of them.
+func computeTagStats(outcomes []models.TestOutcome) []models.GroupStats {
+	return aggregateStatsBy(outcomes, func(to models.TestOutcome) []string {
+		return to.Tags
+	})
+}
+
+// aggregateStatsBy buckets outcomes under every key returned by keysOf, in
+// first-seen order. Returns nil when no outcome has a key.
+func aggregateStatsBy(outcomes []models.TestOutcome, keysOf func(models.TestOutcome) []string) []models.GroupStats {
 	type accumulator struct {
 		passed     int
 		total      int
@@ -229,22 +401,21 @@ func computeGroupStats(outcomes []models.TestOutcome) []models.GroupStats {
 	var order []string
 
 	for _, to := range outcomes {
-		if to.Group == "" {
-			continue
-		}
-		acc, exists := groups[to.Group]
-		if !exists {
-			acc = &accumulator{}
-			groups[to.Group] = acc
-			order = append(order, to.Group)
-		}
-		acc.total++
-		if to.Status == models.StatusPassed {
-			acc.passed++
-		}
-		if to.Stats != nil {
-			acc.scoreTotal += to.Stats.AvgScore
-			acc.scoreCount++
+		for _, key := range keysOf(to) {
+			acc, exists := groups[key]
+			if !exists {
+				acc = &accumulator{}
+				groups[key] = acc
+				order = append(order, key)
+			}
+			acc.total++
+			if to.Status == models.StatusPassed {
+				acc.passed++
+			}
+			if to.Stats != nil {
+				acc.scoreTotal += to.Stats.AvgScore
+				acc.scoreCount++
+			}
 		}
 	}
 
@@ -285,7 +456,7 @@ func aggregateUsageFromOutcomes(testOutcomes []models.TestOutcome) *models.Usage
 // in the original with the graded ones and recomputing stats and digest.
 func RegradeOutcome(original *models.EvaluationOutcome, gradedOutcomes []models.TestOutcome, judgeModel string) *models.EvaluationOutcome {
 	for i := range gradedOutcomes {
-		gradedOutcomes[i].Stats = ComputeTestStats(gradedOutcomes[i].Runs)
+		gradedOutcomes[i].Stats = ComputeTestStats(gradedOutcomes[i].Runs, original.Setup.WeightMode, original.Setup.TrimOutliers, original.Setup.MinRunsForCI)
 	}
 
 	setup := original.Setup
@@ -298,15 +469,60 @@ func RegradeOutcome(original *models.EvaluationOutcome, gradedOutcomes []models.
 		runsPerTest = 1
 	}
 
-	return &models.EvaluationOutcome{
-		RunID:        original.RunID,
-		SkillTested:  original.SkillTested,
-		BenchName:    original.BenchName,
-		Timestamp:    original.Timestamp,
-		Setup:        setup,
-		Digest:       BuildDigest(gradedOutcomes, original.Digest.DurationMs, runsPerTest),
-		Measures:     make(map[string]models.MeasureResult),
-		TestOutcomes: gradedOutcomes,
-		Metadata:     original.Metadata,
+	digest := BuildDigest(gradedOutcomes, original.Digest.DurationMs, runsPerTest)
+	if setup.WeightByTrials {
+		digest.AggregateScore = computeTrialWeightedAggregateScore(gradedOutcomes)
+	} else if setup.AggregateMethod != "" {
+		digest.AggregateScore = computeAggregateScoreBy(gradedOutcomes, setup.AggregateMethod)
+	}
+
+	regraded := &models.EvaluationOutcome{
+		RunID:          original.RunID,
+		SkillTested:    original.SkillTested,
+		BenchName:      original.BenchName,
+		Timestamp:      original.Timestamp,
+		Setup:          setup,
+		Digest:         digest,
+		Measures:       make(map[string]models.MeasureResult),
+		TestOutcomes:   gradedOutcomes,
+		TriggerMetrics: original.TriggerMetrics,
+		Metadata:       original.Metadata,
+		Provenance:     original.Provenance,
+	}
+	// Trigger tests aren't regraded, so their F1 is folded in as before
+	ApplyTriggerWeight(regraded, setup.TriggerWeight)
+	return regraded
+}
+
+// Outcome metadata keys flagging graders whose results can vary between runs.
+const (
+	MetadataNonDeterministic        = "non_deterministic"
+	MetadataNonDeterministicGraders = "non_deterministic_graders"
+)
+
+// nonDeterministicGraders returns the sorted names of non-deterministic graders
+// (see [cache.IsNonDeterministic]) declared in the spec or run by any task.
+func nonDeterministicGraders(specGraders []models.GraderConfig, testOutcomes []models.TestOutcome) []string {
+	seen := make(map[string]bool)
+	for _, g := range specGraders {
+		if cache.IsNonDeterministic(g.Kind) {
+			seen[g.Identifier] = true
+		}
+	}
+	for _, to := range testOutcomes {
+		for _, run := range to.Runs {
+			for name, v := range run.Validations {
+				if cache.IsNonDeterministic(v.Type) {
+					seen[name] = true
+				}
+			}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
 }
@@ -1,6 +1,8 @@
 package orchestration
 
 import (
+	"math"
+	"strconv"
 	"testing"
 
 	"github.com/microsoft/waza/internal/models"
@@ -9,12 +11,177 @@ import (
 )
 
 func TestComputeTestStats_Nil(t *testing.T) {
-	assert.Nil(t, ComputeTestStats(nil))
+	assert.Nil(t, ComputeTestStats(nil, models.WeightModeNormalized, 0, 0))
+}
+
+func TestComputeTestStats_WeightMode(t *testing.T) {
+	runs := []models.RunResult{
+		{Status: models.StatusPassed, Validations: map[string]models.GraderResults{
+			"light": {Score: 1.0, Weight: 1.0},
+			"heavy": {Score: 0.5, Weight: 3.0},
+		}},
+		{Status: models.StatusPassed, Validations: map[string]models.GraderResults{
+			"light": {Score: 0.0, Weight: 1.0},
+			"heavy": {Score: 1.0, Weight: 3.0},
+		}},
+	}
+
+	tests := []struct {
+		mode models.WeightMode
+		want float64
+	}{
+		// run scores 2.5/4 and 3/4
+		{models.WeightModeNormalized, 0.6875},
+		// run scores 2.5 and 3
+		{models.WeightModeRaw, 2.75},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			stats := ComputeTestStats(runs, tt.mode, 0, 0)
+			require.NotNil(t, stats)
+			assert.InDelta(t, tt.want, stats.AvgWeightedScore, 1e-9)
+			// the unweighted average is unaffected by the mode
+			assert.InDelta(t, 0.625, stats.AvgScore, 1e-9)
+		})
+	}
+}
+
+func TestComputeTestStats_TrimOutliers(t *testing.T) {
+	run := func(score float64) models.RunResult {
+		return models.RunResult{Status: models.StatusPassed, Validations: map[string]models.GraderResults{
+			"judge": {Score: score, Weight: 1.0, Passed: true},
+		}}
+	}
+	// Nine consistent judge ratings and one wildly-off one
+	var runs []models.RunResult
+	for range 9 {
+		runs = append(runs, run(0.8))
+	}
+	runs = append(runs, run(0.0))
+
+	raw := ComputeTestStats(runs, models.WeightModeNormalized, 0, 0)
+	require.NotNil(t, raw)
+	assert.InDelta(t, 0.72, raw.AvgScore, 1e-9)
+	assert.Zero(t, raw.TrimmedRuns)
+
+	trimmed := ComputeTestStats(runs, models.WeightModeNormalized, 10, 0)
+	require.NotNil(t, trimmed)
+	assert.InDelta(t, 0.8, trimmed.AvgScore, 1e-9)
+	assert.InDelta(t, 0.8, trimmed.AvgWeightedScore, 1e-9)
+	assert.InDelta(t, 0.0, trimmed.StdDevScore, 1e-9)
+	assert.Less(t, trimmed.StdDevScore, raw.StdDevScore)
+	assert.Equal(t, 2, trimmed.TrimmedRuns, "one run is dropped from each end")
+
+	// Counts and range still cover every run
+	assert.Equal(t, 10, trimmed.TotalRuns)
+	assert.Equal(t, 0.0, trimmed.MinScore)
+	assert.Equal(t, 0.8, trimmed.MaxScore)
+}
+
+func TestComputeTestStats_TrimOutliersKeepsARun(t *testing.T) {
+	runs := []models.RunResult{
+		{Status: models.StatusPassed, Validations: map[string]models.GraderResults{"g": {Score: 0.2, Weight: 1}}},
+		{Status: models.StatusPassed, Validations: map[string]models.GraderResults{"g": {Score: 0.6, Weight: 1}}},
+	}
+
+	// 10% of two runs rounds down to nothing
+	assert.Zero(t, ComputeTestStats(runs, models.WeightModeNormalized, 10, 0).TrimmedRuns)
+
+	// Trimming can't remove every run
+	stats := ComputeTestStats(runs, models.WeightModeNormalized, 49, 0)
+	assert.Zero(t, stats.TrimmedRuns)
+	assert.InDelta(t, 0.4, stats.AvgScore, 1e-9)
+
+	runs = append(runs, models.RunResult{Status: models.StatusPassed, Validations: map[string]models.GraderResults{"g": {Score: 1.0, Weight: 1}}})
+	stats = ComputeTestStats(runs, models.WeightModeNormalized, 49, 0)
+	assert.Equal(t, 2, stats.TrimmedRuns)
+	assert.InDelta(t, 0.6, stats.AvgScore, 1e-9)
+}
+
+func TestComputeTestStats_MinRunsForCI(t *testing.T) {
+	runsOf := func(n int) []models.RunResult {
+		runs := make([]models.RunResult, n)
+		for i := range runs {
+			runs[i] = models.RunResult{Status: models.StatusPassed, Validations: map[string]models.GraderResults{
+				"g": {Score: float64(i%2) * 0.5, Weight: 1, Passed: true},
+			}}
+		}
+		return runs
+	}
+
+	// Below the default minimum: no interval
+	stats := ComputeTestStats(runsOf(3), models.WeightModeNormalized, 0, 0)
+	assert.Nil(t, stats.BootstrapCI)
+	assert.Nil(t, stats.IsSignificant)
+	assert.Zero(t, stats.CI95Lo)
+	assert.Zero(t, stats.CI95Hi)
+
+	stats = ComputeTestStats(runsOf(models.DefaultMinRunsForCI), models.WeightModeNormalized, 0, 0)
+	assert.NotNil(t, stats.BootstrapCI)
+	assert.NotNil(t, stats.IsSignificant)
+
+	// A configured minimum applies in both directions
+	stats = ComputeTestStats(runsOf(3), models.WeightModeNormalized, 0, 3)
+	assert.NotNil(t, stats.BootstrapCI)
+	assert.NotNil(t, stats.IsSignificant)
+	stats = ComputeTestStats(runsOf(5), models.WeightModeNormalized, 0, 8)
+	assert.Nil(t, stats.BootstrapCI)
+
+	// Trimmed runs don't count toward the minimum
+	stats = ComputeTestStats(runsOf(10), models.WeightModeNormalized, 30, 5)
+	assert.Equal(t, 6, stats.TrimmedRuns)
+	assert.Nil(t, stats.BootstrapCI)
+}
+
+func TestComputeTestStats_StabilityScore(t *testing.T) {
+	run := func(status models.Status, score float64) models.RunResult {
+		return models.RunResult{Status: status, Validations: map[string]models.GraderResults{
+			"g": {Score: score, Weight: 1.0, Passed: status == models.StatusPassed},
+		}}
+	}
+	pass := func(score float64) models.RunResult { return run(models.StatusPassed, score) }
+	fail := func(score float64) models.RunResult { return run(models.StatusFailed, score) }
+
+	tests := []struct {
+		name string
+		runs []models.RunResult
+		want float64
+	}{
+		{"single run", []models.RunResult{pass(0.7)}, 1.0},
+		{"identical passes", []models.RunResult{pass(0.9), pass(0.9), pass(0.9), pass(0.9)}, 1.0},
+		{"identical failures", []models.RunResult{fail(0.2), fail(0.2), fail(0.2)}, 1.0},
+		// all pass, but scores spread with std dev 0.1: (1 + 0.8) / 2
+		{"consistent outcome, noisy scores", []models.RunResult{pass(0.7), pass(0.9), pass(0.7), pass(0.9)}, 0.9},
+		// 3 of 4 agree (agreement 0.5); scores 1,1,1,0 have std dev sqrt(3)/4
+		{"one outlier", []models.RunResult{pass(1), pass(1), pass(1), fail(0)}, (0.5 + 1 - math.Sqrt(3)/2) / 2},
+		{"even split at the extremes", []models.RunResult{pass(1), fail(0), pass(1), fail(0)}, 0.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := ComputeTestStats(tt.runs, models.WeightModeNormalized, 0, 0)
+			require.NotNil(t, stats)
+			assert.InDelta(t, tt.want, stats.StabilityScore, 1e-9)
+		})
+	}
+}
+
+func TestBuildDigest_Stability(t *testing.T) {
+	outcomes := []models.TestOutcome{
+		{Status: models.StatusPassed, Stats: &models.TestStats{TotalRuns: 3, StabilityScore: 1.0}},
+		{Status: models.StatusFailed, Stats: &models.TestStats{TotalRuns: 3, StabilityScore: 0.4}},
+		// single-trial tasks say nothing about stability
+		{Status: models.StatusPassed, Stats: &models.TestStats{TotalRuns: 1, StabilityScore: 1.0}},
+	}
+	d := BuildDigest(outcomes, 0, 3)
+	require.NotNil(t, d.Stability)
+	assert.InDelta(t, 0.7, *d.Stability, 1e-9)
+
+	assert.Nil(t, BuildDigest(outcomes[2:], 0, 1).Stability)
 }
 
 func TestDigestHelpers_Nil(t *testing.T) {
 	assert.Equal(t, 0.0, computeAggregateScore(nil))
-	assert.Equal(t, 0.0, computeWeightedAggregateScore(nil))
+	assert.Equal(t, 0.0, computeWeightedAggregateScore(nil, nil))
 
 	minScore, maxScore, stdDev := computeDigestScoreStats(nil)
 	assert.Equal(t, 0.0, minScore)
@@ -22,6 +189,145 @@ func TestDigestHelpers_Nil(t *testing.T) {
 	assert.Equal(t, 0.0, stdDev)
 }
 
+func TestComputeTrialWeightedAggregateScore(t *testing.T) {
+	outcomes := []models.TestOutcome{
+		{TestID: "many", Stats: &models.TestStats{AvgScore: 1.0, TotalRuns: 5}},
+		{TestID: "few", Stats: &models.TestStats{AvgScore: 0.0, TotalRuns: 1}},
+		{TestID: "no-stats"},
+	}
+
+	// Equal weighting counts the stats-less task as a zero.
+	assert.InDelta(t, 1.0/3, computeAggregateScore(outcomes), 1e-9)
+	assert.InDelta(t, 5.0/6, computeTrialWeightedAggregateScore(outcomes), 1e-9)
+	assert.Equal(t, 0.0, computeTrialWeightedAggregateScore(nil))
+}
+
+func TestRegradeOutcome_WeightByTrials(t *testing.T) {
+	graded := func() []models.TestOutcome {
+		return []models.TestOutcome{
+			{TestID: "many", Status: models.StatusPassed, Runs: []models.RunResult{
+				{Status: models.StatusPassed, Validations: map[string]models.GraderResults{"g": {Score: 1.0, Passed: true}}},
+				{Status: models.StatusPassed, Validations: map[string]models.GraderResults{"g": {Score: 1.0, Passed: true}}},
+				{Status: models.StatusPassed, Validations: map[string]models.GraderResults{"g": {Score: 1.0, Passed: true}}},
+			}},
+			{TestID: "few", Status: models.StatusFailed, Runs: []models.RunResult{
+				{Status: models.StatusFailed, Validations: map[string]models.GraderResults{"g": {Score: 0.0}}},
+			}},
+		}
+	}
+
+	equal := RegradeOutcome(&models.EvaluationOutcome{}, graded(), "")
+	assert.InDelta(t, 0.5, equal.Digest.AggregateScore, 1e-9)
+
+	weighted := RegradeOutcome(&models.EvaluationOutcome{Setup: models.OutcomeSetup{WeightByTrials: true}}, graded(), "")
+	assert.InDelta(t, 0.75, weighted.Digest.AggregateScore, 1e-9)
+	assert.True(t, weighted.Setup.WeightByTrials)
+}
+
+func TestComputeAggregateScoreBy(t *testing.T) {
+	// One task collapsing drags the mean down but not the median
+	skewed := []models.TestOutcome{
+		{TestID: "a", Stats: &models.TestStats{AvgScore: 0.9}},
+		{TestID: "b", Stats: &models.TestStats{AvgScore: 0.1}},
+		{TestID: "c", Stats: &models.TestStats{AvgScore: 0.8}},
+		{TestID: "d", Stats: &models.TestStats{AvgScore: 0.95}},
+		{TestID: "e", Stats: &models.TestStats{AvgScore: 0.85}},
+	}
+
+	assert.InDelta(t, 0.72, computeAggregateScoreBy(skewed, ""), 1e-9)
+	assert.InDelta(t, 0.72, computeAggregateScoreBy(skewed, models.AggregateMethodMean), 1e-9)
+	assert.InDelta(t, 0.85, computeAggregateScoreBy(skewed, models.AggregateMethodMedian), 1e-9)
+	assert.InDelta(t, 0.1, computeAggregateScoreBy(skewed, models.AggregateMethodMin), 1e-9)
+	assert.InDelta(t, 0.95, computeAggregateScoreBy(skewed, models.AggregateMethodP90), 1e-9)
+
+	// Even count: median averages the middle pair; tasks without stats count as 0
+	even := append(skewed[:3:3], models.TestOutcome{TestID: "no-stats"})
+	assert.InDelta(t, 0.45, computeAggregateScoreBy(even, models.AggregateMethodMedian), 1e-9)
+	assert.Equal(t, 0.0, computeAggregateScoreBy(even, models.AggregateMethodMin))
+
+	// p90 is nearest rank: with 20 tasks it's the 18th lowest
+	twenty := make([]models.TestOutcome, 20)
+	for i := range twenty {
+		twenty[i] = models.TestOutcome{Stats: &models.TestStats{AvgScore: float64(i+1) / 20}}
+	}
+	assert.InDelta(t, 0.9, computeAggregateScoreBy(twenty, models.AggregateMethodP90), 1e-9)
+
+	assert.Equal(t, 0.0, computeAggregateScoreBy(nil, models.AggregateMethodMedian))
+}
+
+func TestApplyTriggerWeight(t *testing.T) {
+	outcome := func(f1 float64) *models.EvaluationOutcome {
+		return &models.EvaluationOutcome{
+			Digest:         models.OutcomeDigest{TotalTests: 2, AggregateScore: 0.9},
+			TriggerMetrics: &models.TriggerMetrics{F1: f1},
+		}
+	}
+
+	// Poor routing drags the headline down in proportion to the weight
+	poor := outcome(0.3)
+	ApplyTriggerWeight(poor, 0.5)
+	assert.InDelta(t, 0.6, poor.Digest.AggregateScore, 1e-9)
+	assert.Equal(t, 0.5, poor.Setup.TriggerWeight)
+
+	good := outcome(1.0)
+	ApplyTriggerWeight(good, 0.5)
+	assert.InDelta(t, 0.95, good.Digest.AggregateScore, 1e-9)
+	assert.Greater(t, good.Digest.AggregateScore, poor.Digest.AggregateScore)
+
+	// The default weight of 0 leaves the score alone
+	unweighted := outcome(0.3)
+	ApplyTriggerWeight(unweighted, 0)
+	assert.InDelta(t, 0.9, unweighted.Digest.AggregateScore, 1e-9)
+	assert.Zero(t, unweighted.Setup.TriggerWeight)
+
+	// So do runs without trigger tests or without tasks
+	noTriggers := &models.EvaluationOutcome{Digest: models.OutcomeDigest{TotalTests: 2, AggregateScore: 0.9}}
+	ApplyTriggerWeight(noTriggers, 0.5)
+	assert.InDelta(t, 0.9, noTriggers.Digest.AggregateScore, 1e-9)
+	triggerOnly := outcome(0.3)
+	triggerOnly.Digest = models.OutcomeDigest{}
+	ApplyTriggerWeight(triggerOnly, 0.5)
+	assert.Zero(t, triggerOnly.Digest.AggregateScore)
+}
+
+func TestRegradeOutcome_AggregateMethod(t *testing.T) {
+	graded := func() []models.TestOutcome {
+		var outcomes []models.TestOutcome
+		for _, score := range []float64{1.0, 1.0, 0.0} {
+			outcomes = append(outcomes, models.TestOutcome{TestID: strconv.Itoa(len(outcomes)), Runs: []models.RunResult{
+				{Status: models.StatusPassed, Validations: map[string]models.GraderResults{"g": {Score: score, Passed: score > 0}}},
+			}})
+		}
+		return outcomes
+	}
+
+	mean := RegradeOutcome(&models.EvaluationOutcome{}, graded(), "")
+	assert.InDelta(t, 2.0/3, mean.Digest.AggregateScore, 1e-9)
+
+	median := RegradeOutcome(&models.EvaluationOutcome{Setup: models.OutcomeSetup{AggregateMethod: models.AggregateMethodMedian}}, graded(), "")
+	assert.InDelta(t, 1.0, median.Digest.AggregateScore, 1e-9)
+	assert.Equal(t, models.AggregateMethodMedian, median.Setup.AggregateMethod)
+
+	worst := RegradeOutcome(&models.EvaluationOutcome{Setup: models.OutcomeSetup{AggregateMethod: models.AggregateMethodMin}}, graded(), "")
+	assert.InDelta(t, 0.0, worst.Digest.AggregateScore, 1e-9)
+}
+
+func TestRegradeOutcome_TriggerWeight(t *testing.T) {
+	graded := []models.TestOutcome{{TestID: "a", Runs: []models.RunResult{
+		{Status: models.StatusPassed, Validations: map[string]models.GraderResults{"g": {Score: 1.0, Passed: true}}},
+	}}}
+	original := &models.EvaluationOutcome{
+		Setup:          models.OutcomeSetup{TriggerWeight: 0.5},
+		TriggerMetrics: &models.TriggerMetrics{F1: 0.2},
+	}
+
+	// (1-0.5)*1.0 + 0.5*0.2, as the original run folded it
+	regraded := RegradeOutcome(original, graded, "")
+	assert.InDelta(t, 0.6, regraded.Digest.AggregateScore, 1e-9)
+	assert.Same(t, original.TriggerMetrics, regraded.TriggerMetrics)
+	assert.Equal(t, 0.5, regraded.Setup.TriggerWeight)
+}
+
 func TestBuildDigest_SinglePassedTask(t *testing.T) {
 	outcomes := []models.TestOutcome{{
 		Status: models.StatusPassed,
@@ -47,6 +353,22 @@ func TestBuildDigest_MixedTasks(t *testing.T) {
 	assert.InDelta(t, 0.5, d.AggregateScore, 0.001)
 }
 
+func TestBuildDigest_XFail(t *testing.T) {
+	outcomes := []models.TestOutcome{
+		{Status: models.StatusPassed, Stats: &models.TestStats{AvgScore: 1.0}},
+		{Status: models.StatusFailed, XFail: true, Stats: &models.TestStats{AvgScore: 0.0}},
+		{Status: models.StatusError, XFail: true},
+		{Status: models.StatusPassed, XFail: true, Stats: &models.TestStats{AvgScore: 1.0}},
+	}
+	d := BuildDigest(outcomes, 1000, 1)
+	assert.Equal(t, 4, d.TotalTests)
+	assert.Equal(t, 2, d.Succeeded, "xpass still counts as succeeded")
+	assert.Equal(t, 0, d.Failed, "xfail failures don't count as failed")
+	assert.Equal(t, 0, d.Errors, "xfail errors don't count as errors")
+	assert.Equal(t, 2, d.XFailed)
+	assert.Equal(t, 1, d.XPassed)
+}
+
 func TestRegradeOutcome_ComputesStatsAndDigest(t *testing.T) {
 	original := &models.EvaluationOutcome{
 		RunID:       "run-1",
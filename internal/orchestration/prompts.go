@@ -0,0 +1,51 @@
+package orchestration
+
+import (
+	"fmt"
+	"io"
+)
+
+// PrintPrompts writes the final prompt each selected task would send to the
+// engine (after template resolution and resource loading) without executing
+// anything. Task and tag filters, and WithFirstN, apply as they do for
+// RunBenchmark.
+func (r *TestRunner) PrintPrompts(w io.Writer) error {
+	testCases, _, err := r.selectTestCases()
+	if err != nil {
+		return err
+	}
+	if r.firstN > 0 {
+		testCases = firstN(testCases, r.firstN)
+	}
+
+	for tc, err := range testCases {
+		if err != nil {
+			return fmt.Errorf("failed to load test cases: %w", err)
+		}
+		req, err := r.buildExecutionRequest(tc)
+		if err != nil {
+			return fmt.Errorf("task %s: %w", tc.TestID, err)
+		}
+
+		if _, err := fmt.Fprintf(w, "=== %s (%s) ===\n", tc.DisplayName, tc.TestID); err != nil {
+			return err
+		}
+		if req.SystemPrompt != "" {
+			if _, err := fmt.Fprintf(w, "[system] %s\n", req.SystemPrompt); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", req.Message); err != nil {
+			return err
+		}
+		for _, res := range req.Resources {
+			if _, err := fmt.Fprintf(w, "[resource] %s (%d bytes)\n", res.Path, len(res.Content)); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package orchestration
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintPrompts_CSVTemplates(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeCSV(t, tmpDir, "data.csv", "id,lang,prompt\n1,Go,Explain {{.Vars.lang}} to a {{.Vars.audience}}\n2,Rust,Explain {{.Vars.lang}} to a {{.Vars.audience}}\n")
+
+	spec := &models.BenchmarkSpec{
+		TasksFrom: "data.csv",
+		Inputs:    map[string]string{"audience": "beginner"},
+		Config:    models.Config{ModelID: "test-model"},
+	}
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+
+	var buf bytes.Buffer
+	require.NoError(t, NewTestRunner(cfg, nil).PrintPrompts(&buf))
+
+	out := buf.String()
+	assert.Contains(t, out, "=== row-1 (1) ===\nExplain Go to a beginner\n")
+	assert.Contains(t, out, "=== row-2 (2) ===\nExplain Rust to a beginner\n")
+	assert.NotContains(t, out, "{{")
+}
+
+func TestPrintPrompts_FileTasksWithResources(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, "tasks")
+	fixtureDir := filepath.Join(tmpDir, "fixtures")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+	require.NoError(t, os.MkdirAll(fixtureDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(fixtureDir, "main.go"), []byte("package main\n"), 0o644))
+	writeTaskFile(t, filepath.Join(tasksDir, "review.yaml"), `id: review
+name: Review
+inputs:
+  prompt: "Review main.go"
+  files:
+    - path: "main.go"
+    - path: "notes.txt"
+      content: "inline"
+`)
+	writeTaskFile(t, filepath.Join(tasksDir, "other.yaml"), `id: other
+name: Other
+inputs:
+  prompt: "Something else"
+`)
+
+	spec := &models.BenchmarkSpec{
+		Tasks:  []string{"tasks/*.yaml"},
+		Config: models.Config{ModelID: "test-model"},
+	}
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir), config.WithFixtureDir(fixtureDir))
+
+	var buf bytes.Buffer
+	require.NoError(t, NewTestRunner(cfg, nil, WithTaskFilters("review")).PrintPrompts(&buf))
+
+	out := buf.String()
+	assert.Contains(t, out, "=== Review (review) ===\nReview main.go\n[resource] main.go (13 bytes)\n[resource] notes.txt (6 bytes)\n")
+	assert.NotContains(t, out, "Something else")
+}
@@ -0,0 +1,42 @@
+package orchestration
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+)
+
+// WithToolVersion sets the waza build version recorded in each outcome's
+// provenance. An empty version is recorded as "dev".
+func WithToolVersion(version string) RunnerOption {
+	return func(r *TestRunner) {
+		r.toolVersion = version
+	}
+}
+
+// NewProvenance describes the toolchain behind an outcome started at
+// startedAt, filling in the OS and architecture waza is running on.
+func NewProvenance(toolVersion, engineType, engineVersion, modelID string, startedAt time.Time) *models.Provenance {
+	if toolVersion == "" {
+		toolVersion = "dev"
+	}
+	return &models.Provenance{
+		WazaVersion:   toolVersion,
+		EngineType:    engineType,
+		EngineVersion: engineVersion,
+		ModelID:       modelID,
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		Timestamp:     startedAt,
+	}
+}
+
+// engineVersion returns the engine's runtime version when it reports one.
+func engineVersion(engine execution.AgentEngine) string {
+	if v, ok := engine.(execution.VersionReporter); ok {
+		return v.EngineVersion()
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package orchestration
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// versionedEngine is a mock engine that reports a runtime version.
+type versionedEngine struct {
+	*execution.MockEngine
+}
+
+func (versionedEngine) EngineVersion() string { return "mock-runtime 1.2.3" }
+
+func runProvenanceBenchmark(t *testing.T, engine execution.AgentEngine, opts ...RunnerOption) *models.EvaluationOutcome {
+	t.Helper()
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "task.yaml"), "id: t1\nname: T1\ninputs:\n  prompt: hello\n")
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "provenance"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Tasks: []string{"*.yaml"},
+	}
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	outcome, err := NewTestRunner(cfg, engine, opts...).RunBenchmark(context.Background())
+	require.NoError(t, err)
+	return outcome
+}
+
+func TestRunBenchmark_RecordsProvenance(t *testing.T) {
+	outcome := runProvenanceBenchmark(t, versionedEngine{execution.NewMockEngine("mock-model")}, WithToolVersion("v1.4.0"))
+
+	require.NotNil(t, outcome.Provenance)
+	assert.Equal(t, models.Provenance{
+		WazaVersion:   "v1.4.0",
+		EngineType:    "mock",
+		EngineVersion: "mock-runtime 1.2.3",
+		ModelID:       "mock-model",
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		Timestamp:     outcome.Timestamp,
+	}, *outcome.Provenance)
+	assert.False(t, outcome.Provenance.Timestamp.IsZero())
+
+	data, err := json.Marshal(outcome)
+	require.NoError(t, err)
+	var decoded struct {
+		Provenance map[string]any `json:"provenance"`
+	}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "v1.4.0", decoded.Provenance["waza_version"])
+	assert.Equal(t, "mock", decoded.Provenance["engine_type"])
+	assert.Equal(t, "mock-runtime 1.2.3", decoded.Provenance["engine_version"])
+	assert.Equal(t, "mock-model", decoded.Provenance["model_id"])
+	assert.Equal(t, runtime.GOOS, decoded.Provenance["os"])
+	assert.Equal(t, runtime.GOARCH, decoded.Provenance["arch"])
+	assert.NotEmpty(t, decoded.Provenance["timestamp"])
+}
+
+func TestRunBenchmark_ProvenanceDefaults(t *testing.T) {
+	outcome := runProvenanceBenchmark(t, execution.NewMockEngine("mock-model"))
+
+	require.NotNil(t, outcome.Provenance)
+	assert.Equal(t, "dev", outcome.Provenance.WazaVersion, "an unset version is recorded as dev")
+	assert.Empty(t, outcome.Provenance.EngineVersion, "engines that don't report a version leave it empty")
+}
+
+func TestRegradeOutcome_KeepsProvenance(t *testing.T) {
+	p := NewProvenance("v1.0.0", "mock", "", "mock-model", time.Now())
+	original := &models.EvaluationOutcome{Setup: models.OutcomeSetup{RunsPerTest: 1}, Provenance: p}
+	regraded := RegradeOutcome(original, nil, "")
+	assert.Same(t, p, regraded.Provenance)
+}
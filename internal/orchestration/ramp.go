@@ -0,0 +1,40 @@
+package orchestration
+
+import (
+	"context"
+	"time"
+)
+
+// WithRampUp staggers the start of parallel workers evenly across d instead
+// of launching them all at once, so a run doesn't open with a burst of
+// requests that trips the model provider's rate limits. Sequential runs
+// ignore it.
+func WithRampUp(d time.Duration) RunnerOption {
+	return func(r *TestRunner) {
+		r.rampUp = d
+	}
+}
+
+// rampOffset returns how long after the run starts the k-th (0-based) launch
+// may begin. Only the first `workers` launches are ramped; later ones wait for
+// a free slot as usual.
+func rampOffset(k, workers int, rampUp time.Duration) time.Duration {
+	if rampUp <= 0 || workers <= 1 || k >= workers {
+		return 0
+	}
+	return rampUp * time.Duration(k) / time.Duration(workers)
+}
+
+// waitUntilOffset blocks until offset has elapsed since start, or ctx is done.
+func waitUntilOffset(ctx context.Context, start time.Time, offset time.Duration) {
+	d := time.Until(start.Add(offset))
+	if d <= 0 {
+		return
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
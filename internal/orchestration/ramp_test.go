@@ -0,0 +1,128 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRampOffset(t *testing.T) {
+	ramp := 40 * time.Second
+	var offsets []time.Duration
+	for k := range 6 {
+		offsets = append(offsets, rampOffset(k, 4, ramp))
+	}
+	assert.Equal(t, []time.Duration{0, 10 * time.Second, 20 * time.Second, 30 * time.Second, 0, 0}, offsets,
+		"the first 4 launches spread across the window; later ones aren't delayed")
+
+	assert.Zero(t, rampOffset(3, 4, 0), "no ramp by default")
+	assert.Zero(t, rampOffset(0, 1, ramp), "a single worker starts at once")
+}
+
+func newRampRunner(t *testing.T, tasks, workers int, opts ...RunnerOption) *TestRunner {
+	t.Helper()
+	tmpDir := t.TempDir()
+	for i := range tasks {
+		id := fmt.Sprintf("task-%d", i)
+		writeTaskFile(t, filepath.Join(tmpDir, id+".yaml"), fmt.Sprintf("id: %s\nname: %s\ninputs:\n  prompt: \"hi\"\n", id, id))
+	}
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "ramp"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+			Concurrent:    true,
+			Workers:       workers,
+		},
+		Tasks: []string{"task-*.yaml"},
+	}
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	return NewTestRunner(cfg, execution.NewMockEngine("mock-model"), opts...)
+}
+
+func TestRunBenchmark_RampUpStaggersWorkerStarts(t *testing.T) {
+	runner := newRampRunner(t, 6, 3, WithRampUp(9*time.Second))
+
+	// Fake clock: each task start is stamped with the current fake time, and
+	// a ramp wait lets the tasks already launched start before advancing it.
+	var mu sync.Mutex
+	var now time.Duration
+	var waits, starts []time.Duration
+	started := make(chan struct{}, 6)
+	runner.OnProgress(func(e ProgressEvent) {
+		if e.EventType == EventTestStart {
+			mu.Lock()
+			starts = append(starts, now)
+			mu.Unlock()
+			started <- struct{}{}
+		}
+	})
+	seen := 0
+	runner.rampWait = func(_ context.Context, _ time.Time, offset time.Duration) {
+		for ; seen < len(waits)+1; seen++ {
+			<-started
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		waits = append(waits, offset)
+		now = offset
+	}
+
+	outcome, err := runner.RunBenchmark(context.Background())
+	require.NoError(t, err)
+	require.Len(t, outcome.TestOutcomes, 6)
+
+	assert.Equal(t, []time.Duration{3 * time.Second, 6 * time.Second}, waits, "the first worker starts immediately")
+	slices.Sort(starts)
+	require.Len(t, starts, 6)
+	assert.Equal(t, []time.Duration{0, 3 * time.Second, 6 * time.Second}, starts[:3], "one worker starts per third of the window")
+	assert.Equal(t, 6*time.Second, starts[5], "later tasks reuse ramped slots")
+}
+
+func TestRunBenchmark_RampUpRealClock(t *testing.T) {
+	runner := newRampRunner(t, 3, 3, WithRampUp(300*time.Millisecond))
+	var mu sync.Mutex
+	var starts []time.Time
+	runner.OnProgress(func(e ProgressEvent) {
+		if e.EventType == EventTestStart {
+			mu.Lock()
+			starts = append(starts, time.Now())
+			mu.Unlock()
+		}
+	})
+
+	_, err := runner.RunBenchmark(context.Background())
+	require.NoError(t, err)
+	require.Len(t, starts, 3)
+	slices.SortFunc(starts, func(a, b time.Time) int { return a.Compare(b) })
+	assert.GreaterOrEqual(t, starts[2].Sub(starts[0]), 200*time.Millisecond, "the last worker starts at 2/3 of the window")
+}
+
+func TestRunBenchmark_NoRampByDefault(t *testing.T) {
+	runner := newRampRunner(t, 4, 4)
+	runner.rampWait = func(context.Context, time.Time, time.Duration) {
+		t.Error("no ramp configured, but a launch waited")
+	}
+	_, err := runner.RunBenchmark(context.Background())
+	require.NoError(t, err)
+}
+
+func TestWaitUntilOffset_StopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	begin := time.Now()
+	waitUntilOffset(ctx, begin, time.Hour)
+	assert.Less(t, time.Since(begin), time.Second)
+}
@@ -0,0 +1,49 @@
+package orchestration
+
+import (
+	"fmt"
+
+	copilot "github.com/github/copilot-sdk/go"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+)
+
+// replayResponse rebuilds the engine response for tc from its captured transcript.
+func (r *TestRunner) replayResponse(tc *models.TestCase) (*execution.ExecutionResponse, error) {
+	t, ok := r.replay[tc.TestID]
+	if !ok {
+		return nil, fmt.Errorf("no replay transcript for task %q", tc.TestID)
+	}
+	return responseFromTranscript(t), nil
+}
+
+// responseFromTranscript reconstructs an ExecutionResponse from a TaskTranscript.
+// The workspace isn't captured in transcripts, so WorkspaceDir is left empty.
+func responseFromTranscript(t *models.TaskTranscript) *execution.ExecutionResponse {
+	events := make([]copilot.SessionEvent, len(t.Transcript))
+	for i, evt := range t.Transcript {
+		events[i] = evt.SessionEvent
+	}
+
+	toolCalls := t.Session.ToolCalls
+	if len(toolCalls) == 0 {
+		toolCalls = models.FilterToolCalls(events)
+	}
+
+	skillInvocations := make([]execution.SkillInvocation, len(t.SkillInvocations))
+	for i, si := range t.SkillInvocations {
+		skillInvocations[i] = execution.SkillInvocation{Name: si.Name, Path: si.Path}
+	}
+
+	return &execution.ExecutionResponse{
+		FinalOutput:      t.FinalOutput,
+		Events:           events,
+		SkillInvocations: skillInvocations,
+		DurationMs:       t.DurationMs,
+		ToolCalls:        toolCalls,
+		ErrorMsg:         t.ErrorMsg,
+		Success:          t.ErrorMsg == "",
+		SessionID:        t.Session.SessionID,
+		Usage:            t.Session.Usage,
+	}
+}
@@ -0,0 +1,125 @@
+package orchestration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/microsoft/waza/internal/transcript"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func replaySpec() *models.BenchmarkSpec {
+	return &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "replay"},
+		SkillName:    "test-skill",
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Graders: []models.GraderConfig{
+			{
+				Kind:       models.GraderKindText,
+				Identifier: "mentions-prompt",
+				Parameters: models.TextGraderParameters{Contains: []string{"first"}},
+			},
+			{
+				Kind:       models.GraderKindText,
+				Identifier: "is-mock",
+				Parameters: models.TextGraderParameters{RegexMatch: []string{"^Mock response"}},
+			},
+		},
+		Tasks: []string{"tasks/*.yaml"},
+	}
+}
+
+func TestRunBenchmark_ReplayMatchesRecordedGrades(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, "tasks")
+	transcriptDir := filepath.Join(tmpDir, "transcripts")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+
+	writeTaskFile(t, filepath.Join(tasksDir, "a.yaml"), "id: task-a\nname: Task A\ninputs:\n  prompt: \"first\"\n")
+	writeTaskFile(t, filepath.Join(tasksDir, "b.yaml"), "id: task-b\nname: Task B\ninputs:\n  prompt: \"second\"\n")
+
+	// Record
+	engine := execution.NewMockEngine("mock-model")
+	require.NoError(t, engine.Initialize(context.Background()))
+	cfg := config.NewBenchmarkConfig(replaySpec(), config.WithSpecDir(tmpDir), config.WithTranscriptDir(transcriptDir))
+	recorded, err := NewTestRunner(cfg, engine).RunBenchmark(context.Background())
+	require.NoError(t, err)
+
+	// Replay with an uninitialized engine: any Execute call would fail the run
+	transcripts, err := transcript.LoadDir(transcriptDir)
+	require.NoError(t, err)
+	require.Len(t, transcripts, 2)
+
+	cfg = config.NewBenchmarkConfig(replaySpec(), config.WithSpecDir(tmpDir))
+	replayed, err := NewTestRunner(cfg, execution.NewMockEngine("mock-model"), WithReplay(transcripts)).RunBenchmark(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, replayed.TestOutcomes, 2)
+	for i, want := range recorded.TestOutcomes {
+		got := replayed.TestOutcomes[i]
+		require.Equal(t, want.TestID, got.TestID)
+		assert.Equal(t, want.Status, got.Status, got.TestID)
+		assert.Equal(t, want.Runs[0].FinalOutput, got.Runs[0].FinalOutput)
+		require.Len(t, got.Runs[0].Validations, 2)
+		for name, wantResult := range want.Runs[0].Validations {
+			gotResult := got.Runs[0].Validations[name]
+			assert.Equal(t, wantResult.Passed, gotResult.Passed, "%s/%s", got.TestID, name)
+			assert.Equal(t, wantResult.Score, gotResult.Score, "%s/%s", got.TestID, name)
+			assert.Equal(t, wantResult.Feedback, gotResult.Feedback, "%s/%s", got.TestID, name)
+		}
+	}
+	assert.Equal(t, models.StatusPassed, replayed.TestOutcomes[0].Status)
+	assert.Equal(t, models.StatusFailed, replayed.TestOutcomes[1].Status)
+}
+
+func TestRunBenchmark_ReplayMissingTranscript(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, "tasks")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+	writeTaskFile(t, filepath.Join(tasksDir, "a.yaml"), "id: task-a\nname: Task A\ninputs:\n  prompt: \"first\"\n")
+
+	cfg := config.NewBenchmarkConfig(replaySpec(), config.WithSpecDir(tmpDir))
+	runner := NewTestRunner(cfg, execution.NewMockEngine("mock-model"),
+		WithReplay(map[string]*models.TaskTranscript{"other-task": {TaskID: "other-task"}}))
+
+	outcome, err := runner.RunBenchmark(context.Background())
+	require.NoError(t, err)
+	require.Len(t, outcome.TestOutcomes, 1)
+	assert.Equal(t, models.StatusError, outcome.TestOutcomes[0].Runs[0].Status)
+	assert.Contains(t, outcome.TestOutcomes[0].Runs[0].ErrorMsg, `no replay transcript for task "task-a"`)
+}
+
+func TestResponseFromTranscript(t *testing.T) {
+	tr := &models.TaskTranscript{
+		TaskID:      "t",
+		FinalOutput: "done",
+		DurationMs:  42,
+		Session: models.SessionDigest{
+			SessionID: "sess-1",
+			ToolCalls: []models.ToolCall{{Name: "bash", Success: true}},
+			Usage:     &models.UsageStats{InputTokens: 10},
+		},
+		SkillInvocations: []models.SkillInvocation{{Name: "my-skill", Path: "skills/my-skill/SKILL.md"}},
+	}
+
+	resp := responseFromTranscript(tr)
+	assert.Equal(t, "done", resp.FinalOutput)
+	assert.Equal(t, int64(42), resp.DurationMs)
+	assert.True(t, resp.Success)
+	assert.Equal(t, "sess-1", resp.SessionID)
+	assert.Equal(t, 10, resp.Usage.InputTokens)
+	assert.Equal(t, []models.ToolCall{{Name: "bash", Success: true}}, resp.ToolCalls)
+	assert.Equal(t, []execution.SkillInvocation{{Name: "my-skill", Path: "skills/my-skill/SKILL.md"}}, resp.SkillInvocations)
+	assert.Empty(t, resp.WorkspaceDir)
+}
@@ -0,0 +1,77 @@
+package orchestration
+
+import (
+	"context"
+
+	"github.com/microsoft/waza/internal/cache"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+)
+
+// responseCacheKey returns the cache key for trial runNum of tc's engine
+// response, or "" when responses aren't cached: no cache is configured, ctx is
+// a --verify-cache re-run, a grader needs the workspace, which a cached
+// response can't reproduce, or config.redact is set, since graders need the
+// raw response and the cache is on disk.
+func (r *TestRunner) responseCacheKey(ctx context.Context, tc *models.TestCase, runNum int) string {
+	if r.cache == nil || ctx.Value(freshRunKey{}) != nil {
+		return ""
+	}
+	spec := r.cfg.Spec()
+	if cache.NeedsWorkspace(spec, tc) || len(spec.Config.Redact) > 0 {
+		return ""
+	}
+	key, err := cache.ResponseKey(spec, tc, r.cfg.FixtureDir(), runNum)
+	if err != nil {
+		return ""
+	}
+	return key
+}
+
+// executeCached runs req on the engine, reusing the response cached under key
+// when there is one. Successful responses are cached for the next run.
+func (r *TestRunner) executeCached(ctx context.Context, tc *models.TestCase, req *execution.ExecutionRequest, key string) (*execution.ExecutionResponse, error) {
+	if key == "" {
+		return r.engine.Execute(ctx, req)
+	}
+	if resp, ok := r.cache.GetResponse(key); ok {
+		return resp, nil
+	}
+
+	resp, err := r.engine.Execute(ctx, req)
+	if err != nil || resp.ErrorMsg != "" {
+		return resp, err
+	}
+	if err := r.cache.PutResponse(key, resp); err != nil {
+		r.warnf("Failed to cache response for test %q: %v", tc.DisplayName, err)
+	}
+	return resp, nil
+}
+
+// graderResultCache adapts the result cache to graders.ResultCache for one
+// task's response, so unchanged graders aren't re-run against a reused
+// response.
+type graderResultCache struct {
+	cache *cache.Cache
+	resp  *execution.ExecutionResponse
+	task  *models.TestCase
+	warnf func(format string, args ...any)
+}
+
+func (g graderResultCache) Get(identifier string, kind models.GraderKind, params models.GraderParameters) (*models.GraderResults, bool) {
+	key, err := cache.GraderKey(g.resp, g.task, identifier, kind, params)
+	if err != nil {
+		return nil, false
+	}
+	return g.cache.GetGraderResult(key)
+}
+
+func (g graderResultCache) Put(identifier string, kind models.GraderKind, params models.GraderParameters, result *models.GraderResults) {
+	key, err := cache.GraderKey(g.resp, g.task, identifier, kind, params)
+	if err != nil {
+		return
+	}
+	if err := g.cache.PutGraderResult(key, result); err != nil {
+		g.warnf("Failed to cache result for grader %q: %v", identifier, err)
+	}
+}
@@ -0,0 +1,304 @@
+package orchestration
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/microsoft/waza/internal/cache"
+	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingEngine wraps the mock engine and counts Execute calls.
+type countingEngine struct {
+	*execution.MockEngine
+	calls int
+}
+
+func (e *countingEngine) Execute(ctx context.Context, req *execution.ExecutionRequest) (*execution.ExecutionResponse, error) {
+	e.calls++
+	return e.MockEngine.Execute(ctx, req)
+}
+
+func cacheEntries(t *testing.T, dir, prefix string) int {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	n := 0
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestRunBenchmark_GraderEditReusesCachedResponse(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, "tasks")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+	writeTaskFile(t, filepath.Join(tasksDir, "task.yaml"), `id: cached
+name: Cached
+inputs:
+  prompt: "explain caching"
+`)
+	cacheDir := t.TempDir()
+
+	newSpec := func(mustContain string) *models.BenchmarkSpec {
+		return &models.BenchmarkSpec{
+			SpecIdentity: models.SpecIdentity{Name: "two-layer-cache"},
+			SkillName:    "test-skill",
+			Config: models.Config{
+				TrialsPerTask: 1,
+				TimeoutSec:    30,
+				EngineType:    "mock",
+				ModelID:       "mock-model",
+			},
+			Graders: []models.GraderConfig{
+				{
+					Kind:       models.GraderKindText,
+					Identifier: "stable",
+					Parameters: models.TextGraderParameters{Contains: []string{"Mock response"}},
+				},
+				{
+					Kind:       models.GraderKindText,
+					Identifier: "edited",
+					Parameters: models.TextGraderParameters{Contains: []string{mustContain}},
+				},
+			},
+			Tasks: []string{"tasks/*.yaml"},
+		}
+	}
+
+	engine := &countingEngine{MockEngine: execution.NewMockEngine("mock-model")}
+	require.NoError(t, engine.Initialize(context.Background()))
+	run := func(spec *models.BenchmarkSpec) *models.EvaluationOutcome {
+		cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+		outcome, err := NewTestRunner(cfg, engine, WithCache(cache.New(cacheDir))).RunBenchmark(context.Background())
+		require.NoError(t, err)
+		return outcome
+	}
+
+	first := run(newSpec("explain caching"))
+	assert.Equal(t, 1, engine.calls)
+	assert.Equal(t, models.StatusPassed, first.TestOutcomes[0].Status)
+	assert.Equal(t, 1, cacheEntries(t, cacheDir, "response-"))
+	assert.Equal(t, 2, cacheEntries(t, cacheDir, "grader-"))
+
+	// Editing one grader misses the outcome cache but not the response cache
+	second := run(newSpec("not in the output"))
+	assert.Equal(t, 1, engine.calls, "engine should not run again when only a grader changed")
+	assert.Equal(t, models.StatusFailed, second.TestOutcomes[0].Status)
+	validations := second.TestOutcomes[0].Runs[0].Validations
+	assert.True(t, validations["stable"].Passed)
+	assert.False(t, validations["edited"].Passed)
+	assert.Equal(t, first.TestOutcomes[0].Runs[0].FinalOutput, second.TestOutcomes[0].Runs[0].FinalOutput)
+
+	// Only the edited grader produced a new result entry
+	assert.Equal(t, 3, cacheEntries(t, cacheDir, "grader-"))
+}
+
+func TestRunBenchmark_WorkspaceGradersSkipResponseCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, "tasks")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+	writeTaskFile(t, filepath.Join(tasksDir, "task.yaml"), `id: files
+name: Files
+inputs:
+  prompt: "write a file"
+`)
+	cacheDir := t.TempDir()
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "workspace-cache"},
+		SkillName:    "test-skill",
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Graders: []models.GraderConfig{
+			{
+				Kind:       models.GraderKindFile,
+				Identifier: "files",
+				Parameters: models.FileGraderParameters{MustNotExist: []string{"never.txt"}},
+			},
+		},
+		Tasks: []string{"tasks/*.yaml"},
+	}
+
+	engine := execution.NewMockEngine("mock-model")
+	require.NoError(t, engine.Initialize(context.Background()))
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	_, err := NewTestRunner(cfg, engine, WithCache(cache.New(cacheDir))).RunBenchmark(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, cacheEntries(t, cacheDir, "response-"))
+	assert.Equal(t, 0, cacheEntries(t, cacheDir, "grader-"))
+}
+
+func TestRunBenchmark_RedactSkipsResponseCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, "tasks")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+	writeTaskFile(t, filepath.Join(tasksDir, "task.yaml"), `id: redacted
+name: Redacted
+inputs:
+  prompt: "explain caching"
+`)
+	cacheDir := t.TempDir()
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "redact-cache"},
+		SkillName:    "test-skill",
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+			Redact:        []string{"secrets"},
+		},
+		Graders: []models.GraderConfig{
+			{
+				Kind:       models.GraderKindText,
+				Identifier: "mentions",
+				Parameters: models.TextGraderParameters{Contains: []string{"Mock response"}},
+			},
+		},
+		Tasks: []string{"tasks/*.yaml"},
+	}
+
+	engine := execution.NewMockEngine("mock-model")
+	require.NoError(t, engine.Initialize(context.Background()))
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	_, err := NewTestRunner(cfg, engine, WithCache(cache.New(cacheDir))).RunBenchmark(context.Background())
+	require.NoError(t, err)
+
+	// Raw responses would be unredacted on disk; the outcome entry is redacted
+	assert.Equal(t, 0, cacheEntries(t, cacheDir, "response-"))
+	assert.Equal(t, 0, cacheEntries(t, cacheDir, "grader-"))
+	assert.Equal(t, 1, cacheEntries(t, cacheDir, ""))
+}
+
+func TestRunBenchmark_RemoteCacheBackend(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, "tasks")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+	writeTaskFile(t, filepath.Join(tasksDir, "task.yaml"), `id: remote
+name: Remote
+inputs:
+  prompt: "explain caching"
+`)
+
+	var mu sync.Mutex
+	entries := map[string][]byte{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodPut {
+			entries[r.URL.Path], _ = io.ReadAll(r.Body)
+			return
+		}
+		data, ok := entries[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "remote-cache"},
+		SkillName:    "test-skill",
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Graders: []models.GraderConfig{{
+			Kind:       models.GraderKindText,
+			Identifier: "text",
+			Parameters: models.TextGraderParameters{Contains: []string{"Mock response"}},
+		}},
+		Tasks: []string{"tasks/*.yaml"},
+	}
+
+	engine := &countingEngine{MockEngine: execution.NewMockEngine("mock-model")}
+	require.NoError(t, engine.Initialize(context.Background()))
+	run := func(c *cache.Cache) *models.EvaluationOutcome {
+		cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+		outcome, err := NewTestRunner(cfg, engine, WithCache(c)).RunBenchmark(context.Background())
+		require.NoError(t, err)
+		return outcome
+	}
+
+	// A miss falls through to execution and fills the remote store
+	first := run(cache.NewWithBackend(cache.NewHTTPBackend(srv.URL, "")))
+	assert.Equal(t, 1, engine.calls)
+	assert.Equal(t, models.StatusPassed, first.TestOutcomes[0].Status)
+	assert.NotEmpty(t, entries)
+
+	// A fresh cache on the same store, as on another runner, hits
+	second := run(cache.NewWithBackend(cache.NewHTTPBackend(srv.URL, "")))
+	assert.Equal(t, 1, engine.calls, "engine should not run on a remote cache hit")
+	assert.Equal(t, models.StatusPassed, second.TestOutcomes[0].Status)
+
+	// An unreachable store behaves like a miss
+	srv.Close()
+	third := run(cache.NewWithBackend(cache.NewHTTPBackend(srv.URL, "")))
+	assert.Equal(t, 2, engine.calls)
+	assert.Equal(t, models.StatusPassed, third.TestOutcomes[0].Status)
+}
+
+func TestRunBenchmark_GraderCacheCoversTaskMetadata(t *testing.T) {
+	tmpDir := t.TempDir()
+	cacheDir := t.TempDir()
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "metadata-cache"},
+		SkillName:    "test-skill",
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Graders: []models.GraderConfig{
+			{
+				Kind:       models.GraderKindInlineScript,
+				Identifier: "expected-word",
+				Parameters: models.InlineScriptGraderParameters{Assertions: []string{"metadata['word'] in output"}},
+			},
+		},
+		Tasks: []string{"task.yaml"},
+	}
+	run := func(word string) *models.EvaluationOutcome {
+		writeTaskFile(t, filepath.Join(tmpDir, "task.yaml"), `id: task
+name: Task
+inputs:
+  prompt: "explain"
+metadata:
+  word: `+word+"\n")
+		cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+		outcome, err := NewTestRunner(cfg, execution.NewMockEngine("mock-model"), WithCache(cache.New(cacheDir))).RunBenchmark(context.Background())
+		require.NoError(t, err)
+		return outcome
+	}
+
+	require.Equal(t, models.StatusPassed, run("Mock").TestOutcomes[0].Status)
+	// The mock response is unchanged, so only the metadata can tell the grades apart
+	assert.Equal(t, models.StatusFailed, run("absent").TestOutcomes[0].Status,
+		"editing task metadata must not reuse the cached grade")
+}
@@ -0,0 +1,83 @@
+package orchestration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/microsoft/waza/internal/models"
+)
+
+// ResultsStream appends each completed task's TestOutcome to a JSONL file as
+// the run progresses, so a crash part way through a long run keeps the results
+// gathered so far. It's safe for concurrent use.
+type ResultsStream struct {
+	mu   sync.Mutex
+	file *os.File
+	err  error
+}
+
+// NewResultsStream opens path for appending, creating it and its parent
+// directories if needed.
+func NewResultsStream(path string) (*ResultsStream, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create results stream directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open results stream: %w", err)
+	}
+	return &ResultsStream{file: f}, nil
+}
+
+// Append writes outcome as one JSON line and syncs it to disk.
+func (s *ResultsStream) Append(outcome models.TestOutcome) error {
+	data, err := json.Marshal(outcome)
+	if err != nil {
+		return fmt.Errorf("marshal outcome %s: %w", outcome.TestID, err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(data); err != nil {
+		return fmt.Errorf("write results stream: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("sync results stream: %w", err)
+	}
+	return nil
+}
+
+// Listener returns a ProgressListener that appends the outcome of every
+// completed or cached task. The first write error is kept and reported by Close.
+func (s *ResultsStream) Listener() ProgressListener {
+	return func(event ProgressEvent) {
+		if event.EventType != EventTestComplete && event.EventType != EventTestCached {
+			return
+		}
+		outcome, ok := event.Details["outcome"].(models.TestOutcome)
+		if !ok {
+			return
+		}
+		if err := s.Append(outcome); err != nil {
+			s.mu.Lock()
+			if s.err == nil {
+				s.err = err
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Close closes the file and returns the first error hit while streaming.
+func (s *ResultsStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.file.Close(); err != nil && s.err == nil {
+		s.err = err
+	}
+	return s.err
+}
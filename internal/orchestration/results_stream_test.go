@@ -0,0 +1,86 @@
+package orchestration
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultsStream_OneLinePerTaskInParallel(t *testing.T) {
+	tmpDir := t.TempDir()
+	const numTasks = 8
+	for i := range numTasks {
+		writeTaskFile(t, filepath.Join(tmpDir, fmt.Sprintf("task-%d.yaml", i)), fmt.Sprintf(`id: task-%d
+name: Task %d
+inputs:
+  prompt: "hello"
+`, i, i))
+	}
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "stream"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			Concurrent:    true,
+			Workers:       4,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Tasks: []string{"*.yaml"},
+	}
+
+	streamPath := filepath.Join(tmpDir, "out", "results.jsonl")
+	stream, err := NewResultsStream(streamPath)
+	require.NoError(t, err)
+
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	runner := NewTestRunner(cfg, execution.NewMockEngine("mock-model"))
+	runner.OnProgress(stream.Listener())
+
+	_, err = runner.RunBenchmark(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Close())
+
+	f, err := os.Open(streamPath)
+	require.NoError(t, err)
+	defer f.Close() //nolint:errcheck
+
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var outcome models.TestOutcome
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &outcome))
+		assert.False(t, seen[outcome.TestID], "duplicate line for %s", outcome.TestID)
+		seen[outcome.TestID] = true
+		assert.Equal(t, models.StatusPassed, outcome.Status)
+	}
+	require.NoError(t, scanner.Err())
+	assert.Len(t, seen, numTasks)
+}
+
+func TestResultsStream_Appends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"test_id":"earlier"}`+"\n"), 0o644))
+
+	stream, err := NewResultsStream(path)
+	require.NoError(t, err)
+	require.NoError(t, stream.Append(models.TestOutcome{TestID: "later"}))
+	require.NoError(t, stream.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), "\n"))
+}
@@ -0,0 +1,94 @@
+package orchestration
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/microsoft/waza/internal/models"
+)
+
+// maxBackoffShift caps the exponent; the shifted delay itself saturates at
+// maxBackoffDelay, since a large base delay can overflow well before this.
+const maxBackoffShift = 30
+
+const maxBackoffDelay = time.Duration(math.MaxInt64)
+
+// retryPolicy decides whether and how long to wait before retrying a failed attempt.
+type retryPolicy struct {
+	maxAttempts int
+	baseDelay   time.Duration
+	jitter      models.RetryJitter
+	maxElapsed  time.Duration
+
+	// randFloat returns a value in [0, 1). Overridable for tests.
+	randFloat func() float64
+}
+
+func newRetryPolicy(cfg models.Config) retryPolicy {
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return retryPolicy{
+		maxAttempts: maxAttempts,
+		baseDelay:   time.Duration(cfg.RetryBackoffMs) * time.Millisecond,
+		jitter:      cfg.RetryJitter,
+		maxElapsed:  time.Duration(cfg.RetryMaxElapsedSec) * time.Second,
+		randFloat:   rand.Float64,
+	}
+}
+
+// backoff returns the delay to wait after the given (1-based) failed attempt.
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	if p.baseDelay <= 0 {
+		return 0
+	}
+	shift := min(max(attempt-1, 0), maxBackoffShift)
+	delay := maxBackoffDelay
+	if p.baseDelay <= maxBackoffDelay>>shift {
+		delay = p.baseDelay << shift
+	}
+
+	switch p.jitter {
+	case models.RetryJitterFull:
+		return time.Duration(p.randFloat() * float64(delay))
+	case models.RetryJitterEqual:
+		half := delay / 2
+		return half + time.Duration(p.randFloat()*float64(delay-half))
+	default:
+		return delay
+	}
+}
+
+// nextDelay reports the delay before the next attempt, given the number of attempts
+// made so far and the cumulative backoff already spent. ok is false when no more
+// retries should happen, either because attempts are exhausted or the elapsed cap
+// would be exceeded.
+func (p retryPolicy) nextDelay(attempt int, elapsed time.Duration) (delay time.Duration, ok bool) {
+	if attempt >= p.maxAttempts {
+		return 0, false
+	}
+	delay = p.backoff(attempt)
+	// Compared by subtraction so a saturated delay can't overflow the sum
+	if p.maxElapsed > 0 && delay > p.maxElapsed-elapsed {
+		return 0, false
+	}
+	return delay, true
+}
+
+// sleepContext waits for d or until ctx is done, returning ctx.Err() in the latter case.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
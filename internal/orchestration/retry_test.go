@@ -0,0 +1,141 @@
+package orchestration
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy_BackoffExponential(t *testing.T) {
+	p := newRetryPolicy(models.Config{MaxAttempts: 5, RetryBackoffMs: 100})
+
+	assert.Equal(t, 100*time.Millisecond, p.backoff(1))
+	assert.Equal(t, 200*time.Millisecond, p.backoff(2))
+	assert.Equal(t, 400*time.Millisecond, p.backoff(3))
+}
+
+func TestRetryPolicy_NoBackoffConfigured(t *testing.T) {
+	p := newRetryPolicy(models.Config{MaxAttempts: 3, RetryJitter: models.RetryJitterFull})
+	assert.Zero(t, p.backoff(1))
+	assert.Zero(t, p.backoff(2))
+}
+
+func TestRetryPolicy_JitterBounds(t *testing.T) {
+	tests := []struct {
+		name   string
+		jitter models.RetryJitter
+		lo, hi time.Duration
+	}{
+		{"full", models.RetryJitterFull, 0, 400 * time.Millisecond},
+		{"equal", models.RetryJitterEqual, 200 * time.Millisecond, 400 * time.Millisecond},
+		{"none", models.RetryJitterNone, 400 * time.Millisecond, 400 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newRetryPolicy(models.Config{MaxAttempts: 5, RetryBackoffMs: 100, RetryJitter: tt.jitter})
+
+			// attempt 3 → undithered delay of 400ms
+			for range 1000 {
+				d := p.backoff(3)
+				assert.GreaterOrEqual(t, d, tt.lo)
+				assert.LessOrEqual(t, d, tt.hi)
+			}
+
+			// extremes of the random source stay within bounds too
+			p.randFloat = func() float64 { return 0 }
+			assert.Equal(t, tt.lo, p.backoff(3))
+			p.randFloat = func() float64 { return 0.999999 }
+			assert.LessOrEqual(t, p.backoff(3), tt.hi)
+		})
+	}
+}
+
+func TestRetryPolicy_NextDelay(t *testing.T) {
+	p := newRetryPolicy(models.Config{MaxAttempts: 5, RetryBackoffMs: 400, RetryMaxElapsedSec: 1})
+
+	delay, ok := p.nextDelay(1, 0)
+	require.True(t, ok)
+	assert.Equal(t, 400*time.Millisecond, delay)
+
+	// 400ms already spent + 800ms next backoff exceeds the 1s cap
+	_, ok = p.nextDelay(2, 400*time.Millisecond)
+	assert.False(t, ok, "elapsed cap should stop retries even though attempts remain")
+
+	// attempts exhausted
+	_, ok = newRetryPolicy(models.Config{MaxAttempts: 2}).nextDelay(2, 0)
+	assert.False(t, ok)
+}
+
+func TestRetryPolicy_LargeBackoffSaturates(t *testing.T) {
+	// 10s doubled 30 times overflows int64 nanoseconds
+	p := newRetryPolicy(models.Config{MaxAttempts: 50, RetryBackoffMs: 10_000, RetryMaxElapsedSec: 60})
+
+	prev := time.Duration(0)
+	for attempt := 1; attempt < 50; attempt++ {
+		d := p.backoff(attempt)
+		require.Positive(t, d, "attempt %d", attempt)
+		require.GreaterOrEqual(t, d, prev, "attempt %d", attempt)
+		prev = d
+	}
+	assert.Equal(t, maxBackoffDelay, p.backoff(49))
+
+	for _, jitter := range []models.RetryJitter{models.RetryJitterFull, models.RetryJitterEqual} {
+		p.jitter = jitter
+		p.randFloat = func() float64 { return 0.999999 }
+		assert.Positive(t, p.backoff(49), "jitter %s", jitter)
+	}
+	p.jitter = models.RetryJitterNone
+
+	_, ok := p.nextDelay(40, 30*time.Second)
+	assert.False(t, ok, "a saturated delay must still respect the elapsed cap")
+}
+
+func TestRunBenchmark_RetryMaxElapsedHaltsEarly(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "task.yaml"), `id: task-retry
+name: Task Retry
+inputs:
+  prompt: "retry me"
+`)
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "retry-cap"},
+		Config: models.Config{
+			TrialsPerTask:      1,
+			TimeoutSec:         30,
+			EngineType:         "mock",
+			ModelID:            "mock-model",
+			MaxAttempts:        5,
+			RetryBackoffMs:     400,
+			RetryMaxElapsedSec: 1,
+		},
+		Graders: []models.GraderConfig{
+			{
+				Kind:       models.GraderKindText,
+				Identifier: "never-matches",
+				Parameters: models.TextGraderParameters{RegexMatch: []string{"NEVER_MATCH_12345"}},
+			},
+		},
+		Tasks: []string{"task.yaml"},
+	}
+
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	runner := NewTestRunner(cfg, execution.NewMockEngine("mock-model"))
+
+	outcome, err := runner.RunBenchmark(context.Background())
+	require.NoError(t, err)
+	require.Len(t, outcome.TestOutcomes, 1)
+	require.Len(t, outcome.TestOutcomes[0].Runs, 1)
+
+	run := outcome.TestOutcomes[0].Runs[0]
+	assert.Equal(t, models.StatusFailed, run.Status)
+	assert.Equal(t, 2, run.Attempts, "retries should stop once cumulative backoff would exceed the cap")
+}
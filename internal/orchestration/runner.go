@@ -2,7 +2,11 @@ package orchestration
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"iter"
+	"maps"
 	"math"
 	"os"
 	"path/filepath"
@@ -10,7 +14,9 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/google/uuid"
 	"github.com/microsoft/waza/internal/cache"
 	"github.com/microsoft/waza/internal/config"
 	"github.com/microsoft/waza/internal/dataset"
@@ -18,9 +24,9 @@ import (
 	"github.com/microsoft/waza/internal/graders"
 	"github.com/microsoft/waza/internal/hooks"
 	"github.com/microsoft/waza/internal/models"
+	"github.com/microsoft/waza/internal/redact"
 	"github.com/microsoft/waza/internal/template"
 	"github.com/microsoft/waza/internal/transcript"
-	"github.com/microsoft/waza/internal/utils"
 )
 
 // TestRunner orchestrates the execution of tests
@@ -44,9 +50,73 @@ type TestRunner struct {
 	// Skip grading (execution only)
 	skipGraders bool
 
+	// Warn about and skip task files that fail to load, set via WithSkipInvalidTasks
+	skipInvalidTasks bool
+
+	// Bound on concurrent grader runs across tasks, set via WithMaxConcurrentGraders
+	graderLimiter *graders.Limiter
+
+	// Bound on concurrent grader runs within one task, set via WithGraderConcurrencyPerTask
+	graderTaskLimit int
+
+	// Captured transcripts keyed by task ID; when set, runs are replayed instead of executed
+	replay map[string]*models.TaskTranscript
+
 	// Lifecycle hooks
 	hookRunner *hooks.Runner
 
+	// Workspace artifact capture, set via WithArtifactCapture
+	artifacts *ArtifactCapture
+
+	// Per-task weights for the weighted aggregate score, set via WithDifficultyWeights
+	difficultyWeights map[string]float64
+
+	// Per-grader weight factors, set via WithGraderReliability
+	graderReliability map[string]float64
+
+	// Glob patterns selecting which graders run, set via WithGraderTags
+	graderTags []string
+
+	// Fixture hash manifest, recorded when enabled via WithFixtureManifest
+	recordFixtures  bool
+	fixtureMu       sync.Mutex
+	fixtureManifest models.FixtureManifest
+
+	// Saved skills-disabled outcome, set via WithBaselineOutcome
+	baselineOutcome *models.EvaluationOutcome
+
+	// env_matrix environment exported to hooks, set via WithEnvironment
+	environment *models.MatrixEnvironment
+
+	// Task order seed, set via WithShuffle
+	shuffleSeed *uint64
+
+	// Fraction of cache hits to re-execute, set via WithVerifyCache
+	verifyCacheSample float64
+	cacheMismatchMu   sync.Mutex
+	cacheMismatches   []string
+
+	// Number of tasks to run (0 = all), set via WithFirstN
+	firstN int
+
+	// Window over which parallel workers start, set via WithRampUp
+	rampUp time.Duration
+	// rampWait blocks until offset after start; replaced in tests
+	rampWait func(ctx context.Context, start time.Time, offset time.Duration)
+
+	// waza build version recorded in outcome provenance, set via WithToolVersion
+	toolVersion string
+
+	// Redacts persisted copies of outputs, built from config.redact by RunBenchmark
+	redactor *redact.Redactor
+
+	// Non-fatal problems reported through warnf, copied into each outcome
+	warnMu   sync.Mutex
+	warnings []string
+
+	// Destination for human-readable notices, set via WithOutput
+	out io.Writer
+
 	// Progress tracking
 	progressMu sync.Mutex
 	listeners  []ProgressListener
@@ -116,6 +186,24 @@ func WithUpdateSnapshots(enabled bool) RunnerOption {
 	}
 }
 
+// WithMaxConcurrentGraders caps how many graders run at once across all of the
+// runner's tasks, so parallel tasks don't exceed a judge model's rate limits.
+// n <= 0 means no limit.
+func WithMaxConcurrentGraders(n int) RunnerOption {
+	return func(r *TestRunner) {
+		r.graderLimiter = graders.NewLimiter(n)
+	}
+}
+
+// WithGraderConcurrencyPerTask lets each task run up to n of its graders at
+// once instead of one at a time, so one task with many graders can't take
+// every slot of WithMaxConcurrentGraders. n <= 1 keeps graders sequential.
+func WithGraderConcurrencyPerTask(n int) RunnerOption {
+	return func(r *TestRunner) {
+		r.graderTaskLimit = n
+	}
+}
+
 // WithSkipGraders disables grading so only execution occurs.
 func WithSkipGraders() RunnerOption {
 	return func(r *TestRunner) {
@@ -123,6 +211,53 @@ func WithSkipGraders() RunnerOption {
 	}
 }
 
+// WithSkipInvalidTasks warns about and skips task files that fail to parse or
+// validate instead of aborting the run, so one broken file doesn't block the
+// rest of a suite. The run still fails if no task file loads.
+func WithSkipInvalidTasks() RunnerOption {
+	return func(r *TestRunner) {
+		r.skipInvalidTasks = true
+	}
+}
+
+// WithReplay grades previously captured transcripts (see transcript.LoadDir)
+// instead of executing tasks on the engine, so grader changes can be tested
+// without model nondeterminism.
+func WithReplay(transcripts map[string]*models.TaskTranscript) RunnerOption {
+	return func(r *TestRunner) {
+		r.replay = transcripts
+	}
+}
+
+// WithFixtureManifest records a content hash of every fixture file the tasks
+// reference into the outcome's FixtureManifest.
+func WithFixtureManifest() RunnerOption {
+	return func(r *TestRunner) {
+		r.recordFixtures = true
+	}
+}
+
+// WithBaselineOutcome compares the run against a previously saved
+// skills-disabled outcome instead of executing a second, skills-stripped
+// pass. If o is itself a baseline comparison, its BaselineOutcome is used.
+func WithBaselineOutcome(o *models.EvaluationOutcome) RunnerOption {
+	return func(r *TestRunner) {
+		if o != nil && o.BaselineOutcome != nil {
+			o = o.BaselineOutcome
+		}
+		r.baselineOutcome = o
+	}
+}
+
+// WithOutput sends the runner's human-readable notices (filter matches,
+// baseline banners, retry and error lines) to w instead of stdout, e.g.
+// io.Discard when stdout must carry only the outcome JSON.
+func WithOutput(w io.Writer) RunnerOption {
+	return func(r *TestRunner) {
+		r.out = w
+	}
+}
+
 // NewTestRunner creates a new test runner. The caller owns the engine and is responsible for initializing and shutting it down as needed.
 func NewTestRunner(cfg *config.BenchmarkConfig, engine execution.AgentEngine, opts ...RunnerOption) *TestRunner {
 	r := &TestRunner{
@@ -130,6 +265,8 @@ func NewTestRunner(cfg *config.BenchmarkConfig, engine execution.AgentEngine, op
 		engine:    engine,
 		verbose:   cfg.Verbose(),
 		listeners: []ProgressListener{},
+		rampWait:  waitUntilOffset,
+		out:       os.Stdout,
 	}
 	for _, o := range opts {
 		o(r)
@@ -145,8 +282,9 @@ func (r *TestRunner) OnProgress(listener ProgressListener) {
 }
 
 // testOutcomeDetails extracts score and duration from a TestOutcome for inclusion
-// in EventTestComplete Details.
-func testOutcomeDetails(o *models.TestOutcome) map[string]any {
+// in EventTestComplete and EventTestCached Details, along with a redacted copy
+// of the outcome itself.
+func (r *TestRunner) testOutcomeDetails(o *models.TestOutcome) map[string]any {
 	score := 0.0
 	durationMs := int64(0)
 	if o.Stats != nil {
@@ -156,6 +294,7 @@ func testOutcomeDetails(o *models.TestOutcome) map[string]any {
 	return map[string]any{
 		"score":       score,
 		"duration_ms": durationMs,
+		"outcome":     r.redactor.TestOutcome(*o),
 	}
 }
 
@@ -180,11 +319,35 @@ func (r *TestRunner) RunBenchmark(ctx context.Context) (*models.EvaluationOutcom
 
 	spec := r.cfg.Spec()
 
-	if spec.Baseline {
-		return r.runBaselineComparison(ctx)
+	redactor, err := redact.New(spec.Config.Redact)
+	if err != nil {
+		return nil, err
+	}
+	r.redactor = redactor
+	if r.replay != nil && len(spec.Config.Redact) > 0 {
+		r.warnf("config.redact is set, so the replayed transcripts hold redacted text; grades may differ from the original run")
+	}
+
+	var outcome *models.EvaluationOutcome
+	switch {
+	case r.baselineOutcome != nil:
+		outcome, err = r.runAgainstBaselineOutcome(ctx)
+	case spec.Baseline:
+		outcome, err = r.runBaselineComparison(ctx)
+	default:
+		outcome, err = r.runNormalBenchmark(ctx)
 	}
 
-	return r.runNormalBenchmark(ctx)
+	// Grading is done, so everything from here on sees only redacted text
+	r.redactor.Outcome(outcome)
+	return outcome, err
+}
+
+// NewRunID returns a unique ID for one evaluation run. It's the outcome's
+// eval_id and is stamped into every report written from that outcome, so
+// the results JSON, JUnit XML and summaries of a run can be matched up.
+func NewRunID() string {
+	return uuid.NewString()
 }
 
 // runNormalBenchmark executes a normal single-pass evaluation
@@ -193,15 +356,30 @@ func (r *TestRunner) runNormalBenchmark(ctx context.Context) (*models.Evaluation
 
 	// Set up hooks runner
 	spec := r.cfg.Spec()
-	r.hookRunner = &hooks.Runner{Verbose: r.verbose}
+	runID := NewRunID()
+	r.hookRunner = &hooks.Runner{
+		Verbose: r.verbose,
+		Env:     r.hookEnv(),
+	}
+	maps.Copy(r.hookRunner.Env, map[string]string{
+		hooks.EnvSpecDir: r.cfg.SpecDir(),
+		hooks.EnvModel:   spec.Config.ModelID,
+		hooks.EnvSkill:   spec.SkillName,
+		hooks.EnvRunID:   runID,
+	})
 
-	// Run after_run hooks on exit (even on error)
+	// Run after_run hooks on exit (even on error), then attach this pass's warnings
+	var outcome *models.EvaluationOutcome
+	warnStart := r.warningCount()
 	defer func() {
 		if len(spec.Hooks.AfterRun) > 0 {
 			if err := r.hookRunner.Execute(ctx, "after_run", spec.Hooks.AfterRun); err != nil {
-				fmt.Printf("[WARN] after_run hook error: %v\n", err)
+				r.warnf("after_run hook error: %v", err)
 			}
 		}
+		if outcome != nil {
+			outcome.Warnings = r.warningsSince(warnStart)
+		}
 	}()
 
 	// Run before_run hooks
@@ -216,63 +394,131 @@ func (r *TestRunner) runNormalBenchmark(ctx context.Context) (*models.Evaluation
 		return nil, err
 	}
 
-	// Load test cases
-	testCases, err := r.loadTestCases()
+	// Load test cases and apply task/tag filters
+	testCases, total, err := r.selectTestCases()
 	if err != nil {
-		return nil, fmt.Errorf("failed to load test cases: %w", err)
+		return nil, err
+	}
+
+	if total == 0 {
+		return nil, fmt.Errorf("no test cases found")
 	}
 
-	// Apply task/tag filters
-	if len(r.taskFilters) > 0 || len(r.tagFilters) > 0 {
-		testCases, err = FilterTestCases(testCases, r.taskFilters, r.tagFilters)
+	shuffled := r.shuffleSeed != nil && !spec.Config.Concurrent
+	if r.shuffleSeed != nil && !shuffled {
+		r.warnf("task shuffling only applies to sequential runs; ignoring it for this parallel run")
+	}
+	if shuffled {
+		ordered, err := shuffleTestCases(testCases, *r.shuffleSeed)
 		if err != nil {
-			return nil, fmt.Errorf("task/tag filter error: %w", err)
+			return nil, fmt.Errorf("failed to load test cases: %w", err)
 		}
-		fmt.Printf("Task and tag filters matched %d test(s):\n", len(testCases))
-		for _, tc := range testCases {
-			fmt.Printf("  • %s (%s)\n", tc.DisplayName, tc.TestID)
+		testCases = func(yield func(*models.TestCase, error) bool) {
+			for _, tc := range ordered {
+				if !yield(tc, nil) {
+					return
+				}
+			}
 		}
-		fmt.Println()
-	}
-
-	if len(testCases) == 0 {
-		return nil, fmt.Errorf("no test cases found")
 	}
+	testCases, total = r.limitTestCases(testCases, total)
 
 	r.notifyProgress(ProgressEvent{
 		EventType:  EventBenchmarkStart,
-		TotalTests: len(testCases),
+		TotalTests: total,
 	})
 
+	// Number the cases as they stream in; a read error stops the run early, as
+	// does a CSV that grew since it was counted, which would overrun the results
+	var streamErr error
+	indexed := func(yield func(int, *models.TestCase) bool) {
+		i := 0
+		for tc, err := range testCases {
+			if err != nil {
+				streamErr = err
+				return
+			}
+			if i >= total {
+				streamErr = fmt.Errorf("more than the %d task(s) counted before the run; was %s modified?", total, spec.TasksFrom)
+				return
+			}
+			if !yield(i, tc) {
+				return
+			}
+			i++
+		}
+	}
+
 	// Execute tests
 	var testOutcomes []models.TestOutcome
 
 	// Now that CopilotEngine is concurrency-safe (protected by mutex),
 	// we can safely use concurrent execution when configured
 	if spec.Config.Concurrent {
-		testOutcomes = r.runConcurrent(ctx, testCases)
+		testOutcomes = r.runConcurrent(ctx, indexed, total)
 	} else {
-		testOutcomes = r.runSequential(ctx, testCases)
+		testOutcomes = r.runSequential(ctx, indexed, total)
+	}
+
+	if streamErr != nil {
+		return nil, fmt.Errorf("failed to load test cases: %w", streamErr)
 	}
 
 	// Compute statistics
 	digest := BuildDigest(testOutcomes, time.Since(startTime).Milliseconds(), spec.Config.TrialsPerTask)
-	outcome := &models.EvaluationOutcome{
-		RunID:       fmt.Sprintf("run-%d", time.Now().Unix()),
+	if r.difficultyWeights != nil {
+		digest.WeightedScore = computeWeightedAggregateScore(testOutcomes, r.difficultyWeights)
+	}
+	if spec.Config.WeightByTrials {
+		digest.AggregateScore = computeTrialWeightedAggregateScore(testOutcomes)
+	} else if spec.Config.AggregateMethod != "" {
+		digest.AggregateScore = computeAggregateScoreBy(testOutcomes, spec.Config.AggregateMethod)
+	}
+	outcome = &models.EvaluationOutcome{
+		RunID:       runID,
 		SkillTested: spec.SkillName,
 		BenchName:   spec.Name,
 		Timestamp:   startTime,
 		Setup: models.OutcomeSetup{
-			RunsPerTest: spec.Config.TrialsPerTask,
-			ModelID:     spec.Config.ModelID,
-			EngineType:  spec.Config.EngineType,
-			TimeoutSec:  spec.Config.TimeoutSec,
-			JudgeModel:  spec.Config.JudgeModel,
+			RunsPerTest:       spec.Config.TrialsPerTask,
+			ModelID:           spec.Config.ModelID,
+			EngineType:        spec.Config.EngineType,
+			TimeoutSec:        spec.Config.TimeoutSec,
+			JudgeModel:        r.judgeModel(),
+			WeightMode:        spec.Config.WeightMode,
+			SlowTaskMs:        spec.Config.SlowTaskMs,
+			TrimOutliers:      spec.Config.TrimOutliers,
+			MinRunsForCI:      spec.Config.MinRunsForCI,
+			WeightByTrials:    spec.Config.WeightByTrials,
+			Environment:       r.environmentName(),
+			PassRateThreshold: spec.Config.PassRateThreshold,
+			AggregateMethod:   spec.Config.EffectiveAggregateMethod(),
 		},
 		Digest:       digest,
 		Measures:     make(map[string]models.MeasureResult),
 		TestOutcomes: testOutcomes,
 		Metadata:     make(map[string]any),
+		Provenance:   NewProvenance(r.toolVersion, spec.Config.EngineType, engineVersion(r.engine), spec.Config.ModelID, startTime),
+	}
+
+	if r.difficultyWeights != nil {
+		outcome.Metadata["difficulty_weighted"] = true
+	}
+	if r.graderReliability != nil {
+		outcome.Metadata["grader_reliability_weighted"] = true
+	}
+
+	if shuffled {
+		outcome.Metadata[MetadataShuffleSeed] = *r.shuffleSeed
+	}
+
+	if names := nonDeterministicGraders(spec.Graders, testOutcomes); len(names) > 0 {
+		outcome.Metadata[MetadataNonDeterministic] = true
+		outcome.Metadata[MetadataNonDeterministicGraders] = names
+	}
+
+	if r.recordFixtures {
+		outcome.FixtureManifest = r.fixtureManifestSnapshot()
 	}
 
 	r.notifyProgress(ProgressEvent{
@@ -280,6 +526,10 @@ func (r *TestRunner) runNormalBenchmark(ctx context.Context) (*models.Evaluation
 		DurationMs: time.Since(startTime).Milliseconds(),
 	})
 
+	if err := r.cacheVerifyError(); err != nil {
+		return nil, err
+	}
+
 	return outcome, nil
 }
 
@@ -289,14 +539,14 @@ func (r *TestRunner) runBaselineComparison(ctx context.Context) (*models.Evaluat
 
 	// Validation: eval must have skills configured
 	if len(spec.Config.SkillPaths) == 0 && len(spec.Config.RequiredSkills) == 0 {
-		fmt.Println("[WARN] --baseline specified but eval has no skills configured (skill_directories, required_skills empty). Skipping baseline comparison.")
+		fmt.Fprintln(r.out, "[WARN] --baseline specified but eval has no skills configured (skill_directories, required_skills empty). Skipping baseline comparison.")
 		return r.runNormalBenchmark(ctx)
 	}
 
 	// PASS 1: Skills-Enabled
-	fmt.Println("\n════════════════════════════════════════════════════════════════")
-	fmt.Println("PASS 1: Skills-Enabled Run")
-	fmt.Println("════════════════════════════════════════════════════════════════")
+	fmt.Fprintln(r.out, "\n════════════════════════════════════════════════════════════════")
+	fmt.Fprintln(r.out, "PASS 1: Skills-Enabled Run")
+	fmt.Fprintln(r.out, "════════════════════════════════════════════════════════════════")
 	outcomesWithSkills, err := r.runNormalBenchmark(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("skills-enabled run failed: %w", err)
@@ -312,9 +562,9 @@ func (r *TestRunner) runBaselineComparison(ctx context.Context) (*models.Evaluat
 		spec.Config.RequiredSkills = savedRequiredSkills
 	}()
 
-	fmt.Println("\n════════════════════════════════════════════════════════════════")
-	fmt.Println("PASS 2: Skills Baseline (skills stripped)")
-	fmt.Println("════════════════════════════════════════════════════════════════")
+	fmt.Fprintln(r.out, "\n════════════════════════════════════════════════════════════════")
+	fmt.Fprintln(r.out, "PASS 2: Skills Baseline (skills stripped)")
+	fmt.Fprintln(r.out, "════════════════════════════════════════════════════════════════")
 	outcomesWithoutSkills, err := r.runNormalBenchmark(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("baseline run (skills disabled) failed: %w", err)
@@ -328,6 +578,41 @@ func (r *TestRunner) runBaselineComparison(ctx context.Context) (*models.Evaluat
 	return r.mergeBaselineOutcomes(outcomesWithSkills, outcomesWithoutSkills)
 }
 
+// runAgainstBaselineOutcome runs the skills-enabled pass and merges it with
+// the saved baseline outcome from WithBaselineOutcome.
+func (r *TestRunner) runAgainstBaselineOutcome(ctx context.Context) (*models.EvaluationOutcome, error) {
+	outcomesWithSkills, err := r.runNormalBenchmark(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("skills-enabled run failed: %w", err)
+	}
+	return r.mergeBaselineOutcomes(outcomesWithSkills, baselineForTasks(r.baselineOutcome, outcomesWithSkills))
+}
+
+// baselineForTasks narrows a saved baseline to the tasks that ran, so a
+// filtered run (--task, --tags, --first-n) can be compared against a baseline
+// of the full suite. The digest is rebuilt over the remaining tasks; baseline
+// is returned unchanged when it has no extra tasks.
+func baselineForTasks(baseline, ran *models.EvaluationOutcome) *models.EvaluationOutcome {
+	ranIDs := make(map[string]bool, len(ran.TestOutcomes))
+	for _, to := range ran.TestOutcomes {
+		ranIDs[to.TestID] = true
+	}
+	var kept []models.TestOutcome
+	for _, to := range baseline.TestOutcomes {
+		if ranIDs[to.TestID] {
+			kept = append(kept, to)
+		}
+	}
+	if len(kept) == len(baseline.TestOutcomes) {
+		return baseline
+	}
+
+	narrowed := *baseline
+	narrowed.TestOutcomes = kept
+	narrowed.Digest = BuildDigest(kept, baseline.Digest.DurationMs, baseline.Setup.RunsPerTest)
+	return &narrowed
+}
+
 // mergeBaselineOutcomes pairs task results and computes skill impact
 func (r *TestRunner) mergeBaselineOutcomes(
 	withSkills, withoutSkills *models.EvaluationOutcome,
@@ -408,29 +693,29 @@ func computePassRate(outcome *models.TestOutcome) float64 {
 
 // printSkillImpactReport prints the A/B comparison summary
 func (r *TestRunner) printSkillImpactReport(withSkills, withoutSkills *models.EvaluationOutcome) {
-	fmt.Println("\n════════════════════════════════════════════════════════════════")
-	fmt.Println("SKILL IMPACT ANALYSIS")
-	fmt.Println("════════════════════════════════════════════════════════════════")
+	fmt.Fprintln(r.out, "\n════════════════════════════════════════════════════════════════")
+	fmt.Fprintln(r.out, "SKILL IMPACT ANALYSIS")
+	fmt.Fprintln(r.out, "════════════════════════════════════════════════════════════════")
 
 	withPassRate := withSkills.Digest.SuccessRate
 	withoutPassRate := withoutSkills.Digest.SuccessRate
 	delta := withPassRate - withoutPassRate
 
-	fmt.Printf("Overall Performance Delta:\n")
-	fmt.Printf("  With Skills:    %.1f%% (%d/%d tasks passed)\n",
+	fmt.Fprintf(r.out, "Overall Performance Delta:\n")
+	fmt.Fprintf(r.out, "  With Skills:    %.1f%% (%d/%d tasks passed)\n",
 		withPassRate*100, withSkills.Digest.Succeeded, withSkills.Digest.TotalTests)
-	fmt.Printf("  Without Skills: %.1f%% (%d/%d tasks passed)\n",
+	fmt.Fprintf(r.out, "  Without Skills: %.1f%% (%d/%d tasks passed)\n",
 		withoutPassRate*100, withoutSkills.Digest.Succeeded, withoutSkills.Digest.TotalTests)
 
 	if delta > 0 {
-		fmt.Printf("  Impact:         +%.1f percentage points\n\n", delta*100)
+		fmt.Fprintf(r.out, "  Impact:         +%.1f percentage points\n\n", delta*100)
 	} else if delta < 0 {
-		fmt.Printf("  Impact:         %.1f percentage points\n\n", delta*100)
+		fmt.Fprintf(r.out, "  Impact:         %.1f percentage points\n\n", delta*100)
 	} else {
-		fmt.Printf("  Impact:         no change\n\n")
+		fmt.Fprintf(r.out, "  Impact:         no change\n\n")
 	}
 
-	fmt.Println("Per-Task Breakdown:")
+	fmt.Fprintln(r.out, "Per-Task Breakdown:")
 	improved := 0
 	regressed := 0
 	neutral := 0
@@ -453,7 +738,7 @@ func (r *TestRunner) printSkillImpactReport(withSkills, withoutSkills *models.Ev
 			neutral++
 		}
 
-		fmt.Printf("  • %-30s %s  %.0f%% → %.0f%% (%+.0fpp)\n",
+		fmt.Fprintf(r.out, "  • %-30s %s  %.0f%% → %.0f%% (%+.0fpp)\n",
 			to.DisplayName,
 			status,
 			impact.PassRateBaseline*100,
@@ -462,31 +747,143 @@ func (r *TestRunner) printSkillImpactReport(withSkills, withoutSkills *models.Ev
 		)
 	}
 
-	fmt.Println()
+	fmt.Fprintln(r.out)
 	if delta > 0 {
-		fmt.Printf("Verdict: Skills have POSITIVE IMPACT (improved %d/%d tasks)\n", improved, len(withSkills.TestOutcomes))
+		fmt.Fprintf(r.out, "Verdict: Skills have POSITIVE IMPACT (improved %d/%d tasks)\n", improved, len(withSkills.TestOutcomes))
 	} else if delta < 0 {
-		fmt.Printf("Verdict: Skills have NEGATIVE IMPACT (regressed %d/%d tasks)\n", regressed, len(withSkills.TestOutcomes))
+		fmt.Fprintf(r.out, "Verdict: Skills have NEGATIVE IMPACT (regressed %d/%d tasks)\n", regressed, len(withSkills.TestOutcomes))
 	} else {
-		fmt.Printf("Verdict: Skills have NEUTRAL IMPACT (no net change)\n")
+		fmt.Fprintf(r.out, "Verdict: Skills have NEUTRAL IMPACT (no net change)\n")
 	}
-	fmt.Println("════════════════════════════════════════════════════════════════")
+	fmt.Fprintln(r.out, "════════════════════════════════════════════════════════════════")
 }
 
-func (r *TestRunner) loadTestCases() ([]*models.TestCase, error) {
-	spec := r.cfg.Spec()
+// selectTestCases returns the test cases to run, after task/tag filtering, along with
+// how many there are. CSV datasets are streamed rather than loaded: a counting pass
+// validates every row up front, and the returned sequence re-reads the file during
+// execution so the rows are never all held in memory at once.
+func (r *TestRunner) selectTestCases() (iter.Seq2[*models.TestCase, error], int, error) {
+	filtering := len(r.taskFilters) > 0 || len(r.tagFilters) > 0
+
+	if r.cfg.Spec().TasksFrom == "" {
+		testCases, err := r.loadTestCasesFromFiles()
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to load test cases: %w", err)
+		}
+		if filtering {
+			testCases, err = FilterTestCases(testCases, r.taskFilters, r.tagFilters)
+			if err != nil {
+				return nil, 0, fmt.Errorf("task/tag filter error: %w", err)
+			}
+			r.printFilterMatches(testCases)
+		}
+		var templateErrs []error
+		for _, tc := range testCases {
+			templateErrs = append(templateErrs, r.checkTemplates(tc)...)
+		}
+		if err := templatePreflightError(templateErrs); err != nil {
+			return nil, 0, err
+		}
+		seq := func(yield func(*models.TestCase, error) bool) {
+			for _, tc := range testCases {
+				if !yield(tc, nil) {
+					return
+				}
+			}
+		}
+		return seq, len(testCases), nil
+	}
 
-	// CSV dataset path: generate tasks from CSV rows
-	if spec.TasksFrom != "" {
-		return r.loadTestCasesFromCSV()
+	stream, err := r.streamTestCasesFromCSV()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load test cases: %w", err)
 	}
 
-	// Fall through to existing Tasks []string behavior
-	return r.loadTestCasesFromFiles()
+	// Counting pass: surfaces parse, filter and template errors before any task
+	// runs. Template errors are collected so every bad row is reported at once.
+	total := 0
+	var matched []*models.TestCase
+	var templateErrs []error
+	for tc, err := range stream {
+		if errors.Is(err, errRowTemplate) {
+			templateErrs = append(templateErrs, err)
+			continue
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to load test cases: %w", err)
+		}
+		ok, err := matchesFilters(tc, r.taskFilters, r.tagFilters)
+		if err != nil {
+			return nil, 0, fmt.Errorf("task/tag filter error: %w", err)
+		}
+		if !ok {
+			continue
+		}
+		if errs := r.checkTemplates(tc); len(errs) > 0 {
+			templateErrs = append(templateErrs, errs...)
+			continue
+		}
+		total++
+		if filtering {
+			// Only the names are printed, so keep a stripped-down copy
+			matched = append(matched, &models.TestCase{TestID: tc.TestID, DisplayName: tc.DisplayName})
+		}
+	}
+	if err := templatePreflightError(templateErrs); err != nil {
+		return nil, 0, err
+	}
+	if filtering {
+		r.printFilterMatches(matched)
+	}
+
+	seq := func(yield func(*models.TestCase, error) bool) {
+		for tc, err := range stream {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			ok, err := matchesFilters(tc, r.taskFilters, r.tagFilters)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if ok && !yield(tc, nil) {
+				return
+			}
+		}
+	}
+	return seq, total, nil
+}
+
+func (r *TestRunner) printFilterMatches(testCases []*models.TestCase) {
+	fmt.Fprintf(r.out, "Task and tag filters matched %d test(s):\n", len(testCases))
+	for _, tc := range testCases {
+		fmt.Fprintf(r.out, "  • %s (%s)\n", tc.DisplayName, tc.TestID)
+	}
+	fmt.Fprintln(r.out)
 }
 
 // loadTestCasesFromCSV generates in-memory TestCases from CSV rows.
 func (r *TestRunner) loadTestCasesFromCSV() ([]*models.TestCase, error) {
+	stream, err := r.streamTestCasesFromCSV()
+	if err != nil {
+		return nil, err
+	}
+
+	testCases := []*models.TestCase{}
+	for tc, err := range stream {
+		if err != nil {
+			return nil, err
+		}
+		testCases = append(testCases, tc)
+	}
+	return testCases, nil
+}
+
+// streamTestCasesFromCSV validates the tasks_from path and range, then returns a
+// sequence that reads the CSV one row at a time, generating a TestCase per row.
+// Each iteration re-opens the file.
+func (r *TestRunner) streamTestCasesFromCSV() (iter.Seq2[*models.TestCase, error], error) {
 	spec := r.cfg.Spec()
 
 	// Resolve CSV path relative to spec directory
@@ -512,8 +909,8 @@ func (r *TestRunner) loadTestCasesFromCSV() ([]*models.TestCase, error) {
 		return nil, fmt.Errorf("tasks_from path %q escapes spec directory", spec.TasksFrom)
 	}
 
-	// Validate and load CSV with optional range filtering
-	var rows []dataset.Row
+	// Validate optional range filtering
+	var rows iter.Seq2[dataset.Row, error]
 	if spec.Range != [2]int{} {
 		if spec.Range[0] <= 0 || spec.Range[1] <= 0 {
 			return nil, fmt.Errorf("invalid range: both values must be > 0, got [%d, %d]", spec.Range[0], spec.Range[1])
@@ -521,12 +918,9 @@ func (r *TestRunner) loadTestCasesFromCSV() ([]*models.TestCase, error) {
 		if spec.Range[0] > spec.Range[1] {
 			return nil, fmt.Errorf("invalid range: start (%d) must be <= end (%d)", spec.Range[0], spec.Range[1])
 		}
-		rows, err = dataset.LoadCSVRange(csvPath, spec.Range[0], spec.Range[1])
+		rows = dataset.StreamCSVRange(csvPath, spec.Range[0], spec.Range[1])
 	} else {
-		rows, err = dataset.LoadCSV(csvPath)
-	}
-	if err != nil {
-		return nil, fmt.Errorf("loading CSV dataset: %w", err)
+		rows = dataset.StreamCSV(csvPath)
 	}
 
 	// Build template context for resolving templates
@@ -534,68 +928,84 @@ func (r *TestRunner) loadTestCasesFromCSV() ([]*models.TestCase, error) {
 	baseCtx := &template.Context{
 		JobID:     fmt.Sprintf("run-%d", now.Unix()),
 		Timestamp: now.Format(time.RFC3339),
-		Vars:      make(map[string]string),
 	}
 
-	// Merge spec.Inputs as base variables
-	for k, v := range spec.Inputs {
-		baseCtx.Vars[k] = v
-	}
-
-	testCases := make([]*models.TestCase, 0, len(rows))
-	for i, row := range rows {
-		rowNum := i + 1
+	return func(yield func(*models.TestCase, error) bool) {
+		rowNum := 0
+		for row, err := range rows {
+			if err != nil {
+				yield(nil, fmt.Errorf("loading CSV dataset: %w", err))
+				return
+			}
+			rowNum++
 
-		// Determine TestID: prefer "id" column, then "name", then "row-N"
-		testID := fmt.Sprintf("row-%d", rowNum)
-		if v, ok := row["id"]; ok && v != "" {
-			testID = v
-		} else if v, ok := row["name"]; ok && v != "" {
-			testID = v
+			tc, err := csvRowTestCase(row, rowNum, spec.Inputs, baseCtx)
+			if err != nil {
+				// A bad prompt template doesn't stop the rows after it
+				if !yield(nil, err) {
+					return
+				}
+				continue
+			}
+			if !yield(tc, nil) {
+				return
+			}
 		}
+	}, nil
+}
 
-		// Determine DisplayName: prefer "name" column, then "row-N"
-		displayName := fmt.Sprintf("row-%d", rowNum)
-		if v, ok := row["name"]; ok && v != "" {
-			displayName = v
-		}
+// errRowTemplate marks a CSV row whose prompt template doesn't render.
+var errRowTemplate = errors.New("resolving prompt template")
 
-		// Build per-row template context: inputs + CSV row (CSV overrides inputs on conflict)
-		rowCtx := &template.Context{
-			JobID:     baseCtx.JobID,
-			TaskName:  displayName,
-			Iteration: 0,
-			Attempt:   0,
-			Timestamp: baseCtx.Timestamp,
-			Vars:      make(map[string]string),
-		}
-		for k, v := range spec.Inputs {
-			rowCtx.Vars[k] = v
-		}
-		for k, v := range row {
-			rowCtx.Vars[k] = v
-		}
+// csvRowTestCase builds the TestCase for a single CSV row.
+func csvRowTestCase(row dataset.Row, rowNum int, inputs map[string]string, baseCtx *template.Context) (*models.TestCase, error) {
+	// Determine TestID: prefer "id" column, then "name", then "row-N"
+	testID := fmt.Sprintf("row-%d", rowNum)
+	if v, ok := row["id"]; ok && v != "" {
+		testID = v
+	} else if v, ok := row["name"]; ok && v != "" {
+		testID = v
+	}
 
-		// Resolve prompt: use "prompt" column if present, otherwise empty
-		prompt := row["prompt"]
-		if strings.Contains(prompt, "{{") {
-			prompt, err = template.Render(prompt, rowCtx)
-			if err != nil {
-				return nil, fmt.Errorf("resolving prompt template for row %d: %w", rowNum, err)
-			}
-		}
+	// Determine DisplayName: prefer "name" column, then "row-N"
+	displayName := fmt.Sprintf("row-%d", rowNum)
+	if v, ok := row["name"]; ok && v != "" {
+		displayName = v
+	}
 
-		tc := &models.TestCase{
-			TestID:      testID,
-			DisplayName: displayName,
-			Stimulus: models.TestStimulus{
-				Message: prompt,
-			},
+	// Build per-row template context: inputs + CSV row (CSV overrides inputs on conflict)
+	rowCtx := &template.Context{
+		JobID:     baseCtx.JobID,
+		TaskName:  displayName,
+		Iteration: 0,
+		Attempt:   0,
+		Timestamp: baseCtx.Timestamp,
+		Vars:      make(map[string]string),
+	}
+	for k, v := range inputs {
+		rowCtx.Vars[k] = v
+	}
+	for k, v := range row {
+		rowCtx.Vars[k] = v
+	}
+
+	// Resolve prompt: use "prompt" column if present, otherwise empty
+	prompt := row["prompt"]
+	if strings.Contains(prompt, "{{") {
+		var err error
+		prompt, err = template.Render(prompt, rowCtx)
+		if err != nil {
+			return nil, fmt.Errorf("%w for row %d: %w", errRowTemplate, rowNum, err)
 		}
-		testCases = append(testCases, tc)
 	}
 
-	return testCases, nil
+	return &models.TestCase{
+		TestID:      testID,
+		DisplayName: displayName,
+		Stimulus: models.TestStimulus{
+			Message: prompt,
+		},
+	}, nil
 }
 
 // loadTestCasesFromFiles loads test cases from YAML files via glob patterns.
@@ -624,9 +1034,15 @@ func (r *TestRunner) loadTestCasesFromFiles() ([]*models.TestCase, error) {
 	}
 
 	var testCases []*models.TestCase
+	skipped := 0
 	for _, path := range testFiles {
 		tc, err := models.LoadTestCase(path)
 		if err != nil {
+			if r.skipInvalidTasks {
+				r.warnf("skipping invalid task file %s: %v", path, err)
+				skipped++
+				continue
+			}
 			return nil, fmt.Errorf("failed to load test case %s: %w", path, err)
 		}
 		// Only include active test cases
@@ -636,6 +1052,9 @@ func (r *TestRunner) loadTestCasesFromFiles() ([]*models.TestCase, error) {
 		}
 	}
 
+	if skipped == len(testFiles) {
+		return nil, fmt.Errorf("all %d task files failed to load", skipped)
+	}
 	return testCases, nil
 }
 
@@ -655,7 +1074,7 @@ func (r *TestRunner) validateRequiredSkills() error {
 	}
 
 	// Resolve skill paths
-	resolvedPaths := utils.ResolvePaths(spec.Config.SkillPaths, baseDir)
+	resolvedPaths := spec.ResolveSkillPaths(baseDir)
 
 	// If required skills specified but no skill directories, that's an error
 	if len(resolvedPaths) == 0 {
@@ -674,15 +1093,15 @@ func (r *TestRunner) validateRequiredSkills() error {
 	}
 
 	if r.verbose {
-		fmt.Printf("✓ Required skills validation passed (%d/%d skills found)\n\n",
+		fmt.Fprintf(r.out, "✓ Required skills validation passed (%d/%d skills found)\n\n",
 			len(spec.Config.RequiredSkills), len(spec.Config.RequiredSkills))
 	}
 
 	return nil
 }
 
-func (r *TestRunner) runSequential(ctx context.Context, testCases []*models.TestCase) []models.TestOutcome {
-	outcomes := make([]models.TestOutcome, 0, len(testCases))
+func (r *TestRunner) runSequential(ctx context.Context, testCases iter.Seq2[int, *models.TestCase], total int) []models.TestOutcome {
+	outcomes := make([]models.TestOutcome, 0, total)
 	spec := r.cfg.Spec()
 
 	for i, tc := range testCases {
@@ -705,19 +1124,21 @@ func (r *TestRunner) runSequential(ctx context.Context, testCases []*models.Test
 		if r.hookRunner != nil && len(spec.Hooks.BeforeTask) > 0 {
 			if err := r.hookRunner.Execute(ctx, "before_task", spec.Hooks.BeforeTask); err != nil {
 				// before_task failure with error_on_fail: mark task as failed and skip
-				outcomes = append(outcomes, models.TestOutcome{
+				failed := models.TestOutcome{
 					TestID:      tc.TestID,
 					DisplayName: tc.DisplayName,
+					Tags:        tc.Tags,
 					Status:      models.StatusFailed,
 					Runs:        []models.RunResult{},
-				})
+				}
+				outcomes = append(outcomes, failed)
 				r.notifyProgress(ProgressEvent{
 					EventType:  EventTestComplete,
 					TestName:   tc.DisplayName,
 					TestNum:    i + 1,
-					TotalTests: len(testCases),
+					TotalTests: total,
 					Status:     models.StatusFailed,
-					Details:    map[string]any{"score": 0.0, "duration_ms": int64(0)},
+					Details:    r.testOutcomeDetails(&failed),
 				})
 				continue
 			}
@@ -727,18 +1148,18 @@ func (r *TestRunner) runSequential(ctx context.Context, testCases []*models.Test
 			EventType:  EventTestStart,
 			TestName:   tc.DisplayName,
 			TestNum:    i + 1,
-			TotalTests: len(testCases),
+			TotalTests: total,
 		})
 
 		taskStart := time.Now()
-		outcome, wasCached := r.runTest(ctx, tc, i+1, len(testCases))
+		outcome, wasCached := r.runTest(ctx, tc, i+1, total)
 		r.writeTaskTranscript(tc, outcome, taskStart)
 		outcomes = append(outcomes, outcome)
 
 		// Run after_task hooks
 		if r.hookRunner != nil && len(spec.Hooks.AfterTask) > 0 {
 			if err := r.hookRunner.Execute(ctx, "after_task", spec.Hooks.AfterTask); err != nil {
-				fmt.Printf("[WARN] after_task hook error for %s: %v\n", tc.DisplayName, err)
+				r.warnf("after_task hook error for %s: %v", tc.DisplayName, err)
 			}
 		}
 
@@ -748,17 +1169,18 @@ func (r *TestRunner) runSequential(ctx context.Context, testCases []*models.Test
 				EventType:  EventTestCached,
 				TestName:   tc.DisplayName,
 				TestNum:    i + 1,
-				TotalTests: len(testCases),
+				TotalTests: total,
 				Status:     outcome.Status,
+				Details:    r.testOutcomeDetails(&outcome),
 			})
 		} else {
 			r.notifyProgress(ProgressEvent{
 				EventType:  EventTestComplete,
 				TestName:   tc.DisplayName,
 				TestNum:    i + 1,
-				TotalTests: len(testCases),
+				TotalTests: total,
 				Status:     outcome.Status,
-				Details:    testOutcomeDetails(&outcome),
+				Details:    r.testOutcomeDetails(&outcome),
 			})
 		}
 	}
@@ -766,7 +1188,7 @@ func (r *TestRunner) runSequential(ctx context.Context, testCases []*models.Test
 	return outcomes
 }
 
-func (r *TestRunner) runConcurrent(ctx context.Context, testCases []*models.TestCase) []models.TestOutcome {
+func (r *TestRunner) runConcurrent(ctx context.Context, testCases iter.Seq2[int, *models.TestCase], total int) []models.TestOutcome {
 	// Simple concurrent implementation
 	spec := r.cfg.Spec()
 	workers := spec.Config.Workers
@@ -779,35 +1201,44 @@ func (r *TestRunner) runConcurrent(ctx context.Context, testCases []*models.Test
 		outcome models.TestOutcome
 	}
 
-	resultChan := make(chan result, len(testCases))
+	resultChan := make(chan result, total)
 	semaphore := make(chan struct{}, workers)
 
 	var wg sync.WaitGroup
 
+	rampStart := time.Now()
+	launched := 0
 	for i, tc := range testCases {
+		// Acquire before spawning so only `workers` cases are in flight at once;
+		// this also paces how fast a streamed dataset is read.
+		semaphore <- struct{}{}
+		if offset := rampOffset(launched, workers, r.rampUp); offset > 0 {
+			r.rampWait(ctx, rampStart, offset)
+		}
+		launched++
 		wg.Add(1)
 		go func(idx int, test *models.TestCase) {
 			defer wg.Done()
-
-			semaphore <- struct{}{}
 			defer func() { <-semaphore }()
 
 			// Run before_task hooks
 			if r.hookRunner != nil && len(spec.Hooks.BeforeTask) > 0 {
 				if err := r.hookRunner.Execute(ctx, "before_task", spec.Hooks.BeforeTask); err != nil {
-					resultChan <- result{index: idx, outcome: models.TestOutcome{
+					failed := models.TestOutcome{
 						TestID:      test.TestID,
 						DisplayName: test.DisplayName,
+						Tags:        test.Tags,
 						Status:      models.StatusFailed,
 						Runs:        []models.RunResult{},
-					}}
+					}
+					resultChan <- result{index: idx, outcome: failed}
 					r.notifyProgress(ProgressEvent{
 						EventType:  EventTestComplete,
 						TestName:   test.DisplayName,
 						TestNum:    idx + 1,
-						TotalTests: len(testCases),
+						TotalTests: total,
 						Status:     models.StatusFailed,
-						Details:    map[string]any{"score": 0.0, "duration_ms": int64(0)},
+						Details:    r.testOutcomeDetails(&failed),
 					})
 					return
 				}
@@ -817,18 +1248,18 @@ func (r *TestRunner) runConcurrent(ctx context.Context, testCases []*models.Test
 				EventType:  EventTestStart,
 				TestName:   test.DisplayName,
 				TestNum:    idx + 1,
-				TotalTests: len(testCases),
+				TotalTests: total,
 			})
 
 			taskStart := time.Now()
-			outcome, wasCached := r.runTest(ctx, test, idx+1, len(testCases))
+			outcome, wasCached := r.runTest(ctx, test, idx+1, total)
 			r.writeTaskTranscript(test, outcome, taskStart)
 			resultChan <- result{index: idx, outcome: outcome}
 
 			// Run after_task hooks
 			if r.hookRunner != nil && len(spec.Hooks.AfterTask) > 0 {
 				if err := r.hookRunner.Execute(ctx, "after_task", spec.Hooks.AfterTask); err != nil {
-					fmt.Printf("[WARN] after_task hook error for %s: %v\n", test.DisplayName, err)
+					r.warnf("after_task hook error for %s: %v", test.DisplayName, err)
 				}
 			}
 
@@ -837,17 +1268,18 @@ func (r *TestRunner) runConcurrent(ctx context.Context, testCases []*models.Test
 					EventType:  EventTestCached,
 					TestName:   test.DisplayName,
 					TestNum:    idx + 1,
-					TotalTests: len(testCases),
+					TotalTests: total,
 					Status:     outcome.Status,
+					Details:    r.testOutcomeDetails(&outcome),
 				})
 			} else {
 				r.notifyProgress(ProgressEvent{
 					EventType:  EventTestComplete,
 					TestName:   test.DisplayName,
 					TestNum:    idx + 1,
-					TotalTests: len(testCases),
+					TotalTests: total,
 					Status:     outcome.Status,
-					Details:    testOutcomeDetails(&outcome),
+					Details:    r.testOutcomeDetails(&outcome),
 				})
 			}
 		}(i, tc)
@@ -859,7 +1291,7 @@ func (r *TestRunner) runConcurrent(ctx context.Context, testCases []*models.Test
 	}()
 
 	// Collect results
-	results := make([]models.TestOutcome, len(testCases))
+	results := make([]models.TestOutcome, total)
 	for res := range resultChan {
 		results[res.index] = res.outcome
 	}
@@ -870,19 +1302,28 @@ func (r *TestRunner) runConcurrent(ctx context.Context, testCases []*models.Test
 func (r *TestRunner) runTest(ctx context.Context, tc *models.TestCase, testNum, totalTests int) (models.TestOutcome, bool) {
 	spec := r.cfg.Spec()
 
+	if r.recordFixtures {
+		r.recordFixtureHashes(tc)
+	}
+
 	// Check cache if enabled
 	if r.cache != nil {
-		cacheKey, err := cache.CacheKey(spec, tc, r.cfg.FixtureDir())
+		// Key on the graders that will run, so --grader-tags subsets don't share entries
+		cacheKey, err := r.outcomeCacheKey(spec, tc)
 		if err == nil {
 			if cachedOutcome, found := r.cache.Get(cacheKey); found {
+				if r.sampleCacheHit() {
+					return r.verifyCachedOutcome(ctx, tc, *cachedOutcome, testNum, totalTests), false
+				}
 				// Return cached outcome with cached flag
 				return *cachedOutcome, true
 			}
 			// Run the test and cache the result
 			outcome := r.runTestUncached(ctx, tc, testNum, totalTests)
-			// Store in cache and log any failures
-			if err := r.cache.Put(cacheKey, &outcome); err != nil {
-				fmt.Fprintf(os.Stderr, "[WARN] Failed to write cache for test %q: %v\n", tc.DisplayName, err)
+			// Store in cache and log any failures; the cache is on disk, so it gets the redacted copy
+			cached := r.redactor.TestOutcome(outcome)
+			if err := r.cache.Put(cacheKey, &cached); err != nil {
+				r.warnf("Failed to write cache for test %q: %v", tc.DisplayName, err)
 			}
 			return outcome, false
 		}
@@ -898,19 +1339,16 @@ func (r *TestRunner) writeTaskTranscript(tc *models.TestCase, outcome models.Tes
 		return
 	}
 
-	taskTranscript := transcript.BuildTaskTranscript(tc, outcome, startTime)
-	if _, err := transcript.Write(transcriptDir, taskTranscript); err != nil {
-		fmt.Fprintf(os.Stderr, "[WARN] Failed to write transcript for %q: %v\n", tc.DisplayName, err)
+	taskTranscript := r.redactor.Transcript(transcript.BuildTaskTranscript(tc, outcome, startTime))
+	if _, err := transcript.WriteNamed(transcriptDir, taskTranscript, r.cfg.TranscriptNameTemplate()); err != nil {
+		r.warnf("Failed to write transcript for %q: %v", tc.DisplayName, err)
 	}
 }
 
 func (r *TestRunner) runTestUncached(ctx context.Context, tc *models.TestCase, testNum, totalTests int) models.TestOutcome {
 	spec := r.cfg.Spec()
 	runsPerTest := spec.Config.TrialsPerTask
-	maxAttempts := spec.Config.MaxAttempts
-	if maxAttempts < 1 {
-		maxAttempts = 1
-	}
+	policy := newRetryPolicy(spec.Config)
 
 	runs := make([]models.RunResult, 0, runsPerTest)
 
@@ -925,7 +1363,8 @@ func (r *TestRunner) runTestUncached(ctx context.Context, tc *models.TestCase, t
 		})
 
 		var run models.RunResult
-		for attempt := 1; attempt <= maxAttempts; attempt++ {
+		var backoffElapsed time.Duration
+		for attempt := 1; ; attempt++ {
 			run = r.executeRun(ctx, tc, runNum)
 			run.Attempts = attempt
 
@@ -934,16 +1373,29 @@ func (r *TestRunner) runTestUncached(ctx context.Context, tc *models.TestCase, t
 				break
 			}
 
-			// If more attempts remain, log the retry
-			if attempt < maxAttempts && r.verbose {
-				fmt.Printf("[RETRY] %s run %d: attempt %d/%d failed, retrying\n",
-					tc.DisplayName, runNum, attempt, maxAttempts)
+			delay, ok := policy.nextDelay(attempt, backoffElapsed)
+			if !ok {
+				if attempt < policy.maxAttempts && r.verbose {
+					fmt.Fprintf(r.out, "[RETRY] %s run %d: retry time budget of %v exhausted after %d attempt(s)\n",
+						tc.DisplayName, runNum, policy.maxElapsed, attempt)
+				}
+				break
+			}
+
+			if r.verbose {
+				fmt.Fprintf(r.out, "[RETRY] %s run %d: attempt %d/%d failed, retrying in %v\n",
+					tc.DisplayName, runNum, attempt, policy.maxAttempts, delay)
 			}
+
+			if err := sleepContext(ctx, delay); err != nil {
+				break
+			}
+			backoffElapsed += delay
 		}
 
 		// Surface errors even in non-verbose mode because they're critical for understanding test failures
 		if run.ErrorMsg != "" && !r.verbose {
-			fmt.Printf("[ERROR] %s\n\n", run.ErrorMsg)
+			fmt.Fprintf(r.out, "[ERROR] %s\n\n", run.ErrorMsg)
 		}
 
 		runs = append(runs, run)
@@ -961,19 +1413,40 @@ func (r *TestRunner) runTestUncached(ctx context.Context, tc *models.TestCase, t
 	}
 
 	// Compute test statistics
-	stats := ComputeTestStats(runs)
+	stats := ComputeTestStats(runs, spec.Config.WeightMode, spec.Config.TrimOutliers, spec.Config.MinRunsForCI)
 
 	// Determine overall status
 	status := overallStatus(runs)
+	if threshold := tc.EffectivePassThreshold(spec.Config); threshold > 0 {
+		status = thresholdStatus(status, stats, threshold)
+	}
 
-	return models.TestOutcome{
+	outcome := models.TestOutcome{
 		TestID:      tc.TestID,
 		DisplayName: tc.DisplayName,
 		Group:       r.resolveGroup(),
+		Tags:        tc.Tags,
 		Status:      status,
 		Runs:        runs,
 		Stats:       stats,
+		XFail:       tc.XFail,
+		XFailReason: tc.XFailReason,
+	}
+	outcome.StartedAt, outcome.FinishedAt = runSpan(runs)
+	return outcome
+}
+
+// runSpan returns the earliest run start and latest run finish.
+func runSpan(runs []models.RunResult) (started, finished time.Time) {
+	for _, run := range runs {
+		if !run.StartedAt.IsZero() && (started.IsZero() || run.StartedAt.Before(started)) {
+			started = run.StartedAt
+		}
+		if run.FinishedAt.After(finished) {
+			finished = run.FinishedAt
+		}
 	}
+	return started, finished
 }
 
 func overallStatus(runs []models.RunResult) models.Status {
@@ -999,11 +1472,35 @@ func overallStatus(runs []models.RunResult) models.Status {
 	return status
 }
 
-func (r *TestRunner) executeRun(ctx context.Context, tc *models.TestCase, runNum int) models.RunResult {
+// thresholdStatus decides a task's status from its average weighted score
+// rather than from whether every run passed. Skipped tasks stay skipped.
+func thresholdStatus(status models.Status, stats *models.TestStats, threshold float64) models.Status {
+	if status == models.StatusSkipped || stats == nil {
+		return status
+	}
+	if stats.AvgWeightedScore >= threshold {
+		return models.StatusPassed
+	}
+	return models.StatusFailed
+}
+
+func (r *TestRunner) executeRun(ctx context.Context, tc *models.TestCase, runNum int) (run models.RunResult) {
 	startTime := time.Now()
+	defer func() {
+		run.StartedAt = startTime
+		run.FinishedAt = time.Now()
+	}()
 
 	// Prepare execution request
-	req := r.buildExecutionRequest(tc)
+	req, err := r.buildExecutionRequest(tc)
+	if err != nil {
+		return models.RunResult{
+			RunNumber:  runNum,
+			Status:     models.StatusError,
+			DurationMs: time.Since(startTime).Milliseconds(),
+			ErrorMsg:   err.Error(),
+		}
+	}
 
 	// Emit agent prompt event before execution
 	if r.verbose {
@@ -1014,8 +1511,17 @@ func (r *TestRunner) executeRun(ctx context.Context, tc *models.TestCase, runNum
 		})
 	}
 
-	// Execute
-	resp, err := r.engine.Execute(ctx, req)
+	// Execute (reusing a cached response when possible), or replay a captured transcript
+	var resp *execution.ExecutionResponse
+	var respKey string
+	engineStart := time.Now()
+	if r.replay != nil {
+		resp, err = r.replayResponse(tc)
+	} else {
+		respKey = r.responseCacheKey(ctx, tc, runNum)
+		resp, err = r.executeCached(ctx, tc, req, respKey)
+	}
+	timing := &models.RunTiming{EngineMs: time.Since(engineStart).Milliseconds()}
 	if err != nil {
 		return models.RunResult{
 			RunNumber:  runNum,
@@ -1025,6 +1531,11 @@ func (r *TestRunner) executeRun(ctx context.Context, tc *models.TestCase, runNum
 		}
 	}
 
+	// Cap runaway output before it's graded or stored
+	originalOutputBytes := len(resp.FinalOutput)
+	var outputTruncated bool
+	resp.FinalOutput, outputTruncated = truncateOutput(resp.FinalOutput, r.cfg.Spec().Config.MaxOutputBytes)
+
 	// Emit agent response event after execution
 	if r.verbose {
 		r.notifyProgress(ProgressEvent{
@@ -1041,13 +1552,23 @@ func (r *TestRunner) executeRun(ctx context.Context, tc *models.TestCase, runNum
 
 	// Build validation context
 	vCtx := r.buildGraderContext(tc, resp)
+	if outputTruncated {
+		vCtx.Outcome["output_truncated"] = true
+		vCtx.Outcome["original_output_bytes"] = originalOutputBytes
+	}
 
 	var gradersResults map[string]models.GraderResults
 	if r.skipGraders {
 		gradersResults = make(map[string]models.GraderResults)
 	} else {
 		var err error
-		gradersResults, err = r.runGraders(ctx, tc, vCtx)
+		gradingStart := time.Now()
+		var rc graders.ResultCache
+		if respKey != "" {
+			rc = graderResultCache{cache: r.cache, resp: resp, task: tc, warnf: r.warnf}
+		}
+		gradersResults, err = r.runGraders(ctx, tc, vCtx, rc)
+		timing.GradingMs = time.Since(gradingStart).Milliseconds()
 
 		if err != nil {
 			return models.RunResult{
@@ -1076,26 +1597,36 @@ func (r *TestRunner) executeRun(ctx context.Context, tc *models.TestCase, runNum
 				"grader_type": gr.Type,
 				"passed":      gr.Passed,
 				"score":       gr.Score,
-				"feedback":    gr.Feedback,
+				"feedback":    r.redactor.String(gr.Feedback),
 			},
 		})
 	}
 
 	// Determine status
+	session := r.buildSessionDigest(resp)
+	errMsg := resp.ErrorMsg
 	status := models.StatusPassed
 	if resp.ErrorMsg != "" {
 		status = models.StatusError
 	} else if r.skipGraders {
 		status = models.StatusSkipped
 	} else {
-		for _, v := range gradersResults {
-			if !v.Passed {
-				status = models.StatusFailed
-				break
-			}
+		passed, err := tc.RunPassed(gradersResults, session, resp.DurationMs)
+		switch {
+		case err != nil:
+			status = models.StatusError
+			errMsg = err.Error()
+		case !passed:
+			status = models.StatusFailed
 		}
 	}
 
+	// Check tool calls against the task's allowed/denied tools
+	violations := toolViolations(resp.ToolCalls, req.AllowedTools, req.DeniedTools)
+	if len(violations) > 0 && status == models.StatusPassed && r.cfg.Spec().Config.FailOnToolViolation {
+		status = models.StatusFailed
+	}
+
 	// Build transcript
 	transcript := r.buildTranscript(resp)
 
@@ -1104,20 +1635,77 @@ func (r *TestRunner) executeRun(ctx context.Context, tc *models.TestCase, runNum
 		skillInvocations[i] = models.SkillInvocation{Name: si.Name, Path: si.Path}
 	}
 
-	return models.RunResult{
+	run = models.RunResult{
 		RunNumber:        runNum,
 		Status:           status,
 		DurationMs:       resp.DurationMs,
 		Validations:      gradersResults,
-		SessionDigest:    r.buildSessionDigest(resp),
+		SessionDigest:    session,
 		Transcript:       transcript,
 		FinalOutput:      resp.FinalOutput,
-		ErrorMsg:         resp.ErrorMsg,
+		ErrorMsg:         errMsg,
 		SkillInvocations: skillInvocations,
+		ToolViolations:   violations,
+	}
+	if outputTruncated {
+		run.OutputTruncated = true
+		run.OriginalOutputBytes = originalOutputBytes
 	}
+	if artifactsDir, err := r.captureArtifacts(tc, runNum, resp.WorkspaceDir); err != nil {
+		r.warnf("Failed to capture artifacts for %q: %v", tc.DisplayName, err)
+	} else {
+		run.ArtifactsDir = artifactsDir
+	}
+	timing.Graders = r.graderTimings(tc, gradersResults)
+	timing.TotalMs = time.Since(startTime).Milliseconds()
+	run.Timing = timing
+	return run
+}
+
+// graderTimings lists grader durations in the order graders.RunAll executes
+// them: spec-level graders first, then the task's own validators.
+func (r *TestRunner) graderTimings(tc *models.TestCase, results map[string]models.GraderResults) []models.GraderTiming {
+	order := make([]string, 0, len(r.cfg.Spec().Graders)+len(tc.Validators))
+	for _, g := range r.cfg.Spec().Graders {
+		order = append(order, g.Identifier)
+	}
+	for _, v := range tc.Validators {
+		order = append(order, v.Identifier)
+	}
+
+	var timings []models.GraderTiming
+	for _, name := range order {
+		if res, ok := results[name]; ok {
+			timings = append(timings, models.GraderTiming{Name: res.Name, Type: res.Type, DurationMs: res.DurationMs})
+		}
+	}
+	return timings
+}
+
+// truncateOutput keeps the first maxBytes bytes of output (backing off to a
+// UTF-8 boundary) and appends an elision marker. maxBytes <= 0 means no limit.
+func truncateOutput(output string, maxBytes int) (string, bool) {
+	return truncateText(output, maxBytes, "output")
 }
 
-func (r *TestRunner) buildExecutionRequest(tc *models.TestCase) *execution.ExecutionRequest {
+// truncateText is truncateOutput with the marker naming what was cut.
+func truncateText(s string, maxBytes int, what string) (string, bool) {
+	if maxBytes <= 0 || len(s) <= maxBytes {
+		return s, false
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + fmt.Sprintf("\n\n[... %s truncated: %d of %d bytes omitted ...]", what, len(s)-cut, len(s)), true
+}
+
+func (r *TestRunner) buildExecutionRequest(tc *models.TestCase) (*execution.ExecutionRequest, error) {
+	systemPrompt, err := r.systemPromptFor(tc)
+	if err != nil {
+		return nil, err
+	}
+
 	// Load resource files
 	resources := r.loadResources(tc)
 
@@ -1128,25 +1716,54 @@ func (r *TestRunner) buildExecutionRequest(tc *models.TestCase) *execution.Execu
 	}
 
 	// Resolve skill paths relative to spec directory
-	resolvedSkillPaths := utils.ResolvePaths(spec.Config.SkillPaths, r.cfg.SpecDir())
+	resolvedSkillPaths := spec.ResolveSkillPaths(r.cfg.SpecDir())
+
+	allowedTools, deniedTools := r.toolPolicy(tc)
 
 	return &execution.ExecutionRequest{
-		Message:    tc.Stimulus.Message,
-		Context:    tc.Stimulus.Metadata,
-		Resources:  resources,
-		SkillName:  spec.SkillName,
-		SkillPaths: resolvedSkillPaths,
-		Timeout:    time.Duration(timeout) * time.Second,
+		Message:      tc.Stimulus.Message,
+		Context:      tc.Stimulus.Metadata,
+		Resources:    resources,
+		SkillName:    spec.SkillName,
+		SkillPaths:   resolvedSkillPaths,
+		Timeout:      time.Duration(timeout) * time.Second,
+		SystemPrompt: systemPrompt,
+		AllowedTools: allowedTools,
+		DeniedTools:  deniedTools,
+	}, nil
+}
+
+// systemPromptFor returns the task's system_prompt, or the spec's when the
+// task has none, rendered like context_dir.
+func (r *TestRunner) systemPromptFor(tc *models.TestCase) (string, error) {
+	prompt := r.cfg.Spec().Config.SystemPrompt
+	if tc.SystemPrompt != "" {
+		prompt = tc.SystemPrompt
+	}
+	rendered, err := template.Render(prompt, r.taskTemplateContext(tc))
+	if err != nil {
+		return "", fmt.Errorf("system_prompt: %w", err)
+	}
+	return rendered, nil
+}
+
+// taskTemplateContext is the template context for a task's own fields: the
+// spec's inputs and the task's inputs.context as .Vars.
+func (r *TestRunner) taskTemplateContext(tc *models.TestCase) *template.Context {
+	ctx := &template.Context{TaskName: tc.DisplayName, Vars: map[string]string{}}
+	maps.Copy(ctx.Vars, r.cfg.Spec().Inputs)
+	for k, v := range tc.Stimulus.Metadata {
+		ctx.Vars[k] = fmt.Sprint(v)
 	}
+	return ctx
 }
 
 func (r *TestRunner) loadResources(tc *models.TestCase) []execution.ResourceFile {
 	var resources []execution.ResourceFile
 
-	// Determine fixture directory (for loading resource files)
-	fixtureDir := r.cfg.FixtureDir()
-	if tc.ContextRoot != "" {
-		fixtureDir = tc.ContextRoot
+	fixtureDir, err := r.fixtureDirFor(tc)
+	if err != nil {
+		r.warnf("%v", err)
 	}
 
 	for _, ref := range tc.Stimulus.Resources {
@@ -1158,41 +1775,16 @@ func (r *TestRunner) loadResources(tc *models.TestCase) []execution.ResourceFile
 			})
 		} else if ref.Location != "" && fixtureDir != "" {
 			// Load from file - validate path to prevent directory traversal
-			if filepath.IsAbs(ref.Location) {
-				fmt.Fprintf(os.Stderr, "Warning: absolute resource path %q rejected\n", ref.Location)
-				continue
-			}
-
-			cleanPath := filepath.Clean(ref.Location)
-			if strings.Contains(cleanPath, "..") {
-				fmt.Fprintf(os.Stderr, "Warning: resource path %q contains '..' and is rejected\n", ref.Location)
-				continue
-			}
-
-			fullPath := filepath.Join(fixtureDir, cleanPath)
-
-			// Ensure the resolved path is still within fixtureDir
-			absFixtureDir, err := filepath.Abs(fixtureDir)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to get absolute path for fixture dir: %v\n", err)
-				continue
-			}
-
-			absFullPath, err := filepath.Abs(fullPath)
+			fullPath, err := resolveFixturePath(fixtureDir, ref.Location)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to get absolute path for resource: %v\n", err)
-				continue
-			}
-
-			if !strings.HasPrefix(absFullPath, absFixtureDir+string(filepath.Separator)) {
-				fmt.Fprintf(os.Stderr, "Warning: resource path %q escapes fixture directory\n", ref.Location)
+				r.warnf("%v", err)
 				continue
 			}
 
 			content, err := os.ReadFile(fullPath)
 			if err != nil {
 				// Log error but continue - let the test fail if resource is critical
-				fmt.Fprintf(os.Stderr, "Warning: failed to load resource file %s: %v\n", fullPath, err)
+				r.warnf("failed to load resource file %s: %v", fullPath, err)
 				continue
 			}
 			resources = append(resources, execution.ResourceFile{
@@ -1205,6 +1797,68 @@ func (r *TestRunner) loadResources(tc *models.TestCase) []execution.ResourceFile
 	return resources
 }
 
+// fixtureDirFor returns the directory resource files are loaded from for tc.
+// A context_dir containing {{...}} is rendered with the spec's inputs and the
+// task's inputs.context as .Vars, and must stay under its literal prefix.
+func (r *TestRunner) fixtureDirFor(tc *models.TestCase) (string, error) {
+	if tc.ContextRoot == "" {
+		return r.cfg.FixtureDir(), nil
+	}
+	idx := strings.Index(tc.ContextRoot, "{{")
+	if idx < 0 {
+		return tc.ContextRoot, nil
+	}
+
+	rendered, err := template.Render(tc.ContextRoot, r.taskTemplateContext(tc))
+	if err != nil {
+		return "", fmt.Errorf("context_dir %q: %w", tc.ContextRoot, err)
+	}
+
+	// Variables can't climb out of the directory the template names literally
+	base := filepath.Dir(tc.ContextRoot[:idx] + "x")
+	rel, err := filepath.Rel(base, rendered)
+	if err != nil {
+		return "", fmt.Errorf("context_dir %q rendered to %q: %w", tc.ContextRoot, rendered, err)
+	}
+	dir, err := resolveFixturePath(base, rel)
+	if err != nil {
+		return "", fmt.Errorf("context_dir %q rendered to %q: %w", tc.ContextRoot, rendered, err)
+	}
+	return dir, nil
+}
+
+// resolveFixturePath joins a resource location onto fixtureDir, rejecting
+// absolute paths and anything that would escape the fixture directory.
+func resolveFixturePath(fixtureDir, location string) (string, error) {
+	if filepath.IsAbs(location) {
+		return "", fmt.Errorf("absolute resource path %q rejected", location)
+	}
+
+	cleanPath := filepath.Clean(location)
+	if strings.Contains(cleanPath, "..") {
+		return "", fmt.Errorf("resource path %q contains '..' and is rejected", location)
+	}
+
+	fullPath := filepath.Join(fixtureDir, cleanPath)
+
+	// Ensure the resolved path is still within fixtureDir
+	absFixtureDir, err := filepath.Abs(fixtureDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path for fixture dir: %w", err)
+	}
+
+	absFullPath, err := filepath.Abs(fullPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path for resource: %w", err)
+	}
+
+	if !strings.HasPrefix(absFullPath, absFixtureDir+string(filepath.Separator)) {
+		return "", fmt.Errorf("resource path %q escapes fixture directory", location)
+	}
+
+	return fullPath, nil
+}
+
 func (r *TestRunner) buildGraderContext(tc *models.TestCase, resp *execution.ExecutionResponse) *graders.Context {
 	// Convert events to transcript entries
 	var transcript []models.TranscriptEvent
@@ -1215,13 +1869,16 @@ func (r *TestRunner) buildGraderContext(tc *models.TestCase, resp *execution.Exe
 
 	sessionDigest := r.buildSessionDigest(resp)
 
+	metadata := make(map[string]any, len(tc.Metadata))
+	maps.Copy(metadata, tc.Metadata)
+
 	return &graders.Context{
 		TestCase:         tc,
 		Transcript:       transcript,
 		Output:           resp.FinalOutput,
 		Outcome:          make(map[string]any),
 		DurationMS:       resp.DurationMs,
-		Metadata:         make(map[string]any),
+		Metadata:         metadata,
 		WorkspaceDir:     resp.WorkspaceDir,
 		SkillInvocations: resp.SkillInvocations,
 		SessionID:        resp.SessionID,
@@ -1229,9 +1886,36 @@ func (r *TestRunner) buildGraderContext(tc *models.TestCase, resp *execution.Exe
 	}
 }
 
-func (r *TestRunner) runGraders(ctx context.Context, tc *models.TestCase, gradersContext *graders.Context) (map[string]models.GraderResults, error) {
+// runGraders grades a run; rc, when non-nil, serves and stores per-grader results.
+// Only the graders selected by WithGraderTags run.
+func (r *TestRunner) runGraders(ctx context.Context, tc *models.TestCase, gradersContext *graders.Context, rc graders.ResultCache) (map[string]models.GraderResults, error) {
+	spec, tc, err := r.selectGraders(r.cfg.Spec(), tc)
+	if err != nil {
+		return nil, err
+	}
+	if gradersContext.ContextDir, err = r.fixtureDirFor(tc); err != nil {
+		return nil, err
+	}
+	gradersContext.Limiter = r.graderLimiter
+	// A task's runs are graded one after another, so a limiter per call is per task
+	if r.graderTaskLimit > 1 {
+		gradersContext.TaskLimiter = graders.NewLimiter(r.graderTaskLimit)
+	}
+	results, err := graders.RunAllCached(ctx, spec.Graders, tc, gradersContext, r.judgeModel(), r.updateSnapshots, rc)
+	if err != nil {
+		return nil, err
+	}
+	spec.ApplyGraderThresholds(results)
+	applyGraderReliability(results, r.graderReliability)
+	truncateGraderFeedback(results, spec.Config.MaxFeedbackBytes)
+	return results, nil
+}
+
+// judgeModel returns the judge model injected into prompt and rubric graders
+// for the model under test.
+func (r *TestRunner) judgeModel() string {
 	spec := r.cfg.Spec()
-	return graders.RunAll(ctx, spec.Graders, tc, gradersContext, spec.Config.JudgeModel, r.updateSnapshots)
+	return spec.Config.JudgeModelFor(spec.Config.ModelID)
 }
 
 func (r *TestRunner) buildSessionDigest(resp *execution.ExecutionResponse) models.SessionDigest {
@@ -1266,7 +1950,7 @@ func (r *TestRunner) resolveGroup() string {
 	case "":
 		return ""
 	default:
-		fmt.Printf("[WARN] unknown group_by value %q, grouping disabled\n", spec.Config.GroupBy)
+		fmt.Fprintf(r.out, "[WARN] unknown group_by value %q, grouping disabled\n", spec.Config.GroupBy)
 		return ""
 	}
 }
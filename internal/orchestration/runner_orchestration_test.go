@@ -2,8 +2,14 @@ package orchestration
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -12,7 +18,9 @@ import (
 	"github.com/microsoft/waza/internal/config"
 	"github.com/microsoft/waza/internal/execution"
 	"github.com/microsoft/waza/internal/graders"
+	"github.com/microsoft/waza/internal/hooks"
 	"github.com/microsoft/waza/internal/models"
+	"github.com/microsoft/waza/internal/transcript"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -70,6 +78,7 @@ graders:
 			EngineType:    "mock",
 			ModelID:       "mock-model",
 			GroupBy:       "model",
+			MinRunsForCI:  2,
 		},
 		Graders: []models.GraderConfig{
 			{
@@ -188,6 +197,127 @@ inputs:
 	assert.Equal(t, "b-second", outcome.TestOutcomes[1].TestID)
 }
 
+func TestRunBenchmark_MaxConcurrentGraders(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("instrumented grader is a shell script")
+	}
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, "tasks")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+	const numTasks = 8
+	for i := range numTasks {
+		writeTaskFile(t, filepath.Join(tasksDir, fmt.Sprintf("task-%d.yaml", i)), fmt.Sprintf("id: task-%d\nname: Task %d\ninputs:\n  prompt: \"hi\"\n", i, i))
+	}
+
+	// The grader marks itself in-flight, records how many graders are in
+	// flight, then lingers so overlapping runs would be seen.
+	inFlight := filepath.Join(tmpDir, "in-flight")
+	require.NoError(t, os.MkdirAll(inFlight, 0o755))
+	counts := filepath.Join(tmpDir, "counts")
+	script := filepath.Join(tmpDir, "grader.sh")
+	require.NoError(t, os.WriteFile(script, []byte(`touch "$1/$$"
+ls "$1" | wc -l >> "$2"
+sleep 0.1
+rm "$1/$$"
+`), 0o644))
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "max-concurrent-graders"},
+		SkillName:    "test-skill",
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+			Concurrent:    true,
+			Workers:       numTasks,
+		},
+		Graders: []models.GraderConfig{{
+			Kind:       models.GraderKindProgram,
+			Identifier: "instrumented",
+			Parameters: models.ProgramGraderParameters{Command: "sh", Args: []string{script, inFlight, counts}},
+		}},
+		Tasks: []string{"tasks/*.yaml"},
+	}
+
+	const limit = 2
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	runner := NewTestRunner(cfg, execution.NewMockEngine("mock-model"), WithMaxConcurrentGraders(limit))
+	outcome, err := runner.RunBenchmark(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, numTasks, outcome.Digest.Succeeded)
+
+	data, err := os.ReadFile(counts)
+	require.NoError(t, err)
+	lines := strings.Fields(string(data))
+	require.Len(t, lines, numTasks, "every grader should have run once")
+	for _, line := range lines {
+		n, err := strconv.Atoi(line)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, n, limit, "graders in flight")
+	}
+}
+
+func TestRunBenchmark_GraderConcurrencyPerTask(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("instrumented grader is a shell script")
+	}
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "task.yaml"), "id: task\nname: Task\ninputs:\n  prompt: \"hi\"\n")
+
+	// Same instrumentation as TestRunBenchmark_MaxConcurrentGraders, with
+	// every grader belonging to one task
+	inFlight := filepath.Join(tmpDir, "in-flight")
+	require.NoError(t, os.MkdirAll(inFlight, 0o755))
+	counts := filepath.Join(tmpDir, "counts")
+	script := filepath.Join(tmpDir, "grader.sh")
+	require.NoError(t, os.WriteFile(script, []byte(`touch "$1/$$"
+ls "$1" | wc -l >> "$2"
+sleep 0.1
+rm "$1/$$"
+`), 0o644))
+
+	const numGraders = 6
+	var graderConfigs []models.GraderConfig
+	for i := range numGraders {
+		graderConfigs = append(graderConfigs, models.GraderConfig{
+			Kind:       models.GraderKindProgram,
+			Identifier: fmt.Sprintf("instrumented-%d", i),
+			Parameters: models.ProgramGraderParameters{Command: "sh", Args: []string{script, inFlight, counts}},
+		})
+	}
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "grader-concurrency-per-task"},
+		SkillName:    "test-skill",
+		Config: models.Config{
+			TrialsPerTask: 2,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Graders: graderConfigs,
+		Tasks:   []string{"task.yaml"},
+	}
+
+	const limit = 2
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	runner := NewTestRunner(cfg, execution.NewMockEngine("mock-model"), WithGraderConcurrencyPerTask(limit))
+	outcome, err := runner.RunBenchmark(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, outcome.Digest.Succeeded)
+	require.Len(t, outcome.TestOutcomes[0].Runs[0].Validations, numGraders)
+
+	data, err := os.ReadFile(counts)
+	require.NoError(t, err)
+	lines := strings.Fields(string(data))
+	require.Len(t, lines, numGraders*2, "every grader should have run once per trial")
+	for _, line := range lines {
+		n, err := strconv.Atoi(line)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, n, limit, "graders in flight")
+	}
+}
+
 func TestRunBenchmark_SkipGradersMarksTasksSkipped(t *testing.T) {
 	tmpDir := t.TempDir()
 	tasksDir := filepath.Join(tmpDir, "tasks")
@@ -233,6 +363,199 @@ inputs:
 	assert.Equal(t, 1, outcome.Digest.Skipped)
 }
 
+// scriptedEngine wraps the mock engine and replies with outputs in turn.
+type scriptedEngine struct {
+	*execution.MockEngine
+	outputs []string
+	calls   int
+}
+
+func (e *scriptedEngine) Execute(ctx context.Context, req *execution.ExecutionRequest) (*execution.ExecutionResponse, error) {
+	resp, err := e.MockEngine.Execute(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.FinalOutput = e.outputs[e.calls%len(e.outputs)]
+	e.calls++
+	return resp, nil
+}
+
+// capturingEngine wraps the mock engine and records every request it gets.
+type capturingEngine struct {
+	*execution.MockEngine
+	mu       sync.Mutex
+	requests []*execution.ExecutionRequest
+}
+
+func (e *capturingEngine) Execute(ctx context.Context, req *execution.ExecutionRequest) (*execution.ExecutionResponse, error) {
+	e.mu.Lock()
+	e.requests = append(e.requests, req)
+	e.mu.Unlock()
+	return e.MockEngine.Execute(ctx, req)
+}
+
+func TestRunBenchmark_SystemPrompt(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, "tasks")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+	writeTaskFile(t, filepath.Join(tasksDir, "a.yaml"), `id: a
+name: Alpha
+inputs:
+  prompt: "explain"
+`)
+	writeTaskFile(t, filepath.Join(tasksDir, "b.yaml"), `id: b
+name: Beta
+system_prompt: "Variant {{.Vars.variant}}, task only."
+inputs:
+  prompt: "explain"
+`)
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "system-prompt"},
+		SkillName:    "test-skill",
+		Inputs:       map[string]string{"variant": "B"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+			SystemPrompt:  "Variant {{.Vars.variant}} for {{.TaskName}}.",
+		},
+		Tasks: []string{"tasks/*.yaml"},
+	}
+
+	engine := &capturingEngine{MockEngine: execution.NewMockEngine("mock-model")}
+	require.NoError(t, engine.Initialize(context.Background()))
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	outcome, err := NewTestRunner(cfg, engine).RunBenchmark(context.Background())
+	require.NoError(t, err)
+
+	var prompts []string
+	for _, req := range engine.requests {
+		prompts = append(prompts, req.SystemPrompt)
+	}
+	assert.ElementsMatch(t, []string{"Variant B for Alpha.", "Variant B, task only."}, prompts)
+
+	for _, to := range outcome.TestOutcomes {
+		assert.Equal(t, models.StatusPassed, to.Status, to.TestID)
+	}
+}
+
+func TestRunBenchmark_JudgeMap(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, "tasks")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+	writeTaskFile(t, filepath.Join(tasksDir, "a.yaml"), `id: a
+name: Alpha
+inputs:
+  prompt: "explain"
+`)
+
+	// Each executed model is graded by its mapped judge; others use judge_model.
+	for model, judge := range map[string]string{
+		"model-a": "judge-for-a",
+		"model-b": "judge-for-b",
+		"model-c": "default-judge",
+	} {
+		spec := &models.BenchmarkSpec{
+			SpecIdentity: models.SpecIdentity{Name: "judge-map"},
+			SkillName:    "test-skill",
+			Config: models.Config{
+				TrialsPerTask: 1,
+				TimeoutSec:    30,
+				EngineType:    "mock",
+				ModelID:       model,
+				JudgeModel:    "default-judge",
+				JudgeMap:      map[string]string{"model-a": "judge-for-a", "model-b": "judge-for-b"},
+			},
+			Tasks: []string{"tasks/*.yaml"},
+		}
+		engine := execution.NewMockEngine(model)
+		require.NoError(t, engine.Initialize(context.Background()))
+		cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+		runner := NewTestRunner(cfg, engine)
+		assert.Equal(t, judge, runner.judgeModel(), model)
+
+		outcome, err := runner.RunBenchmark(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, judge, outcome.Setup.JudgeModel, model)
+	}
+}
+
+func TestRunBenchmark_PassThreshold(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, "tasks")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+	writeTaskFile(t, filepath.Join(tasksDir, "scored.yaml"), `id: scored
+name: Scored
+inputs:
+  prompt: "write a summary"
+`)
+	writeTaskFile(t, filepath.Join(tasksDir, "strict.yaml"), `id: strict
+name: Strict
+pass_threshold: 0.9
+inputs:
+  prompt: "write another summary"
+`)
+
+	run := func(t *testing.T, threshold float64) map[string]models.TestOutcome {
+		t.Helper()
+		spec := &models.BenchmarkSpec{
+			SpecIdentity: models.SpecIdentity{Name: "pass-threshold"},
+			SkillName:    "test-skill",
+			Config: models.Config{
+				TrialsPerTask: 3,
+				TimeoutSec:    30,
+				EngineType:    "mock",
+				ModelID:       "mock-model",
+				PassThreshold: threshold,
+			},
+			Graders: []models.GraderConfig{{
+				Kind:       models.GraderKindText,
+				Identifier: "good",
+				Parameters: models.TextGraderParameters{RegexMatch: []string{"good"}},
+			}},
+			Tasks: []string{"tasks/*.yaml"},
+		}
+		cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+		// Each task gets two good runs and one bad one: an average of 2/3.
+		engine := &scriptedEngine{MockEngine: execution.NewMockEngine("mock-model"), outputs: []string{"good", "bad", "good"}}
+		outcome, err := NewTestRunner(cfg, engine).RunBenchmark(context.Background())
+		require.NoError(t, err)
+
+		byID := map[string]models.TestOutcome{}
+		for _, to := range outcome.TestOutcomes {
+			require.Len(t, to.Runs, 3)
+			assert.InDelta(t, 2.0/3, to.Stats.AvgWeightedScore, 1e-9)
+			byID[to.TestID] = to
+		}
+		return byID
+	}
+
+	t.Run("unset requires every run to pass", func(t *testing.T) {
+		assert.Equal(t, models.StatusFailed, run(t, 0)["scored"].Status)
+	})
+
+	t.Run("average above threshold passes despite a failed run", func(t *testing.T) {
+		byID := run(t, 0.6)
+		assert.Equal(t, models.StatusPassed, byID["scored"].Status)
+		// The task's own threshold overrides the spec's.
+		assert.Equal(t, models.StatusFailed, byID["strict"].Status)
+	})
+
+	t.Run("average below threshold fails", func(t *testing.T) {
+		assert.Equal(t, models.StatusFailed, run(t, 0.7)["scored"].Status)
+	})
+}
+
+func TestThresholdStatus(t *testing.T) {
+	stats := &models.TestStats{AvgWeightedScore: 0.5}
+	assert.Equal(t, models.StatusPassed, thresholdStatus(models.StatusFailed, stats, 0.5))
+	assert.Equal(t, models.StatusFailed, thresholdStatus(models.StatusPassed, stats, 0.6))
+	assert.Equal(t, models.StatusSkipped, thresholdStatus(models.StatusSkipped, stats, 0.1))
+	assert.Equal(t, models.StatusFailed, thresholdStatus(models.StatusFailed, nil, 0.1))
+}
+
 func TestOverallStatus(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -291,7 +614,7 @@ func TestRunGraders_WeightsAndErrors(t *testing.T) {
 		},
 	}
 
-	results, err := runner.runGraders(context.Background(), testCase, graderCtx)
+	results, err := runner.runGraders(context.Background(), testCase, graderCtx, nil)
 	require.NoError(t, err)
 	assert.Equal(t, 3.0, results["global"].Weight)
 	assert.Equal(t, 1.0, results["task-default-weight"].Weight)
@@ -299,11 +622,37 @@ func TestRunGraders_WeightsAndErrors(t *testing.T) {
 
 	_, err = runner.runGraders(context.Background(), &models.TestCase{
 		Validators: []models.ValidatorInline{{Identifier: "missing-kind"}},
-	}, graderCtx)
+	}, graderCtx, nil)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "no kind associated with grader missing-kind")
 }
 
+func TestRunGraders_GraderThresholds(t *testing.T) {
+	// 3 of 5 patterns match → score 0.6
+	params := models.TextGraderParameters{RegexMatch: []string{"Mock", "response", "here", "MISSING-1", "MISSING-2"}}
+	graderCtx := &graders.Context{Output: "Mock response here"}
+
+	run := func(threshold float64) models.GraderResults {
+		spec := &models.BenchmarkSpec{
+			Graders: []models.GraderConfig{
+				{Kind: models.GraderKindText, Identifier: "partial", Parameters: params},
+			},
+			GraderThresholds: map[string]float64{"text": threshold},
+		}
+		runner := NewTestRunner(config.NewBenchmarkConfig(spec), nil)
+		results, err := runner.runGraders(context.Background(), &models.TestCase{}, graderCtx, nil)
+		require.NoError(t, err)
+		return results["partial"]
+	}
+
+	lenient := run(0.6)
+	assert.InDelta(t, 0.6, lenient.Score, 0.001)
+	assert.True(t, lenient.Passed, "score 0.6 should pass a 0.6 threshold")
+
+	strict := run(0.8)
+	assert.False(t, strict.Passed, "score 0.6 should fail a 0.8 threshold")
+}
+
 func TestRunGraders_DiffSnapshotUpdateOption(t *testing.T) {
 	workspaceDir := t.TempDir()
 	contextDir := t.TempDir()
@@ -332,7 +681,7 @@ func TestRunGraders_DiffSnapshotUpdateOption(t *testing.T) {
 	runner := NewTestRunner(config.NewBenchmarkConfig(spec), nil, WithUpdateSnapshots(true))
 	graderCtx := &graders.Context{WorkspaceDir: workspaceDir}
 
-	results, err := runner.runGraders(context.Background(), &models.TestCase{}, graderCtx)
+	results, err := runner.runGraders(context.Background(), &models.TestCase{}, graderCtx, nil)
 	require.NoError(t, err)
 	assert.True(t, results["diff"].Passed)
 
@@ -369,6 +718,46 @@ func TestLoadResources_PathValidation(t *testing.T) {
 	assert.Equal(t, []byte("ok"), resources[1].Content)
 }
 
+func TestLoadResources_TemplatedContextRoot(t *testing.T) {
+	root := t.TempDir()
+	for _, c := range []string{"alpha", "beta"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, "fixtures", c), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, "fixtures", c, "input.txt"), []byte(c+" input"), 0o644))
+	}
+
+	spec := &models.BenchmarkSpec{Inputs: map[string]string{"case": "alpha"}}
+	runner := NewTestRunner(config.NewBenchmarkConfig(spec), nil)
+	task := func(contextRoot string, vars map[string]any) *models.TestCase {
+		return &models.TestCase{
+			ContextRoot: contextRoot,
+			Stimulus: models.TestStimulus{
+				Metadata:  vars,
+				Resources: []models.ResourceRef{{Location: "input.txt"}},
+			},
+		}
+	}
+	templated := filepath.Join(root, "fixtures") + "/{{.Vars.case}}"
+
+	// Spec inputs supply the default; the task's inputs.context overrides it
+	resources := runner.loadResources(task(templated, nil))
+	require.Len(t, resources, 1)
+	assert.Equal(t, "alpha input", string(resources[0].Content))
+
+	resources = runner.loadResources(task(templated, map[string]any{"case": "beta"}))
+	require.Len(t, resources, 1)
+	assert.Equal(t, "beta input", string(resources[0].Content))
+
+	// Rendered values can't escape the template's literal directory
+	for _, bad := range []string{"../..", "alpha/../../..", ""} {
+		_, err := runner.fixtureDirFor(task(templated, map[string]any{"case": bad}))
+		require.Error(t, err, "case=%q", bad)
+		assert.Empty(t, runner.loadResources(task(templated, map[string]any{"case": bad})))
+	}
+
+	_, err := runner.fixtureDirFor(task(filepath.Join(root, "{{.Vars.missing}}"), nil))
+	require.ErrorContains(t, err, "context_dir")
+}
+
 func TestBuildGraderContextAndScoreHelpers(t *testing.T) {
 	spec := &models.BenchmarkSpec{Config: models.Config{TrialsPerTask: 2}}
 	runner := NewTestRunner(config.NewBenchmarkConfig(spec), nil)
@@ -389,7 +778,12 @@ func TestBuildGraderContextAndScoreHelpers(t *testing.T) {
 		},
 	}
 
-	graderCtx := runner.buildGraderContext(&models.TestCase{TestID: "tc"}, resp)
+	tc := &models.TestCase{TestID: "tc", Metadata: map[string]any{"expected_count": 3}}
+	graderCtx := runner.buildGraderContext(tc, resp)
+	assert.Equal(t, map[string]any{"expected_count": 3}, graderCtx.Metadata)
+	// Graders get a copy, so one can't change what the next one sees
+	graderCtx.Metadata["expected_count"] = 4
+	assert.Equal(t, 3, tc.Metadata["expected_count"])
 	require.Len(t, graderCtx.Transcript, 1)
 	assert.Equal(t, "final output", graderCtx.Output)
 	assert.Equal(t, int64(42), graderCtx.DurationMS)
@@ -440,7 +834,7 @@ func TestComputeTestStats_FlakinessPercent(t *testing.T) {
 		},
 	}
 
-	stats := ComputeTestStats(runs)
+	stats := ComputeTestStats(runs, models.WeightModeNormalized, 0, 0)
 	require.NotNil(t, stats)
 	assert.Equal(t, 4, stats.TotalRuns)
 	assert.Equal(t, 2, stats.PassedRuns)
@@ -501,3 +895,527 @@ func TestRunTest_CacheHitAndTranscriptWrite(t *testing.T) {
 	assert.Equal(t, outcome.TestID, cachedOutcome.TestID)
 	assert.Equal(t, outcome.Status, cachedOutcome.Status)
 }
+
+func TestRunBenchmark_TranscriptsForDuplicateDisplayNames(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "first.yaml"), `id: login-basic
+name: Login flow
+inputs:
+  prompt: "log in"
+`)
+	writeTaskFile(t, filepath.Join(tmpDir, "second.yaml"), `id: login-sso
+name: Login flow
+inputs:
+  prompt: "log in with sso"
+`)
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "dupes"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Tasks: []string{"first.yaml", "second.yaml"},
+	}
+
+	transcriptDir := filepath.Join(tmpDir, "transcripts")
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir), config.WithTranscriptDir(transcriptDir))
+	runner := NewTestRunner(cfg, execution.NewMockEngine("mock-model"))
+
+	_, err := runner.RunBenchmark(context.Background())
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(transcriptDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.True(t, strings.HasPrefix(entries[0].Name(), "login-flow-login-basic-"), entries[0].Name())
+	assert.True(t, strings.HasPrefix(entries[1].Name(), "login-flow-login-sso-"), entries[1].Name())
+
+	byTask, err := transcript.LoadDir(transcriptDir)
+	require.NoError(t, err)
+	assert.Contains(t, byTask, "login-basic")
+	assert.Contains(t, byTask, "login-sso")
+}
+
+func TestRunBenchmark_TranscriptNameTemplate(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "task.yaml"), `id: task-one
+name: Task One
+inputs:
+  prompt: "hello"
+`)
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "named"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Tasks: []string{"task.yaml"},
+	}
+
+	transcriptDir := filepath.Join(tmpDir, "transcripts")
+	cfg := config.NewBenchmarkConfig(spec,
+		config.WithSpecDir(tmpDir),
+		config.WithTranscriptDir(transcriptDir),
+		config.WithTranscriptNameTemplate("{{.TaskID}}_{{.Status}}"),
+	)
+	runner := NewTestRunner(cfg, execution.NewMockEngine("mock-model"))
+
+	_, err := runner.RunBenchmark(context.Background())
+	require.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join(transcriptDir, "task-one_passed.json"))
+	require.NoError(t, err)
+}
+
+func TestRunBenchmark_SuccessWhen(t *testing.T) {
+	tmpDir := t.TempDir()
+	task := func(id, successWhen string) {
+		writeTaskFile(t, filepath.Join(tmpDir, id+".yaml"), `id: `+id+`
+name: `+id+`
+inputs:
+  prompt: "hello"
+success_when: "`+successWhen+`"
+graders:
+  - type: text
+    name: has-mock
+    config:
+      regex_match: ["Mock response"]
+  - type: text
+    name: has-missing
+    config:
+      regex_match: ["not in the output"]
+`)
+	}
+	task("either", "has-mock.passed || has-missing.passed")
+	task("both", "has-mock.passed && has-missing.passed")
+	task("negated", "!has-missing.passed && text.score >= 0.5")
+	task("broken", "nope.passed")
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "success-when"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Tasks: []string{"*.yaml"},
+	}
+
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	runner := NewTestRunner(cfg, execution.NewMockEngine("mock-model"))
+
+	outcome, err := runner.RunBenchmark(context.Background())
+	require.NoError(t, err)
+
+	byID := map[string]models.TestOutcome{}
+	for _, to := range outcome.TestOutcomes {
+		byID[to.TestID] = to
+	}
+	require.Len(t, byID, 4)
+	assert.Equal(t, models.StatusPassed, byID["either"].Status)
+	assert.Equal(t, models.StatusFailed, byID["both"].Status)
+	assert.Equal(t, models.StatusPassed, byID["negated"].Status)
+	assert.Equal(t, models.StatusError, byID["broken"].Runs[0].Status)
+	assert.Contains(t, byID["broken"].Runs[0].ErrorMsg, `unknown name "nope"`)
+}
+
+func TestRunBenchmark_RecordsRunTimestamps(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "timed.yaml"), `id: timed
+name: Timed
+inputs:
+  prompt: "hello"
+`)
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "timestamps"},
+		Config: models.Config{
+			TrialsPerTask: 3,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Tasks: []string{"*.yaml"},
+	}
+
+	before := time.Now()
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	outcome, err := NewTestRunner(cfg, execution.NewMockEngine("mock-model")).RunBenchmark(context.Background())
+	require.NoError(t, err)
+	after := time.Now()
+
+	require.Len(t, outcome.TestOutcomes, 1)
+	to := outcome.TestOutcomes[0]
+	require.Len(t, to.Runs, 3)
+	for i, run := range to.Runs {
+		assert.False(t, run.StartedAt.Before(before), "run %d started before the benchmark", i+1)
+		assert.False(t, run.FinishedAt.After(after), "run %d finished after the benchmark", i+1)
+		require.NotNil(t, run.Timing)
+		elapsed := run.FinishedAt.Sub(run.StartedAt)
+		assert.InDelta(t, run.Timing.TotalMs, elapsed.Milliseconds(), 50, "run %d", i+1)
+		if i > 0 {
+			assert.False(t, run.StartedAt.Before(to.Runs[i-1].FinishedAt), "run %d overlaps run %d", i+1, i)
+		}
+	}
+	assert.Equal(t, to.Runs[0].StartedAt, to.StartedAt)
+	assert.Equal(t, to.Runs[2].FinishedAt, to.FinishedAt)
+
+	data, err := json.Marshal(to)
+	require.NoError(t, err)
+	var decoded models.TestOutcome
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.True(t, decoded.StartedAt.Equal(to.StartedAt))
+	assert.True(t, decoded.Runs[1].FinishedAt.Equal(to.Runs[1].FinishedAt))
+}
+
+func TestRunBenchmark_TagsRoundTripIntoOutcome(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "tagged.yaml"), `id: tagged
+name: Tagged
+tags: [smoke, auth]
+inputs:
+  prompt: "hello"
+`)
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "tags"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Tasks: []string{"tagged.yaml"},
+	}
+
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	runner := NewTestRunner(cfg, execution.NewMockEngine("mock-model"))
+
+	outcome, err := runner.RunBenchmark(context.Background())
+	require.NoError(t, err)
+	require.Len(t, outcome.TestOutcomes, 1)
+	assert.Equal(t, []string{"smoke", "auth"}, outcome.TestOutcomes[0].Tags)
+
+	data, err := json.Marshal(outcome)
+	require.NoError(t, err)
+
+	var decoded models.EvaluationOutcome
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, []string{"smoke", "auth"}, decoded.TestOutcomes[0].Tags)
+	require.Len(t, decoded.Digest.Tags, 2)
+	assert.Equal(t, "smoke", decoded.Digest.Tags[0].Name)
+	assert.Equal(t, 1, decoded.Digest.Tags[0].Total)
+}
+
+func TestRunBenchmark_MaxOutputBytesTruncatesBeforeGrading(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, "tasks")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+	writeTaskFile(t, filepath.Join(tasksDir, "task.yaml"), `id: big-output
+name: Big Output
+inputs:
+  prompt: "`+strings.Repeat("x", 500)+`"
+`)
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "max-output"},
+		SkillName:    "test-skill",
+		Config: models.Config{
+			TrialsPerTask:  1,
+			TimeoutSec:     30,
+			EngineType:     "mock",
+			ModelID:        "mock-model",
+			MaxOutputBytes: 32,
+		},
+		Graders: []models.GraderConfig{
+			{
+				Kind:       models.GraderKindText,
+				Identifier: "sees-truncated-text",
+				Parameters: models.TextGraderParameters{
+					Contains:    []string{"Mock response", "output truncated"},
+					NotContains: []string{strings.Repeat("x", 100)},
+				},
+			},
+		},
+		Tasks: []string{"tasks/*.yaml"},
+	}
+
+	engine := execution.NewMockEngine("mock-model")
+	require.NoError(t, engine.Initialize(context.Background()))
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	outcome, err := NewTestRunner(cfg, engine).RunBenchmark(context.Background())
+	require.NoError(t, err)
+
+	run := outcome.TestOutcomes[0].Runs[0]
+	assert.Equal(t, models.StatusPassed, run.Status, run.Validations["sees-truncated-text"].Feedback)
+	assert.True(t, run.OutputTruncated)
+	assert.Greater(t, run.OriginalOutputBytes, 500)
+	assert.True(t, strings.HasPrefix(run.FinalOutput, "Mock response for: xxxxxxxxxxxxx\n\n[... output truncated: "))
+}
+
+func TestTruncateOutput(t *testing.T) {
+	out, truncated := truncateOutput("hello world", 0)
+	assert.False(t, truncated)
+	assert.Equal(t, "hello world", out)
+
+	out, truncated = truncateOutput("hello world", 11)
+	assert.False(t, truncated)
+	assert.Equal(t, "hello world", out)
+
+	out, truncated = truncateOutput("hello world", 5)
+	assert.True(t, truncated)
+	assert.Equal(t, "hello\n\n[... output truncated: 6 of 11 bytes omitted ...]", out)
+
+	// "é" is two bytes; cutting inside it backs off to the previous rune
+	out, truncated = truncateOutput("caféteria", 4)
+	assert.True(t, truncated)
+	assert.Equal(t, "caf\n\n[... output truncated: 7 of 10 bytes omitted ...]", out)
+}
+
+func TestRunBenchmark_RunTimingBreakdown(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, "tasks")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+	writeTaskFile(t, filepath.Join(tasksDir, "task.yaml"), `id: timed
+name: Timed
+inputs:
+  prompt: "hello"
+graders:
+  - name: task-text
+    type: text
+    config:
+      contains: ["Mock response"]
+`)
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "timing"},
+		SkillName:    "test-skill",
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Graders: []models.GraderConfig{
+			{
+				Kind:       models.GraderKindProgram,
+				Identifier: "slow-program",
+				Parameters: models.ProgramGraderParameters{Command: "sh", Args: []string{"-c", "sleep 0.05"}},
+			},
+		},
+		Tasks: []string{"tasks/*.yaml"},
+	}
+
+	engine := execution.NewMockEngine("mock-model")
+	require.NoError(t, engine.Initialize(context.Background()))
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	outcome, err := NewTestRunner(cfg, engine).RunBenchmark(context.Background())
+	require.NoError(t, err)
+
+	run := outcome.TestOutcomes[0].Runs[0]
+	require.NotNil(t, run.Timing)
+	timing := run.Timing
+
+	// Graders are listed in execution order: spec graders, then task graders
+	require.Len(t, timing.Graders, 2)
+	assert.Equal(t, "slow-program", timing.Graders[0].Name)
+	assert.Equal(t, models.GraderKindProgram, timing.Graders[0].Type)
+	assert.Equal(t, "task-text", timing.Graders[1].Name)
+	assert.GreaterOrEqual(t, timing.Graders[0].DurationMs, int64(40))
+
+	// Per-grader time fits inside grading time, and engine + grading ≈ total
+	var graderSum int64
+	for _, g := range timing.Graders {
+		graderSum += g.DurationMs
+	}
+	assert.LessOrEqual(t, graderSum, timing.GradingMs+1)
+	assert.LessOrEqual(t, timing.EngineMs+timing.GradingMs, timing.TotalMs+1)
+	assert.InDelta(t, timing.TotalMs, timing.EngineMs+timing.GradingMs, 25)
+}
+
+func TestRunBenchmark_HooksReceiveRunEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell script")
+	}
+
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, "tasks")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+	writeTaskFile(t, filepath.Join(tasksDir, "task.yaml"), `id: hooked
+name: Hooked
+inputs:
+  prompt: "hello"
+`)
+	writeTaskFile(t, filepath.Join(tmpDir, "setup.sh"),
+		`printf '%s|%s|%s|%s' "$WAZA_SPEC_DIR" "$WAZA_MODEL" "$WAZA_SKILL" "$WAZA_RUN_ID" > env.txt`)
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "hook-env"},
+		SkillName:    "hook-skill",
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Hooks: hooks.HooksConfig{
+			BeforeRun: []hooks.HookConfig{{Command: "sh setup.sh", WorkingDirectory: tmpDir, ErrorOnFail: true}},
+		},
+		Tasks: []string{"tasks/*.yaml"},
+	}
+
+	engine := execution.NewMockEngine("mock-model")
+	require.NoError(t, engine.Initialize(context.Background()))
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	outcome, err := NewTestRunner(cfg, engine).RunBenchmark(context.Background())
+	require.NoError(t, err)
+
+	got, err := os.ReadFile(filepath.Join(tmpDir, "env.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, tmpDir+"|mock-model|hook-skill|"+outcome.RunID, string(got))
+}
+
+func TestRunBenchmark_FlagsNonDeterministicGraders(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "task.yaml"), `id: nondet
+name: NonDet
+inputs:
+  prompt: "hello"
+graders:
+  - name: task-limits
+    type: behavior
+    config:
+      max_tool_calls: 10
+`)
+
+	writeTaskFile(t, filepath.Join(tmpDir, "plain.yaml"), `id: plain
+name: Plain
+inputs:
+  prompt: "hello"
+`)
+
+	newSpec := func(task string, graders ...models.GraderConfig) *models.BenchmarkSpec {
+		return &models.BenchmarkSpec{
+			SpecIdentity: models.SpecIdentity{Name: "nondet"},
+			Config: models.Config{
+				TrialsPerTask: 1,
+				TimeoutSec:    30,
+				EngineType:    "mock",
+				ModelID:       "mock-model",
+			},
+			Graders: graders,
+			Tasks:   []string{task},
+		}
+	}
+	run := func(spec *models.BenchmarkSpec) *models.EvaluationOutcome {
+		cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+		outcome, err := NewTestRunner(cfg, execution.NewMockEngine("mock-model")).RunBenchmark(context.Background())
+		require.NoError(t, err)
+		return outcome
+	}
+
+	outcome := run(newSpec("task.yaml",
+		models.GraderConfig{Kind: models.GraderKindText, Identifier: "contains", Parameters: models.TextGraderParameters{Contains: []string{"Mock"}}},
+		models.GraderConfig{Kind: models.GraderKindBehavior, Identifier: "spec-limits", Parameters: models.BehaviorGraderParameters{MaxToolCalls: 5}},
+	))
+	assert.Equal(t, true, outcome.Metadata[MetadataNonDeterministic])
+	assert.Equal(t, []string{"spec-limits", "task-limits"}, outcome.Metadata[MetadataNonDeterministicGraders])
+
+	// Deterministic graders only: no flag
+	outcome = run(newSpec("plain.yaml",
+		models.GraderConfig{Kind: models.GraderKindText, Identifier: "contains", Parameters: models.TextGraderParameters{Contains: []string{"Mock"}}},
+	))
+	assert.NotContains(t, outcome.Metadata, MetadataNonDeterministic)
+	assert.NotContains(t, outcome.Metadata, MetadataNonDeterministicGraders)
+}
+
+func TestRunBenchmark_TaskMetadataReachesGraders(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("program grader uses sh")
+	}
+
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "task.yaml"), `id: count-files
+name: Count Files
+metadata:
+  expected_count: 3
+inputs:
+  prompt: "How many files are there?"
+graders:
+  - name: metadata-check
+    type: program
+    config:
+      command: sh
+      args: ["-c", "printf %s \"$WAZA_TASK_METADATA\""]
+`)
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "metadata"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Tasks: []string{"task.yaml"},
+	}
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	outcome, err := NewTestRunner(cfg, execution.NewMockEngine("mock-model")).RunBenchmark(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, outcome.TestOutcomes, 1)
+	require.Len(t, outcome.TestOutcomes[0].Runs, 1)
+	result := outcome.TestOutcomes[0].Runs[0].Validations["metadata-check"]
+	assert.Equal(t, `{"expected_count":3}`, result.Feedback)
+}
+
+func TestRunBenchmark_SkipInvalidTasks(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "broken.yaml"), "id: broken\nname: [unterminated\n")
+	writeTaskFile(t, filepath.Join(tmpDir, "valid.yaml"), "id: valid\nname: Valid\ninputs:\n  prompt: hello\n")
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "skip-invalid"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Tasks: []string{"*.yaml"},
+	}
+	newRunner := func(opts ...RunnerOption) *TestRunner {
+		cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+		return NewTestRunner(cfg, execution.NewMockEngine("mock-model"), opts...)
+	}
+
+	t.Run("fails fast by default", func(t *testing.T) {
+		_, err := newRunner().RunBenchmark(context.Background())
+		require.ErrorContains(t, err, "broken.yaml")
+	})
+
+	t.Run("skips with the option", func(t *testing.T) {
+		outcome, err := newRunner(WithSkipInvalidTasks()).RunBenchmark(context.Background())
+		require.NoError(t, err)
+		require.Len(t, outcome.TestOutcomes, 1)
+		assert.Equal(t, "valid", outcome.TestOutcomes[0].TestID)
+		require.Len(t, outcome.Warnings, 1)
+		assert.Contains(t, outcome.Warnings[0], "skipping invalid task file")
+		assert.Contains(t, outcome.Warnings[0], "broken.yaml")
+	})
+
+	t.Run("fails when nothing loads", func(t *testing.T) {
+		only := *spec
+		only.Tasks = []string{"broken.yaml"}
+		cfg := config.NewBenchmarkConfig(&only, config.WithSpecDir(tmpDir))
+		_, err := NewTestRunner(cfg, execution.NewMockEngine("mock-model"), WithSkipInvalidTasks()).RunBenchmark(context.Background())
+		require.ErrorContains(t, err, "all 1 task files failed to load")
+	})
+}
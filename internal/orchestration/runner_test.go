@@ -1,11 +1,13 @@
 package orchestration
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
 	"github.com/microsoft/waza/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -90,7 +92,8 @@ func TestBuildExecutionRequest_SkillPaths(t *testing.T) {
 			runner := NewTestRunner(cfg, nil)
 
 			// Build execution request
-			req := runner.buildExecutionRequest(tc)
+			req, err := runner.buildExecutionRequest(tc)
+			require.NoError(t, err)
 
 			// Verify skill paths
 			require.NotNil(t, req, "execution request should not be nil")
@@ -137,7 +140,8 @@ func TestBuildExecutionRequest_BasicFields(t *testing.T) {
 	}
 
 	runner := NewTestRunner(cfg, nil)
-	req := runner.buildExecutionRequest(tc)
+	req, err := runner.buildExecutionRequest(tc)
+	require.NoError(t, err)
 
 	// Verify basic fields
 	assert.Equal(t, "Hello world", req.Message)
@@ -174,12 +178,52 @@ func TestBuildExecutionRequest_TimeoutOverride(t *testing.T) {
 	}
 
 	runner := NewTestRunner(cfg, nil)
-	req := runner.buildExecutionRequest(tc)
+	req, err := runner.buildExecutionRequest(tc)
+	require.NoError(t, err)
 
 	// Verify timeout is overridden
 	assert.Equal(t, float64(300), req.Timeout.Seconds(), "test case timeout should override spec timeout")
 }
 
+func TestBuildExecutionRequest_SystemPrompt(t *testing.T) {
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "test-benchmark"},
+		Config: models.Config{
+			EngineType:   "mock",
+			ModelID:      "gpt-4",
+			SystemPrompt: "You are reviewing {{.TaskName}} in {{.Vars.language}}.",
+		},
+		Inputs: map[string]string{"language": "Go"},
+	}
+	runner := NewTestRunner(config.NewBenchmarkConfig(spec), nil)
+
+	// The spec's prompt is rendered with the task's name and variables
+	req, err := runner.buildExecutionRequest(&models.TestCase{
+		TestID:      "test-001",
+		DisplayName: "Parser",
+		Stimulus:    models.TestStimulus{Message: "Hello", Metadata: map[string]any{"language": "Rust"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "You are reviewing Parser in Rust.", req.SystemPrompt)
+	assert.Equal(t, "Hello", req.Message)
+
+	// A task's own prompt wins
+	req, err = runner.buildExecutionRequest(&models.TestCase{
+		TestID:       "test-002",
+		DisplayName:  "Lexer",
+		SystemPrompt: "Be terse about {{.TaskName}}.",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Be terse about Lexer.", req.SystemPrompt)
+
+	// Unknown variables are an error, not an empty string
+	_, err = runner.buildExecutionRequest(&models.TestCase{
+		TestID:       "test-003",
+		SystemPrompt: "{{.Vars.missing}}",
+	})
+	assert.ErrorContains(t, err, "system_prompt")
+}
+
 func TestComputeTestStats_ErrorRunsAreSeparateFromFailed(t *testing.T) {
 	runs := []models.RunResult{
 		{
@@ -194,7 +238,7 @@ func TestComputeTestStats_ErrorRunsAreSeparateFromFailed(t *testing.T) {
 		},
 	}
 
-	stats := ComputeTestStats(runs)
+	stats := ComputeTestStats(runs, models.WeightModeNormalized, 0, 0)
 	require.NotNil(t, stats)
 	assert.Equal(t, 1, stats.PassedRuns)
 	assert.Equal(t, 0, stats.FailedRuns, "Error runs should not count as FailedRuns")
@@ -376,6 +420,47 @@ description: Validate Azure config
 		err := runner.validateRequiredSkills()
 		assert.NoError(t, err)
 	})
+
+	t.Run("skills resolve by name from skill_registry", func(t *testing.T) {
+		registry := filepath.Join(tmpDir, "registry")
+		require.NoError(t, os.MkdirAll(filepath.Join(registry, "azure-deploy"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(registry, "azure-deploy", "SKILL.md"), []byte(skill1Content), 0644))
+		require.NoError(t, os.MkdirAll(filepath.Join(registry, "skill2"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(registry, "skill2", "SKILL.md"), []byte(skill2Content), 0644))
+
+		spec := &models.BenchmarkSpec{
+			SpecIdentity: models.SpecIdentity{
+				Name: "test-benchmark",
+			},
+			SkillName: "azure-deploy",
+			Config: models.Config{
+				EngineType:    "mock",
+				ModelID:       "gpt-4",
+				TimeoutSec:    60,
+				TrialsPerTask: 1,
+				SkillRegistry: "registry",
+				// skill2 is in the registry; skill3 isn't, so it falls back to the spec dir
+				SkillPaths:     []string{"skill2", "skill3"},
+				RequiredSkills: []string{"azure-deploy", "azure-prepare", "azure-validate"},
+			},
+		}
+
+		cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+		runner := NewTestRunner(cfg, nil)
+
+		require.NoError(t, runner.validateRequiredSkills())
+		assert.Equal(t, []string{
+			filepath.Join(registry, "skill2"),
+			skill3Dir,
+			filepath.Join(registry, "azure-deploy"),
+			filepath.Join(registry, "azure-prepare"),
+			filepath.Join(registry, "azure-validate"),
+		}, spec.ResolveSkillPaths(tmpDir))
+
+		req, err := runner.buildExecutionRequest(&models.TestCase{TestID: "t"})
+		require.NoError(t, err)
+		assert.Contains(t, req.SkillPaths, filepath.Join(registry, "azure-deploy"), "registry skills are loaded by the engine")
+	})
 }
 
 func TestComputeGroupStats_MixedGroups(t *testing.T) {
@@ -429,6 +514,36 @@ func TestComputeGroupStats_NoGroupSet(t *testing.T) {
 	assert.Nil(t, stats)
 }
 
+func TestComputeTagStats_MultipleTagsPerTask(t *testing.T) {
+	outcomes := []models.TestOutcome{
+		{TestID: "t1", Tags: []string{"smoke", "auth"}, Status: models.StatusPassed, Stats: &models.TestStats{AvgScore: 1.0}},
+		{TestID: "t2", Tags: []string{"auth"}, Status: models.StatusFailed, Stats: &models.TestStats{AvgScore: 0.2}},
+		{TestID: "t3", Status: models.StatusPassed, Stats: &models.TestStats{AvgScore: 0.8}},
+	}
+
+	stats := computeTagStats(outcomes)
+	require.Len(t, stats, 2)
+
+	assert.Equal(t, models.GroupStats{Name: "smoke", Passed: 1, Total: 1, AvgScore: 1.0}, stats[0])
+
+	assert.Equal(t, "auth", stats[1].Name)
+	assert.Equal(t, 1, stats[1].Passed)
+	assert.Equal(t, 2, stats[1].Total)
+	assert.InDelta(t, 0.6, stats[1].AvgScore, 0.001)
+
+	assert.Nil(t, computeTagStats([]models.TestOutcome{{TestID: "untagged"}}))
+}
+
+func TestBuildDigest_IncludesTagStats(t *testing.T) {
+	outcomes := []models.TestOutcome{
+		{TestID: "t1", Tags: []string{"smoke"}, Status: models.StatusPassed, Stats: &models.TestStats{AvgScore: 1.0}},
+	}
+
+	digest := BuildDigest(outcomes, 0, 1)
+	require.Len(t, digest.Tags, 1)
+	assert.Equal(t, "smoke", digest.Tags[0].Name)
+}
+
 func TestResolveGroup_Model(t *testing.T) {
 	spec := &models.BenchmarkSpec{
 		Config: models.Config{
@@ -467,3 +582,49 @@ func TestResolveGroup_Unknown(t *testing.T) {
 
 	assert.Equal(t, "", runner.resolveGroup())
 }
+
+func TestRunBenchmark_AggregateMethod(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"hit", "miss", "hit-again"} {
+		writeTaskFile(t, filepath.Join(tmpDir, name+".yaml"), "id: "+name+"\nname: "+name+"\ninputs:\n  prompt: \""+name+"\"\n")
+	}
+	run := func(method models.AggregateMethod, weightByTrials bool) *models.EvaluationOutcome {
+		spec := &models.BenchmarkSpec{
+			SpecIdentity: models.SpecIdentity{Name: "aggregate-method"},
+			Config: models.Config{
+				TrialsPerTask:   1,
+				TimeoutSec:      30,
+				EngineType:      "mock",
+				ModelID:         "mock-model",
+				AggregateMethod: method,
+				WeightByTrials:  weightByTrials,
+			},
+			Graders: []models.GraderConfig{{
+				Kind:       models.GraderKindText,
+				Identifier: "hit",
+				Parameters: models.TextGraderParameters{Contains: []string{"hit"}},
+			}},
+			Tasks: []string{"*.yaml"},
+		}
+		cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+		outcome, err := NewTestRunner(cfg, execution.NewMockEngine("mock-model")).RunBenchmark(context.Background())
+		require.NoError(t, err)
+		return outcome
+	}
+
+	mean := run("", false)
+	assert.InDelta(t, 2.0/3, mean.Digest.AggregateScore, 1e-9)
+	assert.Equal(t, models.AggregateMethodMean, mean.Setup.AggregateMethod)
+
+	median := run(models.AggregateMethodMedian, false)
+	assert.InDelta(t, 1.0, median.Digest.AggregateScore, 1e-9)
+	assert.Equal(t, models.AggregateMethodMedian, median.Setup.AggregateMethod)
+
+	worst := run(models.AggregateMethodMin, false)
+	assert.InDelta(t, 0.0, worst.Digest.AggregateScore, 1e-9)
+
+	// weight_by_trials records the method it actually aggregated with
+	weighted := run("", true)
+	assert.InDelta(t, 2.0/3, weighted.Digest.AggregateScore, 1e-9)
+	assert.Equal(t, models.AggregateMethodTrialWeighted, weighted.Setup.AggregateMethod)
+}
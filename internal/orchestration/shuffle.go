@@ -0,0 +1,38 @@
+package orchestration
+
+import (
+	"iter"
+	"math/rand/v2"
+
+	"github.com/microsoft/waza/internal/models"
+)
+
+// MetadataShuffleSeed is the outcome metadata key recording the seed used to
+// shuffle task order, so an order-dependent failure can be reproduced.
+const MetadataShuffleSeed = "shuffle_seed"
+
+// WithShuffle runs tasks in an order randomized by seed instead of spec order,
+// to surface skills that carry state from one task to the next. The same seed
+// always yields the same order. Only sequential runs are shuffled.
+func WithShuffle(seed uint64) RunnerOption {
+	return func(r *TestRunner) {
+		r.shuffleSeed = &seed
+	}
+}
+
+// shuffleTestCases drains testCases and returns them in an order determined by
+// seed. Reading stops at the first error, which is returned.
+func shuffleTestCases(testCases iter.Seq2[*models.TestCase, error], seed uint64) ([]*models.TestCase, error) {
+	var all []*models.TestCase
+	for tc, err := range testCases {
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, tc)
+	}
+	rng := rand.New(rand.NewPCG(seed, seed))
+	rng.Shuffle(len(all), func(i, j int) {
+		all[i], all[j] = all[j], all[i]
+	})
+	return all, nil
+}
@@ -0,0 +1,65 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBenchmark_Shuffle(t *testing.T) {
+	tmpDir := t.TempDir()
+	var specOrder []string
+	for i := range 8 {
+		id := fmt.Sprintf("task-%d", i)
+		specOrder = append(specOrder, id)
+		writeTaskFile(t, filepath.Join(tmpDir, id+".yaml"), fmt.Sprintf("id: %s\nname: %s\ninputs:\n  prompt: \"hi\"\n", id, id))
+	}
+
+	run := func(concurrent bool, opts ...RunnerOption) ([]string, *models.EvaluationOutcome) {
+		spec := &models.BenchmarkSpec{
+			SpecIdentity: models.SpecIdentity{Name: "shuffle"},
+			Config: models.Config{
+				TrialsPerTask: 1,
+				TimeoutSec:    30,
+				EngineType:    "mock",
+				ModelID:       "mock-model",
+				Concurrent:    concurrent,
+				Workers:       2,
+			},
+			Tasks: []string{"task-*.yaml"},
+		}
+		cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+		outcome, err := NewTestRunner(cfg, execution.NewMockEngine("mock-model"), opts...).RunBenchmark(context.Background())
+		require.NoError(t, err)
+		var order []string
+		for _, to := range outcome.TestOutcomes {
+			order = append(order, to.TestID)
+		}
+		return order, outcome
+	}
+
+	unshuffled, outcome := run(false)
+	assert.Equal(t, specOrder, unshuffled)
+	assert.NotContains(t, outcome.Metadata, MetadataShuffleSeed)
+
+	first, outcome := run(false, WithShuffle(42))
+	second, _ := run(false, WithShuffle(42))
+	assert.Equal(t, first, second, "the same seed yields the same order")
+	assert.ElementsMatch(t, specOrder, first)
+	assert.NotEqual(t, specOrder, first)
+	assert.Equal(t, uint64(42), outcome.Metadata[MetadataShuffleSeed])
+
+	other, _ := run(false, WithShuffle(7))
+	assert.NotEqual(t, first, other, "a different seed yields a different order")
+
+	_, outcome = run(true, WithShuffle(42))
+	assert.NotContains(t, outcome.Metadata, MetadataShuffleSeed, "parallel runs aren't shuffled")
+	assert.Contains(t, outcome.Warnings, "task shuffling only applies to sequential runs; ignoring it for this parallel run")
+}
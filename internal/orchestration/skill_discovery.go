@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/microsoft/waza/internal/skill"
 )
 
@@ -56,7 +57,39 @@ func parseSkillName(path string) (string, error) {
 	return strings.TrimSpace(s.Frontmatter.Name), nil
 }
 
-// validateRequiredSkills checks that all required skills are discovered.
+// parseSkillVersion reads a SKILL.md file and returns its frontmatter version:
+// metadata.version, falling back to a top-level version key. Returns "" when unset.
+func parseSkillVersion(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading file: %w", err)
+	}
+
+	var s skill.Skill
+	if err := s.UnmarshalText(data); err != nil {
+		return "", fmt.Errorf("parsing SKILL.md: %w", err)
+	}
+
+	if meta, ok := s.FrontmatterRaw["metadata"].(map[string]any); ok {
+		if v, ok := meta["version"]; ok && v != nil {
+			return strings.TrimSpace(fmt.Sprint(v)), nil
+		}
+	}
+	if v, ok := s.FrontmatterRaw["version"]; ok && v != nil {
+		return strings.TrimSpace(fmt.Sprint(v)), nil
+	}
+	return "", nil
+}
+
+// splitSkillRequirement splits a required_skills entry such as "azure-deploy@>=1.2.0"
+// into the skill name and its version constraint ("" when unconstrained).
+func splitSkillRequirement(requirement string) (name, constraint string) {
+	name, constraint, _ = strings.Cut(requirement, "@")
+	return strings.TrimSpace(name), strings.TrimSpace(constraint)
+}
+
+// validateRequiredSkills checks that all required skills are discovered and, for
+// entries with an @constraint, that the skill's frontmatter version satisfies it.
 // Returns nil if validation passes, or an error describing what's missing.
 func validateRequiredSkills(requiredSkills []string, discoveredSkills map[string]string, searchedDirs []string) error {
 	if len(requiredSkills) == 0 {
@@ -64,10 +97,19 @@ func validateRequiredSkills(requiredSkills []string, discoveredSkills map[string
 		return nil
 	}
 
-	var missing []string
+	var missing, mismatched []string
 	for _, required := range requiredSkills {
-		if _, found := discoveredSkills[required]; !found {
-			missing = append(missing, required)
+		name, constraint := splitSkillRequirement(required)
+		path, found := discoveredSkills[name]
+		if !found {
+			missing = append(missing, name)
+			continue
+		}
+		if constraint == "" {
+			continue
+		}
+		if msg := checkSkillVersion(name, constraint, path); msg != "" {
+			mismatched = append(mismatched, msg)
 		}
 	}
 
@@ -93,5 +135,38 @@ func validateRequiredSkills(requiredSkills []string, discoveredSkills map[string
 		return errors.New(sb.String())
 	}
 
+	if len(mismatched) > 0 {
+		var sb strings.Builder
+		sb.WriteString("required skill versions not satisfied:\n")
+		for _, msg := range mismatched {
+			fmt.Fprintf(&sb, "  - %s\n", msg)
+		}
+		return errors.New(sb.String())
+	}
+
 	return nil
 }
+
+// checkSkillVersion returns a description of why the skill at path doesn't
+// satisfy constraint, or "" when it does.
+func checkSkillVersion(name, constraint, path string) string {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return fmt.Sprintf("%s: invalid version constraint %q: %v", name, constraint, err)
+	}
+	version, err := parseSkillVersion(path)
+	if err != nil {
+		return fmt.Sprintf("%s: reading version from %s: %v", name, path, err)
+	}
+	if version == "" {
+		return fmt.Sprintf("%s: requires %s but %s has no metadata.version", name, constraint, path)
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return fmt.Sprintf("%s: version %q in %s is not a valid semantic version", name, version, path)
+	}
+	if !c.Check(v) {
+		return fmt.Sprintf("%s: requires %s, found %s (%s)", name, constraint, version, path)
+	}
+	return ""
+}
@@ -234,3 +234,52 @@ func TestValidateRequiredSkills(t *testing.T) {
 		assert.True(t, foundDiscoveredSection, "Should have found skills section")
 	})
 }
+
+func TestValidateRequiredSkills_VersionConstraints(t *testing.T) {
+	tmpDir := t.TempDir()
+	skillPath := filepath.Join(tmpDir, "SKILL.md")
+	content := `---
+name: azure-deploy
+description: Deploys to Azure
+metadata:
+  version: 1.3.0
+---
+
+# Azure Deploy
+`
+	require.NoError(t, os.WriteFile(skillPath, []byte(content), 0644))
+	discovered := map[string]string{"azure-deploy": skillPath}
+	searched := []string{tmpDir}
+
+	t.Run("satisfied constraint", func(t *testing.T) {
+		err := validateRequiredSkills([]string{"azure-deploy@>=1.2.0"}, discovered, searched)
+		assert.NoError(t, err)
+	})
+
+	t.Run("unsatisfied constraint", func(t *testing.T) {
+		err := validateRequiredSkills([]string{"azure-deploy@>=2.0.0"}, discovered, searched)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "required skill versions not satisfied")
+		assert.Contains(t, err.Error(), "azure-deploy: requires >=2.0.0, found 1.3.0")
+	})
+
+	t.Run("missing skill reports name without constraint", func(t *testing.T) {
+		err := validateRequiredSkills([]string{"other-skill@>=1.0.0"}, discovered, searched)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "required skills not found:\n  - other-skill\n")
+	})
+
+	t.Run("invalid constraint", func(t *testing.T) {
+		err := validateRequiredSkills([]string{"azure-deploy@not-a-version"}, discovered, searched)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid version constraint "not-a-version"`)
+	})
+
+	t.Run("skill without version", func(t *testing.T) {
+		unversioned := filepath.Join(tmpDir, "unversioned.md")
+		require.NoError(t, os.WriteFile(unversioned, []byte("---\nname: plain\ndescription: x\n---\n"), 0644))
+		err := validateRequiredSkills([]string{"plain@>=1.0.0"}, map[string]string{"plain": unversioned}, searched)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "has no metadata.version")
+	})
+}
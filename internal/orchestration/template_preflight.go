@@ -0,0 +1,36 @@
+package orchestration
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/microsoft/waza/internal/models"
+	"github.com/microsoft/waza/internal/template"
+)
+
+// checkTemplates renders the templates tc runs with (system_prompt and a
+// templated context_dir) against the vars it will have at run time, so
+// undefined functions, parse errors and missing vars are caught before any
+// engine call instead of when the task starts.
+func (r *TestRunner) checkTemplates(tc *models.TestCase) []error {
+	var errs []error
+	if _, err := r.systemPromptFor(tc); err != nil {
+		errs = append(errs, fmt.Errorf("task %s: %w", tc.TestID, err))
+	}
+	if strings.Contains(tc.ContextRoot, "{{") {
+		if _, err := template.Render(tc.ContextRoot, r.taskTemplateContext(tc)); err != nil {
+			errs = append(errs, fmt.Errorf("task %s: context_dir: %w", tc.TestID, err))
+		}
+	}
+	return errs
+}
+
+// templatePreflightError reports every template error found while selecting
+// test cases at once, or nil if there were none.
+func templatePreflightError(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("template pre-flight failed with %d error(s):\n%w", len(errs), errors.Join(errs...))
+}
@@ -0,0 +1,89 @@
+package orchestration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBenchmark_TemplatePreflight(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksDir := filepath.Join(tmpDir, "tasks")
+	require.NoError(t, os.MkdirAll(tasksDir, 0o755))
+	writeTaskFile(t, filepath.Join(tasksDir, "a.yaml"), `id: a
+name: Alpha
+inputs:
+  prompt: "explain"
+`)
+	writeTaskFile(t, filepath.Join(tasksDir, "b.yaml"), `id: b
+name: Beta
+system_prompt: "{{ shout .Vars.variant }}"
+inputs:
+  prompt: "explain"
+`)
+	writeTaskFile(t, filepath.Join(tasksDir, "c.yaml"), `id: c
+name: Gamma
+system_prompt: "{{.Vars.undefined}}"
+context_dir: "fixtures/{{.Vars.missing}}"
+inputs:
+  prompt: "explain"
+`)
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "template-preflight"},
+		Inputs:       map[string]string{"variant": "B"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Tasks: []string{"tasks/*.yaml"},
+	}
+
+	engine := &countingEngine{MockEngine: execution.NewMockEngine("mock-model")}
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	_, err := NewTestRunner(cfg, engine).RunBenchmark(context.Background())
+	require.Error(t, err)
+	assert.Zero(t, engine.calls, "pre-flight should fail before any engine call")
+
+	msg := err.Error()
+	assert.Contains(t, msg, "3 error(s)")
+	assert.Contains(t, msg, `task b: system_prompt: template: parse: template: :1: function "shout" not defined`)
+	assert.Contains(t, msg, "task c: system_prompt")
+	assert.Contains(t, msg, "task c: context_dir")
+	assert.NotContains(t, msg, "task a")
+}
+
+func TestRunBenchmark_TemplatePreflightCSV(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeCSV(t, tmpDir, "data.csv", "id,prompt\nA,{{ shout .Vars.id }}\nB,fine\nC,{{.Vars.nope}}\n")
+
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "template-preflight-csv"},
+		TasksFrom:    "data.csv",
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+	}
+
+	engine := &countingEngine{MockEngine: execution.NewMockEngine("mock-model")}
+	cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+	_, err := NewTestRunner(cfg, engine).RunBenchmark(context.Background())
+	require.Error(t, err)
+	assert.Zero(t, engine.calls)
+	assert.Contains(t, err.Error(), "2 error(s)")
+	assert.Contains(t, err.Error(), "resolving prompt template for row 1")
+	assert.Contains(t, err.Error(), "resolving prompt template for row 3")
+	assert.ErrorIs(t, err, errRowTemplate)
+}
@@ -0,0 +1,52 @@
+package orchestration
+
+import (
+	"path"
+	"slices"
+
+	"github.com/microsoft/waza/internal/models"
+)
+
+// toolPolicy returns the allowed and denied tool patterns for tc. A task's
+// own list replaces the spec's list of the same kind.
+func (r *TestRunner) toolPolicy(tc *models.TestCase) (allowed, denied []string) {
+	cfg := r.cfg.Spec().Config
+	allowed, denied = cfg.AllowedTools, cfg.DeniedTools
+	if tc.AllowedTools != nil {
+		allowed = tc.AllowedTools
+	}
+	if tc.DeniedTools != nil {
+		denied = tc.DeniedTools
+	}
+	return allowed, denied
+}
+
+// toolViolations returns the names of tools in calls that match a denied
+// pattern, or that match no allowed pattern when allowed is non-empty. Each
+// tool is listed once, in the order it was first called.
+func toolViolations(calls []models.ToolCall, allowed, denied []string) []string {
+	if len(allowed) == 0 && len(denied) == 0 {
+		return nil
+	}
+	var violations []string
+	for _, call := range calls {
+		if slices.Contains(violations, call.Name) {
+			continue
+		}
+		if matchesAnyTool(denied, call.Name) || (len(allowed) > 0 && !matchesAnyTool(allowed, call.Name)) {
+			violations = append(violations, call.Name)
+		}
+	}
+	return violations
+}
+
+// matchesAnyTool reports whether name matches any glob pattern. Malformed
+// patterns match only the identical name.
+func matchesAnyTool(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, err := path.Match(p, name); ok || (err != nil && p == name) {
+			return true
+		}
+	}
+	return false
+}
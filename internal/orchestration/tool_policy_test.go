@@ -0,0 +1,147 @@
+package orchestration
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolViolations(t *testing.T) {
+	calls := []models.ToolCall{{Name: "view"}, {Name: "bash"}, {Name: "github-search"}, {Name: "bash"}, {Name: "web_fetch"}}
+
+	tests := []struct {
+		name    string
+		allowed []string
+		denied  []string
+		want    []string
+	}{
+		{"no policy", nil, nil, nil},
+		{"denied", nil, []string{"bash", "web_*"}, []string{"bash", "web_fetch"}},
+		{"allowlist", []string{"view", "github-*"}, nil, []string{"bash", "web_fetch"}},
+		{"deny wins over allow", []string{"*"}, []string{"github-*"}, []string{"github-search"}},
+		{"everything allowed", []string{"*"}, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, toolViolations(calls, tt.allowed, tt.denied))
+		})
+	}
+}
+
+// toolCallingEngine wraps the mock engine, reports the given tool calls and
+// records the last request it saw.
+type toolCallingEngine struct {
+	*execution.MockEngine
+	calls   []models.ToolCall
+	lastReq *execution.ExecutionRequest
+}
+
+func (e *toolCallingEngine) Execute(ctx context.Context, req *execution.ExecutionRequest) (*execution.ExecutionResponse, error) {
+	e.lastReq = req
+	resp, err := e.MockEngine.Execute(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.ToolCalls = e.calls
+	return resp, nil
+}
+
+func TestRunBenchmark_ToolViolations(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "task.yaml"), `id: safe-task
+name: Safe Task
+inputs:
+  prompt: "look but don't touch"
+`)
+	writeTaskFile(t, filepath.Join(tmpDir, "override.yaml"), `id: override-task
+name: Override Task
+allowed_tools: ["*"]
+denied_tools: []
+inputs:
+  prompt: "anything goes"
+`)
+
+	run := func(failOnViolation bool) (*models.EvaluationOutcome, *toolCallingEngine) {
+		spec := &models.BenchmarkSpec{
+			SpecIdentity: models.SpecIdentity{Name: "tools"},
+			Config: models.Config{
+				TrialsPerTask:       1,
+				TimeoutSec:          30,
+				EngineType:          "mock",
+				ModelID:             "mock-model",
+				AllowedTools:        []string{"view", "grep"},
+				DeniedTools:         []string{"bash"},
+				FailOnToolViolation: failOnViolation,
+			},
+			Graders: []models.GraderConfig{{
+				Kind:       models.GraderKindText,
+				Identifier: "mock",
+				Parameters: models.TextGraderParameters{Contains: []string{"Mock response"}},
+			}},
+			Tasks: []string{"task.yaml", "override.yaml"},
+		}
+		engine := &toolCallingEngine{
+			MockEngine: execution.NewMockEngine("mock-model"),
+			calls:      []models.ToolCall{{Name: "view"}, {Name: "bash"}, {Name: "edit"}},
+		}
+		cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+		outcome, err := NewTestRunner(cfg, engine).RunBenchmark(context.Background())
+		require.NoError(t, err)
+		return outcome, engine
+	}
+
+	byID := func(outcome *models.EvaluationOutcome) map[string]models.TestOutcome {
+		m := map[string]models.TestOutcome{}
+		for _, to := range outcome.TestOutcomes {
+			m[to.TestID] = to
+		}
+		require.Len(t, m, 2)
+		return m
+	}
+
+	outcome, engine := run(false)
+	require.NotNil(t, engine.lastReq)
+	tasks := byID(outcome)
+	safe := tasks["safe-task"].Runs[0]
+	assert.Equal(t, []string{"bash", "edit"}, safe.ToolViolations)
+	assert.Equal(t, models.StatusPassed, safe.Status, "violations are only reported by default")
+	assert.Empty(t, tasks["override-task"].Runs[0].ToolViolations, "the task's own lists replace the spec's")
+
+	outcome, _ = run(true)
+	tasks = byID(outcome)
+	safe = tasks["safe-task"].Runs[0]
+	assert.Equal(t, []string{"bash", "edit"}, safe.ToolViolations)
+	assert.Equal(t, models.StatusFailed, safe.Status)
+	assert.Equal(t, models.StatusFailed, tasks["safe-task"].Status)
+	assert.Equal(t, models.StatusPassed, tasks["override-task"].Status)
+}
+
+func TestBuildExecutionRequest_ToolPolicy(t *testing.T) {
+	spec := &models.BenchmarkSpec{Config: models.Config{TimeoutSec: 30, AllowedTools: []string{"view", "grep"}, DeniedTools: []string{"bash"}}}
+	runner := NewTestRunner(config.NewBenchmarkConfig(spec), nil)
+
+	req, err := runner.buildExecutionRequest(&models.TestCase{TestID: "t"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"view", "grep"}, req.AllowedTools)
+	assert.Equal(t, []string{"bash"}, req.DeniedTools)
+}
+
+func TestToolPolicy_TaskOverridesSpec(t *testing.T) {
+	spec := &models.BenchmarkSpec{Config: models.Config{AllowedTools: []string{"view"}, DeniedTools: []string{"bash"}}}
+	runner := NewTestRunner(config.NewBenchmarkConfig(spec), nil)
+
+	allowed, denied := runner.toolPolicy(&models.TestCase{})
+	assert.Equal(t, []string{"view"}, allowed)
+	assert.Equal(t, []string{"bash"}, denied)
+
+	allowed, denied = runner.toolPolicy(&models.TestCase{AllowedTools: []string{"*"}, DeniedTools: []string{}})
+	assert.Equal(t, []string{"*"}, allowed)
+	assert.Empty(t, denied)
+	assert.Nil(t, toolViolations([]models.ToolCall{{Name: "bash"}}, allowed, denied))
+}
@@ -0,0 +1,90 @@
+package orchestration
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/microsoft/waza/internal/models"
+)
+
+// WithVerifyCache re-executes a random sample of the tasks served from the
+// result cache, picking each cache hit with probability sample (0-1), and
+// fails the run if a re-executed task's result differs from the cached one.
+// It's a debugging aid for cache keys that miss a field the result depends on.
+func WithVerifyCache(sample float64) RunnerOption {
+	return func(r *TestRunner) {
+		r.verifyCacheSample = sample
+	}
+}
+
+// freshRunKey marks a context whose runs skip the response and grader caches,
+// which share the outcome cache's inputs and would hide the same key bugs.
+type freshRunKey struct{}
+
+// verifyCachedOutcome re-runs tc, whose cached outcome is cached, and records
+// a mismatch if the results diverge. The fresh outcome is returned either way;
+// the cache entries are left as is.
+func (r *TestRunner) verifyCachedOutcome(ctx context.Context, tc *models.TestCase, cached models.TestOutcome, testNum, totalTests int) models.TestOutcome {
+	fresh := r.runTestUncached(context.WithValue(ctx, freshRunKey{}, true), tc, testNum, totalTests)
+	if diff := cachedOutcomeDiff(cached, r.redactor.TestOutcome(fresh)); diff != "" {
+		r.cacheMismatchMu.Lock()
+		r.cacheMismatches = append(r.cacheMismatches, fmt.Sprintf("%s: %s", tc.DisplayName, diff))
+		r.cacheMismatchMu.Unlock()
+	}
+	return fresh
+}
+
+// sampleCacheHit reports whether a cache hit should be re-executed.
+func (r *TestRunner) sampleCacheHit() bool {
+	return r.verifyCacheSample > 0 && rand.Float64() < r.verifyCacheSample
+}
+
+// cacheVerifyError reports the cache hits whose re-executed results diverged.
+func (r *TestRunner) cacheVerifyError() error {
+	r.cacheMismatchMu.Lock()
+	defer r.cacheMismatchMu.Unlock()
+	if len(r.cacheMismatches) == 0 {
+		return nil
+	}
+	slices.Sort(r.cacheMismatches)
+	return fmt.Errorf("cache verification failed: %d cached task(s) diverged when re-executed, so the cache key is missing an input:\n  %s",
+		len(r.cacheMismatches), strings.Join(r.cacheMismatches, "\n  "))
+}
+
+// cachedOutcomeDiff describes the first difference between a cached outcome
+// and a fresh run of the same task, or returns "" if their status, runs and
+// grader results agree. Output text and timings aren't compared.
+func cachedOutcomeDiff(cached, fresh models.TestOutcome) string {
+	if cached.Status != fresh.Status {
+		return fmt.Sprintf("status %s in cache, %s when re-executed", cached.Status, fresh.Status)
+	}
+	if len(cached.Runs) != len(fresh.Runs) {
+		return fmt.Sprintf("%d run(s) in cache, %d when re-executed", len(cached.Runs), len(fresh.Runs))
+	}
+	for i, c := range cached.Runs {
+		f := fresh.Runs[i]
+		if c.Status != f.Status {
+			return fmt.Sprintf("run %d status %s in cache, %s when re-executed", i+1, c.Status, f.Status)
+		}
+		if len(c.Validations) != len(f.Validations) {
+			return fmt.Sprintf("run %d has %d grader result(s) in cache, %d when re-executed", i+1, len(c.Validations), len(f.Validations))
+		}
+		for _, name := range slices.Sorted(maps.Keys(c.Validations)) {
+			cv := c.Validations[name]
+			fv, ok := f.Validations[name]
+			if !ok {
+				return fmt.Sprintf("run %d grader %s missing when re-executed", i+1, name)
+			}
+			if cv.Passed != fv.Passed || math.Abs(cv.Score-fv.Score) > 1e-9 {
+				return fmt.Sprintf("run %d grader %s passed=%t score=%.2f in cache, passed=%t score=%.2f when re-executed",
+					i+1, name, cv.Passed, cv.Score, fv.Passed, fv.Score)
+			}
+		}
+	}
+	return ""
+}
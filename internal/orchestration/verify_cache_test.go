@@ -0,0 +1,106 @@
+package orchestration
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/microsoft/waza/internal/cache"
+	"github.com/microsoft/waza/internal/config"
+	"github.com/microsoft/waza/internal/execution"
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBenchmark_VerifyCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTaskFile(t, filepath.Join(tmpDir, "task.yaml"), `id: task
+name: Task
+inputs:
+  prompt: "hello"
+graders:
+  - name: mentions-mock
+    type: text
+    config:
+      contains: ["Mock response"]
+`)
+	spec := &models.BenchmarkSpec{
+		SpecIdentity: models.SpecIdentity{Name: "verify-cache"},
+		Config: models.Config{
+			TrialsPerTask: 1,
+			TimeoutSec:    30,
+			EngineType:    "mock",
+			ModelID:       "mock-model",
+		},
+		Tasks: []string{"task.yaml"},
+	}
+	resultCache := cache.New(t.TempDir())
+	newRunner := func(engine execution.AgentEngine, opts ...RunnerOption) *TestRunner {
+		cfg := config.NewBenchmarkConfig(spec, config.WithSpecDir(tmpDir))
+		return NewTestRunner(cfg, engine, append(opts, WithCache(resultCache))...)
+	}
+
+	first, err := newRunner(execution.NewMockEngine("mock-model")).RunBenchmark(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, models.StatusPassed, first.TestOutcomes[0].Status)
+
+	// A cache hit that still matches a fresh run passes verification
+	engine := &countingEngine{MockEngine: execution.NewMockEngine("mock-model")}
+	verified, err := newRunner(engine, WithVerifyCache(1)).RunBenchmark(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, engine.calls, "the sampled cache hit should be re-executed")
+	assert.Equal(t, models.StatusPassed, verified.TestOutcomes[0].Status)
+
+	// Inject a divergent result under the task's key, as a key that misses an
+	// input would serve after the spec changed
+	r := newRunner(execution.NewMockEngine("mock-model"))
+	testCases, err := r.loadTestCasesFromFiles()
+	require.NoError(t, err)
+	key, err := r.outcomeCacheKey(spec, testCases[0])
+	require.NoError(t, err)
+	stale, found := resultCache.Get(key)
+	require.True(t, found)
+	stale.Status = models.StatusFailed
+	stale.Runs[0].Status = models.StatusFailed
+	v := stale.Runs[0].Validations["mentions-mock"]
+	v.Passed, v.Score = false, 0
+	stale.Runs[0].Validations["mentions-mock"] = v
+	require.NoError(t, resultCache.Put(key, stale))
+
+	// Without verification the stale result is reused as is
+	reused, err := newRunner(execution.NewMockEngine("mock-model")).RunBenchmark(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusFailed, reused.TestOutcomes[0].Status)
+
+	_, err = newRunner(execution.NewMockEngine("mock-model"), WithVerifyCache(1)).RunBenchmark(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cache verification failed: 1 cached task(s) diverged")
+	assert.Contains(t, err.Error(), "Task: status failed in cache, passed when re-executed")
+}
+
+func TestCachedOutcomeDiff(t *testing.T) {
+	outcome := func(passed bool, score float64) models.TestOutcome {
+		return models.TestOutcome{
+			Status: models.StatusPassed,
+			Runs: []models.RunResult{{
+				Status: models.StatusPassed,
+				Validations: map[string]models.GraderResults{
+					"g": {Name: "g", Passed: passed, Score: score},
+				},
+			}},
+		}
+	}
+
+	assert.Empty(t, cachedOutcomeDiff(outcome(true, 0.8), outcome(true, 0.8)))
+	assert.Equal(t, "run 1 grader g passed=true score=0.80 in cache, passed=true score=0.60 when re-executed",
+		cachedOutcomeDiff(outcome(true, 0.8), outcome(true, 0.6)))
+
+	extraRun := outcome(true, 0.8)
+	extraRun.Runs = append(extraRun.Runs, extraRun.Runs[0])
+	assert.Equal(t, "1 run(s) in cache, 2 when re-executed", cachedOutcomeDiff(outcome(true, 0.8), extraRun))
+
+	renamed := outcome(true, 0.8)
+	renamed.Runs[0].Validations = map[string]models.GraderResults{"h": {Name: "h", Passed: true, Score: 0.8}}
+	assert.Equal(t, "run 1 grader g missing when re-executed", cachedOutcomeDiff(outcome(true, 0.8), renamed))
+}
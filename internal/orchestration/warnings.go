@@ -0,0 +1,34 @@
+package orchestration
+
+import (
+	"fmt"
+	"os"
+	"slices"
+)
+
+// warnf prints a non-fatal problem to stderr and records it, so it ends up in
+// the outcome's Warnings.
+func (r *TestRunner) warnf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	fmt.Fprintf(os.Stderr, "[WARN] %s\n", msg)
+
+	r.warnMu.Lock()
+	defer r.warnMu.Unlock()
+	r.warnings = append(r.warnings, msg)
+}
+
+func (r *TestRunner) warningCount() int {
+	r.warnMu.Lock()
+	defer r.warnMu.Unlock()
+	return len(r.warnings)
+}
+
+// warningsSince returns the warnings recorded after the first n, or nil.
+func (r *TestRunner) warningsSince(n int) []string {
+	r.warnMu.Lock()
+	defer r.warnMu.Unlock()
+	if n >= len(r.warnings) {
+		return nil
+	}
+	return slices.Clone(r.warnings[n:])
+}
@@ -66,6 +66,10 @@ type CacheConfig struct {
 
 	// Dir is relative to the project root. See [ProjectConfig.Dir].
 	Dir string `yaml:"dir,omitempty"`
+
+	Backend  string `yaml:"backend,omitempty"`  // "file" (default) or "http"
+	URL      string `yaml:"url,omitempty"`      // base URL of the http backend
+	TokenEnv string `yaml:"tokenEnv,omitempty"` // env var holding a bearer token for the http backend
 }
 
 // ServerConfig holds dashboard server settings.
@@ -279,6 +283,15 @@ func mergeConfig(dst, src *ProjectConfig) {
 	if src.Cache.Dir != "" {
 		dst.Cache.Dir = src.Cache.Dir
 	}
+	if src.Cache.Backend != "" {
+		dst.Cache.Backend = src.Cache.Backend
+	}
+	if src.Cache.URL != "" {
+		dst.Cache.URL = src.Cache.URL
+	}
+	if src.Cache.TokenEnv != "" {
+		dst.Cache.TokenEnv = src.Cache.TokenEnv
+	}
 
 	// Server
 	if src.Server.Port != 0 {
@@ -67,6 +67,9 @@ defaults:
 cache:
   enabled: true
   dir: ".my-cache"
+  backend: http
+  url: "https://cache.example.com/waza"
+  tokenEnv: WAZA_CACHE_TOKEN
 server:
   port: 8080
   resultsDir: "./output"
@@ -104,6 +107,9 @@ graders:
 	assertBoolPtr(t, "Defaults.SessionLog", true, cfg.Defaults.SessionLog)
 	assertBoolPtr(t, "Cache.Enabled", true, cfg.Cache.Enabled)
 	assertEqual(t, "Cache.Dir", ".my-cache", cfg.Cache.Dir)
+	assertEqual(t, "Cache.Backend", "http", cfg.Cache.Backend)
+	assertEqual(t, "Cache.URL", "https://cache.example.com/waza", cfg.Cache.URL)
+	assertEqual(t, "Cache.TokenEnv", "WAZA_CACHE_TOKEN", cfg.Cache.TokenEnv)
 	assertEqualInt(t, "Server.Port", 8080, cfg.Server.Port)
 	assertEqual(t, "Server.ResultsDir", "./output", cfg.Server.ResultsDir)
 	assertEqual(t, "Dev.Model", "gpt-5", cfg.Dev.Model)
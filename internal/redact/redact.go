@@ -0,0 +1,187 @@
+// Package redact masks sensitive text, such as customer data or credentials,
+// in results before they're written to disk. Grading always sees the original
+// text; only persisted copies are redacted.
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/microsoft/waza/internal/models"
+)
+
+// Placeholder replaces every redacted match.
+const Placeholder = "[REDACTED]"
+
+// profiles are the named pattern sets accepted in config.redact.
+var profiles = map[string][]string{
+	"secrets": {
+		`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`,
+		`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?[A-Za-z0-9/+=]{40}`,
+		`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]{20,}=*`,
+		`-----BEGIN (?:[A-Z]+ )?PRIVATE KEY-----[\s\S]*?-----END (?:[A-Z]+ )?PRIVATE KEY-----`,
+		`\bgh[pousr]_[A-Za-z0-9]{36,}\b`,
+		`(?i)\b(?:api[_-]?key|secret|password|passwd|token)["']?\s*[:=]\s*["']?[^\s"']{8,}`,
+	},
+	"pii": {
+		`\b[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}\b`,
+		`\b\d{3}-\d{2}-\d{4}\b`,
+		`\b(?:\d[ -]?){13,16}\b`,
+		`(?:\+\d{1,3}[ .-]?)?\(?\b\d{3}\)?[ .-]\d{3}[ .-]\d{4}\b`,
+		`\b(?:\d{1,3}\.){3}\d{1,3}\b`,
+	},
+}
+
+// Profiles returns the names of the built-in profiles.
+func Profiles() []string {
+	return slices.Sorted(maps.Keys(profiles))
+}
+
+// Redactor replaces matches of its patterns with Placeholder. A nil
+// *Redactor leaves everything unchanged.
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// New builds a Redactor from config.redact entries. Each entry is a profile
+// name ("secrets", "pii") or a regular expression. It returns nil when there
+// are no entries.
+func New(entries []string) (*Redactor, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	r := &Redactor{}
+	for i, entry := range entries {
+		if exprs, ok := profiles[entry]; ok {
+			for _, e := range exprs {
+				r.patterns = append(r.patterns, regexp.MustCompile(e))
+			}
+			continue
+		}
+		re, err := regexp.Compile(entry)
+		if err != nil {
+			return nil, fmt.Errorf("redact[%d]: %q is not a profile (%s) or a valid regex: %w", i, entry, strings.Join(Profiles(), ", "), err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// String returns s with every match replaced.
+func (r *Redactor) String(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, Placeholder)
+	}
+	return s
+}
+
+// TestOutcome returns a copy of o with run outputs, transcripts, tool calls,
+// grader feedback and error messages redacted. o is not modified.
+func (r *Redactor) TestOutcome(o models.TestOutcome) models.TestOutcome {
+	if r == nil {
+		return o
+	}
+	runs := make([]models.RunResult, len(o.Runs))
+	for i, run := range o.Runs {
+		run.FinalOutput = r.String(run.FinalOutput)
+		run.ErrorMsg = r.String(run.ErrorMsg)
+		run.Validations = r.validations(run.Validations)
+		run.Transcript = redactJSON(r, run.Transcript)
+		run.SessionDigest.ToolCalls = redactJSON(r, run.SessionDigest.ToolCalls)
+		runs[i] = run
+	}
+	o.Runs = runs
+	return o
+}
+
+// Outcome redacts every task outcome in o, including a baseline outcome,
+// in place.
+func (r *Redactor) Outcome(o *models.EvaluationOutcome) {
+	if r == nil || o == nil {
+		return
+	}
+	for i := range o.TestOutcomes {
+		o.TestOutcomes[i] = r.TestOutcome(o.TestOutcomes[i])
+	}
+	r.Outcome(o.BaselineOutcome)
+}
+
+// Transcript returns a copy of t with its prompt, output, transcript, tool
+// calls, grader feedback and error message redacted. t is not modified.
+func (r *Redactor) Transcript(t *models.TaskTranscript) *models.TaskTranscript {
+	if r == nil || t == nil {
+		return t
+	}
+	c := *t
+	c.Prompt = r.String(c.Prompt)
+	c.FinalOutput = r.String(c.FinalOutput)
+	c.ErrorMsg = r.String(c.ErrorMsg)
+	c.Validations = r.validations(c.Validations)
+	c.Transcript = redactJSON(r, c.Transcript)
+	c.Session.ToolCalls = redactJSON(r, c.Session.ToolCalls)
+	return &c
+}
+
+func (r *Redactor) validations(in map[string]models.GraderResults) map[string]models.GraderResults {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]models.GraderResults, len(in))
+	for name, g := range in {
+		g.Feedback = r.String(g.Feedback)
+		if g.Details != nil {
+			g.Details = redactJSON(r, g.Details)
+		}
+		out[name] = g
+	}
+	return out
+}
+
+// redactJSON redacts every string value in v by round-tripping it through
+// JSON, which reaches the loosely typed fields of session events. If v
+// doesn't round-trip it's returned unchanged.
+func redactJSON[T any](r *Redactor, v T) T {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return v
+	}
+	data, err = json.Marshal(r.walk(generic))
+	if err != nil {
+		return v
+	}
+	var out T
+	if err := json.Unmarshal(data, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+func (r *Redactor) walk(v any) any {
+	switch v := v.(type) {
+	case string:
+		return r.String(v)
+	case []any:
+		for i := range v {
+			v[i] = r.walk(v[i])
+		}
+		return v
+	case map[string]any:
+		for k := range v {
+			v[k] = r.walk(v[k])
+		}
+		return v
+	default:
+		return v
+	}
+}
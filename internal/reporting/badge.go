@@ -0,0 +1,69 @@
+package reporting
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"os"
+
+	"github.com/microsoft/waza/internal/models"
+)
+
+// Badge colors, matching shields.io's brightgreen and red.
+const (
+	BadgeColorPassing = "#4c1"
+	BadgeColorFailing = "#e05d44"
+)
+
+const badgeLabel = "eval"
+
+// badgeCharWidth approximates the width in pixels of one character of 11px
+// Verdana, which is what shields.io badges use. Close enough that the text
+// fits without measuring fonts.
+const badgeCharWidth = 7
+
+// RenderBadge renders a shields.io-style SVG badge summarizing outcome's pass
+// rate, e.g. "eval | 92% passing". The badge is green when the success rate is
+// at least threshold (0-1) and red otherwise.
+func RenderBadge(outcome *models.EvaluationOutcome, threshold float64) string {
+	rate := outcome.Digest.SuccessRate
+	message := fmt.Sprintf("%d%% passing", int(math.Round(rate*100)))
+	color := BadgeColorFailing
+	if rate >= threshold {
+		color = BadgeColorPassing
+	}
+
+	labelWidth := len(badgeLabel)*badgeCharWidth + 10
+	messageWidth := len(message)*badgeCharWidth + 10
+	width := labelWidth + messageWidth
+	title := html.EscapeString(badgeLabel + ": " + message)
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[2]s">
+  <title>%[2]s</title>
+  <linearGradient id="s" x2="0" y2="100%%">
+    <stop offset="0" stop-color="#bbb" stop-opacity=".1"/>
+    <stop offset="1" stop-opacity=".1"/>
+  </linearGradient>
+  <clipPath id="r">
+    <rect width="%[1]d" height="20" rx="3" fill="#fff"/>
+  </clipPath>
+  <g clip-path="url(#r)">
+    <rect width="%[3]d" height="20" fill="#555"/>
+    <rect x="%[3]d" width="%[4]d" height="20" fill="%[5]s"/>
+    <rect width="%[1]d" height="20" fill="url(#s)"/>
+  </g>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+    <text x="%[6]d" y="15" fill="#010101" fill-opacity=".3">%[7]s</text>
+    <text x="%[6]d" y="14">%[7]s</text>
+    <text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[9]s</text>
+    <text x="%[8]d" y="14">%[9]s</text>
+  </g>
+</svg>
+`, width, title, labelWidth, messageWidth, color,
+		labelWidth/2, badgeLabel, labelWidth+messageWidth/2, html.EscapeString(message))
+}
+
+// WriteBadge writes the badge from RenderBadge to path.
+func WriteBadge(outcome *models.EvaluationOutcome, path string, threshold float64) error {
+	return os.WriteFile(path, []byte(RenderBadge(outcome, threshold)), 0644)
+}
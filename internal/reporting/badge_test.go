@@ -0,0 +1,49 @@
+package reporting
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderBadge(t *testing.T) {
+	outcome := &models.EvaluationOutcome{Digest: models.OutcomeDigest{SuccessRate: 0.916}}
+
+	passing := RenderBadge(outcome, 0.9)
+	assert.Contains(t, passing, ">92% passing<")
+	assert.Contains(t, passing, ">eval<")
+	assert.Contains(t, passing, `fill="`+BadgeColorPassing+`"`)
+	assert.NotContains(t, passing, BadgeColorFailing)
+
+	failing := RenderBadge(outcome, 0.95)
+	assert.Contains(t, failing, ">92% passing<")
+	assert.Contains(t, failing, `fill="`+BadgeColorFailing+`"`)
+	assert.NotContains(t, failing, BadgeColorPassing)
+
+	// A rate exactly at the threshold passes.
+	exact := &models.EvaluationOutcome{Digest: models.OutcomeDigest{SuccessRate: 0.8}}
+	assert.Contains(t, RenderBadge(exact, 0.8), BadgeColorPassing)
+}
+
+func TestWriteBadge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "badge.svg")
+	outcome := &models.EvaluationOutcome{Digest: models.OutcomeDigest{SuccessRate: 0.5}}
+	require.NoError(t, WriteBadge(outcome, path, 0.8))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "50% passing")
+
+	// The badge must be well-formed XML to render anywhere.
+	var svg struct {
+		XMLName xml.Name `xml:"svg"`
+		Title   string   `xml:"title"`
+	}
+	require.NoError(t, xml.Unmarshal(data, &svg))
+	assert.Equal(t, "eval: 50% passing", svg.Title)
+}
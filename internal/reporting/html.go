@@ -0,0 +1,113 @@
+package reporting
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/microsoft/waza/internal/models"
+)
+
+// ReportLink is a file in a report bundle, with Href relative to index.html.
+type ReportLink struct {
+	Name string
+	Href string
+}
+
+// ReportEntry is one evaluated skill and model in a report bundle.
+type ReportEntry struct {
+	Skill   string
+	Label   string
+	Outcome *models.EvaluationOutcome
+	// Files are the entry's own outputs, such as its results JSON and JUnit XML.
+	Files []ReportLink
+}
+
+// ReportIndex is everything index.html lists.
+type ReportIndex struct {
+	Generated   time.Time
+	Entries     []ReportEntry
+	Summary     *ReportLink
+	Transcripts []ReportLink
+}
+
+var reportFuncs = template.FuncMap{
+	"percent": func(f float64) string { return fmt.Sprintf("%.1f%%", f*100) },
+	"score":   func(f float64) string { return fmt.Sprintf("%.2f", f) },
+	"lower":   func(s models.Status) string { return strings.ToLower(string(s)) },
+}
+
+// reportTemplate is self-contained: styles are inline and nothing is fetched,
+// so the bundle can be zipped, attached or opened from disk.
+var reportTemplate = template.Must(template.New("index").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>waza eval report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem; color: #1f2328; }
+h1 { font-size: 1.5rem; }
+h2 { font-size: 1.2rem; margin-top: 2rem; }
+table { border-collapse: collapse; margin: .5rem 0 1rem; }
+th, td { border: 1px solid #d0d7de; padding: .3rem .6rem; text-align: left; }
+th { background: #f6f8fa; }
+.passed { color: #1a7f37; }
+.failed, .error { color: #cf222e; }
+.skipped { color: #9a6700; }
+.meta { color: #656d76; }
+</style>
+</head>
+<body>
+<h1>waza eval report</h1>
+<p class="meta">Generated {{.Generated.Format "2006-01-02 15:04:05 MST"}}</p>
+{{- if .Summary}}
+<p><a href="{{.Summary.Href}}">{{.Summary.Name}}</a></p>
+{{- end}}
+{{- range .Entries}}
+<h2>{{if .Skill}}{{.Skill}} · {{end}}{{.Label}}</h2>
+{{- with .Outcome}}
+<p><strong>{{percent .Digest.SuccessRate}} passing</strong> ({{.Digest.Succeeded}} of {{.Digest.TotalTests}} tasks) · score {{score .Digest.AggregateScore}} · {{.Setup.EngineType}} / {{.Setup.ModelID}}{{if .RunID}} · run {{.RunID}}{{end}}</p>
+<table>
+<tr><th>Task</th><th>Status</th><th>Score</th></tr>
+{{- range .TestOutcomes}}
+<tr><td>{{.DisplayName}}</td><td class="{{lower .Status}}">{{.Status}}</td><td>{{if .Stats}}{{score .Stats.AvgScore}}{{else}}-{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+<ul>
+{{- range .Files}}
+<li><a href="{{.Href}}">{{.Name}}</a></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Transcripts}}
+<h2>Transcripts</h2>
+<ul>
+{{- range .Transcripts}}
+<li><a href="{{.Href}}">{{.Name}}</a></li>
+{{- end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`))
+
+// RenderHTMLIndex renders index as a self-contained HTML page.
+func RenderHTMLIndex(index ReportIndex) (string, error) {
+	var b strings.Builder
+	if err := reportTemplate.Execute(&b, index); err != nil {
+		return "", fmt.Errorf("rendering report index: %w", err)
+	}
+	return b.String(), nil
+}
+
+// WriteHTMLIndex writes the page from RenderHTMLIndex to path.
+func WriteHTMLIndex(path string, index ReportIndex) error {
+	page, err := RenderHTMLIndex(index)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(page), 0644)
+}
@@ -0,0 +1,52 @@
+package reporting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderHTMLIndex(t *testing.T) {
+	outcome := newTestOutcome()
+	page, err := RenderHTMLIndex(ReportIndex{
+		Generated: time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC),
+		Entries: []ReportEntry{{
+			Skill:   "code-explainer",
+			Label:   "gpt-4o",
+			Outcome: outcome,
+			Files:   []ReportLink{{Name: "gpt-4o.json", Href: "gpt-4o.json"}},
+		}},
+		Summary:     &ReportLink{Name: "summary.json", Href: "summary.json"},
+		Transcripts: []ReportLink{{Name: "explain-function.json", Href: "transcripts/explain-function.json"}},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, page, "<strong>67.0% passing</strong>")
+	assert.Contains(t, page, `<a href="gpt-4o.json">gpt-4o.json</a>`)
+	assert.Contains(t, page, `<a href="summary.json">`)
+	assert.Contains(t, page, `<a href="transcripts/explain-function.json">`)
+	assert.Contains(t, page, "explain-function")
+	// Self-contained: no external stylesheets or scripts.
+	assert.NotContains(t, page, "<link")
+	assert.NotContains(t, page, "<script")
+}
+
+func TestRenderHTMLIndex_EscapesNames(t *testing.T) {
+	outcome := newTestOutcome()
+	outcome.TestOutcomes[0].DisplayName = "<b>bold</b>"
+	page, err := RenderHTMLIndex(ReportIndex{Entries: []ReportEntry{{Label: "m", Outcome: outcome}}})
+	require.NoError(t, err)
+	assert.Contains(t, page, "&lt;b&gt;bold&lt;/b&gt;")
+}
+
+func TestWriteHTMLIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.html")
+	require.NoError(t, WriteHTMLIndex(path, ReportIndex{}))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "<!DOCTYPE html>")
+}
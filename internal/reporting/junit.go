@@ -81,10 +81,11 @@ func ConvertToJUnit(outcome *models.EvaluationOutcome) *JUnitTestSuites {
 		Tests:     outcome.Digest.TotalTests,
 		Failures:  outcome.Digest.Failed,
 		Errors:    outcome.Digest.Errors,
-		Skipped:   outcome.Digest.Skipped,
+		Skipped:   outcome.Digest.Skipped + outcome.Digest.XFailed,
 		Time:      durationSec,
 		Timestamp: outcome.Timestamp.Format(time.RFC3339),
 		Properties: []JUnitProperty{
+			{Name: "run_id", Value: outcome.RunID},
 			{Name: "skill", Value: outcome.SkillTested},
 			{Name: "model", Value: outcome.Setup.ModelID},
 			{Name: "engine", Value: outcome.Setup.EngineType},
@@ -125,6 +126,16 @@ func convertTestOutcome(skill string, to *models.TestOutcome) JUnitTestCase {
 		Time:      durationSec,
 	}
 
+	if to.XFailed() {
+		// Expected failures don't fail the build, so report them as skipped.
+		msg := "xfail"
+		if to.XFailReason != "" {
+			msg += ": " + to.XFailReason
+		}
+		tc.Skipped = &JUnitSkipped{Message: msg}
+		return tc
+	}
+
 	switch to.Status {
 	case models.StatusFailed:
 		tc.Failure = buildFailure(to)
@@ -176,6 +176,31 @@ func TestConvertToJUnit_SkippedTestCase(t *testing.T) {
 	assert.Equal(t, 1, suites.TestSuites[0].Skipped)
 }
 
+func TestConvertToJUnit_XFailTestCase(t *testing.T) {
+	outcome := &models.EvaluationOutcome{
+		BenchName: "xfail-test",
+		Timestamp: time.Now(),
+		Digest:    models.OutcomeDigest{TotalTests: 1, XFailed: 1},
+		TestOutcomes: []models.TestOutcome{
+			{
+				DisplayName: "known-bad",
+				Status:      models.StatusFailed,
+				XFail:       true,
+				XFailReason: "issue #42",
+			},
+		},
+	}
+
+	suites := ConvertToJUnit(outcome)
+	tc := suites.TestSuites[0].TestCases[0]
+
+	assert.Nil(t, tc.Failure)
+	require.NotNil(t, tc.Skipped)
+	assert.Equal(t, "xfail: issue #42", tc.Skipped.Message)
+	assert.Equal(t, 0, suites.Failures)
+	assert.Equal(t, 1, suites.TestSuites[0].Skipped)
+}
+
 func TestConvertToJUnit_Properties(t *testing.T) {
 	outcome := newTestOutcome()
 	suites := ConvertToJUnit(outcome)
@@ -186,6 +211,7 @@ func TestConvertToJUnit_Properties(t *testing.T) {
 		propMap[p.Name] = p.Value
 	}
 
+	assert.Equal(t, "run-1", propMap["run_id"])
 	assert.Equal(t, "code-explainer", propMap["skill"])
 	assert.Equal(t, "gpt-4o", propMap["model"])
 	assert.Equal(t, "mock", propMap["engine"])
@@ -0,0 +1,113 @@
+package reporting
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/microsoft/waza/internal/models"
+)
+
+// promMetric is one metric family in the Prometheus text exposition format.
+type promMetric struct {
+	name  string
+	help  string
+	kind  string
+	value func(o *models.EvaluationOutcome) float64
+}
+
+var promMetrics = []promMetric{
+	{"waza_pass_rate", "Fraction of tasks that passed (0-1).", "gauge",
+		func(o *models.EvaluationOutcome) float64 { return o.Digest.SuccessRate }},
+	{"waza_aggregate_score", "Mean task score (0-1).", "gauge",
+		func(o *models.EvaluationOutcome) float64 { return o.Digest.AggregateScore }},
+	{"waza_duration_ms", "Wall-clock duration of the run in milliseconds.", "gauge",
+		func(o *models.EvaluationOutcome) float64 { return float64(o.Digest.DurationMs) }},
+}
+
+// promStatuses maps the status label of waza_tasks_total to its digest count.
+var promStatuses = []struct {
+	status models.Status
+	count  func(d models.OutcomeDigest) int
+}{
+	{models.StatusPassed, func(d models.OutcomeDigest) int { return d.Succeeded }},
+	{models.StatusFailed, func(d models.OutcomeDigest) int { return d.Failed }},
+	{models.StatusError, func(d models.OutcomeDigest) int { return d.Errors }},
+	{models.StatusSkipped, func(d models.OutcomeDigest) int { return d.Skipped }},
+}
+
+// MetricsRun is one outcome for RenderPrometheus. Engine tells outcomes of
+// the same engine type apart, e.g. "mock-2" for a repeated --engine; empty
+// uses the outcome's engine type.
+type MetricsRun struct {
+	Outcome *models.EvaluationOutcome
+	Engine  string
+}
+
+// RenderPrometheus renders the run summaries of runs in the Prometheus text
+// exposition format, for node_exporter's textfile collector. Every sample is
+// labeled with the skill, eval, model, engine and env_matrix environment, so
+// the outcomes of a multi-model, multi-engine or env_matrix run can share one
+// file without duplicate series.
+func RenderPrometheus(runs ...MetricsRun) string {
+	var b strings.Builder
+	for _, m := range promMetrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+		for _, run := range runs {
+			fmt.Fprintf(&b, "%s{%s} %s\n", m.name, promLabels(run), promValue(m.value(run.Outcome)))
+		}
+	}
+	b.WriteString("# HELP waza_tasks_total Tasks by final status.\n# TYPE waza_tasks_total counter\n")
+	for _, run := range runs {
+		for _, s := range promStatuses {
+			fmt.Fprintf(&b, "waza_tasks_total{%s,status=%q} %d\n", promLabels(run), s.status, s.count(run.Outcome.Digest))
+		}
+	}
+	return b.String()
+}
+
+// WritePrometheus writes the metrics from RenderPrometheus to path. The file
+// is written next to path and renamed into place, so the textfile collector
+// never reads a partial file.
+func WritePrometheus(path string, runs ...MetricsRun) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+
+	if _, err := tmp.WriteString(RenderPrometheus(runs...)); err != nil {
+		tmp.Close() //nolint:errcheck
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func promLabels(run MetricsRun) string {
+	o := run.Outcome
+	engine := run.Engine
+	if engine == "" {
+		engine = o.Setup.EngineType
+	}
+	return fmt.Sprintf(`skill="%s",eval="%s",model="%s",engine="%s",environment="%s"`,
+		promEscape(o.SkillTested), promEscape(o.BenchName), promEscape(o.Setup.ModelID),
+		promEscape(engine), promEscape(o.Setup.Environment))
+}
+
+// promEscape escapes a label value: backslash, double quote and newline are
+// the only characters the format requires escaping.
+func promEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func promValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,159 @@
+package reporting
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/microsoft/waza/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	promCommentRe = regexp.MustCompile(`^# (HELP|TYPE) ([a-zA-Z_:][a-zA-Z0-9_:]*) (.+)$`)
+	promSampleRe  = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)\{(.*)\} (\S+)$`)
+	promLabelRe   = regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_]*)="((?:[^"\\]|\\.)*)"(?:,|$)`)
+)
+
+type promSample struct {
+	labels map[string]string
+	value  float64
+}
+
+// parsePrometheus parses text in the exposition format, failing the test on
+// any line the format doesn't allow or any sample without a preceding TYPE.
+// It returns the samples and declared type of each metric family.
+func parsePrometheus(t *testing.T, text string) (map[string][]promSample, map[string]string) {
+	t.Helper()
+	samples := map[string][]promSample{}
+	types := map[string]string{}
+	unescape := strings.NewReplacer(`\\`, `\`, `\"`, `"`, `\n`, "\n")
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := promCommentRe.FindStringSubmatch(line); m != nil {
+			if m[1] == "TYPE" {
+				require.Contains(t, []string{"counter", "gauge"}, m[3], line)
+				types[m[2]] = m[3]
+			}
+			continue
+		}
+		m := promSampleRe.FindStringSubmatch(line)
+		require.NotNil(t, m, "not a valid sample line: %q", line)
+		require.Contains(t, types, m[1], "sample before its TYPE: %q", line)
+
+		labels := map[string]string{}
+		matched := promLabelRe.FindAllStringSubmatch(m[2], -1)
+		consumed := 0
+		for _, l := range matched {
+			labels[l[1]] = unescape.Replace(l[2])
+			consumed += len(l[0])
+		}
+		require.Equal(t, len(m[2]), consumed, "malformed labels: %q", line)
+
+		value, err := strconv.ParseFloat(m[3], 64)
+		require.NoError(t, err, line)
+		samples[m[1]] = append(samples[m[1]], promSample{labels: labels, value: value})
+	}
+	return samples, types
+}
+
+func TestRenderPrometheus(t *testing.T) {
+	outcome := &models.EvaluationOutcome{
+		SkillTested: "code-explainer",
+		BenchName:   `explain "tricky" code`,
+		Setup:       models.OutcomeSetup{ModelID: "gpt-4o", EngineType: "copilot-sdk"},
+		Digest: models.OutcomeDigest{
+			TotalTests:     10,
+			Succeeded:      7,
+			Failed:         2,
+			Errors:         1,
+			SuccessRate:    0.7,
+			AggregateScore: 0.825,
+			DurationMs:     12345,
+		},
+	}
+	other := &models.EvaluationOutcome{
+		SkillTested: "code-explainer",
+		BenchName:   outcome.BenchName,
+		Setup:       models.OutcomeSetup{ModelID: "claude-sonnet-4", EngineType: "copilot-sdk"},
+		Digest:      models.OutcomeDigest{TotalTests: 10, Succeeded: 10, SuccessRate: 1, AggregateScore: 1},
+	}
+
+	samples, types := parsePrometheus(t, RenderPrometheus(MetricsRun{Outcome: outcome}, MetricsRun{Outcome: other}))
+	assert.Equal(t, map[string]string{
+		"waza_pass_rate":       "gauge",
+		"waza_aggregate_score": "gauge",
+		"waza_duration_ms":     "gauge",
+		"waza_tasks_total":     "counter",
+	}, types)
+
+	want := map[string]string{
+		"skill": "code-explainer", "eval": `explain "tricky" code`, "model": "gpt-4o",
+		"engine": "copilot-sdk", "environment": "",
+	}
+	require.Len(t, samples["waza_pass_rate"], 2, "one sample per model")
+	assert.Equal(t, promSample{labels: want, value: 0.7}, samples["waza_pass_rate"][0])
+	assert.Equal(t, promSample{labels: want, value: 0.825}, samples["waza_aggregate_score"][0])
+	assert.Equal(t, promSample{labels: want, value: 12345}, samples["waza_duration_ms"][0])
+	assert.Equal(t, "claude-sonnet-4", samples["waza_pass_rate"][1].labels["model"])
+	assert.Equal(t, 1.0, samples["waza_pass_rate"][1].value)
+
+	counts := map[string]float64{}
+	for _, s := range samples["waza_tasks_total"] {
+		if s.labels["model"] == "gpt-4o" {
+			counts[s.labels["status"]] = s.value
+		}
+	}
+	assert.Equal(t, map[string]float64{"passed": 7, "failed": 2, "error": 1, "skipped": 0}, counts)
+}
+
+func TestWritePrometheus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "waza.prom")
+	outcome := &models.EvaluationOutcome{SkillTested: "s", Setup: models.OutcomeSetup{ModelID: "m"}, Digest: models.OutcomeDigest{SuccessRate: 0.5}}
+	require.NoError(t, WritePrometheus(path, MetricsRun{Outcome: outcome}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `waza_pass_rate{skill="s",eval="",model="m",engine="",environment=""} 0.5`+"\n")
+	samples, _ := parsePrometheus(t, string(data))
+	assert.Len(t, samples["waza_tasks_total"], 4)
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the temp file is renamed into place")
+}
+
+func TestRenderPrometheus_DistinctSeries(t *testing.T) {
+	outcome := func(env string) *models.EvaluationOutcome {
+		return &models.EvaluationOutcome{
+			SkillTested: "s",
+			Setup:       models.OutcomeSetup{ModelID: "m", EngineType: "mock", Environment: env},
+		}
+	}
+	// A repeated --engine and an env_matrix run share skill, eval and model
+	samples, _ := parsePrometheus(t, RenderPrometheus(
+		MetricsRun{Outcome: outcome(""), Engine: "mock"},
+		MetricsRun{Outcome: outcome(""), Engine: "mock-2"},
+		MetricsRun{Outcome: outcome("linux"), Engine: "mock"},
+	))
+
+	seen := map[string]bool{}
+	for _, s := range samples["waza_tasks_total"] {
+		key := s.labels["engine"] + "|" + s.labels["environment"] + "|" + s.labels["status"]
+		assert.False(t, seen[key], "duplicate series %q", key)
+		seen[key] = true
+	}
+	assert.Len(t, seen, 12)
+	assert.Equal(t, "mock-2", samples["waza_pass_rate"][1].labels["engine"])
+	assert.Equal(t, "linux", samples["waza_pass_rate"][2].labels["environment"])
+}
+
+func TestPromEscape(t *testing.T) {
+	assert.Equal(t, `a\\b\"c\nd`, promEscape("a\\b\"c\nd"))
+}
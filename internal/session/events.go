@@ -12,6 +12,9 @@ const (
 	EventTaskComplete EventType = "task_complete"
 	EventGraderResult EventType = "grader_result"
 	EventError        EventType = "error"
+	// EventTaskTranscript carries a task's output and transcript, so the
+	// session can be re-graded without the engine (see [Transcripts]).
+	EventTaskTranscript EventType = "task_transcript"
 )
 
 // Event is a single timestamped entry in a session log.
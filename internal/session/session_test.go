@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/microsoft/waza/internal/models"
 )
 
 func TestNewEvent(t *testing.T) {
@@ -290,3 +292,72 @@ func TestRenderTimelineEmpty(t *testing.T) {
 		t.Error("empty events should print 'No events found.'")
 	}
 }
+
+func TestTranscripts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "replay-session.jsonl")
+	logger, err := NewJSONLogger(path)
+	if err != nil {
+		t.Fatalf("NewJSONLogger: %v", err)
+	}
+	first := &models.TaskTranscript{TaskID: "t1", TaskName: "first", FinalOutput: "draft"}
+	retried := &models.TaskTranscript{
+		TaskID:      "t1",
+		TaskName:    "first",
+		Status:      models.StatusPassed,
+		FinalOutput: "final answer",
+		Session:     models.SessionDigest{ToolCallCount: 1, ToolsUsed: []string{"bash"}, SessionID: "s-1"},
+	}
+	other := &models.TaskTranscript{TaskID: "t2", TaskName: "second", FinalOutput: "other", ErrorMsg: "timed out"}
+	for _, ev := range []Event{
+		NewEvent(EventSessionStart, SessionStartData("evals/eval.yaml", "gpt-4o", "mock", 2)),
+		NewEvent(EventTaskTranscript, TaskTranscriptData(first)),
+		NewEvent(EventTaskComplete, TaskCompleteData("first", "passed", 1.0, 500)),
+		NewEvent(EventTaskTranscript, TaskTranscriptData(retried)),
+		NewEvent(EventTaskTranscript, TaskTranscriptData(other)),
+	} {
+		if err := logger.Log(ev); err != nil {
+			t.Fatalf("Log: %v", err)
+		}
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	events, err := ReadEvents(path)
+	if err != nil {
+		t.Fatalf("ReadEvents: %v", err)
+	}
+	if got := SpecPath(events); got != "evals/eval.yaml" {
+		t.Errorf("SpecPath = %q, want evals/eval.yaml", got)
+	}
+	transcripts, err := Transcripts(events)
+	if err != nil {
+		t.Fatalf("Transcripts: %v", err)
+	}
+	if len(transcripts) != 2 {
+		t.Fatalf("got %d transcripts, want 2", len(transcripts))
+	}
+	got := transcripts["t1"]
+	if got.FinalOutput != "final answer" || got.Status != models.StatusPassed {
+		t.Errorf("t1 = %+v, want the last recorded transcript", got)
+	}
+	if got.Session.SessionID != "s-1" || len(got.Session.ToolsUsed) != 1 || got.Session.ToolsUsed[0] != "bash" {
+		t.Errorf("t1 session = %+v", got.Session)
+	}
+	if transcripts["t2"].ErrorMsg != "timed out" {
+		t.Errorf("t2 error = %q, want timed out", transcripts["t2"].ErrorMsg)
+	}
+}
+
+func TestTranscriptsErrors(t *testing.T) {
+	if _, err := Transcripts([]Event{NewEvent(EventSessionStart, SessionStartData("eval.yaml", "m", "mock", 1))}); err == nil {
+		t.Error("expected error for a session without transcripts")
+	}
+	noID := NewEvent(EventTaskTranscript, map[string]any{"transcript": map[string]any{"task_name": "x"}})
+	if _, err := Transcripts([]Event{noID}); err == nil {
+		t.Error("expected error for a transcript without task_id")
+	}
+	if got := SpecPath(nil); got != "" {
+		t.Errorf("SpecPath(nil) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,58 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/microsoft/waza/internal/models"
+)
+
+// TaskTranscriptData returns event data for a task's recorded transcript.
+func TaskTranscriptData(t *models.TaskTranscript) map[string]any {
+	return map[string]any{
+		"task_id":    t.TaskID,
+		"task_name":  t.TaskName,
+		"transcript": t,
+	}
+}
+
+// Transcripts rebuilds the task transcripts recorded in a session's
+// task_transcript events, keyed by task ID, in the form replay expects. A
+// task recorded more than once keeps its last transcript.
+func Transcripts(events []Event) (map[string]*models.TaskTranscript, error) {
+	byTask := make(map[string]*models.TaskTranscript)
+	for i, ev := range events {
+		if ev.Type != EventTaskTranscript {
+			continue
+		}
+		// Data was decoded into generic maps; round-trip it into the typed transcript
+		raw, err := json.Marshal(ev.Data["transcript"])
+		if err != nil {
+			return nil, fmt.Errorf("event %d: encoding transcript: %w", i+1, err)
+		}
+		var t models.TaskTranscript
+		if err := json.Unmarshal(raw, &t); err != nil {
+			return nil, fmt.Errorf("event %d: decoding transcript: %w", i+1, err)
+		}
+		if t.TaskID == "" {
+			return nil, fmt.Errorf("event %d: transcript has no task_id", i+1)
+		}
+		byTask[t.TaskID] = &t
+	}
+	if len(byTask) == 0 {
+		return nil, fmt.Errorf("session log has no %s events to replay", EventTaskTranscript)
+	}
+	return byTask, nil
+}
+
+// SpecPath returns the eval spec path recorded by the session's first
+// session_start event, or "" if there is none.
+func SpecPath(events []Event) string {
+	for _, ev := range events {
+		if ev.Type == EventSessionStart {
+			path, _ := ev.Data["spec_path"].(string) //nolint:errcheck
+			return path
+		}
+	}
+	return ""
+}
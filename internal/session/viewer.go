@@ -83,8 +83,8 @@ func ReadEvents(path string) ([]Event, error) {
 
 	var events []Event
 	scanner := bufio.NewScanner(f)
-	// Increase buffer for large lines.
-	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	// Increase buffer for large lines; task_transcript events hold whole transcripts.
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
 	for scanner.Scan() {
 		var ev Event
 		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
@@ -150,6 +150,10 @@ func RenderTimeline(w io.Writer, events []Event) {
 			}
 			fmt.Fprintf(w, "[%s] %s  Task complete: %s [%s] (%dms)\n", ts, icon, name, status, dur)
 
+		case EventTaskTranscript:
+			name, _ := ev.Data["task_name"].(string) //nolint:errcheck
+			fmt.Fprintf(w, "[%s]    📝 Transcript recorded: %s\n", ts, name)
+
 		case EventError:
 			msg, _ := ev.Data["message"].(string) //nolint:errcheck
 			fmt.Fprintf(w, "[%s] ❌ Error: %s\n", ts, msg)
@@ -0,0 +1,308 @@
+// Package skillsource fetches a packaged skill, either a registry reference
+// (skill://name@version) or a tarball, into a temporary directory so a
+// released artifact can be evaluated without checking out its source.
+package skillsource
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/microsoft/waza/internal/skill"
+)
+
+// RegistryEnvVar names the environment variable holding the base URL that
+// skill:// references resolve against: skill://name@version is fetched from
+// <base>/<name>/<version>.tar.gz.
+const RegistryEnvVar = "WAZA_SKILL_REGISTRY"
+
+// Limits bound what Fetch will download and extract.
+type Limits struct {
+	// MaxArchiveBytes caps the size of the (compressed) archive.
+	MaxArchiveBytes int64
+	// MaxExtractedBytes caps the total size of extracted files.
+	MaxExtractedBytes int64
+	// MaxFiles caps the number of extracted files.
+	MaxFiles int
+}
+
+// DefaultLimits are generous for a skill (markdown, scripts, a few assets)
+// while stopping a runaway or hostile archive.
+var DefaultLimits = Limits{
+	MaxArchiveBytes:   50 << 20,
+	MaxExtractedBytes: 200 << 20,
+	MaxFiles:          10000,
+}
+
+// Skill is a fetched skill. Close removes its temporary directory.
+type Skill struct {
+	// Ref is the reference it was fetched from.
+	Ref string
+	// Dir is the directory containing the skill's SKILL.md.
+	Dir string
+	// Name is the name from SKILL.md's frontmatter.
+	Name string
+
+	root string
+}
+
+// Close removes the directory the skill was extracted to.
+func (s *Skill) Close() error {
+	if s == nil || s.root == "" {
+		return nil
+	}
+	return os.RemoveAll(s.root)
+}
+
+// Fetch downloads or opens ref, extracts it into a new temporary directory
+// and locates its SKILL.md. ref is a skill://name@version reference, an
+// http(s) URL of a tarball, or a local .tar.gz/.tgz/.tar path (optionally
+// file://). Archives may be gzipped or plain tar.
+func Fetch(ctx context.Context, ref string, limits Limits) (*Skill, error) {
+	location, wantName, err := resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, err := open(ctx, location, limits)
+	if err != nil {
+		return nil, fmt.Errorf("fetching skill %s: %w", ref, err)
+	}
+	defer rc.Close() //nolint:errcheck
+
+	root, err := os.MkdirTemp("", "waza-skill-*")
+	if err != nil {
+		return nil, err
+	}
+	s := &Skill{Ref: ref, root: root}
+	if err := extract(rc, root, limits); err != nil {
+		s.Close() //nolint:errcheck
+		return nil, fmt.Errorf("extracting skill %s: %w", ref, err)
+	}
+
+	if s.Dir, s.Name, err = findSkill(root); err != nil {
+		s.Close() //nolint:errcheck
+		return nil, fmt.Errorf("skill %s: %w", ref, err)
+	}
+	if wantName != "" && s.Name != wantName {
+		s.Close() //nolint:errcheck
+		return nil, fmt.Errorf("skill %s: SKILL.md is named %q, not %q", ref, s.Name, wantName)
+	}
+	return s, nil
+}
+
+// resolve turns ref into a URL or file path, plus the skill name a skill://
+// reference promises.
+func resolve(ref string) (location, name string, err error) {
+	rest, ok := strings.CutPrefix(ref, "skill://")
+	if !ok {
+		return strings.TrimPrefix(ref, "file://"), "", nil
+	}
+
+	name, version, _ := strings.Cut(rest, "@")
+	if name == "" || version == "" {
+		return "", "", fmt.Errorf("skill reference %q must be skill://name@version", ref)
+	}
+	if strings.ContainsAny(name, `/\`) || strings.ContainsAny(version, `/\`) || name == ".." || version == ".." {
+		return "", "", fmt.Errorf("skill reference %q: name and version can't contain path separators", ref)
+	}
+	base := os.Getenv(RegistryEnvVar)
+	if base == "" {
+		return "", "", fmt.Errorf("skill reference %q needs %s set to the registry's base URL", ref, RegistryEnvVar)
+	}
+	return strings.TrimSuffix(base, "/") + "/" + url.PathEscape(name) + "/" + url.PathEscape(version) + ".tar.gz", name, nil
+}
+
+// open returns the archive's bytes, failing once more than
+// limits.MaxArchiveBytes have been read.
+func open(ctx context.Context, location string, limits Limits) (io.ReadCloser, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		f, err := os.Open(location)
+		if err != nil {
+			return nil, err
+		}
+		return &cappedReader{ReadCloser: f, remaining: limits.MaxArchiveBytes}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close() //nolint:errcheck
+		return nil, fmt.Errorf("GET %s: %s", location, resp.Status)
+	}
+	if resp.ContentLength > limits.MaxArchiveBytes {
+		resp.Body.Close() //nolint:errcheck
+		return nil, fmt.Errorf("archive is %d bytes, over the %d byte limit", resp.ContentLength, limits.MaxArchiveBytes)
+	}
+	return &cappedReader{ReadCloser: resp.Body, remaining: limits.MaxArchiveBytes}, nil
+}
+
+type cappedReader struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (c *cappedReader) Read(p []byte) (int, error) {
+	if c.remaining <= 0 {
+		// Probe for one more byte to tell "exactly at the limit" from "over it"
+		var one [1]byte
+		if n, _ := c.ReadCloser.Read(one[:]); n == 0 {
+			return 0, io.EOF
+		}
+		return 0, errors.New("archive is over the size limit")
+	}
+	if int64(len(p)) > c.remaining {
+		p = p[:c.remaining]
+	}
+	n, err := c.ReadCloser.Read(p)
+	c.remaining -= int64(n)
+	return n, err
+}
+
+// extract unpacks a gzipped or plain tar stream into root. Only regular files
+// and directories are written; links and special files are skipped. Entries
+// that would land outside root are rejected.
+func extract(r io.Reader, root string, limits Limits) error {
+	br := bufio.NewReader(r)
+	var src io.Reader = br
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer gz.Close() //nolint:errcheck
+		src = gz
+	}
+
+	tr := tar.NewReader(src)
+	var files int
+	var total int64
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		if name == "." {
+			continue
+		}
+		if path.IsAbs(hdr.Name) || name == ".." || strings.HasPrefix(name, "../") || strings.Contains(name, `\`) {
+			return fmt.Errorf("entry %q escapes the extraction directory", hdr.Name)
+		}
+		target := filepath.Join(root, filepath.FromSlash(name))
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			files++
+			if files > limits.MaxFiles {
+				return fmt.Errorf("archive has more than %d files", limits.MaxFiles)
+			}
+			if total+hdr.Size > limits.MaxExtractedBytes {
+				return fmt.Errorf("archive extracts to more than %d bytes", limits.MaxExtractedBytes)
+			}
+			if err := writeFile(target, tr, hdr); err != nil {
+				return err
+			}
+			total += hdr.Size
+		default:
+			// Symlinks, hard links and device files could point outside root
+			continue
+		}
+	}
+	return nil
+}
+
+func writeFile(target string, r io.Reader, hdr *tar.Header) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	// Keep the executable bit for skill scripts; drop everything else
+	mode := fs.FileMode(0o644)
+	if hdr.FileInfo().Mode()&0o111 != 0 {
+		mode = 0o755
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	// Copy no more than the header declares, so Size is what limits count
+	if _, err := io.CopyN(f, r, hdr.Size); err != nil {
+		f.Close() //nolint:errcheck
+		return fmt.Errorf("writing %s: %w", hdr.Name, err)
+	}
+	return f.Close()
+}
+
+// findSkill returns the directory of the shallowest SKILL.md under root and
+// the skill's name. Tarballs often wrap everything in one top-level
+// directory, so the SKILL.md needn't be at the root.
+func findSkill(root string) (dir, name string, err error) {
+	var found []string
+	depth := -1
+	walkErr := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != "SKILL.md" {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p) //nolint:errcheck
+		n := strings.Count(filepath.ToSlash(rel), "/")
+		switch {
+		case depth < 0 || n < depth:
+			depth, found = n, []string{p}
+		case n == depth:
+			found = append(found, p)
+		}
+		return nil
+	})
+	if walkErr != nil {
+		return "", "", walkErr
+	}
+	switch len(found) {
+	case 0:
+		return "", "", errors.New("no SKILL.md in the archive")
+	case 1:
+	default:
+		return "", "", fmt.Errorf("archive has %d SKILL.md files at the same depth; expected one skill", len(found))
+	}
+
+	data, err := os.ReadFile(found[0])
+	if err != nil {
+		return "", "", err
+	}
+	var s skill.Skill
+	if err := s.UnmarshalText(data); err != nil {
+		return "", "", fmt.Errorf("parsing SKILL.md: %w", err)
+	}
+	name = strings.TrimSpace(s.Frontmatter.Name)
+	if name == "" {
+		return "", "", errors.New("SKILL.md has no name in its frontmatter")
+	}
+	return filepath.Dir(found[0]), name, nil
+}
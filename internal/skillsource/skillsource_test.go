@@ -0,0 +1,167 @@
+package skillsource
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const skillMD = "---\nname: packaged-skill\ndescription: A packaged skill\n---\n\n# Packaged\n"
+
+type entry struct {
+	name     string
+	body     string
+	typeflag byte
+	linkname string
+}
+
+func buildTar(t *testing.T, gzipped bool, entries ...entry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var tw *tar.Writer
+	var gz *gzip.Writer
+	if gzipped {
+		gz = gzip.NewWriter(&buf)
+		tw = tar.NewWriter(gz)
+	} else {
+		tw = tar.NewWriter(&buf)
+	}
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.body)), Typeflag: e.typeflag, Linkname: e.linkname}
+		if e.typeflag == 0 {
+			hdr.Typeflag = tar.TypeReg
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			hdr.Size = 0
+		}
+		require.NoError(t, tw.WriteHeader(hdr))
+		if hdr.Typeflag == tar.TypeReg {
+			_, err := tw.Write([]byte(e.body))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+	if gz != nil {
+		require.NoError(t, gz.Close())
+	}
+	return buf.Bytes()
+}
+
+func writeArchive(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(p, data, 0o644))
+	return p
+}
+
+func TestFetch_LocalTarball(t *testing.T) {
+	archive := writeArchive(t, "skill.tgz", buildTar(t, true,
+		entry{name: "packaged-skill-1.0.0/", typeflag: tar.TypeDir},
+		entry{name: "packaged-skill-1.0.0/SKILL.md", body: skillMD},
+		entry{name: "packaged-skill-1.0.0/references/guide.md", body: "guide"},
+		entry{name: "packaged-skill-1.0.0/escape", typeflag: tar.TypeSymlink, linkname: "/etc/passwd"},
+	))
+
+	s, err := Fetch(context.Background(), archive, DefaultLimits)
+	require.NoError(t, err)
+	assert.Equal(t, "packaged-skill", s.Name)
+	assert.Equal(t, "packaged-skill-1.0.0", filepath.Base(s.Dir))
+	assert.FileExists(t, filepath.Join(s.Dir, "references", "guide.md"))
+	_, err = os.Lstat(filepath.Join(s.Dir, "escape"))
+	assert.True(t, os.IsNotExist(err), "symlinks are skipped")
+
+	require.NoError(t, s.Close())
+	assert.NoDirExists(t, s.Dir)
+}
+
+func TestFetch_PlainTarAndFileURL(t *testing.T) {
+	archive := writeArchive(t, "skill.tar", buildTar(t, false, entry{name: "SKILL.md", body: skillMD}))
+
+	s, err := Fetch(context.Background(), "file://"+archive, DefaultLimits)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() }) //nolint:errcheck
+	assert.FileExists(t, filepath.Join(s.Dir, "SKILL.md"))
+}
+
+func TestFetch_Registry(t *testing.T) {
+	archive := buildTar(t, true, entry{name: "SKILL.md", body: skillMD})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/packaged-skill/1.2.0.tar.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(archive) //nolint:errcheck
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv(RegistryEnvVar, srv.URL+"/")
+
+	s, err := Fetch(context.Background(), "skill://packaged-skill@1.2.0", DefaultLimits)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() }) //nolint:errcheck
+	assert.Equal(t, "packaged-skill", s.Name)
+
+	_, err = Fetch(context.Background(), "skill://packaged-skill@9.9.9", DefaultLimits)
+	require.ErrorContains(t, err, "404")
+
+	direct, err := Fetch(context.Background(), srv.URL+"/packaged-skill/1.2.0.tar.gz", DefaultLimits)
+	require.NoError(t, err, "a plain http(s) URL works too")
+	require.NoError(t, direct.Close())
+}
+
+func TestFetch_Errors(t *testing.T) {
+	tgz := func(entries ...entry) string {
+		return writeArchive(t, "skill.tgz", buildTar(t, true, entries...))
+	}
+
+	tests := []struct {
+		name   string
+		ref    string
+		limits Limits
+		want   string
+	}{
+		{"path traversal", tgz(entry{name: "../evil", body: "x"}), DefaultLimits, "escapes the extraction directory"},
+		{"absolute path", tgz(entry{name: "/tmp/evil", body: "x"}), DefaultLimits, "escapes the extraction directory"},
+		{"no SKILL.md", tgz(entry{name: "README.md", body: "x"}), DefaultLimits, "no SKILL.md"},
+		{"two skills", tgz(entry{name: "a/SKILL.md", body: skillMD}, entry{name: "b/SKILL.md", body: skillMD}), DefaultLimits, "2 SKILL.md files"},
+		{"too many files", tgz(entry{name: "SKILL.md", body: skillMD}, entry{name: "x", body: "x"}),
+			Limits{MaxArchiveBytes: 1 << 20, MaxExtractedBytes: 1 << 20, MaxFiles: 1}, "more than 1 files"},
+		{"too many bytes", tgz(entry{name: "SKILL.md", body: skillMD}),
+			Limits{MaxArchiveBytes: 1 << 20, MaxExtractedBytes: 10, MaxFiles: 10}, "more than 10 bytes"},
+		{"archive too big", tgz(entry{name: "SKILL.md", body: skillMD}),
+			Limits{MaxArchiveBytes: 16, MaxExtractedBytes: 1 << 20, MaxFiles: 10}, "over the size limit"},
+		{"bad skill ref", "skill://packaged-skill", DefaultLimits, "must be skill://name@version"},
+		{"ref with path", "skill://../x@1", DefaultLimits, "path separators"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Fetch(context.Background(), tt.ref, tt.limits)
+			require.ErrorContains(t, err, tt.want)
+		})
+	}
+
+	t.Run("no registry", func(t *testing.T) {
+		t.Setenv(RegistryEnvVar, "")
+		_, err := Fetch(context.Background(), "skill://packaged-skill@1.0.0", DefaultLimits)
+		require.ErrorContains(t, err, RegistryEnvVar)
+	})
+
+	t.Run("name mismatch", func(t *testing.T) {
+		archive := buildTar(t, true, entry{name: "SKILL.md", body: skillMD})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write(archive) //nolint:errcheck
+		}))
+		t.Cleanup(srv.Close)
+		t.Setenv(RegistryEnvVar, srv.URL)
+		_, err := Fetch(context.Background(), "skill://other-skill@1.0.0", DefaultLimits)
+		require.ErrorContains(t, err, `named "packaged-skill", not "other-skill"`)
+	})
+}
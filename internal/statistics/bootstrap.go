@@ -79,6 +79,63 @@ func BootstrapCIWithSeed(scores []float64, confidenceLevel float64, seed int64)
 	}
 }
 
+// BootstrapDiffCIWithSeed computes a bootstrap confidence interval for
+// mean(after) - mean(before). Each group is resampled independently, so the
+// two need not be paired or the same size. Returns a degenerate interval at
+// the observed difference when either group has fewer than 2 data points.
+// A negative seed uses a non-deterministic source.
+func BootstrapDiffCIWithSeed(before, after []float64, confidenceLevel float64, seed int64) ConfidenceInterval {
+	diff := mean(after) - mean(before)
+	nb, na := len(before), len(after)
+	if nb < 2 || na < 2 {
+		return ConfidenceInterval{
+			Lower:           diff,
+			Upper:           diff,
+			Mean:            diff,
+			ConfidenceLevel: confidenceLevel,
+			NumBootstraps:   0,
+		}
+	}
+
+	var rng *rand.Rand
+	if seed >= 0 {
+		rng = rand.New(rand.NewSource(seed))
+	} else {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+
+	iters := DefaultBootstrapIterations
+	bootDiffs := make([]float64, iters)
+	sampleB := make([]float64, nb)
+	sampleA := make([]float64, na)
+	for i := 0; i < iters; i++ {
+		for j := 0; j < nb; j++ {
+			sampleB[j] = before[rng.Intn(nb)]
+		}
+		for j := 0; j < na; j++ {
+			sampleA[j] = after[rng.Intn(na)]
+		}
+		bootDiffs[i] = mean(sampleA) - mean(sampleB)
+	}
+
+	sort.Float64s(bootDiffs)
+
+	alpha := 1.0 - confidenceLevel
+	loIdx := int(math.Floor(alpha / 2.0 * float64(iters)))
+	hiIdx := int(math.Floor((1.0 - alpha/2.0) * float64(iters)))
+	if hiIdx >= iters {
+		hiIdx = iters - 1
+	}
+
+	return ConfidenceInterval{
+		Lower:           bootDiffs[loIdx],
+		Upper:           bootDiffs[hiIdx],
+		Mean:            diff,
+		ConfidenceLevel: confidenceLevel,
+		NumBootstraps:   iters,
+	}
+}
+
 // IsSignificant returns true if the confidence interval does not contain zero,
 // indicating statistical significance at the given confidence level.
 func IsSignificant(ci ConfidenceInterval) bool {
@@ -149,3 +149,36 @@ func TestBootstrapCI_DifferentConfidenceLevels(t *testing.T) {
 		t.Errorf("99%% CI should be wider than 90%%: 90%%=%f, 99%%=%f", width90, width99)
 	}
 }
+
+func TestBootstrapDiffCI_ClearShiftIsSignificant(t *testing.T) {
+	before := []float64{0.1, 0.15, 0.2, 0.1, 0.15}
+	after := []float64{0.9, 0.85, 0.95, 0.9, 0.85}
+	ci := BootstrapDiffCIWithSeed(before, after, 0.95, 42)
+
+	if math.Abs(ci.Mean-0.75) > 1e-9 {
+		t.Errorf("expected observed difference 0.75, got %f", ci.Mean)
+	}
+	if !IsSignificant(ci) {
+		t.Errorf("expected a clear shift to be significant, got [%f, %f]", ci.Lower, ci.Upper)
+	}
+}
+
+func TestBootstrapDiffCI_NoiseIsNotSignificant(t *testing.T) {
+	before := []float64{0.2, 0.9, 0.4, 0.7, 0.5}
+	after := []float64{0.3, 0.8, 0.6, 0.9, 0.4}
+	ci := BootstrapDiffCIWithSeed(before, after, 0.95, 42)
+
+	if IsSignificant(ci) {
+		t.Errorf("expected overlapping noisy scores not to be significant, got [%f, %f]", ci.Lower, ci.Upper)
+	}
+}
+
+func TestBootstrapDiffCI_TooFewSamples(t *testing.T) {
+	ci := BootstrapDiffCIWithSeed([]float64{0.2}, []float64{0.8, 0.9}, 0.95, 42)
+	if ci.NumBootstraps != 0 {
+		t.Errorf("expected no bootstraps with a single sample, got %d", ci.NumBootstraps)
+	}
+	if math.Abs(ci.Lower-0.65) > 1e-9 || math.Abs(ci.Upper-0.65) > 1e-9 {
+		t.Errorf("expected degenerate CI at 0.65, got [%f, %f]", ci.Lower, ci.Upper)
+	}
+}
@@ -104,7 +104,7 @@ func getCredentialWithAutoLogin(ctx context.Context) (azcore.TokenCredential, er
 
 // Upload persists an evaluation outcome to Azure Blob Storage.
 // Blob path: {skill-name}/{run-id}.json
-// Metadata: skill, model, passrate, timestamp, runid
+// Metadata: skill, model, passrate, timestamp, runid, runtags
 func (abs *AzureBlobStore) Upload(ctx context.Context, outcome *models.EvaluationOutcome) error {
 	if outcome.RunID == "" {
 		return fmt.Errorf("outcome has empty RunID")
@@ -131,6 +131,9 @@ func (abs *AzureBlobStore) Upload(ctx context.Context, outcome *models.Evaluatio
 		"timestamp": stringPtr(outcome.Timestamp.Format(time.RFC3339)),
 		"runid":     stringPtr(outcome.RunID),
 	}
+	if len(outcome.RunTags) > 0 {
+		metadata["runtags"] = stringPtr(strings.Join(outcome.RunTags, ","))
+	}
 
 	_, err = abs.client.UploadBuffer(ctx, abs.containerName, blobPath, data, &azblob.UploadBufferOptions{
 		Metadata: metadata,
@@ -183,6 +186,9 @@ func (abs *AzureBlobStore) List(ctx context.Context, opts ListOptions) ([]Result
 			if !opts.Since.IsZero() && summary.Timestamp.Before(opts.Since) {
 				continue
 			}
+			if !hasRunTags(summary.RunTags, opts.RunTags) {
+				continue
+			}
 
 			results = append(results, summary)
 		}
@@ -327,6 +333,10 @@ func (abs *AzureBlobStore) blobToResultSummary(blob *container.BlobItem) (Result
 	model := getMetadata(metadata, "model")
 	passRateStr := getMetadata(metadata, "passrate")
 	timestampStr := getMetadata(metadata, "timestamp")
+	var runTags []string
+	if s := getMetadata(metadata, "runtags"); s != "" {
+		runTags = strings.Split(s, ",")
+	}
 
 	if runID == "" || timestampStr == "" {
 		return ResultSummary{}, fmt.Errorf("missing required metadata")
@@ -354,6 +364,7 @@ func (abs *AzureBlobStore) blobToResultSummary(blob *container.BlobItem) (Result
 		Timestamp: timestamp,
 		PassRate:  passRate,
 		BlobPath:  blobPath,
+		RunTags:   runTags,
 	}, nil
 }
 
@@ -373,6 +384,7 @@ func (abs *AzureBlobStore) outcomeToResultSummary(o *models.EvaluationOutcome) R
 		Timestamp: o.Timestamp,
 		PassRate:  passRate,
 		BlobPath:  blobPath,
+		RunTags:   o.RunTags,
 	}
 }
 
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
 	"github.com/microsoft/waza/internal/models"
 )
 
@@ -206,6 +208,31 @@ func TestAzureBlobStore_List_MetadataFiltering(t *testing.T) {
 	}
 }
 
+func TestAzureBlobStore_BlobToResultSummary_RunTags(t *testing.T) {
+	abs := &AzureBlobStore{}
+	name := "skill-a/run-1.json"
+	blob := &container.BlobItem{
+		Name: &name,
+		Metadata: map[string]*string{
+			"runid":     stringPtr("run-1"),
+			"skill":     stringPtr("skill-a"),
+			"timestamp": stringPtr("2026-02-27T12:00:00Z"),
+			"runtags":   stringPtr("nightly,pr-1234"),
+		},
+	}
+
+	summary, err := abs.blobToResultSummary(blob)
+	if err != nil {
+		t.Fatalf("blobToResultSummary() error: %v", err)
+	}
+	if !slices.Equal(summary.RunTags, []string{"nightly", "pr-1234"}) {
+		t.Errorf("RunTags = %v, want [nightly pr-1234]", summary.RunTags)
+	}
+	if !hasRunTags(summary.RunTags, []string{"pr-1234"}) || hasRunTags(summary.RunTags, []string{"weekly"}) {
+		t.Error("hasRunTags didn't match the blob's run tags")
+	}
+}
+
 func TestAzureBlobStore_Compare_DeltaCalculation(t *testing.T) {
 	t.Skip("Skip until azure_blob.go is implemented")
 
@@ -221,6 +221,7 @@ func outcomeToResultSummary(o *models.EvaluationOutcome, dir string) ResultSumma
 		Timestamp: o.Timestamp,
 		PassRate:  passRate,
 		BlobPath:  filepath.Join(dir, sanitizeFilename(o.RunID)+".json"),
+		RunTags:   o.RunTags,
 	}
 }
 
@@ -235,6 +236,9 @@ func matchesFilter(o *models.EvaluationOutcome, opts ListOptions) bool {
 	if !opts.Since.IsZero() && o.Timestamp.Before(opts.Since) {
 		return false
 	}
+	if !hasRunTags(o.RunTags, opts.RunTags) {
+		return false
+	}
 	return true
 }
 
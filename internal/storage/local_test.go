@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"sort"
 	"testing"
 	"time"
 
@@ -521,6 +523,61 @@ func TestLocalStore_List_AllFiltersCombined(t *testing.T) {
 	}
 }
 
+func TestLocalStore_List_RunTags(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	nightly := makeOutcome("run-nightly", "skill-a", "gpt-4o", 8, 10)
+	nightly.RunTags = []string{"nightly"}
+	pr := makeOutcome("run-pr", "skill-a", "gpt-4o", 9, 10)
+	pr.RunTags = []string{"nightly", "pr-1234"}
+	untagged := makeOutcome("run-untagged", "skill-a", "gpt-4o", 10, 10)
+
+	store := NewLocalStore(dir)
+	for _, o := range []*models.EvaluationOutcome{nightly, pr, untagged} {
+		if err := store.Upload(ctx, o); err != nil {
+			t.Fatalf("Upload() error: %v", err)
+		}
+	}
+
+	// Read back from disk so the tags must have been persisted.
+	reloaded := NewLocalStore(dir)
+	runIDs := func(tags ...string) []string {
+		t.Helper()
+		results, err := reloaded.List(ctx, ListOptions{RunTags: tags})
+		if err != nil {
+			t.Fatalf("List(%v) error: %v", tags, err)
+		}
+		var ids []string
+		for _, r := range results {
+			ids = append(ids, r.RunID)
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	if got := runIDs("nightly"); !slices.Equal(got, []string{"run-nightly", "run-pr"}) {
+		t.Errorf("List(nightly) = %v, want [run-nightly run-pr]", got)
+	}
+	if got := runIDs("nightly", "pr-1234"); !slices.Equal(got, []string{"run-pr"}) {
+		t.Errorf("List(nightly, pr-1234) = %v, want [run-pr]", got)
+	}
+	if got := runIDs("weekly"); len(got) != 0 {
+		t.Errorf("List(weekly) = %v, want none", got)
+	}
+	if got := runIDs(); len(got) != 3 {
+		t.Errorf("List() = %v, want all 3 runs", got)
+	}
+
+	results, err := reloaded.List(ctx, ListOptions{RunTags: []string{"pr-1234"}})
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	if !slices.Equal(results[0].RunTags, []string{"nightly", "pr-1234"}) {
+		t.Errorf("RunTags = %v, want [nightly pr-1234]", results[0].RunTags)
+	}
+}
+
 func TestLocalStore_EmptyFields(t *testing.T) {
 	dir := t.TempDir()
 	store := NewLocalStore(dir)
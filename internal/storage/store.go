@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"math"
+	"slices"
 	"time"
 
 	"github.com/microsoft/waza/internal/models"
@@ -36,6 +37,8 @@ type ListOptions struct {
 	Model string
 	Since time.Time
 	Limit int
+	// RunTags keeps only runs tagged with every one of these run tags.
+	RunTags []string
 }
 
 // ResultSummary is a lightweight representation of a stored evaluation run,
@@ -47,6 +50,7 @@ type ResultSummary struct {
 	Timestamp time.Time `json:"timestamp"`
 	PassRate  float64   `json:"pass_rate"`
 	BlobPath  string    `json:"blob_path"`
+	RunTags   []string  `json:"run_tags,omitempty"`
 }
 
 // ComparisonReport holds the result of comparing two evaluation runs.
@@ -77,6 +81,16 @@ func NewStore(cfg *projectconfig.StorageConfig, localDir string) (ResultStore, e
 	return NewLocalStore(localDir), nil
 }
 
+// hasRunTags reports whether tags includes every tag in want.
+func hasRunTags(tags, want []string) bool {
+	for _, w := range want {
+		if !slices.Contains(tags, w) {
+			return false
+		}
+	}
+	return true
+}
+
 // buildMetricDeltas computes per-metric deltas between two outcomes.
 // Used by both LocalStore and AzureBlobStore in their Compare methods.
 func buildMetricDeltas(o1, o2 *models.EvaluationOutcome) map[string]MetricDelta {
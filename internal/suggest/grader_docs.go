@@ -8,18 +8,22 @@ import (
 
 // graderSummary maps grader type to a one-line description for the selection prompt.
 var graderSummary = map[string]string{
-	"code":             "Assertion-based: evaluate Python/JS expressions against execution context (output, tool_calls, etc.)",
-	"prompt":           "LLM-as-judge: use a language model to evaluate quality via a rubric prompt",
-	"text":             "Text matching: check output with substring contains/not_contains and regex_match/regex_not_match",
-	"file":             "File validation: verify file existence, absence, and content patterns in workspace",
-	"json_schema":      "JSON schema: validate that output is valid JSON conforming to a schema",
-	"program":          "External program: run a script/binary that grades via exit code (0=pass)",
-	"behavior":         "Behavior constraints: validate tool call counts, token usage, required/forbidden tools",
-	"action_sequence":  "Action sequence: validate tool call sequence matches expected pattern (exact/in_order/any_order)",
-	"skill_invocation": "Skill invocation: verify dependent skills were invoked in correct sequence",
-	"trigger":          "Trigger heuristic: score prompt-to-skill relevance and assert should-trigger or should-not-trigger behavior",
-	"tool_constraint":  "Tool constraints: validate tool usage patterns, turn/token limits",
-	"diff":             "File diff: compare workspace files against expected snapshots or line fragments",
+	"code":              "Assertion-based: evaluate Python/JS expressions against execution context (output, tool_calls, etc.)",
+	"prompt":            "LLM-as-judge: use a language model to evaluate quality via a rubric prompt",
+	"text":              "Text matching: check output with substring contains/not_contains and regex_match/regex_not_match",
+	"file":              "File validation: verify file existence, absence, and content patterns in workspace",
+	"json_schema":       "JSON schema: validate that output is valid JSON conforming to a schema",
+	"program":           "External program: run a script/binary that grades via exit code (0=pass)",
+	"behavior":          "Behavior constraints: validate tool call counts, token usage, required/forbidden tools",
+	"action_sequence":   "Action sequence: validate tool call sequence matches expected pattern (exact/in_order/any_order)",
+	"skill_invocation":  "Skill invocation: verify dependent skills were invoked in correct sequence",
+	"trigger":           "Trigger: assert should-trigger or should-not-trigger behavior, by prompt-to-skill heuristic or (source: invocation) the skills actually invoked",
+	"tool_constraint":   "Tool constraints: validate tool usage patterns, turn/token limits",
+	"diff":              "File diff: compare workspace files against expected snapshots or line fragments",
+	"no_secrets":        "Secret leaks: fail if output contains credentials (AWS keys, bearer tokens, private keys) or custom patterns",
+	"rubric":            "Rubric scoring: LLM judge scores each criterion (e.g. correctness, clarity) and the weighted total is the grade",
+	"efficiency":        "Efficiency: fail if the agent used more tool calls (max_tool_calls) or turns (max_turns) than allowed",
+	"corpus_similarity": "Corpus similarity: score output by n-gram or word-frequency overlap with a directory of approved reference outputs",
 }
 
 // GraderSummaries returns a formatted block of one-line grader descriptions
@@ -190,6 +190,10 @@ func AvailableGraderTypes() []string {
 		string(models.GraderKindSkillInvocation),
 		string(models.GraderKindTrigger),
 		string(models.GraderKindDiff),
+		string(models.GraderKindNoSecrets),
+		string(models.GraderKindRubric),
+		string(models.GraderKindEfficiency),
+		string(models.GraderKindCorpusSimilarity),
 	}
 }
 
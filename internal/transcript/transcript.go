@@ -1,12 +1,15 @@
 package transcript
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"text/template"
 	"time"
 
 	copilot "github.com/github/copilot-sdk/go"
@@ -26,30 +29,160 @@ func sanitizeName(name string) string {
 	return s
 }
 
-// Filename returns the transcript filename for a task.
-func Filename(taskName string, ts time.Time) string {
-	return fmt.Sprintf("%s-%s.json", sanitizeName(taskName), ts.Format("20060102-150405"))
+// DefaultNameTemplate names transcript files when no template is given.
+// Including the task ID keeps files distinct when several tasks share a
+// display name.
+const DefaultNameTemplate = "{{.TaskName}}-{{.TaskID}}-{{.Timestamp}}"
+
+// NameFields holds the values available to a transcript name template.
+// TaskName and TaskID are sanitized for use in filenames.
+type NameFields struct {
+	TaskID    string
+	TaskName  string
+	Status    string
+	Timestamp string
+}
+
+// ParseNameTemplate parses a transcript name template, falling back to
+// DefaultNameTemplate when tmpl is empty.
+func ParseNameTemplate(tmpl string) (*template.Template, error) {
+	if tmpl == "" {
+		tmpl = DefaultNameTemplate
+	}
+	t, err := template.New("transcript-name").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("parse transcript name template: %w", err)
+	}
+	// Render against sample values so unknown fields fail up front.
+	if _, err := render(t, NameFields{TaskID: "id", TaskName: "name", Status: "passed", Timestamp: "20060102-150405"}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Filename returns the transcript filename for t using the name template
+// tmpl (DefaultNameTemplate when empty). The ".json" extension is appended.
+func Filename(t *models.TaskTranscript, tmpl string) (string, error) {
+	parsed, err := ParseNameTemplate(tmpl)
+	if err != nil {
+		return "", err
+	}
+	fields := NameFields{
+		TaskName:  sanitizeName(t.TaskName),
+		Status:    string(t.Status),
+		Timestamp: t.StartedAt.Format("20060102-150405"),
+	}
+	if t.TaskID != "" {
+		fields.TaskID = sanitizeName(t.TaskID)
+	}
+	name, err := render(parsed, fields)
+	if err != nil {
+		return "", err
+	}
+	return name + ".json", nil
 }
 
-// Write serializes a TaskTranscript and writes it to dir.
+func render(t *template.Template, fields NameFields) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, fields); err != nil {
+		return "", fmt.Errorf("render transcript name template: %w", err)
+	}
+	// Collapse separators left behind by empty fields, e.g. a missing task ID.
+	name := strings.Trim(repeatedDashes.ReplaceAllString(buf.String(), "-"), "-")
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("transcript name template produced invalid filename %q", name)
+	}
+	return name, nil
+}
+
+var repeatedDashes = regexp.MustCompile(`-{2,}`)
+
+// Write serializes a TaskTranscript and writes it to dir using
+// DefaultNameTemplate.
 func Write(dir string, t *models.TaskTranscript) (string, error) {
+	return WriteNamed(dir, t, "")
+}
+
+// WriteNamed serializes a TaskTranscript and writes it to dir, naming the
+// file with the template tmpl. If the name is already taken, a numeric
+// suffix is added so an earlier transcript is never overwritten.
+func WriteNamed(dir string, t *models.TaskTranscript, tmpl string) (string, error) {
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return "", fmt.Errorf("create transcript dir: %w", err)
 	}
 
-	name := Filename(t.TaskName, t.StartedAt)
-	path := filepath.Join(dir, name)
+	name, err := Filename(t, tmpl)
+	if err != nil {
+		return "", err
+	}
 
 	data, err := json.MarshalIndent(t, "", "  ")
 	if err != nil {
 		return "", fmt.Errorf("marshal transcript: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, 0o644); err != nil {
-		return "", fmt.Errorf("write transcript: %w", err)
+	base := strings.TrimSuffix(name, ".json")
+	for i := 1; ; i++ {
+		path := filepath.Join(dir, name)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			name = fmt.Sprintf("%s-%d.json", base, i+1)
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("write transcript: %w", err)
+		}
+		_, err = f.Write(data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return "", fmt.Errorf("write transcript: %w", err)
+		}
+		return path, nil
 	}
+}
 
-	return path, nil
+// Load reads a transcript file written by Write.
+func Load(path string) (*models.TaskTranscript, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read transcript: %w", err)
+	}
+	var t models.TaskTranscript
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("parse transcript %s: %w", path, err)
+	}
+	return &t, nil
+}
+
+// LoadDir reads every transcript in dir, keyed by task ID. When a task has
+// more than one transcript (e.g. from repeated runs into the same directory),
+// the one that started most recently wins.
+func LoadDir(dir string) (map[string]*models.TaskTranscript, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("list transcripts: %w", err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no transcripts found in %s", dir)
+	}
+
+	byTask := make(map[string]*models.TaskTranscript, len(paths))
+	for _, path := range paths {
+		t, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		if t.TaskID == "" {
+			return nil, fmt.Errorf("transcript %s has no task_id", path)
+		}
+		if prev, ok := byTask[t.TaskID]; ok && prev.StartedAt.After(t.StartedAt) {
+			continue
+		}
+		byTask[t.TaskID] = t
+	}
+	return byTask, nil
 }
 
 // BuildFromSessionEvents converts a slice of Copilot session events
@@ -70,6 +203,7 @@ func BuildTaskTranscript(tc *models.TestCase, outcome models.TestOutcome, startT
 	var finalOutput string
 	var session models.SessionDigest
 	var errMsg string
+	var skillInvocations []models.SkillInvocation
 
 	for _, run := range outcome.Runs {
 		totalDurationMs += run.DurationMs
@@ -79,6 +213,7 @@ func BuildTaskTranscript(tc *models.TestCase, outcome models.TestOutcome, startT
 		}
 		finalOutput = run.FinalOutput
 		session = run.SessionDigest
+		skillInvocations = run.SkillInvocations
 		if run.ErrorMsg != "" {
 			errMsg = run.ErrorMsg
 		}
@@ -99,5 +234,7 @@ func BuildTaskTranscript(tc *models.TestCase, outcome models.TestOutcome, startT
 		Validations: allValidations,
 		Session:     session,
 		ErrorMsg:    errMsg,
+
+		SkillInvocations: skillInvocations,
 	}
 }
@@ -40,13 +40,54 @@ func TestSanitizeName(t *testing.T) {
 
 func TestFilename(t *testing.T) {
 	ts := time.Date(2025, 6, 15, 14, 30, 45, 0, time.UTC)
-	got := Filename("My Task", ts)
-	want := "my-task-20250615-143045.json"
-	if got != want {
-		t.Errorf("Filename() = %q, want %q", got, want)
+	tests := []struct {
+		name string
+		tr   *models.TaskTranscript
+		tmpl string
+		want string
+	}{
+		{"default", &models.TaskTranscript{TaskID: "Task_01", TaskName: "My Task", StartedAt: ts}, "", "my-task-task_01-20250615-143045.json"},
+		{"no task id", &models.TaskTranscript{TaskName: "My Task", StartedAt: ts}, "", "my-task-20250615-143045.json"},
+		{"custom", &models.TaskTranscript{TaskID: "t1", TaskName: "My Task", Status: models.StatusFailed, StartedAt: ts}, "{{.Status}}-{{.TaskID}}", "failed-t1.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Filename(tt.tr, tt.tmpl)
+			require.NoError(t, err)
+			if got != tt.want {
+				t.Errorf("Filename() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNameTemplate_Errors(t *testing.T) {
+	for _, tmpl := range []string{"{{.Model}}", "{{.TaskID", "sub/{{.TaskID}}"} {
+		if _, err := ParseNameTemplate(tmpl); err == nil {
+			t.Errorf("ParseNameTemplate(%q) succeeded, want error", tmpl)
+		}
 	}
 }
 
+func TestWriteNamed_DoesNotOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	ts := time.Date(2025, 6, 15, 14, 30, 45, 0, time.UTC)
+	first := &models.TaskTranscript{TaskID: "a", TaskName: "Same", StartedAt: ts}
+	second := &models.TaskTranscript{TaskID: "b", TaskName: "Same", StartedAt: ts}
+
+	p1, err := WriteNamed(dir, first, "{{.TaskName}}")
+	require.NoError(t, err)
+	p2, err := WriteNamed(dir, second, "{{.TaskName}}")
+	require.NoError(t, err)
+
+	require.Equal(t, filepath.Join(dir, "same.json"), p1)
+	require.Equal(t, filepath.Join(dir, "same-2.json"), p2)
+
+	got, err := Load(p1)
+	require.NoError(t, err)
+	require.Equal(t, "a", got.TaskID)
+}
+
 func TestWrite(t *testing.T) {
 	dir := t.TempDir()
 
@@ -213,3 +254,38 @@ func TestBuildTaskTranscript(t *testing.T) {
 		t.Errorf("FinalOutput = %q, want %q", result.FinalOutput, "Sure, this code...")
 	}
 }
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	older := time.Date(2025, 6, 15, 14, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	for _, tr := range []*models.TaskTranscript{
+		{TaskID: "task-a", TaskName: "Task A", StartedAt: newer, FinalOutput: "new"},
+		{TaskID: "task-a", TaskName: "Task A", StartedAt: older, FinalOutput: "old"},
+		{
+			TaskID: "task-b", TaskName: "Task B", StartedAt: older, FinalOutput: "b",
+			SkillInvocations: []models.SkillInvocation{{Name: "my-skill"}},
+		},
+	} {
+		_, err := Write(dir, tr)
+		require.NoError(t, err)
+	}
+
+	got, err := LoadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.Equal(t, "new", got["task-a"].FinalOutput, "most recent transcript wins")
+	require.Equal(t, "b", got["task-b"].FinalOutput)
+	require.Equal(t, []models.SkillInvocation{{Name: "my-skill"}}, got["task-b"].SkillInvocations)
+}
+
+func TestLoadDir_Errors(t *testing.T) {
+	_, err := LoadDir(t.TempDir())
+	require.ErrorContains(t, err, "no transcripts found")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.json"), []byte("{"), 0o644))
+	_, err = LoadDir(dir)
+	require.ErrorContains(t, err, "parse transcript")
+}
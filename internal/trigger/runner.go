@@ -13,7 +13,6 @@ import (
 	"github.com/microsoft/waza/internal/execution"
 	"github.com/microsoft/waza/internal/models"
 	"github.com/microsoft/waza/internal/transcript"
-	"github.com/microsoft/waza/internal/utils"
 )
 
 // Runner executes trigger tests and returns classification metrics.
@@ -164,7 +163,7 @@ func (r *Runner) testTrigger(ctx context.Context, prompt string) (*execution.Exe
 	return r.engine.Execute(ctx, &execution.ExecutionRequest{
 		Message:    prompt,
 		SkillName:  r.spec.Skill,
-		SkillPaths: utils.ResolvePaths(spec.Config.SkillPaths, r.cfg.SpecDir()),
+		SkillPaths: spec.ResolveSkillPaths(r.cfg.SpecDir()),
 		Timeout:    time.Duration(timeout) * time.Second,
 	})
 }
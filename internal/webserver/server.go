@@ -69,7 +69,7 @@ func (s *Server) ListenAndServe(ctx context.Context) error {
 		// Open browser in background after a short delay.
 		go func() {
 			time.Sleep(500 * time.Millisecond)
-			if err := openBrowser(url); err != nil {
+			if err := OpenBrowser(url); err != nil {
 				s.logger.Debug("failed to open browser", "error", err)
 			}
 		}()
@@ -97,8 +97,9 @@ func (s *Server) Handler() http.Handler {
 	return s.srv.Handler
 }
 
-// openBrowser opens the given URL in the default browser.
-func openBrowser(url string) error {
+// OpenBrowser opens url, or a local file path, in the default browser. It
+// returns once the opener has started.
+func OpenBrowser(url string) error {
 	var cmd *exec.Cmd
 	switch runtime.GOOS {
 	case "darwin":
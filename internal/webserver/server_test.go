@@ -290,7 +290,7 @@ func TestListenAndServeWrapsStartupError(t *testing.T) {
 func TestOpenBrowser(t *testing.T) {
 	command := browserCommandName()
 	if command == "" {
-		t.Skip("unsupported test platform for openBrowser")
+		t.Skip("unsupported test platform for OpenBrowser")
 	}
 
 	t.Run("success", func(t *testing.T) {
@@ -300,12 +300,12 @@ func TestOpenBrowser(t *testing.T) {
 		require.NoError(t, err)
 		t.Setenv("PATH", tmpDir)
 
-		require.NoError(t, openBrowser("http://localhost:9999"))
+		require.NoError(t, OpenBrowser("http://localhost:9999"))
 	})
 
 	t.Run("command not found", func(t *testing.T) {
 		t.Setenv("PATH", t.TempDir())
-		require.Error(t, openBrowser("http://localhost:9999"))
+		require.Error(t, OpenBrowser("http://localhost:9999"))
 	})
 }
 